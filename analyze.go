@@ -0,0 +1,107 @@
+package reel
+
+import (
+	"context"
+
+	"github.com/five82/reel/internal/processing"
+)
+
+// Analysis describes an input as reel would encode it.
+type Analysis struct {
+	Width        uint32
+	Height       uint32
+	DurationSecs float64
+	HDR          HDRInfo
+	AudioStreams []AudioStream // Streams that would be encoded
+	Crop         CropInfo
+	OutputWidth  uint32 // Dimensions after crop
+	OutputHeight uint32
+	Tier         string // Resolution tier that selects the CRF and preset: "SD", "HD" or "UHD"
+	CRF          uint8
+	Preset       uint8
+}
+
+// HDRInfo describes the dynamic range of the video stream.
+type HDRInfo struct {
+	IsHDR                   bool
+	ColourPrimaries         string
+	TransferCharacteristics string
+	MatrixCoefficients      string
+	BitDepth                uint8 // Zero when unknown
+}
+
+// AudioStream describes a source audio stream.
+type AudioStream struct {
+	Index     int
+	Codec     string
+	Channels  uint32
+	Language  string
+	IsDefault bool
+}
+
+// CropInfo describes the crop that would be applied.
+type CropInfo struct {
+	Filter         string // FFmpeg crop filter, e.g. "crop=1920:800:0:140"; empty when not cropping
+	Required       bool
+	MultipleRatios bool    // The source changes aspect ratio, so it is not cropped
+	Confidence     float64 // Share of detected samples agreeing on the most common crop
+	Message        string
+}
+
+// Analyze probes an input with default settings and reports what an encode
+// would do, without encoding. Options adjust the settings as for New.
+func Analyze(ctx context.Context, path string, opts ...Option) (*Analysis, error) {
+	e, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return e.Analyze(ctx, path)
+}
+
+// Analyze probes an input and reports the resolution, duration, HDR status,
+// audio streams, crop and CRF that an encode with this encoder's settings
+// would use, without encoding. Crop detection samples the whole video, so
+// this takes a few seconds on long sources.
+func (e *Encoder) Analyze(ctx context.Context, path string) (*Analysis, error) {
+	a, err := processing.Analyze(ctx, e.config, path)
+	if err != nil {
+		return nil, &FileError{Input: path, Err: err}
+	}
+
+	result := &Analysis{
+		Width:        a.Props.Width,
+		Height:       a.Props.Height,
+		DurationSecs: a.Props.DurationSecs,
+		HDR: HDRInfo{
+			IsHDR:                   a.HDR.IsHDR,
+			ColourPrimaries:         a.HDR.ColourPrimaries,
+			TransferCharacteristics: a.HDR.TransferCharacteristics,
+			MatrixCoefficients:      a.HDR.MatrixCoefficients,
+		},
+		Crop: CropInfo{
+			Filter:         a.Crop.CropFilter,
+			Required:       a.Crop.Required,
+			MultipleRatios: a.Crop.MultipleRatios,
+			Confidence:     a.Crop.Confidence,
+			Message:        a.Crop.Message,
+		},
+		OutputWidth:  a.OutputWidth,
+		OutputHeight: a.OutputHeight,
+		Tier:         a.Tier,
+		CRF:          uint8(a.CRF),
+		Preset:       a.Preset,
+	}
+	if a.HDR.BitDepth != nil {
+		result.HDR.BitDepth = *a.HDR.BitDepth
+	}
+	for _, s := range a.AudioStreams {
+		result.AudioStreams = append(result.AudioStreams, AudioStream{
+			Index:     s.Index,
+			Codec:     s.CodecName,
+			Channels:  s.Channels,
+			Language:  s.Language,
+			IsDefault: s.Disposition.Default != 0,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package reel
+
+import (
+	"runtime"
+
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/tools"
+)
+
+// Version is the reel release version.
+const Version = "0.2.0"
+
+// Info describes this reel build and the machine it runs on, for callers
+// that need to decide what they can ask of it.
+type Info struct {
+	Version   string        `json:"version"`
+	GoVersion string        `json:"go_version"`
+	Features  Features      `json:"features"`
+	Tools     []ToolVersion `json:"tools"`
+}
+
+// Features lists capabilities by name, so callers can check for them instead
+// of comparing versions. Fields are only added, never renamed or removed, so
+// a capability older versions lack decodes as false.
+type Features struct {
+	Chunked        bool `json:"chunked"`         // Scene-based chunks encoded in parallel
+	Resume         bool `json:"resume"`          // Interrupted encodes continue from finished chunks
+	ParallelFiles  bool `json:"parallel_files"`  // WithParallelFiles
+	Distributed    bool `json:"distributed"`     // Chunks encoded on other machines (not supported)
+	HardwareEncode bool `json:"hardware_encode"` // GPU or fixed-function encoders (not supported)
+
+	// Detected on this machine
+	Encoders      bool `json:"encoders"`       // SvtAv1EncApp and ffmpeg are installed and recent enough
+	ACBias        bool `json:"ac_bias"`        // The installed SvtAv1EncApp accepts --ac-bias
+	VarianceBoost bool `json:"variance_boost"` // The installed SvtAv1EncApp supports variance boost
+	NUMAPinning   bool `json:"numa_pinning"`   // More than one NUMA node, so WithNUMA("auto") pins workers
+}
+
+// ToolVersion is an external tool reel runs and the version found.
+type ToolVersion struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Version   string `json:"version"` // Version line, or why the tool couldn't be run
+}
+
+// BuildInfo returns the reel version, its features and the versions of the
+// external tools it finds in PATH or through REEL_<TOOL>. It runs each tool
+// once, so call it at startup rather than per encode.
+func BuildInfo() Info {
+	info := Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Features: Features{
+			Chunked:       true,
+			Resume:        true,
+			ParallelFiles: true,
+			NUMAPinning:   encode.DetectNUMAPinning() != nil,
+		},
+	}
+	paths := toolpath.Resolve(nil)
+	if check, err := tools.CheckEncoders(paths); err == nil {
+		info.Features.Encoders = true
+		info.Features.ACBias = check.Features.ACBias
+		info.Features.VarianceBoost = check.Features.VarianceBoost
+	}
+	for _, t := range tools.Inventory(paths) {
+		info.Tools = append(info.Tools, ToolVersion{Name: t.Name, Available: t.Available, Version: t.Version})
+	}
+	return info
+}
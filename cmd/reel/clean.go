@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/util"
+)
+
+// defaultCleanAge is the default --older-than, in hours. A running encode
+// writes chunks far more often than this, so its work directory is never
+// removed unless a shorter age is asked for.
+const defaultCleanAge = 24
+
+// cleanSearchDirs returns the directories work directories can land in
+// besides the output directory: the configured temp dir, the system temp dir
+// selectTempDir falls back to, and the tmpfs used by --scratch memory and
+// auto. Duplicates are dropped.
+func cleanSearchDirs(cfg *config.Config) []string {
+	var dirs []string
+	for _, dir := range []string{cfg.GetTempDir(), os.TempDir(), config.MemoryScratchDir} {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func runClean(args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Remove stale work directories left behind by interrupted encodes.
+
+Usage:
+  %s clean [options] [DIR]...
+
+Searches each DIR (an output directory used for encoding) for .reel-*
+work directories and removes them, reporting the space reclaimed. Without
+DIR, searches where work directories are kept otherwise: the temp dir
+(--temp-dir, or temp_dir in the config file), the system temp directory
+and /dev/shm.
+
+Options:
+  --older-than <HOURS>   Only remove directories untouched for at least HOURS. 0 also
+                           removes those of encodes still running. Default: %d
+  --dry-run              List what would be removed without deleting anything
+  --temp-dir <PATH>      Also search PATH, the --temp-dir used for encoding
+  -c, --config <PATH>    Config file to read temp_dir from
+                           (default: ~/.config/reel/config.toml)
+`, appName, defaultCleanAge)
+	}
+
+	var olderThan uint64
+	var dryRun bool
+	var tempDir, configPath string
+	fs.Uint64Var(&olderThan, "older-than", defaultCleanAge, "Minimum age in hours")
+	fs.BoolVar(&dryRun, "dry-run", false, "List without deleting")
+	fs.StringVar(&tempDir, "temp-dir", "", "Directory for work files")
+	fs.StringVar(&configPath, "c", "", "Config file")
+	fs.StringVar(&configPath, "config", "", "Config file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Explicit DIRs are searched as given, plus --temp-dir. Otherwise the
+	// temp locations are searched; they need not all exist.
+	searchDirs := fs.Args()
+	if tempDir != "" {
+		searchDirs = append(searchDirs, tempDir)
+	}
+	optional := false
+	if fs.NArg() == 0 {
+		cfg := config.NewConfig("", "", "")
+		if err := loadConfigFile(cfg, configPath); err != nil {
+			return err
+		}
+		if tempDir != "" {
+			cfg.TempDir = tempDir
+		}
+		searchDirs = cleanSearchDirs(cfg)
+		optional = true
+	}
+
+	minAge := time.Duration(olderThan) * time.Hour
+	var removed int
+	var reclaimed uint64
+
+	for _, dir := range searchDirs {
+		dirs, err := chunk.FindWorkDirs(dir, minAge)
+		if err != nil {
+			if optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, wd := range dirs {
+			age := util.FormatDurationFromSecs(int64(time.Since(wd.ModTime).Seconds()))
+			if dryRun {
+				fmt.Printf("Would remove %s (%s, idle %s)\n", wd.Path, util.FormatBytes(wd.Size), age)
+			} else {
+				if err := chunk.CleanupWorkDir(wd.Path); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to remove %s: %v\n", wd.Path, err)
+					continue
+				}
+				fmt.Printf("Removed %s (%s, idle %s)\n", wd.Path, util.FormatBytes(wd.Size), age)
+			}
+			removed++
+			reclaimed += wd.Size
+		}
+	}
+
+	switch {
+	case removed == 0:
+		fmt.Println("No stale work directories found")
+	case dryRun:
+		fmt.Printf("%d work directories, %s would be reclaimed\n", removed, util.FormatBytes(reclaimed))
+	default:
+		fmt.Printf("Removed %d work directories, reclaimed %s\n", removed, util.FormatBytes(reclaimed))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+)
+
+func TestCleanSearchDirs(t *testing.T) {
+	tests := []struct {
+		name    string
+		tempDir string
+		want    []string
+	}{
+		{"no temp dir", "", []string{os.TempDir(), config.MemoryScratchDir}},
+		{"configured temp dir", "/scratch", []string{"/scratch", os.TempDir(), config.MemoryScratchDir}},
+		{"temp dir on the tmpfs", config.MemoryScratchDir, []string{config.MemoryScratchDir, os.TempDir()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig("", "", "")
+			cfg.TempDir = tt.tempDir
+			if got := cleanSearchDirs(cfg); !slices.Equal(got, tt.want) {
+				t.Errorf("cleanSearchDirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fatih/color"
+
+	"github.com/five82/reel/internal/compare"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+func runDiffEncodes(args []string) error {
+	fs := flag.NewFlagSet("diff-encodes", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Compare two encodes of the same source.
+
+Usage:
+  %s diff-encodes [options] <A> <B>
+
+Reports file size, bitrate distribution, stream layout differences, and
+SSIM/PSNR of B against A at sampled positions. The encode settings reel
+recorded in each file are shown when present.
+
+Options:
+  --samples <N>          Number of positions to sample for metrics. Default: %d
+`, appName, compare.DefaultSamples)
+	}
+
+	var samples int
+	fs.IntVar(&samples, "samples", compare.DefaultSamples, "Number of metric sample positions")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("diff-encodes requires exactly two files")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := compare.Compare(ctx, toolpath.Resolve(nil), fs.Arg(0), fs.Arg(1), samples)
+	if err != nil {
+		return err
+	}
+
+	printDiffReport(report)
+	return nil
+}
+
+func printDiffReport(r *compare.Report) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	bold := color.New(color.Bold)
+	label := func(name, value string) {
+		fmt.Printf("  %s %s\n", bold.Sprintf("%-18s", name), value)
+	}
+
+	for _, f := range []struct {
+		title string
+		s     compare.FileSummary
+	}{{"ENCODE A", r.A}, {"ENCODE B", r.B}} {
+		fmt.Println()
+		_, _ = cyan.Println(f.title)
+		label("File:", util.GetFilename(f.s.Path))
+		label("Size:", util.FormatBytesReadable(f.s.Size))
+		label("Resolution:", fmt.Sprintf("%dx%d", f.s.Width, f.s.Height))
+		label("Duration:", util.FormatDuration(f.s.DurationSecs))
+		label("Bitrate:", fmt.Sprintf("%.0f kbps", float64(f.s.Bitrate)/1000))
+		label("Video kbps:", fmt.Sprintf("min %.0f, median %.0f, p95 %.0f, max %.0f",
+			f.s.VideoBitrate.Min, f.s.VideoBitrate.Median, f.s.VideoBitrate.P95, f.s.VideoBitrate.Max))
+		label("Streams:", formatStreams(f.s.Streams))
+		if st := f.s.Settings; st != nil {
+			label("Encoded by:", fmt.Sprintf("reel %s from %s", st.Version, st.Source))
+			label("Settings:", st.SvtParams)
+		}
+	}
+
+	fmt.Println()
+	_, _ = cyan.Println("COMPARISON")
+	sizeDelta := -util.CalculateSizeReduction(r.A.Size, r.B.Size)
+	label("Size change:", fmt.Sprintf("%+.1f%% (B vs A)", sizeDelta))
+	if len(r.LayoutDifferences) == 0 {
+		label("Stream layout:", "identical")
+	} else {
+		for _, d := range r.LayoutDifferences {
+			label("Stream layout:", d)
+		}
+	}
+	for _, s := range r.Samples {
+		label(fmt.Sprintf("@ %s:", util.FormatDuration(s.Position)), fmt.Sprintf("SSIM %.4f, PSNR %s", s.SSIM, formatPSNR(s.PSNR)))
+	}
+	label("Mean SSIM:", fmt.Sprintf("%.4f", r.MeanSSIM))
+	label("Mean PSNR:", formatPSNR(r.MeanPSNR))
+}
+
+func formatStreams(streams []ffprobe.StreamInfo) string {
+	counts := map[string]int{}
+	for _, s := range streams {
+		counts[s.CodecType]++
+	}
+	return fmt.Sprintf("%d video, %d audio, %d subtitle, %d other",
+		counts["video"], counts["audio"], counts["subtitle"],
+		len(streams)-counts["video"]-counts["audio"]-counts["subtitle"])
+}
+
+func formatPSNR(v float64) string {
+	if math.IsInf(v, 1) {
+		return "inf (identical)"
+	}
+	return fmt.Sprintf("%.2f dB", v)
+}
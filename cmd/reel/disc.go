@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/discovery"
+	"github.com/five82/reel/internal/logging"
+	"github.com/five82/reel/internal/processing"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// discLabel names a disc kind for messages.
+func discLabel(disc *discovery.Disc) string {
+	if disc.Kind == discovery.DiscDVD {
+		return "DVD"
+	}
+	return "Blu-ray"
+}
+
+// prepareDisc finds the main title of a disc folder and returns the file to
+// encode it from, named after the disc.
+func prepareDisc(ctx context.Context, disc *discovery.Disc, cfg *config.Config, rep reporter.Reporter, logger *logging.Logger) (string, error) {
+	rep.StageProgress(reporter.StageProgress{
+		Stage:   "Preparing",
+		Message: fmt.Sprintf("Finding the main title of %s folder %s", discLabel(disc), disc.Name),
+	})
+	titles, err := disc.Titles(cfg.Tools)
+	if err != nil {
+		return "", fmt.Errorf("%s folder %s: %w", discLabel(disc), disc.Root, err)
+	}
+	title := discovery.MainTitle(titles)
+	for _, t := range titles {
+		rep.Verbose(fmt.Sprintf("Title %s: %s, %s", titleName(t), util.FormatDuration(t.DurationSecs), util.FormatBytes(t.Size)))
+	}
+	rep.Verbose(fmt.Sprintf("Main title: %s", titleName(title)))
+	if disc.Kind == discovery.DiscBluray && title.Name == "" {
+		rep.Warning("No playlists could be read; the main title is the longest single clip, which may be only part of the feature")
+	}
+	for _, t := range titles {
+		if t.Incomplete && int(t.DurationSecs) > int(title.DurationSecs) {
+			rep.Warning(fmt.Sprintf("Playlist %s plays %s, longer than the main title, but some of its clips are missing",
+				t.Name, util.FormatDuration(t.DurationSecs)))
+		}
+	}
+	if logger != nil {
+		logger.Info("Main title: %s (%s)", titleName(title), util.FormatDuration(title.DurationSecs))
+	}
+
+	path, err := processing.PrepareDiscTitle(ctx, cfg.Tools, disc, title, processing.DiscTitleDir(disc, cfg.GetTempDir()), rep)
+	if err != nil {
+		return "", fmt.Errorf("%s folder %s: %w", discLabel(disc), disc.Root, err)
+	}
+	return path, nil
+}
+
+// titleName describes a title by its playlist or program chain, or else its
+// files: the clip, or the first and last VOB of a title set.
+func titleName(t discovery.DiscTitle) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	first := filepath.Base(t.Files[0])
+	if len(t.Files) == 1 {
+		return first
+	}
+	return fmt.Sprintf("%s-%s", first, filepath.Base(t.Files[len(t.Files)-1]))
+}
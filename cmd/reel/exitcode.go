@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/five82/reel/internal/processing"
+	"github.com/five82/reel/internal/tools"
+)
+
+// Process exit codes, stable for scripts. A run matching more than one
+// exits with the first that applies, in the order checked by exitCode.
+const (
+	exitOK          = 0
+	exitError       = 1 // Usage or setup error
+	exitFilesFailed = 2 // One or more files failed to encode
+	exitValidation  = 3 // Outputs were written but failed validation
+	exitDependency  = 4 // A required tool is missing or too old
+	exitCancelled   = 5 // Interrupted by SIGINT or SIGTERM
+)
+
+// exitCodesHelp documents the exit codes in the usage text.
+const exitCodesHelp = `Exit Codes:
+  0  Success: every file was encoded or skipped, and passed validation
+  1  Usage or setup error
+  2  One or more files failed to encode
+  3  Outputs were written, but one or more failed validation
+  4  A required tool (ffmpeg, SvtAv1EncApp, MediaInfo) is missing or too old
+  5  Cancelled by SIGINT or SIGTERM
+`
+
+// errFilesFailed marks a batch in which files failed to encode.
+var errFilesFailed = errors.New("files failed to encode")
+
+// exitCode maps the error a command returned to its exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, processing.ErrCancelled), errors.Is(err, context.Canceled):
+		return exitCancelled
+	case errors.Is(err, tools.ErrDependencyMissing):
+		return exitDependency
+	case errors.Is(err, errFilesFailed):
+		return exitFilesFailed
+	case errors.Is(err, processing.ErrValidationFailed):
+		return exitValidation
+	default:
+		return exitError
+	}
+}
+
+// batchError summarizes a finished batch as an error: the files that failed
+// to encode, else the outputs that failed validation. Skipped and deferred
+// files, and those a --fail-fast stop left unattempted, are not failures.
+func batchError(results []processing.EncodeResult, failures []*processing.FileError) error {
+	var failed []*processing.FileError
+	for _, f := range failures {
+		if !processing.IsSkip(f) && !errors.Is(f, processing.ErrNotAttempted) && !errors.Is(f, processing.ErrDeferred) {
+			failed = append(failed, f)
+		}
+	}
+	if len(failed) == 1 {
+		return fmt.Errorf("%w: %w", errFilesFailed, failed[0])
+	}
+	if len(failed) > 1 {
+		return fmt.Errorf("%w: %d files, first %w", errFilesFailed, len(failed), failed[0])
+	}
+
+	var invalid []string
+	for _, r := range results {
+		if !r.ValidationPassed {
+			invalid = append(invalid, r.Filename)
+		}
+	}
+	if len(invalid) == 1 {
+		return fmt.Errorf("%s: %w", invalid[0], processing.ErrValidationFailed)
+	}
+	if len(invalid) > 1 {
+		return fmt.Errorf("%d files, first %s: %w", len(invalid), invalid[0], processing.ErrValidationFailed)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/processing"
+	"github.com/five82/reel/internal/tools"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"setup error", errors.New("invalid --crf"), exitError},
+		{"files failed", fmt.Errorf("%w: boom", errFilesFailed), exitFilesFailed},
+		{"validation failed", fmt.Errorf("movie.mkv: %w", processing.ErrValidationFailed), exitValidation},
+		{"missing tool", fmt.Errorf("%w: ffmpeg", tools.ErrDependencyMissing), exitDependency},
+		{"cancelled", processing.ErrCancelled, exitCancelled},
+		{"context cancelled", fmt.Errorf("probing: %w", context.Canceled), exitCancelled},
+		// A batch matching several exits with the first code checked
+		{"cancelled file", fmt.Errorf("%w: %w", errFilesFailed, processing.ErrCancelled), exitCancelled},
+		{"missing tool for a file", fmt.Errorf("%w: %w", errFilesFailed, tools.ErrDependencyMissing), exitDependency},
+		{"file failed validation", fmt.Errorf("%w: %w", errFilesFailed, processing.ErrValidationFailed), exitFilesFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	failed := &processing.FileError{Input: "a.mkv", Err: errors.New("encoder crashed")}
+	skipped := &processing.FileError{Input: "b.mkv", Err: processing.ErrOutputExists}
+	noVideo := &processing.FileError{Input: "c.mkv", Err: ffprobe.ErrNoVideo}
+	stopped := &processing.FileError{Input: "d.mkv", Err: processing.ErrNotAttempted}
+	deferred := &processing.FileError{Input: "e.mkv", Err: processing.ErrDeferred}
+	valid := processing.EncodeResult{Filename: "f.mkv", ValidationPassed: true}
+	invalid := processing.EncodeResult{Filename: "g.mkv"}
+
+	tests := []struct {
+		name     string
+		results  []processing.EncodeResult
+		failures []*processing.FileError
+		want     int
+	}{
+		{"all passed", []processing.EncodeResult{valid}, nil, exitOK},
+		{"skips, stops and deferrals", []processing.EncodeResult{valid}, []*processing.FileError{skipped, noVideo, stopped, deferred}, exitOK},
+		{"one failed", []processing.EncodeResult{valid}, []*processing.FileError{skipped, failed}, exitFilesFailed},
+		{"two failed", nil, []*processing.FileError{failed, failed}, exitFilesFailed},
+		{"failed outranks invalid", []processing.EncodeResult{invalid}, []*processing.FileError{failed}, exitFilesFailed},
+		{"one invalid", []processing.EncodeResult{valid, invalid}, []*processing.FileError{skipped}, exitValidation},
+		{"two invalid", []processing.EncodeResult{invalid, invalid}, nil, exitValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := batchError(tt.results, tt.failures)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(batchError()) = %d (%v), want %d", got, err, tt.want)
+			}
+		})
+	}
+}
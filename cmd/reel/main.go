@@ -8,43 +8,68 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/five82/reel"
+	"github.com/five82/reel/internal/cmdlog"
 	"github.com/five82/reel/internal/config"
 	"github.com/five82/reel/internal/discovery"
+	"github.com/five82/reel/internal/encode"
 	"github.com/five82/reel/internal/logging"
 	"github.com/five82/reel/internal/processing"
 	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/tools"
 	"github.com/five82/reel/internal/util"
 )
 
 const (
 	appName    = "reel"
-	appVersion = "0.2.0"
+	appVersion = reel.Version
 )
 
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
 	switch os.Args[1] {
 	case "encode":
 		if err := runEncode(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
+		}
+	case "diff-encodes":
+		if err := runDiffEncodes(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	case "clean":
+		if err := runClean(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	case "profiles":
+		if err := runProfiles(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
 		}
 	case "version", "--version", "-v":
-		fmt.Printf("%s version %s\n", appName, appVersion)
+		if err := runVersion(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
 	case "help", "--help", "-h":
 		printUsage()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
 
@@ -55,12 +80,16 @@ Usage:
   %s <command> [options]
 
 Commands:
-  encode    Encode video files to AV1 format
-  version   Print version information
-  help      Show this help message
+  encode        Encode video files to AV1 format
+  diff-encodes  Compare two encodes of the same source
+  clean         Remove stale work directories from interrupted encodes
+  profiles      List settings profiles and their effective parameters
+  version       Print version information (--json for features and tool versions)
+  help          Show this help message
 
 Run '%s encode --help' for encode command options.
-`, appName, appName, appName)
+
+%s`, appName, appName, appName, exitCodesHelp)
 }
 
 // encodeArgs holds the parsed arguments for the encode command.
@@ -70,12 +99,89 @@ type encodeArgs struct {
 	logDir          string
 	verbose         bool
 	crf             string // Single value or comma-separated triple (SD,HD,UHD)
-	preset          uint
+	preset          string
+	tune            uint
+	acBias          float64
+	varianceBoost   bool
+	varianceStr     uint
+	varianceOctile  uint
+	keyint          float64
+	tiles           string
+	noSCD           bool
+	bitDepth        string
 	disableAutocrop bool
+	cropConfidence  float64
 	noLog           bool
+	logFormat       string
+	saveCommands    string
+	notifyDesktop   bool
 	workers         int
 	chunkBuffer     int
+	decodeAhead     int
 	threads         int
+	noMemoryCap     bool
+	memPerWorker    string
+	numa            string
+	chunkStrategy   string
+	alsoCopyTo      stringList
+	include         stringList
+	exclude         stringList
+	minSize         string
+	minDuration     string
+	tempDir         string
+	stageLocal      bool
+	scratch         string
+	configPath      string
+	profile         string
+	schedule        string
+	deadline        string
+	deadlineChunks  bool
+	maxRuntime      string
+	maxFiles        int
+	cooldown        uint64
+	growingInput    uint64
+	palSlowdown     bool
+	downmix         bool
+	dialogueBoost   float64
+	videoStream     int
+	passthrough     string
+	preserveAtmos   bool
+	dropAttachments bool
+	failFast        bool
+	continueOnError bool
+	dupStragglers   bool
+	chunkRetries    int
+	fewerThreads    bool
+	chunkTimeout    float64
+	noIndexCache    bool
+	noBatchResume   bool
+	resumeBatch     bool
+	prefetch        bool
+	parallelFiles   int
+	estimate        bool
+	verifyDeterm    bool
+	abortLarger     string
+	content         string
+	writeReport     bool
+	attachSettings  bool
+	outputTemplate  string
+	chmod           string
+	chown           string
+	preserveTimes   bool
+	preserveAtime   bool
+	duplicates      string
+	checksum        string
+	explicit        map[string]bool // Flags set on the command line
+}
+
+// stringList is a flag.Value that collects repeated string flags.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
 }
 
 func runEncode(args []string) error {
@@ -90,31 +196,197 @@ Usage:
   %s encode [options]
 
 Required:
-  -i, --input <PATH>     Input video file or directory containing video files
+  -i, --input <PATH>     Input video file or directory containing video files, or -
+                           to read a list of files from stdin, one per line or
+                           NUL-delimited (find -print0). A DVD (VIDEO_TS) or Blu-ray
+                           (BDMV) folder is encoded as its main title
   -o, --output <PATH>    Output directory (or filename if input is a single file)
 
 Options:
+  -c, --config <PATH>    Config file (defaults to ~/.config/reel/config.toml)
   -l, --log-dir <PATH>   Log directory (defaults to ~/.local/state/reel/logs)
   -v, --verbose          Enable verbose output for troubleshooting
 
+Discovery Filters (input directories only):
+  --include <GLOB>       Only encode files whose name matches GLOB (e.g. '*.mkv').
+                           Repeatable; a file matching any of them is kept
+  --exclude <GLOB>       Skip files whose name matches GLOB (e.g. '*sample*').
+                           Repeatable
+  --min-size <SIZE>      Skip files smaller than SIZE (e.g. 500M, 2G)
+  --min-duration <DURATION>
+                         Skip files shorter than DURATION (e.g. 10m)
+
 Quality Settings:
+  --profile <NAME>       Settings profile: dvd, anime, film-grain, archive, or one
+                           defined in the config file. Run 'reel profiles' to list
+                           them with their effective parameters
+  --content <TYPE>       Content type to tune for: auto, film, anime, screen. Default: auto
+                           anime drops ac-bias; screen enables screen content mode
+                           and PSNR tuning. auto classifies each source
   --crf <VALUE>          CRF quality level (0-63, lower=better). Accepts:
                            Single value: --crf 27 (use for all resolutions)
                            Triple: --crf 25,27,29 (SD,HD,UHD)
                          Defaults: SD=%d, HD=%d, UHD=%d
-  --preset <0-13>        SVT-AV1 encoder preset. Lower=slower/better. Default: %d
+  --preset <VALUE>       SVT-AV1 encoder preset (0-13, lower=slower/better). Accepts:
+                           Single value: --preset 6 (use for all resolutions)
+                           Triple: --preset 4,6,8 (SD,HD,UHD)
+                         Default: %d
+  --tune <0-4>           SVT-AV1 tune: 0=VQ, 1=PSNR, 2=SSIM; higher values depend
+                           on the encoder build. Default: %d
+  --ac-bias <0-8>        SVT-AV1 ac-bias; higher keeps more texture and grain. Default: %g
+  --variance-boost       Enable variance boost against banding in flat areas
+  --variance-strength <1-4>
+                         Variance boost strength (implies --variance-boost). Default: %d
+  --variance-octile <1-8>
+                         Variance boost octile (implies --variance-boost). Default: %d
+  --keyint <SECS>        Maximum keyframe interval in seconds (1-30). Default: %g
+  --tiles <RxC>          Tile rows and columns, each a power of two (e.g. 2x2), for
+                           decoders that decode tiles in parallel. Default: 1x1
+  --no-scd               Don't add keyframes at scene changes within a chunk, so
+                           keyframes fall only at chunk starts and every --keyint
+  --bit-depth <MODE>     Output bit depth: 10, 8, or auto (8-bit for 8-bit SDR
+                           sources). HDR is always encoded at 10-bit. Default: 10
 
 Processing Options:
   --disable-autocrop     Disable automatic black bar crop detection
+  --crop-confidence <0-1>
+                         Share of crop samples that must agree on one crop before
+                           it is used; otherwise the video is not cropped. Default: %g
   --workers <N>          Number of parallel encoder workers. Default: %d (auto)
   --buffer <N>           Extra chunks to buffer in memory. Default: %d (auto)
+  --decode-ahead <N>     Frames each worker decodes ahead of its encoder, so neither
+                           waits on the other for every frame. Default: %d
+  --chunk-strategy <MODE>
+                         Chunk placement: fixed (every chunk the chunk duration) or
+                           balanced (fewer frames in complex scenes, so chunks take
+                           about as long to encode). Default: fixed
   --threads <N>          Threads per worker (SVT-AV1 --lp flag). Default: auto
                            Auto mode detects physical cores and SMT, then calculates
                            optimal threads based on resolution. Override if needed.
+  --no-memory-cap        Use --workers as given instead of capping workers by available
+                           memory. For machines with large swap or zram
+  --mem-per-worker <SIZE>
+                         Memory each worker is assumed to need when capping workers
+                           (e.g. 3G). Default: estimated by resolution
+  --numa <MODE>          On multi-socket machines, pin each worker's decoding and encoder
+                           to a NUMA node, round robin (with numactl or taskset when
+                           installed): auto or off. Default: auto
+  --duplicate-stragglers Near the end of an encode, re-encode chunks running much longer
+                           than typical on idle workers and keep whichever finishes first
+  --chunk-retries <N>    Retry a chunk whose encoder was killed (e.g. out of memory) up
+                           to N times, waiting longer each time. Default: %d
+  --retry-fewer-threads  Halve the threads per worker on each chunk retry
+  --chunk-timeout <SECS> Seconds allowed per 1080p frame of a chunk, scaled by frame size,
+                           before its encoder is killed as hung and the chunk retried.
+                           0 disables. Default: %g
+  --prefetch             In a batch, probe, detect crop and index the next file while
+                           the current one encodes, so it starts without waiting
+  --parallel-files <N>   Encode up to N files of a batch at once, each with a 1/N share
+                           of the workers. Suits batches of small SD files. Default: 1
+  --no-index-cache       Don't reuse FFMS2 indexes from earlier runs or cache new ones
+                           (~/.cache/reel/index by default)
+  --estimate             Encode a few probe chunks first and report the projected
+                           output size and encode time
+  --verify-determinism   Encode one random chunk twice first and warn if the outputs
+                           differ, before relying on chunk resume
+  --abort-if-larger-than <RATIO>
+                         Stop a file's encode once its projected output exceeds RATIO
+                           times the source size (e.g. 0.9x)
+  --growing-input <SECS> Encode inputs that are still being ripped: finished chunks are
+                           encoded as the file grows, the rest once it hasn't grown
+                           for SECS seconds. Not with balanced chunks or --stage-local
+  --schedule <HH:MM-HH:MM>
+                         Only start new files and chunks inside this daily window
+                           (e.g. 22:00-07:00). Running chunks finish outside it
+  --deadline <DURATION>  Start no new files once DURATION has passed (e.g. 8h). Files
+                           not reached are listed as deferred; rerun to continue
+  --deadline-chunks      Also start no new chunks after the deadline. Running chunks
+                           finish and are kept, so the rerun resumes the file
+  --max-runtime <DURATION>
+                         Stop starting files and chunks once DURATION has passed;
+                           the same as --deadline with --deadline-chunks
+  --max-files-per-run <N>
+                         Start at most N files, deferring the rest to the next run.
+                           Files whose output already exists don't count
+  --cooldown <SECS>      Pause between files in a batch (default: 3)
+  --pal-slowdown         Slow 25fps PAL sources back to 23.976fps film rate, time-stretching
+                           audio to keep its pitch and retiming subtitles
+  --video-stream <N>     Encode the Nth video stream, counted from 0, instead of the
+                           primary one (the first flagged default, skipping cover art)
+  --audio-passthrough <FORMATS>
+                         Copy audio in these lossless formats instead of encoding it to
+                           Opus: comma-separated truehd, dts-hd, flac, alac, pcm
+  --preserve-atmos       Copy Dolby Atmos and DTS:X audio instead of encoding it to Opus,
+                           which would discard its object metadata
+  --drop-attachments     Leave attachments such as embedded subtitle fonts out of the
+                           output (kept by default so ASS subtitles render as intended)
+  --downmix-stereo       Add a stereo downmix of the first surround audio stream as an
+                           extra track after the others
+  --dialogue-boost <DB>  Raise the center channel by DB (0-12) in the stereo downmix to
+                           make dialogue clearer. Implies --downmix-stereo
+  --temp-dir <PATH>      Directory for work files (chunks, merged video). Defaults to
+                           temp_dir in the config file, or the output directory. Falls back to the output or system
+                           temp directory when it lacks space for the estimated work files
+  --scratch <LOCATION>   Where work files are kept: disk (default); memory, a RAM-backed
+                           tmpfs (--temp-dir, or /dev/shm) used when the work files fit in
+                           its free space and half of available memory; or auto, which
+                           uses /dev/shm when they fit in a quarter of available memory.
+                           Both fall back to disk
+  --stage-local          Copy each source to local storage (--temp-dir, or the system
+                           temp directory) before encoding and move the output into
+                           place afterwards, for sources on NFS or SMB shares
 
 Output Options:
   --no-log               Disable Reel log file creation
-`, appName, config.DefaultCRFSD, config.DefaultCRFHD, config.DefaultCRFUHD, config.DefaultSVTAV1Preset, defaultWorkers, defaultBuffer)
+  --log-format <FORMAT>  Log file format: text, or json for one JSON object per line
+                           (time, level, event, message, fields) for log shippers.
+                           Default: text
+  --save-commands <FILE> Write every external command run (ffmpeg, ffprobe,
+                           SvtAv1EncApp, ...) with its exit status to FILE as a
+                           shell script that replays them. With --verbose they
+                           are also written to the log file
+  --notify-desktop       Show a desktop notification (notify-send) when each file
+                           finishes or fails, and when a batch completes
+  --also-copy-to <DEST>  After validation, also copy the output and its sidecar files
+                           to DEST. Repeatable. DEST is a directory or an rclone
+                           remote prefixed with "rclone:" (e.g. rclone:nas:backup)
+  --report               Write <output>.reel.json with encode results and
+                           machine-readable validation codes
+  --attach-settings      Attach the encode settings (version, CRF, preset, SVT-AV1
+                           parameters, source name) as JSON; they are always
+                           written as container tags
+  --output-template <TEMPLATE>
+                         Name outputs from TEMPLATE instead of the source name, e.g.
+                           "{name}.av1.{ext}" or "{name:x264=AV1}.{ext}". Tokens:
+                           {name}, {resolution}, {crf}, {codec}, {date}, {ext}.
+                           Ignored when -o names the output file
+  --chmod <MODE>         Set outputs and their reports to octal MODE (e.g. 664);
+                           directories created for them get matching search bits.
+                           Default: as created, subject to the umask
+  --chown <USER:GROUP>   Set the owner and/or group of outputs and the directories
+                           created for them (e.g. media, :media, 1000:1000)
+  --preserve-times       Give each output the modification time of its source, so
+                           libraries sorted by date keep their order
+  --preserve-atime       Also copy the source's access time. Implies --preserve-times
+  --checksum <ALGO>      Write a digest of each output to <output>.<ALGO>, in the
+                           format sha256sum -c reads, and add it to the --report
+                           file. Algorithms: sha256
+  --duplicates <POLICY>  Inputs that are the same file or identical content are
+                           encoded once; the others get the output by: link, copy,
+                           skip (no output), or encode (no deduplication). Default: link
+  --fail-fast            Stop the batch at the first file that fails to encode or
+                           fails validation. Exits with an error
+  --continue             Keep going past failed files and list them in the batch
+                           summary (default)
+  --no-batch-resume      Don't continue an interrupted batch from where it stopped;
+                           check every file again (progress is kept in
+                           ~/.local/state/reel/batches by default)
+  --resume-batch         Continue the directory's saved batch even if files were added
+                           or removed since, and skip files that failed in it
+                           instead of trying them again
+`, appName, config.DefaultCRFSD, config.DefaultCRFHD, config.DefaultCRFUHD, config.DefaultSVTAV1Preset,
+			config.DefaultSVTAV1Tune, config.DefaultSVTAV1ACBias, config.DefaultSVTAV1VarianceBoostStrength, config.DefaultSVTAV1VarianceOctile, config.DefaultKeyintSecs,
+			config.DefaultCropConfidence, defaultWorkers, defaultBuffer, config.DefaultDecodeAhead, config.DefaultChunkRetries, config.DefaultChunkTimeoutSecs)
 	}
 
 	var ea encodeArgs
@@ -126,27 +398,98 @@ Output Options:
 	fs.StringVar(&ea.outputDir, "output", "", "Output directory")
 
 	// Optional arguments
+	fs.StringVar(&ea.configPath, "c", "", "Config file")
+	fs.StringVar(&ea.configPath, "config", "", "Config file")
 	fs.StringVar(&ea.logDir, "l", "", "Log directory")
 	fs.StringVar(&ea.logDir, "log-dir", "", "Log directory")
 	fs.BoolVar(&ea.verbose, "v", false, "Enable verbose output")
 	fs.BoolVar(&ea.verbose, "verbose", false, "Enable verbose output")
 
+	// Discovery filters
+	fs.Var(&ea.include, "include", "Only encode files matching this name glob (repeatable)")
+	fs.Var(&ea.exclude, "exclude", "Skip files matching this name glob (repeatable)")
+	fs.StringVar(&ea.minSize, "min-size", "", "Skip files smaller than this size (e.g. 500M)")
+	fs.StringVar(&ea.minDuration, "min-duration", "", "Skip files shorter than this duration (e.g. 10m)")
+
 	// Quality settings
+	fs.StringVar(&ea.profile, "profile", "", "Settings profile")
+	fs.StringVar(&ea.content, "content", config.ContentAuto, "Content type (auto, film, anime, screen)")
 	fs.StringVar(&ea.crf, "crf", "", "CRF quality level (single value or SD,HD,UHD)")
-	fs.UintVar(&ea.preset, "preset", 0, "SVT-AV1 encoder preset (0-13)")
+	fs.StringVar(&ea.preset, "preset", "", "SVT-AV1 encoder preset (single value or SD,HD,UHD)")
+	fs.UintVar(&ea.tune, "tune", 0, "SVT-AV1 tune (0-4)")
+	fs.Float64Var(&ea.acBias, "ac-bias", 0, "SVT-AV1 ac-bias (0-8)")
+	fs.BoolVar(&ea.varianceBoost, "variance-boost", false, "Enable variance boost")
+	fs.UintVar(&ea.varianceStr, "variance-strength", 0, "Variance boost strength (1-4)")
+	fs.UintVar(&ea.varianceOctile, "variance-octile", 0, "Variance boost octile (1-8)")
+	fs.Float64Var(&ea.keyint, "keyint", config.DefaultKeyintSecs, "Maximum keyframe interval in seconds")
+	fs.StringVar(&ea.tiles, "tiles", "", "Tile rows and columns (RxC)")
+	fs.BoolVar(&ea.noSCD, "no-scd", false, "Disable scene change detection within chunks")
+	fs.StringVar(&ea.bitDepth, "bit-depth", "", "Output bit depth (8, 10, auto)")
 
 	// Processing options
 	fs.BoolVar(&ea.disableAutocrop, "disable-autocrop", false, "Disable automatic crop detection")
+	fs.Float64Var(&ea.cropConfidence, "crop-confidence", config.DefaultCropConfidence, "Share of crop samples that must agree (0-1)")
 	fs.IntVar(&ea.workers, "workers", defaultWorkers, "Number of parallel encoder workers")
 	fs.IntVar(&ea.chunkBuffer, "buffer", defaultBuffer, "Extra chunks to buffer in memory")
+	fs.IntVar(&ea.decodeAhead, "decode-ahead", config.DefaultDecodeAhead, "Frames each worker decodes ahead of its encoder")
 	fs.IntVar(&ea.threads, "threads", config.DefaultThreadsPerWorker, "Threads per worker")
+	fs.BoolVar(&ea.noMemoryCap, "no-memory-cap", false, "Don't cap workers by available memory")
+	fs.StringVar(&ea.memPerWorker, "mem-per-worker", "", "Memory per worker when capping workers (e.g. 3G)")
+	fs.StringVar(&ea.numa, "numa", config.NUMAAuto, "Pin workers to NUMA nodes (auto, off)")
+	fs.StringVar(&ea.chunkStrategy, "chunk-strategy", config.ChunkFixed, "Chunk placement (fixed, balanced)")
+	fs.StringVar(&ea.tempDir, "temp-dir", "", "Directory for work files")
+	fs.StringVar(&ea.scratch, "scratch", config.ScratchDisk, "Work file location (disk, memory, auto)")
+	fs.BoolVar(&ea.stageLocal, "stage-local", false, "Encode from a local copy of each source")
+	fs.StringVar(&ea.schedule, "schedule", "", "Daily window for starting work (HH:MM-HH:MM)")
+	fs.StringVar(&ea.deadline, "deadline", "", "Start no new files after this duration (e.g. 8h)")
+	fs.BoolVar(&ea.deadlineChunks, "deadline-chunks", false, "Also start no new chunks after the deadline")
+	fs.StringVar(&ea.maxRuntime, "max-runtime", "", "Start no new files or chunks after this duration (e.g. 6h)")
+	fs.IntVar(&ea.maxFiles, "max-files-per-run", 0, "Start at most this many files (0 = no limit)")
+	fs.Uint64Var(&ea.cooldown, "cooldown", config.DefaultEncodeCooldownSecs, "Seconds to pause between files in a batch")
+	fs.BoolVar(&ea.dupStragglers, "duplicate-stragglers", false, "Duplicate slow final chunks onto idle workers")
+	fs.IntVar(&ea.chunkRetries, "chunk-retries", config.DefaultChunkRetries, "Retries for chunks whose encoder was killed")
+	fs.BoolVar(&ea.fewerThreads, "retry-fewer-threads", false, "Halve threads per worker on each chunk retry")
+	fs.Float64Var(&ea.chunkTimeout, "chunk-timeout", config.DefaultChunkTimeoutSecs, "Seconds per 1080p frame before a chunk's encoder is killed (0 = never)")
+	fs.BoolVar(&ea.prefetch, "prefetch", false, "Analyze the next file in a batch while the current one encodes")
+	fs.IntVar(&ea.parallelFiles, "parallel-files", 1, "Files in a batch to encode at once")
+	fs.BoolVar(&ea.noIndexCache, "no-index-cache", false, "Don't cache FFMS2 indexes between runs")
+	fs.BoolVar(&ea.noBatchResume, "no-batch-resume", false, "Don't continue an interrupted batch")
+	fs.BoolVar(&ea.resumeBatch, "resume-batch", false, "Continue the saved batch, skipping files that failed in it")
+	fs.BoolVar(&ea.estimate, "estimate", false, "Report projected output size and time from probe chunks")
+	fs.BoolVar(&ea.verifyDeterm, "verify-determinism", false, "Encode a chunk twice and compare the outputs")
+	fs.StringVar(&ea.abortLarger, "abort-if-larger-than", "", "Stop when projected output exceeds this fraction of the source (e.g. 0.9x)")
+	fs.Uint64Var(&ea.growingInput, "growing-input", 0, "Encode inputs as they grow; seconds without growth that mark them complete")
+	fs.BoolVar(&ea.palSlowdown, "pal-slowdown", false, "Slow 25fps sources to 23.976fps")
+	fs.IntVar(&ea.videoStream, "video-stream", 0, "Video stream to encode, counted from 0 (default: the primary one)")
+	fs.StringVar(&ea.passthrough, "audio-passthrough", "", "Lossless audio formats to copy instead of encoding (truehd,dts-hd,flac,alac,pcm)")
+	fs.BoolVar(&ea.preserveAtmos, "preserve-atmos", false, "Copy Dolby Atmos and DTS:X audio instead of encoding it")
+	fs.BoolVar(&ea.dropAttachments, "drop-attachments", false, "Leave attachments such as subtitle fonts out of the output")
+	fs.BoolVar(&ea.downmix, "downmix-stereo", false, "Add a stereo downmix of the first surround stream")
+	fs.Float64Var(&ea.dialogueBoost, "dialogue-boost", 0, "Center channel gain in dB for the stereo downmix")
 
 	// Output options
 	fs.BoolVar(&ea.noLog, "no-log", false, "Disable log file creation")
+	fs.StringVar(&ea.logFormat, "log-format", logging.FormatText, "Log file format (text, json)")
+	fs.StringVar(&ea.saveCommands, "save-commands", "", "Write external commands to a replayable shell script")
+	fs.BoolVar(&ea.notifyDesktop, "notify-desktop", false, "Show desktop notifications when files finish or fail")
+	fs.Var(&ea.alsoCopyTo, "also-copy-to", "Additional destination for the validated output (repeatable)")
+	fs.BoolVar(&ea.writeReport, "report", false, "Write <output>.reel.json with results and validation codes")
+	fs.BoolVar(&ea.attachSettings, "attach-settings", false, "Attach the encode settings to the output as JSON")
+	fs.StringVar(&ea.outputTemplate, "output-template", "", "Output filename template (e.g. {name}.av1.{ext})")
+	fs.StringVar(&ea.chmod, "chmod", "", "Octal permissions for outputs (e.g. 664)")
+	fs.StringVar(&ea.chown, "chown", "", "Owner and group for outputs (user:group)")
+	fs.BoolVar(&ea.preserveTimes, "preserve-times", false, "Copy the source modification time to the output")
+	fs.BoolVar(&ea.preserveAtime, "preserve-atime", false, "Also copy the source access time to the output")
+	fs.StringVar(&ea.checksum, "checksum", "", "Write a digest of each output (sha256)")
+	fs.StringVar(&ea.duplicates, "duplicates", config.DuplicatesLink, "Policy for duplicate inputs (link, copy, skip, encode)")
+	fs.BoolVar(&ea.failFast, "fail-fast", false, "Stop the batch at the first failed file")
+	fs.BoolVar(&ea.continueOnError, "continue", false, "Continue past failed files (default)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	ea.explicit = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { ea.explicit[f.Name] = true })
 
 	// Validate required arguments
 	if ea.inputPath == "" {
@@ -159,27 +502,78 @@ Output Options:
 	return executeEncode(ea)
 }
 
+// loadConfigFile applies the config file at path to cfg, or the default
+// config file, if it exists, when path is empty.
+func loadConfigFile(cfg *config.Config, path string) error {
+	if path != "" {
+		return cfg.LoadFile(path, true)
+	}
+	if path = config.DefaultConfigPath(); path != "" {
+		return cfg.LoadFile(path, false)
+	}
+	return nil
+}
+
 func executeEncode(ea encodeArgs) error {
-	// Resolve input path
-	inputPath, err := filepath.Abs(ea.inputPath)
-	if err != nil {
-		return fmt.Errorf("invalid input path: %w", err)
+	// "-" reads the list of inputs from stdin; they are treated like the
+	// files of an input directory
+	var listedFiles []string
+	var disc *discovery.Disc
+	inputPath, isInputDir := ea.inputPath, false
+	if inputPath == "-" {
+		files, err := discovery.ReadFileList(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("stdin: %w", err)
+		}
+		listedFiles, isInputDir = files, true
+		if inputPath, err = os.Getwd(); err != nil {
+			return fmt.Errorf("invalid input path: %w", err)
+		}
+	} else {
+		// Resolve input path
+		var err error
+		inputPath, err = filepath.Abs(inputPath)
+		if err != nil {
+			return fmt.Errorf("invalid input path: %w", err)
+		}
+
+		// Check if input exists
+		inputInfo, err := os.Stat(inputPath)
+		if err != nil {
+			return fmt.Errorf("input path does not exist: %s", inputPath)
+		}
+		isInputDir = inputInfo.IsDir()
+
+		// A DVD or Blu-ray folder is a single input: its main title
+		if isInputDir {
+			if d, ok := discovery.DetectDisc(inputPath); ok {
+				disc, isInputDir = d, false
+			}
+		}
 	}
 
-	// Check if input exists
-	inputInfo, err := os.Stat(inputPath)
-	if err != nil {
-		return fmt.Errorf("input path does not exist: %s", inputPath)
+	// Build configuration; directories are filled in once resolved
+	cfg := config.NewConfig(inputPath, "", "")
+	cfg.Version = appVersion
+
+	// Apply config file settings
+	if err := loadConfigFile(cfg, ea.configPath); err != nil {
+		return err
 	}
+	cfg.Tools = toolpath.Resolve(cfg.ToolPaths)
 
 	// Resolve output path
-	outputDir, targetFilename, err := resolveOutputPath(inputPath, ea.outputDir, inputInfo.IsDir())
+	outputDir, targetFilename, err := resolveOutputPath(ea.outputDir, isInputDir, cfg.VideoExtensions)
 	if err != nil {
 		return err
 	}
+	cfg.OutputDir = outputDir
 
-	// Ensure output directory exists
-	if err := util.EnsureDirectory(outputDir); err != nil {
+	// Ensure output directory exists, with the permissions outputs get
+	if cfg.OutputPerms, err = outputPerms(&ea); err != nil {
+		return err
+	}
+	if err := util.EnsureOutputDirectory(outputDir, cfg.OutputPerms); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -188,41 +582,93 @@ func executeEncode(ea encodeArgs) error {
 	if logDir == "" {
 		logDir = logging.DefaultLogDir()
 	}
+	cfg.LogDir = logDir
 
 	// Setup file logging
-	logger, err := logging.Setup(logDir, ea.verbose, ea.noLog, os.Args)
+	if !slices.Contains(logging.Formats, ea.logFormat) {
+		return fmt.Errorf("log format must be one of %v, got %q", logging.Formats, ea.logFormat)
+	}
+	logger, err := logging.Setup(logDir, ea.logFormat, ea.verbose, ea.noLog, os.Args)
 	if err != nil {
 		return fmt.Errorf("failed to setup logging: %w", err)
 	}
 	if logger != nil {
 		defer func() { _ = logger.Close() }()
+		cmdlog.SetLog(logger.Debug)
+	}
+	if ea.saveCommands != "" {
+		if err := cmdlog.OpenScript(ea.saveCommands); err != nil {
+			return err
+		}
+		defer func() { _ = cmdlog.CloseScript() }()
+		if logger != nil {
+			logger.Info("Command script: %s", ea.saveCommands)
+		}
+	}
+	if logger != nil {
+		for _, t := range tools.Inventory(cfg.Tools) {
+			logger.Info("%s: %s", t.Name, t.Version)
+			if t.Build != "" {
+				logger.Info("%s build: %s", t.Name, t.Build)
+			}
+		}
 	}
 
 	// Discover files to process
+	filter, err := discoveryFilter(&ea)
+	if err != nil {
+		return err
+	}
 	var filesToProcess []string
-	if inputInfo.IsDir() {
-		filesToProcess, err = discovery.FindVideoFiles(inputPath)
+	var excluded []discovery.Excluded
+	switch {
+	case listedFiles != nil:
+		filesToProcess = listedFiles
+		if logger != nil {
+			logger.Info("Read %d input files from stdin", len(filesToProcess))
+			for i, f := range filesToProcess {
+				logger.Debug("  %d. %s", i+1, f)
+			}
+		}
+	case isInputDir:
+		filesToProcess, err = discovery.FindVideoFiles(inputPath, cfg.VideoExtensions)
 		if err != nil {
 			return fmt.Errorf("failed to discover video files: %w", err)
 		}
+		found := len(filesToProcess)
+		filesToProcess, excluded = filter.Apply(cfg.Tools, filesToProcess)
 		if len(filesToProcess) == 0 {
-			return fmt.Errorf("no video files found in %s", inputPath)
+			return fmt.Errorf("all %d video files in %s were excluded by the discovery filters", found, inputPath)
 		}
+		cfg.BatchDir = inputPath
 		if logger != nil {
-			logger.Info("Discovered %d video files in %s", len(filesToProcess), inputPath)
+			logger.Info("Discovered %d video files in %s", found, inputPath)
+			for _, e := range excluded {
+				logger.Info("Excluded %s: %s", filepath.Base(e.Path), e.Reason)
+			}
 			for i, f := range filesToProcess {
 				logger.Debug("  %d. %s", i+1, f)
 			}
 		}
-	} else {
+	case disc != nil:
+		// The main title is found once the temp directory is known
+		if logger != nil {
+			logger.Info("Processing %s folder: %s", discLabel(disc), inputPath)
+		}
+	default:
 		filesToProcess = []string{inputPath}
 		if logger != nil {
 			logger.Info("Processing single file: %s", inputPath)
 		}
 	}
 
-	// Build configuration
-	cfg := config.NewConfig(inputPath, outputDir, logDir)
+	// Apply profile before explicit CLI arguments so they take precedence
+	if ea.profile != "" {
+		if err := cfg.ApplyProfile(ea.profile); err != nil {
+			return err
+		}
+		cfg.Tools = toolpath.Resolve(cfg.ToolPaths)
+	}
 
 	// Override with explicit CLI arguments
 	if ea.crf != "" {
@@ -230,15 +676,128 @@ func executeEncode(ea encodeArgs) error {
 			return err
 		}
 	}
-	if ea.preset != 0 {
-		cfg.SVTAV1Preset = uint8(ea.preset)
+	if ea.preset != "" {
+		if err := parsePreset(ea.preset, cfg); err != nil {
+			return err
+		}
+	}
+	if err := applyTuningFlags(cfg, &ea); err != nil {
+		return err
 	}
 	if ea.disableAutocrop {
 		cfg.CropMode = "none"
 	}
+	cfg.CropConfidence = ea.cropConfidence
+	cfg.ContentType = ea.content
 	cfg.Workers = ea.workers
 	cfg.ChunkBuffer = ea.chunkBuffer
+	cfg.DecodeAhead = ea.decodeAhead
 	cfg.ThreadsPerWorker = ea.threads
+	cfg.DisableMemoryCap = ea.noMemoryCap
+	cfg.NUMA = ea.numa
+	cfg.ChunkStrategy = ea.chunkStrategy
+	if ea.memPerWorker != "" {
+		bytes, err := util.ParseBytes(ea.memPerWorker)
+		if err != nil {
+			return err
+		}
+		cfg.MemPerWorker = bytes
+	}
+	clearParallelOverrides(cfg, ea.explicit)
+	cfg.CopyDestinations = ea.alsoCopyTo
+	cfg.GrowingQuietSecs = ea.growingInput
+	cfg.PALSlowdown = ea.palSlowdown
+	if ea.explicit["video-stream"] {
+		cfg.VideoStream = &ea.videoStream
+	}
+	if ea.passthrough != "" {
+		for format := range strings.SplitSeq(ea.passthrough, ",") {
+			cfg.AudioPassthrough = append(cfg.AudioPassthrough, strings.ToLower(strings.TrimSpace(format)))
+		}
+	}
+	cfg.PreserveAtmos = ea.preserveAtmos
+	cfg.DropAttachments = ea.dropAttachments
+	cfg.StereoDownmix = ea.downmix || ea.dialogueBoost != 0
+	cfg.DialogueBoostDB = ea.dialogueBoost
+	cfg.DuplicateStragglers = ea.dupStragglers
+	cfg.ChunkRetries = ea.chunkRetries
+	cfg.RetryFewerThreads = ea.fewerThreads
+	cfg.ChunkTimeoutSecs = ea.chunkTimeout
+	if ea.noIndexCache {
+		cfg.IndexCacheDir = ""
+	}
+	if ea.noBatchResume && ea.resumeBatch {
+		return fmt.Errorf("--no-batch-resume and --resume-batch cannot be used together")
+	}
+	if !ea.noBatchResume {
+		cfg.BatchStateDir = processing.DefaultBatchStateDir()
+	}
+	cfg.ResumeBatch = ea.resumeBatch
+	cfg.Prefetch = ea.prefetch
+	cfg.ParallelFiles = ea.parallelFiles
+	cfg.EstimateSize = ea.estimate
+	cfg.VerifyDeterminism = ea.verifyDeterm
+	cfg.WriteReport = ea.writeReport
+	cfg.AttachSettings = ea.attachSettings
+	cfg.OutputTemplate = ea.outputTemplate
+	cfg.PreserveTimes = ea.preserveTimes || ea.preserveAtime
+	cfg.PreserveAccessTime = ea.preserveAtime
+	cfg.Checksum = strings.ToLower(ea.checksum)
+	cfg.DuplicatePolicy = ea.duplicates
+	if ea.failFast && ea.continueOnError {
+		return fmt.Errorf("--fail-fast and --continue cannot be used together")
+	}
+	cfg.FailFast = ea.failFast
+	if ea.abortLarger != "" {
+		ratio, err := parseSizeRatio(ea.abortLarger)
+		if err != nil {
+			return err
+		}
+		cfg.AbortSizeRatio = ratio
+	}
+	if ea.schedule != "" {
+		window, err := util.ParseWindow(ea.schedule)
+		if err != nil {
+			return err
+		}
+		cfg.Schedule = &window
+	}
+	if ea.deadline != "" && ea.maxRuntime != "" {
+		return fmt.Errorf("--deadline and --max-runtime cannot be used together")
+	}
+	if ea.deadline != "" {
+		d, err := time.ParseDuration(ea.deadline)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid deadline %q: expected a positive duration such as 8h or 90m", ea.deadline)
+		}
+		cfg.Deadline = time.Now().Add(d)
+		cfg.DeadlineChunks = ea.deadlineChunks
+	}
+	if ea.maxRuntime != "" {
+		d, err := time.ParseDuration(ea.maxRuntime)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid max runtime %q: expected a positive duration such as 6h or 90m", ea.maxRuntime)
+		}
+		cfg.Deadline = time.Now().Add(d)
+		cfg.DeadlineChunks = true
+	}
+	cfg.MaxFilesPerRun = ea.maxFiles
+	cfg.EncodeCooldownSecs = ea.cooldown
+	if ea.tempDir != "" {
+		cfg.TempDir = ea.tempDir
+	}
+	if cfg.TempDir != "" {
+		tempDir, err := filepath.Abs(cfg.TempDir)
+		if err != nil {
+			return fmt.Errorf("invalid temp directory: %w", err)
+		}
+		if err := util.EnsureDirectoryWritable(tempDir); err != nil {
+			return fmt.Errorf("invalid temp directory: %w", err)
+		}
+		cfg.TempDir = tempDir
+	}
+	cfg.ScratchLocation = ea.scratch
+	cfg.StageLocal = ea.stageLocal
 
 	// Debug options
 	cfg.Verbose = ea.verbose
@@ -251,10 +810,46 @@ func executeEncode(ea encodeArgs) error {
 	// Log configuration
 	if logger != nil {
 		logger.Info("Output directory: %s", outputDir)
+		if cfg.Profile != "" {
+			logger.Info("Profile: %s", cfg.Profile)
+		}
 		logger.Info("CRF quality: SD=%d, HD=%d, UHD=%d", cfg.CRFSD, cfg.CRFHD, cfg.CRFUHD)
-		logger.Info("SVT-AV1 preset: %d", cfg.SVTAV1Preset)
+		logger.Info("SVT-AV1 preset: SD=%d, HD=%d, UHD=%d", cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD)
 		logger.Info("Crop mode: %s", cfg.CropMode)
+		logger.Info("Content type: %s", cfg.ContentType)
+		logger.Info("Temp directory: %s", cfg.GetTempDir())
+		if cfg.Schedule != nil {
+			logger.Info("Schedule window: %s", cfg.Schedule)
+		}
+		if !cfg.Deadline.IsZero() {
+			logger.Info("Deadline: %s", cfg.Deadline.Format("2006-01-02 15:04"))
+		}
+		if cfg.MaxFilesPerRun > 0 {
+			logger.Info("Max files this run: %d", cfg.MaxFilesPerRun)
+		}
+		if cfg.AbortSizeRatio > 0 {
+			logger.Info("Abort if projected output exceeds %gx source size", cfg.AbortSizeRatio)
+		}
 		logger.Info("Parallel encoding: workers=%d, buffer=%d, threads/worker=%d", cfg.Workers, cfg.ChunkBuffer, cfg.ThreadsPerWorker)
+		if cfg.DisableMemoryCap {
+			logger.Info("Memory cap: disabled")
+		} else if cfg.MemPerWorker > 0 {
+			logger.Info("Memory per worker: %s", util.FormatBytes(cfg.MemPerWorker))
+		}
+		for _, tier := range []struct {
+			name     string
+			override config.ParallelOverride
+		}{
+			{"SD", cfg.ParallelSD}, {"HD", cfg.ParallelHD}, {"UHD", cfg.ParallelUHD},
+		} {
+			if tier.override.IsSet() {
+				logger.Info("Parallel override (%s): workers=%d, buffer=%d, threads/worker=%d",
+					tier.name, tier.override.Workers, tier.override.Buffer, tier.override.Threads)
+			}
+		}
+		if len(cfg.CopyDestinations) > 0 {
+			logger.Info("Copy destinations: %s", strings.Join(cfg.CopyDestinations, ", "))
+		}
 	}
 
 	// Create reporters
@@ -262,29 +857,207 @@ func executeEncode(ea encodeArgs) error {
 	var rep reporter.Reporter = termRep
 	if logger != nil {
 		// Combine terminal and log reporter so all events go to both
-		logRep := reporter.NewLogReporter(logger.Writer())
+		var logRep reporter.Reporter = reporter.NewLogReporter(logger.Writer())
+		if logger.JSON() {
+			logRep = reporter.NewJSONLogReporter(logger.Writer())
+		}
 		rep = reporter.NewCompositeReporter(termRep, logRep)
 	}
+	if ea.notifyDesktop {
+		notifyRep, err := reporter.NewDesktopNotifyReporter()
+		if err != nil {
+			return fmt.Errorf("--notify-desktop: %w", err)
+		}
+		defer notifyRep.Wait()
+		rep = reporter.NewCompositeReporter(rep, notifyRep)
+	}
 
-	// Setup context with signal handling
-	ctx, cancel := context.WithCancel(context.Background())
+	// Setup context with signal handling. The first SIGINT or SIGTERM stops
+	// new chunks and lets running ones finish so a rerun resumes after them;
+	// a second stops them too.
+	chunkCtx, cancelChunks := context.WithCancel(context.Background())
+	defer cancelChunks()
+	ctx, cancel := context.WithCancel(chunkCtx)
 	defer cancel()
+	ctx = encode.WithFinishInFlight(ctx, chunkCtx)
 
-	sigCh := make(chan os.Signal, 1)
+	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
+		rep.Warning("Stopping: finishing running chunks so they are kept for resuming (interrupt again to stop now)")
 		cancel()
+		<-sigCh
+		cancelChunks()
+	}()
+
+	// SIGHUP toggles verbose output so a long encode can be inspected without restarting
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+	go func() {
+		for range hupCh {
+			if termRep.IsVerbose() {
+				rep.Verbose("Verbose output disabled (SIGHUP)")
+				termRep.SetVerbose(false)
+				logger.SetVerbose(false)
+			} else {
+				termRep.SetVerbose(true)
+				logger.SetVerbose(true)
+				rep.Verbose("Verbose output enabled (SIGHUP)")
+			}
+		}
 	}()
 
+	for _, e := range excluded {
+		rep.Verbose(fmt.Sprintf("Excluded %s: %s", filepath.Base(e.Path), e.Reason))
+	}
+
+	// Encode the main title of a disc folder
+	if disc != nil {
+		titlePath, err := prepareDisc(ctx, disc, cfg, rep, logger)
+		if err != nil {
+			return err
+		}
+		inputPath, filesToProcess = titlePath, []string{titlePath}
+	}
+
 	// Run encoding
-	_, err = processing.ProcessVideos(ctx, cfg, filesToProcess, targetFilename, rep)
-	return err
+	var outputOverrides map[string]string
+	if targetFilename != "" {
+		outputOverrides = map[string]string{inputPath: targetFilename}
+	}
+	results, failures, err := processing.ProcessVideos(ctx, cfg, filesToProcess, outputOverrides, rep)
+	if err != nil {
+		return err
+	}
+	if disc != nil && len(results) == 1 {
+		if err := os.RemoveAll(processing.DiscTitleDir(disc, cfg.GetTempDir())); err != nil {
+			rep.Warning(fmt.Sprintf("Failed to remove the prepared main title: %v", err))
+		}
+	}
+	if ctx.Err() != nil {
+		return processing.ErrCancelled
+	}
+	return batchError(results, failures)
+}
+
+// applyTuningFlags applies SVT-AV1 tuning flags given on the command line.
+// A variance boost strength or octile implies --variance-boost.
+func applyTuningFlags(cfg *config.Config, ea *encodeArgs) error {
+	if ea.explicit["tune"] {
+		if ea.tune > 4 {
+			return fmt.Errorf("--tune must be 0-4, got %d", ea.tune)
+		}
+		cfg.SVTAV1Tune = uint8(ea.tune)
+		cfg.TuneExplicit = true
+	}
+	if ea.explicit["ac-bias"] {
+		if ea.acBias < 0 || ea.acBias > 8 {
+			return fmt.Errorf("--ac-bias must be 0-8, got %g", ea.acBias)
+		}
+		cfg.SVTAV1ACBias = float32(ea.acBias)
+		cfg.ACBiasExplicit = true
+	}
+	if ea.explicit["variance-boost"] {
+		cfg.SVTAV1EnableVarianceBoost = ea.varianceBoost
+	}
+	if ea.explicit["variance-strength"] {
+		if ea.varianceStr < 1 || ea.varianceStr > 4 {
+			return fmt.Errorf("--variance-strength must be 1-4, got %d", ea.varianceStr)
+		}
+		cfg.SVTAV1VarianceBoostStrength = uint8(ea.varianceStr)
+		cfg.SVTAV1EnableVarianceBoost = true
+	}
+	if ea.explicit["variance-octile"] {
+		if ea.varianceOctile < 1 || ea.varianceOctile > 8 {
+			return fmt.Errorf("--variance-octile must be 1-8, got %d", ea.varianceOctile)
+		}
+		cfg.SVTAV1VarianceOctile = uint8(ea.varianceOctile)
+		cfg.SVTAV1EnableVarianceBoost = true
+	}
+	if ea.explicit["keyint"] {
+		if ea.keyint < 1 || ea.keyint > 30 {
+			return fmt.Errorf("--keyint must be 1-30 seconds, got %g", ea.keyint)
+		}
+		cfg.KeyintSecs = ea.keyint
+	}
+	if ea.tiles != "" {
+		rows, columns, err := config.ParseTiles(ea.tiles)
+		if err != nil {
+			return fmt.Errorf("--tiles: %w", err)
+		}
+		cfg.SVTAV1TileRows, cfg.SVTAV1TileColumns = rows, columns
+	}
+	if ea.noSCD {
+		cfg.SVTAV1SceneDetection = false
+	}
+	if ea.bitDepth != "" {
+		cfg.BitDepth = strings.ToLower(ea.bitDepth)
+	}
+	return nil
+}
+
+// clearParallelOverrides drops config file parallelism overrides for any
+// value given explicitly on the command line, so flags always win.
+func clearParallelOverrides(cfg *config.Config, explicit map[string]bool) {
+	for _, p := range []*config.ParallelOverride{&cfg.ParallelSD, &cfg.ParallelHD, &cfg.ParallelUHD} {
+		if explicit["workers"] {
+			p.Workers = 0
+		}
+		if explicit["threads"] {
+			p.Threads = 0
+		}
+		if explicit["buffer"] {
+			p.Buffer = 0
+		}
+	}
+}
+
+// outputPerms builds the output permissions from the --chmod and --chown flags.
+func outputPerms(ea *encodeArgs) (util.OutputPerms, error) {
+	var perms util.OutputPerms
+	if ea.chmod != "" {
+		mode, err := util.ParseFileMode(ea.chmod)
+		if err != nil {
+			return perms, fmt.Errorf("--chmod: %w", err)
+		}
+		perms.Mode = mode
+	}
+	if ea.chown != "" {
+		uid, gid, err := util.ParseOwner(ea.chown)
+		if err != nil {
+			return perms, fmt.Errorf("--chown: %w", err)
+		}
+		perms.Chown, perms.UID, perms.GID = true, uid, gid
+	}
+	return perms, nil
+}
+
+// discoveryFilter builds the discovery filter from the --include, --exclude,
+// --min-size and --min-duration flags.
+func discoveryFilter(ea *encodeArgs) (discovery.Filter, error) {
+	f := discovery.Filter{Include: ea.include, Exclude: ea.exclude}
+	if ea.minSize != "" {
+		size, err := util.ParseBytes(ea.minSize)
+		if err != nil {
+			return f, fmt.Errorf("--min-size: %w", err)
+		}
+		f.MinSize = size
+	}
+	if ea.minDuration != "" {
+		d, err := time.ParseDuration(ea.minDuration)
+		if err != nil || d <= 0 {
+			return f, fmt.Errorf("invalid --min-duration %q: expected a positive duration such as 10m", ea.minDuration)
+		}
+		f.MinDuration = d
+	}
+	return f, f.Validate()
 }
 
 // resolveOutputPath determines the output directory and optional target filename.
 // If input is a file and output has a video extension, treat output as target filename.
-func resolveOutputPath(_, outputPath string, isInputDir bool) (outputDir, targetFilename string, err error) {
+func resolveOutputPath(outputPath string, isInputDir bool, extensions []string) (outputDir, targetFilename string, err error) {
 	outputPath, err = filepath.Abs(outputPath)
 	if err != nil {
 		return "", "", fmt.Errorf("invalid output path: %w", err)
@@ -296,13 +1069,7 @@ func resolveOutputPath(_, outputPath string, isInputDir bool) (outputDir, target
 	}
 
 	// Check if output path looks like a file (has video extension)
-	ext := filepath.Ext(outputPath)
-	videoExtensions := map[string]bool{
-		".mkv": true, ".mp4": true, ".webm": true,
-		".avi": true, ".mov": true, ".m4v": true,
-	}
-
-	if videoExtensions[ext] {
+	if util.HasVideoExtension(outputPath, extensions) {
 		// Output is a target filename
 		return filepath.Dir(outputPath), filepath.Base(outputPath), nil
 	}
@@ -345,3 +1112,37 @@ func parseCRF(crfStr string, cfg *config.Config) error {
 
 	return nil
 }
+
+// parsePreset parses the preset string and applies it to the config.
+// Accepts either a single value (applied to all resolutions) or a comma-separated triple (SD,HD,UHD).
+func parsePreset(presetStr string, cfg *config.Config) error {
+	parts := strings.Split(presetStr, ",")
+	vals := make([]uint8, len(parts))
+	for i, part := range parts {
+		val, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid preset value %q: %w", part, err)
+		}
+		vals[i] = uint8(val)
+	}
+
+	switch len(vals) {
+	case 1:
+		cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD = vals[0], vals[0], vals[0]
+	case 3:
+		cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD = vals[0], vals[1], vals[2]
+	default:
+		return fmt.Errorf("--preset accepts single value or comma-separated triple (SD,HD,UHD), got %d values", len(parts))
+	}
+
+	return nil
+}
+
+// parseSizeRatio parses a size ratio such as "0.9x" or "0.9".
+func parseSizeRatio(s string) (float64, error) {
+	ratio, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "x"), 64)
+	if err != nil || ratio <= 0 {
+		return 0, fmt.Errorf("invalid size ratio %q: want a positive number like 0.9x", s)
+	}
+	return ratio, nil
+}
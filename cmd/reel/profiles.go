@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/five82/reel/internal/config"
+)
+
+func runProfiles(args []string) error {
+	fs := flag.NewFlagSet("profiles", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `List settings profiles and their effective parameters.
+
+Usage:
+  %s profiles [options]
+
+Shows the built-in profiles and any defined under [profiles.<name>] in the
+config file, with the settings each one results in.
+
+Options:
+  -c, --config <PATH>    Config file (defaults to ~/.config/reel/config.toml)
+`, appName)
+	}
+
+	var configPath string
+	fs.StringVar(&configPath, "c", "", "Config file")
+	fs.StringVar(&configPath, "config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	base := config.NewConfig("", "", "")
+	path := configPath
+	if path == "" {
+		path = config.DefaultConfigPath()
+	}
+	if path != "" {
+		if err := base.LoadFile(path, configPath != ""); err != nil {
+			return err
+		}
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	bold := color.New(color.Bold)
+	dim := color.New(color.Faint)
+	label := func(name, value string) {
+		fmt.Printf("  %s %s\n", bold.Sprintf("%-18s", name), value)
+	}
+
+	for _, name := range base.ProfileNames() {
+		cfg := *base
+		if err := cfg.ApplyProfile(name); err != nil {
+			return err
+		}
+
+		fmt.Println()
+		title := cyan.Sprint(name)
+		if base.IsCustomProfile(name) {
+			title += dim.Sprint(" (config file)")
+		}
+		fmt.Println(title)
+		if desc := base.ProfileDescription(name); desc != "" {
+			label("Description:", desc)
+		}
+		label("CRF:", fmt.Sprintf("SD=%d, HD=%d, UHD=%d", cfg.CRFSD, cfg.CRFHD, cfg.CRFUHD))
+		label("Preset:", fmt.Sprintf("SD=%d, HD=%d, UHD=%d", cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD))
+		label("Tune:", fmt.Sprintf("%d", cfg.SVTAV1Tune))
+		label("AC bias:", fmt.Sprintf("%g", cfg.SVTAV1ACBias))
+		if cfg.SVTAV1EnableVarianceBoost {
+			label("Variance boost:", fmt.Sprintf("strength %d, octile %d",
+				cfg.SVTAV1VarianceBoostStrength, cfg.SVTAV1VarianceOctile))
+		} else {
+			label("Variance boost:", "off")
+		}
+		switch {
+		case cfg.FilmGrain == 0:
+			label("Film grain:", "off")
+		case cfg.FilmGrainDenoise:
+			label("Film grain:", fmt.Sprintf("%d, denoised", cfg.FilmGrain))
+		default:
+			label("Film grain:", fmt.Sprintf("%d", cfg.FilmGrain))
+		}
+		label("Keyframes:", fmt.Sprintf("every %gs", cfg.KeyintSecs))
+		label("Crop:", cfg.CropMode)
+		if cfg.Deinterlace {
+			label("Deinterlace:", "interlaced sources")
+		}
+		if cfg.AssumeRec601 {
+			label("Untagged color:", "Rec.601 (SD)")
+		}
+		if cfg.AudioKbpsPerChannel > 0 {
+			label("Audio:", fmt.Sprintf("%d kbps per channel", cfg.AudioKbpsPerChannel))
+		}
+	}
+	return nil
+}
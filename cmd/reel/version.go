@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/five82/reel"
+)
+
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Print version information.
+
+Usage:
+  %s version [options]
+
+Options:
+  --json    Print the version, supported features and detected tool versions
+              as JSON, for scripts and orchestrators
+`, appName)
+	}
+
+	var asJSON bool
+	fs.BoolVar(&asJSON, "json", false, "Print version, features and tool versions as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !asJSON {
+		fmt.Printf("%s version %s\n", appName, appVersion)
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reel.BuildInfo())
+}
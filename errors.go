@@ -0,0 +1,54 @@
+package reel
+
+import (
+	"errors"
+
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/processing"
+	"github.com/five82/reel/internal/tools"
+)
+
+// Errors returned by Encode and recorded in BatchResult.Failures.
+// Test for them with errors.Is and errors.As.
+var (
+	// ErrDependencyMissing means SvtAv1EncApp, ffmpeg or mediainfo is missing,
+	// failed to run, or is older than the supported minimum.
+	ErrDependencyMissing = tools.ErrDependencyMissing
+	// ErrValidationFailed means the output was written but failed validation.
+	ErrValidationFailed = processing.ErrValidationFailed
+	// ErrCancelled means the context was cancelled. The context error is
+	// also wrapped, so errors.Is(err, context.Canceled) works too.
+	ErrCancelled = processing.ErrCancelled
+	// ErrOutputExists means the output file already exists and was left alone.
+	ErrOutputExists = processing.ErrOutputExists
+	// ErrNoVideo means the input has no encodable video stream.
+	ErrNoVideo = ffprobe.ErrNoVideo
+	// ErrNotAttempted means the input was not started because a fail-fast
+	// batch stopped at an earlier failure.
+	ErrNotAttempted = processing.ErrNotAttempted
+	// ErrVariableResolution means the video changes resolution mid-stream,
+	// which the encode pipeline doesn't support.
+	ErrVariableResolution = processing.ErrVariableResolution
+	// ErrFinishedEarlier means the input was skipped because an earlier,
+	// interrupted run of the same batch already finished it.
+	ErrFinishedEarlier = processing.ErrFinishedEarlier
+	// ErrDeferred means the input was not started, or not finished, before
+	// the deadline or the run's file limit. Chunks already encoded are kept
+	// for the next run.
+	ErrDeferred = processing.ErrDeferred
+)
+
+// ErrStopEncoding can be returned by an EventHandler to cancel the encode.
+// The resulting errors wrap both the handler's error and ErrCancelled.
+var ErrStopEncoding = errors.New("stop requested by event handler")
+
+// FileError records why an input was not encoded, or failed validation.
+type FileError = processing.FileError
+
+// ChunkEncodeError reports the index of a chunk that failed to encode.
+type ChunkEncodeError = encode.ChunkEncodeError
+
+// SizeAbortError means the encode was stopped because the projected output
+// exceeded the configured fraction of the source size.
+type SizeAbortError = processing.SizeAbortError
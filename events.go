@@ -8,10 +8,14 @@ const (
 	EventTypeHardware           = "hardware"
 	EventTypeInitialization     = "initialization"
 	EventTypeStageProgress      = "stage_progress"
+	EventTypeTaskProgress       = "task_progress"
 	EventTypeEncodingStarted    = "encoding_started"
 	EventTypeEncodingConfig     = "encoding_config"
+	EventTypeEncodeEstimate     = "encode_estimate"
 	EventTypeCropResult         = "crop_result"
 	EventTypeEncodingProgress   = "encoding_progress"
+	EventTypeChunkRetry         = "chunk_retry"
+	EventTypeWorkerCap          = "worker_cap"
 	EventTypeValidationComplete = "validation_complete"
 	EventTypeEncodingComplete   = "encoding_complete"
 	EventTypeOperationComplete  = "operation_complete"
@@ -37,6 +41,100 @@ type BaseEvent struct {
 func (e BaseEvent) Type() string     { return e.EventType }
 func (e BaseEvent) Timestamp() int64 { return e.Time }
 
+// HardwareEvent describes the host, once per Encode or EncodeBatch call.
+type HardwareEvent struct {
+	BaseEvent
+	Hostname             string `json:"hostname"`
+	CPUModel             string `json:"cpu_model"` // Empty if unknown
+	PhysicalCores        int    `json:"physical_cores"`
+	LogicalCores         int    `json:"logical_cores"`
+	TotalMemoryBytes     uint64 `json:"total_memory_bytes"`     // 0 if unknown
+	AvailableMemoryBytes uint64 `json:"available_memory_bytes"` // 0 if unknown
+	OS                   string `json:"os"`
+	SvtVersion           string `json:"svt_version"` // SvtAv1EncApp version, or "not found"
+	FFmpegVersion        string `json:"ffmpeg_version"`
+	NUMANodes            int    `json:"numa_nodes"`     // 0 if unknown
+	NUMA                 string `json:"numa,omitempty"` // Worker pinning, set when there is more than one node
+}
+
+// BatchStartedEvent is emitted before the first file of a batch of more
+// than one file.
+type BatchStartedEvent struct {
+	BaseEvent
+	TotalFiles int      `json:"total_files"`
+	FileList   []string `json:"file_list"` // Filenames in encoding order
+	OutputDir  string   `json:"output_dir"`
+}
+
+// FileProgressEvent is emitted when a file of a batch starts. CurrentFile
+// counts from 1.
+type FileProgressEvent struct {
+	BaseEvent
+	CurrentFile int `json:"current_file"`
+	TotalFiles  int `json:"total_files"`
+}
+
+// InitializationEvent describes the file about to be encoded. In a batch the
+// events that follow, up to its EncodingCompleteEvent, belong to InputFile
+// (unless files encode in parallel).
+type InitializationEvent struct {
+	BaseEvent
+	InputFile        string `json:"input_file"`
+	OutputFile       string `json:"output_file"`
+	Duration         string `json:"duration"`
+	Resolution       string `json:"resolution"`
+	DynamicRange     string `json:"dynamic_range"`
+	AudioDescription string `json:"audio_description"`
+	ObjectAudio      string `json:"object_audio,omitempty"`
+}
+
+// StageProgressEvent reports which stage a job is in, such as "Preparing",
+// "Chunking", "Encoding", "Merging", "Muxing", "Waiting" or "Paused".
+type StageProgressEvent struct {
+	BaseEvent
+	Stage      string  `json:"stage"`
+	Percent    float32 `json:"percent"`
+	Message    string  `json:"message"`
+	ETASeconds int64   `json:"eta_seconds,omitempty"` // 0 when unknown
+}
+
+// TaskProgressEvent reports how far an analysis task has got: "Indexing"
+// in bytes, "Crop detection" in samples or "Audio extraction" in seconds of
+// source. Like EncodingProgressEvent, all but the last may be dropped by a
+// slow asynchronous handler.
+type TaskProgressEvent struct {
+	BaseEvent
+	Task    string  `json:"task"`
+	Done    uint64  `json:"done"`
+	Total   uint64  `json:"total"`
+	Unit    string  `json:"unit"`
+	Percent float64 `json:"percent"`
+}
+
+// CropResultEvent reports the crop chosen for the video.
+type CropResultEvent struct {
+	BaseEvent
+	Message  string `json:"message"`
+	Crop     string `json:"crop"`     // ffmpeg crop filter, empty when not cropping
+	Required bool   `json:"required"` // Black bars were found and will be cropped
+	Disabled bool   `json:"disabled"` // Crop detection was turned off
+}
+
+// EncodingConfigEvent reports the encoder settings chosen for the video.
+type EncodingConfigEvent struct {
+	BaseEvent
+	Encoder            string `json:"encoder"`
+	Preset             string `json:"preset"`
+	Tune               string `json:"tune"`
+	Quality            string `json:"quality"`
+	Content            string `json:"content"`
+	PixelFormat        string `json:"pixel_format"`
+	MatrixCoefficients string `json:"matrix_coefficients"`
+	AudioCodec         string `json:"audio_codec"`
+	AudioDescription   string `json:"audio_description"`
+	SVTAV1Params       string `json:"svtav1_params"`
+}
+
 // EncodingProgressEvent represents encoding progress updates.
 type EncodingProgressEvent struct {
 	BaseEvent
@@ -44,6 +142,58 @@ type EncodingProgressEvent struct {
 	Speed      float32 `json:"speed"`
 	FPS        float32 `json:"fps"`
 	ETASeconds int64   `json:"eta_seconds"`
+	// Average video bitrate and extrapolated output size from completed chunks
+	BitrateKbps   float64 `json:"bitrate_kbps"`
+	ProjectedSize uint64  `json:"projected_size"`
+}
+
+// EncodingStartedEvent is emitted when chunk encoding begins. Resumed fields
+// are non-zero when an interrupted encode is being continued.
+type EncodingStartedEvent struct {
+	BaseEvent
+	TotalFrames    uint64  `json:"total_frames"`
+	TotalChunks    int     `json:"total_chunks"`
+	ResumedFrames  uint64  `json:"resumed_frames"`
+	ResumedChunks  int     `json:"resumed_chunks"`
+	ResumedPercent float32 `json:"resumed_percent"`
+}
+
+// EncodeEstimateEvent represents the projected result of an encode,
+// extrapolated from probe chunks before the main encode starts.
+type EncodeEstimateEvent struct {
+	BaseEvent
+	OriginalSize     uint64  `json:"original_size"`
+	EstimatedSize    uint64  `json:"estimated_size"`
+	BitrateKbps      float64 `json:"bitrate_kbps"`
+	EstimatedSeconds int64   `json:"estimated_seconds"`
+	ProbeChunks      int     `json:"probe_chunks"`
+}
+
+// ChunkRetryEvent is emitted when a chunk whose encoder was killed (for
+// example out of memory) is retried.
+type ChunkRetryEvent struct {
+	BaseEvent
+	Chunk        int    `json:"chunk"`
+	Attempt      int    `json:"attempt"`
+	Retries      int    `json:"retries"`
+	Threads      int    `json:"threads"`
+	DelaySeconds int64  `json:"delay_seconds"`
+	Reason       string `json:"reason"`
+}
+
+// WorkerCapEvent reports how many encoder workers fit in available memory.
+// It is emitted when chunk encoding starts, and again as the encode scales
+// its workers: with Raised set when more start, Lowered when memory
+// pressure retires some.
+type WorkerCapEvent struct {
+	BaseEvent
+	Requested            int    `json:"requested"`
+	Granted              int    `json:"granted"`
+	MemPerWorkerBytes    uint64 `json:"mem_per_worker_bytes"`   // Estimated, measured once scaling
+	AvailableMemoryBytes uint64 `json:"available_memory_bytes"` // Measured, 0 if unknown
+	Capped               bool   `json:"capped"`
+	Raised               bool   `json:"raised"`
+	Lowered              bool   `json:"lowered"`
 }
 
 // ValidationCompleteEvent represents validation completion.
@@ -53,11 +203,16 @@ type ValidationCompleteEvent struct {
 	ValidationSteps  []ValidationStep `json:"validation_steps"`
 }
 
-// ValidationStep represents a single validation check.
+// ValidationStep represents a single validation check. Check and Code are
+// stable identifiers (e.g. "duration" / "duration_mismatch"); Details is
+// human-readable text that may change between releases.
 type ValidationStep struct {
-	Step    string `json:"step"`
-	Passed  bool   `json:"passed"`
-	Details string `json:"details"`
+	Step    string         `json:"step"`
+	Check   string         `json:"check"`
+	Code    string         `json:"code"`
+	Passed  bool           `json:"passed"`
+	Details string         `json:"details"`
+	Params  map[string]any `json:"params,omitempty"`
 }
 
 // EncodingCompleteEvent represents successful encode completion.
@@ -87,9 +242,27 @@ type ErrorEvent struct {
 // BatchCompleteEvent represents batch completion.
 type BatchCompleteEvent struct {
 	BaseEvent
-	SuccessfulCount           int     `json:"successful_count"`
-	TotalFiles                int     `json:"total_files"`
-	TotalSizeReductionPercent float64 `json:"total_size_reduction_percent"`
+	SuccessfulCount           int           `json:"successful_count"`
+	TotalFiles                int           `json:"total_files"`
+	TotalSizeReductionPercent float64       `json:"total_size_reduction_percent"`
+	SkippedCount              int           `json:"skipped_count"`
+	Skipped                   []SkippedFile `json:"skipped,omitempty"`
+	FailedCount               int           `json:"failed_count"`
+	Failed                    []FailedFile  `json:"failed,omitempty"`
+	DeferredCount             int           `json:"deferred_count"`
+	Deferred                  []string      `json:"deferred,omitempty"` // Filenames left for a later run by the deadline
+}
+
+// SkippedFile is an input skipped because it has no encodable video.
+type SkippedFile struct {
+	Filename string `json:"filename"`
+	Reason   string `json:"reason"`
+}
+
+// FailedFile is an input that produced no output for another reason.
+type FailedFile struct {
+	Filename string `json:"filename"`
+	Reason   string `json:"reason"`
 }
 
 // EventHandler is called with events during encoding.
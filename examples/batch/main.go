@@ -0,0 +1,79 @@
+// Command batch encodes several files or directories, passing events to a
+// separate goroutine over a channel so printing never holds up encoding.
+//
+//	go run ./examples/batch -o output/ /rips/disc1 /rips/disc2/title_t00.mkv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/five82/reel"
+)
+
+func main() {
+	outputDir := flag.String("o", ".", "Output directory")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: batch -o <output-dir> <input>...")
+		os.Exit(2)
+	}
+
+	// Events are delivered from reel's own goroutine; progress updates are
+	// dropped rather than waiting if the channel below falls behind
+	encoder, err := reel.New(reel.WithAsyncEvents(32))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	events := make(chan reel.Event, 32)
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		printEvents(events)
+	}()
+
+	batch, err := encoder.EncodeBatch(ctx, flag.Args(), *outputDir, func(event reel.Event) error {
+		events <- event
+		return nil
+	})
+	close(events)
+	<-printed
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("\n%d of %d files encoded, %.1f%% smaller overall\n",
+		batch.SuccessfulCount, batch.TotalFiles, batch.TotalSizeReduction)
+	for _, f := range batch.Failures {
+		fmt.Printf("  %s: %v\n", f.Input, f.Err)
+	}
+}
+
+// printEvents prints progress for the file being encoded until events is closed.
+func printEvents(events <-chan reel.Event) {
+	var file string
+	var current, total int
+	for event := range events {
+		switch e := event.(type) {
+		case reel.FileProgressEvent:
+			current, total = e.CurrentFile, e.TotalFiles
+		case reel.InitializationEvent:
+			file = e.InputFile
+			fmt.Printf("\n[%d/%d] %s (%s, %s)\n", current, total, file, e.Resolution, e.Duration)
+		case reel.EncodingProgressEvent:
+			fmt.Printf("\r  %5.1f%%  ETA %ds   ", e.Percent, e.ETASeconds)
+		case reel.EncodingCompleteEvent:
+			fmt.Printf("\r  done: %.1f%% smaller\n", e.SizeReductionPercent)
+		case reel.WarningEvent:
+			fmt.Printf("\n  warning: %s\n", e.Message)
+		}
+	}
+}
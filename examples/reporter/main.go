@@ -0,0 +1,65 @@
+// Command reporter encodes one file with a custom Reporter, which receives
+// every event with the library's full detail rather than the Event summaries.
+// Embedding reel.NullReporter means only the methods of interest need
+// implementing.
+//
+//	go run ./examples/reporter input.mkv output/
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/five82/reel"
+)
+
+// stageReporter prints each stage and the settings chosen for the file.
+type stageReporter struct {
+	reel.NullReporter
+}
+
+func (stageReporter) StageProgress(update reel.StageProgress) {
+	fmt.Printf("[%s] %s\n", strings.ToLower(update.Stage), update.Message)
+}
+
+func (stageReporter) EncodingConfig(summary reel.EncodingConfigSummary) {
+	fmt.Printf("encoding with %s preset %s, %s\n", summary.Encoder, summary.Preset, summary.Quality)
+}
+
+func (stageReporter) WorkerCap(workers reel.WorkerCap) {
+	if workers.Granted < workers.Requested {
+		fmt.Printf("memory allows %d of %d workers\n", workers.Granted, workers.Requested)
+	}
+}
+
+func (stageReporter) ValidationComplete(summary reel.ValidationSummary) {
+	for _, step := range summary.Steps {
+		if !step.Passed {
+			fmt.Printf("validation %s: %s\n", step.Code, step.Details)
+		}
+	}
+}
+
+func (stageReporter) Warning(message string) {
+	fmt.Println("warning:", message)
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: reporter <input> <output-dir>")
+		os.Exit(2)
+	}
+
+	encoder, err := reel.New(reel.WithPresetByResolution(4, 6, 8))
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := encoder.EncodeWithReporter(context.Background(), os.Args[1], os.Args[2], stageReporter{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %d -> %d bytes\n", result.OutputFile, result.OriginalSize, result.EncodedSize)
+}
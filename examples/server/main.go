@@ -0,0 +1,147 @@
+// Command server runs encodes for remote clients over HTTP, streaming job
+// updates from the spindle package as newline-delimited JSON. The same
+// program is also the client.
+//
+//	go run ./examples/server -listen :8080
+//	go run ./examples/server -server http://encoder:8080 /media/input.mkv /media/output
+//
+// Paths are on the server's filesystem. One encode runs at a time;
+// disconnecting the client cancels it.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/five82/reel"
+	"github.com/five82/reel/spindle"
+)
+
+// request is the body of POST /encode.
+type request struct {
+	Input     string `json:"input"`
+	OutputDir string `json:"output_dir"`
+	CRF       uint8  `json:"crf,omitempty"` // 0 = reel's defaults by resolution
+}
+
+// line is one line of the response stream: an update while encoding, then
+// the outcome.
+type line struct {
+	Update  *spindle.Update `json:"update,omitempty"`
+	Outcome spindle.Outcome `json:"outcome,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+func main() {
+	listen := flag.String("listen", "", "Serve encodes on this address (e.g. :8080)")
+	server := flag.String("server", "", "Send an encode to this server URL")
+	flag.Parse()
+
+	switch {
+	case *listen != "":
+		log.Fatal(serve(*listen))
+	case *server != "" && flag.NArg() == 2:
+		if err := encodeRemote(*server, flag.Arg(0), flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "usage: server -listen <addr> | server -server <url> <input> <output-dir>")
+		os.Exit(2)
+	}
+}
+
+func serve(addr string) error {
+	var busy sync.Mutex
+	http.HandleFunc("POST /encode", func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Input == "" || req.OutputDir == "" {
+			http.Error(w, "want JSON with input and output_dir", http.StatusBadRequest)
+			return
+		}
+		if !busy.TryLock() {
+			http.Error(w, "an encode is already running", http.StatusConflict)
+			return
+		}
+		defer busy.Unlock()
+
+		var opts []reel.Option
+		if req.CRF > 0 {
+			opts = append(opts, reel.WithCRF(req.CRF))
+		}
+		encoder, err := reel.New(opts...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		send := func(l line) {
+			_ = enc.Encode(l)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		adapter := spindle.NewAdapter(func(u spindle.Update) { send(line{Update: &u}) })
+		_, err = encoder.Encode(r.Context(), req.Input, req.OutputDir, adapter.Handler())
+		out := line{Outcome: spindle.Classify(err)}
+		if err != nil {
+			out.Error = err.Error()
+		}
+		send(out)
+	})
+	log.Printf("listening on %s", addr)
+	return http.ListenAndServe(addr, nil)
+}
+
+func encodeRemote(server, input, outputDir string) error {
+	body, err := json.Marshal(request{Input: input, OutputDir: outputDir})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(server+"/encode", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		return fmt.Errorf("server: %s: %s", resp.Status, msg)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return fmt.Errorf("bad response line: %w", err)
+		}
+		if u := l.Update; u != nil {
+			fmt.Printf("\r%-10s %5.1f%%  ETA %ds   ", u.Phase, u.Percent, u.ETASeconds)
+			if u.Level == spindle.LevelWarning || u.Level == spindle.LevelError {
+				fmt.Printf("\n%s: %s\n", u.Level, u.Message)
+			}
+			continue
+		}
+		fmt.Println()
+		if l.Outcome != spindle.OutcomeEncoded {
+			return fmt.Errorf("%s: %s", l.Outcome, l.Error)
+		}
+		fmt.Println("encoded")
+		return nil
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return errors.New("server closed the stream before the encode finished")
+}
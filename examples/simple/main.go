@@ -0,0 +1,58 @@
+// Command simple encodes one file with the reel library and prints progress.
+//
+//	go run ./examples/simple -crf 27 -preset 6 input.mkv output/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/five82/reel"
+)
+
+func main() {
+	crf := flag.Uint("crf", 27, "CRF quality level (0-63)")
+	preset := flag.Uint("preset", 6, "SVT-AV1 preset (0-13)")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: simple [-crf N] [-preset N] <input> <output-dir>")
+		os.Exit(2)
+	}
+
+	encoder, err := reel.New(
+		reel.WithCRF(uint8(*crf)),
+		reel.WithPreset(uint8(*preset)),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Ctrl-C cancels the encode; finished chunks are kept for resuming
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := encoder.Encode(ctx, flag.Arg(0), flag.Arg(1), func(event reel.Event) error {
+		switch e := event.(type) {
+		case reel.CropResultEvent:
+			fmt.Println(e.Message)
+		case reel.EncodingProgressEvent:
+			fmt.Printf("\r%5.1f%%  %.1f fps  ETA %ds   ", e.Percent, e.FPS, e.ETASeconds)
+		case reel.WarningEvent:
+			fmt.Printf("\nwarning: %s\n", e.Message)
+		}
+		return nil
+	})
+	fmt.Println()
+	switch {
+	case errors.Is(err, reel.ErrValidationFailed):
+		log.Fatalf("%s was written but failed validation: %v", result.OutputFile, err)
+	case err != nil:
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %.1f%% smaller\n", result.OutputFile, result.SizeReductionPercent)
+}
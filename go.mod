@@ -3,6 +3,7 @@ module github.com/five82/reel
 go 1.26.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/fatih/color v1.19.0
 	github.com/schollz/progressbar/v3 v3.19.0
 	golang.org/x/sync v0.20.0
@@ -2,125 +2,233 @@
 package chunk
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffmpeg"
 	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/opuslib"
+	"github.com/five82/reel/internal/toolpath"
+	"golang.org/x/sync/errgroup"
 )
 
-// ExtractAudio extracts audio streams from the source video.
-// The audio is encoded to Opus with bitrates determined by channel count.
-func ExtractAudio(inputPath, workDir string, audioStreams []ffprobe.AudioStreamInfo) error {
-	if len(audioStreams) == 0 {
-		return nil // No audio to extract
+// AudioSettings controls how ExtractAudio turns source streams into tracks.
+type AudioSettings struct {
+	KbpsPerChannel uint32   // Opus bitrate per channel (0 = by channel count)
+	Tempo          float64  // Time-stretch factor, keeping pitch (0 = unchanged)
+	Passthrough    []string // Lossless formats to copy instead of encoding
+	Preserve       []int    // Streams to copy whatever their format, by index
+	Downmix        *Downmix // Extra stereo track after the others (nil = none)
+}
+
+// tempo returns the time-stretch factor, 1 when unset.
+func (s AudioSettings) tempo() float64 {
+	if s.Tempo == 0 {
+		return 1
 	}
+	return s.Tempo
+}
 
-	audioPath := GetAudioPath(workDir)
+// Copies reports whether stream is copied rather than encoded. A copy can't
+// be time-stretched, so nothing is copied at another tempo.
+func (s AudioSettings) Copies(stream ffprobe.AudioStreamInfo) bool {
+	return s.tempo() == 1 &&
+		(slices.Contains(s.Passthrough, stream.LosslessFormat()) || slices.Contains(s.Preserve, stream.Index))
+}
 
-	args := []string{
-		"-hide_banner",
-		"-i", inputPath,
-		"-vn", // No video
-		"-map_metadata", "0",
+// ExtractAudio extracts audio streams from the source video.
+// The audio is encoded to Opus with bitrates determined by channel count,
+// or settings.KbpsPerChannel per channel when non-zero, except streams in a
+// passthrough format, which are copied. Each track is made by its own
+// ffmpeg, all at once, since libopus encodes on a single thread. A non-nil
+// onProgress is called with the seconds of source audio done so far,
+// averaged over the tracks.
+//
+// In builds with libopus linked, ffmpeg only decodes each encoded track to
+// PCM and reel encodes it in-process, measuring its integrated loudness in
+// the same pass. The loudness of those tracks, in LUFS, is returned by
+// track index.
+func ExtractAudio(paths toolpath.Paths, inputPath, workDir string, audioStreams []ffprobe.AudioStreamInfo, settings AudioSettings, onProgress func(seconds float64)) (map[int]float64, error) {
+	if len(audioStreams) == 0 {
+		return nil, nil // No audio to extract
 	}
 
-	// Map each audio stream and set encoding parameters
+	tempo := settings.tempo()
+	jobs := make([]audioJob, 0, len(audioStreams)+1)
 	for i, stream := range audioStreams {
-		args = append(args, "-map", fmt.Sprintf("0:a:%d", stream.Index))
-		args = append(args, fmt.Sprintf("-c:a:%d", i), "libopus")
-		bitrate := calculateAudioBitrate(stream.Channels)
-		args = append(args, fmt.Sprintf("-b:a:%d", i), fmt.Sprintf("%dk", bitrate))
-		args = append(args, fmt.Sprintf("-filter:a:%d", i), "aformat=channel_layouts=7.1|5.1|stereo|mono")
+		output := GetAudioStreamPath(workDir, i)
+		if settings.Copies(stream) {
+			jobs = append(jobs, audioJob{args: audioArgs(inputPath, output, stream.Index, "-c:a", "copy")})
+		} else {
+			jobs = append(jobs, audioStreamArgs(inputPath, output, stream, settings.KbpsPerChannel, tempo))
+		}
+	}
+	if d := settings.Downmix; d != nil {
+		jobs = append(jobs, downmixArgs(inputPath, GetAudioStreamPath(workDir, len(audioStreams)), *d, settings.KbpsPerChannel, tempo))
 	}
 
-	args = append(args, "-y", audioPath)
+	var mu sync.Mutex
+	done := make([]float64, len(jobs))
+	report := func(i int, seconds float64) {
+		if onProgress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done[i] = seconds
+		var sum float64
+		for _, s := range done {
+			sum += s
+		}
+		onProgress(sum / float64(len(done)))
+	}
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("audio extraction failed: %w\nOutput: %s", err, string(output))
+	loudness := make(map[int]float64)
+	var g errgroup.Group
+	for i, job := range jobs {
+		g.Go(func() error {
+			progress := func(seconds float64) { report(i, seconds) }
+			if job.output == "" {
+				if err := runAudioEncode(paths, job.args, tempo, progress); err != nil {
+					return fmt.Errorf("audio track %d: %w", i, err)
+				}
+				return nil
+			}
+			lufs, ok, err := runOpusEncode(paths, job, tempo, progress)
+			if err != nil {
+				return fmt.Errorf("audio track %d: %w", i, err)
+			}
+			if ok {
+				mu.Lock()
+				loudness[i] = lufs
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
+	return loudness, nil
+}
 
-	return nil
+// audioJob is how one track is made: by ffmpeg alone, or, when output is
+// set, by ffmpeg decoding to PCM for libopus in-process.
+type audioJob struct {
+	args     []string // ffmpeg arguments
+	output   string   // Track path, for in-process encoding
+	channels uint32
+	bitrate  uint32 // kbps
 }
 
-// calculateAudioBitrate returns audio bitrate in kbps based on channel count.
-func calculateAudioBitrate(channels uint32) uint32 {
-	switch channels {
-	case 1:
-		return 64 // Mono
-	case 2:
-		return 128 // Stereo
-	case 6:
-		return 256 // 5.1 surround
-	case 8:
-		return 384 // 7.1 surround
-	default:
-		return channels * 48 // ~48 kbps per channel for non-standard configs
-	}
+// audioStreamArgs returns the job that encodes one audio stream of the
+// source to Opus at outputPath.
+func audioStreamArgs(inputPath, outputPath string, stream ffprobe.AudioStreamInfo, kbpsPerChannel uint32, tempo float64) audioJob {
+	layout, channels, _ := OpusLayout(stream)
+	return opusJob(inputPath, outputPath, stream.Index, channels, ffmpeg.AudioBitrate(channels, kbpsPerChannel), audioFilter(layout, tempo))
 }
 
-// MuxFinal combines the encoded video with audio and other streams.
-func MuxFinal(inputPath, workDir, outputPath string, audioStreams []ffprobe.AudioStreamInfo) error {
-	videoPath := GetVideoPath(workDir)
-	audioPath := GetAudioPath(workDir)
+// downmixArgs returns the job that encodes d to stereo Opus at outputPath.
+func downmixArgs(inputPath, outputPath string, d Downmix, kbpsPerChannel uint32, tempo float64) audioJob {
+	return opusJob(inputPath, outputPath, d.Stream.Index, 2, ffmpeg.AudioBitrate(2, kbpsPerChannel),
+		downmixFilter(d.Stream.Channels, d.DialogueBoost, tempo))
+}
 
-	// Check if video exists
-	if _, err := os.Stat(videoPath); err != nil {
-		return fmt.Errorf("video file not found: %w", err)
+// opusJob returns the job that encodes audio stream index of the source to
+// Opus through filter, in-process when libopus is linked.
+func opusJob(inputPath, outputPath string, index int, channels, bitrate uint32, filter string) audioJob {
+	if opuslib.Linked {
+		return audioJob{args: pcmArgs(inputPath, index, filter), output: outputPath, channels: channels, bitrate: bitrate}
 	}
+	return audioJob{args: opusArgs(inputPath, outputPath, index, bitrate, filter)}
+}
 
+// opusArgs returns the ffmpeg arguments that encode audio stream index of
+// the source to Opus through filter.
+func opusArgs(inputPath, outputPath string, index int, bitrate uint32, filter string) []string {
+	return audioArgs(inputPath, outputPath, index,
+		"-c:a", "libopus",
+		"-b:a", fmt.Sprintf("%dk", bitrate),
+		"-filter:a", filter)
+}
+
+// audioArgs returns the ffmpeg arguments that write audio stream index of
+// the source to outputPath with the given codec arguments.
+func audioArgs(inputPath, outputPath string, index int, codec ...string) []string {
 	args := []string{
 		"-hide_banner",
-		"-i", videoPath, // Encoded video
+		"-nostats",
+		"-progress", "pipe:1",
+		"-i", inputPath,
+		"-vn", // No video
+		"-map_metadata", "0",
+		"-map", fmt.Sprintf("0:a:%d", index),
 	}
+	args = append(args, codec...)
+	return append(args, "-y", outputPath)
+}
 
-	// Add audio if it exists
-	hasAudio := false
-	if _, err := os.Stat(audioPath); err == nil && len(audioStreams) > 0 {
-		args = append(args, "-i", audioPath)
-		hasAudio = true
+// runAudioEncode runs ffmpeg with args, passing the seconds of source
+// encoded so far to onProgress.
+func runAudioEncode(paths toolpath.Paths, args []string, tempo float64, onProgress func(seconds float64)) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(paths.Path(toolpath.FFmpeg), args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("audio extraction failed: %w", err)
 	}
-
-	// Add original input for subtitles and chapters
-	args = append(args, "-i", inputPath)
-
-	// Map video
-	args = append(args, "-map", "0:v:0")
-
-	// Map audio if available
-	if hasAudio {
-		args = append(args, "-map", "1:a?")
+	proc, err := cmdlog.Start(cmd, nil)
+	if err != nil {
+		return fmt.Errorf("audio extraction failed: %w", err)
 	}
 
-	// Map subtitles from original
-	subtitleInputIdx := 2
-	if !hasAudio {
-		subtitleInputIdx = 1
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if secs, ok := parseOutTime(scanner.Text()); ok {
+			// Output time runs at the new tempo; report source time
+			onProgress(secs * tempo)
+		}
 	}
-	args = append(args, "-map", fmt.Sprintf("%d:s?", subtitleInputIdx))
 
-	// Copy all streams
-	args = append(args, "-c", "copy")
-
-	// Copy metadata and chapters
-	args = append(args, "-map_metadata", "0")
-	args = append(args, "-map_chapters", fmt.Sprintf("%d", subtitleInputIdx))
-
-	// Faststart for web playback
-	args = append(args, "-movflags", "+faststart")
+	if err := proc.Wait(); err != nil {
+		return fmt.Errorf("audio extraction failed: %w\nOutput: %s", err, stderr.String())
+	}
 
-	args = append(args, "-y", outputPath)
+	return nil
+}
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("final mux failed: %w\nOutput: %s", err, string(output))
+// parseOutTime reads the output position in seconds from a line of ffmpeg
+// -progress output. Despite its name, out_time_ms is in microseconds.
+func parseOutTime(line string) (float64, bool) {
+	value, ok := strings.CutPrefix(line, "out_time_us=")
+	if !ok {
+		return 0, false
+	}
+	us, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || us < 0 {
+		return 0, false // "N/A" before the first packet
 	}
+	return float64(us) / 1e6, true
+}
 
-	return nil
+// audioFilter returns the audio filter chain that remixes to layout. A tempo
+// other than 1 changes the speed without changing the pitch.
+func audioFilter(layout string, tempo float64) string {
+	format := "aformat=channel_layouts=" + layout
+	if tempo == 1 {
+		return format
+	}
+	return fmt.Sprintf("atempo=%.6f,%s", tempo, format)
 }
 
 // CleanupWorkDir removes the work directory and all its contents.
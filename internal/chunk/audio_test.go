@@ -0,0 +1,186 @@
+package chunk
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestAudioFilter(t *testing.T) {
+	tests := []struct {
+		tempo float64
+		want  string
+	}{
+		{1, "aformat=channel_layouts=5.1"},
+		{0.95904, "atempo=0.959040,aformat=channel_layouts=5.1"},
+	}
+
+	for _, tt := range tests {
+		if got := audioFilter("5.1", tt.tempo); got != tt.want {
+			t.Errorf("audioFilter(%v) = %q, want %q", tt.tempo, got, tt.want)
+		}
+	}
+}
+
+func TestParseOutTime(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   float64
+		wantOK bool
+	}{
+		{"out_time_us=12500000", 12.5, true},
+		{"out_time_us=N/A", 0, false},
+		{"out_time_us=-9223372036854775807", 0, false},
+		{"out_time_ms=12500000", 0, false},
+		{"progress=continue", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseOutTime(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseOutTime(%q) = %v, %v; want %v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAudioStreamArgs(t *testing.T) {
+	tests := []struct {
+		name           string
+		stream         ffprobe.AudioStreamInfo
+		kbpsPerChannel uint32
+		wantMap        string
+		wantBitrate    string
+	}{
+		{"surround by channel count", ffprobe.AudioStreamInfo{Index: 0, Channels: 8}, 0, "0:a:0", "384k"},
+		{"commentary per channel", ffprobe.AudioStreamInfo{Index: 2, Channels: 2}, 80, "0:a:2", "160k"},
+		{"2.1 remixed to stereo", ffprobe.AudioStreamInfo{Index: 1, Channels: 3, ChannelLayout: "2.1"}, 0, "0:a:1", "128k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := audioStreamArgs("in.mkv", "out.mka", tt.stream, tt.kbpsPerChannel, 1)
+			args := job.args
+			if i := slices.Index(args, "-map"); i < 0 || args[i+1] != tt.wantMap {
+				t.Errorf("args %v, want -map %s", args, tt.wantMap)
+			}
+			if job.output != "" {
+				// Encoded in-process; ffmpeg only decodes
+				if bitrate := fmt.Sprintf("%dk", job.bitrate); bitrate != tt.wantBitrate || job.output != "out.mka" {
+					t.Errorf("job %s to %s, want %s to out.mka", bitrate, job.output, tt.wantBitrate)
+				}
+				return
+			}
+			if i := slices.Index(args, "-b:a"); i < 0 || args[i+1] != tt.wantBitrate {
+				t.Errorf("args %v, want -b:a %s", args, tt.wantBitrate)
+			}
+			if args[len(args)-1] != "out.mka" {
+				t.Errorf("output = %s, want out.mka", args[len(args)-1])
+			}
+		})
+	}
+}
+
+func TestDownmixFilter(t *testing.T) {
+	tests := []struct {
+		channels uint32
+		boostDB  float64
+		tempo    float64
+		want     string
+	}{
+		{6, 0, 1, "aformat=channel_layouts=5.1,pan=stereo|FL<FL+0.707*FC+0.707*BL|FR<FR+0.707*FC+0.707*BR"},
+		{8, 6, 1, "aformat=channel_layouts=7.1,pan=stereo|FL<FL+1.411*FC+0.707*BL+0.707*SL|FR<FR+1.411*FC+0.707*BR+0.707*SR"},
+		{6, 0, 0.95904, "atempo=0.959040,aformat=channel_layouts=5.1,pan=stereo|FL<FL+0.707*FC+0.707*BL|FR<FR+0.707*FC+0.707*BR"},
+	}
+
+	for _, tt := range tests {
+		if got := downmixFilter(tt.channels, tt.boostDB, tt.tempo); got != tt.want {
+			t.Errorf("downmixFilter(%d, %g, %g) = %q, want %q", tt.channels, tt.boostDB, tt.tempo, got, tt.want)
+		}
+	}
+}
+
+func TestDownmixTitle(t *testing.T) {
+	if got := (Downmix{}).Title(); got != "Stereo downmix" {
+		t.Errorf("Title() = %q", got)
+	}
+	if got := (Downmix{DialogueBoost: 4.5}).Title(); got != "Stereo downmix (dialogue +4.5 dB)" {
+		t.Errorf("Title() with boost = %q", got)
+	}
+}
+
+func TestAudioSettingsCopies(t *testing.T) {
+	truehd := ffprobe.AudioStreamInfo{CodecName: "truehd", Channels: 8}
+	eac3 := ffprobe.AudioStreamInfo{CodecName: "eac3", Channels: 6}
+
+	tests := []struct {
+		name     string
+		settings AudioSettings
+		stream   ffprobe.AudioStreamInfo
+		want     bool
+	}{
+		{"listed format", AudioSettings{Passthrough: []string{"truehd"}}, truehd, true},
+		{"unlisted format", AudioSettings{Passthrough: []string{"flac"}}, truehd, false},
+		{"lossy stream", AudioSettings{Passthrough: []string{"truehd"}}, eac3, false},
+		{"time-stretched", AudioSettings{Passthrough: []string{"truehd"}, Tempo: 0.95904}, truehd, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.settings.Copies(tt.stream); got != tt.want {
+			t.Errorf("%s: Copies() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpusLayout(t *testing.T) {
+	tests := []struct {
+		source       string
+		channels     uint32
+		wantLayout   string
+		wantChannels uint32
+		wantRemapped bool
+	}{
+		{"stereo", 2, "stereo", 2, false},
+		{"5.1(side)", 6, "5.1", 6, false},
+		{"7.1(wide)", 8, "7.1", 8, false},
+		{"6.1", 7, "6.1", 7, false},
+		{"", 6, "5.1", 6, false},
+		{"unknown", 4, "quad", 4, false},
+		{"4.0", 4, "quad", 4, true},
+		{"quad(side)", 4, "quad", 4, false},
+		{"6.0", 6, "5.1", 6, true},
+		{"2.1", 3, "stereo", 2, true},
+		{"4.1", 5, "5.1", 6, true},
+		{"7.0", 7, "7.1", 8, true},
+		{"7.1.4", 12, "7.1", 8, true},
+		{"", 10, "7.1", 8, true},
+	}
+
+	for _, tt := range tests {
+		layout, channels, remapped := OpusLayout(ffprobe.AudioStreamInfo{Channels: tt.channels, ChannelLayout: tt.source})
+		if layout != tt.wantLayout || channels != tt.wantChannels || remapped != tt.wantRemapped {
+			t.Errorf("OpusLayout(%q, %d) = %s, %d, %v; want %s, %d, %v", tt.source, tt.channels,
+				layout, channels, remapped, tt.wantLayout, tt.wantChannels, tt.wantRemapped)
+		}
+	}
+}
+
+func TestVorbisOrder(t *testing.T) {
+	tests := []struct {
+		channels int
+		want     []int
+	}{
+		{2, []int{0, 1}},
+		{3, []int{0, 2, 1}},
+		{6, []int{0, 2, 1, 4, 5, 3}},
+		{7, []int{0, 2, 1, 5, 6, 4, 3}},
+		{8, []int{0, 2, 1, 6, 7, 4, 5, 3}},
+	}
+
+	for _, tt := range tests {
+		if got := vorbisOrder(tt.channels); !slices.Equal(got, tt.want) {
+			t.Errorf("vorbisOrder(%d) = %v, want %v", tt.channels, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package chunk
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+// baseFrameCost is the share of a frame's encode time that doesn't depend on
+// its content. The rest scales with the frame's size in the source, which
+// stands in for how hard it is to encode.
+const baseFrameCost = 0.5
+
+// FrameCosts estimates the relative encode time of each of frames frames from
+// the source's video packets, 1 being an average frame. Packets are placed by
+// timestamp at fps. Returns nil if there are no packets to go on.
+func FrameCosts(packets []ffprobe.PacketSample, fps float64, frames int) []float64 {
+	if len(packets) == 0 || frames <= 0 || fps <= 0 {
+		return nil
+	}
+
+	// Packets arrive in decode order
+	sorted := slices.SortedFunc(slices.Values(packets), func(a, b ffprobe.PacketSample) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	start := sorted[0].Time
+
+	sizes := make([]float64, frames)
+	var total float64
+	for _, p := range sorted {
+		frame := min(max(int(math.Round((p.Time-start)*fps)), 0), frames-1)
+		sizes[frame] += float64(p.Size)
+		total += float64(p.Size)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	mean := total / float64(frames)
+	costs := make([]float64, frames)
+	for i, size := range sizes {
+		costs[i] = baseFrameCost + (1-baseFrameCost)*size/mean
+	}
+	return costs
+}
+
+// PlanBalanced splits frames with the given costs into chunks of about equal
+// total cost, as many as chunks of targetFrames would make. Each chunk keeps
+// between half and twice targetFrames, so a run of static or busy scenes
+// doesn't make one tiny or one huge chunk. Returns the chunk start frames.
+func PlanBalanced(costs []float64, targetFrames int) []int {
+	frames := len(costs)
+	if frames == 0 || targetFrames < 1 {
+		return []int{0}
+	}
+
+	count := max(int(math.Round(float64(frames)/float64(targetFrames))), 1)
+	minFrames := max(targetFrames/2, 1)
+	maxFrames := targetFrames * 2
+
+	// prefix[i] is the cost of frames before i
+	prefix := make([]float64, frames+1)
+	for i, c := range costs {
+		prefix[i+1] = prefix[i] + c
+	}
+	share := prefix[frames] / float64(count)
+
+	starts := []int{0}
+	for k := 1; k < count; k++ {
+		start := starts[len(starts)-1]
+		end := sort.SearchFloat64s(prefix, share*float64(k))
+
+		// Stay within the size limits, leaving the remaining chunks room for theirs
+		left := count - k
+		lo := max(start+minFrames, frames-left*maxFrames)
+		hi := min(start+maxFrames, frames-left*minFrames)
+		end = min(max(end, lo), hi)
+		if end <= start || end >= frames {
+			break
+		}
+		starts = append(starts, end)
+	}
+	return starts
+}
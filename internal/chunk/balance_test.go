@@ -0,0 +1,81 @@
+package chunk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+// costRun returns n frames of cost c.
+func costRun(n int, c float64) []float64 {
+	return slices.Repeat([]float64{c}, n)
+}
+
+func TestPlanBalanced(t *testing.T) {
+	tests := []struct {
+		name   string
+		costs  []float64
+		target int
+		want   []int
+	}{
+		{
+			name:   "uniform cost matches fixed chunks",
+			costs:  costRun(1200, 1),
+			target: 300,
+			want:   []int{0, 300, 600, 900},
+		},
+		{
+			name:   "complex start gets shorter chunks",
+			costs:  slices.Concat(costRun(600, 3), costRun(600, 1)),
+			target: 300,
+			want:   []int{0, 200, 400, 600},
+		},
+		{
+			name:   "chunks keep at least half the target",
+			costs:  slices.Concat(costRun(100, 10), costRun(1100, 1)),
+			target: 300,
+			want:   []int{0, 150, 300, 675},
+		},
+		{
+			name:   "shorter than a chunk",
+			costs:  costRun(100, 1),
+			target: 300,
+			want:   []int{0},
+		},
+		{
+			name:   "no frames",
+			costs:  nil,
+			target: 300,
+			want:   []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlanBalanced(tt.costs, tt.target); !slices.Equal(got, tt.want) {
+				t.Errorf("PlanBalanced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameCosts(t *testing.T) {
+	// Decode order: the frame at 10.5s arrives after the one at 11.0s, and
+	// the last frame has no packet
+	packets := []ffprobe.PacketSample{
+		{Time: 10.0, Size: 400},
+		{Time: 11.0, Size: 200},
+		{Time: 10.5, Size: 200},
+	}
+
+	got := FrameCosts(packets, 2, 4)
+	want := []float64{1.5, 1, 1, 0.5}
+	if !slices.Equal(got, want) {
+		t.Errorf("FrameCosts() = %v, want %v", got, want)
+	}
+
+	if got := FrameCosts(nil, 24, 100); got != nil {
+		t.Errorf("FrameCosts(no packets) = %v, want nil", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -77,13 +78,18 @@ func LoadScenes(path string, totalFrames int) ([]Scene, error) {
 		frameNums = append([]int{0}, frameNums...)
 	}
 
-	// Convert to scenes
-	scenes := make([]Scene, 0, len(frameNums))
-	for i := 0; i < len(frameNums); i++ {
-		start := frameNums[i]
+	return ScenesFromStarts(frameNums, totalFrames), nil
+}
+
+// ScenesFromStarts converts sorted scene start frames to scenes, each ending
+// where the next starts and the last at totalFrames.
+func ScenesFromStarts(starts []int, totalFrames int) []Scene {
+	scenes := make([]Scene, 0, len(starts))
+	for i := 0; i < len(starts); i++ {
+		start := starts[i]
 		end := totalFrames
-		if i+1 < len(frameNums) {
-			end = frameNums[i+1]
+		if i+1 < len(starts) {
+			end = starts[i+1]
 		}
 
 		if start < end {
@@ -94,7 +100,7 @@ func LoadScenes(path string, totalFrames int) ([]Scene, error) {
 		}
 	}
 
-	return scenes, nil
+	return scenes
 }
 
 // ValidateScenes checks that scenes are valid and not too long.
@@ -212,6 +218,32 @@ func AppendDone(chunk ChunkComp, workDir string) error {
 	return nil
 }
 
+// ForgetDone removes the given chunks from the resume file, so they are
+// encoded again.
+func ForgetDone(workDir string, idxs []int) error {
+	resume, err := GetResume(workDir)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, c := range resume.ChunksDone {
+		if !slices.Contains(idxs, c.Idx) {
+			fmt.Fprintf(&b, "%d %d %d\n", c.Idx, c.Frames, c.Size)
+		}
+	}
+
+	donePath := filepath.Join(workDir, "done.txt")
+	tmp := donePath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write resume file: %w", err)
+	}
+	if err := os.Rename(tmp, donePath); err != nil {
+		return fmt.Errorf("failed to replace resume file: %w", err)
+	}
+	return nil
+}
+
 // DoneSet returns a set of completed chunk indices for quick lookup.
 func (r *ResumeInf) DoneSet() map[int]bool {
 	done := make(map[int]bool, len(r.ChunksDone))
@@ -221,6 +253,29 @@ func (r *ResumeInf) DoneSet() map[int]bool {
 	return done
 }
 
+// Valid returns the completed chunks that belong to the given chunk layout,
+// keeping the first entry for each index. Entries for unknown indices or with
+// a different frame count come from another layout and are dropped, so
+// resumed progress can never exceed the real total.
+func (r *ResumeInf) Valid(chunks []Chunk) *ResumeInf {
+	frames := make(map[int]int, len(chunks))
+	for _, c := range chunks {
+		frames[c.Idx] = c.Frames()
+	}
+
+	seen := make(map[int]bool, len(r.ChunksDone))
+	valid := make([]ChunkComp, 0, len(r.ChunksDone))
+	for _, c := range r.ChunksDone {
+		want, ok := frames[c.Idx]
+		if !ok || want != c.Frames || seen[c.Idx] {
+			continue
+		}
+		seen[c.Idx] = true
+		valid = append(valid, c)
+	}
+	return &ResumeInf{ChunksDone: valid}
+}
+
 // TotalEncodedSize returns the total size of all completed chunks.
 func (r *ResumeInf) TotalEncodedSize() uint64 {
 	var total uint64
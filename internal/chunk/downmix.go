@@ -0,0 +1,40 @@
+package chunk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+// Downmix describes an extra stereo track mixed from a surround stream, for
+// players and speakers that handle surround poorly.
+type Downmix struct {
+	Stream        ffprobe.AudioStreamInfo // Surround stream to mix down
+	DialogueBoost float64                 // Extra center channel gain in dB (0 = standard downmix)
+}
+
+// Title returns the track title the downmix is muxed with.
+func (d Downmix) Title() string {
+	if d.DialogueBoost > 0 {
+		return fmt.Sprintf("Stereo downmix (dialogue +%g dB)", d.DialogueBoost)
+	}
+	return "Stereo downmix"
+}
+
+// downmixFilter returns the filter chain that folds a surround stream down
+// to stereo with ITU-R BS.775 coefficients, the center raised by boostDB.
+// Gains are normalized so a boosted center doesn't clip.
+func downmixFilter(channels uint32, boostDB, tempo float64) string {
+	center := 0.707 * math.Pow(10, boostDB/20)
+	layout, left, right := "5.1", "FL+%.3f*FC+0.707*BL", "FR+%.3f*FC+0.707*BR"
+	if channels >= 7 {
+		layout, left, right = "7.1", left+"+0.707*SL", right+"+0.707*SR"
+	}
+
+	filter := fmt.Sprintf("aformat=channel_layouts=%s,pan=stereo|FL<"+left+"|FR<"+right, layout, center, center)
+	if tempo != 1 {
+		filter = fmt.Sprintf("atempo=%.6f,%s", tempo, filter)
+	}
+	return filter
+}
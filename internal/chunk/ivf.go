@@ -0,0 +1,155 @@
+package chunk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	ivfSignature       = "DKIF"
+	ivfHeaderSize      = 32 // Minimum file header size
+	ivfFrameHeaderSize = 12 // Frame size (4 bytes) and timestamp (8 bytes)
+)
+
+// IVFFrames counts the frames in an IVF file by walking its frame headers.
+// The frame count in the file header isn't trusted, since an encoder writing
+// a stream may never fill it in. A file cut off inside a frame is an error.
+func IVFFrames(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	header := make([]byte, ivfHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, fmt.Errorf("incomplete IVF header: %w", err)
+	}
+	if string(header[:4]) != ivfSignature {
+		return 0, fmt.Errorf("not an IVF file")
+	}
+	offset := int64(binary.LittleEndian.Uint16(header[6:8]))
+	if offset < ivfHeaderSize {
+		return 0, fmt.Errorf("invalid IVF header size %d", offset)
+	}
+
+	frames := 0
+	frameHeader := make([]byte, ivfFrameHeaderSize)
+	for offset < size {
+		if _, err := f.ReadAt(frameHeader, offset); err != nil {
+			if errors.Is(err, io.EOF) {
+				return frames, fmt.Errorf("truncated after %d frames", frames)
+			}
+			return frames, err
+		}
+		offset += ivfFrameHeaderSize + int64(binary.LittleEndian.Uint32(frameHeader[:4]))
+		if offset > size {
+			return frames, fmt.Errorf("truncated after %d frames", frames)
+		}
+		frames++
+	}
+	return frames, nil
+}
+
+// ChunkFault is a chunk whose IVF can't be merged as it is.
+type ChunkFault struct {
+	Idx    int
+	Reason string
+}
+
+// VerifyChunks checks the IVF of every chunk before merging: it must exist,
+// be non-empty, parse to the end and hold as many frames as the chunk. A
+// truncated chunk would otherwise only show up as a duration mismatch after
+// the merge and mux.
+func VerifyChunks(workDir string, chunks []Chunk) []ChunkFault {
+	var faults []ChunkFault
+	for _, ch := range chunks {
+		if reason := verifyChunk(IVFPath(workDir, ch.Idx), ch.Frames()); reason != "" {
+			faults = append(faults, ChunkFault{Idx: ch.Idx, Reason: reason})
+		}
+	}
+	return faults
+}
+
+// verifyChunk returns why the IVF at path doesn't hold the expected frames,
+// or "" when it does.
+func verifyChunk(path string, expected int) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "missing"
+	}
+	if info.Size() == 0 {
+		return "empty"
+	}
+	frames, err := IVFFrames(path)
+	if err != nil {
+		return err.Error()
+	}
+	if frames != expected {
+		return fmt.Sprintf("%d of %d frames", frames, expected)
+	}
+	return ""
+}
+
+// IVFWriter writes AV1 packets to an IVF file, as SvtAv1EncApp does, for
+// encoders that return packets instead of writing the file themselves.
+type IVFWriter struct {
+	w      io.WriteSeeker
+	frames uint32
+	buf    [ivfFrameHeaderSize]byte
+}
+
+// NewIVFWriter writes an IVF file header for a width x height stream with
+// frame rate fpsNum/fpsDen to w.
+func NewIVFWriter(w io.WriteSeeker, width, height, fpsNum, fpsDen uint32) (*IVFWriter, error) {
+	header := make([]byte, ivfHeaderSize)
+	copy(header, ivfSignature)
+	binary.LittleEndian.PutUint16(header[4:6], 0) // Version
+	binary.LittleEndian.PutUint16(header[6:8], ivfHeaderSize)
+	copy(header[8:12], "AV01")
+	binary.LittleEndian.PutUint16(header[12:14], uint16(width))
+	binary.LittleEndian.PutUint16(header[14:16], uint16(height))
+	binary.LittleEndian.PutUint32(header[16:20], fpsNum)
+	binary.LittleEndian.PutUint32(header[20:24], fpsDen)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &IVFWriter{w: w}, nil
+}
+
+// WriteFrame appends a packet with presentation timestamp pts.
+func (iw *IVFWriter) WriteFrame(data []byte, pts int64) error {
+	binary.LittleEndian.PutUint32(iw.buf[:4], uint32(len(data)))
+	binary.LittleEndian.PutUint64(iw.buf[4:], uint64(pts))
+	if _, err := iw.w.Write(iw.buf[:]); err != nil {
+		return err
+	}
+	if _, err := iw.w.Write(data); err != nil {
+		return err
+	}
+	iw.frames++
+	return nil
+}
+
+// Finish fills in the frame count in the file header.
+func (iw *IVFWriter) Finish() error {
+	if _, err := iw.w.Seek(24, io.SeekStart); err != nil {
+		return err
+	}
+	var count [4]byte
+	binary.LittleEndian.PutUint32(count[:], iw.frames)
+	if _, err := iw.w.Write(count[:]); err != nil {
+		return err
+	}
+	_, err := iw.w.Seek(0, io.SeekEnd)
+	return err
+}
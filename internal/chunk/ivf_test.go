@@ -0,0 +1,162 @@
+package chunk
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ivfData builds an IVF file holding frames of the given payload sizes.
+func ivfData(sizes ...int) []byte {
+	data := make([]byte, ivfHeaderSize)
+	copy(data, ivfSignature)
+	binary.LittleEndian.PutUint16(data[6:8], ivfHeaderSize)
+	copy(data[8:12], "AV01")
+	for i, size := range sizes {
+		frame := make([]byte, ivfFrameHeaderSize+size)
+		binary.LittleEndian.PutUint32(frame[:4], uint32(size))
+		binary.LittleEndian.PutUint64(frame[4:12], uint64(i))
+		data = append(data, frame...)
+	}
+	return data
+}
+
+func writeIVF(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIVFFrames(t *testing.T) {
+	full := ivfData(100, 20, 300)
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr string
+	}{
+		{"complete", full, 3, ""},
+		{"no frames", ivfData(), 0, ""},
+		{"cut inside a frame", full[:len(full)-10], 2, "truncated after 2 frames"},
+		{"cut inside a frame header", full[:ivfHeaderSize+ivfFrameHeaderSize+100+4], 1, "truncated after 1 frames"},
+		{"short header", full[:10], 0, "incomplete IVF header"},
+		{"not IVF", append([]byte("RIFF"), full[4:]...), 0, "not an IVF file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "chunk.ivf")
+			writeIVF(t, path, tt.data)
+
+			got, err := IVFFrames(path)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("IVFFrames() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("IVFFrames() error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IVFFrames() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChunks(t *testing.T) {
+	workDir := t.TempDir()
+	chunks := []Chunk{
+		{Idx: 0, Start: 0, End: 3},
+		{Idx: 1, Start: 3, End: 5},
+		{Idx: 2, Start: 5, End: 8},
+		{Idx: 3, Start: 8, End: 10},
+		{Idx: 4, Start: 10, End: 12},
+	}
+	writeIVF(t, IVFPath(workDir, 0), ivfData(10, 10, 10))
+	writeIVF(t, IVFPath(workDir, 1), ivfData(10))
+	writeIVF(t, IVFPath(workDir, 3), nil)
+	full := ivfData(10, 10)
+	writeIVF(t, IVFPath(workDir, 4), full[:len(full)-1])
+
+	want := map[int]string{
+		1: "1 of 2 frames",
+		2: "missing",
+		3: "empty",
+		4: "truncated after 1 frames",
+	}
+	faults := VerifyChunks(workDir, chunks)
+	if len(faults) != len(want) {
+		t.Fatalf("VerifyChunks() = %+v, want chunks %v", faults, want)
+	}
+	for _, f := range faults {
+		if f.Reason != want[f.Idx] {
+			t.Errorf("chunk %d reason = %q, want %q", f.Idx, f.Reason, want[f.Idx])
+		}
+	}
+}
+
+func TestForgetDone(t *testing.T) {
+	workDir := t.TempDir()
+	for _, c := range []ChunkComp{{0, 3, 100}, {1, 2, 50}, {2, 3, 70}} {
+		if err := AppendDone(c, workDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ForgetDone(workDir, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	resume, err := GetResume(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := resume.DoneSet()
+	if len(done) != 2 || !done[0] || done[1] || !done[2] {
+		t.Errorf("done after ForgetDone = %v, want chunks 0 and 2", done)
+	}
+}
+
+func TestIVFWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunk.ivf")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewIVFWriter(f, 1920, 800, 24000, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, size := range []int{100, 20, 300} {
+		if err := w.WriteFrame(make([]byte, size), int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ivfData(100, 20, 300)
+	binary.LittleEndian.PutUint16(want[12:14], 1920)
+	binary.LittleEndian.PutUint16(want[14:16], 800)
+	binary.LittleEndian.PutUint32(want[16:20], 24000)
+	binary.LittleEndian.PutUint32(want[20:24], 1001)
+	binary.LittleEndian.PutUint32(want[24:28], 3)
+	if string(data) != string(want) {
+		t.Errorf("written IVF differs from expected layout")
+	}
+	if frames, err := IVFFrames(path); err != nil || frames != 3 {
+		t.Errorf("IVFFrames() = %d, %v, want 3", frames, err)
+	}
+}
@@ -0,0 +1,93 @@
+package chunk
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+// opusLayouts are the layouts libopus encodes, indexed by channel count
+// minus one: the Vorbis channel orders of Opus mapping family 1.
+var opusLayouts = []string{"mono", "stereo", "3.0", "quad", "5.0", "5.1", "6.1", "7.1"}
+
+// layoutFallbacks maps source layouts whose channel count lands on a poor
+// match to the nearest layout libopus encodes.
+var layoutFallbacks = map[string]string{
+	"2.1":        "stereo", // Rather than 3.0 with a silent center
+	"3.1":        "5.1",    // Keep the LFE rather than fold into quad
+	"4.1":        "5.1",
+	"7.0":        "7.1", // Keep both rear pairs rather than fold into 6.1
+	"7.0(front)": "7.1",
+}
+
+// OpusLayout returns the channel layout a stream is encoded to and its
+// channel count. Layouts libopus can't take, such as 4.0 or 6.0, are
+// remixed to the nearest one it can, and anything over eight channels is
+// downmixed to 7.1. remapped reports whether speakers move in the process;
+// side and wide variants of a layout only reorder them.
+func OpusLayout(stream ffprobe.AudioStreamInfo) (layout string, channels uint32, remapped bool) {
+	source := stream.ChannelLayout
+	if source == "unknown" {
+		source = ""
+	}
+	switch fallback, ok := layoutFallbacks[source]; {
+	case ok:
+		layout = fallback
+	case stream.Channels == 0:
+		layout = "stereo"
+	default:
+		layout = opusLayouts[min(int(stream.Channels), len(opusLayouts))-1]
+	}
+	baseName, _, _ := strings.Cut(source, "(")
+	remapped = (source != "" && baseName != layout) || stream.Channels > uint32(len(opusLayouts))
+	return layout, uint32(slices.Index(opusLayouts, layout) + 1), remapped
+}
+
+// ffmpegChannels and vorbisChannels are the speakers of opusLayouts, as
+// ffmpeg orders them and as Opus mapping family 1 takes them.
+var (
+	ffmpegChannels = [][]string{
+		{"FC"}, {"FL", "FR"}, {"FL", "FR", "FC"}, {"FL", "FR", "BL", "BR"},
+		{"FL", "FR", "FC", "BL", "BR"},
+		{"FL", "FR", "FC", "LFE", "BL", "BR"},
+		{"FL", "FR", "FC", "LFE", "BC", "SL", "SR"},
+		{"FL", "FR", "FC", "LFE", "BL", "BR", "SL", "SR"},
+	}
+	vorbisChannels = [][]string{
+		{"FC"}, {"FL", "FR"}, {"FL", "FC", "FR"}, {"FL", "FR", "BL", "BR"},
+		{"FL", "FC", "FR", "BL", "BR"},
+		{"FL", "FC", "FR", "BL", "BR", "LFE"},
+		{"FL", "FC", "FR", "SL", "SR", "BC", "LFE"},
+		{"FL", "FC", "FR", "SL", "SR", "BL", "BR", "LFE"},
+	}
+)
+
+// vorbisOrder returns, for each channel in Vorbis order, its index in the
+// ffmpeg order of the Opus layout with channels.
+func vorbisOrder(channels int) []int {
+	src := ffmpegChannels[channels-1]
+	order := make([]int, channels)
+	for i, name := range vorbisChannels[channels-1] {
+		order[i] = slices.Index(src, name)
+	}
+	return order
+}
+
+// channelWeights returns the loudness weight of each channel, in ffmpeg
+// order, of the Opus layout with channels: surrounds count for 1.5 dB
+// more, and the LFE not at all.
+func channelWeights(channels int) []float64 {
+	weights := make([]float64, channels)
+	for i, name := range ffmpegChannels[channels-1] {
+		switch name {
+		case "LFE":
+			weights[i] = 0
+		case "FL", "FR", "FC":
+			weights[i] = 1
+		default:
+			weights[i] = 1.41
+		}
+	}
+	return weights
+}
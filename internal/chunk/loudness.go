@@ -0,0 +1,124 @@
+package chunk
+
+import "math"
+
+// K-weighting filter coefficients at 48 kHz, from ITU-R BS.1770: a high
+// shelf modelling the head, then a high pass.
+var (
+	kShelfB = [3]float64{1.53512485958697, -2.69169618940638, 1.19839281085285}
+	kShelfA = [2]float64{-1.69065929318241, 0.73248077421585}
+	kPassB  = [3]float64{1, -2, 1}
+	kPassA  = [2]float64{-1.99004745483398, 0.99007225036621}
+)
+
+const (
+	loudnessStep     = 4800 // 100 ms at 48 kHz; a gating block is four steps
+	loudnessAbsolute = -70  // LUFS below which a block is silence
+	loudnessRelative = -10  // LU below the ungated level at which a block is dropped
+)
+
+// biquad is one second-order filter section in direct form I.
+type biquad struct {
+	b      [3]float64
+	a      [2]float64
+	x1, x2 float64
+	y1, y2 float64
+}
+
+func (f *biquad) apply(x float64) float64 {
+	y := f.b[0]*x + f.b[1]*f.x1 + f.b[2]*f.x2 - f.a[0]*f.y1 - f.a[1]*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// loudnessMeter measures the integrated loudness of 48 kHz audio, as EBU
+// R128 defines it, in the same pass that encodes it.
+type loudnessMeter struct {
+	weights []float64   // Per channel; zero for LFE
+	filters [][2]biquad // Per channel
+	step    []float64   // Per-channel sum of squares in the current step
+	n       int         // Samples per channel in the current step
+	recent  [4]float64  // Weighted mean squares of the last four steps
+	steps   int
+	blocks  []float64 // Weighted mean square of each gating block
+}
+
+// newLoudnessMeter returns a meter for audio in the ffmpeg channel order of
+// the Opus layout with channels.
+func newLoudnessMeter(channels int) *loudnessMeter {
+	m := &loudnessMeter{
+		weights: channelWeights(channels),
+		filters: make([][2]biquad, channels),
+		step:    make([]float64, channels),
+	}
+	for i := range m.filters {
+		m.filters[i] = [2]biquad{{b: kShelfB, a: kShelfA}, {b: kPassB, a: kPassA}}
+	}
+	return m
+}
+
+// add measures interleaved samples.
+func (m *loudnessMeter) add(samples []float32) {
+	channels := len(m.weights)
+	for i := 0; i+channels <= len(samples); i += channels {
+		for c := range channels {
+			f := &m.filters[c]
+			y := f[1].apply(f[0].apply(float64(samples[i+c])))
+			m.step[c] += y * y
+		}
+		m.n++
+		if m.n == loudnessStep {
+			m.endStep()
+		}
+	}
+}
+
+// endStep closes a 100 ms step, and with it a 400 ms gating block once
+// there are four: blocks overlap by 75%.
+func (m *loudnessMeter) endStep() {
+	var z float64
+	for c, sum := range m.step {
+		z += m.weights[c] * sum / loudnessStep
+		m.step[c] = 0
+	}
+	m.n = 0
+	m.recent[m.steps%4] = z
+	m.steps++
+	if m.steps >= 4 {
+		m.blocks = append(m.blocks, (m.recent[0]+m.recent[1]+m.recent[2]+m.recent[3])/4)
+	}
+}
+
+// integrated returns the gated loudness in LUFS, false when the audio is
+// too short or too quiet to measure.
+func (m *loudnessMeter) integrated() (float64, bool) {
+	gated := func(threshold float64) (float64, bool) {
+		var sum float64
+		var n int
+		for _, z := range m.blocks {
+			if loudness(z) > threshold {
+				sum += z
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+	z, ok := gated(loudnessAbsolute)
+	if !ok {
+		return 0, false
+	}
+	z, ok = gated(loudness(z) + loudnessRelative)
+	if !ok {
+		return 0, false
+	}
+	return loudness(z), true
+}
+
+// loudness converts a weighted mean square to LUFS.
+func loudness(z float64) float64 {
+	return -0.691 + 10*math.Log10(z)
+}
@@ -0,0 +1,56 @@
+package chunk
+
+import (
+	"math"
+	"testing"
+)
+
+// sine returns seconds of interleaved 48 kHz audio with a 1 kHz sine of
+// peak amplitude in the channels that are on and silence in the others.
+func sine(seconds float64, amplitude float64, channels []bool) []float32 {
+	n := int(seconds * 48000)
+	samples := make([]float32, n*len(channels))
+	for i := range n {
+		v := float32(amplitude * math.Sin(2*math.Pi*1000*float64(i)/48000))
+		for c, on := range channels {
+			if on {
+				samples[i*len(channels)+c] = v
+			}
+		}
+	}
+	return samples
+}
+
+func TestLoudnessMeter(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels []bool
+		dbfs     float64
+		want     float64
+		wantOK   bool
+	}{
+		// EBU Tech 3341 case 1: stereo sine at -23 dBFS reads -23 LUFS
+		{"stereo -23 dBFS", []bool{true, true}, -23, -23, true},
+		{"stereo -33 dBFS", []bool{true, true}, -33, -33, true},
+		{"mono -23 dBFS", []bool{true}, -23, -26, true},
+		{"5.1 LFE only", []bool{false, false, false, true, false, false}, -20, 0, false},
+		{"silence", []bool{false, false}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newLoudnessMeter(len(tt.channels))
+			samples := sine(10, math.Pow(10, tt.dbfs/20), tt.channels)
+			// Feed it in frames, as the encoder does
+			for len(samples) > 0 {
+				n := min(960*len(tt.channels), len(samples))
+				m.add(samples[:n])
+				samples = samples[n:]
+			}
+			got, ok := m.integrated()
+			if ok != tt.wantOK || (ok && math.Abs(got-tt.want) > 0.1) {
+				t.Errorf("integrated() = %.2f, %v; want %.2f, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
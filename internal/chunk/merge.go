@@ -2,206 +2,125 @@
 package chunk
 
 import (
+	"bufio"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/five82/reel/internal/ffms"
 )
 
-// writeConcatFile writes a FFmpeg concat file with the given paths.
-// Uses defer for proper resource cleanup.
-func writeConcatFile(concatPath string, paths []string) (err error) {
-	f, err := os.Create(concatPath)
+// MergeOutput concatenates the chunk IVFs, in chunk order, into a single
+// IVF video stream. Frames are copied as they are and given consecutive
+// timestamps at the output frame rate, so no chunk's own timing survives
+// into the merged stream.
+func MergeOutput(workDir string, chunks []Chunk, inf *ffms.VidInf) (err error) {
+	// Validate FPS to prevent division by zero
+	if inf.FPSNum == 0 || inf.FPSDen == 0 {
+		return fmt.Errorf("invalid video info: frame rate %d/%d", inf.FPSNum, inf.FPSDen)
+	}
+	if len(chunks) == 0 {
+		return fmt.Errorf("no chunks to merge")
+	}
+
+	videoPath := GetVideoPath(workDir)
+	f, err := os.Create(videoPath)
 	if err != nil {
-		return fmt.Errorf("failed to create concat file: %w", err)
+		return fmt.Errorf("failed to create merged video: %w", err)
 	}
 	defer func() {
 		if cerr := f.Close(); cerr != nil && err == nil {
-			err = fmt.Errorf("failed to close concat file: %w", cerr)
+			err = fmt.Errorf("failed to close merged video: %w", cerr)
 		}
 	}()
 
-	for _, p := range paths {
-		absPath, err := filepath.Abs(p)
+	// The header is written last, once the frame count is known
+	if _, err := f.Seek(ivfHeaderSize, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write merged video: %w", err)
+	}
+
+	w := bufio.NewWriterSize(f, 1<<20)
+	var header []byte
+	var frames uint64
+	for _, ch := range chunks {
+		chunkHeader, n, err := appendIVF(w, IVFPath(workDir, ch.Idx), frames)
 		if err != nil {
-			return fmt.Errorf("failed to get absolute path for %s: %w", p, err)
+			return fmt.Errorf("failed to merge chunk %d: %w", ch.Idx, err)
 		}
-		if _, err := fmt.Fprintf(f, "file '%s'\n", absPath); err != nil {
-			return fmt.Errorf("failed to write to concat file: %w", err)
+		if header == nil {
+			header = chunkHeader
 		}
+		frames += n
 	}
-
-	return nil
-}
-
-// MergeOutput concatenates all IVF files into a single video file.
-func MergeOutput(workDir, outputPath string, inf *ffms.VidInf, inputPath string) error {
-	// Validate FPS to prevent division by zero
-	if inf.FPSDen == 0 {
-		return fmt.Errorf("invalid video info: FPS denominator is 0")
-	}
-
-	encodeDir := filepath.Join(workDir, "encode")
-
-	// Find all IVF files
-	ivfFiles, err := filepath.Glob(filepath.Join(encodeDir, "*.ivf"))
-	if err != nil {
-		return fmt.Errorf("failed to find IVF files: %w", err)
-	}
-
-	if len(ivfFiles) == 0 {
-		return fmt.Errorf("no IVF files found in %s", encodeDir)
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write merged video: %w", err)
 	}
 
-	// Create concat list file
-	concatPath := filepath.Join(workDir, "concat.txt")
-	if err := writeConcatFile(concatPath, ivfFiles); err != nil {
-		return err
+	// Timestamps count frames, so the time base is one frame
+	binary.LittleEndian.PutUint16(header[6:8], ivfHeaderSize)
+	binary.LittleEndian.PutUint32(header[16:20], inf.FPSNum)
+	binary.LittleEndian.PutUint32(header[20:24], inf.FPSDen)
+	binary.LittleEndian.PutUint32(header[24:28], uint32(frames))
+	if _, err := f.WriteAt(header, 0); err != nil {
+		return fmt.Errorf("failed to write merged video header: %w", err)
 	}
 
-	// Calculate FPS for output
-	fps := float64(inf.FPSNum) / float64(inf.FPSDen)
-
-	// Create intermediate video file (video only)
-	videoPath := filepath.Join(workDir, "video.mkv")
-
-	// Build FFmpeg concat command
-	args := []string{
-		"-hide_banner",
-		"-f", "concat",
-		"-safe", "0",
-		"-i", concatPath,
-		"-c", "copy",
-		"-r", fmt.Sprintf("%.6f", fps),
-		"-fflags", "+genpts+igndts+discardcorrupt+bitexact",
-		"-avoid_negative_ts", "make_zero",
-		"-reset_timestamps", "1",
-		"-start_at_zero",
-		"-y",
-		videoPath,
-	}
-
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ffmpeg concat failed: %w\nOutput: %s", err, string(output))
-	}
-
-	// Cleanup concat file
-	_ = os.Remove(concatPath)
-
 	return nil
 }
 
-// MergeBatched handles large numbers of IVF files by merging in batches.
-// This is necessary because FFmpeg's concat demuxer can have issues with
-// very large numbers of files.
-func MergeBatched(workDir string, numChunks int) error {
-	const batchSize = 500
-
-	if numChunks <= batchSize {
-		return nil // No batching needed, MergeOutput handles it
+// appendIVF copies the frames of the IVF at path to w, numbering their
+// timestamps from pts. Returns the file header and the number of frames.
+func appendIVF(w io.Writer, path string, pts uint64) ([]byte, uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer func() { _ = f.Close() }()
 
-	encodeDir := filepath.Join(workDir, "encode")
-	tempDir := filepath.Join(workDir, "temp_merge")
-
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp merge dir: %w", err)
+	r := bufio.NewReaderSize(f, 1<<20)
+	header := make([]byte, ivfHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, fmt.Errorf("incomplete IVF header: %w", err)
 	}
-
-	// Process in batches
-	batchNum := 0
-	for start := 0; start < numChunks; start += batchSize {
-		end := start + batchSize
-		if end > numChunks {
-			end = numChunks
+	if string(header[:4]) != ivfSignature {
+		return nil, 0, fmt.Errorf("not an IVF file")
+	}
+	if skip := int64(binary.LittleEndian.Uint16(header[6:8])) - ivfHeaderSize; skip > 0 {
+		if _, err := io.CopyN(io.Discard, r, skip); err != nil {
+			return nil, 0, fmt.Errorf("incomplete IVF header: %w", err)
 		}
+	}
 
-		// Create concat list for this batch
-		concatPath := filepath.Join(tempDir, fmt.Sprintf("batch_%04d.txt", batchNum))
-		batchPaths := make([]string, 0, end-start)
-		for i := start; i < end; i++ {
-			batchPaths = append(batchPaths, IVFPath(workDir, i))
+	var frames uint64
+	frameHeader := make([]byte, ivfFrameHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, frameHeader); err == io.EOF {
+			return header, frames, nil
+		} else if err != nil {
+			return nil, 0, fmt.Errorf("truncated after %d frames", frames)
 		}
-		if err := writeConcatFile(concatPath, batchPaths); err != nil {
-			return fmt.Errorf("failed to create batch %d concat file: %w", batchNum, err)
+		binary.LittleEndian.PutUint64(frameHeader[4:12], pts+frames)
+		if _, err := w.Write(frameHeader); err != nil {
+			return nil, 0, err
 		}
-
-		// Merge this batch
-		batchOut := filepath.Join(tempDir, fmt.Sprintf("batch_%04d.ivf", batchNum))
-		args := []string{
-			"-hide_banner",
-			"-f", "concat",
-			"-safe", "0",
-			"-i", concatPath,
-			"-c", "copy",
-			"-y",
-			batchOut,
+		size := int64(binary.LittleEndian.Uint32(frameHeader[:4]))
+		if _, err := io.CopyN(w, r, size); err == io.EOF {
+			return nil, 0, fmt.Errorf("truncated after %d frames", frames)
+		} else if err != nil {
+			return nil, 0, err
 		}
-
-		cmd := exec.Command("ffmpeg", args...)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("batch merge failed: %w\nOutput: %s", err, string(output))
-		}
-
-		_ = os.Remove(concatPath)
-		batchNum++
-	}
-
-	// Now merge all batch outputs
-	finalConcatPath := filepath.Join(tempDir, "final.txt")
-	finalBatchPaths := make([]string, batchNum)
-	for i := 0; i < batchNum; i++ {
-		finalBatchPaths[i] = filepath.Join(tempDir, fmt.Sprintf("batch_%04d.ivf", i))
-	}
-	if err := writeConcatFile(finalConcatPath, finalBatchPaths); err != nil {
-		return fmt.Errorf("failed to create final concat file: %w", err)
+		frames++
 	}
-
-	// Final merge to encode directory
-	finalOut := filepath.Join(encodeDir, "merged.ivf")
-	args := []string{
-		"-hide_banner",
-		"-f", "concat",
-		"-safe", "0",
-		"-i", finalConcatPath,
-		"-c", "copy",
-		"-y",
-		finalOut,
-	}
-
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("final merge failed: %w\nOutput: %s", err, string(output))
-	}
-
-	// Move merged file to replace individual IVFs
-	for i := 0; i < numChunks; i++ {
-		_ = os.Remove(IVFPath(workDir, i))
-	}
-
-	// Rename merged to 0000.ivf so MergeOutput can find it
-	if err := os.Rename(finalOut, IVFPath(workDir, 0)); err != nil {
-		return fmt.Errorf("failed to rename merged file: %w", err)
-	}
-
-	// Cleanup temp dir
-	_ = os.RemoveAll(tempDir)
-
-	return nil
 }
 
 // GetVideoPath returns the path to the merged video file.
 func GetVideoPath(workDir string) string {
-	return filepath.Join(workDir, "video.mkv")
+	return filepath.Join(workDir, "video.ivf")
 }
 
-// GetAudioPath returns the path to the extracted audio file.
-func GetAudioPath(workDir string) string {
-	return filepath.Join(workDir, "audio.mka")
+// GetAudioStreamPath returns the path to the i-th extracted audio stream.
+func GetAudioStreamPath(workDir string, i int) string {
+	return filepath.Join(workDir, fmt.Sprintf("audio_%d.mka", i))
 }
@@ -0,0 +1,60 @@
+package chunk
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/five82/reel/internal/ffms"
+)
+
+func TestMergeOutput(t *testing.T) {
+	workDir := t.TempDir()
+	chunks := []Chunk{{Idx: 0, Start: 0, End: 2}, {Idx: 1, Start: 2, End: 5}}
+	writeIVF(t, IVFPath(workDir, 0), ivfData(10, 20))
+	writeIVF(t, IVFPath(workDir, 1), ivfData(30, 40, 50))
+
+	inf := &ffms.VidInf{FPSNum: 24000, FPSDen: 1001}
+	if err := MergeOutput(workDir, chunks, inf); err != nil {
+		t.Fatalf("MergeOutput() error = %v", err)
+	}
+
+	merged := GetVideoPath(workDir)
+	frames, err := IVFFrames(merged)
+	if err != nil || frames != 5 {
+		t.Fatalf("IVFFrames(merged) = %d, %v; want 5 frames", frames, err)
+	}
+
+	data, err := os.ReadFile(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate, scale := binary.LittleEndian.Uint32(data[16:20]), binary.LittleEndian.Uint32(data[20:24]); rate != 24000 || scale != 1001 {
+		t.Errorf("time base = %d/%d, want 1001/24000", scale, rate)
+	}
+	if n := binary.LittleEndian.Uint32(data[24:28]); n != 5 {
+		t.Errorf("header frame count = %d, want 5", n)
+	}
+
+	// Timestamps run on across chunks and payloads keep their order
+	offset := ivfHeaderSize
+	for i, size := range []int{10, 20, 30, 40, 50} {
+		gotSize := binary.LittleEndian.Uint32(data[offset : offset+4])
+		pts := binary.LittleEndian.Uint64(data[offset+4 : offset+12])
+		if int(gotSize) != size || pts != uint64(i) {
+			t.Errorf("frame %d: size %d pts %d, want size %d pts %d", i, gotSize, pts, size, i)
+		}
+		offset += ivfFrameHeaderSize + size
+	}
+}
+
+func TestMergeOutputTruncatedChunk(t *testing.T) {
+	workDir := t.TempDir()
+	chunks := []Chunk{{Idx: 0, Start: 0, End: 2}}
+	data := ivfData(10, 20)
+	writeIVF(t, IVFPath(workDir, 0), data[:len(data)-5])
+
+	if err := MergeOutput(workDir, chunks, &ffms.VidInf{FPSNum: 25, FPSDen: 1}); err == nil {
+		t.Error("MergeOutput() merged a truncated chunk")
+	}
+}
@@ -0,0 +1,282 @@
+package chunk
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// muxProfile holds what the final mux does differently per output container.
+type muxProfile struct {
+	format       string   // ffmpeg muxer
+	flags        []string // Muxer options
+	subtitles    bool     // Holds the source's subtitle formats (PGS, ASS, ...) as is
+	attachments  bool     // Holds attachments
+	defaultAudio bool     // Flag the first audio track default when the source flags none
+	statistics   bool     // Has track statistics tags for mkvpropedit to write
+}
+
+var (
+	// matroskaProfile is for MKV output. Players fall back to the first
+	// track of each type, so no default needs adding.
+	matroskaProfile = muxProfile{format: "matroska", subtitles: true, attachments: true, statistics: true}
+
+	// mp4Profile is for MP4 and QuickTime output. The index goes at the
+	// front for streaming, and players skip audio tracks not flagged
+	// default. Disc subtitle formats and attachments don't fit.
+	mp4Profile = muxProfile{format: "mp4", flags: []string{"-movflags", "+faststart"}, defaultAudio: true}
+)
+
+// muxProfileFor returns the mux profile for outputPath's extension,
+// Matroska unless it is an MP4 or QuickTime one.
+func muxProfileFor(outputPath string) muxProfile {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".mp4", ".m4v":
+		return mp4Profile
+	case ".mov":
+		p := mp4Profile
+		p.format = "mov"
+		return p
+	default:
+		return matroskaProfile
+	}
+}
+
+// HoldsAttachments reports whether outputPath's container keeps
+// attachments, such as subtitle fonts and the encode settings.
+func HoldsAttachments(outputPath string) bool {
+	return muxProfileFor(outputPath).attachments
+}
+
+// MuxedAudioStreams returns audioStreams with the dispositions MuxFinal
+// gives their tracks in outputPath's container.
+func MuxedAudioStreams(outputPath string, audioStreams []ffprobe.AudioStreamInfo) []ffprobe.AudioStreamInfo {
+	flagDefault := muxProfileFor(outputPath).defaultAudio && !slices.ContainsFunc(audioStreams, func(s ffprobe.AudioStreamInfo) bool {
+		return s.Disposition.Default != 0
+	})
+	if !flagDefault || len(audioStreams) == 0 {
+		return audioStreams
+	}
+	muxed := slices.Clone(audioStreams)
+	muxed[0].Disposition.Default = 1
+	return muxed
+}
+
+// statisticsTags are the track statistics tags mkvmerge and mkvpropedit
+// write, which ffmpeg reads back with the tag language appended. Carried
+// over to a re-encoded track they describe the source.
+var statisticsTags = []string{
+	"BPS", "DURATION", "NUMBER_OF_FRAMES", "NUMBER_OF_BYTES",
+	"_STATISTICS_WRITING_APP", "_STATISTICS_WRITING_DATE_UTC", "_STATISTICS_TAGS",
+}
+
+// clearStatisticsArgs returns the ffmpeg arguments that drop statistics
+// tags from output audio track i.
+func clearStatisticsArgs(i int) []string {
+	spec := fmt.Sprintf("-metadata:s:a:%d", i)
+	var args []string
+	for _, tag := range statisticsTags {
+		args = append(args, spec, tag+"=", spec, tag+"-eng=")
+	}
+	return args
+}
+
+// WriteTrackStatistics has mkvpropedit write fresh track statistics tags
+// (bitrate, frame and byte counts) to outputPath, as mkvmerge would. It
+// returns false without error when the container has no such tags or
+// mkvpropedit isn't installed.
+func WriteTrackStatistics(outputPath string) (bool, error) {
+	if !muxProfileFor(outputPath).statistics {
+		return false, nil
+	}
+	if _, err := exec.LookPath("mkvpropedit"); err != nil {
+		return false, nil
+	}
+	output, err := cmdlog.CombinedOutput(exec.Command("mkvpropedit", outputPath, "--add-track-statistics-tags"))
+	if err != nil {
+		return false, fmt.Errorf("mkvpropedit failed: %w\nOutput: %s", err, string(output))
+	}
+	return true, nil
+}
+
+// MuxFinal combines the encoded video with audio and other streams. Each
+// audio track keeps the language, title and dispositions of its source
+// stream. A non-nil downmix is muxed after the other audio, titled and not
+// default. The source streams at the coverArt container indexes are carried
+// over as attached pictures, and with attachments so are the source's
+// attachments, such as the fonts ASS subtitles need. Subtitle timestamps are
+// multiplied by timeScale. A non-nil settings is written as container tags.
+// Flags and what the container can hold follow the output's mux profile.
+func MuxFinal(paths toolpath.Paths, inputPath, workDir, outputPath string, audioStreams []ffprobe.AudioStreamInfo, downmix *Downmix, coverArt []int, attachments bool, settings *Settings, timeScale float64) error {
+	videoPath := GetVideoPath(workDir)
+	profile := muxProfileFor(outputPath)
+
+	// Check if video exists
+	if _, err := os.Stat(videoPath); err != nil {
+		return fmt.Errorf("video file not found: %w", err)
+	}
+
+	args := []string{
+		"-hide_banner",
+		"-i", videoPath, // Encoded video
+	}
+
+	// Add audio if every track was extracted
+	tracks := len(audioStreams)
+	if downmix != nil && tracks > 0 {
+		tracks++
+	}
+	audioInputs := 0
+	if tracks > 0 && audioExtracted(workDir, tracks) {
+		for i := range tracks {
+			args = append(args, "-i", GetAudioStreamPath(workDir, i))
+		}
+		audioInputs = tracks
+	}
+
+	// Add original input for subtitles and chapters; subtitles are retimed to match a slowed-down video
+	if timeScale != 1 {
+		args = append(args, "-itsscale", fmt.Sprintf("%.6f", timeScale))
+	}
+	args = append(args, "-i", inputPath)
+
+	// Map video
+	args = append(args, "-map", "0:v:0")
+
+	// Map audio in source order
+	for i := 1; i <= audioInputs; i++ {
+		args = append(args, "-map", fmt.Sprintf("%d:a?", i))
+	}
+
+	// Map subtitles from original
+	subtitleInputIdx := 1 + audioInputs
+	if profile.subtitles {
+		args = append(args, "-map", fmt.Sprintf("%d:s?", subtitleInputIdx))
+	}
+
+	// Map cover art from original; Matroska stores it as an attachment
+	for _, index := range coverArt {
+		args = append(args, "-map", fmt.Sprintf("%d:%d", subtitleInputIdx, index))
+	}
+
+	// Map attachments (subtitle fonts) from original
+	if attachments && profile.attachments {
+		args = append(args, "-map", fmt.Sprintf("%d:t?", subtitleInputIdx))
+	}
+
+	// Attach the encode settings after the source attachments
+	if settings != nil && settings.Attach && profile.attachments {
+		settingsPath, err := writeSettingsFile(workDir, settings)
+		if err != nil {
+			return err
+		}
+		index := 0
+		if attachments {
+			if index, err = ffprobe.CountAttachments(paths, inputPath); err != nil {
+				return fmt.Errorf("failed to count source attachments: %w", err)
+			}
+		}
+		spec := fmt.Sprintf("-metadata:s:t:%d", index)
+		args = append(args, "-attach", settingsPath,
+			spec, "mimetype=application/json", spec, "filename="+SettingsFilename)
+	}
+
+	// Copy all streams
+	args = append(args, "-c", "copy")
+
+	// The video is the default video track
+	args = append(args, "-disposition:v:0", "default")
+
+	// Carry stream metadata over explicitly; the extracted tracks don't keep
+	// the source dispositions, and their statistics describe the source
+	if audioInputs > 0 {
+		for i, stream := range MuxedAudioStreams(outputPath, audioStreams) {
+			args = append(args, audioMetadataArgs(i, stream.Language, stream.Title, dispositionFlags(stream.Disposition))...)
+		}
+		for i := range audioInputs {
+			args = append(args, clearStatisticsArgs(i)...)
+		}
+	}
+
+	// Label the downmix so players don't pick it over the original
+	if downmix != nil && audioInputs > 0 {
+		args = append(args, audioMetadataArgs(audioInputs-1, downmix.Stream.Language, downmix.Title(), "0")...)
+	}
+
+	// Copy global metadata from the source, and chapters
+	args = append(args, "-map_metadata", fmt.Sprintf("%d", subtitleInputIdx))
+	args = append(args, "-map_chapters", fmt.Sprintf("%d", subtitleInputIdx))
+
+	// Record the encode settings, replacing any the source carried
+	if settings != nil {
+		args = append(args, settings.metadataArgs()...)
+	}
+
+	args = append(args, "-f", profile.format)
+	args = append(args, profile.flags...)
+	args = append(args, "-y", outputPath)
+
+	cmd := exec.Command(paths.Path(toolpath.FFmpeg), args...)
+	output, err := cmdlog.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("final mux failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// audioMetadataArgs returns the ffmpeg arguments that set the language,
+// title and dispositions of output audio track i. Empty tags are left unset.
+func audioMetadataArgs(i int, language, title, disposition string) []string {
+	spec := fmt.Sprintf("a:%d", i)
+	var args []string
+	if language != "" {
+		args = append(args, "-metadata:s:"+spec, "language="+language)
+	}
+	if title != "" {
+		args = append(args, "-metadata:s:"+spec, "title="+title)
+	}
+	return append(args, "-disposition:"+spec, disposition)
+}
+
+// dispositionFlags formats d for ffmpeg's -disposition, "0" when no flag is set.
+func dispositionFlags(d ffprobe.StreamDisposition) string {
+	var flags []string
+	for _, f := range []struct {
+		name string
+		set  int
+	}{
+		{"default", d.Default},
+		{"dub", d.Dub},
+		{"original", d.Original},
+		{"comment", d.Comment},
+		{"forced", d.Forced},
+		{"hearing_impaired", d.HearingImpaired},
+		{"visual_impaired", d.VisualImpaired},
+	} {
+		if f.set != 0 {
+			flags = append(flags, f.name)
+		}
+	}
+	if len(flags) == 0 {
+		return "0"
+	}
+	return strings.Join(flags, "+")
+}
+
+// audioExtracted reports whether all n audio stream files exist.
+func audioExtracted(workDir string, n int) bool {
+	for i := range n {
+		if _, err := os.Stat(GetAudioStreamPath(workDir, i)); err != nil {
+			return false
+		}
+	}
+	return true
+}
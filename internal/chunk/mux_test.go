@@ -0,0 +1,97 @@
+package chunk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestMuxProfileFor(t *testing.T) {
+	tests := []struct {
+		path       string
+		format     string
+		faststart  bool
+		statistics bool
+	}{
+		{"/out/movie.mkv", "matroska", false, true},
+		{"/out/movie.MP4", "mp4", true, false},
+		{"/out/movie.mov", "mov", true, false},
+	}
+
+	for _, tt := range tests {
+		p := muxProfileFor(tt.path)
+		if p.format != tt.format || slices.Contains(p.flags, "+faststart") != tt.faststart || p.statistics != tt.statistics {
+			t.Errorf("muxProfileFor(%q) = %+v", tt.path, p)
+		}
+	}
+}
+
+func TestClearStatisticsArgs(t *testing.T) {
+	args := clearStatisticsArgs(1)
+	for _, want := range []string{"BPS=", "BPS-eng=", "NUMBER_OF_BYTES-eng="} {
+		i := slices.Index(args, want)
+		if i < 1 || args[i-1] != "-metadata:s:a:1" {
+			t.Errorf("args %q don't clear %s on track 1", args, want)
+		}
+	}
+}
+
+func TestAudioMetadataArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition ffprobe.StreamDisposition
+		language    string
+		title       string
+		want        []string
+	}{
+		{"no flags or tags", ffprobe.StreamDisposition{}, "", "", []string{"-disposition:a:1", "0"}},
+		{"default forced", ffprobe.StreamDisposition{Default: 1, Forced: 1}, "eng", "", []string{
+			"-metadata:s:a:1", "language=eng", "-disposition:a:1", "default+forced",
+		}},
+		{"titled commentary", ffprobe.StreamDisposition{Comment: 1}, "eng", "Director's commentary", []string{
+			"-metadata:s:a:1", "language=eng", "-metadata:s:a:1", "title=Director's commentary", "-disposition:a:1", "comment",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := audioMetadataArgs(1, tt.language, tt.title, dispositionFlags(tt.disposition))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("audioMetadataArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMuxedAudioStreams(t *testing.T) {
+	undefaulted := []ffprobe.AudioStreamInfo{{Index: 1}, {Index: 2}}
+	defaulted := []ffprobe.AudioStreamInfo{{Index: 1}, {Index: 2, Disposition: ffprobe.StreamDisposition{Default: 1}}}
+
+	tests := []struct {
+		name    string
+		path    string
+		streams []ffprobe.AudioStreamInfo
+		want    []int
+	}{
+		{"mkv keeps source flags", "/out/movie.mkv", undefaulted, []int{0, 0}},
+		{"mp4 flags first track", "/out/movie.mp4", undefaulted, []int{1, 0}},
+		{"mp4 keeps source default", "/out/movie.mp4", defaulted, []int{0, 1}},
+		{"mov without audio", "/out/movie.mov", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, s := range MuxedAudioStreams(tt.path, tt.streams) {
+				got = append(got, s.Disposition.Default)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("default flags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if undefaulted[0].Disposition.Default != 0 {
+		t.Error("MuxedAudioStreams modified its input")
+	}
+}
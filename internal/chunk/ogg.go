@@ -0,0 +1,141 @@
+package chunk
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/five82/reel/internal/opuslib"
+)
+
+const (
+	oggHeaderSize  = 27   // Fixed part of a page header, before the segment table
+	oggMaxSegments = 255  // Segment table entries in a page
+	oggPageTarget  = 4096 // Body size after which a page is flushed
+
+	oggContinued = 0x01
+	oggBOS       = 0x02 // First page of the stream
+	oggEOS       = 0x04 // Last page of the stream
+)
+
+// oggCRC is the CRC-32 lookup table of Ogg pages: polynomial 0x04c11db7,
+// unreflected, with no initial or final XOR.
+var oggCRC = func() (t [256]uint32) {
+	for i := range t {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// oggChecksum returns the CRC of page, whose checksum field is zero.
+func oggChecksum(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRC[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// OggOpusWriter writes Opus packets to an Ogg Opus stream, for encoders that
+// return packets instead of writing the file themselves.
+type OggOpusWriter struct {
+	w        io.Writer
+	serial   uint32
+	seq      uint32
+	segments []byte
+	body     []byte
+	granule  int64
+}
+
+// NewOggOpusWriter writes the identification and comment headers for a
+// stream described by head, naming vendor as the encoder, to w.
+func NewOggOpusWriter(w io.Writer, head opuslib.Head, vendor string) (*OggOpusWriter, error) {
+	ow := &OggOpusWriter{w: w, serial: 1}
+
+	id := make([]byte, 19, 21+len(head.Mapping))
+	copy(id, "OpusHead")
+	id[8] = 1 // Version
+	id[9] = byte(head.Channels)
+	binary.LittleEndian.PutUint16(id[10:12], uint16(head.PreSkip))
+	binary.LittleEndian.PutUint32(id[12:16], opuslib.SampleRate)
+	binary.LittleEndian.PutUint16(id[16:18], 0) // Output gain
+	id[18] = byte(head.Family)
+	if head.Family != 0 {
+		id = append(id, byte(head.Streams), byte(head.Coupled))
+		id = append(id, head.Mapping...)
+	}
+	ow.add(id)
+	if err := ow.flush(oggBOS); err != nil {
+		return nil, err
+	}
+
+	tags := make([]byte, 8, 16+len(vendor))
+	copy(tags, "OpusTags")
+	tags = binary.LittleEndian.AppendUint32(tags, uint32(len(vendor)))
+	tags = append(tags, vendor...)
+	tags = binary.LittleEndian.AppendUint32(tags, 0) // No user comments
+	ow.add(tags)
+	if err := ow.flush(0); err != nil {
+		return nil, err
+	}
+	return ow, nil
+}
+
+// WritePacket appends a packet that ends at granule, the samples decoded
+// so far including those skipped at the start.
+func (ow *OggOpusWriter) WritePacket(data []byte, granule int64) error {
+	if len(ow.segments)+len(data)/255+1 > oggMaxSegments {
+		if err := ow.flush(0); err != nil {
+			return err
+		}
+	}
+	ow.add(data)
+	ow.granule = granule
+	if len(ow.body) >= oggPageTarget {
+		return ow.flush(0)
+	}
+	return nil
+}
+
+// Close writes the last page, marking the end of the stream.
+func (ow *OggOpusWriter) Close() error {
+	return ow.flush(oggEOS)
+}
+
+// add puts data in the current page as one packet, laced into segments.
+func (ow *OggOpusWriter) add(data []byte) {
+	n := len(data)
+	for ; n >= 255; n -= 255 {
+		ow.segments = append(ow.segments, 255)
+	}
+	ow.segments = append(ow.segments, byte(n))
+	ow.body = append(ow.body, data...)
+}
+
+// flush writes the current page with flags and starts the next.
+func (ow *OggOpusWriter) flush(flags byte) error {
+	page := make([]byte, oggHeaderSize, oggHeaderSize+len(ow.segments)+len(ow.body))
+	copy(page, "OggS")
+	page[4] = 0 // Version
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:14], uint64(ow.granule))
+	binary.LittleEndian.PutUint32(page[14:18], ow.serial)
+	binary.LittleEndian.PutUint32(page[18:22], ow.seq)
+	page[26] = byte(len(ow.segments))
+	page = append(page, ow.segments...)
+	page = append(page, ow.body...)
+	binary.LittleEndian.PutUint32(page[22:26], oggChecksum(page))
+
+	ow.seq++
+	ow.segments = ow.segments[:0]
+	ow.body = ow.body[:0]
+	_, err := ow.w.Write(page)
+	return err
+}
@@ -0,0 +1,114 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/five82/reel/internal/opuslib"
+)
+
+func TestOggChecksum(t *testing.T) {
+	// CRC-32/CKSUM of "123456789" without its final XOR
+	if got, want := oggChecksum([]byte("123456789")), uint32(0x765e7680^0xffffffff); got != want {
+		t.Errorf("oggChecksum() = %#x, want %#x", got, want)
+	}
+}
+
+// oggPage is a page read back from an Ogg stream.
+type oggPage struct {
+	flags   byte
+	granule int64
+	packets [][]byte
+}
+
+// readOggPages splits data into pages, checking each checksum. Packets
+// are assumed not to span pages.
+func readOggPages(t *testing.T, data []byte) []oggPage {
+	t.Helper()
+	var pages []oggPage
+	for len(data) > 0 {
+		if len(data) < oggHeaderSize || string(data[:4]) != "OggS" {
+			t.Fatalf("page %d: bad header", len(pages))
+		}
+		nsegs := int(data[26])
+		segments := data[oggHeaderSize : oggHeaderSize+nsegs]
+		size := oggHeaderSize + nsegs
+		for _, s := range segments {
+			size += int(s)
+		}
+		page := bytes.Clone(data[:size])
+		binary.LittleEndian.PutUint32(page[22:26], 0)
+		if got := binary.LittleEndian.Uint32(data[22:26]); got != oggChecksum(page) {
+			t.Errorf("page %d: checksum %#x, want %#x", len(pages), got, oggChecksum(page))
+		}
+
+		p := oggPage{flags: data[5], granule: int64(binary.LittleEndian.Uint64(data[6:14]))}
+		body := data[oggHeaderSize+nsegs : size]
+		var packet []byte
+		for _, s := range segments {
+			packet = append(packet, body[:s]...)
+			body = body[s:]
+			if s < 255 {
+				p.packets = append(p.packets, packet)
+				packet = nil
+			}
+		}
+		pages = append(pages, p)
+		data = data[size:]
+	}
+	return pages
+}
+
+func TestOggOpusWriter(t *testing.T) {
+	var buf bytes.Buffer
+	head := opuslib.Head{Channels: 6, PreSkip: 312, Family: 1, Streams: 4, Coupled: 2, Mapping: []byte{0, 4, 1, 2, 3, 5}}
+	w, err := NewOggOpusWriter(&buf, head, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packets [][]byte
+	for i := range 20 {
+		packet := bytes.Repeat([]byte{byte(i)}, 300+i) // Laced into two segments
+		packets = append(packets, packet)
+		if err := w.WritePacket(packet, int64(i+1)*960); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := readOggPages(t, buf.Bytes())
+	if len(pages) < 4 {
+		t.Fatalf("got %d pages, want headers and at least two audio pages", len(pages))
+	}
+	if pages[0].flags != oggBOS || pages[len(pages)-1].flags != oggEOS {
+		t.Errorf("flags first %#x, last %#x; want BOS and EOS", pages[0].flags, pages[len(pages)-1].flags)
+	}
+
+	id := pages[0].packets[0]
+	if string(id[:8]) != "OpusHead" || id[9] != 6 || binary.LittleEndian.Uint16(id[10:12]) != 312 ||
+		id[18] != 1 || id[19] != 4 || id[20] != 2 || !bytes.Equal(id[21:], head.Mapping) {
+		t.Errorf("OpusHead = %v", id)
+	}
+	if tags := pages[1].packets[0]; string(tags[:8]) != "OpusTags" || string(tags[12:16]) != "test" {
+		t.Errorf("OpusTags = %q", tags)
+	}
+
+	var got [][]byte
+	for _, p := range pages[2:] {
+		got = append(got, p.packets...)
+	}
+	if len(got) != len(packets) {
+		t.Fatalf("read %d packets, want %d", len(got), len(packets))
+	}
+	for i := range got {
+		if !bytes.Equal(got[i], packets[i]) {
+			t.Errorf("packet %d differs", i)
+		}
+	}
+	if g := pages[len(pages)-1].granule; g != 20*960 {
+		t.Errorf("final granule = %d, want %d", g, 20*960)
+	}
+}
@@ -0,0 +1,179 @@
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/opuslib"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// pcmArgs returns the ffmpeg arguments that decode audio stream index of
+// the source through filter to 48 kHz float PCM on stdout, for encoding
+// in-process.
+func pcmArgs(inputPath string, index int, filter string) []string {
+	return []string{
+		"-hide_banner",
+		"-nostats",
+		"-i", inputPath,
+		"-vn", // No video
+		"-map", fmt.Sprintf("0:a:%d", index),
+		"-filter:a", filter,
+		"-ar", fmt.Sprint(opuslib.SampleRate),
+		"-c:a", "pcm_f32le",
+		"-f", "f32le",
+		"pipe:1",
+	}
+}
+
+// runOpusEncode runs ffmpeg with job.args to decode a track to PCM and
+// encodes it with libopus, passing the seconds of source encoded so far to
+// onProgress. The Ogg Opus stream it writes is remuxed to job.output. It
+// returns the integrated loudness of the track, measured as it's encoded,
+// with false when there was nothing loud enough to measure.
+func runOpusEncode(paths toolpath.Paths, job audioJob, tempo float64, onProgress func(seconds float64)) (float64, bool, error) {
+	channels := int(job.channels)
+	enc, err := opuslib.Open(channels, int(job.bitrate)*1000)
+	if err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+	defer enc.Close()
+
+	oggPath := strings.TrimSuffix(job.output, ".mka") + ".opus"
+	defer func() { _ = os.Remove(oggPath) }()
+	f, err := os.Create(oggPath)
+	if err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	bw := bufio.NewWriter(f)
+	ow, err := NewOggOpusWriter(bw, enc.Head(), opuslib.Version())
+	if err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(paths.Path(toolpath.FFmpeg), job.args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, false, fmt.Errorf("audio decoding failed: %w", err)
+	}
+	proc, err := cmdlog.Start(cmd, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("audio decoding failed: %w", err)
+	}
+
+	meter := newLoudnessMeter(channels)
+	var order []int
+	if channels > 2 {
+		order = vorbisOrder(channels)
+	}
+	raw := make([]byte, opuslib.FrameSize*channels*4)
+	pcm := make([]float32, opuslib.FrameSize*channels)
+	frame := make([]float32, opuslib.FrameSize*channels)
+	preSkip := int64(enc.Head().PreSkip)
+	var samples, encoded int64
+
+	// encode sends one frame to libopus; the packet ends at the samples
+	// decoded so far, but no further than the end of the source
+	encode := func() error {
+		packet, err := enc.Encode(frame)
+		if err != nil {
+			return err
+		}
+		encoded += opuslib.FrameSize
+		return ow.WritePacket(packet, min(encoded, preSkip+samples))
+	}
+
+	var readErr error
+	for {
+		n, err := io.ReadFull(stdout, raw)
+		if n == 0 {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
+		n /= 4 * channels
+		for i := range pcm {
+			if i < n*channels {
+				pcm[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+			} else {
+				pcm[i] = 0 // Pad the last frame with silence
+			}
+		}
+		meter.add(pcm[:n*channels])
+		reorder(frame, pcm, order, channels)
+		samples += int64(n)
+		if readErr = encode(); readErr != nil {
+			break
+		}
+		// Output runs at the new tempo; report source time
+		onProgress(float64(samples) / opuslib.SampleRate * tempo)
+		if err != nil {
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				readErr = err
+			}
+			break // Short read at the end of the stream
+		}
+	}
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+		_ = proc.Wait()
+		return 0, false, fmt.Errorf("audio encoding failed: %w", readErr)
+	}
+	if err := proc.Wait(); err != nil {
+		return 0, false, fmt.Errorf("audio decoding failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	// The encoder's delay holds back the end of the source; silence
+	// flushes it out
+	clear(frame)
+	for encoded < preSkip+samples {
+		if err := encode(); err != nil {
+			return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+		}
+	}
+	if err := ow.Close(); err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, false, fmt.Errorf("audio encoding failed: %w", err)
+	}
+
+	remux := exec.Command(paths.Path(toolpath.FFmpeg), "-hide_banner", "-nostats",
+		"-i", oggPath, "-c:a", "copy", "-y", job.output)
+	if out, err := cmdlog.CombinedOutput(remux); err != nil {
+		return 0, false, fmt.Errorf("audio remux failed: %w\nOutput: %s", err, out)
+	}
+
+	lufs, ok := meter.integrated()
+	return lufs, ok, nil
+}
+
+// reorder copies interleaved samples from src to dst, moving each channel
+// to the position order gives it; a nil order copies them as they are.
+func reorder(dst, src []float32, order []int, channels int) {
+	if order == nil {
+		copy(dst, src)
+		return
+	}
+	for i := 0; i < len(src); i += channels {
+		for c, from := range order {
+			dst[i+c] = src[i+from]
+		}
+	}
+}
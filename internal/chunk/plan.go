@@ -0,0 +1,41 @@
+package chunk
+
+import (
+	"fmt"
+
+	"github.com/five82/reel/internal/ffms"
+)
+
+// Planner places chunks in place of the chunk strategy, for custom scene
+// detection or boundaries known in advance. PlanChunks receives the video and
+// the scenes the chunk strategy chose, and returns chunks covering every
+// frame in order. Chunk indexes are assigned by position.
+type Planner interface {
+	PlanChunks(video *ffms.VidInf, scenes []Scene) ([]Chunk, error)
+}
+
+// StartsOf returns the start frames of chunks, which must cover frames 0 to
+// totalFrames in order without gaps or overlaps.
+func StartsOf(chunks []Chunk, totalFrames int) ([]int, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no chunks")
+	}
+
+	starts := make([]int, 0, len(chunks))
+	next := 0
+	for i, c := range chunks {
+		if c.Start != next {
+			return nil, fmt.Errorf("chunk %d starts at frame %d, expected %d", i, c.Start, next)
+		}
+		if c.End <= c.Start {
+			return nil, fmt.Errorf("chunk %d is empty (frames %d-%d)", i, c.Start, c.End)
+		}
+		starts = append(starts, c.Start)
+		next = c.End
+	}
+	if next != totalFrames {
+		return nil, fmt.Errorf("chunks end at frame %d, video has %d frames", next, totalFrames)
+	}
+
+	return starts, nil
+}
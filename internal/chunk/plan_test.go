@@ -0,0 +1,74 @@
+package chunk
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStartsOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunks  []Chunk
+		frames  int
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:   "contiguous chunks",
+			chunks: []Chunk{{Start: 0, End: 100}, {Start: 100, End: 250}, {Start: 250, End: 300}},
+			frames: 300,
+			want:   []int{0, 100, 250},
+		},
+		{
+			name:   "indexes are ignored",
+			chunks: []Chunk{{Idx: 5, Start: 0, End: 300}},
+			frames: 300,
+			want:   []int{0},
+		},
+		{name: "no chunks", frames: 300, wantErr: true},
+		{
+			name:    "first chunk misses frame 0",
+			chunks:  []Chunk{{Start: 10, End: 300}},
+			frames:  300,
+			wantErr: true,
+		},
+		{
+			name:    "gap between chunks",
+			chunks:  []Chunk{{Start: 0, End: 100}, {Start: 120, End: 300}},
+			frames:  300,
+			wantErr: true,
+		},
+		{
+			name:    "empty chunk",
+			chunks:  []Chunk{{Start: 0, End: 100}, {Start: 100, End: 100}, {Start: 100, End: 300}},
+			frames:  300,
+			wantErr: true,
+		},
+		{
+			name:    "chunks stop short of the end",
+			chunks:  []Chunk{{Start: 0, End: 200}},
+			frames:  300,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StartsOf(tt.chunks, tt.frames)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartsOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("StartsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScenesFromStarts(t *testing.T) {
+	got := ScenesFromStarts([]int{0, 100, 100, 250}, 300)
+	want := []Scene{{0, 100}, {100, 250}, {250, 300}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ScenesFromStarts() = %v, want %v", got, want)
+	}
+}
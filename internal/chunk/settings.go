@@ -0,0 +1,86 @@
+package chunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SettingsFilename is the name of the attached encode settings file.
+const SettingsFilename = "reel-settings.json"
+
+// Container tags the encode settings are written to.
+const (
+	tagVersion   = "REEL_VERSION"
+	tagSource    = "REEL_SOURCE"
+	tagCRF       = "REEL_CRF"
+	tagPreset    = "REEL_PRESET"
+	tagSvtParams = "REEL_SVT_PARAMS"
+)
+
+// Settings records how an output was encoded. MuxFinal writes it as
+// container tags, and as an attached JSON file when Attach is set, so an
+// encoded file describes itself.
+type Settings struct {
+	Version   string `json:"version"`
+	Source    string `json:"source"` // Source file name, without its directory
+	CRF       uint32 `json:"crf"`
+	Preset    uint8  `json:"preset"`
+	SvtParams string `json:"svt_params"`
+
+	Attach bool `json:"-"` // Also attach the settings as JSON where the container allows
+}
+
+// metadataArgs returns the ffmpeg arguments that write s as global tags.
+func (s *Settings) metadataArgs() []string {
+	var args []string
+	for _, tag := range []struct{ key, value string }{
+		{tagVersion, s.Version},
+		{tagSource, s.Source},
+		{tagCRF, strconv.FormatUint(uint64(s.CRF), 10)},
+		{tagPreset, strconv.FormatUint(uint64(s.Preset), 10)},
+		{tagSvtParams, s.SvtParams},
+	} {
+		if tag.value != "" {
+			args = append(args, "-metadata", tag.key+"="+tag.value)
+		}
+	}
+	return args
+}
+
+// writeSettingsFile writes s as JSON into workDir for attaching.
+func writeSettingsFile(workDir string, s *Settings) (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode settings: %w", err)
+	}
+	path := filepath.Join(workDir, SettingsFilename)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write settings: %w", err)
+	}
+	return path, nil
+}
+
+// SettingsFromTags recovers encode settings from container tags, as
+// returned by ffprobe.GetFormatTags. It returns nil when the file wasn't
+// encoded by reel.
+func SettingsFromTags(tags map[string]string) *Settings {
+	version, ok := tags[tagVersion]
+	if !ok {
+		return nil
+	}
+	s := &Settings{
+		Version:   version,
+		Source:    tags[tagSource],
+		SvtParams: tags[tagSvtParams],
+	}
+	if crf, err := strconv.ParseUint(tags[tagCRF], 10, 32); err == nil {
+		s.CRF = uint32(crf)
+	}
+	if preset, err := strconv.ParseUint(tags[tagPreset], 10, 8); err == nil {
+		s.Preset = uint8(preset)
+	}
+	return s
+}
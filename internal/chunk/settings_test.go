@@ -0,0 +1,60 @@
+package chunk
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSettingsRoundTrip(t *testing.T) {
+	s := &Settings{
+		Version:   "0.2.0",
+		Source:    "movie.mkv",
+		CRF:       27,
+		Preset:    6,
+		SvtParams: "--crf 27 --preset 6 --tune 0 --scm 0 --ac-bias 0.10",
+	}
+
+	// ffmpeg -metadata arguments come in pairs of flag and KEY=value
+	args := s.metadataArgs()
+	tags := map[string]string{}
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] != "-metadata" {
+			t.Fatalf("unexpected argument %q in %q", args[i], args)
+		}
+		key, value, _ := strings.Cut(args[i+1], "=")
+		tags[key] = value
+	}
+
+	got := SettingsFromTags(tags)
+	if got == nil || *got != *s {
+		t.Errorf("SettingsFromTags() = %+v, want %+v", got, s)
+	}
+}
+
+func TestSettingsFromTagsNotReel(t *testing.T) {
+	if got := SettingsFromTags(map[string]string{"ENCODER": "Lavf61.7.100"}); got != nil {
+		t.Errorf("SettingsFromTags() = %+v, want nil", got)
+	}
+}
+
+func TestWriteSettingsFile(t *testing.T) {
+	s := &Settings{Version: "0.2.0", Source: "movie.mkv", CRF: 27, Preset: 6, Attach: true}
+	path, err := writeSettingsFile(t.TempDir(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Settings
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	if got.CRF != 27 || got.Source != "movie.mkv" || got.Attach {
+		t.Errorf("settings file = %+v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package chunk
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// workDirPrefix is the name prefix shared by all work directories.
+const workDirPrefix = ".reel-"
+
+// WorkDirInfo describes an existing work directory.
+type WorkDirInfo struct {
+	Path    string
+	Size    uint64    // Total size of all files in bytes
+	ModTime time.Time // Most recent modification time of any file inside
+}
+
+// FindWorkDirs returns work directories directly under dir whose contents have
+// not been modified for at least minAge. A zero minAge matches all of them.
+func FindWorkDirs(dir string, minAge time.Duration) ([]WorkDirInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	now := time.Now()
+	var found []WorkDirInfo
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), workDirPrefix) {
+			continue
+		}
+		info, err := scanWorkDir(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if now.Sub(info.ModTime) >= minAge {
+			found = append(found, info)
+		}
+	}
+	return found, nil
+}
+
+// scanWorkDir totals the size of a work directory and finds its newest file,
+// so a directory with an encode still writing chunks is never considered stale.
+func scanWorkDir(path string) (WorkDirInfo, error) {
+	info := WorkDirInfo{Path: path}
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip entries we can't access
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if fi.ModTime().After(info.ModTime) {
+			info.ModTime = fi.ModTime()
+		}
+		if !d.IsDir() {
+			info.Size += uint64(fi.Size())
+		}
+		return nil
+	})
+	return info, err
+}
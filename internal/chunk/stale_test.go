@@ -0,0 +1,50 @@
+package chunk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindWorkDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	old := filepath.Join(dir, ".reel-old")
+	fresh := filepath.Join(dir, ".reel-fresh")
+	other := filepath.Join(dir, "not-reel")
+	for _, d := range []string{old, fresh, other} {
+		if err := os.MkdirAll(filepath.Join(d, "encode"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "encode", "0000.ivf"), make([]byte, 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	past := time.Now().Add(-48 * time.Hour)
+	for _, p := range []string{filepath.Join(old, "encode", "0000.ivf"), filepath.Join(old, "encode"), old} {
+		if err := os.Chtimes(p, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := FindWorkDirs(dir, 0)
+	if err != nil {
+		t.Fatalf("FindWorkDirs() error = %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("FindWorkDirs(0) returned %d dirs, want 2", len(all))
+	}
+
+	stale, err := FindWorkDirs(dir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("FindWorkDirs() error = %v", err)
+	}
+	if len(stale) != 1 || stale[0].Path != old {
+		t.Fatalf("FindWorkDirs(24h) = %+v, want only %s", stale, old)
+	}
+	if stale[0].Size != 100 {
+		t.Errorf("Size = %d, want 100", stale[0].Size)
+	}
+}
@@ -0,0 +1,52 @@
+package chunk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Totals records the chunk layout an encode was started with, so a resumed
+// encode can tell whether its completed chunks still apply.
+type Totals struct {
+	Frames int
+	Chunks int
+}
+
+const totalsFile = "totals.txt"
+
+// WriteTotals saves the chunk layout to the work directory.
+func WriteTotals(workDir string, t Totals) error {
+	data := fmt.Sprintf("%d %d\n", t.Frames, t.Chunks)
+	if err := os.WriteFile(filepath.Join(workDir, totalsFile), []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write totals file: %w", err)
+	}
+	return nil
+}
+
+// ReadTotals loads the chunk layout saved by WriteTotals.
+// Returns false if no layout has been saved yet.
+func ReadTotals(workDir string) (Totals, bool, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, totalsFile))
+	if os.IsNotExist(err) {
+		return Totals{}, false, nil
+	}
+	if err != nil {
+		return Totals{}, false, fmt.Errorf("failed to read totals file: %w", err)
+	}
+
+	var t Totals
+	if _, err := fmt.Sscanf(string(data), "%d %d", &t.Frames, &t.Chunks); err != nil {
+		return Totals{}, false, fmt.Errorf("invalid totals file: %w", err)
+	}
+	return t, true, nil
+}
+
+// ResetResume discards completed chunk records so every chunk is re-encoded.
+func ResetResume(workDir string) error {
+	err := os.Remove(filepath.Join(workDir, "done.txt"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset resume file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package chunk
+
+import "testing"
+
+func TestTotalsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, ok, err := ReadTotals(dir); err != nil || ok {
+		t.Fatalf("ReadTotals on empty dir = ok %v, err %v; want false, nil", ok, err)
+	}
+
+	want := Totals{Frames: 14400, Chunks: 60}
+	if err := WriteTotals(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := ReadTotals(dir)
+	if err != nil || !ok || got != want {
+		t.Errorf("ReadTotals = %+v, %v, %v; want %+v, true, nil", got, ok, err, want)
+	}
+}
+
+func TestResumeValid(t *testing.T) {
+	chunks := []Chunk{
+		{Idx: 0, Start: 0, End: 240},
+		{Idx: 1, Start: 240, End: 480},
+		{Idx: 2, Start: 480, End: 600},
+	}
+
+	dir := t.TempDir()
+	for _, c := range []ChunkComp{
+		{Idx: 0, Frames: 240, Size: 1000},
+		{Idx: 1, Frames: 240, Size: 1100},
+		{Idx: 1, Frames: 240, Size: 1100}, // duplicate record
+		{Idx: 2, Frames: 300, Size: 900},  // different layout
+		{Idx: 7, Frames: 240, Size: 800},  // unknown chunk
+	} {
+		if err := AppendDone(c, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resume, err := GetResume(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := resume.Valid(chunks)
+
+	if got := len(valid.ChunksDone); got != 2 {
+		t.Errorf("valid chunks = %d, want 2", got)
+	}
+	if got := valid.TotalEncodedFrames(); got != 480 {
+		t.Errorf("TotalEncodedFrames = %d, want 480", got)
+	}
+	if got := valid.TotalEncodedSize(); got != 2100 {
+		t.Errorf("TotalEncodedSize = %d, want 2100", got)
+	}
+
+	if err := ResetResume(dir); err != nil {
+		t.Fatal(err)
+	}
+	resume, err = GetResume(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resume.ChunksDone) != 0 {
+		t.Errorf("chunks after ResetResume = %d, want 0", len(resume.ChunksDone))
+	}
+}
@@ -0,0 +1,181 @@
+// Package cmdlog records the external commands reel runs (ffmpeg, ffprobe,
+// mediainfo, SvtAv1EncApp and others) with their exit status, to the log
+// file and to a replayable shell script, for debugging encode issues.
+package cmdlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mu     sync.Mutex
+	logf   func(format string, args ...any)
+	script *os.File
+)
+
+// SetLog makes every command finished from now on be logged through f,
+// such as a logger's Debug method. A nil f stops logging.
+func SetLog(f func(format string, args ...any)) {
+	mu.Lock()
+	defer mu.Unlock()
+	logf = f
+}
+
+// OpenScript starts writing every command finished from now on to a shell
+// script at path, replacing any file there.
+func OpenScript(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create command script: %w", err)
+	}
+	header := fmt.Sprintf("#!/bin/sh\n# Commands run by reel on %s, in the order they finished.\n"+
+		"# Encoder commands read frames that reel decodes itself; each is fed by\n"+
+		"# an ffmpeg command producing the same frames.\n",
+		time.Now().Format("2006-01-02 15:04:05"))
+	if _, err := f.WriteString(header); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write command script: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if script != nil {
+		_ = script.Close()
+	}
+	script = f
+	return nil
+}
+
+// CloseScript stops writing commands to the script and closes it.
+func CloseScript() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if script == nil {
+		return nil
+	}
+	err := script.Close()
+	script = nil
+	return err
+}
+
+// Scripting reports whether commands are being written to a script, so
+// callers only work out how to replay a command's input when it is used.
+func Scripting() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return script != nil
+}
+
+// Run runs cmd like cmd.Run and records it.
+func Run(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	record(cmd, nil, start, err)
+	return err
+}
+
+// Output runs cmd like cmd.Output and records it.
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	out, err := cmd.Output()
+	record(cmd, nil, start, err)
+	return out, err
+}
+
+// CombinedOutput runs cmd like cmd.CombinedOutput and records it.
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	record(cmd, nil, start, err)
+	return out, err
+}
+
+// Process is a started command, recorded once it is waited for.
+type Process struct {
+	cmd   *exec.Cmd
+	input []string
+	start time.Time
+}
+
+// Start starts cmd like cmd.Start. If reel writes cmd's stdin itself,
+// input is a command producing the same data, which the script pipes into
+// cmd; it is nil otherwise. A command that fails to start is recorded.
+func Start(cmd *exec.Cmd, input []string) (*Process, error) {
+	p := &Process{cmd: cmd, input: input, start: time.Now()}
+	if err := cmd.Start(); err != nil {
+		record(cmd, input, p.start, err)
+		return nil, err
+	}
+	return p, nil
+}
+
+// Wait waits for the command like cmd.Wait and records it.
+func (p *Process) Wait() error {
+	err := p.cmd.Wait()
+	record(p.cmd, p.input, p.start, err)
+	return err
+}
+
+// record logs a finished command and appends it to the script.
+func record(cmd *exec.Cmd, input []string, start time.Time, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if logf == nil && script == nil {
+		return
+	}
+	line := CommandLine(cmd.Args, input)
+	status := exitStatus(err)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if logf != nil {
+		logf("Command (%s, %s): %s", status, elapsed, line)
+	}
+	if script != nil {
+		_, _ = fmt.Fprintf(script, "\n# %s, %s\n%s\n", status, elapsed, line)
+	}
+}
+
+// exitStatus describes how a command ended.
+func exitStatus(err error) string {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return "exit status 0"
+	case errors.As(err, &exitErr):
+		return exitErr.String()
+	default:
+		return "not run: " + err.Error()
+	}
+}
+
+// CommandLine formats args as a shell command, piped from input if it is
+// not empty.
+func CommandLine(args, input []string) string {
+	line := quoteArgs(args)
+	if len(input) > 0 {
+		line = quoteArgs(input) + " | " + line
+	}
+	return line
+}
+
+// safeArg matches arguments the shell takes literally.
+var safeArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// quoteArgs joins args, single-quoting those the shell would interpret.
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if safeArg.MatchString(a) {
+			quoted[i] = a
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
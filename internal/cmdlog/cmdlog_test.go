@@ -0,0 +1,66 @@
+package cmdlog
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input []string
+		want  string
+	}{
+		{"plain", []string{"ffprobe", "-v", "error", "/a/b.mkv"}, nil, "ffprobe -v error /a/b.mkv"},
+		{"spaces", []string{"ffmpeg", "-i", "/a/My Movie.mkv"}, nil, "ffmpeg -i '/a/My Movie.mkv'"},
+		{"quote", []string{"echo", "it's"}, nil, `echo 'it'\''s'`},
+		{"empty arg", []string{"x", ""}, nil, "x ''"},
+		{"filter", []string{"ffmpeg", "-vf", "crop=1920:800:0:140,select=between(n\\,0\\,9)"}, nil,
+			`ffmpeg -vf 'crop=1920:800:0:140,select=between(n\,0\,9)'`},
+		{"piped", []string{"SvtAv1EncApp", "-i", "stdin"}, []string{"ffmpeg", "-f", "rawvideo", "-"},
+			"ffmpeg -f rawvideo - | SvtAv1EncApp -i stdin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommandLine(tt.args, tt.input); got != tt.want {
+				t.Errorf("CommandLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.sh")
+	if err := OpenScript(path); err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	SetLog(func(format string, args ...any) { logged = append(logged, format) })
+	defer SetLog(nil)
+
+	_ = Run(exec.Command("sh", "-c", "exit 3"))
+	if _, err := Output(exec.Command("true")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseScript(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+	for _, want := range []string{"#!/bin/sh\n", "# exit status 3, ", "\nsh -c 'exit 3'\n", "# exit status 0, ", "\ntrue\n"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if len(logged) != 2 {
+		t.Errorf("logged %d commands, want 2", len(logged))
+	}
+}
@@ -0,0 +1,307 @@
+// Package compare compares two encodes of the same source.
+package compare
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+const (
+	// DefaultSamples is the default number of positions sampled for metrics.
+	DefaultSamples = 10
+
+	// sampleSecs is the length of each metric sample.
+	sampleSecs = 2.0
+)
+
+var (
+	ssimRegex = regexp.MustCompile(`SSIM .*All:([0-9.]+)`)
+	psnrRegex = regexp.MustCompile(`PSNR .*average:([0-9.]+|inf)`)
+)
+
+// BitrateStats summarises per-second video bitrate in kbps.
+type BitrateStats struct {
+	Min    float64
+	Median float64
+	P95    float64
+	Max    float64
+}
+
+// FileSummary contains the measured properties of one encode.
+type FileSummary struct {
+	Path         string
+	Size         uint64
+	DurationSecs float64
+	Width        uint32
+	Height       uint32
+	Bitrate      uint64 // Overall container bitrate in bits per second
+	VideoBitrate BitrateStats
+	Streams      []ffprobe.StreamInfo
+	Settings     *chunk.Settings // Encode settings from the container tags; nil if not encoded by reel
+}
+
+// MetricSample contains similarity scores between the two encodes at one position.
+type MetricSample struct {
+	Position float64 // Seconds from start
+	SSIM     float64
+	PSNR     float64 // math.Inf(1) when the frames are identical
+}
+
+// Report is the result of comparing two encodes.
+type Report struct {
+	A                 FileSummary
+	B                 FileSummary
+	Samples           []MetricSample
+	MeanSSIM          float64
+	MeanPSNR          float64
+	LayoutDifferences []string
+}
+
+// Compare measures both files and samples SSIM/PSNR between them.
+// The metrics describe how similar B is to A, so values close to 1.0 SSIM
+// (or high PSNR) mean a settings change had little visible effect.
+func Compare(ctx context.Context, paths toolpath.Paths, pathA, pathB string, samples int) (*Report, error) {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+
+	a, err := summarize(paths, pathA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := summarize(paths, pathB)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		A:                 *a,
+		B:                 *b,
+		LayoutDifferences: compareLayouts(a.Streams, b.Streams),
+	}
+
+	duration := math.Min(a.DurationSecs, b.DurationSecs)
+	var ssimTotal, psnrTotal float64
+	var psnrCount int
+	for _, pos := range samplePositions(duration, samples) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		sample, err := measureAt(ctx, paths, a, b, pos)
+		if err != nil {
+			return nil, err
+		}
+		report.Samples = append(report.Samples, sample)
+		ssimTotal += sample.SSIM
+		if !math.IsInf(sample.PSNR, 1) {
+			psnrTotal += sample.PSNR
+			psnrCount++
+		}
+	}
+
+	if len(report.Samples) > 0 {
+		report.MeanSSIM = ssimTotal / float64(len(report.Samples))
+	}
+	if psnrCount > 0 {
+		report.MeanPSNR = psnrTotal / float64(psnrCount)
+	} else if len(report.Samples) > 0 {
+		report.MeanPSNR = math.Inf(1)
+	}
+
+	return report, nil
+}
+
+// summarize collects size, bitrate, and stream layout for one file.
+func summarize(paths toolpath.Paths, path string) (*FileSummary, error) {
+	size, err := util.GetFileSize(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	props, err := ffprobe.GetVideoProperties(paths, path)
+	if err != nil {
+		return nil, err
+	}
+
+	streams, err := ffprobe.GetStreams(paths, path)
+	if err != nil {
+		return nil, err
+	}
+
+	packets, err := ffprobe.GetVideoPackets(paths, path)
+	if err != nil {
+		return nil, err
+	}
+
+	bitrate, err := ffprobe.GetFormatBitrate(paths, path)
+	if err != nil && props.DurationSecs > 0 {
+		bitrate = uint64(float64(size) * 8 / props.DurationSecs)
+	}
+
+	tags, err := ffprobe.GetFormatTags(paths, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSummary{
+		Path:         path,
+		Size:         size,
+		DurationSecs: props.DurationSecs,
+		Width:        props.Width,
+		Height:       props.Height,
+		Bitrate:      bitrate,
+		VideoBitrate: bitrateStats(packets),
+		Streams:      streams,
+		Settings:     chunk.SettingsFromTags(tags),
+	}, nil
+}
+
+// bitrateStats buckets packets into one-second windows and summarises them.
+func bitrateStats(packets []ffprobe.PacketSample) BitrateStats {
+	buckets := make(map[int]uint64)
+	for _, p := range packets {
+		if p.Time < 0 {
+			continue
+		}
+		buckets[int(p.Time)] += p.Size
+	}
+	if len(buckets) == 0 {
+		return BitrateStats{}
+	}
+
+	rates := make([]float64, 0, len(buckets))
+	for _, bytes := range buckets {
+		rates = append(rates, float64(bytes)*8/1000)
+	}
+	sort.Float64s(rates)
+
+	return BitrateStats{
+		Min:    rates[0],
+		Median: percentile(rates, 0.5),
+		P95:    percentile(rates, 0.95),
+		Max:    rates[len(rates)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	idx = max(0, min(idx, len(sorted)-1))
+	return sorted[idx]
+}
+
+// samplePositions spreads n sample start times across 10-90% of the duration.
+func samplePositions(duration float64, n int) []float64 {
+	if duration <= sampleSecs {
+		return []float64{0}
+	}
+	if n == 1 {
+		return []float64{duration / 2}
+	}
+
+	start := duration * 0.1
+	span := duration*0.8 - sampleSecs
+	positions := make([]float64, n)
+	for i := range n {
+		positions[i] = start + span*float64(i)/float64(n-1)
+	}
+	return positions
+}
+
+// measureAt computes SSIM and PSNR of B against A for a short sample.
+// B is scaled to A's dimensions so encodes with different crops still compare.
+func measureAt(ctx context.Context, paths toolpath.Paths, a, b *FileSummary, pos float64) (MetricSample, error) {
+	filter := fmt.Sprintf(
+		"[1:v]scale=%d:%d,format=yuv420p10le[b];[0:v]format=yuv420p10le[a];[a]split[a1][a2];[b]split[b1][b2];[a1][b1]ssim;[a2][b2]psnr",
+		a.Width, a.Height,
+	)
+	ss := fmt.Sprintf("%.2f", pos)
+	dur := fmt.Sprintf("%.2f", sampleSecs)
+
+	cmd := exec.CommandContext(ctx, paths.Path(toolpath.FFmpeg),
+		"-hide_banner", "-nostats",
+		"-ss", ss, "-t", dur, "-i", a.Path,
+		"-ss", ss, "-t", dur, "-i", b.Path,
+		"-lavfi", filter,
+		"-f", "null", "-",
+	)
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return MetricSample{}, fmt.Errorf("failed to create ffmpeg pipe: %w", err)
+	}
+	proc, err := cmdlog.Start(cmd, nil)
+	if err != nil {
+		return MetricSample{}, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	sample := MetricSample{Position: pos}
+	var sawSSIM bool
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := ssimRegex.FindStringSubmatch(line); m != nil {
+			sample.SSIM, _ = strconv.ParseFloat(m[1], 64)
+			sawSSIM = true
+		}
+		if m := psnrRegex.FindStringSubmatch(line); m != nil {
+			sample.PSNR = parsePSNR(m[1])
+		}
+	}
+
+	if err := proc.Wait(); err != nil {
+		return MetricSample{}, fmt.Errorf("metric sample at %.1fs failed: %w", pos, err)
+	}
+	if !sawSSIM {
+		return MetricSample{}, fmt.Errorf("no metric output for sample at %.1fs", pos)
+	}
+
+	return sample, nil
+}
+
+// parsePSNR parses an ffmpeg PSNR value, which is "inf" for identical frames.
+func parsePSNR(s string) float64 {
+	if s == "inf" {
+		return math.Inf(1)
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// compareLayouts describes differences between two stream layouts.
+func compareLayouts(a, b []ffprobe.StreamInfo) []string {
+	var diffs []string
+	if len(a) != len(b) {
+		diffs = append(diffs, fmt.Sprintf("stream count differs: %d vs %d", len(a), len(b)))
+	}
+
+	for i := range min(len(a), len(b)) {
+		sa, sb := a[i], b[i]
+		switch {
+		case sa.CodecType != sb.CodecType:
+			diffs = append(diffs, fmt.Sprintf("stream %d: %s vs %s", i, sa.CodecType, sb.CodecType))
+		case sa.CodecName != sb.CodecName:
+			diffs = append(diffs, fmt.Sprintf("stream %d (%s): codec %s vs %s", i, sa.CodecType, sa.CodecName, sb.CodecName))
+		case sa.Channels != sb.Channels:
+			diffs = append(diffs, fmt.Sprintf("stream %d (%s): %d vs %d channels", i, sa.CodecType, sa.Channels, sb.Channels))
+		case sa.Width != sb.Width || sa.Height != sb.Height:
+			diffs = append(diffs, fmt.Sprintf("stream %d (%s): %dx%d vs %dx%d", i, sa.CodecType, sa.Width, sa.Height, sb.Width, sb.Height))
+		case sa.Language != sb.Language:
+			diffs = append(diffs, fmt.Sprintf("stream %d (%s): language %q vs %q", i, sa.CodecType, sa.Language, sb.Language))
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,82 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestBitrateStats(t *testing.T) {
+	// Four one-second windows of 1000, 2000, 3000 and 4000 bytes
+	var packets []ffprobe.PacketSample
+	for sec, bytes := range []uint64{1000, 2000, 3000, 4000} {
+		packets = append(packets,
+			ffprobe.PacketSample{Time: float64(sec), Size: bytes / 2},
+			ffprobe.PacketSample{Time: float64(sec) + 0.5, Size: bytes / 2},
+		)
+	}
+
+	stats := bitrateStats(packets)
+	if stats.Min != 8 {
+		t.Errorf("Min = %v, want 8", stats.Min)
+	}
+	if stats.Median != 16 {
+		t.Errorf("Median = %v, want 16", stats.Median)
+	}
+	if stats.P95 != 32 || stats.Max != 32 {
+		t.Errorf("P95/Max = %v/%v, want 32/32", stats.P95, stats.Max)
+	}
+
+	if got := bitrateStats(nil); got != (BitrateStats{}) {
+		t.Errorf("bitrateStats(nil) = %+v, want zero value", got)
+	}
+}
+
+func TestSamplePositions(t *testing.T) {
+	positions := samplePositions(100, 5)
+	if len(positions) != 5 {
+		t.Fatalf("got %d positions, want 5", len(positions))
+	}
+	if positions[0] != 10 {
+		t.Errorf("first position = %v, want 10", positions[0])
+	}
+	last := positions[len(positions)-1]
+	if last+sampleSecs > 90.0001 {
+		t.Errorf("last sample ends at %v, want <= 90", last+sampleSecs)
+	}
+
+	if got := samplePositions(1, 5); len(got) != 1 || got[0] != 0 {
+		t.Errorf("short duration positions = %v, want [0]", got)
+	}
+}
+
+func TestParsePSNR(t *testing.T) {
+	if !math.IsInf(parsePSNR("inf"), 1) {
+		t.Error("parsePSNR(inf) should be +Inf")
+	}
+	if got := parsePSNR("42.5"); got != 42.5 {
+		t.Errorf("parsePSNR(42.5) = %v", got)
+	}
+}
+
+func TestCompareLayouts(t *testing.T) {
+	a := []ffprobe.StreamInfo{
+		{CodecType: "video", CodecName: "av1", Width: 1920, Height: 800},
+		{CodecType: "audio", CodecName: "opus", Channels: 6, Language: "eng"},
+	}
+
+	if diffs := compareLayouts(a, a); len(diffs) != 0 {
+		t.Errorf("identical layouts reported differences: %v", diffs)
+	}
+
+	b := []ffprobe.StreamInfo{
+		{CodecType: "video", CodecName: "av1", Width: 1920, Height: 1080},
+		{CodecType: "audio", CodecName: "opus", Channels: 2, Language: "eng"},
+		{CodecType: "subtitle", CodecName: "subrip"},
+	}
+	diffs := compareLayouts(a, b)
+	if len(diffs) != 3 {
+		t.Errorf("got %d differences, want 3: %v", len(diffs), diffs)
+	}
+}
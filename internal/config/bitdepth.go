@@ -0,0 +1,12 @@
+package config
+
+// Bit depth modes decide whether SDR sources are encoded at 8 or 10 bits.
+// HDR sources are always encoded at 10 bits.
+const (
+	BitDepth10   = "10"   // Every source at 10 bits; 8-bit sources are upconverted
+	BitDepth8    = "8"    // SDR sources at 8 bits, rounding 10-bit ones down
+	BitDepthAuto = "auto" // SDR sources at their own depth: 8-bit at 8, others at 10
+)
+
+// BitDepths lists the accepted --bit-depth values.
+var BitDepths = []string{BitDepth10, BitDepth8, BitDepthAuto}
@@ -0,0 +1,10 @@
+package config
+
+// Checksum algorithms a digest of each output can be computed with.
+const (
+	ChecksumNone   = ""       // No checksum
+	ChecksumSHA256 = "sha256" // SHA-256, written to <output>.sha256
+)
+
+// Checksums lists the accepted --checksum values.
+var Checksums = []string{ChecksumSHA256}
@@ -0,0 +1,10 @@
+package config
+
+// Chunk strategies decide where chunk boundaries fall.
+const (
+	ChunkFixed    = "fixed"    // Every chunk spans the chunk duration
+	ChunkBalanced = "balanced" // Chunks are sized so each takes about as long to encode
+)
+
+// ChunkStrategies lists the accepted --chunk-strategy values.
+var ChunkStrategies = []string{ChunkFixed, ChunkBalanced}
@@ -1,7 +1,15 @@
 // Package config provides configuration types and defaults for reel.
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
 
 // Default constants
 const (
@@ -32,15 +40,21 @@ const (
 	// DefaultSVTAV1EnableVarianceBoost is whether variance boost is enabled.
 	DefaultSVTAV1EnableVarianceBoost bool = false
 
-	// DefaultSVTAV1VarianceBoostStrength is the variance boost strength.
-	DefaultSVTAV1VarianceBoostStrength uint8 = 0
+	// DefaultSVTAV1VarianceBoostStrength is the variance boost strength (1-4),
+	// used when variance boost is enabled.
+	DefaultSVTAV1VarianceBoostStrength uint8 = 2
 
-	// DefaultSVTAV1VarianceOctile is the variance octile parameter.
-	DefaultSVTAV1VarianceOctile uint8 = 0
+	// DefaultSVTAV1VarianceOctile is the variance octile parameter (1-8),
+	// used when variance boost is enabled.
+	DefaultSVTAV1VarianceOctile uint8 = 6
 
 	// DefaultCropMode is the crop mode for the main encode.
 	DefaultCropMode string = "auto"
 
+	// DefaultCropConfidence is the share of crop samples that must agree
+	// on one crop before it is used.
+	DefaultCropConfidence float64 = 0.8
+
 	// DefaultEncodeCooldownSecs is the cooldown period between encodes.
 	DefaultEncodeCooldownSecs uint64 = 3
 
@@ -53,6 +67,29 @@ const (
 	DefaultChunkDurationHD  float64 = 30.0 // 1080p: balanced
 	DefaultChunkDurationUHD float64 = 45.0 // 4K: slower encode, needs longer warmup
 
+	// DefaultKeyintSecs is the maximum keyframe interval in seconds.
+	DefaultKeyintSecs float64 = 10.0
+
+	// DefaultDecodeAhead is how many frames each worker decodes ahead of its
+	// encoder.
+	DefaultDecodeAhead int = 2
+
+	// DefaultChunkRetries is how many times a chunk whose encoder was killed
+	// by a signal is retried before the encode fails.
+	DefaultChunkRetries int = 2
+
+	// DefaultChunkTimeoutSecs is the time allowed per 1080p frame of a chunk
+	// before its encoder is taken to be hung. It is generous, as a slow
+	// preset on a busy machine must not be mistaken for a hang.
+	DefaultChunkTimeoutSecs float64 = 30
+
+	// DefaultIndexCacheMaxMB caps the size of the FFMS2 index cache.
+	DefaultIndexCacheMaxMB uint64 = 2048
+
+	// MaxDialogueBoostDB caps the center channel boost in a stereo downmix;
+	// beyond it the normalized mix leaves music and effects too quiet.
+	MaxDialogueBoostDB float64 = 12
+
 	// DefaultThreadsPerWorker of 0 means auto-calculate based on CPU topology.
 	// Auto mode detects physical cores and SMT, then calculates optimal threads
 	// based on resolution. Override with --threads flag if needed.
@@ -79,31 +116,183 @@ type Config struct {
 	TempDir   string // Optional, defaults to OutputDir
 
 	// SVT-AV1 parameters
-	SVTAV1Preset                uint8
 	SVTAV1Tune                  uint8
 	SVTAV1ACBias                float32
 	SVTAV1EnableVarianceBoost   bool
 	SVTAV1VarianceBoostStrength uint8
 	SVTAV1VarianceOctile        uint8
+	SVTAV1SCM                   uint8 // Screen content mode (0 = off, 1 = on)
+	SVTAV1SceneDetection        bool  // Keyframes at scene changes within a chunk (--scd)
+	SVTAV1TileRows              uint8 // Tile rows, a power of two (1 = no row tiling)
+	SVTAV1TileColumns           uint8 // Tile columns, a power of two (1 = no column tiling)
+
+	// SVTAV1ExtraParams are additional SvtAv1EncApp parameters as
+	// "key=value:key=value", appended after reel's own
+	SVTAV1ExtraParams string
+
+	// BitDepth is the output bit depth for SDR sources (see BitDepths)
+	BitDepth string
+
+	// Set when tune or ac-bias were given explicitly or by a profile;
+	// content tuning leaves them alone
+	TuneExplicit   bool
+	ACBiasExplicit bool
 
 	// Quality settings (CRF value 0-63) by resolution
 	CRFSD  uint8 // CRF for SD content (<1920 width)
 	CRFHD  uint8 // CRF for HD content (>=1920, <3840 width)
 	CRFUHD uint8 // CRF for UHD content (>=3840 width)
 
+	// SVT-AV1 preset (0-13) by resolution
+	PresetSD  uint8 // Preset for SD content (<1920 width)
+	PresetHD  uint8 // Preset for HD content (>=1920, <3840 width)
+	PresetUHD uint8 // Preset for UHD content (>=3840 width)
+
+	// Source handling
+	Profile             string  // Built-in profile applied to these settings ("" = default)
+	Deinterlace         bool    // Deinterlace sources detected as interlaced before encoding
+	AssumeRec601        bool    // Tag untagged SD sources as Rec.601 (NTSC or PAL by height)
+	KeyintSecs          float64 // Maximum keyframe interval in seconds
+	AudioKbpsPerChannel uint32  // Opus bitrate per channel (0 = built-in table)
+	PALSlowdown         bool    // Slow 25fps sources to 23.976fps, time-stretching audio
+	StereoDownmix       bool    // Add a stereo downmix of the first surround stream
+	DialogueBoostDB     float64 // Center channel gain in the downmix, in dB
+
+	// Per-title settings (usually from a sidecar file)
+	FilmGrain        uint8    // SVT-AV1 film grain synthesis level (0 = off)
+	FilmGrainDenoise bool     // Denoise before grain synthesis
+	FilmGrainTable   string   // Film grain table path ("" = none)
+	VideoStream      *int     // Video stream to encode, counted from 0 (nil = the primary one)
+	AudioTracks      []int    // Audio stream indexes to keep (nil = all)
+	AudioLanguages   []string // Audio language tags to keep (nil = all)
+	AudioPassthrough []string // Lossless audio formats to copy instead of encoding
+	PreserveAtmos    bool     // Copy Dolby Atmos and DTS:X streams instead of encoding
+	DropAttachments  bool     // Leave attachments such as subtitle fonts out of the output
+
+	// Named profiles from the config file, layered over built-ins of the same name
+	CustomProfiles map[string]ProfileSettings
+
 	// Processing options
-	CropMode           string // "auto" or "none"
-	EncodeCooldownSecs uint64 // Cooldown between batch encodes
+	ContentType        string       // "auto" or a content type to tune for
+	CropMode           string       // "auto" or "none"
+	CropFilter         string       // Manual centered crop "W:H:X:Y", used instead of detection
+	CropConfidence     float64      // Share of crop samples that must agree to crop (0-1)
+	EncodeCooldownSecs uint64       // Cooldown between batch encodes
+	GrowingQuietSecs   uint64       // Encode inputs while they grow; complete once quiet this long (0 = off)
+	Schedule           *util.Window // Only start files and chunks inside this daily window (nil = always)
+	Deadline           time.Time    // Start no new files after this (zero = no deadline)
+	DeadlineChunks     bool         // Also start no new chunks after the deadline
+	FailFast           bool         // Stop the batch at the first failed file instead of continuing
+	Prefetch           bool         // Analyze the next file in a batch while the current one encodes
+	BatchStateDir      string       // Where batch progress is kept so a rerun continues it ("" = don't keep)
 
 	// Parallel encoding options
 	Workers          int // Number of parallel encoder workers
 	ChunkBuffer      int // Extra chunks to buffer in memory
+	DecodeAhead      int // Frames each worker decodes ahead of its encoder
 	ThreadsPerWorker int // Threads per encoder worker (SVT-AV1 --lp flag)
+	ParallelFiles    int // Batch files encoded at once, each with a share of the workers
+
+	DisableMemoryCap bool   // Use Workers as given instead of capping them by available memory
+	MemPerWorker     uint64 // Memory per worker when capping (0 = estimate by resolution)
+	NUMA             string // Whether workers are pinned to NUMA nodes (see NUMAModes)
+
+	DuplicateStragglers bool    // Re-encode slow final chunks on idle workers, keeping the first to finish
+	ChunkRetries        int     // Retries for chunks whose encoder was killed by a signal
+	RetryFewerThreads   bool    // Halve the threads per worker on each chunk retry
+	ChunkTimeoutSecs    float64 // Seconds per 1080p frame before a chunk's encoder is killed as hung (0 = never)
+	EstimateSize        bool    // Encode probe chunks first and report projected output size and time
+
+	// AbortSizeRatio stops a file's encode when its projected output exceeds
+	// this fraction of the source size (0 = never)
+	AbortSizeRatio float64
+
+	// Per-resolution parallelism overrides (from the config file)
+	ParallelSD  ParallelOverride // Overrides for SD content (<1920 width)
+	ParallelHD  ParallelOverride // Overrides for HD content (>=1920, <3840 width)
+	ParallelUHD ParallelOverride // Overrides for UHD content (>=3840 width)
 
 	// Chunk duration settings by resolution (seconds)
 	ChunkDurationSD  float64 // Chunk duration for SD content (<1920 width)
 	ChunkDurationHD  float64 // Chunk duration for HD content (>=1920, <3840 width)
 	ChunkDurationUHD float64 // Chunk duration for UHD content (>=3840 width)
+	ChunkStrategy    string  // How chunk boundaries are placed (see ChunkStrategies)
+
+	// ChunkPlanner is a chunk.Planner that replaces the chunk strategy's
+	// chunks when set (library only). It is held opaquely so config doesn't
+	// depend on chunk
+	ChunkPlanner any
+
+	// FFMS2 indexes are cached here between runs ("" = work directory only)
+	IndexCacheDir   string
+	IndexCacheMaxMB uint64 // Least recently used indexes are removed above this size
+
+	// Output placement
+	CopyDestinations []string // Extra directories or rclone remotes to copy validated outputs to
+	DuplicatePolicy  string   // How duplicate inputs in a batch get their output (see DuplicatePolicies)
+	ScratchLocation  string   // Where work directories are kept (see ScratchLocations)
+
+	// OutputPerms are applied to outputs and the directories made for them
+	OutputPerms util.OutputPerms
+
+	// Copy the source's modification time, and optionally its access time,
+	// onto the output
+	PreserveTimes      bool
+	PreserveAccessTime bool
+
+	// StageLocal copies each source to local temp storage before encoding
+	// and moves the output into place afterwards, for sources on network
+	// storage
+	StageLocal bool
+
+	// Checksum is the algorithm a digest of each output is computed with
+	// and written next to it (see Checksums); empty computes none
+	Checksum string
+
+	// MaxFilesPerRun is how many files a run starts before deferring the
+	// rest to the next run (0 = no limit)
+	MaxFilesPerRun int
+
+	// VerifyDeterminism encodes one chunk of each file twice before the
+	// encode and warns if the outputs differ
+	VerifyDeterminism bool
+
+	// BatchDir is the input directory a batch was discovered in, keying its
+	// saved progress so it outlives changes to the files found ("" = keyed
+	// by the file list). ResumeBatch carries saved progress over to a batch
+	// whose files changed, and skips files that failed in an earlier run.
+	BatchDir    string
+	ResumeBatch bool
+
+	// OutputTemplate names outputs, e.g. "{name}.av1.{ext}" (see
+	// util.ExpandOutputTemplate); empty names them after the source
+	OutputTemplate string
+
+	// Files found in input directories are skipped unless their name
+	// matches one of IncludeGlobs (when given), or when it matches one of
+	// ExcludeGlobs, or they are smaller than MinFileSize or shorter than
+	// MinDuration (see discovery.Filter)
+	IncludeGlobs []string
+	ExcludeGlobs []string
+	MinFileSize  uint64
+	MinDuration  time.Duration
+
+	// VideoExtensions are the extensions treated as video, for discovery and
+	// for recognizing an output filename (lowercase, with leading dot)
+	VideoExtensions []string
+
+	// ToolPaths pins external tools to binaries, keyed by tool name in any
+	// case (see toolpath.Tools); unpinned tools are looked up in PATH
+	ToolPaths map[string]string
+
+	// Tools are the binaries an encode runs, resolved from ToolPaths and
+	// the environment when it starts (nil = looked up in PATH)
+	Tools toolpath.Paths
+
+	WriteReport    bool   // Write <output>.reel.json with encode results and validation codes
+	AttachSettings bool   // Attach the encode settings as JSON, besides writing them as tags
+	Version        string // reel version recorded in the output's tags
+	EventBuffer    int    // Library events buffered for asynchronous delivery (0 = synchronous)
 
 	// Debug options
 	Verbose bool // Enable verbose output
@@ -117,30 +306,63 @@ func NewConfig(inputDir, outputDir, logDir string) *Config {
 		InputDir:                    inputDir,
 		OutputDir:                   outputDir,
 		LogDir:                      logDir,
-		SVTAV1Preset:                DefaultSVTAV1Preset,
 		SVTAV1Tune:                  DefaultSVTAV1Tune,
 		SVTAV1ACBias:                DefaultSVTAV1ACBias,
 		SVTAV1EnableVarianceBoost:   DefaultSVTAV1EnableVarianceBoost,
 		SVTAV1VarianceBoostStrength: DefaultSVTAV1VarianceBoostStrength,
 		SVTAV1VarianceOctile:        DefaultSVTAV1VarianceOctile,
-		CRFSD:              DefaultCRFSD,
-		CRFHD:              DefaultCRFHD,
-		CRFUHD:             DefaultCRFUHD,
-		CropMode:           DefaultCropMode,
-		EncodeCooldownSecs: DefaultEncodeCooldownSecs,
-		Workers:          workers,
-		ChunkBuffer:      buffer,
-		ThreadsPerWorker: DefaultThreadsPerWorker,
-		ChunkDurationSD:  DefaultChunkDurationSD,
-		ChunkDurationHD:  DefaultChunkDurationHD,
-		ChunkDurationUHD: DefaultChunkDurationUHD,
+		SVTAV1SceneDetection:        true,
+		SVTAV1TileRows:              1,
+		SVTAV1TileColumns:           1,
+		BitDepth:                    BitDepth10,
+		CRFSD:                       DefaultCRFSD,
+		CRFHD:                       DefaultCRFHD,
+		CRFUHD:                      DefaultCRFUHD,
+		PresetSD:                    DefaultSVTAV1Preset,
+		PresetHD:                    DefaultSVTAV1Preset,
+		PresetUHD:                   DefaultSVTAV1Preset,
+		CropMode:                    DefaultCropMode,
+		CropConfidence:              DefaultCropConfidence,
+		ContentType:                 ContentAuto,
+		EncodeCooldownSecs:          DefaultEncodeCooldownSecs,
+		Workers:                     workers,
+		ChunkBuffer:                 buffer,
+		DecodeAhead:                 DefaultDecodeAhead,
+		ChunkRetries:                DefaultChunkRetries,
+		ChunkTimeoutSecs:            DefaultChunkTimeoutSecs,
+		IndexCacheDir:               DefaultIndexCacheDir(),
+		IndexCacheMaxMB:             DefaultIndexCacheMaxMB,
+		ThreadsPerWorker:            DefaultThreadsPerWorker,
+		KeyintSecs:                  DefaultKeyintSecs,
+		ChunkDurationSD:             DefaultChunkDurationSD,
+		ChunkDurationHD:             DefaultChunkDurationHD,
+		ChunkDurationUHD:            DefaultChunkDurationUHD,
+		ChunkStrategy:               ChunkFixed,
+		VideoExtensions:             slices.Clone(util.DefaultVideoExtensions),
+		DuplicatePolicy:             DuplicatesLink,
+		ScratchLocation:             ScratchDisk,
+		NUMA:                        NUMAAuto,
+		ParallelFiles:               1,
 	}
 }
 
 // Validate checks the configuration for errors.
 func (c *Config) Validate() error {
-	if c.SVTAV1Preset > 13 {
-		return fmt.Errorf("svt_av1_preset must be 0-13, got %d", c.SVTAV1Preset)
+	if c.SVTAV1Tune > 4 {
+		return fmt.Errorf("tune must be 0-4, got %d", c.SVTAV1Tune)
+	}
+
+	if c.SVTAV1ACBias < 0 || c.SVTAV1ACBias > 8 {
+		return fmt.Errorf("ac-bias must be 0-8, got %g", c.SVTAV1ACBias)
+	}
+
+	if c.SVTAV1EnableVarianceBoost {
+		if c.SVTAV1VarianceBoostStrength < 1 || c.SVTAV1VarianceBoostStrength > 4 {
+			return fmt.Errorf("variance boost strength must be 1-4, got %d", c.SVTAV1VarianceBoostStrength)
+		}
+		if c.SVTAV1VarianceOctile < 1 || c.SVTAV1VarianceOctile > 8 {
+			return fmt.Errorf("variance octile must be 1-8, got %d", c.SVTAV1VarianceOctile)
+		}
 	}
 
 	if c.CRFSD > 63 {
@@ -152,15 +374,46 @@ func (c *Config) Validate() error {
 	if c.CRFUHD > 63 {
 		return fmt.Errorf("crf-uhd must be 0-63, got %d", c.CRFUHD)
 	}
+	if c.PresetSD > 13 {
+		return fmt.Errorf("preset-sd must be 0-13, got %d", c.PresetSD)
+	}
+	if c.PresetHD > 13 {
+		return fmt.Errorf("preset-hd must be 0-13, got %d", c.PresetHD)
+	}
+	if c.PresetUHD > 13 {
+		return fmt.Errorf("preset-uhd must be 0-13, got %d", c.PresetUHD)
+	}
 
 	if c.Workers < 1 {
 		return fmt.Errorf("workers must be at least 1, got %d", c.Workers)
 	}
 
+	if c.ThreadsPerWorker < 0 {
+		return fmt.Errorf("threads per worker must be non-negative, got %d", c.ThreadsPerWorker)
+	}
+
 	if c.ChunkBuffer < 0 {
 		return fmt.Errorf("chunk_buffer must be non-negative, got %d", c.ChunkBuffer)
 	}
 
+	if c.DecodeAhead < 1 {
+		return fmt.Errorf("decode ahead must be at least 1 frame, got %d", c.DecodeAhead)
+	}
+
+	if c.ParallelFiles < 1 {
+		return fmt.Errorf("parallel files must be at least 1, got %d", c.ParallelFiles)
+	}
+	if c.MaxFilesPerRun < 0 {
+		return fmt.Errorf("max files per run must be non-negative, got %d", c.MaxFilesPerRun)
+	}
+
+	if c.ChunkRetries < 0 {
+		return fmt.Errorf("chunk retries must be non-negative, got %d", c.ChunkRetries)
+	}
+	if c.ChunkTimeoutSecs < 0 {
+		return fmt.Errorf("chunk timeout must be non-negative, got %g", c.ChunkTimeoutSecs)
+	}
+
 	// Validate chunk durations
 	for _, cd := range []struct {
 		name  string
@@ -175,6 +428,139 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.CropMode != "auto" && c.CropMode != "none" {
+		return fmt.Errorf("crop mode must be auto or none, got %q", c.CropMode)
+	}
+
+	if c.CropConfidence <= 0 || c.CropConfidence > 1 {
+		return fmt.Errorf("crop confidence must be greater than 0 and at most 1, got %g", c.CropConfidence)
+	}
+
+	if c.CropFilter != "" && !cropFilterPattern.MatchString(c.CropFilter) {
+		return fmt.Errorf("crop filter must be W:H:X:Y, got %q", c.CropFilter)
+	}
+
+	if _, err := ParseSvtParams(c.SVTAV1ExtraParams); err != nil {
+		return err
+	}
+
+	if c.AbortSizeRatio < 0 {
+		return fmt.Errorf("abort size ratio must be non-negative, got %g", c.AbortSizeRatio)
+	}
+
+	if !slices.Contains(ContentTypes, c.ContentType) {
+		return fmt.Errorf("content type must be one of %v, got %q", ContentTypes, c.ContentType)
+	}
+
+	if c.FilmGrain > 50 {
+		return fmt.Errorf("film_grain must be 0-50, got %d", c.FilmGrain)
+	}
+
+	if c.DialogueBoostDB < 0 || c.DialogueBoostDB > MaxDialogueBoostDB {
+		return fmt.Errorf("dialogue boost must be 0-%g dB, got %g", float64(MaxDialogueBoostDB), c.DialogueBoostDB)
+	}
+
+	for _, format := range c.AudioPassthrough {
+		if !slices.Contains(PassthroughFormats, format) {
+			return fmt.Errorf("audio passthrough formats must be among %v, got %q", PassthroughFormats, format)
+		}
+	}
+
+	if c.VideoStream != nil && *c.VideoStream < 0 {
+		return fmt.Errorf("video stream must be non-negative, got %d", *c.VideoStream)
+	}
+	for _, idx := range c.AudioTracks {
+		if idx < 0 {
+			return fmt.Errorf("audio tracks must be non-negative, got %d", idx)
+		}
+	}
+
+	if !slices.Contains(DuplicatePolicies, c.DuplicatePolicy) {
+		return fmt.Errorf("duplicate policy must be one of %v, got %q", DuplicatePolicies, c.DuplicatePolicy)
+	}
+
+	if !slices.Contains(BitDepths, c.BitDepth) {
+		return fmt.Errorf("bit depth must be one of %v, got %q", BitDepths, c.BitDepth)
+	}
+
+	if c.Checksum != ChecksumNone && !slices.Contains(Checksums, c.Checksum) {
+		return fmt.Errorf("checksum must be one of %v, got %q", Checksums, c.Checksum)
+	}
+
+	if !slices.Contains(ScratchLocations, c.ScratchLocation) {
+		return fmt.Errorf("scratch must be one of %v, got %q", ScratchLocations, c.ScratchLocation)
+	}
+
+	if !slices.Contains(NUMAModes, c.NUMA) {
+		return fmt.Errorf("numa must be one of %v, got %q", NUMAModes, c.NUMA)
+	}
+
+	if !slices.Contains(ChunkStrategies, c.ChunkStrategy) {
+		return fmt.Errorf("chunk strategy must be one of %v, got %q", ChunkStrategies, c.ChunkStrategy)
+	}
+
+	// Chunks of a growing input are encoded before the rest of it exists, so
+	// their boundaries can't depend on the whole file
+	if c.GrowingQuietSecs > 0 {
+		if c.ChunkStrategy != ChunkFixed || c.ChunkPlanner != nil {
+			return fmt.Errorf("growing inputs need the fixed chunk strategy")
+		}
+		if c.StageLocal {
+			return fmt.Errorf("growing inputs can't be staged locally")
+		}
+	}
+
+	if len(c.VideoExtensions) == 0 {
+		return fmt.Errorf("video_extensions must not be empty")
+	}
+
+	for tool, path := range c.ToolPaths {
+		if !toolpath.Known(tool) {
+			return fmt.Errorf("unknown tool %q (known: %v)", tool, toolpath.Tools)
+		}
+		if path == "" {
+			return fmt.Errorf("path for %s must not be empty", tool)
+		}
+	}
+
+	for _, pattern := range slices.Concat(c.IncludeGlobs, c.ExcludeGlobs) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+
+	if c.OutputTemplate != "" {
+		if err := util.ValidateOutputTemplate(c.OutputTemplate); err != nil {
+			return err
+		}
+	}
+
+	if c.KeyintSecs < 1 || c.KeyintSecs > 30 {
+		return fmt.Errorf("keyint must be between 1 and 30 seconds, got %g", c.KeyintSecs)
+	}
+	if err := validateTiles(c.SVTAV1TileRows, c.SVTAV1TileColumns); err != nil {
+		return err
+	}
+
+	for _, p := range []struct {
+		name  string
+		value ParallelOverride
+	}{
+		{"parallel.sd", c.ParallelSD},
+		{"parallel.hd", c.ParallelHD},
+		{"parallel.uhd", c.ParallelUHD},
+	} {
+		if p.value.Workers < 0 || p.value.Threads < 0 || p.value.Buffer < 0 {
+			return fmt.Errorf("%s values must be non-negative, got %+v", p.name, p.value)
+		}
+	}
+
+	for _, dest := range c.CopyDestinations {
+		if dest == "" {
+			return fmt.Errorf("copy destinations must not be empty")
+		}
+	}
+
 	return nil
 }
 
@@ -197,6 +583,28 @@ func (c *Config) CRFForWidth(width uint32) uint8 {
 	return c.CRFSD
 }
 
+// PresetForWidth returns the SVT-AV1 preset for the given video width.
+func (c *Config) PresetForWidth(width uint32) uint8 {
+	if width >= UHDWidthThreshold {
+		return c.PresetUHD
+	}
+	if width >= HDWidthThreshold {
+		return c.PresetHD
+	}
+	return c.PresetSD
+}
+
+// ParallelForWidth returns the parallelism overrides for the given video width.
+func (c *Config) ParallelForWidth(width uint32) ParallelOverride {
+	if width >= UHDWidthThreshold {
+		return c.ParallelUHD
+	}
+	if width >= HDWidthThreshold {
+		return c.ParallelHD
+	}
+	return c.ParallelSD
+}
+
 // ChunkDurationForWidth returns the appropriate chunk duration based on video width.
 func (c *Config) ChunkDurationForWidth(width uint32) float64 {
 	if width >= UHDWidthThreshold {
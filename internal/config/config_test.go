@@ -18,8 +18,8 @@ func TestNewConfig(t *testing.T) {
 	}
 
 	// Check defaults
-	if cfg.SVTAV1Preset != DefaultSVTAV1Preset {
-		t.Errorf("expected SVTAV1Preset=%d, got %d", DefaultSVTAV1Preset, cfg.SVTAV1Preset)
+	if cfg.PresetSD != DefaultSVTAV1Preset || cfg.PresetHD != DefaultSVTAV1Preset || cfg.PresetUHD != DefaultSVTAV1Preset {
+		t.Errorf("expected presets=%d, got %d/%d/%d", DefaultSVTAV1Preset, cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD)
 	}
 	if cfg.CRFSD != DefaultCRFSD {
 		t.Errorf("expected CRFSD=%d, got %d", DefaultCRFSD, cfg.CRFSD)
@@ -45,12 +45,22 @@ func TestConfigValidate(t *testing.T) {
 		},
 		{
 			name:    "preset 14 is invalid",
-			modify:  func(c *Config) { c.SVTAV1Preset = 14 },
+			modify:  func(c *Config) { c.PresetSD = 14 },
 			wantErr: true,
 		},
 		{
 			name:    "preset 13 is valid",
-			modify:  func(c *Config) { c.SVTAV1Preset = 13 },
+			modify:  func(c *Config) { c.PresetSD = 13 },
+			wantErr: false,
+		},
+		{
+			name:    "preset-uhd 14 is invalid",
+			modify:  func(c *Config) { c.PresetUHD = 14 },
+			wantErr: true,
+		},
+		{
+			name:    "preset ladder 4,6,8 is valid",
+			modify:  func(c *Config) { c.PresetSD, c.PresetHD, c.PresetUHD = 4, 6, 8 },
 			wantErr: false,
 		},
 		{
@@ -78,6 +88,150 @@ func TestConfigValidate(t *testing.T) {
 			modify:  func(c *Config) { c.ChunkDurationHD = 121 },
 			wantErr: true,
 		},
+		{
+			name:    "tune 5 is invalid",
+			modify:  func(c *Config) { c.SVTAV1Tune = 5 },
+			wantErr: true,
+		},
+		{
+			name:    "ac-bias 8.5 is invalid",
+			modify:  func(c *Config) { c.SVTAV1ACBias = 8.5 },
+			wantErr: true,
+		},
+		{
+			name:    "variance boost with defaults is valid",
+			modify:  func(c *Config) { c.SVTAV1EnableVarianceBoost = true },
+			wantErr: false,
+		},
+		{
+			name: "variance octile 9 is invalid",
+			modify: func(c *Config) {
+				c.SVTAV1EnableVarianceBoost = true
+				c.SVTAV1VarianceOctile = 9
+			},
+			wantErr: true,
+		},
+		{
+			name:    "crop confidence 1 is valid",
+			modify:  func(c *Config) { c.CropConfidence = 1 },
+			wantErr: false,
+		},
+		{
+			name:    "crop confidence 0 is invalid",
+			modify:  func(c *Config) { c.CropConfidence = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "decode ahead 0 is invalid",
+			modify:  func(c *Config) { c.DecodeAhead = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "negative chunk retries is invalid",
+			modify:  func(c *Config) { c.ChunkRetries = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative chunk timeout is invalid",
+			modify:  func(c *Config) { c.ChunkTimeoutSecs = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "parallel files 0 is invalid",
+			modify:  func(c *Config) { c.ParallelFiles = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "negative max files per run is invalid",
+			modify:  func(c *Config) { c.MaxFilesPerRun = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "unknown scratch location is invalid",
+			modify:  func(c *Config) { c.ScratchLocation = "s3" },
+			wantErr: true,
+		},
+		{
+			name:    "growing input with fixed chunks is valid",
+			modify:  func(c *Config) { c.GrowingQuietSecs = 60 },
+			wantErr: false,
+		},
+		{
+			name: "growing input with balanced chunks is invalid",
+			modify: func(c *Config) {
+				c.GrowingQuietSecs = 60
+				c.ChunkStrategy = ChunkBalanced
+			},
+			wantErr: true,
+		},
+		{
+			name: "growing input with local staging is invalid",
+			modify: func(c *Config) {
+				c.GrowingQuietSecs = 60
+				c.StageLocal = true
+			},
+			wantErr: true,
+		},
+		{
+			name:    "sha256 checksum is valid",
+			modify:  func(c *Config) { c.Checksum = ChecksumSHA256 },
+			wantErr: false,
+		},
+		{
+			name:    "unknown checksum is invalid",
+			modify:  func(c *Config) { c.Checksum = "md5" },
+			wantErr: true,
+		},
+		{
+			name:    "numa off is valid",
+			modify:  func(c *Config) { c.NUMA = NUMAOff },
+			wantErr: false,
+		},
+		{
+			name:    "unknown numa mode is invalid",
+			modify:  func(c *Config) { c.NUMA = "interleave" },
+			wantErr: true,
+		},
+		{
+			name:    "balanced chunk strategy is valid",
+			modify:  func(c *Config) { c.ChunkStrategy = ChunkBalanced },
+			wantErr: false,
+		},
+		{
+			name:    "unknown chunk strategy is invalid",
+			modify:  func(c *Config) { c.ChunkStrategy = "scene" },
+			wantErr: true,
+		},
+		{
+			name:    "lossless passthrough formats are valid",
+			modify:  func(c *Config) { c.AudioPassthrough = []string{"truehd", "dts-hd", "flac"} },
+			wantErr: false,
+		},
+		{
+			name:    "lossy passthrough format is invalid",
+			modify:  func(c *Config) { c.AudioPassthrough = []string{"ac3"} },
+			wantErr: true,
+		},
+		{
+			name:    "dialogue boost 12 dB is valid",
+			modify:  func(c *Config) { c.StereoDownmix, c.DialogueBoostDB = true, 12 },
+			wantErr: false,
+		},
+		{
+			name:    "dialogue boost over 12 dB is invalid",
+			modify:  func(c *Config) { c.StereoDownmix, c.DialogueBoostDB = true, 20 },
+			wantErr: true,
+		},
+		{
+			name:    "discovery globs are valid",
+			modify:  func(c *Config) { c.IncludeGlobs, c.ExcludeGlobs = []string{"*.mkv"}, []string{"*sample*"} },
+			wantErr: false,
+		},
+		{
+			name:    "malformed discovery glob is invalid",
+			modify:  func(c *Config) { c.ExcludeGlobs = []string{"[sample"} },
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +276,32 @@ func TestCRFForWidth(t *testing.T) {
 	}
 }
 
+func TestPresetForWidth(t *testing.T) {
+	cfg := NewConfig("/input", "/output", "/log")
+	cfg.PresetSD = 4
+	cfg.PresetHD = 6
+	cfg.PresetUHD = 8
+
+	tests := []struct {
+		width    uint32
+		expected uint8
+	}{
+		{width: 720, expected: 4},
+		{width: 1919, expected: 4},
+		{width: 1920, expected: 6},
+		{width: 3839, expected: 6},
+		{width: 3840, expected: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			if got := cfg.PresetForWidth(tt.width); got != tt.expected {
+				t.Errorf("PresetForWidth(%d) = %d, want %d", tt.width, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestChunkDurationForWidth(t *testing.T) {
 	cfg := NewConfig("/input", "/output", "/log")
 	cfg.ChunkDurationSD = 20.0
@@ -152,3 +332,32 @@ func TestChunkDurationForWidth(t *testing.T) {
 	}
 }
 
+func TestApplyContentKeepsExplicitTuning(t *testing.T) {
+	cfg := NewConfig("/input", "/output", "/log")
+	cfg.SVTAV1ACBias = 0.5
+	cfg.ACBiasExplicit = true
+
+	if err := cfg.ApplyContent(ContentScreen); err != nil {
+		t.Fatalf("ApplyContent() error = %v", err)
+	}
+	if cfg.SVTAV1ACBias != 0.5 {
+		t.Errorf("SVTAV1ACBias = %g, want explicit 0.5 kept", cfg.SVTAV1ACBias)
+	}
+	if cfg.SVTAV1Tune != 1 || cfg.SVTAV1SCM != 1 {
+		t.Errorf("tune/scm = %d/%d, want 1/1", cfg.SVTAV1Tune, cfg.SVTAV1SCM)
+	}
+}
+
+func TestApplyContentKeepsProfileTuning(t *testing.T) {
+	cfg := NewConfig("/input", "/output", "/log")
+	if err := cfg.ApplyProfile(ProfileFilmGrain); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.ApplyContent(ContentAnime); err != nil {
+		t.Fatalf("ApplyContent() error = %v", err)
+	}
+	if cfg.SVTAV1ACBias != 0.3 {
+		t.Errorf("SVTAV1ACBias = %g, want the profile's 0.3 kept", cfg.SVTAV1ACBias)
+	}
+}
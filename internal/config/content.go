@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+// Content types for --content. ContentAuto classifies each source.
+const (
+	ContentAuto   = "auto"
+	ContentFilm   = "film"
+	ContentAnime  = "anime"
+	ContentScreen = "screen"
+)
+
+// ContentTypes lists the accepted --content values.
+var ContentTypes = []string{ContentAuto, ContentFilm, ContentAnime, ContentScreen}
+
+// ApplyContent adjusts encoder tuning for a classified content type.
+// Film keeps the configured settings. Animation drops ac-bias, which adds
+// texture to flat shading that isn't there. Screen content (text, UI,
+// slides) enables SVT-AV1 screen content tools and tunes for PSNR to keep
+// hard edges sharp. Tune and ac-bias set explicitly or by a profile are
+// kept.
+func (c *Config) ApplyContent(content string) error {
+	acBias, tune := c.SVTAV1ACBias, c.SVTAV1Tune
+	switch content {
+	case ContentFilm:
+	case ContentAnime:
+		acBias = 0
+		c.SVTAV1SCM = 0
+	case ContentScreen:
+		acBias = 0
+		tune = 1
+		c.SVTAV1SCM = 1
+	default:
+		return fmt.Errorf("unknown content type %q (available: %v)", content, ContentTypes[1:])
+	}
+	if !c.ACBiasExplicit {
+		c.SVTAV1ACBias = acBias
+	}
+	if !c.TuneExplicit {
+		c.SVTAV1Tune = tune
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package config
+
+// Duplicate policies for batch inputs that are the same file (symlinks, hard
+// links) or have identical content. All but DuplicatesEncode encode the
+// content once.
+const (
+	DuplicatesLink   = "link"   // Hard-link the output to each duplicate's output path, copying if linking fails
+	DuplicatesCopy   = "copy"   // Copy the output to each duplicate's output path
+	DuplicatesSkip   = "skip"   // Produce no output for duplicates
+	DuplicatesEncode = "encode" // Encode every input, even duplicates
+)
+
+// DuplicatePolicies lists the accepted --duplicates values.
+var DuplicatePolicies = []string{DuplicatesLink, DuplicatesCopy, DuplicatesSkip, DuplicatesEncode}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/five82/reel/internal/util"
+)
+
+// DefaultConfigPath returns the default config file path following XDG Base Directory Spec.
+// Uses $XDG_CONFIG_HOME/reel/config.toml, defaulting to ~/.config/reel/config.toml.
+func DefaultConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "reel", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "reel", "config.toml")
+}
+
+// DefaultIndexCacheDir returns the default FFMS2 index cache directory following
+// XDG Base Directory Spec. Uses $XDG_CACHE_HOME/reel/index, defaulting to
+// ~/.cache/reel/index.
+func DefaultIndexCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "reel", "index")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cache", "reel", "index")
+}
+
+// ParallelOverride sets explicit parallelism for one resolution tier.
+// Zero values keep the automatic calculation.
+type ParallelOverride struct {
+	Workers int `toml:"workers"` // Encoder workers, used as-is without memory capping
+	Threads int `toml:"threads"` // Threads per worker (SVT-AV1 --lp flag)
+	Buffer  int `toml:"buffer"`  // Extra chunks to buffer in memory
+}
+
+// IsSet reports whether any value is overridden.
+func (p ParallelOverride) IsSet() bool {
+	return p.Workers != 0 || p.Threads != 0 || p.Buffer != 0
+}
+
+// fileConfig is the on-disk config file layout.
+type fileConfig struct {
+	Parallel struct {
+		SD  ParallelOverride `toml:"sd"`
+		HD  ParallelOverride `toml:"hd"`
+		UHD ParallelOverride `toml:"uhd"`
+	} `toml:"parallel"`
+
+	Profiles map[string]ProfileSettings `toml:"profiles"`
+
+	VideoExtensions []string `toml:"video_extensions"` // Replaces the default list
+
+	IndexCacheMaxMB uint64 `toml:"index_cache_max_mb"`
+
+	TempDir string `toml:"temp_dir"` // Work file directory; --temp-dir overrides it
+
+	Tools map[string]string `toml:"tools"` // Binary paths by tool name
+}
+
+// LoadFile applies settings from a TOML config file to c.
+// A missing file is not an error unless required is true.
+func (c *Config) LoadFile(path string, required bool) error {
+	var fc fileConfig
+	md, err := toml.DecodeFile(path, &fc)
+	if err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown key %q in config file %s", undecoded[0].String(), path)
+	}
+
+	c.ParallelSD = fc.Parallel.SD
+	c.ParallelHD = fc.Parallel.HD
+	c.ParallelUHD = fc.Parallel.UHD
+	c.CustomProfiles = fc.Profiles
+	if fc.Tools != nil {
+		c.ToolPaths = fc.Tools
+	}
+	if fc.IndexCacheMaxMB > 0 {
+		c.IndexCacheMaxMB = fc.IndexCacheMaxMB
+	}
+	if fc.TempDir != "" {
+		c.TempDir = fc.TempDir
+	}
+	if fc.VideoExtensions != nil {
+		var exts []string
+		for _, ext := range fc.VideoExtensions {
+			if ext = util.NormalizeExtension(ext); ext != "" {
+				exts = append(exts, ext)
+			}
+		}
+		c.VideoExtensions = exts
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	content := `
+index_cache_max_mb = 512
+temp_dir = "/scratch"
+
+[parallel.hd]
+workers = 6
+threads = 4
+
+[parallel.uhd]
+workers = 2
+buffer = 1
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if cfg.ParallelSD.IsSet() {
+		t.Errorf("ParallelSD = %+v, want unset", cfg.ParallelSD)
+	}
+	if got := cfg.ParallelForWidth(1920); got != (ParallelOverride{Workers: 6, Threads: 4}) {
+		t.Errorf("ParallelForWidth(1920) = %+v", got)
+	}
+	if got := cfg.ParallelForWidth(3840); got != (ParallelOverride{Workers: 2, Buffer: 1}) {
+		t.Errorf("ParallelForWidth(3840) = %+v", got)
+	}
+	if cfg.IndexCacheMaxMB != 512 {
+		t.Errorf("IndexCacheMaxMB = %d, want 512", cfg.IndexCacheMaxMB)
+	}
+	if cfg.TempDir != "/scratch" {
+		t.Errorf("TempDir = %q, want /scratch", cfg.TempDir)
+	}
+}
+
+func TestLoadFileVideoExtensions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(`video_extensions = [".MKV", "iso", ""]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if want := []string{".mkv", ".iso"}; !slices.Equal(cfg.VideoExtensions, want) {
+		t.Errorf("VideoExtensions = %v, want %v", cfg.VideoExtensions, want)
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.toml")
+	cfg := NewConfig(".", ".", ".")
+
+	if err := cfg.LoadFile(path, false); err != nil {
+		t.Errorf("LoadFile(missing, optional) error = %v, want nil", err)
+	}
+	if err := cfg.LoadFile(path, true); err == nil {
+		t.Error("LoadFile(missing, required) expected error")
+	}
+}
+
+func TestLoadFileUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[parallel.hd]\nworkerz = 6\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err == nil {
+		t.Error("LoadFile() with unknown key expected error")
+	}
+}
+
+func TestLoadFileToolPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[tools]
+ffmpeg = "/opt/ffmpeg/bin/ffmpeg"
+SvtAv1EncApp = "/usr/bin/SvtAv1EncApp"
+
+[profiles.psy.tools]
+svtav1encapp = "/opt/svt-av1-psy/bin/SvtAv1EncApp"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if err := cfg.ApplyProfile("psy"); err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	want := map[string]string{
+		"ffmpeg":       "/opt/ffmpeg/bin/ffmpeg",
+		"svtav1encapp": "/opt/svt-av1-psy/bin/SvtAv1EncApp",
+	}
+	if !maps.Equal(cfg.ToolPaths, want) {
+		t.Errorf("ToolPaths = %v, want %v", cfg.ToolPaths, want)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.ToolPaths["x264"] = "/usr/bin/x264"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown tool")
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+// NUMA modes decide whether encoder workers are pinned to NUMA nodes on
+// multi-socket machines.
+const (
+	NUMAAuto = "auto" // Pin workers round robin when there is more than one node
+	NUMAOff  = "off"  // Let the kernel schedule workers anywhere
+)
+
+// NUMAModes lists the accepted --numa values.
+var NUMAModes = []string{NUMAAuto, NUMAOff}
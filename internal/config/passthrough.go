@@ -0,0 +1,5 @@
+package config
+
+// PassthroughFormats lists the lossless audio formats --audio-passthrough
+// can copy instead of encoding to Opus. dts-hd is DTS-HD Master Audio only.
+var PassthroughFormats = []string{"truehd", "dts-hd", "flac", "alac", "pcm"}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Built-in profile names.
+const (
+	ProfileDVD       = "dvd"
+	ProfileAnime     = "anime"
+	ProfileFilmGrain = "film-grain"
+	ProfileArchive   = "archive"
+)
+
+// ProfileSettings is a named bundle of settings. Nil fields leave the current
+// value unchanged, so a profile only needs to list what it changes.
+type ProfileSettings struct {
+	Description string `toml:"description"`
+
+	CRF    *uint8 `toml:"crf"` // All resolutions; crf_sd/hd/uhd take precedence
+	CRFSD  *uint8 `toml:"crf_sd"`
+	CRFHD  *uint8 `toml:"crf_hd"`
+	CRFUHD *uint8 `toml:"crf_uhd"`
+
+	Preset    *uint8 `toml:"preset"` // All resolutions; preset_sd/hd/uhd take precedence
+	PresetSD  *uint8 `toml:"preset_sd"`
+	PresetHD  *uint8 `toml:"preset_hd"`
+	PresetUHD *uint8 `toml:"preset_uhd"`
+
+	Tune                  *uint8   `toml:"tune"`
+	ACBias                *float32 `toml:"ac_bias"`
+	VarianceBoost         *bool    `toml:"variance_boost"`
+	VarianceBoostStrength *uint8   `toml:"variance_boost_strength"`
+	VarianceOctile        *uint8   `toml:"variance_octile"`
+	FilmGrain             *uint8   `toml:"film_grain"`
+	FilmGrainDenoise      *bool    `toml:"film_grain_denoise"`
+
+	Crop                *string  `toml:"crop"`
+	Deinterlace         *bool    `toml:"deinterlace"`
+	AssumeRec601        *bool    `toml:"assume_rec601"`
+	KeyintSecs          *float64 `toml:"keyint"`
+	AudioKbpsPerChannel *uint32  `toml:"audio_kbps_per_channel"`
+
+	Tools map[string]string `toml:"tools"` // Binary paths by tool name, over the [tools] table
+}
+
+// builtinProfiles are always available. A config file profile with the same
+// name is applied on top, so it only needs to list the values it changes.
+var builtinProfiles = map[string]ProfileSettings{
+	// DVD video is low-detail SD, often interlaced and untagged, so it gets a
+	// lower CRF, shorter keyframe interval, and leaner audio. Deinterlacing
+	// and Rec.601 tagging only apply to sources that need them
+	ProfileDVD: {
+		Description:         "SD/DVD sources: deinterlace, Rec.601 tagging, 5s keyframes, leaner audio",
+		CRFSD:               ptr[uint8](22),
+		Crop:                ptr("auto"),
+		Deinterlace:         ptr(true),
+		AssumeRec601:        ptr(true),
+		KeyintSecs:          ptr(5.0),
+		AudioKbpsPerChannel: ptr[uint32](40),
+	},
+	// Flat shading and line art: no ac-bias, which adds texture that doesn't
+	// exist, and variance boost against banding in gradients
+	ProfileAnime: {
+		Description:           "Animation: no ac-bias, variance boost against banding",
+		ACBias:                ptr[float32](0),
+		VarianceBoost:         ptr(true),
+		VarianceBoostStrength: ptr[uint8](2),
+		VarianceOctile:        ptr[uint8](6),
+		FilmGrain:             ptr[uint8](0),
+	},
+	// Heavy grain is expensive to encode; denoise it and synthesize grain on playback
+	ProfileFilmGrain: {
+		Description:      "Grainy film: denoise and resynthesize grain on playback",
+		ACBias:           ptr[float32](0.3),
+		FilmGrain:        ptr[uint8](10),
+		FilmGrainDenoise: ptr(true),
+	},
+	ProfileArchive: {
+		Description: "Archival: lower CRF and a slower preset for near-transparent output",
+		CRFSD:       ptr[uint8](20),
+		CRFHD:       ptr[uint8](22),
+		CRFUHD:      ptr[uint8](24),
+		Preset:      ptr[uint8](4),
+	},
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// ProfileNames returns the built-in and config file profile names, sorted.
+func (c *Config) ProfileNames() []string {
+	names := slices.Collect(maps.Keys(builtinProfiles))
+	for name := range c.CustomProfiles {
+		if _, ok := builtinProfiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ProfileDescription returns a profile's description, preferring the config file's.
+func (c *Config) ProfileDescription(name string) string {
+	if p, ok := c.CustomProfiles[name]; ok && p.Description != "" {
+		return p.Description
+	}
+	return builtinProfiles[name].Description
+}
+
+// IsCustomProfile reports whether the config file defines or changes a profile.
+func (c *Config) IsCustomProfile(name string) bool {
+	_, ok := c.CustomProfiles[name]
+	return ok
+}
+
+// ApplyProfile applies a named profile's settings to c: the built-in
+// definition first, then any config file profile of the same name.
+// Explicit options should be applied afterwards so they take precedence.
+func (c *Config) ApplyProfile(name string) error {
+	builtin, isBuiltin := builtinProfiles[name]
+	custom, isCustom := c.CustomProfiles[name]
+	if !isBuiltin && !isCustom {
+		return fmt.Errorf("unknown profile %q (available: %v)", name, c.ProfileNames())
+	}
+	if isBuiltin {
+		builtin.apply(c)
+	}
+	if isCustom {
+		custom.apply(c)
+	}
+	c.Profile = name
+	return nil
+}
+
+// apply copies the profile's set fields onto c.
+func (p ProfileSettings) apply(c *Config) {
+	set := func(dst *uint8, src *uint8) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	if p.CRF != nil {
+		c.CRFSD, c.CRFHD, c.CRFUHD = *p.CRF, *p.CRF, *p.CRF
+	}
+	set(&c.CRFSD, p.CRFSD)
+	set(&c.CRFHD, p.CRFHD)
+	set(&c.CRFUHD, p.CRFUHD)
+	if p.Preset != nil {
+		c.PresetSD, c.PresetHD, c.PresetUHD = *p.Preset, *p.Preset, *p.Preset
+	}
+	set(&c.PresetSD, p.PresetSD)
+	set(&c.PresetHD, p.PresetHD)
+	set(&c.PresetUHD, p.PresetUHD)
+	if p.Tune != nil {
+		c.SVTAV1Tune = *p.Tune
+		c.TuneExplicit = true
+	}
+	set(&c.SVTAV1VarianceBoostStrength, p.VarianceBoostStrength)
+	set(&c.SVTAV1VarianceOctile, p.VarianceOctile)
+	set(&c.FilmGrain, p.FilmGrain)
+
+	if p.ACBias != nil {
+		c.SVTAV1ACBias = *p.ACBias
+		c.ACBiasExplicit = true
+	}
+	if p.VarianceBoost != nil {
+		c.SVTAV1EnableVarianceBoost = *p.VarianceBoost
+	}
+	if p.FilmGrainDenoise != nil {
+		c.FilmGrainDenoise = *p.FilmGrainDenoise
+	}
+	if p.Crop != nil {
+		c.CropMode = *p.Crop
+	}
+	if p.Deinterlace != nil {
+		c.Deinterlace = *p.Deinterlace
+	}
+	if p.AssumeRec601 != nil {
+		c.AssumeRec601 = *p.AssumeRec601
+	}
+	if p.KeyintSecs != nil {
+		c.KeyintSecs = *p.KeyintSecs
+	}
+	if p.AudioKbpsPerChannel != nil {
+		c.AudioKbpsPerChannel = *p.AudioKbpsPerChannel
+	}
+	if len(p.Tools) > 0 {
+		// Tool names match in any case, so merge them lowercased
+		tools := make(map[string]string, len(c.ToolPaths)+len(p.Tools))
+		for tool, path := range c.ToolPaths {
+			tools[strings.ToLower(tool)] = path
+		}
+		for tool, path := range p.Tools {
+			tools[strings.ToLower(tool)] = path
+		}
+		c.ToolPaths = tools
+	}
+}
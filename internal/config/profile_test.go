@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestApplyProfileDVD(t *testing.T) {
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.ApplyProfile(ProfileDVD); err != nil {
+		t.Fatalf("ApplyProfile(dvd) error = %v", err)
+	}
+
+	if !cfg.Deinterlace {
+		t.Error("dvd profile should enable deinterlacing")
+	}
+	if !cfg.AssumeRec601 {
+		t.Error("dvd profile should assume Rec.601")
+	}
+	if cfg.KeyintSecs != 5 {
+		t.Errorf("KeyintSecs = %g, want 5", cfg.KeyintSecs)
+	}
+	if cfg.CRFSD != 22 {
+		t.Errorf("CRFSD = %d, want 22", cfg.CRFSD)
+	}
+	if cfg.Profile != ProfileDVD {
+		t.Errorf("Profile = %q, want %q", cfg.Profile, ProfileDVD)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() after dvd profile error = %v", err)
+	}
+}
+
+func TestApplyProfileUnknown(t *testing.T) {
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.ApplyProfile("bluray"); err == nil {
+		t.Error("ApplyProfile(bluray) expected error")
+	}
+}
+
+func TestBuiltinProfilesValidate(t *testing.T) {
+	base := NewConfig(".", ".", ".")
+	for _, name := range base.ProfileNames() {
+		t.Run(name, func(t *testing.T) {
+			cfg := NewConfig(".", ".", ".")
+			if err := cfg.ApplyProfile(name); err != nil {
+				t.Fatalf("ApplyProfile(%s) error = %v", name, err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if cfg.ProfileDescription(name) == "" {
+				t.Error("missing description")
+			}
+		})
+	}
+}
+
+func TestConfigFileProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	content := `
+[profiles.archive]
+preset = 2
+
+[profiles.tv]
+description = "Broadcast TV"
+crf = 30
+film_grain = 4
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if !slices.Contains(cfg.ProfileNames(), "tv") {
+		t.Errorf("ProfileNames() = %v, want tv included", cfg.ProfileNames())
+	}
+
+	// Config file values are layered over the built-in profile
+	if err := cfg.ApplyProfile(ProfileArchive); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PresetHD != 2 || cfg.CRFHD != 22 {
+		t.Errorf("archive preset/crf-hd = %d/%d, want 2/22", cfg.PresetHD, cfg.CRFHD)
+	}
+
+	cfg = NewConfig(".", ".", ".")
+	if err := cfg.LoadFile(path, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyProfile("tv"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CRFSD != 30 || cfg.CRFUHD != 30 || cfg.FilmGrain != 4 {
+		t.Errorf("tv crf/grain = %d/%d/%d, want 30/30/4", cfg.CRFSD, cfg.CRFUHD, cfg.FilmGrain)
+	}
+}
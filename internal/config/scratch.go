@@ -0,0 +1,16 @@
+package config
+
+// Scratch locations decide where work directories (chunks, the merged video
+// and intermediates) are kept during an encode.
+const (
+	ScratchDisk   = "disk"   // TempDir, falling back to the output and system temp directories
+	ScratchMemory = "memory" // A RAM-backed tmpfs (TempDir, or /dev/shm), falling back to disk
+	ScratchAuto   = "auto"   // /dev/shm when work files fit comfortably in memory, otherwise disk
+)
+
+// MemoryScratchDir is the RAM-backed tmpfs the memory locations use when no
+// temp dir is configured.
+const MemoryScratchDir = "/dev/shm"
+
+// ScratchLocations lists the accepted --scratch values.
+var ScratchLocations = []string{ScratchDisk, ScratchMemory, ScratchAuto}
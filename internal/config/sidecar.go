@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Sidecar holds per-title overrides read from a TOML file next to the source.
+// Unset fields keep the batch settings.
+type Sidecar struct {
+	CRF              *uint8 `toml:"crf"`                // CRF for this title, regardless of resolution
+	Crop             string `toml:"crop"`               // "auto" or "none"
+	FilmGrain        *uint8 `toml:"film_grain"`         // SVT-AV1 film grain synthesis level (0-50)
+	FilmGrainDenoise *bool  `toml:"film_grain_denoise"` // Denoise before grain synthesis
+	FilmGrainTable   string `toml:"film_grain_table"`   // Film grain table, relative to the sidecar
+	PALSlowdown      *bool  `toml:"pal_slowdown"`       // Slow 25fps video to the film rate
+	VideoStream      *int   `toml:"video_stream"`       // Video stream to encode (0-based)
+
+	Audio struct {
+		Tracks        []int    `toml:"tracks"`         // Audio stream indexes to keep (0-based)
+		Languages     []string `toml:"languages"`      // Language tags to keep
+		Passthrough   []string `toml:"passthrough"`    // Lossless formats to copy instead of encoding
+		PreserveAtmos *bool    `toml:"preserve_atmos"` // Copy Dolby Atmos and DTS:X streams
+		Downmix       *bool    `toml:"downmix"`        // Add a stereo downmix of the first surround stream
+		DialogueBoost *float64 `toml:"dialogue_boost"` // Center channel gain in the downmix, in dB
+	} `toml:"audio"`
+}
+
+// SidecarPath returns the sidecar path for an input: movie.mkv -> movie.reel.toml.
+func SidecarPath(inputPath string) string {
+	return strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + ".reel.toml"
+}
+
+// LoadSidecar reads the sidecar at path. Returns nil if it doesn't exist.
+func LoadSidecar(path string) (*Sidecar, error) {
+	var s Sidecar
+	md, err := toml.DecodeFile(path, &s)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load sidecar %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %q in sidecar %s", undecoded[0].String(), path)
+	}
+
+	if s.FilmGrainTable != "" && !filepath.IsAbs(s.FilmGrainTable) {
+		s.FilmGrainTable = filepath.Join(filepath.Dir(path), s.FilmGrainTable)
+	}
+	return &s, nil
+}
+
+// ApplySidecar applies per-title overrides to c and revalidates it.
+func (c *Config) ApplySidecar(s *Sidecar) error {
+	if s.CRF != nil {
+		c.CRFSD, c.CRFHD, c.CRFUHD = *s.CRF, *s.CRF, *s.CRF
+	}
+	if s.Crop != "" {
+		c.CropMode = s.Crop
+	}
+	if s.FilmGrain != nil {
+		c.FilmGrain = *s.FilmGrain
+	}
+	if s.FilmGrainDenoise != nil {
+		c.FilmGrainDenoise = *s.FilmGrainDenoise
+	}
+	if s.FilmGrainTable != "" {
+		c.FilmGrainTable = s.FilmGrainTable
+	}
+	if s.PALSlowdown != nil {
+		c.PALSlowdown = *s.PALSlowdown
+	}
+	if s.VideoStream != nil {
+		c.VideoStream = s.VideoStream
+	}
+	if s.Audio.Tracks != nil {
+		c.AudioTracks = s.Audio.Tracks
+	}
+	if s.Audio.Languages != nil {
+		c.AudioLanguages = s.Audio.Languages
+	}
+	if s.Audio.Passthrough != nil {
+		c.AudioPassthrough = s.Audio.Passthrough
+	}
+	if s.Audio.PreserveAtmos != nil {
+		c.PreserveAtmos = *s.Audio.PreserveAtmos
+	}
+	if s.Audio.Downmix != nil {
+		c.StereoDownmix = *s.Audio.Downmix
+	}
+	if s.Audio.DialogueBoost != nil {
+		c.DialogueBoostDB = *s.Audio.DialogueBoost
+	}
+	return c.Validate()
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSidecarPath(t *testing.T) {
+	if got := SidecarPath("/rips/Movie (2001).mkv"); got != "/rips/Movie (2001).reel.toml" {
+		t.Errorf("SidecarPath() = %q", got)
+	}
+}
+
+func TestApplySidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "movie.reel.toml")
+	content := `
+crf = 20
+crop = "none"
+film_grain = 8
+film_grain_table = "movie.tbl"
+pal_slowdown = true
+video_stream = 1
+
+[audio]
+languages = ["eng"]
+downmix = true
+dialogue_boost = 6.0
+preserve_atmos = true
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar, err := LoadSidecar(path)
+	if err != nil {
+		t.Fatalf("LoadSidecar() error = %v", err)
+	}
+	cfg := NewConfig(".", ".", ".")
+	if err := cfg.ApplySidecar(sidecar); err != nil {
+		t.Fatalf("ApplySidecar() error = %v", err)
+	}
+
+	if cfg.CRFSD != 20 || cfg.CRFHD != 20 || cfg.CRFUHD != 20 {
+		t.Errorf("CRF = %d/%d/%d, want 20 for all", cfg.CRFSD, cfg.CRFHD, cfg.CRFUHD)
+	}
+	if cfg.CropMode != "none" {
+		t.Errorf("CropMode = %q, want none", cfg.CropMode)
+	}
+	if cfg.FilmGrain != 8 {
+		t.Errorf("FilmGrain = %d, want 8", cfg.FilmGrain)
+	}
+	if want := filepath.Join(dir, "movie.tbl"); cfg.FilmGrainTable != want {
+		t.Errorf("FilmGrainTable = %q, want %q", cfg.FilmGrainTable, want)
+	}
+	if !cfg.PALSlowdown {
+		t.Error("PALSlowdown = false, want true")
+	}
+	if cfg.VideoStream == nil || *cfg.VideoStream != 1 {
+		t.Errorf("VideoStream = %v, want 1", cfg.VideoStream)
+	}
+	if len(cfg.AudioLanguages) != 1 || cfg.AudioLanguages[0] != "eng" || cfg.AudioTracks != nil {
+		t.Errorf("audio selection = %v/%v", cfg.AudioTracks, cfg.AudioLanguages)
+	}
+	if !cfg.StereoDownmix || cfg.DialogueBoostDB != 6 {
+		t.Errorf("downmix = %v at %g dB, want true at 6 dB", cfg.StereoDownmix, cfg.DialogueBoostDB)
+	}
+	if !cfg.PreserveAtmos {
+		t.Error("PreserveAtmos = false, want true")
+	}
+}
+
+func TestLoadSidecarErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if s, err := LoadSidecar(filepath.Join(dir, "missing.reel.toml")); s != nil || err != nil {
+		t.Errorf("LoadSidecar(missing) = %v, %v; want nil, nil", s, err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", "preset = 4\n"},
+		{"bad crop", "crop = \"sometimes\"\n"},
+		{"grain out of range", "film_grain = 60\n"},
+		{"crf out of range", "crf = 70\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "movie.reel.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			sidecar, err := LoadSidecar(path)
+			if err == nil {
+				err = NewConfig(".", ".", ".").ApplySidecar(sidecar)
+			}
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// reservedSvtParams are set by reel for every chunk. Overriding them would
+// break the raw frame pipeline or bypass reel's own options, and a second
+// copy of a parameter leaves it to SVT-AV1 which one applies.
+var reservedSvtParams = map[string]bool{
+	"i": true, "b": true, "input": true, "output": true,
+	"input-depth": true, "color-format": true, "profile": true,
+	"width": true, "height": true, "fps-num": true, "fps-denom": true,
+	"frames": true, "progress": true, "passes": true, "rc": true,
+	"crf": true, "preset": true, "lp": true,
+	"keyint": true, "scd": true, "scm": true, "tune": true,
+	"film-grain": true, "film-grain-denoise": true, "fgs-table": true,
+	"enable-variance-boost": true, "variance-boost-strength": true,
+	"variance-octile": true, "ac-bias": true,
+	// Color and HDR metadata, taken from the source
+	"color-primaries": true, "transfer-characteristics": true,
+	"matrix-coefficients": true, "mastering-display": true, "content-light": true,
+}
+
+// svtParamKey matches SvtAv1EncApp long option names.
+var svtParamKey = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ParseSvtParams converts "key=value:key=value" into SvtAv1EncApp arguments
+// ("--key value"). An empty string yields no arguments.
+func ParseSvtParams(params string) ([]string, error) {
+	var args []string
+	for _, pair := range strings.Split(params, ":") {
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimPrefix(key, "--")
+		if !ok || value == "" || !svtParamKey.MatchString(key) {
+			return nil, fmt.Errorf("invalid SVT-AV1 parameter %q (want key=value)", pair)
+		}
+		if reservedSvtParams[key] {
+			return nil, fmt.Errorf("SVT-AV1 parameter %q is managed by reel and can't be overridden", key)
+		}
+		args = append(args, "--"+key, value)
+	}
+	return args, nil
+}
+
+// Tile limits of SvtAv1EncApp, which takes them as log2 values (0-6 rows,
+// 0-4 columns).
+const (
+	maxTileRows    = 64
+	maxTileColumns = 16
+)
+
+// ParseTiles parses a tile layout given as "RxC", the number of tile rows
+// and columns, each a power of two.
+func ParseTiles(s string) (rows, columns uint8, err error) {
+	r, c, ok := strings.Cut(strings.ToLower(s), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid tiles %q (want RxC, e.g. 2x2)", s)
+	}
+	rowCount, err := strconv.ParseUint(r, 10, 8)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tile rows in %q", s)
+	}
+	columnCount, err := strconv.ParseUint(c, 10, 8)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tile columns in %q", s)
+	}
+	rows, columns = uint8(rowCount), uint8(columnCount)
+	if err := validateTiles(rows, columns); err != nil {
+		return 0, 0, err
+	}
+	return rows, columns, nil
+}
+
+// validateTiles checks tile counts against what SvtAv1EncApp accepts.
+func validateTiles(rows, columns uint8) error {
+	if !isPowerOfTwo(rows) || rows > maxTileRows {
+		return fmt.Errorf("tile rows must be a power of two up to %d, got %d", maxTileRows, rows)
+	}
+	if !isPowerOfTwo(columns) || columns > maxTileColumns {
+		return fmt.Errorf("tile columns must be a power of two up to %d, got %d", maxTileColumns, columns)
+	}
+	return nil
+}
+
+func isPowerOfTwo(n uint8) bool {
+	return n != 0 && n&(n-1) == 0
+}
+
+// cropFilterPattern matches a manual crop, with or without the "crop=" prefix.
+var cropFilterPattern = regexp.MustCompile(`^(crop=)?\d+:\d+:\d+:\d+$`)
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSvtParams(t *testing.T) {
+	tests := []struct {
+		params  string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"enable-qm=1:qm-min=8", []string{"--enable-qm", "1", "--qm-min", "8"}, false},
+		{"--sharpness=2:", []string{"--sharpness", "2"}, false},
+		{"enable-qm", nil, true},
+		{"enable-qm=", nil, true},
+		{"crf=20", nil, true},
+		{"width=1280", nil, true},
+		{"keyint=240", nil, true},
+		{"--ac-bias=0.5", nil, true},
+		{"enable-qm=1:mastering-display=G(0.265,0.69)", nil, true},
+		{"bad key=1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			got, err := ParseSvtParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSvtParams(%q) error = %v, wantErr %v", tt.params, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseSvtParams(%q) = %v, want %v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTiles(t *testing.T) {
+	tests := []struct {
+		tiles      string
+		rows, cols uint8
+		wantErr    bool
+	}{
+		{"1x1", 1, 1, false},
+		{"2x4", 2, 4, false},
+		{"64X16", 64, 16, false},
+		{"3x2", 0, 0, true},
+		{"2x32", 0, 0, true},
+		{"0x1", 0, 0, true},
+		{"2", 0, 0, true},
+		{"ax2", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tiles, func(t *testing.T) {
+			rows, cols, err := ParseTiles(tt.tiles)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTiles(%q) error = %v, wantErr %v", tt.tiles, err, tt.wantErr)
+			}
+			if rows != tt.rows || cols != tt.cols {
+				t.Errorf("ParseTiles(%q) = %dx%d, want %dx%d", tt.tiles, rows, cols, tt.rows, tt.cols)
+			}
+		})
+	}
+}
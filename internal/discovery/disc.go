@@ -0,0 +1,381 @@
+package discovery
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// Disc kinds whose folder structure reel reads a main title from.
+const (
+	DiscDVD    = "dvd"    // VIDEO_TS with VTS_NN_K.VOB title sets
+	DiscBluray = "bluray" // BDMV with PLAYLIST/*.mpls playing STREAM/*.m2ts clips
+)
+
+// Disc is a DVD or Blu-ray folder structure, as copied from a disc.
+type Disc struct {
+	Kind string
+	Root string // Folder holding VIDEO_TS or BDMV
+	Name string // Name of Root, which outputs are named after
+	dir  string // The VIDEO_TS or BDMV folder
+}
+
+// DiscTitle is a title of a disc: the stream files that play in order.
+type DiscTitle struct {
+	Name         string // Playlist or program chain, "" for a clip or whole title set
+	Files        []string
+	Sectors      []SectorRange // Cells played from Files, nil to play them whole
+	DurationSecs float64
+	Size         uint64 // Bytes played
+	Incomplete   bool   // Some of the playlist's clips are missing from the folder
+}
+
+// vobPattern matches title set VOBs, VTS_NN_K.VOB; K 0 holds the menus.
+var vobPattern = regexp.MustCompile(`(?i)^VTS_(\d\d)_(\d)\.VOB$`)
+
+// minTitleShare is the size, relative to the largest clip, below which a
+// Blu-ray clip is taken for a menu or extra and not probed, and likewise the
+// duration relative to the longest playlist for playlists.
+const minTitleShare = 0.25
+
+// DetectDisc reports whether path is a disc folder: one holding VIDEO_TS or
+// BDMV, or the VIDEO_TS or BDMV folder itself. Names match in any case.
+func DetectDisc(path string) (*Disc, bool) {
+	for _, kind := range []struct{ kind, dir string }{{DiscDVD, "VIDEO_TS"}, {DiscBluray, "BDMV"}} {
+		if strings.EqualFold(filepath.Base(path), kind.dir) {
+			root := filepath.Dir(path)
+			return &Disc{Kind: kind.kind, Root: root, Name: filepath.Base(root), dir: path}, true
+		}
+		if dir, ok := childDir(path, kind.dir); ok {
+			return &Disc{Kind: kind.kind, Root: path, Name: filepath.Base(path), dir: dir}, true
+		}
+	}
+	return nil, false
+}
+
+// childDir returns the subdirectory of parent named name in any case.
+func childDir(parent, name string) (string, bool) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if e.IsDir() && strings.EqualFold(e.Name(), name) {
+			return filepath.Join(parent, e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// Titles returns the disc's candidate titles with their durations: each
+// program chain of a DVD title set, or each Blu-ray playlist long enough to
+// be a feature. Without a readable IFO, a DVD title set is one title; without
+// playlists, each Blu-ray clip large enough to be a feature is. Durations
+// not recorded on the disc are probed with the ffprobe in paths.
+func (d *Disc) Titles(paths toolpath.Paths) ([]DiscTitle, error) {
+	var titles []DiscTitle
+	var err error
+	if d.Kind == DiscDVD {
+		titles, err = d.dvdTitles()
+	} else if titles, err = d.playlistTitles(); err == nil && len(titles) == 0 {
+		titles, err = d.blurayTitles()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(titles, func(t DiscTitle) bool { return !t.Incomplete }) {
+		return nil, fmt.Errorf("no titles found in %s", d.dir)
+	}
+	for i := range titles {
+		if titles[i].DurationSecs > 0 {
+			continue
+		}
+		path := titles[i].Files[0]
+		if len(titles[i].Files) > 1 {
+			path = "concat:" + strings.Join(titles[i].Files, "|")
+		}
+		if titles[i].DurationSecs, err = probeDuration(paths, path); err != nil {
+			return nil, fmt.Errorf("failed to probe %s: %w", filepath.Base(titles[i].Files[0]), err)
+		}
+	}
+	return titles, nil
+}
+
+// MainTitle returns the longest complete title of titles, which must hold
+// one, or the larger one if two are as long to the second.
+func MainTitle(titles []DiscTitle) DiscTitle {
+	complete := slices.DeleteFunc(slices.Clone(titles), func(t DiscTitle) bool { return t.Incomplete })
+	return slices.MaxFunc(complete, func(a, b DiscTitle) int {
+		return cmp.Or(cmp.Compare(int(a.DurationSecs), int(b.DurationSecs)), cmp.Compare(a.Size, b.Size))
+	})
+}
+
+// dvdTitles groups the VOBs of each title set in playing order, skipping
+// the menu VOBs, and returns a title for each distinct program chain its
+// IFO lists, or the whole set when the IFO can't be read.
+func (d *Disc) dvdTitles() ([]DiscTitle, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[string]*DiscTitle)
+	var order []string
+	names := make(map[string]string)
+	for _, e := range entries {
+		names[strings.ToUpper(e.Name())] = e.Name()
+		m := vobPattern.FindStringSubmatch(e.Name())
+		if m == nil || m[2] == "0" || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		t, ok := sets[m[1]]
+		if !ok {
+			t = &DiscTitle{}
+			sets[m[1]] = t
+			order = append(order, m[1])
+		}
+		t.Files = append(t.Files, filepath.Join(d.dir, e.Name()))
+		t.Size += uint64(info.Size())
+	}
+	slices.Sort(order)
+	titles := make([]DiscTitle, 0, len(order))
+	for _, set := range order {
+		t := sets[set]
+		slices.SortFunc(t.Files, func(a, b string) int {
+			return cmp.Compare(vobPart(a), vobPart(b))
+		})
+		chains := d.programChains(names, set, t.Size)
+		if len(chains) == 0 {
+			titles = append(titles, *t)
+			continue
+		}
+		seen := make(map[string]bool)
+		for i, chain := range chains {
+			key := fmt.Sprint(chain.cells)
+			if len(chain.cells) == 0 || chain.seconds == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			title := DiscTitle{
+				Name:         fmt.Sprintf("VTS_%s PGC %d", set, i+1),
+				Files:        t.Files,
+				Sectors:      chain.cells,
+				DurationSecs: chain.seconds,
+			}
+			for _, r := range chain.cells {
+				title.Size += uint64(r.Last-r.First+1) * DVDSector
+			}
+			titles = append(titles, title)
+		}
+	}
+	return titles, nil
+}
+
+// programChains reads the program chains of title set set from its IFO, or
+// its backup, found through names (upper-case name to name). Returns nil if
+// neither can be read or a chain plays past the size of the set's VOBs.
+func (d *Disc) programChains(names map[string]string, set string, size uint64) []programChain {
+	for _, ext := range []string{"IFO", "BUP"} {
+		name, ok := names[fmt.Sprintf("VTS_%s_0.%s", set, ext)]
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(d.dir, name))
+		if err != nil {
+			continue
+		}
+		if chains, err := parseTitleSetIFO(data); err == nil && chainsFit(chains, size) {
+			return chains
+		}
+	}
+	return nil
+}
+
+// chainsFit reports whether every cell of chains lies within the first
+// size bytes of the title VOBs.
+func chainsFit(chains []programChain, size uint64) bool {
+	for _, c := range chains {
+		for _, r := range c.cells {
+			if uint64(r.Last+1)*DVDSector > size {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// vobPart returns K of VTS_NN_K.VOB.
+func vobPart(path string) int {
+	m := vobPattern.FindStringSubmatch(filepath.Base(path))
+	k, _ := strconv.Atoi(m[2])
+	return k
+}
+
+// blurayTitles returns the clips in BDMV/STREAM large enough to be a
+// feature, each as a title of its own.
+func (d *Disc) blurayTitles() ([]DiscTitle, error) {
+	clips, err := d.streamClips()
+	if err != nil {
+		return nil, err
+	}
+	var largest uint64
+	for _, c := range clips {
+		largest = max(largest, c.Size)
+	}
+	return slices.DeleteFunc(clips, func(c DiscTitle) bool {
+		return float64(c.Size) < float64(largest)*minTitleShare
+	}), nil
+}
+
+// streamClips returns each clip in BDMV/STREAM as a title of its own.
+func (d *Disc) streamClips() ([]DiscTitle, error) {
+	streamDir, ok := childDir(d.dir, "STREAM")
+	if !ok {
+		return nil, fmt.Errorf("no STREAM folder in %s", d.dir)
+	}
+	entries, err := os.ReadDir(streamDir)
+	if err != nil {
+		return nil, err
+	}
+	var clips []DiscTitle
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".m2ts") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, DiscTitle{Files: []string{filepath.Join(streamDir, e.Name())}, Size: uint64(info.Size())})
+	}
+	return clips, nil
+}
+
+// playlistTitles returns a title for each distinct playlist in
+// BDMV/PLAYLIST at least minTitleShare as long as the longest, timed from
+// its play items. Playlists that play a clip twice, as menu loops do, or
+// can't be read are left out; those with clips missing from STREAM are
+// marked incomplete. Returns nil without a PLAYLIST folder.
+func (d *Disc) playlistTitles() ([]DiscTitle, error) {
+	playlistDir, ok := childDir(d.dir, "PLAYLIST")
+	if !ok {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(playlistDir)
+	if err != nil {
+		return nil, err
+	}
+	clips, err := d.streamClips()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]DiscTitle, len(clips))
+	for _, c := range clips {
+		byName[strings.ToUpper(strings.TrimSuffix(filepath.Base(c.Files[0]), filepath.Ext(c.Files[0])))] = c
+	}
+
+	var titles []DiscTitle
+	var longest float64
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".mpls") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(playlistDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		items, err := parsePlaylist(data)
+		if err != nil || len(items) == 0 || repeatsClip(items) {
+			continue
+		}
+		key := fmt.Sprint(items)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		title := DiscTitle{Name: e.Name()}
+		for _, item := range items {
+			title.DurationSecs += item.seconds
+			clip, ok := byName[strings.ToUpper(item.clip)]
+			if !ok {
+				title.Incomplete = true
+				continue
+			}
+			title.Files = append(title.Files, clip.Files[0])
+			title.Size += clip.Size
+		}
+		titles = append(titles, title)
+		longest = max(longest, title.DurationSecs)
+	}
+	return slices.DeleteFunc(titles, func(t DiscTitle) bool {
+		return t.DurationSecs < longest*minTitleShare
+	}), nil
+}
+
+// repeatsClip reports whether a clip appears more than once in items.
+func repeatsClip(items []playItem) bool {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.clip] {
+			return true
+		}
+		seen[item.clip] = true
+	}
+	return false
+}
+
+// Segment is a run of bytes of a file.
+type Segment struct {
+	Path   string
+	Offset int64
+	Length int64
+}
+
+// Segments returns the runs of its files the title plays, in order: the
+// cells in Sectors mapped onto Files by their sizes, or each file whole.
+func (t DiscTitle) Segments() ([]Segment, error) {
+	sizes := make([]int64, len(t.Files))
+	for i, path := range t.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = info.Size()
+	}
+	if t.Sectors == nil {
+		segments := make([]Segment, len(t.Files))
+		for i, path := range t.Files {
+			segments[i] = Segment{Path: path, Length: sizes[i]}
+		}
+		return segments, nil
+	}
+
+	var segments []Segment
+	for _, r := range t.Sectors {
+		start, end := int64(r.First)*DVDSector, int64(r.Last+1)*DVDSector
+		var fileStart int64
+		for i, path := range t.Files {
+			fileEnd := fileStart + sizes[i]
+			if from, to := max(start, fileStart), min(end, fileEnd); from < to {
+				segments = append(segments, Segment{Path: path, Offset: from - fileStart, Length: to - from})
+			}
+			fileStart = fileEnd
+		}
+		if end > fileStart {
+			return nil, fmt.Errorf("sectors %d-%d are past the end of %s", r.First, r.Last, filepath.Base(t.Files[0]))
+		}
+	}
+	return segments, nil
+}
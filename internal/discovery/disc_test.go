@@ -0,0 +1,388 @@
+package discovery
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile creates path, and its directory, holding size zero bytes.
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	writeData(t, path, make([]byte, size))
+}
+
+// writeData creates path, and its directory, holding data.
+func writeData(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testItem is a play item of a test playlist.
+type testItem struct {
+	clip    string
+	seconds float64
+}
+
+// mplsData builds an MPLS playlist playing items.
+func mplsData(items ...testItem) []byte {
+	data := []byte("MPLS0200")
+	data = binary.BigEndian.AppendUint32(data, 20) // PlayList
+	data = binary.BigEndian.AppendUint32(data, 0)  // PlayListMark
+	data = binary.BigEndian.AppendUint32(data, 0)  // ExtensionData
+	data = binary.BigEndian.AppendUint32(data, uint32(4+len(items)*22))
+	data = append(data, 0, 0)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(items)))
+	data = binary.BigEndian.AppendUint16(data, 0) // SubPaths
+	for _, item := range items {
+		data = binary.BigEndian.AppendUint16(data, 20)
+		data = append(data, item.clip+"M2TS"...)
+		data = append(data, 0, 1, 0)
+		data = binary.BigEndian.AppendUint32(data, 1000)
+		data = binary.BigEndian.AppendUint32(data, 1000+uint32(item.seconds*mplsClock))
+	}
+	return data
+}
+
+// testCell is a cell of a test program chain.
+type testCell struct {
+	category    byte // Block mode and type bits
+	first, last uint32
+}
+
+// testChain is a program chain of a test IFO, with its playback time as
+// BCD hh mm ss ff.
+type testChain struct {
+	time  [4]byte
+	cells []testCell
+}
+
+// ifoData builds a title set IFO listing chains, with the program chain
+// table in its second sector.
+func ifoData(chains ...testChain) []byte {
+	data := make([]byte, DVDSector)
+	copy(data, "DVDVIDEO-VTS")
+	binary.BigEndian.PutUint32(data[ifoPGCITable:], 1)
+
+	table := binary.BigEndian.AppendUint16(nil, uint16(len(chains)))
+	table = append(table, 0, 0, 0, 0, 0, 0)
+	offset := 8 + 8*len(chains)
+	var pgcs []byte
+	for _, chain := range chains {
+		table = append(table, 0x81, 0, 0, 0)
+		table = binary.BigEndian.AppendUint32(table, uint32(offset+len(pgcs)))
+		pgc := make([]byte, pgcMinLength)
+		pgc[pgcCellCount] = byte(len(chain.cells))
+		copy(pgc[pgcPlayTime:], chain.time[:])
+		binary.BigEndian.PutUint16(pgc[pgcCellTable:], pgcMinLength)
+		for _, c := range chain.cells {
+			cell := make([]byte, cellEntryBytes)
+			cell[0] = c.category
+			binary.BigEndian.PutUint32(cell[8:], c.first)
+			binary.BigEndian.PutUint32(cell[20:], c.last)
+			pgc = append(pgc, cell...)
+		}
+		pgcs = append(pgcs, pgc...)
+	}
+	return append(append(data, table...), pgcs...)
+}
+
+func TestDetectDisc(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"dvd/VIDEO_TS", "bluray/bdmv", "plain/extras"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "file/VIDEO_TS"), 1)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantKind string
+		wantRoot string
+		wantOK   bool
+	}{
+		{"folder holding VIDEO_TS", "dvd", DiscDVD, "dvd", true},
+		{"VIDEO_TS itself", "dvd/VIDEO_TS", DiscDVD, "dvd", true},
+		{"lower-case BDMV", "bluray", DiscBluray, "bluray", true},
+		{"BDMV itself", "bluray/bdmv", DiscBluray, "bluray", true},
+		{"plain folder", "plain", "", "", false},
+		{"file named VIDEO_TS", "file", "", "", false},
+		{"missing folder", "missing", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disc, ok := DetectDisc(filepath.Join(dir, tt.path))
+			if ok != tt.wantOK {
+				t.Fatalf("DetectDisc() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if disc.Kind != tt.wantKind || disc.Root != filepath.Join(dir, tt.wantRoot) || disc.Name != tt.wantRoot {
+				t.Errorf("DetectDisc() = %+v, want kind %s, root %s", disc, tt.wantKind, tt.wantRoot)
+			}
+		})
+	}
+}
+
+func TestVobPart(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/disc/VIDEO_TS/VTS_01_1.VOB", 1},
+		{"/disc/VIDEO_TS/VTS_01_9.VOB", 9},
+		{"/disc/VIDEO_TS/vts_12_3.vob", 3},
+	}
+	for _, tt := range tests {
+		if got := vobPart(tt.path); got != tt.want {
+			t.Errorf("vobPart(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMainTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		titles []DiscTitle
+		want   string
+	}{
+		{"single", []DiscTitle{{Name: "a", DurationSecs: 60}}, "a"},
+		{"longest", []DiscTitle{{Name: "a", DurationSecs: 60, Size: 9}, {Name: "b", DurationSecs: 6000, Size: 1}}, "b"},
+		{"larger when as long to the second", []DiscTitle{{Name: "a", DurationSecs: 6000.2, Size: 1}, {Name: "b", DurationSecs: 6000.7, Size: 2}}, "b"},
+		{"longer by a second", []DiscTitle{{Name: "a", DurationSecs: 6001, Size: 1}, {Name: "b", DurationSecs: 6000, Size: 2}}, "a"},
+		{"incomplete skipped", []DiscTitle{{Name: "a", DurationSecs: 9000, Incomplete: true}, {Name: "b", DurationSecs: 6000}}, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MainTitle(tt.titles); got.Name != tt.want {
+				t.Errorf("MainTitle() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestDVDTitles(t *testing.T) {
+	movie := testChain{time: [4]byte{0x01, 0x30, 0x00, 0xC0}, cells: []testCell{{0, 0, 9}, {0, 10, 19}}}
+	episode := testChain{time: [4]byte{0x00, 0x22, 0x30, 0x45}, cells: []testCell{{0, 20, 29}}}
+	angles := testChain{time: [4]byte{0x00, 0x00, 0x10, 0x00}, cells: []testCell{{0x50, 30, 31}, {0x90, 32, 33}, {0xD0, 34, 35}, {0, 36, 37}}}
+	empty := testChain{time: [4]byte{0x00, 0x00, 0x00, 0x00}}
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  []DiscTitle
+	}{
+		{
+			name: "title sets without IFO",
+			files: map[string][]byte{
+				"VTS_01_0.VOB": make([]byte, 5),
+				"VTS_01_2.VOB": make([]byte, 3),
+				"VTS_01_1.VOB": make([]byte, 4),
+				"VTS_02_1.VOB": make([]byte, 2),
+			},
+			want: []DiscTitle{
+				{Files: []string{"VTS_01_1.VOB", "VTS_01_2.VOB"}, Size: 7},
+				{Files: []string{"VTS_02_1.VOB"}, Size: 2},
+			},
+		},
+		{
+			name: "program chains",
+			files: map[string][]byte{
+				"VTS_01_0.IFO": ifoData(movie, episode, movie, angles, empty),
+				"VTS_01_1.VOB": make([]byte, 20*DVDSector),
+				"VTS_01_2.VOB": make([]byte, 20*DVDSector),
+			},
+			want: []DiscTitle{
+				{Name: "VTS_01 PGC 1", Files: []string{"VTS_01_1.VOB", "VTS_01_2.VOB"}, Sectors: []SectorRange{{0, 19}}, DurationSecs: 5400, Size: 20 * DVDSector},
+				{Name: "VTS_01 PGC 2", Files: []string{"VTS_01_1.VOB", "VTS_01_2.VOB"}, Sectors: []SectorRange{{20, 29}}, DurationSecs: 1350 + 5.0/25, Size: 10 * DVDSector},
+				{Name: "VTS_01 PGC 4", Files: []string{"VTS_01_1.VOB", "VTS_01_2.VOB"}, Sectors: []SectorRange{{30, 31}, {36, 37}}, DurationSecs: 10, Size: 4 * DVDSector},
+			},
+		},
+		{
+			name: "backup IFO",
+			files: map[string][]byte{
+				"vts_01_0.bup": ifoData(episode),
+				"vts_01_1.vob": make([]byte, 30*DVDSector),
+			},
+			want: []DiscTitle{
+				{Name: "VTS_01 PGC 1", Files: []string{"vts_01_1.vob"}, Sectors: []SectorRange{{20, 29}}, DurationSecs: 1350 + 5.0/25, Size: 10 * DVDSector},
+			},
+		},
+		{
+			name: "IFO past the VOBs",
+			files: map[string][]byte{
+				"VTS_01_0.IFO": ifoData(movie),
+				"VTS_01_1.VOB": make([]byte, 10*DVDSector),
+			},
+			want: []DiscTitle{
+				{Files: []string{"VTS_01_1.VOB"}, Size: 10 * DVDSector},
+			},
+		},
+		{
+			name: "unreadable IFO",
+			files: map[string][]byte{
+				"VTS_01_0.IFO": []byte("DVDVIDEO-VTS"),
+				"VTS_01_1.VOB": make([]byte, 10),
+			},
+			want: []DiscTitle{
+				{Files: []string{"VTS_01_1.VOB"}, Size: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			videoTS := filepath.Join(root, "VIDEO_TS")
+			for name, data := range tt.files {
+				writeData(t, filepath.Join(videoTS, name), data)
+			}
+			disc, ok := DetectDisc(root)
+			if !ok {
+				t.Fatal("DetectDisc() found no disc")
+			}
+			got, err := disc.dvdTitles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				for j, f := range tt.want[i].Files {
+					tt.want[i].Files[j] = filepath.Join(videoTS, f)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dvdTitles() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlurayTitles(t *testing.T) {
+	root := t.TempDir()
+	stream := filepath.Join(root, "BDMV", "STREAM")
+	playlists := filepath.Join(root, "BDMV", "PLAYLIST")
+	for name, size := range map[string]int{"00001.m2ts": 100, "00002.m2ts": 200, "00003.M2TS": 50, "00009.m2ts": 1} {
+		writeFile(t, filepath.Join(stream, name), size)
+	}
+	feature := mplsData(testItem{"00001", 3000}, testItem{"00003", 600}, testItem{"00002", 3600})
+	writeData(t, filepath.Join(playlists, "00800.mpls"), feature)
+	writeData(t, filepath.Join(playlists, "00801.mpls"), feature) // Duplicate
+	writeData(t, filepath.Join(playlists, "00010.mpls"), mplsData(testItem{"00002", 3600}))
+	writeData(t, filepath.Join(playlists, "00020.mpls"), mplsData(testItem{"00009", 30}))                            // Too short
+	writeData(t, filepath.Join(playlists, "00030.mpls"), mplsData(testItem{"00009", 9000}, testItem{"00009", 9000})) // Loop
+	writeData(t, filepath.Join(playlists, "00040.mpls"), mplsData(testItem{"00001", 3000}, testItem{"00005", 6000}))
+	writeData(t, filepath.Join(playlists, "00050.mpls"), []byte("MPLS0200"))
+
+	disc, ok := DetectDisc(root)
+	if !ok {
+		t.Fatal("DetectDisc() found no disc")
+	}
+	got, err := disc.Titles(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clip := func(name string) string { return filepath.Join(stream, name) }
+	want := []DiscTitle{
+		{Name: "00010.mpls", Files: []string{clip("00002.m2ts")}, DurationSecs: 3600, Size: 200},
+		{Name: "00040.mpls", Files: []string{clip("00001.m2ts")}, DurationSecs: 9000, Size: 100, Incomplete: true},
+		{Name: "00800.mpls", Files: []string{clip("00001.m2ts"), clip("00003.M2TS"), clip("00002.m2ts")}, DurationSecs: 7200, Size: 350},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Titles() = %+v\nwant %+v", got, want)
+	}
+	if main := MainTitle(got); main.Name != "00800.mpls" {
+		t.Errorf("MainTitle() = %s, want 00800.mpls", main.Name)
+	}
+}
+
+func TestBlurayTitlesWithoutPlaylists(t *testing.T) {
+	root := t.TempDir()
+	stream := filepath.Join(root, "BDMV", "STREAM")
+	for name, size := range map[string]int{"00001.m2ts": 100, "00002.m2ts": 30, "00003.m2ts": 24, "info.txt": 500} {
+		writeFile(t, filepath.Join(stream, name), size)
+	}
+	disc, _ := DetectDisc(root)
+	got, err := disc.blurayTitles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiscTitle{
+		{Files: []string{filepath.Join(stream, "00001.m2ts")}, Size: 100},
+		{Files: []string{filepath.Join(stream, "00002.m2ts")}, Size: 30},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("blurayTitles() = %+v\nwant %+v", got, want)
+	}
+	if titles, err := disc.playlistTitles(); err != nil || titles != nil {
+		t.Errorf("playlistTitles() = %v, %v, want nil without a PLAYLIST folder", titles, err)
+	}
+}
+
+func TestParsePlaylist(t *testing.T) {
+	valid := mplsData(testItem{"00001", 60}, testItem{"00002", 1.5})
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []playItem
+		wantErr bool
+	}{
+		{"valid", valid, []playItem{{"00001", 60}, {"00002", 1.5}}, false},
+		{"no items", mplsData(), []playItem{}, false},
+		{"not MPLS", append([]byte("XPLS"), valid[4:]...), nil, true},
+		{"truncated", valid[:len(valid)-4], nil, true},
+		{"header only", valid[:20], nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePlaylist(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePlaylist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePlaylist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegments(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "VTS_01_1.VOB"), filepath.Join(dir, "VTS_01_2.VOB")
+	writeFile(t, a, 4*DVDSector)
+	writeFile(t, b, 3*DVDSector)
+
+	tests := []struct {
+		name    string
+		sectors []SectorRange
+		want    []Segment
+		wantErr bool
+	}{
+		{"whole files", nil, []Segment{{a, 0, 4 * DVDSector}, {b, 0, 3 * DVDSector}}, false},
+		{"within a file", []SectorRange{{1, 2}}, []Segment{{a, DVDSector, 2 * DVDSector}}, false},
+		{"across files", []SectorRange{{3, 4}}, []Segment{{a, 3 * DVDSector, DVDSector}, {b, 0, DVDSector}}, false},
+		{"in order", []SectorRange{{5, 6}, {0, 0}}, []Segment{{b, DVDSector, 2 * DVDSector}, {a, 0, DVDSector}}, false},
+		{"past the end", []SectorRange{{6, 7}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiscTitle{Files: []string{a, b}, Sectors: tt.sectors}.Segments()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Segments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@
 package discovery
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,9 +13,9 @@ import (
 	"github.com/five82/reel/internal/util"
 )
 
-// FindVideoFiles finds video files in the given directory.
-// Returns files sorted alphabetically by filename.
-func FindVideoFiles(inputDir string) ([]string, error) {
+// FindVideoFiles finds files with one of the given extensions in the given
+// directory. Returns files sorted alphabetically by filename.
+func FindVideoFiles(inputDir string, extensions []string) ([]string, error) {
 	info, err := os.Stat(inputDir)
 	if err != nil {
 		return nil, fmt.Errorf("directory does not exist: %s", inputDir)
@@ -42,7 +44,7 @@ func FindVideoFiles(inputDir string) ([]string, error) {
 		}
 
 		fullPath := filepath.Join(inputDir, name)
-		if util.IsVideoFile(fullPath) {
+		if util.IsVideoFile(fullPath, extensions) {
 			files = append(files, fullPath)
 		}
 	}
@@ -58,3 +60,48 @@ func FindVideoFiles(inputDir string) ([]string, error) {
 
 	return files, nil
 }
+
+// ReadFileList reads a list of input files, one per line or, when the list
+// contains a NUL byte (as from find -print0), NUL-delimited. Paths are made
+// absolute and keep the order they were listed in; blank entries are
+// ignored. Every path must be an existing file.
+func ReadFileList(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file list: %w", err)
+	}
+
+	sep := []byte("\n")
+	if bytes.IndexByte(data, 0) >= 0 {
+		sep = []byte{0}
+	}
+
+	var files []string
+	for entry := range bytes.SplitSeq(data, sep) {
+		name := string(entry)
+		if sep[0] == '\n' {
+			name = strings.TrimSuffix(name, "\r")
+		}
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input path %q: %w", name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("input path does not exist: %s", path)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory; list files only", path)
+		}
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no input files listed")
+	}
+	return files, nil
+}
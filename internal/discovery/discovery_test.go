@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.mkv")
+	b := filepath.Join(dir, "with space.mkv")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"newline list", a + "\n" + b + "\n", []string{a, b}, false},
+		{"no trailing newline", b + "\n" + a, []string{b, a}, false},
+		{"blank lines", "\n" + a + "\n\n  \n" + b + "\n\n", []string{a, b}, false},
+		{"CRLF", a + "\r\n" + b + "\r\n", []string{a, b}, false},
+		{"NUL list", a + "\x00" + b + "\x00", []string{a, b}, false},
+		{"NUL list with empty entries", a + "\x00\x00" + b, []string{a, b}, false},
+		{"missing file", a + "\n" + filepath.Join(dir, "gone.mkv") + "\n", nil, true},
+		{"directory", dir + "\n", nil, true},
+		{"empty", "\n\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFileList(strings.NewReader(tt.list))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFileList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ReadFileList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFileListMakesPathsAbsolute(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("movie.mkv", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadFileList(strings.NewReader("movie.mkv\n"))
+	if err != nil {
+		t.Fatalf("ReadFileList() error = %v", err)
+	}
+	if want := filepath.Join(dir, "movie.mkv"); len(got) != 1 || got[0] != want {
+		t.Errorf("ReadFileList() = %q, want [%q]", got, want)
+	}
+}
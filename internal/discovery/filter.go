@@ -0,0 +1,98 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+// Filter narrows the files discovered in an input directory, so batch runs
+// can skip samples, extras and trailers. The zero value keeps every file.
+type Filter struct {
+	Include     []string      // Name globs a file must match one of, if any are given
+	Exclude     []string      // Name globs that drop a matching file
+	MinSize     uint64        // Smallest file size in bytes
+	MinDuration time.Duration // Shortest duration; each remaining file is probed
+}
+
+// Excluded is a file a Filter dropped.
+type Excluded struct {
+	Path   string
+	Reason string
+}
+
+// probeDuration returns the duration of a file in seconds.
+func probeDuration(paths toolpath.Paths, path string) (float64, error) {
+	props, err := ffprobe.GetVideoProperties(paths, path)
+	if err != nil {
+		return 0, err
+	}
+	return props.DurationSecs, nil
+}
+
+// Validate checks the glob patterns are well formed.
+func (f Filter) Validate() error {
+	for _, pattern := range slices.Concat(f.Include, f.Exclude) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Apply returns the files f keeps, in order, and the ones it drops. Globs
+// match the file name case-insensitively. A file whose duration can't be
+// probed is kept, so encoding it reports the problem. Durations are probed
+// with the ffprobe in paths.
+func (f Filter) Apply(paths toolpath.Paths, files []string) (kept []string, excluded []Excluded) {
+	for _, path := range files {
+		if reason := f.exclude(paths, path); reason != "" {
+			excluded = append(excluded, Excluded{Path: path, Reason: reason})
+		} else {
+			kept = append(kept, path)
+		}
+	}
+	return kept, excluded
+}
+
+// exclude returns why f drops the file at path, or "" to keep it. The
+// cheap checks run first so only files they keep are probed.
+func (f Filter) exclude(paths toolpath.Paths, path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	if len(f.Include) > 0 && !matchAny(f.Include, name) {
+		return "matches no include pattern"
+	}
+	for _, pattern := range f.Exclude {
+		if matchAny([]string{pattern}, name) {
+			return fmt.Sprintf("matches %q", pattern)
+		}
+	}
+	if f.MinSize > 0 {
+		if info, err := os.Stat(path); err == nil && uint64(info.Size()) < f.MinSize {
+			return fmt.Sprintf("smaller than %s", util.FormatBytesReadable(f.MinSize))
+		}
+	}
+	if f.MinDuration > 0 {
+		if secs, err := probeDuration(paths, path); err == nil && secs < f.MinDuration.Seconds() {
+			return fmt.Sprintf("shorter than %s", f.MinDuration)
+		}
+	}
+	return ""
+}
+
+// matchAny reports whether the lower-cased name matches one of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package discovery
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantErr bool
+	}{
+		{"zero value", Filter{}, false},
+		{"valid globs", Filter{Include: []string{"*.mkv", "s0?e*"}, Exclude: []string{"*[Ss]ample*"}}, false},
+		{"bad include", Filter{Include: []string{"[a-"}}, true},
+		{"bad exclude", Filter{Include: []string{"*.mkv"}, Exclude: []string{"trailer\\"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	dir := t.TempDir()
+	sizes := map[string]int{
+		"Movie.mkv":         1 << 20,
+		"Movie-Sample.mkv":  1 << 20,
+		"Movie-Trailer.mp4": 1 << 20,
+		"S01E01.mkv":        1 << 20,
+		"extra.mkv":         100,
+	}
+	var files []string
+	for _, name := range []string{"Movie.mkv", "Movie-Sample.mkv", "Movie-Trailer.mp4", "S01E01.mkv", "extra.mkv"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, sizes[name]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	tests := []struct {
+		name         string
+		filter       Filter
+		wantKept     []string
+		wantExcluded map[string]string
+	}{
+		{
+			name:     "zero value keeps everything",
+			filter:   Filter{},
+			wantKept: []string{"Movie.mkv", "Movie-Sample.mkv", "Movie-Trailer.mp4", "S01E01.mkv", "extra.mkv"},
+		},
+		{
+			name:     "include matches case-insensitively",
+			filter:   Filter{Include: []string{"*.MKV"}},
+			wantKept: []string{"Movie.mkv", "Movie-Sample.mkv", "S01E01.mkv", "extra.mkv"},
+			wantExcluded: map[string]string{
+				"Movie-Trailer.mp4": "matches no include pattern",
+			},
+		},
+		{
+			name:     "exclude wins over include",
+			filter:   Filter{Include: []string{"movie*"}, Exclude: []string{"*sample*", "*trailer*"}},
+			wantKept: []string{"Movie.mkv"},
+			wantExcluded: map[string]string{
+				"Movie-Sample.mkv":  `matches "*sample*"`,
+				"Movie-Trailer.mp4": `matches "*trailer*"`,
+				"S01E01.mkv":        "matches no include pattern",
+				"extra.mkv":         "matches no include pattern",
+			},
+		},
+		{
+			name:     "first matching exclude is reported",
+			filter:   Filter{Exclude: []string{"*-*", "*sample*"}},
+			wantKept: []string{"Movie.mkv", "S01E01.mkv", "extra.mkv"},
+			wantExcluded: map[string]string{
+				"Movie-Sample.mkv":  `matches "*-*"`,
+				"Movie-Trailer.mp4": `matches "*-*"`,
+			},
+		},
+		{
+			name:     "size below the minimum",
+			filter:   Filter{MinSize: 512 << 10},
+			wantKept: []string{"Movie.mkv", "Movie-Sample.mkv", "Movie-Trailer.mp4", "S01E01.mkv"},
+			wantExcluded: map[string]string{
+				"extra.mkv": "smaller than 0.50 MB (0.00 GB)",
+			},
+		},
+		{
+			name:     "size at the minimum is kept",
+			filter:   Filter{MinSize: 1 << 20},
+			wantKept: []string{"Movie.mkv", "Movie-Sample.mkv", "Movie-Trailer.mp4", "S01E01.mkv"},
+			wantExcluded: map[string]string{
+				"extra.mkv": "smaller than 1.00 MB (0.00 GB)",
+			},
+		},
+		{
+			name:     "unprobeable files are kept",
+			filter:   Filter{MinDuration: time.Minute},
+			wantKept: []string{"Movie.mkv", "Movie-Sample.mkv", "Movie-Trailer.mp4", "S01E01.mkv", "extra.mkv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, excluded := tt.filter.Apply(nil, files)
+			var keptNames []string
+			for _, path := range kept {
+				keptNames = append(keptNames, filepath.Base(path))
+			}
+			if !slices.Equal(keptNames, tt.wantKept) {
+				t.Errorf("kept = %v, want %v", keptNames, tt.wantKept)
+			}
+			if len(excluded) != len(tt.wantExcluded) {
+				t.Fatalf("excluded = %v, want %v", excluded, tt.wantExcluded)
+			}
+			for _, e := range excluded {
+				if want := tt.wantExcluded[filepath.Base(e.Path)]; e.Reason != want {
+					t.Errorf("%s excluded for %q, want %q", filepath.Base(e.Path), e.Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterMinDuration(t *testing.T) {
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skip(tool + " not installed")
+		}
+	}
+	dir := t.TempDir()
+	short := filepath.Join(dir, "short.mkv")
+	long := filepath.Join(dir, "long.mkv")
+	for path, secs := range map[string]string{short: "1", long: "3"} {
+		cmd := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error", "-y",
+			"-f", "lavfi", "-i", "testsrc=duration="+secs+":size=64x64:rate=10", "-c:v", "ffv1", path)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("ffmpeg: %v\n%s", err, output)
+		}
+	}
+
+	kept, excluded := Filter{MinDuration: 2 * time.Second}.Apply(nil, []string{short, long})
+	if !slices.Equal(kept, []string{long}) {
+		t.Errorf("kept = %v, want %v", kept, []string{long})
+	}
+	if len(excluded) != 1 || excluded[0].Path != short || excluded[0].Reason != "shorter than 2s" {
+		t.Errorf("excluded = %v, want %s shorter than 2s", excluded, short)
+	}
+}
@@ -0,0 +1,98 @@
+package discovery
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// DVDSector is the size of a DVD sector, the unit IFO files address VOBs in.
+const DVDSector = 2048
+
+// errBadIFO is returned for a title set IFO that can't be read.
+var errBadIFO = errors.New("not a valid title set IFO")
+
+// SectorRange is a run of DVD sectors, First to Last inclusive, counted
+// from the start of a title set's first title VOB.
+type SectorRange struct {
+	First uint32
+	Last  uint32
+}
+
+// programChain is a program chain of a DVD title set: what one title, or
+// one episode of it, plays.
+type programChain struct {
+	seconds float64
+	cells   []SectorRange // Adjacent cells merged
+}
+
+// Offsets in a title set IFO.
+const (
+	ifoPGCITable   = 0xCC // Sector of the program chain table
+	pgcCellCount   = 0x03
+	pgcPlayTime    = 0x04
+	pgcCellTable   = 0xE8 // Offset of the cell playback table
+	pgcMinLength   = 0xEC
+	cellEntryBytes = 24
+)
+
+// parseTitleSetIFO returns the program chains of a VTS_NN_0.IFO in the
+// order they are listed. Only the first angle of multi-angle blocks is kept.
+func parseTitleSetIFO(data []byte) ([]programChain, error) {
+	if len(data) < 0x100 || string(data[:12]) != "DVDVIDEO-VTS" {
+		return nil, errBadIFO
+	}
+	table := int(binary.BigEndian.Uint32(data[ifoPGCITable:])) * DVDSector
+	if table == 0 || table+8 > len(data) {
+		return nil, errBadIFO
+	}
+	count := int(binary.BigEndian.Uint16(data[table:]))
+
+	chains := make([]programChain, 0, count)
+	for i := range count {
+		entry := table + 8 + i*8
+		if entry+8 > len(data) {
+			return nil, errBadIFO
+		}
+		pgc := table + int(binary.BigEndian.Uint32(data[entry+4:]))
+		if pgc+pgcMinLength > len(data) {
+			return nil, errBadIFO
+		}
+		chain := programChain{seconds: dvdTime(data[pgc+pgcPlayTime : pgc+pgcPlayTime+4])}
+		cellTable := pgc + int(binary.BigEndian.Uint16(data[pgc+pgcCellTable:]))
+		cellCount := int(data[pgc+pgcCellCount])
+		if cellCount > 0 && cellTable+cellCount*cellEntryBytes > len(data) {
+			return nil, errBadIFO
+		}
+		for c := range cellCount {
+			cell := data[cellTable+c*cellEntryBytes:]
+			// Cells after the first of an angle block play the other angles
+			blockMode, blockType := cell[0]>>6, cell[0]>>4&3
+			if blockType == 1 && blockMode > 1 {
+				continue
+			}
+			r := SectorRange{First: binary.BigEndian.Uint32(cell[8:]), Last: binary.BigEndian.Uint32(cell[20:])}
+			if r.Last < r.First {
+				return nil, errBadIFO
+			}
+			if n := len(chain.cells); n > 0 && chain.cells[n-1].Last+1 == r.First {
+				chain.cells[n-1].Last = r.Last
+			} else {
+				chain.cells = append(chain.cells, r)
+			}
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+// dvdTime converts a BCD playback time, hh mm ss ff with the frame rate in
+// the top bits of ff, to seconds.
+func dvdTime(b []byte) float64 {
+	bcd := func(v byte) float64 { return float64(v>>4*10 + v&0x0F) }
+	secs := bcd(b[0])*3600 + bcd(b[1])*60 + bcd(b[2])
+	fps := 30.0
+	if b[3]>>6 == 1 {
+		fps = 25
+	}
+	return secs + bcd(b[3]&0x3F)/fps
+}
@@ -0,0 +1,52 @@
+package discovery
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// mplsClock is the rate of the in and out times of MPLS play items.
+const mplsClock = 45000
+
+// errBadPlaylist is returned for an MPLS file that can't be read.
+var errBadPlaylist = errors.New("not a valid MPLS playlist")
+
+// playItem is a clip a Blu-ray playlist plays, and for how long.
+type playItem struct {
+	clip    string // Clip name, e.g. "00001" for STREAM/00001.m2ts
+	seconds float64
+}
+
+// parsePlaylist returns the play items of an MPLS playlist in playing
+// order. Only the first angle of multi-angle items is kept.
+func parsePlaylist(data []byte) ([]playItem, error) {
+	if len(data) < 20 || string(data[:4]) != "MPLS" {
+		return nil, errBadPlaylist
+	}
+	start := int(binary.BigEndian.Uint32(data[8:12]))
+	if start < 20 || start+10 > len(data) {
+		return nil, errBadPlaylist
+	}
+	count := int(binary.BigEndian.Uint16(data[start+6:]))
+
+	items := make([]playItem, 0, count)
+	pos := start + 10
+	for range count {
+		if pos+2 > len(data) {
+			return nil, errBadPlaylist
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 20 || pos+2+length > len(data) {
+			return nil, errBadPlaylist
+		}
+		item := data[pos+2 : pos+2+length]
+		in := binary.BigEndian.Uint32(item[12:16])
+		out := binary.BigEndian.Uint32(item[16:20])
+		if out < in {
+			return nil, errBadPlaylist
+		}
+		items = append(items, playItem{clip: string(item[:5]), seconds: float64(out-in) / mplsClock})
+		pos += 2 + length
+	}
+	return items, nil
+}
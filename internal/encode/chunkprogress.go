@@ -0,0 +1,97 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval is how often progress is reported while chunks are
+// running, from the frames their encoders have got through so far.
+const progressInterval = time.Second
+
+// chunkProgress tracks the frames encoded so far in each running chunk, so
+// progress moves within long chunks rather than only as they finish.
+type chunkProgress struct {
+	mu     sync.Mutex
+	frames map[int]int
+}
+
+func newChunkProgress() *chunkProgress {
+	return &chunkProgress{frames: make(map[int]int)}
+}
+
+// set records frames encoded in chunk. The count never goes back, so a
+// retry or a duplicate attempt starting over doesn't rewind progress.
+func (p *chunkProgress) set(chunk, frames int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frames[chunk] = max(p.frames[chunk], frames)
+}
+
+// clear forgets chunk once it has finished or failed.
+func (p *chunkProgress) clear(chunk int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.frames, chunk)
+}
+
+// total returns the frames encoded across the running chunks.
+func (p *chunkProgress) total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, frames := range p.frames {
+		n += frames
+	}
+	return n
+}
+
+// progressWriter passes an encoder's stderr through to w, calling onFrames
+// with the frame count of each progress update it writes.
+type progressWriter struct {
+	w        io.Writer
+	onFrames func(frames int)
+	line     []byte
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	for rest := b; len(rest) > 0; {
+		i := bytes.IndexAny(rest, "\r\n")
+		if i < 0 {
+			p.line = append(p.line, rest...)
+			break
+		}
+		p.line = append(p.line, rest[:i]...)
+		if frames, ok := parseSvtProgress(string(p.line)); ok {
+			p.onFrames(frames)
+		}
+		p.line = p.line[:0]
+		rest = rest[i+1:]
+	}
+	return p.w.Write(b)
+}
+
+// parseSvtProgress returns the frames encoded from a line of SvtAv1EncApp
+// --progress 2 output, such as
+// "Encoding:  120/1440 Frames @ 12.31 fps | 2841.06 kb/s | ...". Terminal
+// escapes around the update are skipped.
+func parseSvtProgress(line string) (int, bool) {
+	_, rest, ok := strings.Cut(line, "Encoding:")
+	if !ok {
+		return 0, false
+	}
+	rest = strings.TrimLeft(rest, " ")
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(rest)
+	}
+	frames, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0, false
+	}
+	return frames, true
+}
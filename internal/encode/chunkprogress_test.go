@@ -0,0 +1,71 @@
+package encode
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestParseSvtProgress(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   int
+		wantOK bool
+	}{
+		{"Encoding:  120/1440 Frames @ 12.31 fps | 2841.06 kb/s | Time: 0:00:09 [0:01:47] | Size: 3.21 MB [38.47 MB]", 120, true},
+		{"Encoding: 1440 Frames @ 12.31 fps", 1440, true},
+		{"\x1b[KEncoding:    0/1440 Frames", 0, true},
+		{"Svt[info]: SVT [version]:	SVT-AV1-PSY Encoder Lib v2.3.0", 0, false},
+		{"Encoding: ", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSvtProgress(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseSvtProgress(%q) = %d, %v, want %d, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var log bytes.Buffer
+	var updates []int
+	w := &progressWriter{w: &log, onFrames: func(frames int) { updates = append(updates, frames) }}
+
+	// Progress is redrawn with carriage returns and can arrive split
+	// across writes
+	writes := []string{
+		"Svt[info]: starting\n",
+		"\rEncoding:   10/100 Fr",
+		"ames @ 5.00 fps\rEncoding:   20/100 Frames @ 5.00 fps",
+		"\rEncoding:  100/100 Frames @ 5.00 fps\n",
+		"SUMMARY\n",
+	}
+	var want string
+	for _, s := range writes {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		want += s
+	}
+	if !slices.Equal(updates, []int{10, 20, 100}) {
+		t.Errorf("updates = %v, want [10 20 100]", updates)
+	}
+	if log.String() != want {
+		t.Errorf("log = %q, want %q", log.String(), want)
+	}
+}
+
+func TestChunkProgress(t *testing.T) {
+	p := newChunkProgress()
+	p.set(1, 50)
+	p.set(2, 30)
+	p.set(1, 10) // A retry starting over
+	if got := p.total(); got != 80 {
+		t.Errorf("total() = %d, want 80", got)
+	}
+	p.clear(1)
+	if got := p.total(); got != 30 {
+		t.Errorf("total() after clear = %d, want 30", got)
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/cmdlog"
 	"github.com/five82/reel/internal/encoder"
 	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/svtlib"
+	"github.com/five82/reel/internal/toolpath"
 	"github.com/five82/reel/internal/util"
 	"github.com/five82/reel/internal/worker"
 )
@@ -19,11 +24,79 @@ import (
 type EncodeConfig struct {
 	Workers           int     // Number of parallel encoder workers
 	ChunkBuffer       int     // Extra chunks to buffer in memory
+	DecodeAhead       int     // Frames each worker decodes ahead of its encoder (0 = 1)
 	CRF               float32 // Quality (CRF value)
 	Preset            uint8   // SVT-AV1 preset
 	Tune              uint8   // SVT-AV1 tune
 	GrainTable        *string // Optional film grain table path
 	LogicalProcessors int     // Threads per worker (--lp flag), calculated if 0
+	FixedWorkers      bool    // Use Workers as-is instead of capping by memory
+	MemPerWorker      uint64  // Memory per worker when capping, 0 = estimate by resolution
+	SharedFiles       int     // Files encoding at once, each using a share of the workers (0 = 1)
+
+	KeyintSecs   float64 // Maximum keyframe interval in seconds, 0 = 10
+	AssumeRec601 bool    // Tag untagged SD sources as Rec.601
+	FilmGrain    uint8   // Film grain synthesis level, 0 = off
+	Denoise      bool    // Denoise before film grain synthesis
+	SCM          uint8   // Screen content mode, 0 = off
+	DisableSCD   bool    // No keyframes at scene changes within a chunk
+	TileRows     uint8   // Tile rows, a power of two (0 or 1 = no row tiling)
+	TileColumns  uint8   // Tile columns, a power of two (0 or 1 = no column tiling)
+
+	ExtraParams []string // Additional SvtAv1EncApp arguments
+
+	// Tools are the SvtAv1EncApp the chunks are encoded with and the ffmpeg
+	// the command script decodes with
+	Tools toolpath.Paths
+
+	// NUMA pins workers to NUMA nodes (nil = unpinned); bind is the pinning
+	// command for one worker's encoders
+	NUMA *NUMAPinning
+	bind []string
+
+	// replay feeds encoder commands in the command script (nil = no script)
+	replay *replaySource
+
+	// frames holds the frame buffers shared by the workers
+	frames *framePool
+
+	// running tracks the frames encoded so far in running chunks
+	running *chunkProgress
+
+	// DuplicateStragglers re-encodes slow final chunks on idle workers;
+	// whichever attempt finishes first is kept.
+	DuplicateStragglers bool
+
+	// ChunkRetries retries chunks whose encoder was killed by a signal (for
+	// example by the OOM killer) before failing the encode, waiting longer
+	// each time. RetryFewerThreads halves the threads on each retry.
+	// OnChunkRetry is called before each retry.
+	ChunkRetries      int
+	RetryFewerThreads bool
+	OnChunkRetry      func(retry ChunkRetry)
+
+	// ChunkTimeout is the time allowed per 1080p frame of a chunk, scaled by
+	// the frame size, before its encoder is killed as hung and the chunk
+	// retried (0 = no timeout).
+	ChunkTimeout time.Duration
+
+	// OnChunkComplete is called with the encode time of each chunk encoded
+	// by this run.
+	OnChunkComplete func(timing ChunkTiming)
+
+	// OnWorkerCap is called when memory freed up during a memory-capped
+	// encode lets more workers start.
+	OnWorkerCap func(c WorkerCap)
+
+	// Schedule limits dispatch of new chunks to a daily window; chunks already
+	// running finish normally. OnSchedulePause is called when dispatch pauses.
+	Schedule        *util.Window
+	OnSchedulePause func(resume time.Time)
+
+	// OnPause and OnResume are called when a pause file in the work
+	// directory holds dispatch and when it is removed.
+	OnPause  func(path string)
+	OnResume func()
 
 	// Advanced SVT-AV1 parameters
 	ACBias                float32
@@ -61,12 +134,15 @@ func EncodeAll(
 	if err != nil {
 		return 0, fmt.Errorf("failed to load resume info: %w", err)
 	}
+	resume = resume.Valid(chunks)
 	doneSet := resume.DoneSet()
 
 	// Count remaining chunks
 	remainingChunks := make([]chunk.Chunk, 0, len(chunks))
+	byIdx := make(map[int]chunk.Chunk, len(chunks))
 	totalFrames := 0
 	for _, ch := range chunks {
+		byIdx[ch.Idx] = ch
 		totalFrames += ch.Frames()
 		if !doneSet[ch.Idx] {
 			remainingChunks = append(remainingChunks, ch)
@@ -82,6 +158,11 @@ func EncodeAll(
 	if err != nil {
 		return 0, fmt.Errorf("failed to determine decode strategy: %w", err)
 	}
+	if cmdlog.Scripting() {
+		cfg.replay = newReplaySource(cfg.Tools, idx, inf, cropCalc)
+	}
+	cfg.frames = newFramePool(ffms.CalcFrameSize(inf, cropCalc))
+	cfg.running = newChunkProgress()
 
 	// Calculate effective dimensions
 	width := inf.Width
@@ -92,19 +173,37 @@ func EncodeAll(
 	}
 
 	// Cap workers based on resolution and available memory
-	actualWorkers, _ := CapWorkers(cfg.Workers, width, height)
+	workerCap := WorkerCap{Requested: cfg.Workers, Granted: cfg.Workers}
+	if !cfg.FixedWorkers {
+		workerCap = CapWorkers(cfg.Workers, width, height, cfg.MemPerWorker)
+	}
+	actualWorkers := workerCap.Granted
 
-	// Calculate optimal threads per worker if not explicitly set
+	// Calculate optimal threads per worker if not explicitly set, counting
+	// the workers of other files encoding at the same time
 	if cfg.LogicalProcessors == 0 {
 		cfg.LogicalProcessors = calculateThreadsPerWorker(actualWorkers, width)
 	}
+	actualWorkers = ShareWorkers(actualWorkers, cfg.SharedFiles)
+	maxWorkers := ShareWorkers(workerCap.Requested, cfg.SharedFiles)
+	if !cfg.FixedWorkers {
+		actualWorkers = StartWorkers(workerCap, actualWorkers)
+	}
 
-	// Calculate permits for actual worker count
+	// Calculate permits for actual worker count, leaving room to grow if
+	// memory allows
 	permits := CalculatePermits(actualWorkers, cfg.ChunkBuffer)
-	sem := worker.NewSemaphore(permits)
+	sem := worker.NewGrowableSemaphore(permits, CalculatePermits(maxWorkers, cfg.ChunkBuffer))
 
 	// Chunk channel - workers receive chunk metadata (not decoded frames)
-	chunkChan := make(chan chunk.Chunk, permits)
+	chunkChan := make(chan job, permits)
+
+	// Dispatch order: most expensive chunks first
+	queue := newCostQueue(remainingChunks, width, height)
+
+	// Tracks attempts so duplicated stragglers resolve to a single result
+	tracker := newInflightTracker(workDir)
+	var busyWorkers atomic.Int32
 
 	// Results channel
 	resultChan := make(chan worker.EncodeResult, len(remainingChunks))
@@ -131,14 +230,104 @@ func EncodeAll(
 		return nil
 	}
 
-	// Start streaming workers - each creates its own VidSrc for thread safety
+	// Start streaming workers - each creates its own VidSrc for thread safety.
+	// A token on retire makes one worker exit before its next chunk.
 	var workerWg sync.WaitGroup
-	for i := 0; i < actualWorkers; i++ {
-		workerWg.Add(1)
-		go func() {
-			defer workerWg.Done()
-			streamingWorker(ctx, idx, chunkChan, resultChan, sem, cfg, inf, strat, cropCalc, workDir, width, height, setError, getError)
-		}()
+	var runningWorkers, peakWorkers atomic.Int32
+	retire := make(chan struct{}, maxWorkers)
+	startWorkers := func(n int) {
+		for range n {
+			peakWorkers.Store(max(peakWorkers.Load(), runningWorkers.Add(1)))
+			workerWg.Go(func() {
+				defer runningWorkers.Add(-1)
+				streamingWorker(ctx, idx, chunkChan, retire, resultChan, sem, tracker, &busyWorkers, cfg, inf, strat, cropCalc, workDir, width, height, setError, getError)
+			})
+		}
+	}
+	startWorkers(actualWorkers)
+
+	// Scale memory-capped workers as the encode runs, measuring the memory
+	// and throughput of those running. Counted with the workers so they
+	// aren't all seen as finished meanwhile.
+	dispatched := make(chan struct{})
+	if !cfg.FixedWorkers && workerCap.Available > 0 {
+		scaleCap := workerCap
+		scaleCap.Requested = maxWorkers
+		scaler := newWorkerScaler(scaleCap, cfg.SharedFiles, actualWorkers)
+		workerWg.Go(func() {
+			ticker := time.NewTicker(MemoryRecheckInterval)
+			defer ticker.Stop()
+			last := time.Now()
+			progressMu.Lock()
+			lastChunks, lastFrames := progress.ChunksComplete, progress.FramesComplete
+			progressMu.Unlock()
+			for {
+				select {
+				case <-ticker.C:
+				case <-dispatched:
+					return
+				case <-ctx.Done():
+					return
+				}
+				progressMu.Lock()
+				chunks, frames := progress.ChunksComplete, progress.FramesComplete
+				progressMu.Unlock()
+				now := time.Now()
+				scaler.record(chunks-lastChunks, frames-lastFrames, now.Sub(last).Seconds())
+				last, lastChunks, lastFrames = now, chunks, frames
+
+				before := scaler.workers
+				decision := scaler.next(util.AvailableMemoryBytes())
+				switch change := decision.Granted - before; {
+				case change > 0:
+					sem.Grow(change)
+					startWorkers(change)
+				case change < 0:
+					sem.Shrink(-change)
+					for range -change {
+						retire <- struct{}{}
+					}
+				default:
+					continue
+				}
+				if cfg.OnWorkerCap != nil {
+					cfg.OnWorkerCap(decision)
+				}
+			}
+		})
+	}
+
+	// Progress is reported as chunks finish, and every progressInterval
+	// in between from the frames running chunks have encoded
+	var reportMu sync.Mutex
+	reportProgress := func() {
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		progressMu.Lock()
+		p := progress
+		p.FramesEncoding = cfg.running.total()
+		progressMu.Unlock()
+		progressCb(p)
+	}
+	var tickerWg sync.WaitGroup
+	collected := make(chan struct{})
+	if progressCb != nil {
+		tickerWg.Go(func() {
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			reported := 0
+			for {
+				select {
+				case <-ticker.C:
+				case <-collected:
+					return
+				}
+				if frames := cfg.running.total(); frames != reported {
+					reported = frames
+					reportProgress()
+				}
+			}
+		})
 	}
 
 	// Start result collector
@@ -148,17 +337,32 @@ func EncodeAll(
 		defer collectorWg.Done()
 		for result := range resultChan {
 			if result.Error != nil {
-				setError(result.Error)
+				cfg.running.clear(result.ChunkIdx)
+				setError(&ChunkEncodeError{Chunk: result.ChunkIdx, Err: result.Error})
 				continue
 			}
 
-			// Update progress
+			// Update progress, moving the chunk's frames from running to
+			// complete together
 			progressMu.Lock()
+			cfg.running.clear(result.ChunkIdx)
 			progress.ChunksComplete++
 			progress.FramesComplete += result.Frames
 			progress.BytesComplete += result.Size
 			progressMu.Unlock()
 
+			queue.observe(byIdx[result.ChunkIdx], result.Elapsed)
+			if cfg.OnChunkComplete != nil {
+				cfg.OnChunkComplete(ChunkTiming{
+					Chunk:       byIdx[result.ChunkIdx],
+					Elapsed:     result.Elapsed,
+					Decode:      result.Decode,
+					Pipe:        result.Pipe,
+					DecodeStall: result.DecodeStall,
+					EncodeStall: result.EncodeStall,
+				})
+			}
+
 			// Append to done file (ignore errors, resume will handle incomplete state)
 			_ = chunk.AppendDone(chunk.ChunkComp{
 				Idx:    result.ChunkIdx,
@@ -168,19 +372,24 @@ func EncodeAll(
 
 			// Report progress
 			if progressCb != nil {
-				progressMu.Lock()
-				p := progress
-				progressMu.Unlock()
-				progressCb(p)
+				reportProgress()
 			}
 		}
 	}()
 
+	pausePath := PausePath(workDir)
+	onPause := func() {
+		if cfg.OnPause != nil {
+			cfg.OnPause(pausePath)
+		}
+	}
+
 	// Chunk dispatcher goroutine
 	go func() {
+		defer close(dispatched)
 		defer close(chunkChan)
 
-		for _, ch := range remainingChunks {
+		for queue.remaining() > 0 {
 			// Check for cancellation
 			select {
 			case <-ctx.Done():
@@ -193,6 +402,18 @@ func EncodeAll(
 				return
 			}
 
+			// Hold new chunks until the schedule window opens
+			if cfg.Schedule != nil {
+				if err := cfg.Schedule.Wait(ctx, cfg.OnSchedulePause); err != nil {
+					return
+				}
+			}
+
+			// Hold new chunks while the pause file exists
+			if err := waitWhilePaused(ctx, pausePath, pausePollInterval, onPause, cfg.OnResume); err != nil {
+				return
+			}
+
 			// Acquire semaphore with context cancellation support
 			select {
 			case <-sem.Chan():
@@ -201,9 +422,20 @@ func EncodeAll(
 				return
 			}
 
+			// A pause file created while waiting for the permit holds this
+			// chunk too
+			if isPaused(pausePath) {
+				sem.Release()
+				continue
+			}
+
+			// Pick the chunk once a worker can take it, with the timings
+			// of the chunks finished by then
+			ch, _ := queue.next()
+
 			// Send chunk metadata to worker
 			select {
-			case chunkChan <- ch:
+			case chunkChan <- job{ch: ch}:
 				// Successfully sent
 			case <-ctx.Done():
 				// Context cancelled while waiting to send
@@ -211,6 +443,32 @@ func EncodeAll(
 				return
 			}
 		}
+
+		if !cfg.DuplicateStragglers {
+			return
+		}
+
+		// All chunks are dispatched. Until they finish, hand stragglers to
+		// workers that have gone idle.
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for !tracker.allDone(len(remainingChunks)) && getError() == nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			if busyWorkers.Load() >= runningWorkers.Load() || len(chunkChan) > 0 || isPaused(pausePath) {
+				continue
+			}
+			if ch, ok := tracker.pickStraggler(time.Now()); ok {
+				select {
+				case chunkChan <- job{ch: ch, duplicate: true}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
 	}()
 
 	// Wait for workers to finish
@@ -219,8 +477,18 @@ func EncodeAll(
 
 	// Wait for result collector
 	collectorWg.Wait()
+	close(collected)
+	tickerWg.Wait()
 
-	return actualWorkers, getError()
+	actualWorkers = int(peakWorkers.Load())
+	if err := getError(); err != nil {
+		return actualWorkers, err
+	}
+	// Dispatch stopped early; the chunks that finished are recorded for resuming
+	if ctx.Err() != nil && progress.ChunksComplete < progress.ChunksTotal {
+		return actualWorkers, ctx.Err()
+	}
+	return actualWorkers, nil
 }
 
 // streamingWorker runs in a goroutine and processes chunks using streaming decode/encode.
@@ -228,9 +496,12 @@ func EncodeAll(
 func streamingWorker(
 	ctx context.Context,
 	idx *ffms.VidIdx,
-	chunkChan <-chan chunk.Chunk,
+	chunkChan <-chan job,
+	retire <-chan struct{},
 	resultChan chan<- worker.EncodeResult,
 	sem *worker.Semaphore,
+	tracker *inflightTracker,
+	busyWorkers *atomic.Int32,
 	cfg *EncodeConfig,
 	inf *ffms.VidInf,
 	strat ffms.DecodeStrat,
@@ -240,25 +511,63 @@ func streamingWorker(
 	setError func(error),
 	getError func() error,
 ) {
+	// Pin the decode thread, and the encoders started from it, to a NUMA
+	// node before the video source allocates. The thread is still locked
+	// when the worker returns, so it exits rather than rejoining the pool.
+	if cfg.NUMA != nil {
+		node := cfg.NUMA.nextNode()
+		runtime.LockOSThread()
+		_ = util.PinThread(node.CPUs)
+		pinned := *cfg
+		pinned.bind = cfg.NUMA.bind(node)
+		cfg = &pinned
+	}
+
 	// Create per-worker video source (single-threaded, thread-safe)
 	src, err := ffms.ThrVidSrc(idx, 1)
 	if err != nil {
 		setError(fmt.Errorf("failed to create video source for worker: %w", err))
 		// Drain chunks and release permits
-		for range chunkChan {
-			sem.Release()
+		for j := range chunkChan {
+			if !j.duplicate {
+				sem.Release()
+			}
 		}
 		return
 	}
 	defer src.Close()
 
-	for ch := range chunkChan {
+	for {
+		// Exit before the next chunk when retired to free memory
+		var j job
+		select {
+		case <-retire:
+			return
+		default:
+		}
+		select {
+		case <-retire:
+			return
+		case next, ok := <-chunkChan:
+			if !ok {
+				return
+			}
+			j = next
+		}
+
+		// Duplicates were dispatched without taking a permit
+		release := func() {
+			if !j.duplicate {
+				sem.Release()
+			}
+		}
+
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			sem.Release()
+			release()
 			resultChan <- worker.EncodeResult{
-				ChunkIdx: ch.Idx,
+				ChunkIdx: j.ch.Idx,
 				Error:    ctx.Err(),
 			}
 			continue
@@ -267,28 +576,35 @@ func streamingWorker(
 
 		// Check for error from other workers
 		if getError() != nil {
-			sem.Release()
+			release()
 			continue
 		}
 
 		// Encode the chunk using streaming (decode one frame, encode, repeat)
-		result := encodeChunkStreaming(ctx, src, ch, inf, strat, cropCalc, cfg, workDir, width, height)
+		busyWorkers.Add(1)
+		attemptCtx := tracker.start(ChunkContext(ctx), j)
+		result := encodeChunkWithRetries(attemptCtx, src, j, inf, strat, cropCalc, cfg, workDir, width, height)
+		result, report := tracker.finish(j, result)
+		busyWorkers.Add(-1)
 
 		// Release semaphore
-		sem.Release()
+		release()
 
-		// Send result
-		resultChan <- result
+		// Send result (losing attempts at a duplicated chunk are dropped)
+		if report {
+			resultChan <- result
+		}
 	}
 }
 
-// encodeChunkStreaming decodes and encodes frames one at a time, reusing a single frame buffer.
-// This dramatically reduces memory usage compared to decoding all frames upfront.
-// Memory per worker: ~6 MB (single frame) instead of ~5 GB (all frames in chunk).
-func encodeChunkStreaming(
+// encodeChunkWithRetries encodes a chunk, retrying transient encoder failures
+// up to cfg.ChunkRetries times with a growing delay between attempts. Each
+// attempt is cancelled, killing its encoder, if it outlasts the chunk's
+// timeout.
+func encodeChunkWithRetries(
 	ctx context.Context,
 	src *ffms.VidSrc,
-	ch chunk.Chunk,
+	j job,
 	inf *ffms.VidInf,
 	strat ffms.DecodeStrat,
 	cropCalc *ffms.CropCalc,
@@ -296,15 +612,52 @@ func encodeChunkStreaming(
 	workDir string,
 	width, height uint32,
 ) worker.EncodeResult {
-	frameCount := ch.Frames()
-	frameSize := ffms.CalcFrameSize(inf, cropCalc)
+	encodeChunk := encodeChunkStreaming
+	if svtlib.Linked {
+		encodeChunk = encodeChunkInProcess
+	}
 
-	// Single frame buffer, reused for each frame (~6 MB for 1080p 10-bit)
-	frameBuf := make([]byte, frameSize)
+	timeout := chunkTimeout(cfg.ChunkTimeout, j.ch.Frames(), width, height)
+	attemptCfg := cfg
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeoutCause(ctx, timeout, &ChunkTimeoutError{Timeout: timeout})
+		}
+		result := encodeChunk(attemptCtx, src, j.ch, inf, strat, cropCalc, attemptCfg, j.outputPath(workDir), width, height)
+		if result.Error != nil && ctx.Err() == nil && attemptCtx.Err() != nil {
+			result.Error = context.Cause(attemptCtx)
+		}
+		cancel()
+		if result.Error == nil || attempt > cfg.ChunkRetries || ctx.Err() != nil || !isTransientFailure(result.Error) {
+			return result
+		}
 
-	outputPath := chunk.IVFPath(workDir, ch.Idx)
+		retryCfg := *cfg
+		retryCfg.LogicalProcessors = retryThreads(cfg.LogicalProcessors, attempt, cfg.RetryFewerThreads)
+		attemptCfg = &retryCfg
+
+		delay := retryDelay(attempt)
+		if cfg.OnChunkRetry != nil {
+			cfg.OnChunkRetry(ChunkRetry{
+				Chunk:   j.ch.Idx,
+				Attempt: attempt,
+				Retries: cfg.ChunkRetries,
+				Threads: retryCfg.LogicalProcessors,
+				Delay:   delay,
+				Err:     result.Error,
+			})
+		}
+		if !sleepCtx(ctx, delay) {
+			return worker.EncodeResult{ChunkIdx: j.ch.Idx, Error: ctx.Err()}
+		}
+	}
+}
 
-	encCfg := &encoder.EncConfig{
+// chunkEncConfig returns the encoder configuration for a chunk of the given
+// number of frames, written to outputPath.
+func chunkEncConfig(cfg *EncodeConfig, inf *ffms.VidInf, outputPath string, width, height uint32, frames int) *encoder.EncConfig {
+	return &encoder.EncConfig{
 		Inf:                   inf,
 		CRF:                   cfg.CRF,
 		Preset:                cfg.Preset,
@@ -313,68 +666,141 @@ func encodeChunkStreaming(
 		GrainTable:            cfg.GrainTable,
 		Width:                 width,
 		Height:                height,
-		Frames:                frameCount,
+		Frames:                frames,
 		ACBias:                cfg.ACBias,
 		EnableVarianceBoost:   cfg.EnableVarianceBoost,
 		VarianceBoostStrength: cfg.VarianceBoostStrength,
 		VarianceOctile:        cfg.VarianceOctile,
 		LogicalProcessors:     cfg.LogicalProcessors,
+		KeyintSecs:            cfg.KeyintSecs,
+		AssumeRec601:          cfg.AssumeRec601,
+		FilmGrain:             cfg.FilmGrain,
+		Denoise:               cfg.Denoise,
+		SCM:                   cfg.SCM,
+		DisableSCD:            cfg.DisableSCD,
+		TileRows:              cfg.TileRows,
+		TileColumns:           cfg.TileColumns,
+		ExtraParams:           cfg.ExtraParams,
+		Bind:                  cfg.bind,
+		Svt:                   cfg.Tools.Path(toolpath.SvtAv1EncApp),
 	}
+}
 
-	cmd := encoder.MakeSvtCmd(encCfg)
+// encodeChunkStreaming decodes and encodes frames one at a time, with frame buffers from the
+// shared pool. This dramatically reduces memory usage compared to decoding all frames upfront.
+// Memory per worker: a few frames (~6 MB each for 1080p 10-bit) instead of ~5 GB (all frames
+// in chunk).
+func encodeChunkStreaming(
+	ctx context.Context,
+	src *ffms.VidSrc,
+	ch chunk.Chunk,
+	inf *ffms.VidInf,
+	strat ffms.DecodeStrat,
+	cropCalc *ffms.CropCalc,
+	cfg *EncodeConfig,
+	outputPath string,
+	width, height uint32,
+) worker.EncodeResult {
+	start := time.Now()
+	frameCount := ch.Frames()
+
+	encCfg := chunkEncConfig(cfg, inf, outputPath, width, height, frameCount)
+
+	cmd := encoder.MakeSvtCmd(ctx, encCfg)
+
+	// Capture stderr so a failure can say why the encoder exited
+	logPath := stderrLogPath(outputPath)
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return worker.EncodeResult{
+			ChunkIdx: ch.Idx,
+			Error:    fmt.Errorf("failed to create encoder log: %w", err),
+		}
+	}
+	defer func() { _ = logFile.Close() }()
+	cmd.Stderr = &progressWriter{w: logFile, onFrames: func(frames int) {
+		cfg.running.set(ch.Idx, min(frames, frameCount))
+	}}
 
-	// Setup stdin pipe
-	stdin, err := cmd.StdinPipe()
+	// Setup stdin pipe, large enough to take a frame per write
+	stdinR, stdin, err := os.Pipe()
 	if err != nil {
 		return worker.EncodeResult{
 			ChunkIdx: ch.Idx,
 			Error:    fmt.Errorf("failed to create stdin pipe: %w", err),
 		}
 	}
+	defer func() { _ = stdinR.Close() }()
+	util.GrowPipe(stdin, ffms.CalcFrameSize(inf, cropCalc))
+	cmd.Stdin = stdinR
 
 	// Start encoder
-	if err := cmd.Start(); err != nil {
+	var input []string
+	if cfg.replay != nil {
+		input = cfg.replay.input(ch)
+	}
+	proc, err := cmdlog.Start(cmd, input)
+	if err != nil {
+		_ = stdin.Close()
 		return worker.EncodeResult{
 			ChunkIdx: ch.Idx,
 			Error:    fmt.Errorf("failed to start encoder: %w", err),
 		}
 	}
+	_ = stdinR.Close() // The encoder holds its own copy
 
-	// Stream frames one at a time: decode -> write to encoder -> repeat
-	var writeErr error
+	// Stream frames: decode -> queue for the encoder -> repeat, with
+	// decoding up to cfg.DecodeAhead frames ahead of the encoder
+	pipe := newFramePipe(stdin, cfg.frames, cfg.DecodeAhead)
+	var decode time.Duration
 	for i := 0; i < frameCount; i++ {
-		// Check for cancellation
+		// Check for cancellation; the encoder has been killed, so drop the
+		// queued frames rather than wait for them to be written
 		if ctx.Err() != nil {
-			_ = stdin.Close()
-			_ = cmd.Wait()
+			pipe.abort()
+			_ = proc.Wait()
 			return worker.EncodeResult{
 				ChunkIdx: ch.Idx,
 				Error:    ctx.Err(),
 			}
 		}
 
-		// Decode frame into reusable buffer
+		// Decode frame into a pooled buffer
 		frameIdx := ch.Start + i
-		if err := ffms.ExtractFrame(src, frameIdx, frameBuf, inf, strat, cropCalc); err != nil {
-			_ = stdin.Close()
-			_ = cmd.Wait()
+		frameBuf := cfg.frames.get()
+		decodeStart := time.Now()
+		if err := ffms.ExtractFrame(src, frameIdx, *frameBuf, inf, strat, cropCalc); err != nil {
+			cfg.frames.put(frameBuf)
+			_, _ = pipe.close()
+			_ = proc.Wait()
 			return worker.EncodeResult{
 				ChunkIdx: ch.Idx,
 				Error:    fmt.Errorf("failed to extract frame %d: %w", frameIdx, err),
 			}
 		}
+		decode += time.Since(decodeStart)
 
-		// Write frame to encoder stdin
-		_, writeErr = stdin.Write(frameBuf)
-		if writeErr != nil {
+		// Queue frame for encoder stdin
+		if pipe.send(frameBuf) != nil {
 			break
 		}
 	}
 
-	_ = stdin.Close()
+	stats, writeErr := pipe.close()
 
+	// A cancelled encoder was killed, which is no failure of its own
+	if ctx.Err() != nil {
+		_ = proc.Wait()
+		return worker.EncodeResult{ChunkIdx: ch.Idx, Error: ctx.Err()}
+	}
 	if writeErr != nil {
-		_ = cmd.Wait()
+		// A write fails when the encoder has died; report why it died
+		if err := proc.Wait(); err != nil {
+			return worker.EncodeResult{
+				ChunkIdx: ch.Idx,
+				Error:    encoderError(err, logPath),
+			}
+		}
 		return worker.EncodeResult{
 			ChunkIdx: ch.Idx,
 			Error:    fmt.Errorf("failed to write frame data: %w", writeErr),
@@ -382,13 +808,19 @@ func encodeChunkStreaming(
 	}
 
 	// Wait for encoder to finish
-	if err := cmd.Wait(); err != nil {
+	if err := proc.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return worker.EncodeResult{ChunkIdx: ch.Idx, Error: ctx.Err()}
+		}
 		return worker.EncodeResult{
 			ChunkIdx: ch.Idx,
-			Error:    fmt.Errorf("encoder failed: %w", err),
+			Error:    encoderError(err, logPath),
 		}
 	}
 
+	_ = logFile.Close()
+	_ = os.Remove(logPath)
+
 	// Get output file size
 	stat, err := os.Stat(outputPath)
 	if err != nil {
@@ -399,9 +831,14 @@ func encodeChunkStreaming(
 	}
 
 	return worker.EncodeResult{
-		ChunkIdx: ch.Idx,
-		Frames:   frameCount,
-		Size:     uint64(stat.Size()),
+		ChunkIdx:    ch.Idx,
+		Frames:      frameCount,
+		Size:        uint64(stat.Size()),
+		Elapsed:     time.Since(start),
+		Decode:      decode,
+		Pipe:        stats.Write,
+		DecodeStall: stats.DecodeStall,
+		EncodeStall: stats.EncodeStall,
 	}
 }
 
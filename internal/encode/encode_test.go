@@ -137,3 +137,110 @@ func TestCalculateThreadsPerWorkerReturnsPositive(t *testing.T) {
 		}
 	}
 }
+
+func TestShareWorkers(t *testing.T) {
+	tests := []struct {
+		workers, files, want int
+	}{
+		{8, 0, 8},
+		{8, 1, 8},
+		{8, 2, 4},
+		{7, 2, 3},
+		{2, 4, 1},
+	}
+	for _, tt := range tests {
+		if got := ShareWorkers(tt.workers, tt.files); got != tt.want {
+			t.Errorf("ShareWorkers(%d, %d) = %d, want %d", tt.workers, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestCapWorkers(t *testing.T) {
+	const perWorker = 2 << 30
+	tests := []struct {
+		name       string
+		requested  int
+		available  uint64
+		wantGrant  int
+		wantCapped bool
+	}{
+		{"unknown memory", 8, 0, 8, false},
+		{"all fit", 4, 20 << 30, 4, false},
+		{"capped", 8, 10 << 30, 3, true}, // 70% of 10 GiB fits 3 workers
+		{"at least one", 8, 1 << 30, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capWorkers(tt.requested, perWorker, tt.available)
+			if got.Granted != tt.wantGrant || got.Capped() != tt.wantCapped {
+				t.Errorf("capWorkers() = %+v, want %d granted (capped %v)", got, tt.wantGrant, tt.wantCapped)
+			}
+		})
+	}
+}
+
+func TestStartWorkers(t *testing.T) {
+	tests := []struct {
+		name      string
+		available uint64
+		granted   int
+		want      int
+	}{
+		{"unknown memory", 0, 8, 8},
+		{"half", 40 << 30, 8, 4},
+		{"rounds up", 40 << 30, 5, 3},
+		{"at least one", 40 << 30, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := WorkerCap{Requested: 8, Granted: tt.granted, Available: tt.available}
+			if got := StartWorkers(c, tt.granted); got != tt.want {
+				t.Errorf("StartWorkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerScaler(t *testing.T) {
+	const gib = 1 << 30
+	start := WorkerCap{Requested: 8, Granted: 8, MemPerWorker: 2 * gib, Available: 40 * gib}
+	tests := []struct {
+		name      string
+		files     int
+		measure   bool // Record a chunk per worker before deciding
+		available uint64
+		want      int
+	}{
+		{"unknown memory", 1, true, 0, 4},
+		{"not yet measured", 1, false, 30 * gib, 4},
+		{"grows by half", 1, true, 30 * gib, 6},
+		{"shared with another file", 2, true, 20 * gib, 5},
+		{"no room", 1, true, 10 * gib, 4},
+		{"memory pressure", 1, true, 1 * gib, 3},
+		{"pressure before measuring", 1, false, 1 << 20, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newWorkerScaler(start, tt.files, 4)
+			if tt.measure {
+				s.record(4, 400, 10)
+			}
+			if got := s.next(tt.available); got.Granted != tt.want {
+				t.Errorf("next() granted %d, want %d", got.Granted, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerScalerCeiling(t *testing.T) {
+	const gib = 1 << 30
+	s := newWorkerScaler(WorkerCap{Requested: 16, MemPerWorker: 1 * gib, Available: 64 * gib}, 1, 4)
+	s.record(4, 400, 10) // 40 fps
+	if got := s.next(56 * gib).Granted; got != 6 {
+		t.Fatalf("next() granted %d, want 6", got)
+	}
+	s.record(6, 410, 10) // 41 fps: too little gain to keep growing
+	if got := s.next(52 * gib).Granted; got != 6 {
+		t.Errorf("next() granted %d after throughput levelled off, want 6", got)
+	}
+}
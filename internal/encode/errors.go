@@ -0,0 +1,30 @@
+package encode
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChunkEncodeError reports a failure encoding one chunk.
+type ChunkEncodeError struct {
+	Chunk int // Chunk index
+	Err   error
+}
+
+func (e *ChunkEncodeError) Error() string {
+	return fmt.Sprintf("chunk %d: %v", e.Chunk, e.Err)
+}
+
+func (e *ChunkEncodeError) Unwrap() error {
+	return e.Err
+}
+
+// ChunkTimeoutError reports a chunk attempt whose encoder was killed for
+// running past the chunk's timeout.
+type ChunkTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *ChunkTimeoutError) Error() string {
+	return fmt.Sprintf("encoder hung: killed after %s", e.Timeout)
+}
@@ -0,0 +1,128 @@
+package encode
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// framePool recycles frame buffers across workers and chunks, so a chunk
+// doesn't allocate its frames afresh; for SD content at high frame rates a
+// worker may run through a chunk every few seconds.
+type framePool struct {
+	pool sync.Pool
+}
+
+// newFramePool returns a pool of size-byte frame buffers.
+func newFramePool(size int) *framePool {
+	return &framePool{pool: sync.Pool{New: func() any {
+		buf := make([]byte, size)
+		return &buf
+	}}}
+}
+
+func (p *framePool) get() *[]byte {
+	return p.pool.Get().(*[]byte)
+}
+
+func (p *framePool) put(buf *[]byte) {
+	p.pool.Put(buf)
+}
+
+// framePipe writes frames to an encoder's stdin from its own goroutine,
+// with a ring of up to depth decoded frames between the two, so decoding
+// stays ahead of the encoder and neither stalls the other on every frame.
+// Frames are written whole, straight from the pool buffer they were decoded
+// into, and returned to the pool once written. A worker holds at most
+// depth+2 frames: one decoding, depth queued and one being written.
+type framePipe struct {
+	w     *os.File
+	pool  *framePool
+	queue chan *[]byte
+	done  chan struct{}
+
+	mu  sync.Mutex
+	err error // First write error
+
+	// Read once done is closed
+	stats pipeStats
+}
+
+// pipeStats is where the time went on each side of a framePipe.
+type pipeStats struct {
+	Write       time.Duration // Writer blocked writing frames to the encoder
+	DecodeStall time.Duration // Decoder blocked with the ring full, waiting on the encoder
+	EncodeStall time.Duration // Writer idle with the ring empty, waiting on the decoder
+}
+
+// newFramePipe starts writing the frames sent to it to w, queueing up to
+// depth of them (at least one).
+func newFramePipe(w *os.File, pool *framePool, depth int) *framePipe {
+	p := &framePipe{w: w, pool: pool, queue: make(chan *[]byte, max(depth, 1)), done: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+func (p *framePipe) run() {
+	defer close(p.done)
+	for {
+		idle := time.Now()
+		buf, ok := <-p.queue
+		if !ok {
+			return
+		}
+		p.stats.EncodeStall += time.Since(idle)
+		if p.failed() == nil {
+			start := time.Now()
+			_, err := p.w.Write(*buf)
+			p.stats.Write += time.Since(start)
+			if err != nil {
+				p.mu.Lock()
+				p.err = err
+				p.mu.Unlock()
+			}
+		}
+		p.pool.put(buf)
+	}
+}
+
+// failed returns the write error, if a write has failed.
+func (p *framePipe) failed() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// send queues a frame from the pool for writing. It fails once a write
+// has failed, which means the encoder has exited.
+func (p *framePipe) send(buf *[]byte) error {
+	if err := p.failed(); err != nil {
+		p.pool.put(buf)
+		return err
+	}
+	select {
+	case p.queue <- buf:
+	default:
+		start := time.Now()
+		p.queue <- buf
+		p.stats.DecodeStall += time.Since(start)
+	}
+	return nil
+}
+
+// abort stops writing at once: a write blocked on the encoder is
+// interrupted by closing w, and the queued frames are dropped.
+func (p *framePipe) abort() {
+	_ = p.w.Close()
+	close(p.queue)
+	<-p.done
+}
+
+// close waits for the queued frames to be written and closes w, returning
+// where the time went and the first write error.
+func (p *framePipe) close() (pipeStats, error) {
+	close(p.queue)
+	<-p.done
+	_ = p.w.Close()
+	return p.stats, p.err
+}
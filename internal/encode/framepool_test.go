@@ -0,0 +1,147 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFramePipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+
+	const size, frames = 1000, 20
+	pool := newFramePool(size)
+	pipe := newFramePipe(w, pool, 2)
+
+	got := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		got <- data
+	}()
+
+	var want []byte
+	for i := range frames {
+		buf := pool.get()
+		if len(*buf) != size {
+			t.Fatalf("pooled frame is %d bytes, want %d", len(*buf), size)
+		}
+		for j := range *buf {
+			(*buf)[j] = byte(i)
+		}
+		want = append(want, *buf...)
+		if err := pipe.send(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pipe.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if data := <-got; !bytes.Equal(data, want) {
+		t.Errorf("read %d bytes, want the %d written in order", len(data), len(want))
+	}
+}
+
+func TestFramePipeReaderGone(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = r.Close() // The encoder has exited
+
+	pool := newFramePool(100)
+	pipe := newFramePipe(w, pool, 2)
+	for range 10 {
+		if pipe.send(pool.get()) != nil {
+			break // Sends fail once a write has
+		}
+	}
+	if _, err := pipe.close(); err == nil {
+		t.Error("close() error = nil, want the write error")
+	}
+}
+
+func TestFramePipeAbort(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }() // Open but never read: writes block
+
+	pool := newFramePool(256 << 10) // Larger than the pipe buffer
+	pipe := newFramePipe(w, pool, 2)
+	for range 3 {
+		if err := pipe.send(pool.get()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pipe.abort()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("abort() did not interrupt a blocked write")
+	}
+}
+
+func TestFramePipeStalls(t *testing.T) {
+	tests := []struct {
+		name        string
+		slowDecode  bool
+		wantDecoder bool // Decoder waited on the encoder, rather than the reverse
+	}{
+		{"slow encoder", false, true},
+		{"slow decoder", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = r.Close() }()
+
+			const size = 256 << 10 // More than the pipe holds
+			go func() {
+				buf := make([]byte, size)
+				for {
+					if !tt.slowDecode {
+						time.Sleep(2 * time.Millisecond)
+					}
+					if _, err := io.ReadFull(r, buf); err != nil {
+						return
+					}
+				}
+			}()
+
+			pool := newFramePool(size)
+			pipe := newFramePipe(w, pool, 1)
+			for range 10 {
+				if tt.slowDecode {
+					time.Sleep(2 * time.Millisecond)
+				}
+				if err := pipe.send(pool.get()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stats, err := pipe.close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoder := stats.DecodeStall > stats.EncodeStall; decoder != tt.wantDecoder {
+				t.Errorf("DecodeStall %v, EncodeStall %v; want the %s waiting", stats.DecodeStall, stats.EncodeStall,
+					map[bool]string{true: "decoder", false: "encoder"}[tt.wantDecoder])
+			}
+		})
+	}
+}
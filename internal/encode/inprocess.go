@@ -0,0 +1,110 @@
+package encode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/encoder"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/svtlib"
+	"github.com/five82/reel/internal/worker"
+)
+
+// encodeChunkInProcess encodes a chunk like encodeChunkStreaming, but with
+// libSvtAv1Enc linked in (the svtlib build tag) instead of an SvtAv1EncApp
+// process: frames are handed to the encoder without a pipe, and its packets
+// are written to the chunk's IVF here.
+func encodeChunkInProcess(
+	ctx context.Context,
+	src *ffms.VidSrc,
+	ch chunk.Chunk,
+	inf *ffms.VidInf,
+	strat ffms.DecodeStrat,
+	cropCalc *ffms.CropCalc,
+	cfg *EncodeConfig,
+	outputPath string,
+	width, height uint32,
+) worker.EncodeResult {
+	start := time.Now()
+	fail := func(err error) worker.EncodeResult {
+		return worker.EncodeResult{ChunkIdx: ch.Idx, Error: err}
+	}
+
+	bitDepth := 10
+	if inf.Output8Bit {
+		bitDepth = 8
+	}
+	enc, err := svtlib.Open(svtlib.Config{
+		Width:    width,
+		Height:   height,
+		FPSNum:   inf.FPSNum,
+		FPSDen:   inf.FPSDen,
+		BitDepth: bitDepth,
+		Params:   encoder.LibParams(chunkEncConfig(cfg, inf, outputPath, width, height, ch.Frames())),
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to start encoder: %w", err))
+	}
+	defer enc.Close()
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fail(fmt.Errorf("failed to create output: %w", err))
+	}
+	defer func() { _ = f.Close() }()
+	ivf, err := chunk.NewIVFWriter(f, width, height, inf.FPSNum, inf.FPSDen)
+	if err != nil {
+		return fail(fmt.Errorf("failed to write output: %w", err))
+	}
+
+	// Each packet written is a frame encoded
+	encoded := 0
+	write := func(packet []byte, pts int64) error {
+		encoded++
+		cfg.running.set(ch.Idx, encoded)
+		return ivf.WriteFrame(packet, pts)
+	}
+
+	// The encoder copies each frame, so one pooled buffer serves the chunk
+	frameBuf := cfg.frames.get()
+	defer cfg.frames.put(frameBuf)
+	var decode time.Duration
+	for i := range ch.Frames() {
+		if ctx.Err() != nil {
+			return fail(ctx.Err())
+		}
+		frameIdx := ch.Start + i
+		decodeStart := time.Now()
+		if err := ffms.ExtractFrame(src, frameIdx, *frameBuf, inf, strat, cropCalc); err != nil {
+			return fail(fmt.Errorf("failed to extract frame %d: %w", frameIdx, err))
+		}
+		decode += time.Since(decodeStart)
+		if err := enc.Send(*frameBuf, write); err != nil {
+			return fail(fmt.Errorf("encoder failed: %w", err))
+		}
+	}
+	if err := enc.Finish(write); err != nil {
+		return fail(fmt.Errorf("encoder failed: %w", err))
+	}
+	if err := ivf.Finish(); err != nil {
+		return fail(fmt.Errorf("failed to write output: %w", err))
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return fail(fmt.Errorf("failed to stat output: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		return fail(fmt.Errorf("failed to write output: %w", err))
+	}
+	return worker.EncodeResult{
+		ChunkIdx: ch.Idx,
+		Frames:   ch.Frames(),
+		Size:     uint64(stat.Size()),
+		Elapsed:  time.Since(start),
+		Decode:   decode,
+	}
+}
@@ -0,0 +1,67 @@
+package encode
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/five82/reel/internal/util"
+)
+
+// NUMAPinning spreads workers across NUMA nodes round robin. Each worker's
+// decode thread is pinned to its node, and its encoders are started under
+// Tool so their memory is allocated there too.
+type NUMAPinning struct {
+	Nodes []util.NUMANode
+	Tool  string // "numactl", "taskset", or "" when the encoder only inherits the thread's affinity
+}
+
+// nextNUMAWorker numbers workers across every encode in the process, so files
+// encoding in parallel don't all start on the first node.
+var nextNUMAWorker atomic.Uint64
+
+// DetectNUMAPinning returns how to pin workers on this machine, or nil when
+// there is a single NUMA node (or the topology can't be read).
+func DetectNUMAPinning() *NUMAPinning {
+	nodes := util.NUMANodes()
+	if len(nodes) < 2 {
+		return nil
+	}
+	p := &NUMAPinning{Nodes: nodes}
+	for _, tool := range []string{"numactl", "taskset"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			p.Tool = tool
+			break
+		}
+	}
+	return p
+}
+
+// String describes the pinning for the hardware summary.
+func (p *NUMAPinning) String() string {
+	tool := p.Tool
+	if tool == "" {
+		tool = "thread affinity"
+	}
+	return fmt.Sprintf("%d nodes, pinning workers with %s", len(p.Nodes), tool)
+}
+
+// nextNode returns the node for the next worker to start.
+func (p *NUMAPinning) nextNode() util.NUMANode {
+	n := nextNUMAWorker.Add(1) - 1
+	return p.Nodes[n%uint64(len(p.Nodes))]
+}
+
+// bind returns the command prefix that runs an encoder on node. CPUs are
+// bound by list rather than by node, since node.CPUs leaves out any the
+// process isn't allowed to use.
+func (p *NUMAPinning) bind(node util.NUMANode) []string {
+	switch p.Tool {
+	case "numactl":
+		return []string{"numactl", "--physcpubind=" + node.CPUList, "--preferred=" + strconv.Itoa(node.ID)}
+	case "taskset":
+		return []string{"taskset", "-c", node.CPUList}
+	}
+	return nil
+}
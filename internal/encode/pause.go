@@ -0,0 +1,51 @@
+package encode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PauseFileName is a sentinel in the work directory. While it exists no new
+// chunks are dispatched; chunks already running finish normally.
+const PauseFileName = ".reel-pause"
+
+// pausePollInterval is how often a paused dispatcher checks the pause file.
+const pausePollInterval = 2 * time.Second
+
+// PausePath returns the pause file path for a work directory.
+func PausePath(workDir string) string {
+	return filepath.Join(workDir, PauseFileName)
+}
+
+// isPaused reports whether the pause file exists.
+func isPaused(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// waitWhilePaused blocks while the pause file at path exists. onPause is
+// called when waiting starts and onResume when the file is removed.
+func waitWhilePaused(ctx context.Context, path string, poll time.Duration, onPause, onResume func()) error {
+	if !isPaused(path) {
+		return nil
+	}
+	if onPause != nil {
+		onPause()
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for isPaused(path) {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if onResume != nil {
+		onResume()
+	}
+	return nil
+}
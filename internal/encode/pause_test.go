@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWaitWhilePaused(t *testing.T) {
+	path := PausePath(t.TempDir())
+
+	// No pause file: returns immediately without callbacks
+	called := false
+	mark := func() { called = true }
+	if err := waitWhilePaused(context.Background(), path, time.Millisecond, mark, mark); err != nil {
+		t.Fatalf("waitWhilePaused() = %v", err)
+	}
+	if called {
+		t.Error("callbacks ran without a pause file")
+	}
+
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paused := make(chan struct{})
+	resumed := false
+	go func() {
+		<-paused
+		_ = os.Remove(path)
+	}()
+	err := waitWhilePaused(context.Background(), path, time.Millisecond,
+		func() { close(paused) }, func() { resumed = true })
+	if err != nil || !resumed {
+		t.Errorf("waitWhilePaused() = %v, resumed = %v; want nil, true", err, resumed)
+	}
+}
+
+func TestWaitWhilePausedCancel(t *testing.T) {
+	path := PausePath(t.TempDir())
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitWhilePaused(ctx, path, time.Millisecond, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitWhilePaused() = %v, want context.Canceled", err)
+	}
+}
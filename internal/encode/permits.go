@@ -1,6 +1,10 @@
 package encode
 
-import "github.com/five82/reel/internal/util"
+import (
+	"time"
+
+	"github.com/five82/reel/internal/util"
+)
 
 // Estimated memory per worker by resolution (bytes).
 // Based on real-world SVT-AV1 measurements.
@@ -14,21 +18,171 @@ const (
 // 70% leaves headroom for OS, file cache, and other processes.
 const MemoryFraction = 0.7
 
-// CapWorkers returns the safe number of workers based on available memory.
-// Returns (actualWorkers, wasCapped).
-func CapWorkers(requested int, width, height uint32) (int, bool) {
-	memPerWorker := memoryPerWorker(width, height)
+// MemoryRecheckInterval is how often a memory-capped encode measures its
+// workers and decides whether to start more or retire some.
+const MemoryRecheckInterval = 30 * time.Second
+
+// minScalingGain is how much more throughput, as a fraction, a worker count
+// must reach over the last smaller one for the encode to keep adding
+// workers. Below it the CPU is saturated and more workers only cost memory.
+const minScalingGain = 0.05
+
+// WorkerCap is a decision on how many workers fit in memory.
+type WorkerCap struct {
+	Requested    int    // Workers asked for
+	Granted      int    // Workers allowed to encode at once
+	MemPerWorker uint64 // Estimated memory per worker, or measured once running
+	Available    uint64 // Measured available memory (0 if unknown)
+}
+
+// Capped reports whether memory limited the workers.
+func (c WorkerCap) Capped() bool {
+	return c.Granted < c.Requested
+}
+
+// CapWorkers returns how many of the requested workers fit in available
+// memory. memPerWorker overrides the estimate for the resolution if non-zero.
+func CapWorkers(requested int, width, height uint32, memPerWorker uint64) WorkerCap {
+	if memPerWorker == 0 {
+		memPerWorker = memoryPerWorker(width, height)
+	}
+	return capWorkers(requested, memPerWorker, util.AvailableMemoryBytes())
+}
 
-	maxByMemory := requested // default if we can't determine memory
-	if available := util.AvailableMemoryBytes(); available > 0 {
+// capWorkers fits requested workers of memPerWorker each into available memory.
+func capWorkers(requested int, memPerWorker, available uint64) WorkerCap {
+	c := WorkerCap{Requested: requested, Granted: requested, MemPerWorker: memPerWorker, Available: available}
+	if available > 0 {
 		usable := uint64(float64(available) * MemoryFraction)
-		maxByMemory = max(int(usable/memPerWorker), 1)
+		c.Granted = min(requested, max(int(usable/memPerWorker), 1))
+	}
+	return c
+}
+
+// StartWorkers returns how many of granted workers an encode capped at c
+// starts with: half when memory is known, as the estimate per worker is
+// only a guess until they run, and more start once measurements show room.
+// With memory unknown there is nothing to measure, so all start at once.
+func StartWorkers(c WorkerCap, granted int) int {
+	if c.Available == 0 {
+		return granted
+	}
+	return max((granted+1)/2, 1)
+}
+
+// workerScaler adjusts the workers of a memory-capped encode as it runs.
+// It measures the memory the workers use, from how far available memory
+// has fallen since they started, and the encode's throughput at each
+// worker count. It adds workers while memory has room and throughput keeps
+// improving, and retires them when memory runs short.
+type workerScaler struct {
+	requested int
+	files     int    // Files encoding at once, which share free memory
+	estimate  uint64 // Memory per worker until measured
+	baseline  uint64 // Available memory before any worker started (0 = unknown)
+	workers   int
+	levels    map[int]*scalingLevel
+	ceiling   int // Workers beyond which throughput stopped improving (0 = not reached)
+}
+
+// scalingLevel is the throughput measured at one worker count.
+type scalingLevel struct {
+	frames int
+	chunks int
+	secs   float64
+}
+
+func (l *scalingLevel) fps() float64 {
+	if l.secs <= 0 {
+		return 0
 	}
+	return float64(l.frames) / l.secs
+}
+
+// newWorkerScaler returns a scaler for an encode capped at c, running
+// workers to start with.
+func newWorkerScaler(c WorkerCap, files, workers int) *workerScaler {
+	return &workerScaler{
+		requested: c.Requested,
+		files:     max(files, 1),
+		estimate:  c.MemPerWorker,
+		baseline:  c.Available,
+		workers:   workers,
+		levels:    make(map[int]*scalingLevel),
+	}
+}
+
+// record adds the chunks and frames finished over secs at the current
+// worker count.
+func (s *workerScaler) record(chunks, frames int, secs float64) {
+	l := s.levels[s.workers]
+	if l == nil {
+		l = &scalingLevel{}
+		s.levels[s.workers] = l
+	}
+	l.chunks += chunks
+	l.frames += frames
+	l.secs += secs
+}
+
+// memPerWorker returns the memory each worker uses, measured when
+// available memory has fallen since the start, otherwise the estimate.
+// A measurement is never taken below a quarter of the estimate, as
+// memory freed by other processes meanwhile would otherwise hide the
+// workers' share.
+func (s *workerScaler) memPerWorker(available uint64) uint64 {
+	if s.baseline == 0 || available >= s.baseline {
+		return s.estimate
+	}
+	return max((s.baseline-available)/uint64(s.workers), s.estimate/4)
+}
 
-	if requested > maxByMemory {
-		return maxByMemory, true
+// measured reports whether the current worker count has finished a chunk
+// per worker, enough to judge its throughput.
+func (s *workerScaler) measured() bool {
+	l := s.levels[s.workers]
+	return l != nil && l.chunks >= s.workers
+}
+
+// next decides the workers to run with available memory, returning the
+// decision with the memory per worker it was based on.
+func (s *workerScaler) next(available uint64) WorkerCap {
+	c := WorkerCap{Requested: s.requested, Granted: s.workers, MemPerWorker: s.estimate, Available: available}
+	if available == 0 {
+		return c
+	}
+	per := s.memPerWorker(available)
+	c.MemPerWorker = per
+
+	// Memory pressure: less than a worker's worth left. Retiring one
+	// frees that much again; the next check retires another if not.
+	if available < per {
+		s.workers = max(s.workers-1, 1)
+		c.Granted = s.workers
+		return c
+	}
+
+	if !s.measured() {
+		return c
+	}
+	// Stop growing once a count gains too little over the last smaller one
+	for n := s.workers - 1; n > 0; n-- {
+		if l := s.levels[n]; l != nil && l.chunks >= n {
+			if s.levels[s.workers].fps() < l.fps()*(1+minScalingGain) {
+				s.ceiling = s.workers
+			}
+			break
+		}
+	}
+	limit := s.requested
+	if s.ceiling > 0 {
+		limit = min(limit, s.ceiling)
 	}
-	return requested, false
+	extra := int(uint64(float64(available)*MemoryFraction)/per) / s.files
+	// Grow by at most half again, so each step can be measured
+	s.workers += max(min(extra, limit-s.workers, max(s.workers/2, 1)), 0)
+	c.Granted = s.workers
+	return c
 }
 
 // memoryPerWorker returns estimated memory usage per worker based on resolution.
@@ -43,6 +197,12 @@ func memoryPerWorker(width, height uint32) uint64 {
 	}
 }
 
+// ShareWorkers returns the workers one of files encodes with when that many
+// files encode at the same time. Returns at least 1.
+func ShareWorkers(workers, files int) int {
+	return max(workers/max(files, 1), 1)
+}
+
 // CalculatePermits returns the number of in-flight chunk permits.
 // Permits = workers + buffer to allow prefetching chunks.
 // Returns at least 1.
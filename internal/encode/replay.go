@@ -0,0 +1,49 @@
+package encode
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// replaySource decodes the source with ffmpeg into the frames reel feeds
+// the encoder, so encoder commands in a command script can be replayed.
+type replaySource struct {
+	ffmpeg string
+	path   string
+	stream string // ffmpeg -map of the video track
+	crop   string // crop filter, empty if uncropped
+	pixFmt string
+}
+
+// newReplaySource describes decoding the video indexed by idx, cropped by
+// cropCalc if it is not nil, with the ffmpeg in paths.
+func newReplaySource(paths toolpath.Paths, idx *ffms.VidIdx, inf *ffms.VidInf, cropCalc *ffms.CropCalc) *replaySource {
+	r := &replaySource{ffmpeg: paths.Path(toolpath.FFmpeg), path: idx.Path(), stream: "0:v:0", pixFmt: "yuv420p10le"}
+	if track := idx.Track(); track >= 0 {
+		r.stream = "0:" + strconv.Itoa(track)
+	}
+	if cropCalc != nil && (cropCalc.CropH > 0 || cropCalc.CropV > 0) {
+		r.crop = fmt.Sprintf(",crop=%d:%d:%d:%d", cropCalc.NewW, cropCalc.NewH, cropCalc.CropH, cropCalc.CropV)
+	}
+	if inf.Output8Bit {
+		r.pixFmt = "yuv420p"
+	}
+	return r
+}
+
+// input returns an ffmpeg command writing the raw frames of ch to stdout.
+func (r *replaySource) input(ch chunk.Chunk) []string {
+	return []string{
+		r.ffmpeg, "-v", "error",
+		"-i", r.path,
+		"-map", r.stream,
+		"-vf", fmt.Sprintf(`select=between(n\,%d\,%d)%s`, ch.Start, ch.End-1, r.crop),
+		"-fps_mode", "passthrough",
+		"-pix_fmt", r.pixFmt,
+		"-f", "rawvideo", "-",
+	}
+}
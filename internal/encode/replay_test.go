@@ -0,0 +1,21 @@
+package encode
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/chunk"
+)
+
+func TestReplayInput(t *testing.T) {
+	r := &replaySource{ffmpeg: "ffmpeg", path: "/in/a.mkv", stream: "0:v:0", crop: ",crop=1920:800:0:140", pixFmt: "yuv420p10le"}
+	got := r.input(chunk.Chunk{Idx: 1, Start: 240, End: 480})
+	want := []string{
+		"ffmpeg", "-v", "error", "-i", "/in/a.mkv", "-map", "0:v:0",
+		"-vf", `select=between(n\,240\,479),crop=1920:800:0:140`,
+		"-fps_mode", "passthrough", "-pix_fmt", "yuv420p10le", "-f", "rawvideo", "-",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("input() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package encode
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// retryBaseDelay is the wait before the first retry of a failed chunk; it
+// doubles with each further attempt, up to maxRetryDelay.
+const (
+	retryBaseDelay = 5 * time.Second
+	maxRetryDelay  = 5 * time.Minute
+)
+
+// minChunkTimeout is the least time an attempt at a chunk is given, so a
+// short chunk isn't killed while its encoder is still starting up.
+const minChunkTimeout = 5 * time.Minute
+
+// timeoutPixels is the frame size a per-frame chunk timeout is given for:
+// 1080p.
+const timeoutPixels = 1920 * 1080
+
+// ChunkRetry describes a failed chunk attempt that is about to be retried.
+type ChunkRetry struct {
+	Chunk   int           // Chunk index
+	Attempt int           // Retry number, starting at 1
+	Retries int           // Configured retry limit
+	Threads int           // Threads (--lp) for the retry
+	Delay   time.Duration // Wait before the retry
+	Err     error         // Failure of the previous attempt
+}
+
+// isTransientFailure reports whether err is the encoder being killed by a
+// signal, such as SIGKILL from the OOM killer, or for running past the
+// chunk's timeout, which a retry may survive. Encoder errors and failed
+// frame decodes are not retried.
+func isTransientFailure(err error) bool {
+	var timeoutErr *ChunkTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return true
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled()
+}
+
+// chunkTimeout returns how long an attempt at a chunk of frames at width x
+// height may run before its encoder is taken to be hung: perFrame for each
+// frame, scaled by the frame's pixels against a 1080p frame, and at least
+// minChunkTimeout. A zero perFrame means no timeout.
+func chunkTimeout(perFrame time.Duration, frames int, width, height uint32) time.Duration {
+	if perFrame <= 0 {
+		return 0
+	}
+	scale := float64(width) * float64(height) / timeoutPixels
+	return max(time.Duration(float64(perFrame)*float64(frames)*scale), minChunkTimeout)
+}
+
+// retryDelay returns the backoff before the given retry (1-based). The shift
+// is bounded so high attempt counts can't overflow.
+func retryDelay(attempt int) time.Duration {
+	shift := min(max(attempt-1, 0), 16)
+	return min(retryBaseDelay<<shift, maxRetryDelay)
+}
+
+// retryThreads returns the threads for the given retry, halving them on each
+// attempt when reduce is set.
+func retryThreads(threads, attempt int, reduce bool) int {
+	if !reduce {
+		return threads
+	}
+	return max(threads>>attempt, 1)
+}
+
+// sleepCtx waits for d, returning false if ctx is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestIsTransientFailure(t *testing.T) {
+	killed := exec.Command("sh", "-c", "kill -9 $$").Run()
+	exited := exec.Command("sh", "-c", "exit 1").Run()
+	if killed == nil || exited == nil {
+		t.Fatal("expected both commands to fail")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"killed by signal", fmt.Errorf("encoder failed: %w", killed), true},
+		{"nonzero exit", fmt.Errorf("encoder failed: %w", exited), false},
+		{"timed out", &ChunkTimeoutError{Timeout: time.Hour}, true},
+		{"other error", errors.New("failed to extract frame 10"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientFailure(tt.err); got != tt.want {
+				t.Errorf("isTransientFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		perFrame      time.Duration
+		frames        int
+		width, height uint32
+		want          time.Duration
+	}{
+		{"off", 0, 1000, 1920, 1080, 0},
+		{"1080p", time.Second, 600, 1920, 1080, 10 * time.Minute},
+		{"4K takes four times as long", time.Second, 600, 3840, 2160, 40 * time.Minute},
+		{"short chunks get the minimum", time.Second, 100, 1920, 1080, minChunkTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkTimeout(tt.perFrame, tt.frames, tt.width, tt.height); got != tt.want {
+				t.Errorf("chunkTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	if got := retryDelay(1); got != 5*time.Second {
+		t.Errorf("retryDelay(1) = %v, want 5s", got)
+	}
+	if got := retryDelay(3); got != 20*time.Second {
+		t.Errorf("retryDelay(3) = %v, want 20s", got)
+	}
+	for _, attempt := range []int{7, 8, 64, 1000} {
+		if got := retryDelay(attempt); got != maxRetryDelay {
+			t.Errorf("retryDelay(%d) = %v, want the %v cap", attempt, got, maxRetryDelay)
+		}
+	}
+
+	tests := []struct {
+		threads, attempt int
+		reduce           bool
+		want             int
+	}{
+		{8, 1, false, 8},
+		{8, 1, true, 4},
+		{8, 2, true, 2},
+		{3, 2, true, 1},
+	}
+	for _, tt := range tests {
+		if got := retryThreads(tt.threads, tt.attempt, tt.reduce); got != tt.want {
+			t.Errorf("retryThreads(%d, %d, %v) = %d, want %d", tt.threads, tt.attempt, tt.reduce, got, tt.want)
+		}
+	}
+}
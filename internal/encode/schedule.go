@@ -0,0 +1,91 @@
+package encode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+)
+
+// costQueue hands out chunks most expensive first, so the encode doesn't
+// end with one long chunk running alone while the other workers sit idle.
+// Cost starts as frames times pixels; once chunks finish, it is frames
+// times the time per frame of the nearest finished chunk, since scenes
+// close together tend to encode at similar speeds.
+type costQueue struct {
+	mu      sync.Mutex
+	pending []chunk.Chunk
+	pixels  float64
+	rates   map[int]float64 // Seconds per frame of finished chunks, by index
+}
+
+// newCostQueue returns a queue of chunks of a width x height video.
+func newCostQueue(chunks []chunk.Chunk, width, height uint32) *costQueue {
+	return &costQueue{
+		pending: append([]chunk.Chunk(nil), chunks...),
+		pixels:  float64(width) * float64(height),
+		rates:   make(map[int]float64),
+	}
+}
+
+// next removes and returns the chunk with the highest estimated cost,
+// the lowest index on a tie, and false once none remain.
+func (q *costQueue) next() (chunk.Chunk, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return chunk.Chunk{}, false
+	}
+	best := 0
+	bestCost := q.cost(q.pending[0])
+	for i, ch := range q.pending[1:] {
+		if c := q.cost(ch); c > bestCost || (c == bestCost && ch.Idx < q.pending[best].Idx) {
+			best, bestCost = i+1, c
+		}
+	}
+	ch := q.pending[best]
+	q.pending = append(q.pending[:best], q.pending[best+1:]...)
+	return ch, true
+}
+
+// remaining returns the number of chunks not yet handed out.
+func (q *costQueue) remaining() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// observe records how long a finished chunk took, refining the cost of
+// the chunks near it.
+func (q *costQueue) observe(ch chunk.Chunk, elapsed time.Duration) {
+	if ch.Frames() <= 0 || elapsed <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rates[ch.Idx] = elapsed.Seconds() / float64(ch.Frames())
+}
+
+// cost estimates the time ch takes to encode, in seconds once any chunk
+// has finished and in pixels encoded before. Callers hold q.mu.
+func (q *costQueue) cost(ch chunk.Chunk) float64 {
+	frames := float64(ch.Frames())
+	if len(q.rates) == 0 {
+		return frames * q.pixels
+	}
+	nearest, rate := -1, 0.0
+	for idx, r := range q.rates {
+		d := abs(idx - ch.Idx)
+		if nearest < 0 || d < nearest || (d == nearest && r > rate) {
+			nearest, rate = d, r
+		}
+	}
+	return frames * rate
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
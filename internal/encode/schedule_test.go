@@ -0,0 +1,54 @@
+package encode
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+)
+
+func TestCostQueue(t *testing.T) {
+	chunks := []chunk.Chunk{
+		{Idx: 0, Start: 0, End: 100},
+		{Idx: 1, Start: 100, End: 400}, // Longest
+		{Idx: 2, Start: 400, End: 500},
+		{Idx: 3, Start: 500, End: 700},
+		{Idx: 4, Start: 700, End: 800},
+	}
+
+	t.Run("longest first", func(t *testing.T) {
+		q := newCostQueue(chunks, 1920, 1080)
+		var order []int
+		for q.remaining() > 0 {
+			ch, _ := q.next()
+			order = append(order, ch.Idx)
+		}
+		if want := []int{1, 3, 0, 2, 4}; !slices.Equal(order, want) {
+			t.Errorf("order = %v, want %v", order, want)
+		}
+		if _, ok := q.next(); ok {
+			t.Error("next() on an empty queue = true, want false")
+		}
+	})
+
+	t.Run("refined by measured speed", func(t *testing.T) {
+		q := newCostQueue(chunks, 1920, 1080)
+		first, _ := q.next()  // Chunk 1
+		second, _ := q.next() // Chunk 3
+		// Chunk 3's scenes encode four times slower per frame than chunk 1's
+		q.observe(first, 30*time.Second)
+		q.observe(second, 80*time.Second)
+
+		// Chunk 4 (100 frames at 0.4s) now costs more than chunk 0 (100 at 0.1s);
+		// chunk 2 is as close to either, so takes the slower estimate
+		var order []int
+		for q.remaining() > 0 {
+			ch, _ := q.next()
+			order = append(order, ch.Idx)
+		}
+		if want := []int{2, 4, 0}; !slices.Equal(order, want) {
+			t.Errorf("order = %v, want %v", order, want)
+		}
+	})
+}
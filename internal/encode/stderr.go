@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stderrTailLines is how many of a failed encoder's last stderr lines are
+// included in the chunk's error.
+const stderrTailLines = 20
+
+// stderrTailBytes bounds how much of the end of a stderr log is read, as
+// progress output can make the log of a long chunk large.
+const stderrTailBytes = 64 * 1024
+
+// stderrLogPath returns where a chunk's encoder stderr is written: next to
+// its output, as encode/NNNN.log in the work directory.
+func stderrLogPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".ivf") + ".log"
+}
+
+// encoderError describes an encoder that exited with err, adding the last
+// lines it wrote to stderr, which tell bad arguments and corrupt input
+// apart. A process killed by a signal usually wrote nothing useful; the
+// log is kept for inspection either way.
+func encoderError(err error, logPath string) error {
+	lines := readTail(logPath, stderrTailLines)
+	if len(lines) == 0 {
+		return fmt.Errorf("encoder failed: %w (stderr: %s)", err, logPath)
+	}
+	return fmt.Errorf("encoder failed: %w (stderr: %s):\n  %s", err, logPath, strings.Join(lines, "\n  "))
+}
+
+// readTail returns the last n non-progress lines of the file at path, or
+// nil if it cannot be read.
+func readTail(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	offset := max(info.Size()-stderrTailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return nil
+	}
+	if offset > 0 {
+		// Drop the partial line the read started in
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return tailLines(string(data), n)
+}
+
+// tailLines returns the last n lines of s that are neither blank nor
+// progress updates. Progress is redrawn with carriage returns, so those
+// split lines too.
+func tailLines(s string, n int) []string {
+	var lines []string
+	for line := range strings.FieldsFuncSeq(s, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Encoding") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines[max(len(lines)-n, 0):]
+}
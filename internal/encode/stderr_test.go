@@ -0,0 +1,55 @@
+package encode
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		n    int
+		want []string
+	}{
+		{"empty", "", 3, nil},
+		{"fewer than n", "a\nb\n", 3, []string{"a", "b"}},
+		{"last n", "a\nb\nc\nd\n", 2, []string{"c", "d"}},
+		{"skips progress", "Svt[info]: start\rEncoding frame 1\rEncoding frame 2\nSvt[error]: bad\n", 5,
+			[]string{"Svt[info]: start", "Svt[error]: bad"}},
+		{"skips blank", "a\n\n  \nb", 5, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tailLines(tt.in, tt.n); !slices.Equal(got, tt.want) {
+				t.Errorf("tailLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTailLargeLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0001.log")
+	log := strings.Repeat("Encoding frame 1\r", stderrTailBytes/8) + "Svt[error]: corrupt frame\n"
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTail(path, 2); !slices.Equal(got, []string{"Svt[error]: corrupt frame"}) {
+		t.Errorf("readTail() = %q", got)
+	}
+	if got := readTail(filepath.Join(t.TempDir(), "missing.log"), 2); got != nil {
+		t.Errorf("readTail(missing) = %q, want nil", got)
+	}
+}
+
+func TestStderrLogPath(t *testing.T) {
+	if got := stderrLogPath("/w/encode/0001.ivf"); got != "/w/encode/0001.log" {
+		t.Errorf("stderrLogPath() = %q", got)
+	}
+	if got := stderrLogPath("/w/encode/0001.ivf.dup"); got != "/w/encode/0001.ivf.dup.log" {
+		t.Errorf("stderrLogPath(dup) = %q", got)
+	}
+}
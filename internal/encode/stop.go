@@ -0,0 +1,21 @@
+package encode
+
+import "context"
+
+type chunkContextKey struct{}
+
+// WithFinishInFlight returns a copy of ctx whose cancellation only stops new
+// chunks from starting. Chunks already running finish and are recorded for
+// resuming, unless chunkCtx is cancelled too.
+func WithFinishInFlight(ctx, chunkCtx context.Context) context.Context {
+	return context.WithValue(ctx, chunkContextKey{}, chunkCtx)
+}
+
+// ChunkContext returns the context running chunks are stopped by: the one
+// given to WithFinishInFlight, or ctx itself.
+func ChunkContext(ctx context.Context) context.Context {
+	if chunkCtx, ok := ctx.Value(chunkContextKey{}).(context.Context); ok {
+		return chunkCtx
+	}
+	return ctx
+}
@@ -0,0 +1,25 @@
+package encode
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChunkContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if ChunkContext(ctx) != ctx {
+		t.Error("ChunkContext() without WithFinishInFlight should return ctx")
+	}
+
+	chunkCtx, cancelChunks := context.WithCancel(context.Background())
+	defer cancelChunks()
+	graceful := WithFinishInFlight(ctx, chunkCtx)
+	cancel()
+	if graceful.Err() == nil {
+		t.Error("cancelling ctx should stop dispatch")
+	}
+	if ChunkContext(graceful).Err() != nil {
+		t.Error("cancelling ctx should leave running chunks going")
+	}
+}
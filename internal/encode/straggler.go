@@ -0,0 +1,141 @@
+package encode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/worker"
+)
+
+// stragglerFactor is how much longer than the median completed chunk a chunk
+// must run before it is duplicated onto an idle worker.
+const stragglerFactor = 1.5
+
+// job is a unit of work sent to a streaming worker.
+type job struct {
+	ch        chunk.Chunk
+	duplicate bool // Second attempt at a straggler; holds no semaphore permit
+}
+
+// outputPath returns where this attempt writes its IVF. Duplicates write beside
+// the real chunk file so the two attempts never share a file.
+func (j job) outputPath(workDir string) string {
+	path := chunk.IVFPath(workDir, j.ch.Idx)
+	if j.duplicate {
+		return path + ".dup"
+	}
+	return path
+}
+
+// chunkState tracks all attempts at one chunk.
+type chunkState struct {
+	ch         chunk.Chunk
+	started    time.Time
+	ctx        context.Context
+	cancel     context.CancelFunc
+	running    int
+	duplicated bool
+	done       bool
+}
+
+// inflightTracker decides which attempt at each chunk wins. The first attempt
+// to succeed wins and the others are cancelled; a failure only counts once no
+// other attempt at that chunk is still running.
+type inflightTracker struct {
+	mu        sync.Mutex
+	workDir   string
+	chunks    map[int]*chunkState
+	doneCount int
+	durations []time.Duration
+}
+
+func newInflightTracker(workDir string) *inflightTracker {
+	return &inflightTracker{workDir: workDir, chunks: make(map[int]*chunkState)}
+}
+
+// start registers an attempt and returns the context it should encode under.
+func (t *inflightTracker) start(parent context.Context, j job) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.chunks[j.ch.Idx]
+	if st == nil {
+		ctx, cancel := context.WithCancel(parent)
+		st = &chunkState{ch: j.ch, started: time.Now(), ctx: ctx, cancel: cancel}
+		t.chunks[j.ch.Idx] = st
+	}
+	st.running++
+	return st.ctx
+}
+
+// finish records an attempt's result. It returns the result to report and
+// whether it should be reported at all (losing attempts are dropped).
+func (t *inflightTracker) finish(j job, result worker.EncodeResult) (worker.EncodeResult, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.chunks[j.ch.Idx]
+	st.running--
+
+	if st.done || (result.Error != nil && st.running > 0) {
+		if j.duplicate {
+			_ = os.Remove(j.outputPath(t.workDir))
+		}
+		return result, false
+	}
+
+	if result.Error == nil && j.duplicate {
+		if err := os.Rename(j.outputPath(t.workDir), chunk.IVFPath(t.workDir, j.ch.Idx)); err != nil {
+			result.Error = fmt.Errorf("failed to keep duplicate of chunk %d: %w", j.ch.Idx, err)
+		}
+	}
+	if result.Error == nil {
+		t.durations = append(t.durations, time.Since(st.started))
+	}
+
+	st.done = true
+	st.cancel()
+	t.doneCount++
+	return result, true
+}
+
+// allDone reports whether total chunks have a final result.
+func (t *inflightTracker) allDone(total int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.doneCount >= total
+}
+
+// pickStraggler returns the longest-running chunk that has run more than
+// stragglerFactor times the median completed chunk and has not been duplicated.
+func (t *inflightTracker) pickStraggler(now time.Time) (chunk.Chunk, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.durations) == 0 {
+		return chunk.Chunk{}, false
+	}
+	sorted := slices.Clone(t.durations)
+	slices.Sort(sorted)
+	deadline := time.Duration(float64(sorted[len(sorted)/2]) * stragglerFactor)
+
+	var pick *chunkState
+	for _, st := range t.chunks {
+		if st.done || st.duplicated || st.running == 0 || now.Sub(st.started) <= deadline {
+			continue
+		}
+		if pick == nil || st.started.Before(pick.started) {
+			pick = st
+		}
+	}
+	if pick == nil {
+		return chunk.Chunk{}, false
+	}
+	pick.duplicated = true
+	return pick.ch, true
+}
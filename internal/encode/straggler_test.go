@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/worker"
+)
+
+func TestPickStraggler(t *testing.T) {
+	tracker := newInflightTracker(t.TempDir())
+	ctx := context.Background()
+
+	// No completed chunks yet: no baseline, nothing is a straggler
+	slow := job{ch: chunk.Chunk{Idx: 1}}
+	tracker.start(ctx, slow)
+	if _, ok := tracker.pickStraggler(time.Now().Add(time.Hour)); ok {
+		t.Fatal("pickStraggler() picked a chunk with no completed baseline")
+	}
+
+	fast := job{ch: chunk.Chunk{Idx: 0}}
+	tracker.start(ctx, fast)
+	tracker.finish(fast, worker.EncodeResult{ChunkIdx: 0})
+
+	ch, ok := tracker.pickStraggler(time.Now().Add(time.Hour))
+	if !ok || ch.Idx != 1 {
+		t.Fatalf("pickStraggler() = %v, %v, want chunk 1", ch, ok)
+	}
+	if _, ok := tracker.pickStraggler(time.Now().Add(time.Hour)); ok {
+		t.Error("pickStraggler() duplicated the same chunk twice")
+	}
+}
+
+func TestFinishDuplicateWins(t *testing.T) {
+	workDir := t.TempDir()
+	if err := chunk.EnsureEncodeDir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	tracker := newInflightTracker(workDir)
+
+	orig := job{ch: chunk.Chunk{Idx: 3}}
+	dup := job{ch: chunk.Chunk{Idx: 3}, duplicate: true}
+	origCtx := tracker.start(context.Background(), orig)
+	tracker.start(context.Background(), dup)
+
+	if err := os.WriteFile(dup.outputPath(workDir), []byte("dup"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, report := tracker.finish(dup, worker.EncodeResult{ChunkIdx: 3}); !report {
+		t.Fatal("first successful attempt was not reported")
+	}
+	if origCtx.Err() == nil {
+		t.Error("losing attempt was not cancelled")
+	}
+	data, err := os.ReadFile(chunk.IVFPath(workDir, 3))
+	if err != nil || string(data) != "dup" {
+		t.Errorf("chunk file = %q, %v, want duplicate output", data, err)
+	}
+
+	if _, report := tracker.finish(orig, worker.EncodeResult{ChunkIdx: 3, Error: context.Canceled}); report {
+		t.Error("losing attempt was reported")
+	}
+	if !tracker.allDone(1) {
+		t.Error("allDone(1) = false after chunk finished")
+	}
+}
+
+func TestFinishFailureWaitsForOtherAttempt(t *testing.T) {
+	tracker := newInflightTracker(t.TempDir())
+
+	orig := job{ch: chunk.Chunk{Idx: 0}}
+	dup := job{ch: chunk.Chunk{Idx: 0}, duplicate: true}
+	tracker.start(context.Background(), orig)
+	tracker.start(context.Background(), dup)
+
+	if _, report := tracker.finish(dup, worker.EncodeResult{Error: errors.New("boom")}); report {
+		t.Error("failure reported while another attempt was still running")
+	}
+	if _, report := tracker.finish(orig, worker.EncodeResult{Error: errors.New("boom")}); !report {
+		t.Error("failure of the last attempt was not reported")
+	}
+}
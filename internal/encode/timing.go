@@ -0,0 +1,26 @@
+package encode
+
+import (
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+)
+
+// ChunkTiming is the wall time a chunk took to encode.
+type ChunkTiming struct {
+	Chunk   chunk.Chunk
+	Elapsed time.Duration
+	Decode  time.Duration // Worker time decoding frames
+	Pipe    time.Duration // Writer time blocked on the encoder's stdin (0 in-process)
+
+	DecodeStall time.Duration // Decoder waiting on the encoder (0 in-process)
+	EncodeStall time.Duration // Encoder waiting on the decoder (0 in-process)
+}
+
+// FPS returns the chunk's encode speed in frames per second.
+func (t ChunkTiming) FPS() float64 {
+	if t.Elapsed <= 0 {
+		return 0
+	}
+	return float64(t.Chunk.Frames()) / t.Elapsed.Seconds()
+}
@@ -2,15 +2,18 @@
 package encoder
 
 import (
+	"context"
 	"fmt"
+	"math/bits"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
 )
 
-const svtEncBinary = "SvtAv1EncApp"
-
 // EncConfig contains configuration for encoding a chunk.
 type EncConfig struct {
 	Inf        *ffms.VidInf // Video properties
@@ -29,39 +32,78 @@ type EncConfig struct {
 	VarianceBoostStrength uint8
 	VarianceOctile        uint8
 	LogicalProcessors     int // Threads per worker (--lp flag), 0 = SVT-AV1 default
+
+	KeyintSecs   float64 // Maximum keyframe interval in seconds, 0 = 10
+	AssumeRec601 bool    // Tag untagged SD sources as Rec.601
+	FilmGrain    uint8   // Film grain synthesis level, 0 = off
+	Denoise      bool    // Denoise before film grain synthesis
+	SCM          uint8   // Screen content mode, 0 = off
+	DisableSCD   bool    // No keyframes at scene changes within the chunk
+	TileRows     uint8   // Tile rows, a power of two (0 or 1 = no row tiling)
+	TileColumns  uint8   // Tile columns, a power of two (0 or 1 = no column tiling)
+
+	ExtraParams []string // Additional SvtAv1EncApp arguments, appended last
+	Svt         string   // SvtAv1EncApp binary ("" = looked up in PATH)
+
+	// Bind is a command the encoder is run under to pin it to a NUMA node,
+	// e.g. numactl --physcpubind=16-31 --preferred=1 (empty = unpinned)
+	Bind []string
 }
 
 // MakeSvtCmd builds an SvtAv1EncApp command for encoding.
 // The command reads raw YUV data from stdin and outputs to an IVF file.
 // The command is wrapped with nice -n 19 to keep the system responsive.
-func MakeSvtCmd(cfg *EncConfig) *exec.Cmd {
+// Cancelling ctx kills the encoder's whole process group at once.
+func MakeSvtCmd(ctx context.Context, cfg *EncConfig) *exec.Cmd {
 	args := buildSvtArgs(cfg)
-	niceArgs := append([]string{"-n", "19", svtEncBinary}, args...)
-	return exec.Command("nice", niceArgs...)
+	svt := cfg.Svt
+	if svt == "" {
+		svt = toolpath.SvtAv1EncApp
+	}
+	niceArgs := append([]string{"-n", "19", svt}, args...)
+	var cmd *exec.Cmd
+	if len(cfg.Bind) > 0 {
+		bindArgs := append(slices.Clone(cfg.Bind[1:]), "nice")
+		cmd = exec.CommandContext(ctx, cfg.Bind[0], append(bindArgs, niceArgs...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, "nice", niceArgs...)
+	}
+	util.KillGroupOnCancel(cmd)
+	return cmd
 }
 
 // buildSvtArgs constructs the argument list for SvtAv1EncApp.
 func buildSvtArgs(cfg *EncConfig) []string {
-	// Calculate keyint in frames (10 seconds worth)
+	// Calculate keyint in frames (10 seconds worth unless configured)
+	keyintSecs := cfg.KeyintSecs
+	if keyintSecs == 0 {
+		keyintSecs = 10
+	}
 	fps := float64(cfg.Inf.FPSNum) / float64(cfg.Inf.FPSDen)
-	keyintFrames := int(fps * 10)
+	keyintFrames := int(fps * keyintSecs)
+
+	// Frames are 10-bit unless extracted as 8-bit
+	inputDepth := "10"
+	if cfg.Inf.Output8Bit {
+		inputDepth = "8"
+	}
 
 	args := []string{
 		"-i", "stdin",
-		"--input-depth", "10", // Always 10-bit input (8-bit sources are converted)
+		"--input-depth", inputDepth,
 		"--color-format", "1", // YUV420
 		"--profile", "0",      // Main profile
 		"--passes", "1",
-		"--tile-rows", "0",
-		"--tile-columns", "0",
+		"--tile-rows", fmt.Sprintf("%d", tileLog2(cfg.TileRows)),
+		"--tile-columns", fmt.Sprintf("%d", tileLog2(cfg.TileColumns)),
 		"--width", fmt.Sprintf("%d", cfg.Width),
 		"--height", fmt.Sprintf("%d", cfg.Height),
 		"--fps-num", fmt.Sprintf("%d", cfg.Inf.FPSNum),
 		"--fps-denom", fmt.Sprintf("%d", cfg.Inf.FPSDen),
-		"--keyint", fmt.Sprintf("%d", keyintFrames), // Keyframe every 10 seconds
+		"--keyint", fmt.Sprintf("%d", keyintFrames), // Keyframe every keyintSecs
 		"--rc", "0",       // CRF mode
-		"--scd", "1",      // Enable scene change detection for keyframes within chunks
-		"--scm", "0",      // Screen content mode disabled
+		"--scd", boolFlag(!cfg.DisableSCD), // Scene change detection for keyframes within chunks
+		"--scm", fmt.Sprintf("%d", cfg.SCM), // Screen content mode
 		"--progress", "2", // Progress to stderr
 		"--frames", fmt.Sprintf("%d", cfg.Frames),
 		"--crf", fmt.Sprintf("%.0f", cfg.CRF),
@@ -77,14 +119,18 @@ func buildSvtArgs(cfg *EncConfig) []string {
 	}
 
 	// Add color metadata if available
-	if cfg.Inf.ColorPrimaries != nil {
-		args = append(args, "--color-primaries", fmt.Sprintf("%d", *cfg.Inf.ColorPrimaries))
+	primaries, transfer, matrix := cfg.Inf.ColorPrimaries, cfg.Inf.TransferCharacteristics, cfg.Inf.MatrixCoefficients
+	if cfg.AssumeRec601 && cfg.Inf.Height <= rec601MaxHeight && primaries == nil && transfer == nil && matrix == nil {
+		primaries, transfer, matrix = rec601Color(cfg.Inf.Height)
+	}
+	if primaries != nil {
+		args = append(args, "--color-primaries", fmt.Sprintf("%d", *primaries))
 	}
-	if cfg.Inf.TransferCharacteristics != nil {
-		args = append(args, "--transfer-characteristics", fmt.Sprintf("%d", *cfg.Inf.TransferCharacteristics))
+	if transfer != nil {
+		args = append(args, "--transfer-characteristics", fmt.Sprintf("%d", *transfer))
 	}
-	if cfg.Inf.MatrixCoefficients != nil {
-		args = append(args, "--matrix-coefficients", fmt.Sprintf("%d", *cfg.Inf.MatrixCoefficients))
+	if matrix != nil {
+		args = append(args, "--matrix-coefficients", fmt.Sprintf("%d", *matrix))
 	}
 
 	// Add mastering display if available
@@ -95,9 +141,61 @@ func buildSvtArgs(cfg *EncConfig) []string {
 		args = append(args, "--content-light", *cfg.Inf.ContentLight)
 	}
 
-	// Add film grain table if provided
+	args = append(args, tuningArgs(cfg)...)
+
+	// Output file
+	args = append(args, "-b", cfg.Output)
+
+	return args
+}
+
+// appOnlyArgs are SvtAv1EncApp arguments without a libSvtAv1Enc parameter:
+// input and output, which the caller handles, and stream properties that are
+// set on the library's configuration directly.
+var appOnlyArgs = map[string]bool{
+	"-i": true, "-b": true, "--progress": true, "--frames": true, "--passes": true,
+	"--input-depth": true, "--color-format": true, "--profile": true,
+	"--width": true, "--height": true, "--fps-num": true, "--fps-denom": true,
+}
+
+// LibParam is a libSvtAv1Enc parameter as svt_av1_enc_parse_parameter takes
+// it: the SvtAv1EncApp argument name without dashes, and its value.
+type LibParam struct {
+	Name  string
+	Value string
+}
+
+// LibParams returns the SvtAv1EncApp arguments for cfg as libSvtAv1Enc
+// parameters, for encoding in-process. An extra argument without a value
+// gets "1", as the library's boolean parameters take.
+func LibParams(cfg *EncConfig) []LibParam {
+	args := buildSvtArgs(cfg)
+	var params []LibParam
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		value := "1"
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			value = args[i+1]
+			i++
+		}
+		if appOnlyArgs[name] {
+			continue
+		}
+		params = append(params, LibParam{Name: strings.TrimLeft(name, "-"), Value: value})
+	}
+	return params
+}
+
+// tuningArgs returns the film grain, advanced and extra parameters.
+func tuningArgs(cfg *EncConfig) []string {
+	var args []string
+
+	// Add film grain table if provided, otherwise synthesized grain if requested
 	if cfg.GrainTable != nil {
 		args = append(args, "--fgs-table", *cfg.GrainTable)
+	} else if cfg.FilmGrain > 0 {
+		args = append(args, "--film-grain", fmt.Sprintf("%d", cfg.FilmGrain))
+		args = append(args, "--film-grain-denoise", boolFlag(cfg.Denoise))
 	}
 
 	// Add advanced parameters
@@ -111,10 +209,29 @@ func buildSvtArgs(cfg *EncConfig) []string {
 		args = append(args, "--variance-octile", fmt.Sprintf("%d", cfg.VarianceOctile))
 	}
 
-	// Output file
-	args = append(args, "-b", cfg.Output)
+	return append(args, cfg.ExtraParams...)
+}
 
-	return args
+// tileLog2 converts a tile count to the log2 value SvtAv1EncApp takes.
+func tileLog2(n uint8) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len8(n) - 1
+}
+
+// rec601MaxHeight is the tallest frame AssumeRec601 tags: untagged HD is
+// more likely Rec.709, and is left untagged.
+const rec601MaxHeight = 576
+
+// rec601Color returns Rec.601 color metadata for an untagged SD source:
+// BT.470BG primaries and matrix for 576-line (PAL) video, SMPTE 170M otherwise (NTSC).
+func rec601Color(height uint32) (primaries, transfer, matrix *int32) {
+	p, t, m := int32(6), int32(6), int32(6)
+	if height == 576 {
+		p, m = 5, 5
+	}
+	return &p, &t, &m
 }
 
 // SvtArgsString returns a human-readable string of the SVT-AV1 arguments.
@@ -123,36 +240,64 @@ func SvtArgsString(cfg *EncConfig) string {
 	return strings.Join(args, " ")
 }
 
+// SettingsString returns the SVT-AV1 arguments that shape the encode, for
+// recording in the output: quality, tune, screen content mode, scene
+// detection, tiles and the tuning parameters, without input, geometry,
+// color, keyframe or threading arguments.
+func SettingsString(cfg *EncConfig) string {
+	args := []string{
+		"--crf", fmt.Sprintf("%.0f", cfg.CRF),
+		"--preset", fmt.Sprintf("%d", cfg.Preset),
+		"--tune", fmt.Sprintf("%d", cfg.Tune),
+		"--scm", fmt.Sprintf("%d", cfg.SCM),
+		"--scd", boolFlag(!cfg.DisableSCD),
+		"--tile-rows", fmt.Sprintf("%d", tileLog2(cfg.TileRows)),
+		"--tile-columns", fmt.Sprintf("%d", tileLog2(cfg.TileColumns)),
+	}
+	return strings.Join(append(args, tuningArgs(cfg)...), " ")
+}
+
+// boolFlag formats a boolean as an SvtAv1EncApp 0/1 argument.
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
 // SvtParamsDisplay returns a human-readable colon-separated string of key SVT-AV1 parameters
 // for display purposes (similar to FFmpeg's -svtav1-params format).
-func SvtParamsDisplay(acBias float32, enableVarianceBoost bool, tune uint8) string {
+func SvtParamsDisplay(cfg *EncConfig) string {
 	params := []string{
-		fmt.Sprintf("ac-bias=%g", acBias),
+		fmt.Sprintf("ac-bias=%g", cfg.ACBias),
+		"enable-variance-boost=" + boolFlag(cfg.EnableVarianceBoost),
 	}
 
-	if enableVarianceBoost {
-		params = append(params, "enable-variance-boost=1")
-	} else {
-		params = append(params, "enable-variance-boost=0")
+	keyintSecs := cfg.KeyintSecs
+	if keyintSecs == 0 {
+		keyintSecs = 10
 	}
-
 	params = append(params,
-		fmt.Sprintf("tune=%d", tune),
-		"keyint=10s",
-		"scd=1",
-		"scm=0",
+		fmt.Sprintf("tune=%d", cfg.Tune),
+		fmt.Sprintf("keyint=%gs", keyintSecs),
+		"scd="+boolFlag(!cfg.DisableSCD),
+		fmt.Sprintf("scm=%d", cfg.SCM),
 	)
+	if cfg.TileRows > 1 || cfg.TileColumns > 1 {
+		params = append(params, fmt.Sprintf("tiles=%dx%d", max(cfg.TileRows, 1), max(cfg.TileColumns, 1)))
+	}
 
 	return strings.Join(params, ":")
 }
 
-// IsSvtAvailable checks if SvtAv1EncApp is available in PATH.
-func IsSvtAvailable() bool {
-	_, err := exec.LookPath(svtEncBinary)
+// IsSvtAvailable checks if SvtAv1EncApp is available, at its pinned path or
+// in PATH.
+func IsSvtAvailable(paths toolpath.Paths) bool {
+	_, err := exec.LookPath(paths.Path(toolpath.SvtAv1EncApp))
 	return err == nil
 }
 
 // GetSvtPath returns the path to SvtAv1EncApp if available.
-func GetSvtPath() (string, error) {
-	return exec.LookPath(svtEncBinary)
+func GetSvtPath(paths toolpath.Paths) (string, error) {
+	return exec.LookPath(paths.Path(toolpath.SvtAv1EncApp))
 }
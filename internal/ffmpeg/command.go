@@ -11,6 +11,15 @@ type EncodeParams struct {
 	MatrixCoefficients string
 }
 
+// AudioBitrate returns the audio bitrate in kbps for a stream, using
+// kbpsPerChannel when set and the CalculateAudioBitrate table otherwise.
+func AudioBitrate(channels, kbpsPerChannel uint32) uint32 {
+	if kbpsPerChannel > 0 {
+		return channels * kbpsPerChannel
+	}
+	return CalculateAudioBitrate(channels)
+}
+
 // CalculateAudioBitrate returns audio bitrate in kbps based on channel count.
 func CalculateAudioBitrate(channels uint32) uint32 {
 	switch channels {
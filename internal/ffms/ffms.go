@@ -30,11 +30,21 @@ static void free_error_info(FFMS_ErrorInfo* err) {
 static const char* get_error_message(FFMS_ErrorInfo* err) {
 	return err->Buffer;
 }
+
+// Defined in progress.go
+extern int reelIndexProgress(int64_t current, int64_t total, uintptr_t handle);
+
+// Helper to route indexing progress to the Go callback behind handle
+static void set_index_progress(FFMS_Indexer* indexer, uintptr_t handle) {
+	FFMS_SetProgressCallback(indexer, (TIndexCallback)reelIndexProgress, (void*)handle);
+}
 */
 import "C"
 
 import (
+	"context"
 	"fmt"
+	"runtime/cgo"
 	"sync"
 	"unsafe"
 )
@@ -48,10 +58,17 @@ func Init() {
 	})
 }
 
+// Version returns the FFMS2 library version as major.minor.micro.bump.
+func Version() string {
+	v := int(C.FFMS_GetVersion())
+	return fmt.Sprintf("%d.%d.%d.%d", v>>24&0xff, v>>16&0xff, v>>8&0xff, v&0xff)
+}
+
 // VidIdx wraps an FFMS_Index pointer.
 type VidIdx struct {
 	ptr       *C.FFMS_Index
 	videoPath string
+	track     int // Video track to decode (-1 = the first)
 }
 
 // VidSrc wraps an FFMS_VideoSource pointer.
@@ -70,6 +87,7 @@ type VidInf struct {
 	TransferCharacteristics *int32
 	MatrixCoefficients      *int32
 	Is10Bit                 bool
+	Output8Bit              bool // Frames are extracted as 8-bit instead of 10-bit
 	MasteringDisplay        *string
 	ContentLight            *string
 	PixelFormat             int
@@ -122,8 +140,20 @@ type CropCalc struct {
 	CropH    uint32 // Horizontal crop amount (left/right)
 }
 
-// NewVidIdx creates a new video index for the given file path.
-func NewVidIdx(path string, showProgress bool) (*VidIdx, error) {
+// NewVidIdx creates a new video index for the given file path. A non-nil
+// progress is called as indexing reads through the file. Indexing stops
+// when ctx is cancelled.
+func NewVidIdx(ctx context.Context, path string, progress IndexProgress) (*VidIdx, error) {
+	return newVidIdx(ctx, path, progress, C.FFMS_IEH_ABORT)
+}
+
+// NewGrowingVidIdx indexes a file that is still being written. A truncated
+// packet at the end ends its track there instead of failing the index.
+func NewGrowingVidIdx(ctx context.Context, path string) (*VidIdx, error) {
+	return newVidIdx(ctx, path, nil, C.FFMS_IEH_STOP_TRACK)
+}
+
+func newVidIdx(ctx context.Context, path string, progress IndexProgress, errorHandling C.int) (*VidIdx, error) {
 	Init()
 
 	errInfo := C.create_error_info()
@@ -141,13 +171,88 @@ func NewVidIdx(path string, showProgress bool) (*VidIdx, error) {
 	// Index all tracks
 	C.FFMS_TrackIndexSettings(indexer, -1, 1, 0)
 
+	h := cgo.NewHandle(&indexing{ctx: ctx, progress: progress})
+	defer h.Delete()
+	C.set_index_progress(indexer, C.uintptr_t(h))
+
 	// Run indexing
-	idx := C.FFMS_DoIndexing2(indexer, C.int(0), errInfo)
+	idx := C.FFMS_DoIndexing2(indexer, errorHandling, errInfo)
 	if idx == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to index: %s", C.GoString(C.get_error_message(errInfo)))
 	}
 
-	return &VidIdx{ptr: idx, videoPath: path}, nil
+	return &VidIdx{ptr: idx, videoPath: path, track: -1}, nil
+}
+
+// ReadVidIdx loads an index saved by Write, failing if it was made for a
+// different file.
+func ReadVidIdx(path, indexPath string) (*VidIdx, error) {
+	Init()
+
+	errInfo := C.create_error_info()
+	defer C.free_error_info(errInfo)
+
+	cIndexPath := C.CString(indexPath)
+	defer C.free(unsafe.Pointer(cIndexPath))
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	idx := C.FFMS_ReadIndex(cIndexPath, errInfo)
+	if idx == nil {
+		return nil, fmt.Errorf("failed to read index: %s", C.GoString(C.get_error_message(errInfo)))
+	}
+	if C.FFMS_IndexBelongsToFile(idx, cPath, errInfo) != 0 {
+		C.FFMS_DestroyIndex(idx)
+		return nil, fmt.Errorf("index doesn't match %s: %s", path, C.GoString(C.get_error_message(errInfo)))
+	}
+
+	return &VidIdx{ptr: idx, videoPath: path, track: -1}, nil
+}
+
+// Write saves the index so a later run can load it with ReadVidIdx.
+func (v *VidIdx) Write(indexPath string) error {
+	errInfo := C.create_error_info()
+	defer C.free_error_info(errInfo)
+
+	cIndexPath := C.CString(indexPath)
+	defer C.free(unsafe.Pointer(cIndexPath))
+
+	if C.FFMS_WriteIndex(cIndexPath, v.ptr, errInfo) != 0 {
+		return fmt.Errorf("failed to write index: %s", C.GoString(C.get_error_message(errInfo)))
+	}
+	return nil
+}
+
+// SelectTrack makes GetVidInf and ThrVidSrc decode the video track with
+// container index track instead of the first video track.
+func (v *VidIdx) SelectTrack(track int) {
+	v.track = track
+}
+
+// Path returns the path of the indexed video file.
+func (v *VidIdx) Path() string {
+	return v.videoPath
+}
+
+// Track returns the container index of the selected video track, or -1 for
+// the first video track.
+func (v *VidIdx) Track() int {
+	return v.track
+}
+
+// videoTrack returns the number of the video track to decode.
+func (v *VidIdx) videoTrack(errInfo *C.FFMS_ErrorInfo) (C.int, error) {
+	if v.track >= 0 {
+		return C.int(v.track), nil
+	}
+	trackNum := C.FFMS_GetFirstTrackOfType(v.ptr, C.FFMS_TYPE_VIDEO, errInfo)
+	if trackNum < 0 {
+		return 0, fmt.Errorf("no video track found: %s", C.GoString(C.get_error_message(errInfo)))
+	}
+	return trackNum, nil
 }
 
 // Close releases the index resources.
@@ -168,9 +273,9 @@ func GetVidInf(idx *VidIdx) (*VidInf, error) {
 	defer C.free_error_info(errInfo)
 
 	// Get video track number
-	trackNum := C.FFMS_GetFirstTrackOfType(idx.ptr, C.FFMS_TYPE_VIDEO, errInfo)
-	if trackNum < 0 {
-		return nil, fmt.Errorf("no video track found: %s", C.GoString(C.get_error_message(errInfo)))
+	trackNum, err := idx.videoTrack(errInfo)
+	if err != nil {
+		return nil, err
 	}
 
 	cPath := C.CString(idx.videoPath)
@@ -332,9 +437,9 @@ func ThrVidSrc(idx *VidIdx, threads int) (*VidSrc, error) {
 	defer C.free_error_info(errInfo)
 
 	// Get video track number
-	trackNum := C.FFMS_GetFirstTrackOfType(idx.ptr, C.FFMS_TYPE_VIDEO, errInfo)
-	if trackNum < 0 {
-		return nil, fmt.Errorf("no video track found: %s", C.GoString(C.get_error_message(errInfo)))
+	trackNum, err := idx.videoTrack(errInfo)
+	if err != nil {
+		return nil, err
 	}
 
 	cPath := C.CString(idx.videoPath)
@@ -358,8 +463,9 @@ func (v *VidSrc) Close() {
 }
 
 // ExtractFrame extracts a single frame from the video source.
-// Output is always 10-bit YUV420 (16-bit little-endian per sample).
-// 8-bit sources are converted to 10-bit by left-shifting by 2.
+// Output is 10-bit YUV420 (16-bit little-endian per sample), or 8-bit YUV420
+// when inf.Output8Bit is set. 8-bit sources are converted to 10-bit by
+// left-shifting by 2, and 10-bit sources to 8-bit by rounding off 2 bits.
 func ExtractFrame(src *VidSrc, frameIdx int, output []byte, inf *VidInf, strat DecodeStrat, cropCalc *CropCalc) error {
 	if src == nil || src.ptr == nil {
 		return fmt.Errorf("nil video source")
@@ -374,6 +480,12 @@ func ExtractFrame(src *VidSrc, frameIdx int, output []byte, inf *VidInf, strat D
 		return fmt.Errorf("failed to get frame %d: %s", frameIdx, C.GoString(C.get_error_message(errInfo)))
 	}
 
+	// Planes are read at the first frame's size, so a size change would overrun them
+	if uint32(frame.EncodedWidth) != inf.Width || uint32(frame.EncodedHeight) != inf.Height {
+		return fmt.Errorf("frame %d is %dx%d, but the video starts at %dx%d; variable-resolution sources are not supported",
+			frameIdx, frame.EncodedWidth, frame.EncodedHeight, inf.Width, inf.Height)
+	}
+
 	// Extract data based on strategy
 	width := inf.Width
 	height := inf.Height
@@ -382,10 +494,14 @@ func ExtractFrame(src *VidSrc, frameIdx int, output []byte, inf *VidInf, strat D
 		height = cropCalc.NewH
 	}
 
-	// Output is always 10-bit (16 bits per sample)
-	yPlaneSize := int(width) * int(height) * 2       // Y: 2 bytes per pixel
-	uPlaneSize := int(width) * int(height) / 4 * 2   // U: 1/4 pixels, 2 bytes each
-	vPlaneSize := int(width) * int(height) / 4 * 2   // V: 1/4 pixels, 2 bytes each
+	// 10-bit output has 16 bits per sample, 8-bit output 8
+	bytesPerSample := 2
+	if inf.Output8Bit {
+		bytesPerSample = 1
+	}
+	yPlaneSize := int(width) * int(height) * bytesPerSample     // Y: 1 sample per pixel
+	uPlaneSize := int(width) * int(height) / 4 * bytesPerSample // U: 1/4 pixels
+	vPlaneSize := int(width) * int(height) / 4 * bytesPerSample // V: 1/4 pixels
 
 	expectedSize := yPlaneSize + uPlaneSize + vPlaneSize
 	if len(output) < expectedSize {
@@ -400,7 +516,28 @@ func ExtractFrame(src *VidSrc, frameIdx int, output []byte, inf *VidInf, strat D
 	uData := unsafe.Slice((*byte)(unsafe.Pointer(frame.Data[1])), int(frame.Linesize[1])*int(inf.Height/2))
 	vData := unsafe.Slice((*byte)(unsafe.Pointer(frame.Data[2])), int(frame.Linesize[2])*int(inf.Height/2))
 
-	if inf.Is10Bit {
+	if inf.Output8Bit {
+		srcYStride := int(frame.Linesize[0])
+		srcUVStride := int(frame.Linesize[1])
+		planes := []struct {
+			dst, src      []byte
+			width, height int
+			stride        int
+		}{
+			{output[:yPlaneSize], yData, int(width), int(height), srcYStride},
+			{output[yPlaneSize : yPlaneSize+uPlaneSize], uData, int(width / 2), int(height / 2), srcUVStride},
+			{output[yPlaneSize+uPlaneSize:], vData, int(width / 2), int(height / 2), srcUVStride},
+		}
+		for _, p := range planes {
+			if inf.Is10Bit {
+				// Source is 10-bit, round to 8-bit
+				convert10to8bit(p.dst, p.src, p.width, p.height, p.stride)
+			} else {
+				// Source is 8-bit, copy directly
+				copyPlane10bit(p.dst, p.src, p.height, p.width, p.stride)
+			}
+		}
+	} else if inf.Is10Bit {
 		// Source is 10-bit, copy directly
 		srcYStride := int(frame.Linesize[0])
 		srcUVStride := int(frame.Linesize[1])
@@ -465,6 +602,20 @@ func convert8to10bit(dst, src []byte, width, height, srcStride int) {
 	}
 }
 
+// convert10to8bit converts 10-bit YUV data (16-bit little-endian per
+// sample) to 8-bit by dropping the low 2 bits with rounding.
+func convert10to8bit(dst, src []byte, width, height, srcStride int) {
+	dstOff := 0
+	for row := 0; row < height; row++ {
+		srcRowStart := row * srcStride
+		for col := 0; col < width; col++ {
+			sample10 := uint16(src[srcRowStart+2*col]) | uint16(src[srcRowStart+2*col+1])<<8
+			dst[dstOff] = byte(min((sample10+2)>>2, 255))
+			dstOff++
+		}
+	}
+}
+
 // copyPlaneCropped copies plane data with cropping.
 func copyPlaneCropped(dst, src []byte, rows, startOffset, rowLen, stride int) {
 	srcOff := startOffset
@@ -488,8 +639,9 @@ func Calc8BitSize(w, h uint32) int {
 	return int(w) * int(h) * 3 / 2
 }
 
-// CalcFrameSize returns the buffer size needed for a frame given video info.
-// Always returns 10-bit size since we convert 8-bit sources to 10-bit for encoding.
+// CalcFrameSize returns the buffer size needed for a frame given video info:
+// the 10-bit size, which 8-bit sources are converted to, unless frames are
+// extracted as 8-bit.
 func CalcFrameSize(inf *VidInf, cropCalc *CropCalc) int {
 	w := inf.Width
 	h := inf.Height
@@ -498,7 +650,9 @@ func CalcFrameSize(inf *VidInf, cropCalc *CropCalc) int {
 		h = cropCalc.NewH
 	}
 
-	// Always use 10-bit size - 8-bit sources are converted to 10-bit
+	if inf.Output8Bit {
+		return Calc8BitSize(w, h)
+	}
 	return CalcPackedSize(w, h)
 }
 
@@ -0,0 +1,37 @@
+package ffms
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"context"
+	"runtime/cgo"
+)
+
+// IndexProgress receives how many bytes of the file indexing has read so
+// far, out of total.
+type IndexProgress func(current, total int64)
+
+// indexing is what the indexing callback reports to and checks.
+type indexing struct {
+	ctx      context.Context
+	progress IndexProgress // May be nil
+}
+
+// reelIndexProgress is the FFMS2 indexing callback. It lives apart from
+// ffms.go because a file with exports may only declare, not define, C
+// functions in its preamble.
+//
+//export reelIndexProgress
+func reelIndexProgress(current, total C.int64_t, handle C.uintptr_t) C.int {
+	ix := cgo.Handle(handle).Value().(*indexing)
+	if ix.progress != nil {
+		ix.progress(int64(current), int64(total))
+	}
+	if ix.ctx.Err() != nil {
+		return 1 // Cancels indexing
+	}
+	return 0
+}
@@ -2,13 +2,28 @@
 package ffprobe
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/toolpath"
 )
 
+// ErrNoVideo indicates an input without an encodable video stream, such as
+// an audio-only file, a still image, or a file whose only picture is cover art.
+var ErrNoVideo = errors.New("no encodable video stream")
+
+// stillImageCodecs carry single pictures rather than video when they have
+// at most one frame.
+var stillImageCodecs = map[string]bool{
+	"png": true, "mjpeg": true, "bmp": true, "tiff": true, "webp": true, "gif": true,
+}
+
 // MediaInfo contains basic media information.
 type MediaInfo struct {
 	Duration    float64
@@ -23,6 +38,9 @@ type VideoProperties struct {
 	Height       uint32
 	DurationSecs float64
 	HDRInfo      HDRInfo
+	StreamIndex  int   // Container index of the selected video stream
+	VideoIndex   int   // Position among the video streams, as in ffmpeg's 0:v:N
+	CoverArt     []int // Container indexes of attached pictures
 }
 
 // HDRInfo contains HDR-related information.
@@ -36,12 +54,31 @@ type HDRInfo struct {
 
 // AudioStreamInfo contains information about an audio stream.
 type AudioStreamInfo struct {
-	Channels    uint32
-	CodecName   string
-	Profile     string
-	Index       int
-	IsSpatial   bool // Always false (spatial support removed)
-	Disposition StreamDisposition
+	Channels      uint32
+	ChannelLayout string // ffmpeg layout name, e.g. "5.1(side)"; "" when unknown
+	CodecName     string
+	Profile       string
+	Index         int
+	Language      string
+	Title         string
+	IsSpatial     bool // Always false (spatial support removed)
+	Disposition   StreamDisposition
+}
+
+// LosslessFormat names the stream's lossless format as used by audio
+// passthrough ("truehd", "dts-hd", "flac", "alac" or "pcm"), or returns ""
+// for lossy streams. Only DTS-HD Master Audio counts as dts-hd.
+func (s AudioStreamInfo) LosslessFormat() string {
+	codec := strings.ToLower(s.CodecName)
+	switch {
+	case codec == "truehd", codec == "flac", codec == "alac":
+		return codec
+	case codec == "dts" && strings.HasPrefix(s.Profile, "DTS-HD MA"):
+		return "dts-hd"
+	case strings.HasPrefix(codec, "pcm_"):
+		return "pcm"
+	}
+	return ""
 }
 
 // StreamDisposition contains stream disposition flags.
@@ -67,16 +104,20 @@ type ffprobeOutput struct {
 }
 
 type ffprobeFormat struct {
-	Duration string `json:"duration"`
+	Duration string            `json:"duration"`
+	BitRate  string            `json:"bit_rate"`
+	Tags     map[string]string `json:"tags"`
 }
 
 type ffprobeStream struct {
+	Index            int               `json:"index"`
 	CodecType        string            `json:"codec_type"`
 	CodecName        string            `json:"codec_name"`
 	Profile          string            `json:"profile"`
 	Width            int64             `json:"width"`
 	Height           int64             `json:"height"`
 	Channels         int               `json:"channels"`
+	ChannelLayout    string            `json:"channel_layout"`
 	NbFrames         string            `json:"nb_frames"`
 	PixFmt           string            `json:"pix_fmt"`
 	ColorPrimaries   string            `json:"color_primaries"`
@@ -84,11 +125,17 @@ type ffprobeStream struct {
 	ColorSpace       string            `json:"color_space"`
 	BitsPerRawSample string            `json:"bits_per_raw_sample"`
 	Disposition      StreamDisposition `json:"disposition"`
+	Tags             streamTags        `json:"tags"`
+}
+
+type streamTags struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
 }
 
 // runFFprobe executes ffprobe and returns the parsed output.
-func runFFprobe(inputPath string) (*ffprobeOutput, error) {
-	cmd := exec.Command("ffprobe",
+func runFFprobe(paths toolpath.Paths, inputPath string) (*ffprobeOutput, error) {
+	cmd := exec.Command(paths.Path(toolpath.FFprobe),
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
@@ -96,7 +143,7 @@ func runFFprobe(inputPath string) (*ffprobeOutput, error) {
 		inputPath,
 	)
 
-	output, err := cmd.Output()
+	output, err := cmdlog.Output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("ffprobe failed: %w", err)
 	}
@@ -110,8 +157,8 @@ func runFFprobe(inputPath string) (*ffprobeOutput, error) {
 }
 
 // GetMediaInfo returns basic media information for a file.
-func GetMediaInfo(inputPath string) (*MediaInfo, error) {
-	probe, err := runFFprobe(inputPath)
+func GetMediaInfo(paths toolpath.Paths, inputPath string) (*MediaInfo, error) {
+	probe, err := runFFprobe(paths, inputPath)
 	if err != nil {
 		return nil, err
 	}
@@ -142,9 +189,17 @@ func GetMediaInfo(inputPath string) (*MediaInfo, error) {
 	return info, nil
 }
 
-// GetVideoProperties returns video properties including HDR info.
-func GetVideoProperties(inputPath string) (*VideoProperties, error) {
-	probe, err := runFFprobe(inputPath)
+// GetVideoProperties returns video properties including HDR info, for the
+// video stream picked by selectVideoStream.
+func GetVideoProperties(paths toolpath.Paths, inputPath string) (*VideoProperties, error) {
+	return GetVideoStreamProperties(paths, inputPath, -1)
+}
+
+// GetVideoStreamProperties returns video properties including HDR info for
+// the videoStream-th video stream, counted from 0, or for the stream picked
+// by selectVideoStream when videoStream is negative.
+func GetVideoStreamProperties(paths toolpath.Paths, inputPath string, videoStream int) (*VideoProperties, error) {
+	probe, err := runFFprobe(paths, inputPath)
 	if err != nil {
 		return nil, err
 	}
@@ -159,27 +214,24 @@ func GetVideoProperties(inputPath string) (*VideoProperties, error) {
 		}
 	}
 
-	// Find video stream
-	var videoStream *ffprobeStream
-	for i := range probe.Streams {
-		if probe.Streams[i].CodecType == "video" {
-			videoStream = &probe.Streams[i]
-			break
-		}
+	var stream *ffprobeStream
+	if videoStream >= 0 {
+		stream, err = nthVideoStream(probe, videoStream)
+	} else {
+		stream, err = selectVideoStream(probe)
 	}
-
-	if videoStream == nil {
-		return nil, fmt.Errorf("no video stream found in %s", inputPath)
+	if err != nil {
+		return nil, err
 	}
 
-	if videoStream.Width <= 0 || videoStream.Height <= 0 {
-		return nil, fmt.Errorf("invalid dimensions in %s: %dx%d", inputPath, videoStream.Width, videoStream.Height)
+	if stream.Width <= 0 || stream.Height <= 0 {
+		return nil, fmt.Errorf("invalid dimensions in %s: %dx%d", inputPath, stream.Width, stream.Height)
 	}
 
 	// Parse bit depth
 	var bitDepth *uint8
-	if videoStream.BitsPerRawSample != "" {
-		if bd, err := strconv.ParseUint(videoStream.BitsPerRawSample, 10, 8); err == nil {
+	if stream.BitsPerRawSample != "" {
+		if bd, err := strconv.ParseUint(stream.BitsPerRawSample, 10, 8); err == nil {
 			bdVal := uint8(bd)
 			bitDepth = &bdVal
 		}
@@ -187,24 +239,120 @@ func GetVideoProperties(inputPath string) (*VideoProperties, error) {
 
 	// Detect HDR from color metadata
 	hdrInfo := HDRInfo{
-		ColourPrimaries:         videoStream.ColorPrimaries,
-		TransferCharacteristics: videoStream.ColorTransfer,
-		MatrixCoefficients:      videoStream.ColorSpace,
+		ColourPrimaries:         stream.ColorPrimaries,
+		TransferCharacteristics: stream.ColorTransfer,
+		MatrixCoefficients:      stream.ColorSpace,
 		BitDepth:                bitDepth,
-		IsHDR:                   detectHDR(videoStream.ColorPrimaries, videoStream.ColorTransfer, videoStream.ColorSpace),
+		IsHDR:                   detectHDR(stream.ColorPrimaries, stream.ColorTransfer, stream.ColorSpace),
 	}
 
 	return &VideoProperties{
-		Width:        uint32(videoStream.Width),
-		Height:       uint32(videoStream.Height),
+		Width:        uint32(stream.Width),
+		Height:       uint32(stream.Height),
 		DurationSecs: durationSecs,
 		HDRInfo:      hdrInfo,
+		StreamIndex:  stream.Index,
+		VideoIndex:   videoPosition(probe, stream),
+		CoverArt:     coverArt(probe),
 	}, nil
 }
 
+// selectVideoStream returns the primary video stream: the first encodable
+// one flagged default, or the first encodable one when none is. Cover art
+// and still images are passed over. Errors wrap ErrNoVideo with the reason
+// when there is none.
+func selectVideoStream(probe *ffprobeOutput) (*ffprobeStream, error) {
+	var coverArt, audio bool
+	var first *ffprobeStream
+	var still string
+	for i := range probe.Streams {
+		s := &probe.Streams[i]
+		switch {
+		case s.CodecType == "audio":
+			audio = true
+		case s.CodecType != "video":
+		case s.Disposition.AttachedPic == 1:
+			coverArt = true
+		case stillImageCodecs[s.CodecName] && isSingleFrame(s.NbFrames):
+			if still == "" {
+				still = s.CodecName
+			}
+		case s.Disposition.Default == 1:
+			return s, nil
+		case first == nil:
+			first = s
+		}
+	}
+
+	switch {
+	case first != nil:
+		return first, nil
+	case still != "":
+		return nil, fmt.Errorf("%w: still image (%s)", ErrNoVideo, still)
+	case coverArt:
+		return nil, fmt.Errorf("%w: only cover art", ErrNoVideo)
+	case audio:
+		return nil, fmt.Errorf("%w: audio only", ErrNoVideo)
+	default:
+		return nil, ErrNoVideo
+	}
+}
+
+// nthVideoStream returns the n-th video stream, counted from 0, for an
+// explicit selection. Cover art can't be selected.
+func nthVideoStream(probe *ffprobeOutput, n int) (*ffprobeStream, error) {
+	count := 0
+	for i := range probe.Streams {
+		s := &probe.Streams[i]
+		if s.CodecType != "video" {
+			continue
+		}
+		if count == n {
+			if s.Disposition.AttachedPic == 1 {
+				return nil, fmt.Errorf("video stream %d is cover art", n)
+			}
+			return s, nil
+		}
+		count++
+	}
+	return nil, fmt.Errorf("video stream %d does not exist (source has %d)", n, count)
+}
+
+// videoPosition returns the position of s among the video streams.
+func videoPosition(probe *ffprobeOutput, s *ffprobeStream) int {
+	n := 0
+	for i := range probe.Streams {
+		if &probe.Streams[i] == s {
+			break
+		}
+		if probe.Streams[i].CodecType == "video" {
+			n++
+		}
+	}
+	return n
+}
+
+// coverArt returns the container indexes of attached pictures.
+func coverArt(probe *ffprobeOutput) []int {
+	var indexes []int
+	for _, s := range probe.Streams {
+		if s.Disposition.AttachedPic == 1 {
+			indexes = append(indexes, s.Index)
+		}
+	}
+	return indexes
+}
+
+// isSingleFrame reports whether a stream's frame count is at most one.
+// Image demuxers often leave nb_frames unset, which also counts.
+func isSingleFrame(nbFrames string) bool {
+	n, err := strconv.ParseUint(nbFrames, 10, 64)
+	return err != nil || n <= 1
+}
+
 // GetAudioChannels returns the channel count for each audio stream.
-func GetAudioChannels(inputPath string) ([]uint32, error) {
-	probe, err := runFFprobe(inputPath)
+func GetAudioChannels(paths toolpath.Paths, inputPath string) ([]uint32, error) {
+	probe, err := runFFprobe(paths, inputPath)
 	if err != nil {
 		return nil, err
 	}
@@ -220,8 +368,8 @@ func GetAudioChannels(inputPath string) ([]uint32, error) {
 }
 
 // GetAudioStreamInfo returns detailed audio stream information.
-func GetAudioStreamInfo(inputPath string) ([]AudioStreamInfo, error) {
-	probe, err := runFFprobe(inputPath)
+func GetAudioStreamInfo(paths toolpath.Paths, inputPath string) ([]AudioStreamInfo, error) {
+	probe, err := runFFprobe(paths, inputPath)
 	if err != nil {
 		return nil, err
 	}
@@ -239,12 +387,15 @@ func GetAudioStreamInfo(inputPath string) ([]AudioStreamInfo, error) {
 		}
 
 		streams = append(streams, AudioStreamInfo{
-			Channels:    uint32(stream.Channels),
-			CodecName:   stream.CodecName,
-			Profile:     stream.Profile,
-			Index:       audioIndex,
-			IsSpatial:   false, // Spatial audio support removed
-			Disposition: stream.Disposition,
+			Channels:      uint32(stream.Channels),
+			ChannelLayout: stream.ChannelLayout,
+			CodecName:     stream.CodecName,
+			Profile:       stream.Profile,
+			Index:         audioIndex,
+			Language:      stream.Tags.Language,
+			Title:         stream.Tags.Title,
+			IsSpatial:     false, // Spatial audio support removed
+			Disposition:   stream.Disposition,
 		})
 
 		audioIndex++
@@ -279,8 +430,8 @@ func containsCI(s, substr string) bool {
 }
 
 // GetVideoCodecName returns the video codec name for a file.
-func GetVideoCodecName(inputPath string) (string, error) {
-	probe, err := runFFprobe(inputPath)
+func GetVideoCodecName(paths toolpath.Paths, inputPath string) (string, error) {
+	probe, err := runFFprobe(paths, inputPath)
 	if err != nil {
 		return "", err
 	}
@@ -293,3 +444,180 @@ func GetVideoCodecName(inputPath string) (string, error) {
 
 	return "", fmt.Errorf("no video stream found in %s", inputPath)
 }
+
+// StreamInfo describes a single stream of any type.
+type StreamInfo struct {
+	Index     int
+	CodecType string
+	CodecName string
+	Width     int64
+	Height    int64
+	Channels  int
+	Language  string
+	Title     string
+}
+
+// GetStreams returns every stream in the file in container order.
+func GetStreams(paths toolpath.Paths, inputPath string) ([]StreamInfo, error) {
+	probe, err := runFFprobe(paths, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]StreamInfo, 0, len(probe.Streams))
+	for i, stream := range probe.Streams {
+		streams = append(streams, StreamInfo{
+			Index:     i,
+			CodecType: stream.CodecType,
+			CodecName: stream.CodecName,
+			Width:     stream.Width,
+			Height:    stream.Height,
+			Channels:  stream.Channels,
+			Language:  stream.Tags.Language,
+			Title:     stream.Tags.Title,
+		})
+	}
+	return streams, nil
+}
+
+// CountAttachments returns the number of attachment streams, such as the
+// fonts Matroska files embed for ASS subtitles. Cover art is an attached
+// picture, not an attachment, and isn't counted.
+func CountAttachments(paths toolpath.Paths, inputPath string) (int, error) {
+	probe, err := runFFprobe(paths, inputPath)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "attachment" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetFormatBitrate returns the overall container bitrate in bits per second.
+func GetFormatBitrate(paths toolpath.Paths, inputPath string) (uint64, error) {
+	probe, err := runFFprobe(paths, inputPath)
+	if err != nil {
+		return 0, err
+	}
+	if probe.Format.BitRate == "" {
+		return 0, fmt.Errorf("no bitrate reported for %s", inputPath)
+	}
+	return strconv.ParseUint(probe.Format.BitRate, 10, 64)
+}
+
+// GetFormatTags returns the container-level metadata tags. Keys are
+// upper-cased, since containers differ in the case they store them in.
+func GetFormatTags(paths toolpath.Paths, inputPath string) (map[string]string, error) {
+	probe, err := runFFprobe(paths, inputPath)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(probe.Format.Tags))
+	for k, v := range probe.Format.Tags {
+		tags[strings.ToUpper(k)] = v
+	}
+	return tags, nil
+}
+
+// PacketSample is the timestamp and size of a single video packet.
+type PacketSample struct {
+	Time float64
+	Size uint64
+}
+
+// GetVideoPackets returns the timestamp and size of every packet in the first
+// video stream. Output is streamed so long files don't need to be buffered.
+func GetVideoPackets(paths toolpath.Paths, inputPath string) ([]PacketSample, error) {
+	cmd := exec.Command(paths.Path(toolpath.FFprobe),
+		"-v", "quiet",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,size",
+		"-of", "csv=p=0",
+		inputPath,
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ffprobe pipe: %w", err)
+	}
+	proc, err := cmdlog.Start(cmd, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var packets []PacketSample
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) < 2 {
+			continue
+		}
+		t, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		packets = append(packets, PacketSample{Time: t, Size: size})
+	}
+
+	if err := proc.Wait(); err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	return packets, nil
+}
+
+// FrameSize is the decoded size of a frame at a timestamp.
+type FrameSize struct {
+	Time   float64
+	Width  uint32
+	Height uint32
+}
+
+// SampleFrameSizes decodes a few frames from the videoIndex-th video stream
+// at each of the given times (seconds) and returns their sizes.
+func SampleFrameSizes(paths toolpath.Paths, inputPath string, videoIndex int, times []float64) ([]FrameSize, error) {
+	intervals := make([]string, len(times))
+	for i, t := range times {
+		intervals[i] = fmt.Sprintf("%.3f%%+#2", t)
+	}
+
+	cmd := exec.Command(paths.Path(toolpath.FFprobe),
+		"-v", "quiet",
+		"-select_streams", fmt.Sprintf("v:%d", videoIndex),
+		"-read_intervals", strings.Join(intervals, ","),
+		"-show_entries", "frame=best_effort_timestamp_time,width,height",
+		"-of", "json",
+		inputPath,
+	)
+	output, err := cmdlog.Output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Frames []struct {
+			Time   string `json:"best_effort_timestamp_time"`
+			Width  uint32 `json:"width"`
+			Height uint32 `json:"height"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	sizes := make([]FrameSize, 0, len(result.Frames))
+	for _, f := range result.Frames {
+		t, _ := strconv.ParseFloat(f.Time, 64)
+		sizes = append(sizes, FrameSize{Time: t, Width: f.Width, Height: f.Height})
+	}
+	return sizes, nil
+}
@@ -0,0 +1,102 @@
+package ffprobe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSelectVideoStream(t *testing.T) {
+	video := ffprobeStream{CodecType: "video", CodecName: "h264", NbFrames: "1440"}
+	audio := ffprobeStream{CodecType: "audio", CodecName: "flac"}
+	cover := ffprobeStream{CodecType: "video", CodecName: "mjpeg", Disposition: StreamDisposition{AttachedPic: 1}}
+	motionJPEG := ffprobeStream{CodecType: "video", CodecName: "mjpeg", NbFrames: "900"}
+	image := ffprobeStream{CodecType: "video", CodecName: "png"}
+	angle := ffprobeStream{CodecType: "video", CodecName: "hevc", NbFrames: "1440"}
+	defaultAngle := ffprobeStream{CodecType: "video", CodecName: "vc1", NbFrames: "1440", Disposition: StreamDisposition{Default: 1}}
+
+	tests := []struct {
+		name    string
+		streams []ffprobeStream
+		want    string // codec of the selected stream
+		reason  string // substring of the ErrNoVideo reason
+	}{
+		{"video after cover art", []ffprobeStream{cover, audio, video}, "h264", ""},
+		{"motion jpeg is video", []ffprobeStream{motionJPEG}, "mjpeg", ""},
+		{"first without default flags", []ffprobeStream{video, angle}, "h264", ""},
+		{"default flagged angle", []ffprobeStream{angle, defaultAngle}, "vc1", ""},
+		{"video after still image", []ffprobeStream{image, video}, "h264", ""},
+		{"audio only", []ffprobeStream{audio}, "", "audio only"},
+		{"audio with cover art", []ffprobeStream{audio, cover}, "", "cover art"},
+		{"still image", []ffprobeStream{image}, "", "still image"},
+		{"no streams", nil, "", "no encodable video"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := selectVideoStream(&ffprobeOutput{Streams: tt.streams})
+			if tt.reason != "" {
+				if !errors.Is(err, ErrNoVideo) || !strings.Contains(err.Error(), tt.reason) {
+					t.Errorf("selectVideoStream() error = %v, want ErrNoVideo with %q", err, tt.reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectVideoStream() error = %v", err)
+			}
+			if s.CodecName != tt.want {
+				t.Errorf("selectVideoStream() = %s, want %s", s.CodecName, tt.want)
+			}
+		})
+	}
+}
+
+func TestNthVideoStream(t *testing.T) {
+	probe := &ffprobeOutput{Streams: []ffprobeStream{
+		{Index: 0, CodecType: "video", CodecName: "h264"},
+		{Index: 1, CodecType: "audio", CodecName: "ac3"},
+		{Index: 2, CodecType: "video", CodecName: "mjpeg", Disposition: StreamDisposition{AttachedPic: 1}},
+		{Index: 3, CodecType: "video", CodecName: "hevc"},
+	}}
+
+	s, err := nthVideoStream(probe, 2)
+	if err != nil || s.Index != 3 {
+		t.Fatalf("nthVideoStream(2) = %+v, %v; want stream 3", s, err)
+	}
+	if got := videoPosition(probe, s); got != 2 {
+		t.Errorf("videoPosition() = %d, want 2", got)
+	}
+	if _, err := nthVideoStream(probe, 1); err == nil {
+		t.Error("nthVideoStream(1) selected cover art")
+	}
+	if _, err := nthVideoStream(probe, 3); err == nil {
+		t.Error("nthVideoStream(3) selected a missing stream")
+	}
+	if got := coverArt(probe); len(got) != 1 || got[0] != 2 {
+		t.Errorf("coverArt() = %v, want [2]", got)
+	}
+}
+
+func TestLosslessFormat(t *testing.T) {
+	tests := []struct {
+		codec, profile string
+		want           string
+	}{
+		{"truehd", "", "truehd"},
+		{"dts", "DTS-HD MA", "dts-hd"},
+		{"dts", "DTS-HD MA + DTS:X", "dts-hd"},
+		{"dts", "DTS-HD HRA", ""},
+		{"dts", "DTS", ""},
+		{"flac", "", "flac"},
+		{"pcm_s24le", "", "pcm"},
+		{"eac3", "", ""},
+		{"opus", "", ""},
+	}
+
+	for _, tt := range tests {
+		s := AudioStreamInfo{CodecName: tt.codec, Profile: tt.profile}
+		if got := s.LosslessFormat(); got != tt.want {
+			t.Errorf("LosslessFormat(%s, %q) = %q, want %q", tt.codec, tt.profile, got, tt.want)
+		}
+	}
+}
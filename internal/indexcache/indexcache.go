@@ -0,0 +1,146 @@
+// Package indexcache stores FFMS2 indexes between runs, keyed by the content
+// of the indexed file, so repeat encodes of a source skip indexing.
+package indexcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sampleSize is how much of the start and end of a file is hashed for its key.
+const sampleSize = 1 << 20
+
+const (
+	indexExt   = ".ffindex"
+	partialExt = ".partial"
+)
+
+// pruneGrace is how recently an index must have been written or used for
+// Prune to leave it, since another reel may be writing or about to read it.
+// Partial writes older than this were left by a reel that stopped.
+const pruneGrace = 10 * time.Minute
+
+// Key identifies a file's content by hashing its size and its first and last
+// megabyte, which is quick even for very large remuxes. A renamed or moved
+// file keeps its key.
+func Key(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\n", info.Size())
+	if _, err := io.CopyN(h, f, sampleSize); err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if info.Size() > 2*sampleSize {
+		if _, err := f.Seek(-sampleSize, io.SeekEnd); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if _, err := io.Copy(h, f); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32], nil
+}
+
+// Path returns where the index for the file at path is cached in dir.
+func Path(dir, path string) (string, error) {
+	key, err := Key(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+indexExt), nil
+}
+
+// Write saves an index at indexPath with write, which writes it to the path
+// it is given: a temporary file beside indexPath that is renamed into place,
+// so a concurrent reader never sees a half-written index.
+func Write(indexPath string, write func(path string) error) error {
+	f, err := os.CreateTemp(filepath.Dir(indexPath), filepath.Base(indexPath)+".*"+partialExt)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	tmp := f.Name()
+	_ = f.Close()
+	if err := write(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, indexPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+	return nil
+}
+
+// Touch marks a cached index as used so Prune keeps it longest. Call it
+// before reading the index, so Prune leaves it while it is read.
+func Touch(indexPath string) {
+	now := time.Now()
+	_ = os.Chtimes(indexPath, now, now)
+}
+
+// Prune deletes the least recently used indexes in dir until they total at
+// most maxBytes, and partial writes left behind. Indexes written or used in
+// the last pruneGrace are kept even if that leaves more than maxBytes.
+func Prune(dir string, maxBytes uint64) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read index cache: %w", err)
+	}
+
+	type cached struct {
+		path    string
+		size    uint64
+		modTime time.Time
+	}
+	var files []cached
+	var total uint64
+	recent := time.Now().Add(-pruneGrace)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case strings.HasSuffix(e.Name(), partialExt):
+			if info.ModTime().Before(recent) {
+				_ = os.Remove(path)
+			}
+		case strings.HasSuffix(e.Name(), indexExt):
+			files = append(files, cached{path, uint64(info.Size()), info.ModTime()})
+			total += uint64(info.Size())
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= maxBytes || !f.modTime.Before(recent) {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			return fmt.Errorf("failed to prune index cache: %w", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package indexcache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	key := func(path string) string {
+		k, err := Key(path)
+		if err != nil {
+			t.Fatalf("Key(%s) error = %v", path, err)
+		}
+		return k
+	}
+
+	large := bytes.Repeat([]byte("x"), 3*sampleSize)
+	a := write("a.mkv", large)
+	renamed := write("b.mkv", large)
+	if key(a) != key(renamed) {
+		t.Error("identical files should share a key")
+	}
+
+	// A change near the end is caught by the tail sample
+	changed := bytes.Clone(large)
+	changed[len(changed)-10] = 'y'
+	if key(a) == key(write("c.mkv", changed)) {
+		t.Error("files differing near the end should have different keys")
+	}
+
+	if key(write("small.mkv", []byte("abc"))) == key(write("small2.mkv", []byte("abd"))) {
+		t.Error("small files with different content should have different keys")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"old.ffindex", "mid.ffindex", "new.ffindex"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mod := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Prune(dir, 250); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	for name, want := range map[string]bool{"old.ffindex": false, "mid.ffindex": true, "new.ffindex": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", name, exists, want)
+		}
+	}
+}
+
+func TestPruneKeepsRecentIndexes(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	files := map[string]time.Time{
+		"old.ffindex":              old,
+		"writing.ffindex":          time.Now(),
+		"read.ffindex":             time.Now().Add(-time.Minute),
+		"stale.ffindex.1.partial":  old,
+		"active.ffindex.2.partial": time.Now(),
+	}
+	for name, mod := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Over the limit even without old.ffindex, but the rest are in use
+	if err := Prune(dir, 50); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	want := map[string]bool{
+		"old.ffindex":              false,
+		"writing.ffindex":          true,
+		"read.ffindex":             true,
+		"stale.ffindex.1.partial":  false,
+		"active.ffindex.2.partial": true,
+	}
+	for name, want := range want {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", name, exists, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "key.ffindex")
+	if err := os.WriteFile(indexPath, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed write leaves the existing index and no partial file
+	err := Write(indexPath, func(path string) error {
+		if err := os.WriteFile(path, []byte("half"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("Write() succeeded for a failed write")
+	}
+	if data, _ := os.ReadFile(indexPath); string(data) != "old" {
+		t.Errorf("index after failed write = %q, want %q", data, "old")
+	}
+
+	err = Write(indexPath, func(path string) error {
+		if path == indexPath {
+			t.Errorf("index written in place")
+		}
+		return os.WriteFile(path, []byte("new"), 0o644)
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, _ := os.ReadFile(indexPath); string(data) != "new" {
+		t.Errorf("index = %q, want %q", data, "new")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("cache holds %d files, want only the index", len(entries))
+	}
+}
@@ -33,9 +33,10 @@ func GenerateFixedChunks(totalFrames int, fpsNum, fpsDen uint32, chunkDurationSe
 	return keyframes
 }
 
-// ExtractKeyframesIfNeeded generates fixed-length chunks and writes them to scenes.txt if not already present.
+// PlanChunksIfNeeded writes the chunk start frames returned by plan to
+// scenes.txt if not already present, so a resumed encode keeps its layout.
 // Returns the path to the scenes.txt file.
-func ExtractKeyframesIfNeeded(videoPath, workDir string, fpsNum, fpsDen uint32, totalFrames int, chunkDuration float64) (string, error) {
+func PlanChunksIfNeeded(workDir string, plan func() ([]int, error)) (string, error) {
 	sceneFile := filepath.Join(workDir, "scenes.txt")
 
 	// Check if scene file already exists
@@ -43,11 +44,13 @@ func ExtractKeyframesIfNeeded(videoPath, workDir string, fpsNum, fpsDen uint32,
 		return sceneFile, nil
 	}
 
-	// Generate fixed-length chunks
-	keyframes := GenerateFixedChunks(totalFrames, fpsNum, fpsDen, chunkDuration)
+	frames, err := plan()
+	if err != nil {
+		return "", err
+	}
 
 	// Write to scenes.txt
-	if err := writeSceneFile(sceneFile, keyframes); err != nil {
+	if err := writeSceneFile(sceneFile, frames); err != nil {
 		return "", err
 	}
 
@@ -2,13 +2,17 @@
 package logging
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/five82/reel/internal/reporter"
 )
 
 // DefaultLogDir returns the default log directory following XDG Base Directory Spec.
@@ -25,8 +29,17 @@ func DefaultLogDir() string {
 	return filepath.Join(home, ".local", "state", "reel", "logs")
 }
 
+// Log file formats.
+const (
+	FormatText = "text" // Timestamped lines of free text
+	FormatJSON = "json" // JSON lines with time, level, event and message
+)
+
+// Formats lists the accepted --log-format values.
+var Formats = []string{FormatText, FormatJSON}
+
 // level represents the logging level.
-type level int
+type level int32
 
 const (
 	levelInfo level = iota
@@ -35,16 +48,18 @@ const (
 
 // Logger wraps the standard logger with level filtering and file output.
 type Logger struct {
-	level    level
+	level    atomic.Int32
 	logger   *log.Logger
 	file     *os.File
 	filePath string
+	json     bool
 }
 
-// Setup creates a new logger that writes to a timestamped log file.
+// Setup creates a new logger that writes to a timestamped log file in the
+// given format (FormatText or FormatJSON).
 // Returns nil if logging is disabled (noLog=true).
 // cmdArgs should be os.Args to log the command that was run.
-func Setup(logDir string, verbose, noLog bool, cmdArgs []string) (*Logger, error) {
+func Setup(logDir, format string, verbose, noLog bool, cmdArgs []string) (*Logger, error) {
 	if noLog {
 		return nil, nil
 	}
@@ -73,11 +88,12 @@ func Setup(logDir string, verbose, noLog bool, cmdArgs []string) (*Logger, error
 	logger := log.New(file, "", 0) // No flags - we add timestamps manually for consistent format
 
 	l := &Logger{
-		level:    level,
 		logger:   logger,
 		file:     file,
 		filePath: filePath,
+		json:     format == FormatJSON,
 	}
+	l.level.Store(int32(level))
 
 	// Log startup
 	l.Info("Command: %s", strings.Join(cmdArgs, " "))
@@ -103,17 +119,50 @@ func (l *Logger) Info(format string, args ...any) {
 	if l == nil {
 		return
 	}
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	l.logger.Printf("%s [INFO] "+format, append([]any{timestamp}, args...)...)
+	l.write("info", format, args)
 }
 
 // Debug logs a debug-level message (only if verbose mode is enabled).
 func (l *Logger) Debug(format string, args ...any) {
-	if l == nil || l.level < levelDebug {
+	if l == nil || level(l.level.Load()) < levelDebug {
+		return
+	}
+	l.write("debug", format, args)
+}
+
+// write formats a message as a text line or a JSON record with event "log".
+func (l *Logger) write(levelName, format string, args []any) {
+	if l.json {
+		line, _ := json.Marshal(reporter.JSONRecord{
+			Time:    time.Now().Format(reporter.JSONTimeFormat),
+			Level:   levelName,
+			Event:   "log",
+			Message: fmt.Sprintf(format, args...),
+		})
+		l.logger.Print(string(line))
 		return
 	}
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	l.logger.Printf("%s [DEBUG] "+format, append([]any{timestamp}, args...)...)
+	l.logger.Printf("%s [%s] "+format, append([]any{timestamp, strings.ToUpper(levelName)}, args...)...)
+}
+
+// JSON reports whether the log is written as JSON lines.
+func (l *Logger) JSON() bool {
+	return l != nil && l.json
+}
+
+// SetVerbose switches debug-level logging on or off. Safe to call while logging.
+func (l *Logger) SetVerbose(verbose bool) {
+	if l == nil {
+		return
+	}
+	if verbose {
+		l.level.Store(int32(levelDebug))
+		l.Info("Debug level logging enabled")
+	} else {
+		l.level.Store(int32(levelInfo))
+		l.Info("Debug level logging disabled")
+	}
 }
 
 // Writer returns an io.Writer that writes to the log file.
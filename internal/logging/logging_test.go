@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/reporter"
+)
+
+// readLog closes l and returns the lines of its file.
+func readLog(t *testing.T, l *Logger) []string {
+	t.Helper()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(l.filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestJSONLog(t *testing.T) {
+	l, err := Setup(t.TempDir(), FormatJSON, false, false, []string{"reel", "encode"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.JSON() {
+		t.Error("JSON() = false, want true")
+	}
+	l.Info("Encoding %s", "movie.mkv")
+	l.Debug("Hidden without --verbose")
+	l.SetVerbose(true)
+	l.Debug("Chunk %d done", 3)
+
+	var messages []string
+	for _, line := range readLog(t, l) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q is not a JSON object: %v", line, err)
+		}
+		for key := range record {
+			if key != "time" && key != "level" && key != "event" && key != "message" {
+				t.Errorf("line %q has unexpected key %q", line, key)
+			}
+		}
+		if _, err := time.Parse(reporter.JSONTimeFormat, record["time"].(string)); err != nil {
+			t.Errorf("line %q: %v", line, err)
+		}
+		if record["event"] != "log" {
+			t.Errorf("line %q has event %v, want log", line, record["event"])
+		}
+		messages = append(messages, record["level"].(string)+": "+record["message"].(string))
+	}
+
+	want := []string{
+		"info: Command: reel encode",
+		"info: Reel encoder starting",
+		"info: Log file: " + l.filePath,
+		"info: Encoding movie.mkv",
+		"info: Debug level logging enabled",
+		"debug: Chunk 3 done",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("log messages =\n%s\nwant\n%s", strings.Join(messages, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTextLog(t *testing.T) {
+	l, err := Setup(t.TempDir(), FormatText, true, false, []string{"reel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.JSON() {
+		t.Error("JSON() = true, want false")
+	}
+	l.Debug("Chunk %d done", 3)
+
+	lines := readLog(t, l)
+	last := lines[len(lines)-1]
+	if !strings.HasSuffix(last, " [DEBUG] Chunk 3 done") || json.Valid([]byte(last)) {
+		t.Errorf("last line = %q, want a text debug line", last)
+	}
+}
+
+func TestSetupNoLog(t *testing.T) {
+	l, err := Setup(t.TempDir(), FormatJSON, false, true, nil)
+	if l != nil || err != nil {
+		t.Errorf("Setup(noLog) = %v, %v, want nil, nil", l, err)
+	}
+	// A nil logger is safe to use
+	l.Info("ignored")
+	if l.JSON() {
+		t.Error("nil JSON() = true")
+	}
+}
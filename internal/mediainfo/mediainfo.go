@@ -1,12 +1,17 @@
-// Package mediainfo provides functions for HDR detection using MediaInfo.
+// Package mediainfo provides functions for HDR and object audio detection
+// using MediaInfo.
 package mediainfo
 
 import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/toolpath"
 )
 
 // VideoTrack contains video track information from MediaInfo.
@@ -22,14 +27,21 @@ type VideoTrack struct {
 	ColourPrimaries         string `json:"colour_primaries"`
 	TransferCharacteristics string `json:"transfer_characteristics"`
 	MatrixCoefficients      string `json:"matrix_coefficients"`
+
+	MasteringDisplayPrimaries string `json:"MasteringDisplay_ColorPrimaries"`
+	MasteringDisplayLuminance string `json:"MasteringDisplay_Luminance"`
+	MaxCLL                    string `json:"MaxCLL"`
+	MaxFALL                   string `json:"MaxFALL"`
 }
 
 // AudioTrack contains audio track information from MediaInfo.
 type AudioTrack struct {
-	Format       string `json:"Format"`
-	Channels     string `json:"Channels"`
-	SamplingRate string `json:"SamplingRate"`
-	BitRate      string `json:"BitRate"`
+	Format             string `json:"Format"`
+	FormatCommercial   string `json:"Format_Commercial_IfAny"`
+	AdditionalFeatures string `json:"Format_AdditionalFeatures"`
+	Channels           string `json:"Channels"`
+	SamplingRate       string `json:"SamplingRate"`
+	BitRate            string `json:"BitRate"`
 }
 
 // Track represents a MediaInfo track with type information.
@@ -77,20 +89,27 @@ type HDRInfo struct {
 	TransferCharacteristics string
 	MatrixCoefficients      string
 	BitDepth                *uint8
+
+	// Static HDR metadata as MediaInfo reports it, e.g. "Display P3" and
+	// "min: 0.0050 cd/m2, max: 1000 cd/m2"; empty when absent
+	MasteringDisplayPrimaries string
+	MasteringDisplayLuminance string
+	MaxCLL                    string
+	MaxFALL                   string
 }
 
 // IsAvailable checks if MediaInfo is available on the system.
-func IsAvailable() bool {
-	cmd := exec.Command("mediainfo", "--Version")
-	err := cmd.Run()
+func IsAvailable(paths toolpath.Paths) bool {
+	cmd := exec.Command(paths.Path(toolpath.MediaInfo), "--Version")
+	err := cmdlog.Run(cmd)
 	return err == nil
 }
 
 // GetMediaInfo runs MediaInfo and returns parsed output.
-func GetMediaInfo(inputPath string) (*Response, error) {
-	cmd := exec.Command("mediainfo", "--Output=JSON", inputPath)
+func GetMediaInfo(paths toolpath.Paths, inputPath string) (*Response, error) {
+	cmd := exec.Command(paths.Path(toolpath.MediaInfo), "--Output=JSON", inputPath)
 
-	output, err := cmd.Output()
+	output, err := cmdlog.Output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("mediainfo failed: %w", err)
 	}
@@ -140,6 +159,11 @@ func DetectHDR(info *Response) HDRInfo {
 		TransferCharacteristics: transfer,
 		MatrixCoefficients:      matrix,
 		BitDepth:                bitDepth,
+
+		MasteringDisplayPrimaries: videoTrack.MasteringDisplayPrimaries,
+		MasteringDisplayLuminance: videoTrack.MasteringDisplayLuminance,
+		MaxCLL:                    videoTrack.MaxCLL,
+		MaxFALL:                   videoTrack.MaxFALL,
 	}
 }
 
@@ -186,3 +210,49 @@ func GetAudioChannels(info *Response) []uint32 {
 	}
 	return channels
 }
+
+// Object audio formats carry positional metadata that a channel-based
+// encode like Opus discards.
+const (
+	ObjectAtmos = "Dolby Atmos"
+	ObjectDTSX  = "DTS:X"
+)
+
+// ObjectAudio is an audio stream with object metadata.
+type ObjectAudio struct {
+	Index  int    // Audio stream index, counted from 0 as ffprobe does
+	Format string // ObjectAtmos or ObjectDTSX
+}
+
+// DetectObjectAudio finds Dolby Atmos and DTS:X streams: Atmos in TrueHD or
+// E-AC-3 (JOC), and DTS:X in DTS-HD MA, from MediaInfo's commercial names
+// and additional features.
+func DetectObjectAudio(info *Response) []ObjectAudio {
+	var found []ObjectAudio
+	index := 0
+	for _, track := range info.Media.Track {
+		if track.Type != "Audio" {
+			continue
+		}
+		if format := objectFormat(track.Audio); format != "" {
+			found = append(found, ObjectAudio{Index: index, Format: format})
+		}
+		index++
+	}
+	return found
+}
+
+// objectFormat returns the object audio format of a track, or "" for
+// channel-based audio.
+func objectFormat(t AudioTrack) string {
+	switch {
+	case containsAny(t.FormatCommercial, "Atmos"),
+		t.Format == "E-AC-3" && containsAny(t.AdditionalFeatures, "JOC"),
+		t.Format == "MLP FBA" && containsAny(t.AdditionalFeatures, "16-ch"):
+		return ObjectAtmos
+	case containsAny(t.FormatCommercial, "DTS:X"),
+		t.Format == "DTS" && slices.Contains(strings.Fields(t.AdditionalFeatures), "X"):
+		return ObjectDTSX
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+//go:build !opuslib
+
+// Package opuslib links libopus to encode audio tracks in-process from PCM
+// that ffmpeg decodes, instead of having ffmpeg run libopus itself. It is
+// only linked when reel is built with the opuslib tag.
+package opuslib
+
+import "errors"
+
+// Linked reports whether libopus is linked into this build.
+const Linked = false
+
+// errNotLinked is returned by Open in builds without the opuslib tag.
+var errNotLinked = errors.New("libopus is not linked; build with -tags opuslib")
+
+// Version returns the linked libopus version; empty as none is linked.
+func Version() string {
+	return ""
+}
+
+// Encoder is an in-process libopus encoder for one track.
+type Encoder struct{}
+
+// Open fails, as libopus is not linked.
+func Open(channels, bitrate int) (*Encoder, error) {
+	return nil, errNotLinked
+}
+
+// Head returns no stream parameters, as libopus is not linked.
+func (e *Encoder) Head() Head {
+	return Head{}
+}
+
+// Encode fails, as libopus is not linked.
+func (e *Encoder) Encode([]float32) ([]byte, error) {
+	return nil, errNotLinked
+}
+
+// Close does nothing.
+func (e *Encoder) Close() {}
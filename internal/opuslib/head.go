@@ -0,0 +1,19 @@
+package opuslib
+
+const (
+	// SampleRate is the rate Encoder takes audio at, in Hz.
+	SampleRate = 48000
+	// FrameSize is the samples per channel in each frame Encoder takes:
+	// 20 ms at SampleRate.
+	FrameSize = 960
+)
+
+// Head describes an encoded stream for its Ogg Opus identification header.
+type Head struct {
+	Channels int
+	PreSkip  int    // Samples to discard from the start, the encoder's delay
+	Family   int    // Channel mapping family: 0 for mono or stereo, 1 for surround
+	Streams  int    // Opus streams in each packet
+	Coupled  int    // Of which stereo
+	Mapping  []byte // Stream channel for each output channel, for family 1
+}
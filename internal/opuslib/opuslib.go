@@ -0,0 +1,105 @@
+//go:build opuslib
+
+// Package opuslib links libopus to encode audio tracks in-process from PCM
+// that ffmpeg decodes, instead of having ffmpeg run libopus itself. It is
+// only linked when reel is built with the opuslib tag.
+package opuslib
+
+/*
+#cgo pkg-config: opus
+#include <opus_multistream.h>
+
+// Helpers for the variadic encoder ctl, which cgo can't call directly
+static int reel_opus_set_bitrate(OpusMSEncoder *enc, opus_int32 bitrate) {
+	return opus_multistream_encoder_ctl(enc, OPUS_SET_BITRATE(bitrate));
+}
+
+static int reel_opus_get_lookahead(OpusMSEncoder *enc, opus_int32 *lookahead) {
+	return opus_multistream_encoder_ctl(enc, OPUS_GET_LOOKAHEAD(lookahead));
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Linked reports whether libopus is linked into this build.
+const Linked = true
+
+// maxPacket is the largest packet an Encoder accepts from libopus: the
+// recommended 1275 bytes per stream for up to eight streams.
+const maxPacket = 1275 * 8
+
+// Version returns the linked libopus version, e.g. "libopus 1.5.2".
+func Version() string {
+	return C.GoString(C.opus_get_version_string())
+}
+
+// Encoder is an in-process libopus encoder for one track.
+type Encoder struct {
+	enc    *C.OpusMSEncoder
+	head   Head
+	packet []byte
+}
+
+// Open creates an encoder for channels of 48 kHz audio at bitrate bits per
+// second. Up to two channels use mapping family 0, more use family 1, which
+// takes them in Vorbis order.
+func Open(channels, bitrate int) (*Encoder, error) {
+	family := 0
+	if channels > 2 {
+		family = 1
+	}
+	var streams, coupled, errc C.int
+	mapping := make([]byte, channels)
+	enc := C.opus_multistream_surround_encoder_create(SampleRate, C.int(channels), C.int(family),
+		&streams, &coupled, (*C.uchar)(unsafe.Pointer(&mapping[0])), C.OPUS_APPLICATION_AUDIO, &errc)
+	if errc != C.OPUS_OK {
+		return nil, fmt.Errorf("failed to create encoder: %s", C.GoString(C.opus_strerror(errc)))
+	}
+	e := &Encoder{enc: enc, packet: make([]byte, maxPacket)}
+
+	if errc := C.reel_opus_set_bitrate(enc, C.opus_int32(bitrate)); errc != C.OPUS_OK {
+		e.Close()
+		return nil, fmt.Errorf("encoder rejected bitrate %d: %s", bitrate, C.GoString(C.opus_strerror(errc)))
+	}
+	var lookahead C.opus_int32
+	if errc := C.reel_opus_get_lookahead(enc, &lookahead); errc != C.OPUS_OK {
+		e.Close()
+		return nil, fmt.Errorf("failed to read encoder delay: %s", C.GoString(C.opus_strerror(errc)))
+	}
+
+	e.head = Head{
+		Channels: channels,
+		PreSkip:  int(lookahead),
+		Family:   family,
+		Streams:  int(streams),
+		Coupled:  int(coupled),
+		Mapping:  mapping,
+	}
+	return e, nil
+}
+
+// Head returns the stream parameters for the Ogg Opus header.
+func (e *Encoder) Head() Head {
+	return e.head
+}
+
+// Encode encodes one frame of FrameSize samples per channel, interleaved
+// in the channel order Open describes. The packet is only valid until the
+// next call.
+func (e *Encoder) Encode(pcm []float32) ([]byte, error) {
+	n := C.opus_multistream_encode_float(e.enc, (*C.float)(unsafe.Pointer(&pcm[0])), FrameSize,
+		(*C.uchar)(unsafe.Pointer(&e.packet[0])), C.opus_int32(len(e.packet)))
+	if n < 0 {
+		return nil, fmt.Errorf("encoding failed: %s", C.GoString(C.opus_strerror(n)))
+	}
+	return e.packet[:n], nil
+}
+
+// Close releases the encoder.
+func (e *Encoder) Close() {
+	C.opus_multistream_encoder_destroy(e.enc)
+}
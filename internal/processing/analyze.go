@@ -0,0 +1,87 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/mediainfo"
+	"github.com/five82/reel/internal/tools"
+)
+
+// Analysis is what ProcessVideos would work out about an input before encoding it.
+type Analysis struct {
+	Props        *ffprobe.VideoProperties
+	HDR          mediainfo.HDRInfo
+	AudioStreams []ffprobe.AudioStreamInfo // Streams that would be encoded
+	Crop         CropResult
+	OutputWidth  uint32 // Dimensions after crop
+	OutputHeight uint32
+	Tier         string // "SD", "HD" or "UHD"
+	CRF          uint32
+	Preset       uint8
+}
+
+// Analyze probes inputPath the way ProcessVideos does, applying any sidecar
+// overrides, without encoding it.
+func Analyze(ctx context.Context, cfg *config.Config, inputPath string) (*Analysis, error) {
+	cfg, err := withSidecar(withTools(cfg), inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := ffprobe.GetVideoStreamProperties(cfg.Tools, inputPath, videoStream(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := mediainfo.GetMediaInfo(cfg.Tools, inputPath)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			err = fmt.Errorf("%w: %w", tools.ErrDependencyMissing, err)
+		}
+		return nil, err
+	}
+
+	audioStreams := GetAudioStreamInfo(cfg.Tools, inputPath)
+	if len(cfg.AudioTracks) > 0 || len(cfg.AudioLanguages) > 0 {
+		audioStreams, err = SelectAudioStreams(audioStreams, cfg.AudioTracks, cfg.AudioLanguages)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, cancelled(ctx.Err())
+	}
+
+	var crop CropResult
+	if cfg.CropFilter != "" {
+		crop, err = ManualCrop(cfg.CropFilter, props)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		crop = DetectCrop(ctx, cfg.Tools, inputPath, props, cfg.CropMode == "none", cfg.CropConfidence, nil)
+		if ctx.Err() != nil {
+			return nil, cancelled(ctx.Err())
+		}
+	}
+	outW, outH := GetOutputDimensions(props.Width, props.Height, crop.CropFilter)
+	crf, _ := determineQualitySettings(props, cfg)
+
+	return &Analysis{
+		Props:        props,
+		HDR:          mediainfo.DetectHDR(info),
+		AudioStreams: audioStreams,
+		Crop:         crop,
+		OutputWidth:  outW,
+		OutputHeight: outH,
+		Tier:         qualityTier(props.Width),
+		CRF:          crf,
+		Preset:       cfg.PresetForWidth(props.Width),
+	}, nil
+}
@@ -2,15 +2,20 @@ package processing
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
 	"github.com/five82/reel/internal/ffmpeg"
 	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/mediainfo"
+	"github.com/five82/reel/internal/toolpath"
 )
 
 // GetAudioChannels returns audio channel counts for a file.
-func GetAudioChannels(inputPath string) []uint32 {
-	channels, err := ffprobe.GetAudioChannels(inputPath)
+func GetAudioChannels(paths toolpath.Paths, inputPath string) []uint32 {
+	channels, err := ffprobe.GetAudioChannels(paths, inputPath)
 	if err != nil {
 		return nil
 	}
@@ -18,14 +23,122 @@ func GetAudioChannels(inputPath string) []uint32 {
 }
 
 // GetAudioStreamInfo returns detailed audio stream information.
-func GetAudioStreamInfo(inputPath string) []ffprobe.AudioStreamInfo {
-	streams, err := ffprobe.GetAudioStreamInfo(inputPath)
+func GetAudioStreamInfo(paths toolpath.Paths, inputPath string) []ffprobe.AudioStreamInfo {
+	streams, err := ffprobe.GetAudioStreamInfo(paths, inputPath)
 	if err != nil {
 		return nil
 	}
 	return streams
 }
 
+// SelectAudioStreams keeps the streams listed in tracks or tagged with one of
+// languages. With neither set, every stream is kept. Returns an error if a
+// track doesn't exist or the selection drops all audio from a source that has it.
+func SelectAudioStreams(streams []ffprobe.AudioStreamInfo, tracks []int, languages []string) ([]ffprobe.AudioStreamInfo, error) {
+	if len(tracks) == 0 && len(languages) == 0 {
+		return streams, nil
+	}
+
+	wanted := make(map[int]bool, len(tracks))
+	for _, t := range tracks {
+		if t >= len(streams) {
+			return nil, fmt.Errorf("audio track %d does not exist (source has %d)", t, len(streams))
+		}
+		wanted[t] = true
+	}
+
+	var selected []ffprobe.AudioStreamInfo
+	for _, s := range streams {
+		if wanted[s.Index] || slices.ContainsFunc(languages, func(lang string) bool {
+			return strings.EqualFold(lang, s.Language)
+		}) {
+			selected = append(selected, s)
+		}
+	}
+
+	if len(selected) == 0 && len(streams) > 0 {
+		return nil, fmt.Errorf("audio selection (tracks %v, languages %v) matched none of %d tracks",
+			tracks, languages, len(streams))
+	}
+	return selected, nil
+}
+
+// StereoDownmix returns the downmix cfg asks for: the first surround stream
+// of streams folded to stereo. Returns nil when downmixing is off or there
+// is no surround stream.
+func StereoDownmix(cfg *config.Config, streams []ffprobe.AudioStreamInfo) *chunk.Downmix {
+	if !cfg.StereoDownmix {
+		return nil
+	}
+	for _, s := range streams {
+		if s.Channels > 2 {
+			return &chunk.Downmix{Stream: s, DialogueBoost: cfg.DialogueBoostDB}
+		}
+	}
+	return nil
+}
+
+// SelectObjectAudio keeps the object audio streams that are among streams.
+func SelectObjectAudio(found []mediainfo.ObjectAudio, streams []ffprobe.AudioStreamInfo) []mediainfo.ObjectAudio {
+	var selected []mediainfo.ObjectAudio
+	for _, o := range found {
+		if slices.ContainsFunc(streams, func(s ffprobe.AudioStreamInfo) bool { return s.Index == o.Index }) {
+			selected = append(selected, o)
+		}
+	}
+	return selected
+}
+
+// FormatObjectAudio lists object audio streams, e.g. "Dolby Atmos (stream 0)".
+// Returns "" when there are none.
+func FormatObjectAudio(found []mediainfo.ObjectAudio) string {
+	parts := make([]string, 0, len(found))
+	for _, o := range found {
+		parts = append(parts, fmt.Sprintf("%s (stream %d)", o.Format, o.Index))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PreservedCodecs returns the codecs of streams copied for their object
+// audio rather than for being in a passthrough format, which validation
+// must accept.
+func PreservedCodecs(streams []ffprobe.AudioStreamInfo, settings chunk.AudioSettings) []string {
+	var codecs []string
+	for _, s := range streams {
+		if slices.Contains(settings.Preserve, s.Index) {
+			codecs = append(codecs, strings.ToLower(s.CodecName))
+		}
+	}
+	return codecs
+}
+
+// copiedFormat names the format of a copied stream.
+func copiedFormat(s ffprobe.AudioStreamInfo) string {
+	if format := s.LosslessFormat(); format != "" {
+		return format
+	}
+	return strings.ToLower(s.CodecName)
+}
+
+// opusLayoutNote describes the remix of a stream whose layout Opus can't
+// take, e.g. "4.0 as quad", returning "" when none is needed.
+func opusLayoutNote(stream ffprobe.AudioStreamInfo) string {
+	layout, _, remapped := chunk.OpusLayout(stream)
+	if !remapped {
+		return ""
+	}
+	source := stream.ChannelLayout
+	if source == "" || source == "unknown" {
+		source = fmt.Sprintf("%dch", stream.Channels)
+	}
+	return source + " as " + layout
+}
+
+// FormatDownmixDescription describes a downmix for the config display.
+func FormatDownmixDescription(d *chunk.Downmix, kbpsPerChannel uint32) string {
+	return fmt.Sprintf("%s of stream %d [%dkbps Opus]", strings.ToLower(d.Title()), d.Stream.Index, ffmpeg.AudioBitrate(2, kbpsPerChannel))
+}
+
 // FormatAudioDescription formats a basic audio description.
 func FormatAudioDescription(channels []uint32) string {
 	if len(channels) == 0 {
@@ -44,7 +157,8 @@ func FormatAudioDescription(channels []uint32) string {
 }
 
 // FormatAudioDescriptionConfig formats audio description for config display.
-func FormatAudioDescriptionConfig(channels []uint32, streams []ffprobe.AudioStreamInfo) string {
+// Streams that settings copies are shown as passthrough.
+func FormatAudioDescriptionConfig(channels []uint32, streams []ffprobe.AudioStreamInfo, settings chunk.AudioSettings) string {
 	if streams == nil {
 		return FormatAudioDescription(channels)
 	}
@@ -53,32 +167,66 @@ func FormatAudioDescriptionConfig(channels []uint32, streams []ffprobe.AudioStre
 		return "No audio"
 	}
 
+	kbpsPerChannel, copies := settings.KbpsPerChannel, settings.Copies
 	if len(streams) == 1 {
 		stream := streams[0]
-		bitrate := ffmpeg.CalculateAudioBitrate(stream.Channels)
+		if copies(stream) {
+			return fmt.Sprintf("%d channels, %s passthrough", stream.Channels, copiedFormat(stream))
+		}
+		_, channels, _ := chunk.OpusLayout(stream)
+		bitrate := ffmpeg.AudioBitrate(channels, kbpsPerChannel)
+		if note := opusLayoutNote(stream); note != "" {
+			return fmt.Sprintf("%d channels (%s) @ %dkbps Opus", stream.Channels, note, bitrate)
+		}
 		return fmt.Sprintf("%d channels @ %dkbps Opus", stream.Channels, bitrate)
 	}
 
 	var parts []string
 	for _, stream := range streams {
-		bitrate := ffmpeg.CalculateAudioBitrate(stream.Channels)
+		if copies(stream) {
+			parts = append(parts, fmt.Sprintf("Stream %d: %dch [%s passthrough]", stream.Index, stream.Channels, copiedFormat(stream)))
+			continue
+		}
+		_, channels, _ := chunk.OpusLayout(stream)
+		bitrate := ffmpeg.AudioBitrate(channels, kbpsPerChannel)
+		if note := opusLayoutNote(stream); note != "" {
+			parts = append(parts, fmt.Sprintf("Stream %d: %dch [%s, %dkbps Opus]", stream.Index, stream.Channels, note, bitrate))
+			continue
+		}
 		parts = append(parts, fmt.Sprintf("Stream %d: %dch [%dkbps Opus]", stream.Index, stream.Channels, bitrate))
 	}
 	return strings.Join(parts, ", ")
 }
 
 // GenerateAudioResultsDescription generates audio description for results.
-func GenerateAudioResultsDescription(channels []uint32, streams []ffprobe.AudioStreamInfo) string {
+// Streams that settings copies are shown as passthrough.
+func GenerateAudioResultsDescription(channels []uint32, streams []ffprobe.AudioStreamInfo, settings chunk.AudioSettings) string {
+	kbpsPerChannel := settings.KbpsPerChannel
 	if len(streams) > 0 {
+		copies := settings.Copies
 		if len(streams) == 1 {
-			bitrate := ffmpeg.CalculateAudioBitrate(streams[0].Channels)
-			return fmt.Sprintf("Opus %dch @ %dkbps", streams[0].Channels, bitrate)
+			if copies(streams[0]) {
+				return fmt.Sprintf("%s %dch (passthrough)", copiedFormat(streams[0]), streams[0].Channels)
+			}
+			_, channels, _ := chunk.OpusLayout(streams[0])
+			bitrate := ffmpeg.AudioBitrate(channels, kbpsPerChannel)
+			return fmt.Sprintf("Opus %dch @ %dkbps", channels, bitrate)
 		}
 
 		var parts []string
+		copied := false
 		for _, stream := range streams {
-			bitrate := ffmpeg.CalculateAudioBitrate(stream.Channels)
-			parts = append(parts, fmt.Sprintf("%dch@%dk", stream.Channels, bitrate))
+			if copies(stream) {
+				parts = append(parts, fmt.Sprintf("%dch %s", stream.Channels, copiedFormat(stream)))
+				copied = true
+				continue
+			}
+			_, channels, _ := chunk.OpusLayout(stream)
+			bitrate := ffmpeg.AudioBitrate(channels, kbpsPerChannel)
+			parts = append(parts, fmt.Sprintf("%dch@%dk", channels, bitrate))
+		}
+		if copied {
+			return fmt.Sprintf("Opus and passthrough (%s)", strings.Join(parts, ", "))
 		}
 		return fmt.Sprintf("Opus (%s)", strings.Join(parts, ", "))
 	}
@@ -88,13 +236,13 @@ func GenerateAudioResultsDescription(channels []uint32, streams []ffprobe.AudioS
 	}
 
 	if len(channels) == 1 {
-		bitrate := ffmpeg.CalculateAudioBitrate(channels[0])
+		bitrate := ffmpeg.AudioBitrate(channels[0], kbpsPerChannel)
 		return fmt.Sprintf("Opus %dch @ %dkbps", channels[0], bitrate)
 	}
 
 	var parts []string
 	for _, ch := range channels {
-		bitrate := ffmpeg.CalculateAudioBitrate(ch)
+		bitrate := ffmpeg.AudioBitrate(ch, kbpsPerChannel)
 		parts = append(parts, fmt.Sprintf("%dch@%dk", ch, bitrate))
 	}
 	return fmt.Sprintf("Opus (%s)", strings.Join(parts, ", "))
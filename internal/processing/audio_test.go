@@ -0,0 +1,120 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/mediainfo"
+)
+
+func TestSelectAudioStreams(t *testing.T) {
+	streams := []ffprobe.AudioStreamInfo{
+		{Index: 0, Channels: 6, Language: "eng"},
+		{Index: 1, Channels: 2, Language: "fre"},
+		{Index: 2, Channels: 2, Language: "eng"},
+	}
+
+	tests := []struct {
+		name      string
+		tracks    []int
+		languages []string
+		want      []int
+		wantErr   bool
+	}{
+		{"no selection keeps all", nil, nil, []int{0, 1, 2}, false},
+		{"by track", []int{1}, nil, []int{1}, false},
+		{"by language", nil, []string{"ENG"}, []int{0, 2}, false},
+		{"track or language", []int{1}, []string{"eng"}, []int{0, 1, 2}, false},
+		{"missing track", []int{5}, nil, nil, true},
+		{"nothing matched", nil, []string{"jpn"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectAudioStreams(streams, tt.tracks, tt.languages)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d streams, want %d", len(got), len(tt.want))
+			}
+			for i, s := range got {
+				if s.Index != tt.want[i] {
+					t.Errorf("stream %d index = %d, want %d", i, s.Index, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStereoDownmix(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		streams   []ffprobe.AudioStreamInfo
+		wantIndex int // -1 = no downmix
+	}{
+		{"off", false, []ffprobe.AudioStreamInfo{{Index: 0, Channels: 8}}, -1},
+		{"first surround stream", true, []ffprobe.AudioStreamInfo{{Index: 0, Channels: 2}, {Index: 1, Channels: 8}, {Index: 2, Channels: 6}}, 1},
+		{"stereo only", true, []ffprobe.AudioStreamInfo{{Index: 0, Channels: 2}}, -1},
+		{"no audio", true, nil, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig("/input", "/output", "/log")
+			cfg.StereoDownmix, cfg.DialogueBoostDB = tt.enabled, 6
+			got := StereoDownmix(cfg, tt.streams)
+			switch {
+			case tt.wantIndex < 0 && got != nil:
+				t.Errorf("got downmix of stream %d, want none", got.Stream.Index)
+			case tt.wantIndex >= 0 && (got == nil || got.Stream.Index != tt.wantIndex || got.DialogueBoost != 6):
+				t.Errorf("got %+v, want stream %d boosted 6 dB", got, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestSelectObjectAudio(t *testing.T) {
+	found := []mediainfo.ObjectAudio{
+		{Index: 0, Format: mediainfo.ObjectAtmos},
+		{Index: 2, Format: mediainfo.ObjectDTSX},
+	}
+	streams := []ffprobe.AudioStreamInfo{{Index: 1}, {Index: 2}}
+
+	got := SelectObjectAudio(found, streams)
+	if len(got) != 1 || got[0].Index != 2 {
+		t.Fatalf("got %+v, want only stream 2", got)
+	}
+	if desc := FormatObjectAudio(got); desc != "DTS:X (stream 2)" {
+		t.Errorf("FormatObjectAudio() = %q", desc)
+	}
+	if desc := FormatObjectAudio(nil); desc != "" {
+		t.Errorf("FormatObjectAudio(nil) = %q, want empty", desc)
+	}
+}
+
+func TestPreservedCodecs(t *testing.T) {
+	streams := []ffprobe.AudioStreamInfo{
+		{Index: 0, CodecName: "EAC3"},
+		{Index: 1, CodecName: "ac3"},
+		{Index: 2, CodecName: "truehd"},
+	}
+	got := PreservedCodecs(streams, chunk.AudioSettings{Preserve: []int{0, 2}})
+	if len(got) != 2 || got[0] != "eac3" || got[1] != "truehd" {
+		t.Errorf("PreservedCodecs() = %v, want [eac3 truehd]", got)
+	}
+}
+
+func TestFormatAudioDescriptionConfigLayouts(t *testing.T) {
+	streams := []ffprobe.AudioStreamInfo{
+		{Index: 0, Channels: 6, ChannelLayout: "5.1(side)"},
+		{Index: 1, Channels: 5, ChannelLayout: "4.1"},
+	}
+	want := "Stream 0: 6ch [256kbps Opus], Stream 1: 5ch [4.1 as 5.1, 256kbps Opus]"
+	if got := FormatAudioDescriptionConfig(nil, streams, chunk.AudioSettings{}); got != want {
+		t.Errorf("FormatAudioDescriptionConfig() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package processing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/five82/reel/internal/config"
+)
+
+// DefaultBatchStateDir returns where batch progress is kept between runs.
+// Uses $XDG_STATE_HOME/reel/batches, defaulting to ~/.local/state/reel/batches.
+func DefaultBatchStateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "reel", "batches")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "reel", "batches")
+}
+
+// batchState records which inputs of a batch have finished or failed, so
+// re-running an interrupted batch continues with the first unfinished file.
+// Failed inputs, including encodes that failed validation or were stopped
+// for size, are tried again unless the batch is resumed with
+// --resume-batch; inputs not reached are not recorded.
+type batchState struct {
+	Inputs []string          `json:"inputs"`
+	Done   map[string]string `json:"done"`             // Input path -> outcome
+	Failed map[string]string `json:"failed,omitempty"` // Input path -> error
+
+	path string
+}
+
+// batchStatePath returns the state file for a batch. It is keyed by the
+// inputs and their outputs in order and the encode settings (see
+// batchSettingsKey), so only an identical command resumes it.
+func batchStatePath(dir string, inputs, outputs []string, settings string) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "settings\t%s\n", settings)
+	for i, input := range inputs {
+		_, _ = fmt.Fprintf(h, "%s\t%s\n", input, outputs[i])
+	}
+	return filepath.Join(dir, hex.EncodeToString(h.Sum(nil))[:32]+".json")
+}
+
+// batchDirStatePath returns the state file for a batch discovered in
+// inputDir. It is keyed by the directory, where its outputs go and the
+// encode settings rather than the files found, so the state outlives files
+// being added or removed.
+func batchDirStatePath(dir, inputDir, outputDir, template, settings string) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "dir\t%s\t%s\t%s\t%s\n", inputDir, outputDir, template, settings)
+	return filepath.Join(dir, hex.EncodeToString(h.Sum(nil))[:32]+".json")
+}
+
+// batchSettingsKey digests the settings that shape an encode's output, so
+// a batch rerun with a different quality, preset or profile starts over
+// instead of skipping files encoded the old way. Parallelism, scheduling
+// and placement settings are left out; changing them resumes the batch.
+func batchSettingsKey(cfg *config.Config) string {
+	data, _ := json.Marshal(map[string]any{
+		"tune":             cfg.SVTAV1Tune,
+		"ac_bias":          cfg.SVTAV1ACBias,
+		"variance_boost":   []any{cfg.SVTAV1EnableVarianceBoost, cfg.SVTAV1VarianceBoostStrength, cfg.SVTAV1VarianceOctile},
+		"scm":              cfg.SVTAV1SCM,
+		"scd":              cfg.SVTAV1SceneDetection,
+		"tiles":            []uint8{cfg.SVTAV1TileRows, cfg.SVTAV1TileColumns},
+		"svt_params":       cfg.SVTAV1ExtraParams,
+		"bit_depth":        cfg.BitDepth,
+		"crf":              []uint8{cfg.CRFSD, cfg.CRFHD, cfg.CRFUHD},
+		"preset":           []uint8{cfg.PresetSD, cfg.PresetHD, cfg.PresetUHD},
+		"profile":          cfg.Profile,
+		"deinterlace":      cfg.Deinterlace,
+		"assume_rec601":    cfg.AssumeRec601,
+		"keyint":           cfg.KeyintSecs,
+		"audio_kbps":       cfg.AudioKbpsPerChannel,
+		"pal_slowdown":     cfg.PALSlowdown,
+		"downmix":          []any{cfg.StereoDownmix, cfg.DialogueBoostDB},
+		"film_grain":       []any{cfg.FilmGrain, cfg.FilmGrainDenoise, cfg.FilmGrainTable},
+		"video_stream":     cfg.VideoStream,
+		"audio_tracks":     cfg.AudioTracks,
+		"audio_languages":  cfg.AudioLanguages,
+		"audio_passthru":   cfg.AudioPassthrough,
+		"preserve_atmos":   cfg.PreserveAtmos,
+		"drop_attachments": cfg.DropAttachments,
+		"content":          cfg.ContentType,
+		"crop":             []any{cfg.CropMode, cfg.CropFilter, cfg.CropConfidence},
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadBatchState returns the state saved at path for inputs, or an empty
+// state if there is none. Saved progress is used only for the same inputs,
+// unless carryOver is set: then it's kept for the inputs still in the batch.
+func loadBatchState(path string, inputs []string, carryOver bool) (*batchState, error) {
+	s := &batchState{Inputs: inputs, Done: make(map[string]string), Failed: make(map[string]string), path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read batch state: %w", err)
+	}
+	var saved batchState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return s, fmt.Errorf("failed to parse batch state %s: %w", path, err)
+	}
+	if !carryOver && !slices.Equal(saved.Inputs, inputs) {
+		return s, nil
+	}
+	for input, outcome := range saved.Done {
+		if slices.Contains(inputs, input) {
+			s.Done[input] = outcome
+		}
+	}
+	for input, reason := range saved.Failed {
+		if slices.Contains(inputs, input) {
+			s.Failed[input] = reason
+		}
+	}
+	return s, nil
+}
+
+// markDone records that input finished with outcome and saves the state.
+func (s *batchState) markDone(input, outcome string) error {
+	s.Done[input] = outcome
+	delete(s.Failed, input)
+	return s.save()
+}
+
+// markFailed records that input failed with reason and saves the state.
+func (s *batchState) markFailed(input, reason string) error {
+	s.Failed[input] = reason
+	return s.save()
+}
+
+// save writes the state, replacing the saved file atomically.
+func (s *batchState) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode batch state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create batch state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	return nil
+}
+
+// complete reports whether every input has finished.
+func (s *batchState) complete() bool {
+	return len(s.Done) == len(s.Inputs)
+}
+
+// remove deletes the saved state once the batch has finished.
+func (s *batchState) remove() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove batch state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package processing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/reporter"
+)
+
+func TestBatchStateResumes(t *testing.T) {
+	dir := t.TempDir()
+	inputs := []string{"/rips/a.mkv", "/rips/b.mkv", "/rips/c.mkv"}
+	outputs := []string{"/out/a.mkv", "/out/b.mkv", "/out/c.mkv"}
+	path := batchStatePath(dir, inputs, outputs, "")
+
+	s, err := loadBatchState(path, inputs, false)
+	if err != nil || len(s.Done) != 0 {
+		t.Fatalf("loadBatchState() with no state = %v, %v; want empty", s.Done, err)
+	}
+	if err := s.markDone("/rips/a.mkv", "encoded"); err != nil {
+		t.Fatalf("markDone() error = %v", err)
+	}
+
+	s, err = loadBatchState(path, inputs, false)
+	if err != nil || s.Done["/rips/a.mkv"] != "encoded" || len(s.Done) != 1 {
+		t.Errorf("reloaded state = %v, %v; want a.mkv encoded", s.Done, err)
+	}
+	if s.complete() {
+		t.Error("complete() = true with two inputs left")
+	}
+	_ = s.markDone("/rips/b.mkv", "encoded")
+	_ = s.markDone("/rips/c.mkv", "output file already exists")
+	if !s.complete() {
+		t.Error("complete() = false with every input done")
+	}
+	if err := s.remove(); err != nil {
+		t.Errorf("remove() error = %v", err)
+	}
+	if s, _ := loadBatchState(path, inputs, false); len(s.Done) != 0 {
+		t.Errorf("state after remove = %v, want empty", s.Done)
+	}
+}
+
+func TestBatchStatePathDependsOnCommand(t *testing.T) {
+	inputs := []string{"/rips/a.mkv", "/rips/b.mkv"}
+	outputs := []string{"/out/a.mkv", "/out/b.mkv"}
+	settings := batchSettingsKey(config.NewConfig("/rips", "/out", "/log"))
+	base := batchStatePath("/state", inputs, outputs, settings)
+	if filepath.Dir(base) != "/state" {
+		t.Errorf("batchStatePath() = %q, want a file in /state", base)
+	}
+	if got := batchStatePath("/state", inputs, []string{"/other/a.mkv", "/other/b.mkv"}, settings); got == base {
+		t.Error("batchStatePath() ignores the outputs")
+	}
+	if got := batchStatePath("/state", []string{"/rips/b.mkv", "/rips/a.mkv"}, []string{"/out/b.mkv", "/out/a.mkv"}, settings); got == base {
+		t.Error("batchStatePath() ignores the input order")
+	}
+	if got := batchStatePath("/state", inputs, outputs, "other"); got == base {
+		t.Error("batchStatePath() ignores the settings")
+	}
+}
+
+func TestBatchSettingsKey(t *testing.T) {
+	cfg := config.NewConfig("/rips", "/out", "/log")
+	base := batchSettingsKey(cfg)
+	if again := batchSettingsKey(config.NewConfig("/rips", "/out", "/log")); again != base {
+		t.Errorf("batchSettingsKey() = %q then %q for the same settings", base, again)
+	}
+
+	for name, change := range map[string]func(*config.Config){
+		"crf":     func(c *config.Config) { c.CRFHD++ },
+		"preset":  func(c *config.Config) { c.PresetUHD++ },
+		"profile": func(c *config.Config) { c.Profile = "grain" },
+		"svt":     func(c *config.Config) { c.SVTAV1ExtraParams = "enable-qm=1" },
+	} {
+		changed := *cfg
+		change(&changed)
+		if batchSettingsKey(&changed) == base {
+			t.Errorf("batchSettingsKey() ignores %s", name)
+		}
+	}
+
+	// Parallelism doesn't change the output
+	changed := *cfg
+	changed.Workers++
+	if batchSettingsKey(&changed) != base {
+		t.Error("batchSettingsKey() depends on the worker count")
+	}
+}
+
+func TestBatchRetriesUnvalidatedAndMissingOutputs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewConfig(dir, dir, dir)
+	inputs := []string{"/rips/a.mkv", "/rips/b.mkv", "/rips/c.mkv"}
+	state, _ := loadBatchState(filepath.Join(dir, "state.json"), inputs, false)
+	b := &batch{cfg: cfg, files: inputs, outputs: newOutputNamer(cfg, nil), state: state}
+	rep := reporter.NullReporter{}
+
+	for _, name := range []string{"a.mkv", "b.mkv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.addResult(rep, "/rips/a.mkv", EncodeResult{ValidationPassed: true})
+	b.addResult(rep, "/rips/b.mkv", EncodeResult{ValidationPassed: false})
+	b.addResult(rep, "/rips/c.mkv", EncodeResult{ValidationPassed: true}) // Output deleted since
+
+	if _, ok := b.finishedEarlier("/rips/a.mkv"); !ok {
+		t.Error("validated encode with its output in place not skipped")
+	}
+	if outcome, ok := b.finishedEarlier("/rips/b.mkv"); ok {
+		t.Errorf("encode that failed validation skipped as %q", outcome)
+	}
+	if outcome, ok := b.finishedEarlier("/rips/c.mkv"); ok {
+		t.Errorf("encode whose output is gone skipped as %q", outcome)
+	}
+}
+
+func TestBatchStateCarryOver(t *testing.T) {
+	path := batchDirStatePath(t.TempDir(), "/rips", "/out", "", "")
+	inputs := []string{"/rips/a.mkv", "/rips/b.mkv", "/rips/c.mkv"}
+
+	s, _ := loadBatchState(path, inputs, false)
+	_ = s.markDone("/rips/a.mkv", "encoded")
+	_ = s.markFailed("/rips/b.mkv", "encoder crashed")
+
+	// A file was added and one removed since
+	changed := []string{"/rips/b.mkv", "/rips/c.mkv", "/rips/d.mkv"}
+	if s, _ := loadBatchState(path, changed, false); len(s.Done) != 0 || len(s.Failed) != 0 {
+		t.Errorf("state for changed inputs without carryOver = %v, %v; want empty", s.Done, s.Failed)
+	}
+	s, err := loadBatchState(path, changed, true)
+	if err != nil {
+		t.Fatalf("loadBatchState() error = %v", err)
+	}
+	if len(s.Done) != 0 {
+		t.Errorf("Done = %v, want the removed input forgotten", s.Done)
+	}
+	if s.Failed["/rips/b.mkv"] != "encoder crashed" {
+		t.Errorf("Failed = %v, want b.mkv carried over", s.Failed)
+	}
+
+	// Finishing a failed input clears its failure
+	_ = s.markDone("/rips/b.mkv", "encoded")
+	if s, _ := loadBatchState(path, changed, true); len(s.Failed) != 0 || s.Done["/rips/b.mkv"] != "encoded" {
+		t.Errorf("reloaded state = %v, %v; want b.mkv done", s.Done, s.Failed)
+	}
+}
+
+func TestBatchDirStatePath(t *testing.T) {
+	base := batchDirStatePath("/state", "/rips", "/out", "", "")
+	for _, other := range []string{
+		batchDirStatePath("/state", "/other", "/out", "", ""),
+		batchDirStatePath("/state", "/rips", "/other", "", ""),
+		batchDirStatePath("/state", "/rips", "/out", "{name}.av1.{ext}", ""),
+		batchDirStatePath("/state", "/rips", "/out", "", "other"),
+	} {
+		if other == base {
+			t.Errorf("batchDirStatePath() = %q for different batches", other)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package processing
+
+import (
+	"fmt"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/mediainfo"
+)
+
+// outputBitDepth returns the bit depth to encode a source at under the
+// given mode, with a warning when the mode can't be honored. HDR needs 10
+// bits, and a source of unknown depth is assumed not to be 8-bit.
+func outputBitDepth(mode string, hdr mediainfo.HDRInfo) (uint8, string) {
+	switch {
+	case mode == config.BitDepth10:
+		return 10, ""
+	case hdr.IsHDR:
+		if mode == config.BitDepth8 {
+			return 10, "HDR sources are encoded at 10-bit; ignoring bit depth 8"
+		}
+		return 10, ""
+	case mode == config.BitDepth8:
+		return 8, ""
+	case hdr.BitDepth != nil && *hdr.BitDepth <= 8:
+		return 8, ""
+	default:
+		return 10, ""
+	}
+}
+
+// pixelFormat returns the ffmpeg name of the YUV 4:2:0 format at depth.
+func pixelFormat(depth uint8) string {
+	if depth == 8 {
+		return "yuv420p"
+	}
+	return fmt.Sprintf("yuv420p%dle", depth)
+}
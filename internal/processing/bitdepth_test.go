@@ -0,0 +1,40 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/mediainfo"
+)
+
+func TestOutputBitDepth(t *testing.T) {
+	eight, ten := uint8(8), uint8(10)
+	sdr8 := mediainfo.HDRInfo{BitDepth: &eight}
+	sdr10 := mediainfo.HDRInfo{BitDepth: &ten}
+	hdr := mediainfo.HDRInfo{IsHDR: true, BitDepth: &ten}
+
+	tests := []struct {
+		name    string
+		mode    string
+		hdr     mediainfo.HDRInfo
+		want    uint8
+		warning bool
+	}{
+		{"default upconverts 8-bit", config.BitDepth10, sdr8, 10, false},
+		{"auto keeps 8-bit", config.BitDepthAuto, sdr8, 8, false},
+		{"auto keeps 10-bit", config.BitDepthAuto, sdr10, 10, false},
+		{"auto with unknown depth", config.BitDepthAuto, mediainfo.HDRInfo{}, 10, false},
+		{"8 rounds 10-bit SDR down", config.BitDepth8, sdr10, 8, false},
+		{"8 leaves HDR at 10-bit", config.BitDepth8, hdr, 10, true},
+		{"auto leaves HDR at 10-bit", config.BitDepthAuto, hdr, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warning := outputBitDepth(tt.mode, tt.hdr)
+			if got != tt.want || (warning != "") != tt.warning {
+				t.Errorf("outputBitDepth(%q) = %d, %q; want %d, warning %v", tt.mode, got, warning, tt.want, tt.warning)
+			}
+		})
+	}
+}
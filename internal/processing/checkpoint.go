@@ -0,0 +1,116 @@
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/indexcache"
+)
+
+// Analysis results kept in the work directory, so an interrupted encode
+// resumes without repeating crop detection or indexing.
+const (
+	checkpointFile = "analysis.json"
+	indexFile      = "video.ffindex"
+)
+
+// writeIndex saves idx for later runs, in place at once so a concurrent
+// reader never sees it half-written. Cached indexes are pruned to maxMB
+// afterwards, keeping the most recently used.
+func writeIndex(idx *ffms.VidIdx, path string, cached bool, maxMB uint64) error {
+	if cached {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create index cache: %w", err)
+		}
+	}
+	if err := indexcache.Write(path, idx.Write); err != nil {
+		return err
+	}
+	if cached {
+		return indexcache.Prune(filepath.Dir(path), maxMB<<20)
+	}
+	return nil
+}
+
+// analysisCheckpoint is the saved analysis of an input. It only applies while
+// the input and the crop settings that produced it are unchanged, or, for an
+// input saved while it was growing, until it shrinks.
+type analysisCheckpoint struct {
+	InputSize    int64      `json:"input_size"`
+	InputModTime time.Time  `json:"input_mod_time"`
+	Growing      bool       `json:"growing,omitempty"`
+	CropSettings string     `json:"crop_settings"`
+	Crop         CropResult `json:"crop"`
+}
+
+// cropSettings identifies the settings that affect the crop result.
+func cropSettings(cfg *config.Config) string {
+	return fmt.Sprintf("mode=%s filter=%s confidence=%g", cfg.CropMode, cfg.CropFilter, cfg.CropConfidence)
+}
+
+// newCheckpoint records the analysis of inputPath.
+func newCheckpoint(inputPath string, cfg *config.Config, crop CropResult) (analysisCheckpoint, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return analysisCheckpoint{}, fmt.Errorf("failed to stat input: %w", err)
+	}
+	return analysisCheckpoint{
+		InputSize:    info.Size(),
+		InputModTime: info.ModTime(),
+		Growing:      cfg.GrowingQuietSecs > 0,
+		CropSettings: cropSettings(cfg),
+		Crop:         crop,
+	}, nil
+}
+
+// matches reports whether the checkpoint was saved for the same input
+// contents and crop settings as current. Chunks encoded from a growing input
+// used its crop, so it stays in force as the input grows.
+func (c analysisCheckpoint) matches(current analysisCheckpoint) bool {
+	if c.CropSettings != current.CropSettings {
+		return false
+	}
+	if c.Growing {
+		return current.InputSize >= c.InputSize
+	}
+	return c.InputSize == current.InputSize && c.InputModTime.Equal(current.InputModTime)
+}
+
+// saveCheckpoint writes the analysis of inputPath to the work directory.
+func saveCheckpoint(workDir, inputPath string, cfg *config.Config, crop CropResult) error {
+	c, err := newCheckpoint(inputPath, cfg, crop)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, checkpointFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// loadCheckpoint returns the crop saved by an earlier run for inputPath.
+// It returns false if there is none or it no longer applies.
+func loadCheckpoint(workDir, inputPath string, cfg *config.Config) (CropResult, bool) {
+	data, err := os.ReadFile(filepath.Join(workDir, checkpointFile))
+	if err != nil {
+		return CropResult{}, false
+	}
+	var saved analysisCheckpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return CropResult{}, false
+	}
+	current, err := newCheckpoint(inputPath, cfg, CropResult{})
+	if err != nil || !saved.matches(current) {
+		return CropResult{}, false
+	}
+	return saved.Crop, true
+}
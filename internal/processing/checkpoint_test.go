@@ -0,0 +1,78 @@
+package processing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/config"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "movie.mkv")
+	if err := os.WriteFile(input, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfig(dir, dir, dir)
+	crop := CropResult{
+		CropFilter: "crop=1920:800:0:140",
+		Required:   true,
+		Votes:      []CropVote{{Crop: "1920:800:0:140", Count: 120}},
+		Samples:    141,
+		Confidence: 1,
+	}
+
+	if _, ok := loadCheckpoint(dir, input, cfg); ok {
+		t.Fatal("loadCheckpoint() found a checkpoint before one was saved")
+	}
+	if err := saveCheckpoint(dir, input, cfg, crop); err != nil {
+		t.Fatalf("saveCheckpoint() error = %v", err)
+	}
+	got, ok := loadCheckpoint(dir, input, cfg)
+	if !ok || got.CropFilter != crop.CropFilter || got.Samples != crop.Samples || len(got.Votes) != 1 {
+		t.Fatalf("loadCheckpoint() = %+v, %v; want %+v", got, ok, crop)
+	}
+
+	// Different crop settings or a modified input invalidate it
+	changed := *cfg
+	changed.CropConfidence = 0.5
+	if _, ok := loadCheckpoint(dir, input, &changed); ok {
+		t.Error("loadCheckpoint() applied a checkpoint made with other crop settings")
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(input, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(dir, input, cfg); ok {
+		t.Error("loadCheckpoint() applied a checkpoint for a modified input")
+	}
+}
+
+func TestCheckpointGrowingInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "rip.mkv")
+	if err := os.WriteFile(input, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfig(dir, dir, dir)
+	cfg.GrowingQuietSecs = 60
+	if err := saveCheckpoint(dir, input, cfg, CropResult{CropFilter: "crop=1920:800:0:140", Required: true}); err != nil {
+		t.Fatalf("saveCheckpoint() error = %v", err)
+	}
+
+	// The crop used for chunks already encoded outlives the input growing
+	if err := os.WriteFile(input, []byte("video and more"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(dir, input, config.NewConfig(dir, dir, dir)); !ok {
+		t.Error("loadCheckpoint() dropped the checkpoint of a grown input")
+	}
+	if err := os.WriteFile(input, []byte("vid"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCheckpoint(dir, input, cfg); ok {
+		t.Error("loadCheckpoint() applied a checkpoint for an input that shrank")
+	}
+}
@@ -3,9 +3,13 @@ package processing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -13,28 +17,51 @@ import (
 	"github.com/five82/reel/internal/chunk"
 	"github.com/five82/reel/internal/config"
 	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/encoder"
 	"github.com/five82/reel/internal/ffms"
 	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/indexcache"
 	"github.com/five82/reel/internal/keyframe"
 	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/svtlib"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
 	"github.com/five82/reel/internal/worker"
 )
 
-// ProcessChunked runs the chunked encoding pipeline for a single file.
-// Returns the crop result so the caller can use it for validation.
+// ChunkedResult describes how ProcessChunked transformed the source, for validation.
+type ChunkedResult struct {
+	Crop      CropResult
+	TimeScale float64       // Output duration over source duration; 1 unless slowed down
+	Chunks    ReportChunks  // Encode speed of the chunks encoded by this run
+	Memory    *ReportMemory // Peak memory while encoding; nil where it can't be measured
+
+	// Determinism is the result of encoding a chunk twice, with
+	// cfg.VerifyDeterminism
+	Determinism *DeterminismCheck
+}
+
+// ProcessChunked runs the chunked encoding pipeline for a single file. Audio
+// streams are encoded or copied as audio says, and time-stretched to match
+// if the video is slowed down. A non-nil crop was detected ahead of time and is used instead of
+// detecting it. Frames are encoded at bitDepth, 8 or 10.
+// Returns the crop and timing applied so the caller can use them for validation.
 func ProcessChunked(
 	ctx context.Context,
 	cfg *config.Config,
 	inputPath, outputPath string,
 	videoProps *ffprobe.VideoProperties,
 	audioStreams []ffprobe.AudioStreamInfo,
+	audio chunk.AudioSettings,
+	crop *CropResult,
 	quality uint32,
+	bitDepth uint8,
 	rep reporter.Reporter,
-) (CropResult, error) {
+) (ChunkedResult, error) {
 	// Create work directory
 	workDir := chunk.GetWorkDirPath(inputPath, cfg.GetTempDir())
 	if err := chunk.CreateWorkDir(workDir); err != nil {
-		return CropResult{}, fmt.Errorf("failed to create work directory: %w", err)
+		return ChunkedResult{}, fmt.Errorf("failed to create work directory: %w", err)
 	}
 
 	// Cleanup on completion (unless resuming a failed encode)
@@ -45,6 +72,29 @@ func ProcessChunked(
 		}
 	}()
 
+	// A source still being written is encoded as it grows, except that
+	// deinterlacing needs the finished file
+	growing := cfg.GrowingQuietSecs > 0
+	if growing && cfg.Deinterlace {
+		rep.StageProgress(reporter.StageProgress{Stage: "Waiting", Message: "Deinterlacing needs the finished source; waiting for it to stop growing"})
+		if err := waitUntilFinished(ctx, inputPath, growingQuiet(cfg)); err != nil {
+			return ChunkedResult{}, err
+		}
+		growing = false
+	}
+
+	// Interlaced sources are deinterlaced to a lossless intermediate that
+	// replaces the source for decoding; audio and subtitles still come from the source
+	videoSource := inputPath
+	if cfg.Deinterlace {
+		rep.StageProgress(reporter.StageProgress{Stage: "Preparing", Message: "Deinterlacing video"})
+		deinterlaced, err := deinterlaceSource(ctx, cfg.Tools, inputPath, workDir, videoProps.VideoIndex)
+		if err != nil {
+			return ChunkedResult{}, err
+		}
+		videoSource = deinterlaced
+	}
+
 	// ========================================================================
 	// PHASE 1: Run FFMS2 indexing and crop detection in parallel
 	// ========================================================================
@@ -52,23 +102,76 @@ func ProcessChunked(
 
 	var idx *ffms.VidIdx
 	var cropResult CropResult
+	var indexLoaded bool
+	var indexWriteErr error
+
+	// An interrupted encode of the same input left its analysis behind
+	savedCrop, reuseAnalysis := loadCheckpoint(workDir, inputPath, cfg)
+	indexPath, readIndex, cachedIndex := filepath.Join(workDir, indexFile), reuseAnalysis && !growing, false
+
+	// Sources are indexed once and the index reused by later encodes; a
+	// deinterlaced intermediate is new each run and stays in the work
+	// directory, and a growing source changes until it is finished
+	if cfg.IndexCacheDir != "" && videoSource == inputPath && !growing {
+		if path, err := indexcache.Path(cfg.IndexCacheDir, videoSource); err != nil {
+			rep.Verbose(fmt.Sprintf("Index cache unavailable: %v", err))
+		} else {
+			indexPath, readIndex, cachedIndex = path, true, true
+		}
+	}
 
 	phase1, _ := errgroup.WithContext(ctx)
 
 	// FFMS2 indexing goroutine
 	phase1.Go(func() error {
 		var err error
-		idx, err = ffms.NewVidIdx(inputPath, true)
+		if growing {
+			if idx, err = ffms.NewGrowingVidIdx(ctx, videoSource); err != nil {
+				return fmt.Errorf("failed to create video index: %w", err)
+			}
+			return nil
+		}
+		if readIndex {
+			if cachedIndex {
+				indexcache.Touch(indexPath)
+			}
+			if idx, err = ffms.ReadVidIdx(videoSource, indexPath); err == nil {
+				indexLoaded = true
+				return nil
+			}
+		}
+		indexing := newTaskProgress(rep, "Indexing", "bytes")
+		idx, err = ffms.NewVidIdx(ctx, videoSource, func(current, total int64) {
+			indexing.update(uint64(max(current, 0)), uint64(max(total, 0)))
+		})
 		if err != nil {
 			return fmt.Errorf("failed to create video index: %w", err)
 		}
+		indexing.finish()
+		indexWriteErr = writeIndex(idx, indexPath, cachedIndex, cfg.IndexCacheMaxMB)
 		return nil
 	})
 
 	// Crop detection goroutine
 	phase1.Go(func() error {
-		cropResult = DetectCrop(inputPath, videoProps, cfg.CropMode == "none")
-		return nil
+		if reuseAnalysis {
+			cropResult = savedCrop
+			return nil
+		}
+		if crop != nil {
+			cropResult = *crop
+			return nil
+		}
+		if cfg.CropFilter != "" {
+			var err error
+			cropResult, err = ManualCrop(cfg.CropFilter, videoProps)
+			return err
+		}
+		cropping := newTaskProgress(rep, "Crop detection", "samples")
+		cropResult = DetectCrop(ctx, cfg.Tools, inputPath, videoProps, cfg.CropMode == "none", cfg.CropConfidence, func(done, total int) {
+			cropping.update(uint64(done), uint64(total))
+		})
+		return ctx.Err() // A cancelled detection skipped samples
 	})
 
 	// Wait for phase 1 to complete
@@ -76,9 +179,27 @@ func ProcessChunked(
 		if idx != nil {
 			idx.Close()
 		}
-		return CropResult{}, err
+		return ChunkedResult{}, err
+	}
+	defer func() { idx.Close() }() // A growing source replaces idx
+
+	// The intermediate holds only the selected stream; the source may have
+	// cover art or other angles ahead of it
+	if videoSource == inputPath {
+		idx.SelectTrack(videoProps.StreamIndex)
+	}
+
+	if reuseAnalysis {
+		rep.Verbose("Reusing crop detection from the interrupted encode")
+	} else if err := saveCheckpoint(workDir, inputPath, cfg, cropResult); err != nil {
+		rep.Verbose(fmt.Sprintf("Analysis won't be reused if interrupted: %v", err))
+	}
+	if indexLoaded {
+		rep.Verbose(fmt.Sprintf("Reusing video index %s", indexPath))
+	}
+	if indexWriteErr != nil {
+		rep.Verbose(fmt.Sprintf("Video index won't be reused: %v", indexWriteErr))
 	}
-	defer idx.Close()
 
 	// Report crop detection result
 	rep.CropResult(reporter.CropSummary{
@@ -87,32 +208,213 @@ func ProcessChunked(
 		Required: cropResult.Required,
 		Disabled: cfg.CropMode == "none",
 	})
+	if len(cropResult.Votes) > 0 {
+		rep.Verbose("Crop samples: " + FormatCropVotes(cropResult))
+	}
 
 	// Get video info (needs index)
 	vidInf, err := ffms.GetVidInf(idx)
 	if err != nil {
-		return CropResult{}, fmt.Errorf("failed to get video info: %w", err)
+		return ChunkedResult{}, fmt.Errorf("failed to get video info: %w", err)
+	}
+	vidInf.Output8Bit = bitDepth == 8
+
+	// PAL speedup correction re-signals 25fps video at the film rate and
+	// stretches audio and subtitles to match
+	outInf, timeScale := vidInf, 1.0
+	if cfg.PALSlowdown {
+		if isPALRate(vidInf.FPSNum, vidInf.FPSDen) {
+			slowed := *vidInf
+			slowed.FPSNum, slowed.FPSDen = filmFPSNum, filmFPSDen
+			outInf, timeScale = &slowed, palTimeScale
+			rep.Verbose(fmt.Sprintf("PAL slowdown: 25fps to %d/%d, duration x%.4f", filmFPSNum, filmFPSDen, timeScale))
+		} else {
+			rep.Warning(fmt.Sprintf("Source is %.3f fps, not PAL 25 fps; encoding without slowdown",
+				float64(vidInf.FPSNum)/float64(vidInf.FPSDen)))
+		}
 	}
 
-	// Generate fixed-length chunks based on resolution (using config values)
+	// Generate fixed-length chunks based on resolution (using config values),
+	// shorter for a short input so each worker gets a chunk
 	chunkDuration := cfg.ChunkDurationForWidth(vidInf.Width)
-	rep.StageProgress(reporter.StageProgress{Stage: "Chunking", Message: fmt.Sprintf("Creating %.0fs chunks", chunkDuration)})
-	sceneFile, err := keyframe.ExtractKeyframesIfNeeded(
-		inputPath,
-		workDir,
-		vidInf.FPSNum,
-		vidInf.FPSDen,
-		vidInf.Frames,
-		chunkDuration,
-	)
+	fileWorkers := cfg.Workers
+	if override := cfg.ParallelForWidth(vidInf.Width); override.Workers > 0 {
+		fileWorkers = override.Workers
+	}
+	fileWorkers = encode.ShareWorkers(fileWorkers, cfg.ParallelFiles)
+	inputSecs := float64(vidInf.Frames) * float64(vidInf.FPSDen) / float64(vidInf.FPSNum)
+	if short := shortInputChunkSecs(inputSecs, chunkDuration, fileWorkers); short != chunkDuration && !growing {
+		rep.Verbose(fmt.Sprintf("Short input (%s): %.1fs chunks instead of %.0fs to give %d workers a chunk each",
+			util.FormatDuration(inputSecs), short, chunkDuration, fileWorkers))
+		chunkDuration = short
+	}
+
+	// Convert crop filter to cropH/cropV
+	var cropH, cropV uint32
+	if cropResult.Required && cropResult.CropFilter != "" {
+		cropH, cropV = parseCropFilter(cropResult.CropFilter, videoProps.Width, videoProps.Height)
+		rep.Verbose(fmt.Sprintf("Crop offsets: horizontal %d, vertical %d", cropH, cropV))
+	}
+
+	// Setup encode config
+	// Encode times of the chunks, including estimate probes
+	var timings []encode.ChunkTiming
+	var running int // Workers running, as the encode scales them
+	encCfg := &encode.EncodeConfig{
+		Workers:               cfg.Workers,
+		ChunkBuffer:           cfg.ChunkBuffer,
+		DecodeAhead:           cfg.DecodeAhead,
+		CRF:                   float32(quality),
+		Preset:                cfg.PresetForWidth(videoProps.Width),
+		Tune:                  cfg.SVTAV1Tune,
+		ACBias:                cfg.SVTAV1ACBias,
+		EnableVarianceBoost:   cfg.SVTAV1EnableVarianceBoost,
+		VarianceBoostStrength: cfg.SVTAV1VarianceBoostStrength,
+		VarianceOctile:        cfg.SVTAV1VarianceOctile,
+		LogicalProcessors:     cfg.ThreadsPerWorker,
+		FixedWorkers:          cfg.DisableMemoryCap,
+		MemPerWorker:          cfg.MemPerWorker,
+		NUMA:                  numaPinning(cfg),
+		DuplicateStragglers:   cfg.DuplicateStragglers,
+		ChunkRetries:          cfg.ChunkRetries,
+		RetryFewerThreads:     cfg.RetryFewerThreads,
+		ChunkTimeout:          time.Duration(cfg.ChunkTimeoutSecs * float64(time.Second)),
+		KeyintSecs:            cfg.KeyintSecs,
+		AssumeRec601:          cfg.AssumeRec601,
+		FilmGrain:             cfg.FilmGrain,
+		Denoise:               cfg.FilmGrainDenoise,
+		SCM:                   cfg.SVTAV1SCM,
+		DisableSCD:            !cfg.SVTAV1SceneDetection,
+		TileRows:              cfg.SVTAV1TileRows,
+		TileColumns:           cfg.SVTAV1TileColumns,
+		Schedule:              cfg.Schedule,
+		OnSchedulePause: func(resume time.Time) {
+			rep.Warning(fmt.Sprintf("Outside schedule %s; running chunks will finish, new chunks start at %s",
+				cfg.Schedule, resume.Format("15:04")))
+		},
+		OnPause: func(path string) {
+			rep.Warning(fmt.Sprintf("Paused by %s; running chunks will finish, remove it to resume", path))
+		},
+		OnResume: func() {
+			rep.Verbose("Pause file removed; resuming chunk dispatch")
+		},
+		OnWorkerCap: func(c encode.WorkerCap) {
+			reporter.ReportWorkerCap(rep, reporter.WorkerCap{
+				Requested:    c.Requested,
+				Granted:      c.Granted,
+				MemPerWorker: c.MemPerWorker,
+				Available:    c.Available,
+				Raised:       c.Granted > running,
+				Lowered:      c.Granted < running,
+			})
+			running = c.Granted
+		},
+		OnChunkComplete: func(t encode.ChunkTiming) {
+			timings = append(timings, t)
+		},
+		OnChunkRetry: func(r encode.ChunkRetry) {
+			reporter.ReportChunkRetry(rep, reporter.ChunkRetry{
+				Chunk:   r.Chunk,
+				Attempt: r.Attempt,
+				Retries: r.Retries,
+				Threads: r.Threads,
+				Delay:   r.Delay,
+				Reason:  r.Err.Error(),
+			})
+		},
+	}
+
+	if cfg.FilmGrainTable != "" {
+		encCfg.GrainTable = &cfg.FilmGrainTable
+	}
+	if encCfg.ExtraParams, err = config.ParseSvtParams(cfg.SVTAV1ExtraParams); err != nil {
+		return ChunkedResult{}, err
+	}
+
+	// Apply per-resolution overrides from the config file
+	if override := cfg.ParallelForWidth(vidInf.Width); override.IsSet() {
+		if override.Workers > 0 {
+			encCfg.Workers = override.Workers
+			encCfg.FixedWorkers = true
+		}
+		if override.Threads > 0 {
+			encCfg.LogicalProcessors = override.Threads
+		}
+		if override.Buffer > 0 {
+			encCfg.ChunkBuffer = override.Buffer
+		}
+		rep.Verbose(fmt.Sprintf("Parallel overrides: workers=%d, threads=%d, buffer=%d",
+			encCfg.Workers, encCfg.LogicalProcessors, encCfg.ChunkBuffer))
+	}
+
+	// Encode the finished part of a growing source until it stops growing;
+	// the chunks of the finished file then resume from those
+	if growing {
+		idx, vidInf, err = encodeGrowing(ctx, growingEncode{
+			cfg:         cfg,
+			inputPath:   inputPath,
+			streamIndex: videoProps.StreamIndex,
+			workDir:     workDir,
+			encCfg:      encCfg,
+			cropH:       cropH,
+			cropV:       cropV,
+			chunkSecs:   chunkDuration,
+			bitDepth:    bitDepth,
+			outFPSNum:   outInf.FPSNum,
+			outFPSDen:   outInf.FPSDen,
+			rep:         rep,
+		}, idx, vidInf)
+		if err != nil {
+			return ChunkedResult{}, err
+		}
+		if timeScale != 1 {
+			slowed := *vidInf
+			slowed.FPSNum, slowed.FPSDen = filmFPSNum, filmFPSDen
+			outInf = &slowed
+		} else {
+			outInf = vidInf
+		}
+	}
+
+	message := fmt.Sprintf("Creating %gs chunks", chunkDuration)
+	plan := func() ([]int, error) {
+		return keyframe.GenerateFixedChunks(vidInf.Frames, vidInf.FPSNum, vidInf.FPSDen, chunkDuration), nil
+	}
+	if cfg.ChunkStrategy == config.ChunkBalanced {
+		message = fmt.Sprintf("Creating chunks balanced around %gs", chunkDuration)
+		plan = func() ([]int, error) {
+			return balancedChunks(cfg.Tools, inputPath, vidInf, chunkDuration, rep), nil
+		}
+	}
+	if planner, ok := cfg.ChunkPlanner.(chunk.Planner); ok {
+		message = "Creating chunks with the chunk planner"
+		strategy := plan
+		plan = func() ([]int, error) {
+			starts, err := strategy()
+			if err != nil {
+				return nil, err
+			}
+			planned, err := planner.PlanChunks(vidInf, chunk.ScenesFromStarts(starts, vidInf.Frames))
+			if err != nil {
+				return nil, fmt.Errorf("chunk planner failed: %w", err)
+			}
+			starts, err = chunk.StartsOf(planned, vidInf.Frames)
+			if err != nil {
+				return nil, fmt.Errorf("chunk planner returned invalid chunks: %w", err)
+			}
+			return starts, nil
+		}
+	}
+	rep.StageProgress(reporter.StageProgress{Stage: "Chunking", Message: message})
+	sceneFile, err := keyframe.PlanChunksIfNeeded(workDir, plan)
 	if err != nil {
-		return CropResult{}, fmt.Errorf("chunk generation failed: %w", err)
+		return ChunkedResult{}, fmt.Errorf("chunk generation failed: %w", err)
 	}
 
 	// Load scenes
 	scenes, err := chunk.LoadScenes(sceneFile, vidInf.Frames)
 	if err != nil {
-		return CropResult{}, fmt.Errorf("failed to load scenes: %w", err)
+		return ChunkedResult{}, fmt.Errorf("failed to load scenes: %w", err)
 	}
 	rep.Verbose(fmt.Sprintf("Created %d chunks", len(scenes)))
 
@@ -130,71 +432,171 @@ func ProcessChunked(
 	avgChunkDuration := avgChunkFrames / fps
 	rep.Verbose(fmt.Sprintf("Average chunk duration: %.1fs (%d frames)", avgChunkDuration, int(avgChunkFrames)))
 
-	// Convert crop filter to cropH/cropV
-	var cropH, cropV uint32
-	if cropResult.Required && cropResult.CropFilter != "" {
-		cropH, cropV = parseCropFilter(cropResult.CropFilter, videoProps.Width, videoProps.Height)
-		rep.Verbose(fmt.Sprintf("Crop offsets: horizontal %d, vertical %d", cropH, cropV))
+	// Completed chunks only carry over if the chunk layout is unchanged
+	totals := chunk.Totals{Frames: totalFrames, Chunks: len(chunks)}
+	saved, ok, err := chunk.ReadTotals(workDir)
+	if err != nil {
+		return ChunkedResult{}, err
 	}
-
-	// Setup encode config
-	encCfg := &encode.EncodeConfig{
-		Workers:               cfg.Workers,
-		ChunkBuffer:           cfg.ChunkBuffer,
-		CRF:                   float32(quality),
-		Preset:                cfg.SVTAV1Preset,
-		Tune:                  cfg.SVTAV1Tune,
-		ACBias:                cfg.SVTAV1ACBias,
-		EnableVarianceBoost:   cfg.SVTAV1EnableVarianceBoost,
-		VarianceBoostStrength: cfg.SVTAV1VarianceBoostStrength,
-		VarianceOctile:        cfg.SVTAV1VarianceOctile,
-		LogicalProcessors:     cfg.ThreadsPerWorker,
+	if ok && saved != totals {
+		rep.Warning(fmt.Sprintf("Chunk layout changed since the interrupted encode (%d chunks, now %d); re-encoding all chunks",
+			saved.Chunks, totals.Chunks))
+		if err := chunk.ResetResume(workDir); err != nil {
+			return ChunkedResult{}, err
+		}
+	}
+	if err := chunk.WriteTotals(workDir, totals); err != nil {
+		return ChunkedResult{}, err
+	}
+	resumed, err := chunk.GetResume(workDir)
+	if err != nil {
+		return ChunkedResult{}, fmt.Errorf("failed to load resume info: %w", err)
+	}
+	resumed = resumed.Valid(chunks)
+
+	// No more workers than chunks left to encode
+	remaining := len(chunks) - len(resumed.ChunksDone)
+	if capped := workersForChunks(encCfg.Workers, remaining, cfg.ParallelFiles); capped < encCfg.Workers {
+		rep.StageProgress(reporter.StageProgress{Stage: "Chunking", Message: fmt.Sprintf("Only %d chunks to encode; using %d workers instead of %d",
+			remaining, encode.ShareWorkers(capped, cfg.ParallelFiles), encode.ShareWorkers(encCfg.Workers, cfg.ParallelFiles))})
+		encCfg.Workers = capped
 	}
 
 	// Calculate actual workers (may be capped based on resolution and memory)
-	actualWorkers, wasCapped := encode.CapWorkers(cfg.Workers, vidInf.Width, vidInf.Height)
+	actualWorkers, wasCapped := encCfg.Workers, false
+	var workerCap encode.WorkerCap
+	if !encCfg.FixedWorkers {
+		workerCap = encode.CapWorkers(encCfg.Workers, vidInf.Width, vidInf.Height, encCfg.MemPerWorker)
+		actualWorkers, wasCapped = workerCap.Granted, workerCap.Capped()
+		reporter.ReportWorkerCap(rep, reporter.WorkerCap{
+			Requested:    workerCap.Requested,
+			Granted:      workerCap.Granted,
+			MemPerWorker: workerCap.MemPerWorker,
+			Available:    workerCap.Available,
+		})
+	}
+	encCfg.SharedFiles = cfg.ParallelFiles
+	actualWorkers = encode.ShareWorkers(actualWorkers, encCfg.SharedFiles)
+	running = actualWorkers
+	if !encCfg.FixedWorkers {
+		running = encode.StartWorkers(workerCap, actualWorkers)
+	}
+
+	// A copied stream can't be time-stretched, so a slowdown encodes it instead
+	if timeScale != 1 && slices.ContainsFunc(audioStreams, audio.Copies) {
+		rep.Warning("Copied audio can't be time-stretched for PAL slowdown; encoding it to Opus instead")
+	}
+	audio.Tempo = 1 / timeScale
+	audioRate := audioBytesPerSecond(audioStreams, audio)
+
+	if cfg.EstimateSize {
+		est, err := reportEstimate(ctx, inputPath, chunks, vidInf, encCfg, idx, workDir, cropH, cropV, actualWorkers, audioRate, rep)
+		if err != nil {
+			return ChunkedResult{}, err
+		}
+		if exceedsSizeLimit(est.EstimatedSize, est.OriginalSize, cfg.AbortSizeRatio) {
+			return ChunkedResult{}, &SizeAbortError{Projected: est.EstimatedSize, Source: est.OriginalSize, Ratio: cfg.AbortSizeRatio}
+		}
+	}
+
+	var determinism *DeterminismCheck
+	if cfg.VerifyDeterminism {
+		if determinism, err = verifyDeterminism(ctx, chunks, vidInf, encCfg, idx, workDir, cropH, cropV, rep); err != nil {
+			return ChunkedResult{}, err
+		}
+	}
 
 	// Show both requested and actual worker counts
 	var workerMsg string
-	if wasCapped {
-		workerMsg = fmt.Sprintf("Starting chunked encoding with %d/%d workers (memory limited)", actualWorkers, cfg.Workers)
-	} else {
+	switch {
+	case running < actualWorkers:
+		workerMsg = fmt.Sprintf("Starting chunked encoding with %d workers, up to %d as memory allows", running, encode.ShareWorkers(encCfg.Workers, encCfg.SharedFiles))
+	case wasCapped:
+		workerMsg = fmt.Sprintf("Starting chunked encoding with %d/%d workers (memory limited)", actualWorkers, encCfg.Workers)
+	default:
 		workerMsg = fmt.Sprintf("Starting chunked encoding with %d workers", actualWorkers)
 	}
+	if encCfg.SharedFiles > 1 {
+		workerMsg += fmt.Sprintf(" (%d files encoding at once)", encCfg.SharedFiles)
+	}
 	rep.StageProgress(reporter.StageProgress{Stage: "Encoding", Message: workerMsg})
 
-	rep.EncodingStarted(uint64(vidInf.Frames))
+	reporter.ReportEncodingStarted(rep, reporter.EncodingStart{
+		TotalFrames:   uint64(totalFrames),
+		TotalChunks:   len(chunks),
+		ResumedFrames: uint64(resumed.TotalEncodedFrames()),
+		ResumedChunks: len(resumed.ChunksDone),
+	})
+
+	// Frames finished by an earlier run or the estimate probes don't count toward speed
+	resume, err := chunk.GetResume(workDir)
+	if err != nil {
+		return ChunkedResult{}, fmt.Errorf("failed to load resume info: %w", err)
+	}
+	framesBefore := resume.Valid(chunks).TotalEncodedFrames()
 
 	startTime := time.Now()
 
+	// The encode is cancelled with a SizeAbortError once the projection is
+	// trustworthy and over the limit
+	var sourceSize uint64
+	if info, err := os.Stat(inputPath); err == nil {
+		sourceSize = uint64(info.Size())
+	}
+	encodeCtx, cancelEncode := context.WithCancelCause(ctx)
+	defer cancelEncode(nil)
+	if cfg.DeadlineChunks && !cfg.Deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		encodeCtx, cancelDeadline = context.WithDeadlineCause(encodeCtx, cfg.Deadline, ErrDeferred)
+		defer cancelDeadline()
+	}
+	chunkCtx, cancelChunks := context.WithCancelCause(encode.ChunkContext(ctx))
+	defer cancelChunks(nil)
+	encodeCtx = encode.WithFinishInFlight(encodeCtx, chunkCtx)
+
+	rate := newFPSMeter(startTime, framesBefore)
 	progressCallback := func(progress worker.Progress) {
-		// Calculate speed and ETA
-		elapsed := time.Since(startTime)
+		// Calculate speed and ETA, counting the frames of chunks still
+		// running
+		now := time.Now()
+		elapsed := now.Sub(startTime)
+		encoded := progress.FramesEncoded()
 		var speed float32
 		var eta time.Duration
 
-		if elapsed.Seconds() > 0 && progress.FramesComplete > 0 {
-			// Video seconds encoded
-			videoSeconds := float64(progress.FramesComplete) / fps
+		if elapsed.Seconds() > 0 && encoded > framesBefore {
+			// Video seconds encoded this run
+			videoSeconds := float64(encoded-framesBefore) / fps
 			// Speed = video seconds per real second
 			speed = float32(videoSeconds / elapsed.Seconds())
 
 			// ETA based on remaining frames
 			if speed > 0 {
-				remainingFrames := progress.FramesTotal - progress.FramesComplete
+				remainingFrames := progress.FramesTotal - encoded
 				remainingVideoSeconds := float64(remainingFrames) / fps
 				eta = time.Duration(remainingVideoSeconds/float64(speed)) * time.Second
 			}
 		}
 
+		projected, kbps := projectOutput(progress.BytesComplete, progress.FramesComplete, progress.FramesTotal, fps, audioRate)
+		if float64(progress.FramesComplete) >= abortMinFraction*float64(progress.FramesTotal) &&
+			exceedsSizeLimit(projected, sourceSize, cfg.AbortSizeRatio) {
+			abortErr := &SizeAbortError{Projected: projected, Source: sourceSize, Ratio: cfg.AbortSizeRatio}
+			cancelEncode(abortErr)
+			cancelChunks(abortErr)
+		}
+
 		rep.EncodingProgress(reporter.ProgressSnapshot{
-			CurrentFrame:   uint64(progress.FramesComplete),
+			CurrentFrame:   uint64(encoded),
 			TotalFrames:    uint64(progress.FramesTotal),
 			Percent:        float32(progress.Percent()),
 			Speed:          speed,
+			FPS:            float32(rate.add(now, encoded)),
 			ETA:            eta,
 			ChunksComplete: progress.ChunksComplete,
 			ChunksTotal:    progress.ChunksTotal,
+			BitrateKbps:    kbps,
+			ProjectedSize:  projected,
 		})
 	}
 
@@ -208,59 +610,209 @@ func ProcessChunked(
 	if len(audioStreams) > 0 {
 		go func() {
 			defer close(audioDone)
-			audioErr = chunk.ExtractAudio(inputPath, workDir, audioStreams)
+			extracting := newTaskProgress(rep, "Audio extraction", "seconds")
+			duration := uint64(videoProps.DurationSecs)
+			var loudness map[int]float64
+			loudness, audioErr = chunk.ExtractAudio(cfg.Tools, inputPath, workDir, audioStreams, audio, func(seconds float64) {
+				extracting.update(uint64(seconds), duration)
+			})
+			if audioErr == nil {
+				extracting.finish()
+				for _, i := range slices.Sorted(maps.Keys(loudness)) {
+					rep.Verbose(fmt.Sprintf("Audio track %d loudness: %.1f LUFS", i, loudness[i]))
+				}
+			}
 		}()
 	} else {
 		close(audioDone)
 	}
 
 	// Run parallel video encode
-	_, encodeErr := encode.EncodeAll(
-		ctx,
-		chunks,
-		vidInf,
-		encCfg,
-		idx,
-		workDir,
-		cropH,
-		cropV,
-		progressCallback,
-	)
+	runEncode := func() error {
+		_, err := encode.EncodeAll(
+			encodeCtx,
+			chunks,
+			outInf,
+			encCfg,
+			idx,
+			workDir,
+			cropH,
+			cropV,
+			progressCallback,
+		)
+		return err
+	}
+	memory := startMemorySampler(cfg.Tools)
+	encodeErr := runEncode()
+
+	// Check every chunk before the merge, re-encoding damaged or short ones
+	for round := 0; encodeErr == nil; round++ {
+		faults := chunk.VerifyChunks(workDir, chunks)
+		if len(faults) == 0 {
+			break
+		}
+		if round == chunkRepairRounds {
+			encodeErr = fmt.Errorf("%d chunks still failed verification after re-encoding, first chunk %d: %s",
+				len(faults), faults[0].Idx, faults[0].Reason)
+			break
+		}
+		if encodeErr = discardChunks(workDir, faults, rep); encodeErr == nil {
+			encodeErr = runEncode()
+		}
+	}
+	peakMemory := memory.finish()
 
 	if encodeErr != nil {
 		// Wait for audio to finish before returning
 		<-audioDone
-		return CropResult{}, fmt.Errorf("chunked encoding failed: %w", encodeErr)
+		var sizeErr *SizeAbortError
+		if errors.As(context.Cause(encodeCtx), &sizeErr) {
+			return ChunkedResult{}, sizeErr
+		}
+		if errors.Is(context.Cause(encodeCtx), ErrDeferred) && ctx.Err() == nil {
+			return ChunkedResult{}, fmt.Errorf("%w: stopped at %s with encoded chunks kept for resuming",
+				ErrDeferred, cfg.Deadline.Format("15:04"))
+		}
+		return ChunkedResult{}, fmt.Errorf("chunked encoding failed: %w", encodeErr)
 	}
 
 	// Merge IVF files
 	rep.StageProgress(reporter.StageProgress{Stage: "Merging", Message: "Merging encoded chunks"})
-	if len(chunks) > 500 {
-		// Use batched merge for large number of chunks
-		if err := chunk.MergeBatched(workDir, len(chunks)); err != nil {
-			<-audioDone
-			return CropResult{}, fmt.Errorf("batched merge failed: %w", err)
-		}
-	}
-
-	if err := chunk.MergeOutput(workDir, outputPath, vidInf, inputPath); err != nil {
+	if err := chunk.MergeOutput(workDir, chunks, outInf); err != nil {
 		<-audioDone
-		return CropResult{}, fmt.Errorf("video merge failed: %w", err)
+		return ChunkedResult{}, fmt.Errorf("video merge failed: %w", err)
 	}
 
 	// Wait for audio extraction to complete
 	<-audioDone
 	if audioErr != nil {
-		return CropResult{}, fmt.Errorf("audio extraction failed: %w", audioErr)
+		return ChunkedResult{}, fmt.Errorf("audio extraction failed: %w", audioErr)
 	}
 
 	// Final mux
 	rep.StageProgress(reporter.StageProgress{Stage: "Muxing", Message: "Creating final output"})
-	if err := chunk.MuxFinal(inputPath, workDir, outputPath, audioStreams); err != nil {
-		return CropResult{}, fmt.Errorf("final mux failed: %w", err)
+	settings := encodeSettings(cfg, inputPath, encCfg)
+	if err := chunk.MuxFinal(cfg.Tools, inputPath, workDir, outputPath, audioStreams, audio.Downmix, videoProps.CoverArt, !cfg.DropAttachments, settings, timeScale); err != nil {
+		return ChunkedResult{}, fmt.Errorf("final mux failed: %w", err)
+	}
+	if written, err := chunk.WriteTrackStatistics(outputPath); err != nil {
+		rep.Verbose(fmt.Sprintf("Track statistics not written: %v", err))
+	} else if written {
+		rep.Verbose("Wrote track statistics tags with mkvpropedit")
+	}
+
+	chunkStats := newReportChunks(timings, fps)
+	reportChunkSpeed(rep, chunkStats)
+	if peakMemory != nil {
+		peakMemory.Estimate = workerCap.MemPerWorker
+		reportPeakMemory(rep, *peakMemory)
+	}
+
+	return ChunkedResult{Crop: cropResult, TimeScale: timeScale, Chunks: chunkStats, Memory: peakMemory, Determinism: determinism}, nil
+}
+
+// chunkRepairRounds is how many times chunks failing verification are
+// re-encoded before the encode fails.
+const chunkRepairRounds = 2
+
+// discardChunks removes the IVFs of faulty chunks and their resume entries,
+// so the next encode pass encodes them again.
+func discardChunks(workDir string, faults []chunk.ChunkFault, rep reporter.Reporter) error {
+	idxs := make([]int, 0, len(faults))
+	for _, f := range faults {
+		rep.Warning(fmt.Sprintf("Chunk %d failed verification (%s); re-encoding", f.Idx, f.Reason))
+		if err := os.Remove(chunk.IVFPath(workDir, f.Idx)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove chunk %d: %w", f.Idx, err)
+		}
+		idxs = append(idxs, f.Idx)
 	}
+	return chunk.ForgetDone(workDir, idxs)
+}
+
+// encodeSettings returns the settings recorded in the output's metadata.
+func encodeSettings(cfg *config.Config, inputPath string, encCfg *encode.EncodeConfig) *chunk.Settings {
+	return &chunk.Settings{
+		Version: cfg.Version,
+		Source:  filepath.Base(inputPath),
+		CRF:     uint32(encCfg.CRF),
+		Preset:  encCfg.Preset,
+		SvtParams: encoder.SettingsString(&encoder.EncConfig{
+			CRF:                   encCfg.CRF,
+			Preset:                encCfg.Preset,
+			Tune:                  encCfg.Tune,
+			GrainTable:            encCfg.GrainTable,
+			ACBias:                encCfg.ACBias,
+			EnableVarianceBoost:   encCfg.EnableVarianceBoost,
+			VarianceBoostStrength: encCfg.VarianceBoostStrength,
+			VarianceOctile:        encCfg.VarianceOctile,
+			FilmGrain:             encCfg.FilmGrain,
+			Denoise:               encCfg.Denoise,
+			SCM:                   encCfg.SCM,
+			DisableSCD:            encCfg.DisableSCD,
+			TileRows:              encCfg.TileRows,
+			TileColumns:           encCfg.TileColumns,
+			ExtraParams:           encCfg.ExtraParams,
+		}),
+		Attach: cfg.AttachSettings,
+	}
+}
+
+// balancedChunks plans chunks of about chunkSecs whose estimated encode
+// time is equal, judging complexity by the size of each frame in the source.
+// Falls back to fixed chunks if the source's packets can't be read.
+func balancedChunks(paths toolpath.Paths, inputPath string, vidInf *ffms.VidInf, chunkSecs float64, rep reporter.Reporter) []int {
+	fps := float64(vidInf.FPSNum) / float64(vidInf.FPSDen)
+	packets, err := ffprobe.GetVideoPackets(paths, inputPath)
+	costs := chunk.FrameCosts(packets, fps, vidInf.Frames)
+	if err != nil || costs == nil {
+		rep.Warning("Couldn't read frame sizes for balanced chunks; using fixed chunks")
+		return keyframe.GenerateFixedChunks(vidInf.Frames, vidInf.FPSNum, vidInf.FPSDen, chunkSecs)
+	}
+
+	starts := chunk.PlanBalanced(costs, max(int(fps*chunkSecs), 1))
+	shortest, longest := vidInf.Frames, 0
+	for i, start := range starts {
+		end := vidInf.Frames
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		shortest, longest = min(shortest, end-start), max(longest, end-start)
+	}
+	rep.Verbose(fmt.Sprintf("Balanced chunks: %d from %.1fs to %.1fs", len(starts),
+		float64(shortest)/fps, float64(longest)/fps))
+	return starts
+}
+
+// reportPeakMemory reports the peak memory of the encode, set against the
+// memory per worker that capping assumed.
+func reportPeakMemory(rep reporter.Reporter, m ReportMemory) {
+	msg := fmt.Sprintf("Peak memory: %s total, %s in reel", util.FormatBytes(m.PeakTotal), util.FormatBytes(m.PeakReel))
+	if m.PeakEncoders > 0 {
+		msg += fmt.Sprintf(", largest encoder %s (%d running at most)", util.FormatBytes(m.PeakEncoder), m.PeakEncoders)
+	}
+	if m.Estimate > 0 {
+		msg += fmt.Sprintf("; capping assumed %s per worker", util.FormatBytes(m.Estimate))
+	}
+	rep.Verbose(msg)
+}
 
-	return cropResult, nil
+// reportChunkSpeed reports the chunk encode speed and the slowest chunks,
+// which usually hold the most complex scenes.
+func reportChunkSpeed(rep reporter.Reporter, stats ReportChunks) {
+	if stats.Encoded == 0 {
+		return
+	}
+	rep.Verbose(fmt.Sprintf("Chunk encode speed: %d chunks, median %.1f fps, mean %.1f fps",
+		stats.Encoded, stats.MedianFPS, stats.MeanFPS))
+	rep.Verbose(fmt.Sprintf("Frame pipeline: decoding %.0f%% of chunk time, writing to the encoder %.0f%%",
+		stats.DecodeShare*100, stats.PipeShare*100))
+	rep.Verbose(fmt.Sprintf("Frame pipeline stalls: decoder waiting on the encoder %.0f%%, encoder waiting on the decoder %.0f%%",
+		stats.DecodeStallShare*100, stats.EncodeStallShare*100))
+	for _, c := range stats.Slowest {
+		rep.Verbose(fmt.Sprintf("Slow chunk %d: frames %d-%d (%s-%s), %.1f fps, %s",
+			c.Chunk, c.StartFrame, c.EndFrame-1, util.FormatDuration(c.StartSecs), util.FormatDuration(c.EndSecs),
+			c.FPS, util.FormatDuration(c.EncodeSecs)))
+	}
 }
 
 // parseCropFilter extracts cropH and cropV from a crop filter string.
@@ -282,17 +834,99 @@ func parseCropFilter(filter string, srcWidth, srcHeight uint32) (cropH, cropV ui
 	return cropH, cropV
 }
 
+// numaPinning returns how to pin workers to NUMA nodes, or nil when pinning
+// is off or the machine has a single node.
+func numaPinning(cfg *config.Config) *encode.NUMAPinning {
+	if cfg.NUMA != config.NUMAAuto {
+		return nil
+	}
+	return encode.DetectNUMAPinning()
+}
+
 // CheckChunkedDependencies verifies that required tools are available.
-func CheckChunkedDependencies() error {
-	// Check for SvtAv1EncApp in PATH
-	if _, err := exec.LookPath("SvtAv1EncApp"); err != nil {
-		return fmt.Errorf("SvtAv1EncApp not found in PATH (required for encoding)")
+func CheckChunkedDependencies(paths toolpath.Paths) error {
+	// Check for SvtAv1EncApp, in PATH unless pinned, unless it is linked
+	if _, err := exec.LookPath(paths.Path(toolpath.SvtAv1EncApp)); err != nil && !svtlib.Linked {
+		return fmt.Errorf("SvtAv1EncApp not found (required for encoding): %w", err)
 	}
 
-	// Check for ffmpeg in PATH (used for audio extraction)
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return fmt.Errorf("ffmpeg not found in PATH (required for audio extraction)")
+	// Check for ffmpeg (used for audio extraction)
+	if _, err := exec.LookPath(paths.Path(toolpath.FFmpeg)); err != nil {
+		return fmt.Errorf("ffmpeg not found (required for audio extraction): %w", err)
 	}
 
 	return nil
 }
+
+// reportEstimate encodes probe chunks spread across the video and reports the
+// projected output. Probes are written to the normal encode directory, so the
+// main encode picks them up as already done.
+func reportEstimate(
+	ctx context.Context,
+	inputPath string,
+	chunks []chunk.Chunk,
+	vidInf *ffms.VidInf,
+	encCfg *encode.EncodeConfig,
+	idx *ffms.VidIdx,
+	workDir string,
+	cropH, cropV uint32,
+	workers int,
+	audioRate float64,
+	rep reporter.Reporter,
+) (reporter.EncodeEstimate, error) {
+	probes := selectProbeChunks(chunks, min(maxProbeChunks, workers))
+
+	before, err := chunk.GetResume(workDir)
+	if err != nil {
+		return reporter.EncodeEstimate{}, fmt.Errorf("failed to load resume info: %w", err)
+	}
+	doneBefore := before.Valid(chunks).DoneSet()
+	resumed := false
+	for _, p := range probes {
+		if doneBefore[p.Idx] {
+			resumed = true
+		}
+	}
+
+	rep.StageProgress(reporter.StageProgress{
+		Stage:   "Encoding",
+		Message: fmt.Sprintf("Encoding %d probe chunks to estimate output size", len(probes)),
+	})
+	start := time.Now()
+	if _, err := encode.EncodeAll(ctx, probes, vidInf, encCfg, idx, workDir, cropH, cropV, nil); err != nil {
+		return reporter.EncodeEstimate{}, fmt.Errorf("probe encoding failed: %w", err)
+	}
+	elapsed := time.Since(start)
+	if resumed {
+		// Some probes came from an earlier run, so the timing covers only part of them
+		elapsed = 0
+	}
+
+	after, err := chunk.GetResume(workDir)
+	if err != nil {
+		return reporter.EncodeEstimate{}, fmt.Errorf("failed to load resume info: %w", err)
+	}
+	isProbe := make(map[int]bool, len(probes))
+	for _, p := range probes {
+		isProbe[p.Idx] = true
+	}
+	var done []chunk.ChunkComp
+	for _, c := range after.Valid(chunks).ChunksDone {
+		if isProbe[c.Idx] {
+			done = append(done, c)
+		}
+	}
+
+	totalFrames := 0
+	for _, c := range chunks {
+		totalFrames += c.Frames()
+	}
+	fps := float64(vidInf.FPSNum) / float64(vidInf.FPSDen)
+
+	est := extrapolateEstimate(done, totalFrames, fps, audioRate, elapsed, workers)
+	if info, err := os.Stat(inputPath); err == nil {
+		est.OriginalSize = uint64(info.Size())
+	}
+	reporter.ReportEncodeEstimate(rep, est)
+	return est, nil
+}
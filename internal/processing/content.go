@@ -0,0 +1,138 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"slices"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// Content classification samples a few downscaled grayscale frames and looks
+// at how much of each picture is flat. Camera footage carries sensor noise or
+// grain almost everywhere; animation has large flat-shaded areas; rendered
+// screen content is flat to the exact pixel value.
+const (
+	contentSamples     = 8
+	contentFrameWidth  = 320
+	contentFrameHeight = 180
+	contentBlockSize   = 8
+
+	flatBlockVariance   = 2.0  // Max pixel variance for a block to count as flat
+	blackBlockMean      = 24.0 // Blocks darker than this (letterboxing, fades) are ignored
+	animeFlatFraction   = 0.45 // Median flat share above which a source is animation
+	screenExactFraction = 0.5  // Median share of perfectly uniform blocks for screen content
+)
+
+// DetectContent classifies a source as film, anime, or screen content.
+// Falls back to film when frames can't be sampled.
+func DetectContent(paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties) string {
+	var frames [][]byte
+	for i := range contentSamples {
+		// Sample evenly between 15% and 85%, like crop detection
+		pos := 0.15 + 0.7*float64(i)/float64(contentSamples-1)
+		frame, err := sampleGrayFrame(paths, inputPath, props.VideoIndex, props.DurationSecs*pos)
+		if err == nil {
+			frames = append(frames, frame)
+		}
+	}
+	return classifyFrames(frames, contentFrameWidth, contentFrameHeight)
+}
+
+// sampleGrayFrame decodes one frame at startTime as 8-bit grayscale. Nearest
+// neighbor scaling keeps per-pixel noise that area scaling would average out.
+func sampleGrayFrame(paths toolpath.Paths, inputPath string, videoIndex int, startTime float64) ([]byte, error) {
+	cmd := exec.Command(paths.Path(toolpath.FFmpeg),
+		"-hide_banner",
+		"-loglevel", "error",
+		"-ss", fmt.Sprintf("%.2f", startTime),
+		"-i", inputPath,
+		"-map", videoMap(videoIndex),
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:flags=neighbor,format=gray", contentFrameWidth, contentFrameHeight),
+		"-f", "rawvideo",
+		"-",
+	)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmdlog.Run(cmd); err != nil {
+		return nil, fmt.Errorf("failed to sample frame: %w", err)
+	}
+	if out.Len() != contentFrameWidth*contentFrameHeight {
+		return nil, fmt.Errorf("unexpected frame size %d", out.Len())
+	}
+	return out.Bytes(), nil
+}
+
+// classifyFrames classifies grayscale frames of the given size by the median
+// share of flat and perfectly uniform blocks.
+func classifyFrames(frames [][]byte, width, height int) string {
+	var flat, exact []float64
+	for _, f := range frames {
+		flatFrac, exactFrac, ok := flatness(f, width, height)
+		if ok {
+			flat = append(flat, flatFrac)
+			exact = append(exact, exactFrac)
+		}
+	}
+	if len(flat) == 0 {
+		return config.ContentFilm
+	}
+
+	switch {
+	case median(exact) > screenExactFraction:
+		return config.ContentScreen
+	case median(flat) > animeFlatFraction:
+		return config.ContentAnime
+	default:
+		return config.ContentFilm
+	}
+}
+
+// flatness returns the share of non-black blocks that are flat and that are
+// perfectly uniform. ok is false if the frame is (almost) entirely black.
+func flatness(frame []byte, width, height int) (flatFrac, exactFrac float64, ok bool) {
+	var counted, flat, exact int
+	n := float64(contentBlockSize * contentBlockSize)
+
+	for by := 0; by+contentBlockSize <= height; by += contentBlockSize {
+		for bx := 0; bx+contentBlockSize <= width; bx += contentBlockSize {
+			var sum, sumSq float64
+			for y := by; y < by+contentBlockSize; y++ {
+				for _, p := range frame[y*width+bx : y*width+bx+contentBlockSize] {
+					v := float64(p)
+					sum += v
+					sumSq += v * v
+				}
+			}
+			mean := sum / n
+			if mean < blackBlockMean {
+				continue
+			}
+			variance := sumSq/n - mean*mean
+			counted++
+			if variance <= flatBlockVariance {
+				flat++
+			}
+			if variance == 0 {
+				exact++
+			}
+		}
+	}
+
+	// Need a meaningful amount of picture to judge
+	if counted < (width/contentBlockSize)*(height/contentBlockSize)/10 {
+		return 0, 0, false
+	}
+	return float64(flat) / float64(counted), float64(exact) / float64(counted), true
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted[len(sorted)/2]
+}
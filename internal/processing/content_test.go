@@ -0,0 +1,61 @@
+package processing
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+)
+
+// syntheticFrame builds a frame of flat panels at mid gray with per-pixel
+// noise of +/- noise.
+func syntheticFrame(rng *rand.Rand, width, height, noise int) []byte {
+	frame := make([]byte, width*height)
+	for y := range height {
+		for x := range width {
+			// Two panels so the frame isn't a single uniform color
+			base := 100
+			if x >= width/2 {
+				base = 160
+			}
+			if noise > 0 {
+				base += rng.IntN(2*noise+1) - noise
+			}
+			frame[y*width+x] = byte(base)
+		}
+	}
+	return frame
+}
+
+func TestClassifyFrames(t *testing.T) {
+	const w, h = 64, 48
+
+	tests := []struct {
+		name  string
+		noise int
+		want  string
+	}{
+		{"grainy film", 12, config.ContentFilm},
+		{"flat shading", 1, config.ContentAnime},
+		{"rendered screen", 0, config.ContentScreen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			var frames [][]byte
+			for range 5 {
+				frames = append(frames, syntheticFrame(rng, w, h, tt.noise))
+			}
+			if got := classifyFrames(frames, w, h); got != tt.want {
+				t.Errorf("classifyFrames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("black frames fall back to film", func(t *testing.T) {
+		if got := classifyFrames([][]byte{make([]byte, w*h)}, w, h); got != config.ContentFilm {
+			t.Errorf("classifyFrames(black) = %q, want film", got)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package processing
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os/exec"
 	"regexp"
@@ -11,7 +12,9 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/five82/reel/internal/cmdlog"
 	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
 )
 
 // cropDetectionConcurrency is the maximum number of concurrent crop detection samples.
@@ -23,14 +26,29 @@ type CropResult struct {
 	Required       bool   // Whether cropping is required
 	MultipleRatios bool   // Whether multiple aspect ratios were detected
 	Message        string // Human-readable message about the crop result
+
+	// Sample distribution; empty when crop detection didn't run
+	Votes      []CropVote // Samples per detected crop, most common first
+	Samples    int        // Positions sampled
+	Confidence float64    // Share of detected samples that agree on the most common crop
+}
+
+// CropVote is how many samples detected one crop rectangle.
+type CropVote struct {
+	Crop  string `json:"crop"` // "W:H:X:Y"
+	Count int    `json:"count"`
 }
 
 // cropRegex matches FFmpeg cropdetect output.
 var cropRegex = regexp.MustCompile(`crop=(\d+:\d+:\d+:\d+)`)
 
 // DetectCrop performs crop detection on a video file.
-// It samples 141 points from 15-85% of the video to detect black bars.
-func DetectCrop(inputPath string, props *ffprobe.VideoProperties, disableCrop bool) CropResult {
+// It samples 141 points from 15-85% of the video to detect black bars, and
+// crops when at least minConfidence of the samples agree on one crop.
+// HDR sources get a threshold per sample from its measured black level, and
+// the chosen crop is re-checked along its boundary to avoid cropping picture.
+// Samples not yet taken when ctx is cancelled are skipped.
+func DetectCrop(ctx context.Context, paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties, disableCrop bool, minConfidence float64, onSample func(done, total int)) CropResult {
 	if disableCrop {
 		return CropResult{
 			Required: false,
@@ -53,6 +71,7 @@ func DetectCrop(inputPath string, props *ffprobe.VideoProperties, disableCrop bo
 
 	// Process samples in parallel
 	cropCounts := make(map[string]int)
+	done := 0
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
@@ -65,13 +84,26 @@ func DetectCrop(inputPath string, props *ffprobe.VideoProperties, disableCrop bo
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
 
 			startTime := props.DurationSecs * pos
-			crop := sampleCropAtPosition(inputPath, startTime, threshold)
+			limit := threshold
+			if props.HDRInfo.IsHDR {
+				if black, ok := measureBlackLevel(paths, inputPath, props.VideoIndex, startTime); ok {
+					limit = adaptiveThreshold(black)
+				}
+			}
+			crop := sampleCropAtPosition(paths, inputPath, props.VideoIndex, startTime, limit)
+			mu.Lock()
+			defer mu.Unlock()
 			if crop != "" {
-				mu.Lock()
 				cropCounts[crop]++
-				mu.Unlock()
+			}
+			done++
+			if onSample != nil {
+				onSample(done, numSamples)
 			}
 		}(position)
 	}
@@ -79,79 +111,102 @@ func DetectCrop(inputPath string, props *ffprobe.VideoProperties, disableCrop bo
 	wg.Wait()
 
 	sampleMsg := fmt.Sprintf("Analyzed %d samples", numSamples)
-
-	// Analyze results
-	if len(cropCounts) == 0 {
+	votes := sortCropVotes(cropCounts)
+	if len(votes) == 0 {
 		return CropResult{
 			Required: false,
 			Message:  sampleMsg,
+			Samples:  numSamples,
 		}
 	}
 
-	if len(cropCounts) == 1 {
-		// Single crop detected
-		for crop := range cropCounts {
-			if !isEffectiveCrop(crop, props.Width, props.Height) {
-				return CropResult{
-					Required: false,
-					Message:  sampleMsg,
-				}
-			}
-			return CropResult{
-				CropFilter: "crop=" + crop,
-				Required:   true,
-				Message:    "Black bars detected",
-			}
+	// Use the most common crop when enough of the samples agree on it
+	detected := 0
+	for _, v := range votes {
+		detected += v.Count
+	}
+	confidence := float64(votes[0].Count) / float64(detected)
+
+	var result CropResult
+	if confidence >= minConfidence {
+		result = chosenCrop(paths, inputPath, props, votes[0].Crop, sampleMsg)
+	} else {
+		// Multiple significant aspect ratios - don't crop
+		result = CropResult{
+			Required:       false,
+			MultipleRatios: true,
+			Message: fmt.Sprintf("Multiple aspect ratios detected (%.0f%% agree, %.0f%% required)",
+				confidence*100, minConfidence*100),
 		}
 	}
+	result.Votes, result.Samples, result.Confidence = votes, numSamples, confidence
+	return result
+}
 
-	// Multiple crops detected - find the most common
-	type cropCount struct {
-		crop  string
-		count int
+// sortCropVotes orders crop sample counts from most to least common.
+func sortCropVotes(counts map[string]int) []CropVote {
+	var votes []CropVote
+	for crop, count := range counts {
+		votes = append(votes, CropVote{Crop: crop, Count: count})
 	}
-	var sorted []cropCount
-	totalSamples := 0
-	for crop, count := range cropCounts {
-		sorted = append(sorted, cropCount{crop, count})
-		totalSamples += count
-	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].count > sorted[j].count
+	sort.Slice(votes, func(i, j int) bool {
+		if votes[i].Count != votes[j].Count {
+			return votes[i].Count > votes[j].Count
+		}
+		return votes[i].Crop < votes[j].Crop
 	})
+	return votes
+}
 
-	mostCommon := sorted[0]
-	ratio := float64(mostCommon.count) / float64(totalSamples)
+// FormatCropVotes describes the crop sample distribution for verbose output.
+func FormatCropVotes(r CropResult) string {
+	var parts []string
+	detected := 0
+	for _, v := range r.Votes {
+		parts = append(parts, fmt.Sprintf("%s x%d", v.Crop, v.Count))
+		detected += v.Count
+	}
+	return fmt.Sprintf("%s (%.0f%% agree; %d of %d samples detected a crop)",
+		strings.Join(parts, ", "), r.Confidence*100, detected, r.Samples)
+}
 
-	// If one crop is dominant (>80% of samples), use it
-	if ratio > 0.8 {
-		if !isEffectiveCrop(mostCommon.crop, props.Width, props.Height) {
-			return CropResult{
-				Required: false,
-				Message:  sampleMsg,
-			}
+// chosenCrop builds the result for the crop picked from the samples, first
+// re-checking its boundary on HDR sources.
+func chosenCrop(paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties, crop, sampleMsg string) CropResult {
+	message := "Black bars detected"
+	if props.HDRInfo.IsHDR && isEffectiveCrop(crop, props.Width, props.Height) {
+		if verified := verifyCrop(paths, inputPath, props, crop); verified != crop {
+			crop = verified
+			message = "Black bars detected (reduced after boundary check)"
 		}
+	}
+	if !isEffectiveCrop(crop, props.Width, props.Height) {
 		return CropResult{
-			CropFilter: "crop=" + mostCommon.crop,
-			Required:   true,
-			Message:    "Black bars detected",
+			Required: false,
+			Message:  sampleMsg,
 		}
 	}
-
-	// Multiple significant aspect ratios - don't crop
 	return CropResult{
-		Required:       false,
-		MultipleRatios: true,
-		Message:        "Multiple aspect ratios detected",
+		CropFilter: "crop=" + crop,
+		Required:   true,
+		Message:    message,
 	}
 }
 
+// videoMap returns the -map specifier of the index-th video stream of the
+// first input, so analysis looks at the stream being encoded rather than the
+// one ffmpeg would pick.
+func videoMap(index int) string {
+	return fmt.Sprintf("0:v:%d", index)
+}
+
 // sampleCropAtPosition samples crop detection at a specific position.
-func sampleCropAtPosition(inputPath string, startTime float64, threshold uint32) string {
-	cmd := exec.Command("ffmpeg",
+func sampleCropAtPosition(paths toolpath.Paths, inputPath string, videoIndex int, startTime float64, threshold uint32) string {
+	cmd := exec.Command(paths.Path(toolpath.FFmpeg),
 		"-hide_banner",
 		"-ss", fmt.Sprintf("%.2f", startTime),
 		"-i", inputPath,
+		"-map", videoMap(videoIndex),
 		"-vframes", "10",
 		"-vf", fmt.Sprintf("cropdetect=limit=%d:round=2:reset=1", threshold),
 		"-f", "null",
@@ -163,7 +218,8 @@ func sampleCropAtPosition(inputPath string, startTime float64, threshold uint32)
 		return ""
 	}
 
-	if err := cmd.Start(); err != nil {
+	proc, err := cmdlog.Start(cmd, nil)
+	if err != nil {
 		return ""
 	}
 
@@ -180,7 +236,7 @@ func sampleCropAtPosition(inputPath string, startTime float64, threshold uint32)
 		}
 	}
 
-	_ = cmd.Wait()
+	_ = proc.Wait()
 
 	// Return the most common crop value
 	if len(cropCounts) == 0 {
@@ -236,6 +292,30 @@ func isEffectiveCrop(crop string, sourceWidth, sourceHeight uint32) bool {
 	return uint32(cropWidth) != sourceWidth || uint32(cropHeight) != sourceHeight
 }
 
+// ManualCrop builds a crop result from a configured "W:H:X:Y" filter. The
+// crop must fit the source and be centered, since frames are cropped by the
+// same amount on opposite sides.
+func ManualCrop(filter string, props *ffprobe.VideoProperties) (CropResult, error) {
+	crop := strings.TrimPrefix(filter, "crop=")
+	var w, h, x, y uint32
+	if !isValidCropFormat(crop) {
+		return CropResult{}, fmt.Errorf("invalid crop filter %q", filter)
+	}
+	_, _ = fmt.Sscanf(crop, "%d:%d:%d:%d", &w, &h, &x, &y)
+	if w == 0 || h == 0 || w+2*x != props.Width || h+2*y != props.Height {
+		return CropResult{}, fmt.Errorf("crop %s doesn't center within the %dx%d source", crop, props.Width, props.Height)
+	}
+
+	if !isEffectiveCrop(crop, props.Width, props.Height) {
+		return CropResult{Required: false, Message: "Manual crop matches source; no crop"}, nil
+	}
+	return CropResult{
+		CropFilter: "crop=" + crop,
+		Required:   true,
+		Message:    "Manual crop",
+	}, nil
+}
+
 // GetOutputDimensions calculates final output dimensions after crop.
 func GetOutputDimensions(originalWidth, originalHeight uint32, cropFilter string) (uint32, uint32) {
 	if cropFilter == "" {
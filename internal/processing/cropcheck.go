@@ -0,0 +1,169 @@
+package processing
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// HDR crop detection tuning, in the source's code values (0-1023 for 10-bit).
+const (
+	hdrBlackMargin  = 24  // How far above the measured black level still counts as black
+	hdrMinThreshold = 32  // Lowest cropdetect limit
+	hdrMaxThreshold = 160 // Highest cropdetect limit, so dark scenes aren't taken for bars
+)
+
+// Boundary re-check of a proposed HDR crop.
+const (
+	cropCheckSamples    = 12  // Positions checked across the video
+	cropCheckBand       = 4   // Rows or columns just outside the crop that are measured
+	cropCheckStep       = 8   // Pixels given back on each side per level
+	cropCheckLevels     = 4   // Steps tried before giving up on cropping that axis
+	cropCheckMaxPicture = 0.2 // Fraction of positions allowed to show picture outside the crop
+)
+
+// signalstatsRegex matches values printed by the metadata filter after signalstats.
+var signalstatsRegex = regexp.MustCompile(`lavfi\.signalstats\.([A-Z]+)=([\d.]+)`)
+
+// measureBlackLevel returns the lowest 10th-percentile luma over ten frames
+// at startTime. In a letterboxed frame that is the level of the bars.
+func measureBlackLevel(paths toolpath.Paths, inputPath string, videoIndex int, startTime float64) (float64, bool) {
+	values := signalstats(paths, inputPath, videoIndex, startTime, "", "YLOW")
+	if len(values) == 0 {
+		return 0, false
+	}
+	return slices.Min(values), true
+}
+
+// adaptiveThreshold returns the cropdetect limit for a measured black level.
+func adaptiveThreshold(black float64) uint32 {
+	return uint32(min(max(black+hdrBlackMargin, hdrMinThreshold), hdrMaxThreshold))
+}
+
+// signalstats runs signalstats over ten frames at startTime, optionally
+// cropped to the "W:H:X:Y" region, and returns key's value for each frame.
+func signalstats(paths toolpath.Paths, inputPath string, videoIndex int, startTime float64, region, key string) []float64 {
+	filter := "signalstats,metadata=print:key=lavfi.signalstats." + key
+	if region != "" {
+		filter = "crop=" + region + "," + filter
+	}
+	out, err := cmdlog.CombinedOutput(exec.Command(paths.Path(toolpath.FFmpeg),
+		"-hide_banner",
+		"-ss", fmt.Sprintf("%.2f", startTime),
+		"-i", inputPath,
+		"-map", videoMap(videoIndex),
+		"-vframes", "10",
+		"-vf", filter,
+		"-f", "null",
+		"-",
+	))
+	if err != nil {
+		return nil
+	}
+	return parseSignalstats(string(out), key)
+}
+
+// parseSignalstats extracts the values of one signalstats key from ffmpeg output.
+func parseSignalstats(output, key string) []float64 {
+	var values []float64
+	for _, m := range signalstatsRegex.FindAllStringSubmatch(output, -1) {
+		if m[1] != key {
+			continue
+		}
+		if v, err := strconv.ParseFloat(m[2], 64); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// cropRect is a crop region; X and Y are also the amounts cropped from the
+// right and bottom, since crops are centered.
+type cropRect struct {
+	W, H, X, Y uint32
+}
+
+func (c cropRect) String() string {
+	return fmt.Sprintf("%d:%d:%d:%d", c.W, c.H, c.X, c.Y)
+}
+
+// widen gives back up to step pixels on each side of the axes that are set.
+func (c cropRect) widen(vertical, horizontal bool, step uint32) cropRect {
+	if vertical {
+		d := min(step, c.Y)
+		c.Y -= d
+		c.H += 2 * d
+	}
+	if horizontal {
+		d := min(step, c.X)
+		c.X -= d
+		c.W += 2 * d
+	}
+	return c
+}
+
+// verifyCrop checks the rows and columns just outside a proposed crop at
+// positions across the video. Where picture shows there on more than a few
+// positions, that axis is cropped less, a step at a time, until the boundary
+// is clean or the axis is no longer cropped. Returns the crop to use.
+func verifyCrop(paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties, crop string) string {
+	var c cropRect
+	if _, err := fmt.Sscanf(crop, "%d:%d:%d:%d", &c.W, &c.H, &c.X, &c.Y); err != nil {
+		return crop
+	}
+
+	// Black levels don't depend on the crop, so measure them once
+	var positions []float64
+	var limits []uint32
+	for i := range cropCheckSamples {
+		pos := 0.15 + 0.7*float64(i)/float64(cropCheckSamples-1)
+		if black, ok := measureBlackLevel(paths, inputPath, props.VideoIndex, props.DurationSecs*pos); ok {
+			positions = append(positions, props.DurationSecs*pos)
+			limits = append(limits, adaptiveThreshold(black))
+		}
+	}
+	if len(positions) == 0 {
+		return crop
+	}
+
+	for level := 0; ; level++ {
+		vertical := c.Y > 0 && pictureInBand(paths, inputPath, props.VideoIndex, positions, limits, cropRect{
+			W: c.W, H: min(cropCheckBand, c.Y), X: c.X, Y: c.Y - min(cropCheckBand, c.Y),
+		})
+		horizontal := c.X > 0 && pictureInBand(paths, inputPath, props.VideoIndex, positions, limits, cropRect{
+			W: min(cropCheckBand, c.X), H: c.H, X: c.X - min(cropCheckBand, c.X), Y: c.Y,
+		})
+		if !vertical && !horizontal {
+			return c.String()
+		}
+		if level == cropCheckLevels {
+			if vertical {
+				c.H, c.Y = props.Height, 0
+			}
+			if horizontal {
+				c.W, c.X = props.Width, 0
+			}
+			return c.String()
+		}
+		c = c.widen(vertical, horizontal, cropCheckStep)
+	}
+}
+
+// pictureInBand reports whether the band shows picture, brighter than the
+// black limit, at more than cropCheckMaxPicture of the positions.
+func pictureInBand(paths toolpath.Paths, inputPath string, videoIndex int, positions []float64, limits []uint32, band cropRect) bool {
+	picture := 0
+	for i, pos := range positions {
+		values := signalstats(paths, inputPath, videoIndex, pos, band.String(), "YAVG")
+		if len(values) > 0 && slices.Max(values) > float64(limits[i]) {
+			picture++
+		}
+	}
+	return float64(picture) > cropCheckMaxPicture*float64(len(positions))
+}
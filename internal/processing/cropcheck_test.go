@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAdaptiveThreshold(t *testing.T) {
+	tests := []struct {
+		black float64
+		want  uint32
+	}{
+		{64, 88},   // Limited-range 10-bit black
+		{0, 32},    // Clamped to the minimum
+		{100, 124}, // Raised bars
+		{400, 160}, // Clamped so dark scenes aren't taken for bars
+	}
+
+	for _, tt := range tests {
+		if got := adaptiveThreshold(tt.black); got != tt.want {
+			t.Errorf("adaptiveThreshold(%v) = %d, want %d", tt.black, got, tt.want)
+		}
+	}
+}
+
+func TestParseSignalstats(t *testing.T) {
+	output := `frame:0    pts:0       pts_time:0
+[Parsed_metadata_1 @ 0x5581] lavfi.signalstats.YLOW=64
+frame:1    pts:1001    pts_time:0.0417
+[Parsed_metadata_1 @ 0x5581] lavfi.signalstats.YLOW=66.5
+[Parsed_metadata_1 @ 0x5581] lavfi.signalstats.YAVG=301.2
+`
+	if got := parseSignalstats(output, "YLOW"); !slices.Equal(got, []float64{64, 66.5}) {
+		t.Errorf("parseSignalstats(YLOW) = %v", got)
+	}
+	if got := parseSignalstats(output, "YMAX"); got != nil {
+		t.Errorf("parseSignalstats(YMAX) = %v, want nil", got)
+	}
+}
+
+func TestCropRectWiden(t *testing.T) {
+	c := cropRect{W: 3840, H: 1600, X: 0, Y: 280}
+
+	tests := []struct {
+		name                 string
+		vertical, horizontal bool
+		want                 cropRect
+	}{
+		{"vertical", true, false, cropRect{W: 3840, H: 1616, X: 0, Y: 272}},
+		{"uncropped axis unchanged", false, true, c},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.widen(tt.vertical, tt.horizontal, cropCheckStep); got != tt.want {
+				t.Errorf("widen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Never gives back more than was cropped
+	if got := (cropRect{W: 1916, H: 1080, X: 2}).widen(false, true, cropCheckStep); got != (cropRect{W: 1920, H: 1080}) {
+		t.Errorf("widen() past the edge = %v", got)
+	}
+}
+
+func TestSortCropVotes(t *testing.T) {
+	votes := sortCropVotes(map[string]int{
+		"3840:2160:0:0":   12,
+		"3840:1600:0:280": 110,
+		"3840:1608:0:276": 12,
+	})
+	want := []CropVote{
+		{Crop: "3840:1600:0:280", Count: 110},
+		{Crop: "3840:1608:0:276", Count: 12},
+		{Crop: "3840:2160:0:0", Count: 12},
+	}
+	if !slices.Equal(votes, want) {
+		t.Errorf("sortCropVotes() = %v, want %v", votes, want)
+	}
+
+	r := CropResult{Votes: votes, Samples: 141, Confidence: 110.0 / 134}
+	got := FormatCropVotes(r)
+	if want := "3840:1600:0:280 x110, 3840:1608:0:276 x12, 3840:2160:0:0 x12 (82% agree; 134 of 141 samples detected a crop)"; got != want {
+		t.Errorf("FormatCropVotes() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package processing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/util"
+)
+
+// fingerprintSample is the size of each sampled region when comparing file
+// contents. Files of equal size are first compared by hashing their start,
+// middle, and end, and only read in full when those match.
+const fingerprintSample = 1 << 20
+
+// dedupeInputs splits files into those to encode and, keyed by each of
+// those, the inputs that are the same file or have identical content.
+// Order is preserved; the first occurrence is the one encoded.
+func dedupeInputs(files []string) (unique []string, duplicates map[string][]string, err error) {
+	type candidate struct {
+		path        string
+		info        os.FileInfo
+		fingerprint []byte
+	}
+	bySize := make(map[int64][]*candidate)
+	duplicates = make(map[string][]string)
+
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		c := &candidate{path: path, info: info}
+
+		var original *candidate
+		for _, prev := range bySize[info.Size()] {
+			if os.SameFile(prev.info, info) {
+				original = prev
+				break
+			}
+			if prev.fingerprint == nil {
+				if prev.fingerprint, err = fingerprint(prev.path, info.Size()); err != nil {
+					return nil, nil, err
+				}
+			}
+			if c.fingerprint == nil {
+				if c.fingerprint, err = fingerprint(path, info.Size()); err != nil {
+					return nil, nil, err
+				}
+			}
+			if string(prev.fingerprint) != string(c.fingerprint) {
+				continue
+			}
+			// Samples can match where the rest differs; confirm in full
+			same, err := sameContent(prev.path, path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if same {
+				original = prev
+				break
+			}
+		}
+
+		if original != nil {
+			duplicates[original.path] = append(duplicates[original.path], path)
+			continue
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], c)
+		unique = append(unique, path)
+	}
+	return unique, duplicates, nil
+}
+
+// fingerprint hashes a file's size and samples from its start, middle, and end.
+func fingerprint(path string, size int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	_ = binary.Write(h, binary.LittleEndian, size)
+	for _, offset := range []int64{0, size/2 - fingerprintSample/2, size - fingerprintSample} {
+		offset = max(offset, 0)
+		if _, err := io.Copy(h, io.NewSectionReader(f, offset, fingerprintSample)); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// sameContent reports whether the files at a and b have identical bytes.
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", a, err)
+	}
+	defer func() { _ = fa.Close() }()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", b, err)
+	}
+	defer func() { _ = fb.Close() }()
+
+	bufA, bufB := make([]byte, fingerprintSample), make([]byte, fingerprintSample)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		switch {
+		case errA != nil && !doneA:
+			return false, fmt.Errorf("failed to read %s: %w", a, errA)
+		case errB != nil && !doneB:
+			return false, fmt.Errorf("failed to read %s: %w", b, errB)
+		case doneA || doneB:
+			return doneA && doneB, nil
+		}
+	}
+}
+
+// placeDuplicateOutput puts an encoded output at a duplicate input's output
+// path according to policy.
+func placeDuplicateOutput(outputPath, dupOutputPath, policy string) error {
+	switch policy {
+	case config.DuplicatesLink:
+		if err := os.Link(outputPath, dupOutputPath); err == nil {
+			return nil
+		}
+		return util.CopyFile(outputPath, dupOutputPath)
+	case config.DuplicatesCopy:
+		return util.CopyFile(outputPath, dupOutputPath)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/reporter"
+)
+
+func TestDedupeInputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	content := make([]byte, 5*fingerprintSample)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	a := write("a.mkv", content)
+	copyOfA := write("copy.mkv", content)
+
+	// Same size, different middle
+	other := slices.Clone(content)
+	other[len(other)/2] ^= 0xff
+	b := write("b.mkv", other)
+
+	// Same size and samples, different bytes between them
+	unsampled := slices.Clone(content)
+	unsampled[fingerprintSample*3/2] ^= 0xff
+	c := write("c.mkv", unsampled)
+
+	link := filepath.Join(dir, "link.mkv")
+	if err := os.Symlink(b, link); err != nil {
+		t.Fatal(err)
+	}
+
+	unique, dups, err := dedupeInputs([]string{a, b, copyOfA, c, link})
+	if err != nil {
+		t.Fatalf("dedupeInputs() error = %v", err)
+	}
+	if want := []string{a, b, c}; !slices.Equal(unique, want) {
+		t.Errorf("unique = %v, want %v", unique, want)
+	}
+	if !slices.Equal(dups[a], []string{copyOfA}) || !slices.Equal(dups[b], []string{link}) {
+		t.Errorf("duplicates = %v", dups)
+	}
+}
+
+func TestPlaceDuplicatesSkipsExistingOutputs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewConfig(dir, dir, dir)
+	cfg.DuplicatePolicy = config.DuplicatesCopy
+	b := &batch{
+		cfg:     cfg,
+		outputs: newOutputNamer(cfg, nil),
+		duplicates: map[string][]string{
+			"/src/a.mkv": {"/src/b.mkv", "/src/c.mkv"},
+		},
+	}
+
+	output := filepath.Join(dir, "a.mkv")
+	if err := os.WriteFile(output, []byte("encoded"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dir, "b.mkv")
+	if err := os.WriteFile(existing, []byte("earlier"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b.placeDuplicates(reporter.NullReporter{}, "/src/a.mkv", output, "")
+
+	if data, _ := os.ReadFile(existing); string(data) != "earlier" {
+		t.Errorf("existing duplicate output = %q, want it left alone", data)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "c.mkv")); err != nil || string(data) != "encoded" {
+		t.Errorf("duplicate after an existing output = %q, %v; want the encoded output", data, err)
+	}
+}
@@ -0,0 +1,109 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// ffv1Ratio is the approximate FFV1 size relative to raw 8-bit 4:2:0 video.
+const ffv1Ratio = 0.6
+
+// EstimateDeinterlaceSpace estimates the size of the lossless deinterlaced
+// intermediate, assuming 30 fps 8-bit 4:2:0 source video.
+func EstimateDeinterlaceSpace(props *ffprobe.VideoProperties) uint64 {
+	rawPerSec := float64(props.Width) * float64(props.Height) * 1.5 * 30
+	return uint64(rawPerSec * props.DurationSecs * ffv1Ratio)
+}
+
+// idetFrames is how many frames idet examines to decide whether a source is
+// interlaced.
+const idetFrames = 1000
+
+// idetInterlacedShare is the share of frames idet must find interlaced for
+// the source to be deinterlaced. Hard-telecined film combs two frames in five.
+const idetInterlacedShare = 0.25
+
+// idetSummary matches idet's multi-frame counts, which use neighboring frames
+// and are steadier than its single-frame ones.
+var idetSummary = regexp.MustCompile(`Multi frame detection: TFF:\s*(\d+)\s+BFF:\s*(\d+)\s+Progressive:\s*(\d+)`)
+
+// DetectInterlaced reports whether the source's video is interlaced, from
+// idet run over frames a third of the way in. Progressive and soft-telecined
+// sources, whose frames decode whole, are not.
+func DetectInterlaced(ctx context.Context, paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties) (bool, error) {
+	cmd := exec.CommandContext(ctx, paths.Path(toolpath.FFmpeg),
+		"-hide_banner", "-nostats",
+		"-ss", fmt.Sprintf("%.2f", props.DurationSecs/3),
+		"-i", inputPath,
+		"-map", videoMap(props.VideoIndex),
+		"-vf", "idet",
+		"-frames:v", strconv.Itoa(idetFrames),
+		"-an", "-f", "null", "-",
+	)
+	output, err := cmdlog.CombinedOutput(cmd)
+	if err != nil {
+		return false, fmt.Errorf("interlace detection failed: %w", err)
+	}
+	interlaced, ok := parseIdet(string(output))
+	if !ok {
+		return false, fmt.Errorf("interlace detection failed: no idet summary in ffmpeg output")
+	}
+	return interlaced, nil
+}
+
+// parseIdet reads idet's multi-frame counts from ffmpeg output and reports
+// whether enough frames were interlaced. Undetermined frames don't count.
+func parseIdet(output string) (interlaced, ok bool) {
+	m := idetSummary.FindStringSubmatch(output)
+	if m == nil {
+		return false, false
+	}
+	tff, _ := strconv.Atoi(m[1])
+	bff, _ := strconv.Atoi(m[2])
+	progressive, _ := strconv.Atoi(m[3])
+	total := tff + bff + progressive
+	return total > 0 && float64(tff+bff) >= idetInterlacedShare*float64(total), true
+}
+
+// deinterlaceSource writes a losslessly compressed, deinterlaced copy of the
+// source's videoIndex-th video stream into the work directory and returns
+// its path.
+// FFMS2 decodes frames as stored, so deinterlacing has to happen before indexing.
+// bwdif only touches frames flagged as interlaced, so progressive material
+// (e.g. film on DVD) passes through unchanged. An existing intermediate from an
+// interrupted run is reused.
+func deinterlaceSource(ctx context.Context, paths toolpath.Paths, inputPath, workDir string, videoIndex int) (string, error) {
+	outPath := filepath.Join(workDir, "deinterlaced.mkv")
+	if _, err := os.Stat(outPath); err == nil {
+		return outPath, nil
+	}
+
+	partial := outPath + ".partial"
+	cmd := exec.CommandContext(ctx, paths.Path(toolpath.FFmpeg),
+		"-hide_banner",
+		"-i", inputPath,
+		"-map", videoMap(videoIndex),
+		"-vf", "bwdif=mode=send_frame:parity=auto:deint=interlaced",
+		"-c:v", "ffv1", "-level", "3",
+		"-f", "matroska",
+		"-y", partial,
+	)
+	if output, err := cmdlog.CombinedOutput(cmd); err != nil {
+		_ = os.Remove(partial)
+		return "", fmt.Errorf("deinterlacing failed: %w\nOutput: %s", err, string(output))
+	}
+
+	if err := os.Rename(partial, outPath); err != nil {
+		return "", fmt.Errorf("failed to finalize deinterlaced video: %w", err)
+	}
+	return outPath, nil
+}
@@ -0,0 +1,44 @@
+package processing
+
+import "testing"
+
+func TestParseIdet(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantInterlaced bool
+		wantOK         bool
+	}{
+		{
+			name:           "interlaced",
+			output:         "[Parsed_idet_0 @ 0x1] Multi frame detection: TFF:  912 BFF:    0 Progressive:   61 Undetermined:   27",
+			wantInterlaced: true, wantOK: true,
+		},
+		{
+			name:           "progressive",
+			output:         "[Parsed_idet_0 @ 0x1] Multi frame detection: TFF:    3 BFF:    0 Progressive:  990 Undetermined:    7",
+			wantInterlaced: false, wantOK: true,
+		},
+		{
+			name:           "hard telecine",
+			output:         "[Parsed_idet_0 @ 0x1] Multi frame detection: TFF:  395 BFF:    0 Progressive:  600 Undetermined:    5",
+			wantInterlaced: true, wantOK: true,
+		},
+		{
+			name: "single frame counts only",
+			output: "[Parsed_idet_0 @ 0x1] Single frame detection: TFF:  900 BFF:    0 Progressive:   61 Undetermined:   39\n" +
+				"[Parsed_idet_0 @ 0x1] Multi frame detection: TFF:    0 BFF:    0 Progressive:    0 Undetermined: 1000",
+			wantInterlaced: false, wantOK: true,
+		},
+		{name: "missing", output: "Output #0, null, to 'pipe:':", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interlaced, ok := parseIdet(tt.output)
+			if interlaced != tt.wantInterlaced || ok != tt.wantOK {
+				t.Errorf("parseIdet() = %v, %v, want %v, %v", interlaced, ok, tt.wantInterlaced, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// DeterminismCheck is the result of encoding one chunk twice.
+type DeterminismCheck struct {
+	Chunk         int    `json:"chunk"`
+	Deterministic bool   `json:"deterministic"`
+	FirstSHA256   string `json:"first_sha256"`
+	SecondSHA256  string `json:"second_sha256"`
+}
+
+// verifyDeterminism encodes a randomly chosen chunk twice with the same
+// settings, in scratch directories of its own, and compares the outputs.
+// Resuming from chunks, and encoding them on different machines, relies on
+// a chunk encoding to the same bytes every time.
+func verifyDeterminism(
+	ctx context.Context,
+	chunks []chunk.Chunk,
+	vidInf *ffms.VidInf,
+	encCfg *encode.EncodeConfig,
+	idx *ffms.VidIdx,
+	workDir string,
+	cropH, cropV uint32,
+	rep reporter.Reporter,
+) (*DeterminismCheck, error) {
+	c := chunks[rand.IntN(len(chunks))]
+	rep.StageProgress(reporter.StageProgress{
+		Stage:   "Encoding",
+		Message: fmt.Sprintf("Encoding chunk %d twice to check the encoder is deterministic", c.Idx),
+	})
+
+	dir := filepath.Join(workDir, "determinism")
+	defer func() { _ = os.RemoveAll(dir) }()
+	var digests [2]string
+	for i := range digests {
+		runDir := filepath.Join(dir, fmt.Sprint(i))
+		if _, err := encode.EncodeAll(ctx, []chunk.Chunk{c}, vidInf, encCfg, idx, runDir, cropH, cropV, nil); err != nil {
+			return nil, fmt.Errorf("determinism check encoding failed: %w", err)
+		}
+		digest, err := util.FileChecksum(ctx, chunk.IVFPath(runDir, c.Idx), "sha256", nil)
+		if err != nil {
+			return nil, fmt.Errorf("determinism check failed: %w", err)
+		}
+		digests[i] = digest
+	}
+
+	check := &DeterminismCheck{
+		Chunk:         c.Idx,
+		Deterministic: digests[0] == digests[1],
+		FirstSHA256:   digests[0],
+		SecondSHA256:  digests[1],
+	}
+	if check.Deterministic {
+		rep.Verbose(fmt.Sprintf("Chunk %d encoded identically twice (sha256 %s)", c.Idx, digests[0]))
+	} else {
+		rep.Warning(fmt.Sprintf("Encoder is not deterministic: chunk %d encoded differently twice (sha256 %s, %s). "+
+			"Resumed or distributed encodes may not match a single-run encode", c.Idx, digests[0][:12], digests[1][:12]))
+	}
+	return check, nil
+}
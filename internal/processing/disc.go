@@ -0,0 +1,138 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/discovery"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// PrepareDiscTitle makes a disc's title encodable as one file named after
+// the disc, in dir, and returns its path. A single Blu-ray clip is linked
+// to; DVD program chains and multi-clip playlists are joined into an MKV
+// without re-encoding, so FFMS2 sees continuous timestamps, by the ffmpeg in
+// paths. A file left by an earlier run is reused.
+func PrepareDiscTitle(ctx context.Context, paths toolpath.Paths, disc *discovery.Disc, title discovery.DiscTitle, dir string, rep reporter.Reporter) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	if disc.Kind == discovery.DiscBluray && len(title.Files) == 1 && title.Sectors == nil {
+		linkPath := filepath.Join(dir, disc.Name+filepath.Ext(title.Files[0]))
+		if target, err := os.Readlink(linkPath); err == nil && target == title.Files[0] {
+			return linkPath, nil
+		}
+		_ = os.Remove(linkPath)
+		if err := os.Symlink(title.Files[0], linkPath); err != nil {
+			return "", fmt.Errorf("failed to link main title: %w", err)
+		}
+		return linkPath, nil
+	}
+
+	outPath := filepath.Join(dir, disc.Name+".mkv")
+	if _, err := os.Stat(outPath); err == nil {
+		return outPath, nil
+	}
+	rep.StageProgress(reporter.StageProgress{
+		Stage:   "Preparing",
+		Message: fmt.Sprintf("Joining %d files of the main title", len(title.Files)),
+	})
+
+	// Program chains play runs of the VOBs, piped to ffmpeg in order
+	input := []string{"-i", "concat:" + strings.Join(title.Files, "|")}
+	var stdin io.Reader
+	var inputCmd []string
+	if title.Sectors != nil {
+		segments, err := title.Segments()
+		if err != nil {
+			return "", fmt.Errorf("failed to read main title: %w", err)
+		}
+		files, reader, err := openSegments(segments)
+		if err != nil {
+			return "", fmt.Errorf("failed to read main title: %w", err)
+		}
+		defer closeAll(files)
+		input, stdin, inputCmd = []string{"-f", "mpeg", "-i", "pipe:0"}, reader, segmentsCommand(segments)
+	}
+
+	partial := outPath + ".partial"
+	args := append([]string{"-hide_banner", "-fflags", "+genpts"}, input...)
+	args = append(args,
+		"-map", "0:v:0", "-map", "0:a?", "-map", "0:s?",
+		"-c", "copy",
+		"-f", "matroska",
+		"-y", partial,
+	)
+	cmd := exec.CommandContext(ctx, paths.Path(toolpath.FFmpeg), args...)
+	cmd.Stdin = stdin
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	proc, err := cmdlog.Start(cmd, inputCmd)
+	if err == nil {
+		err = proc.Wait()
+	}
+	if err != nil {
+		_ = os.Remove(partial)
+		return "", fmt.Errorf("joining the main title failed: %w\nOutput: %s", err, output.String())
+	}
+
+	if err := os.Rename(partial, outPath); err != nil {
+		return "", fmt.Errorf("failed to finalize main title: %w", err)
+	}
+	return outPath, nil
+}
+
+// openSegments opens the files of segments and returns a reader of the
+// segments in order. The caller closes the files.
+func openSegments(segments []discovery.Segment) ([]*os.File, io.Reader, error) {
+	var files []*os.File
+	opened := make(map[string]*os.File)
+	readers := make([]io.Reader, len(segments))
+	for i, seg := range segments {
+		f, ok := opened[seg.Path]
+		if !ok {
+			var err error
+			if f, err = os.Open(seg.Path); err != nil {
+				closeAll(files)
+				return nil, nil, err
+			}
+			opened[seg.Path] = f
+			files = append(files, f)
+		}
+		readers[i] = io.NewSectionReader(f, seg.Offset, seg.Length)
+	}
+	return files, io.MultiReader(readers...), nil
+}
+
+// closeAll closes files.
+func closeAll(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
+
+// segmentsCommand returns a shell command writing segments in order, which
+// the command script pipes into ffmpeg in place of reel.
+func segmentsCommand(segments []discovery.Segment) []string {
+	parts := make([]string, len(segments))
+	for i, seg := range segments {
+		parts[i] = cmdlog.CommandLine([]string{"dd", "if=" + seg.Path, "iflag=skip_bytes,count_bytes",
+			fmt.Sprintf("skip=%d", seg.Offset), fmt.Sprintf("count=%d", seg.Length), "bs=1M", "status=none"}, nil)
+	}
+	return []string{"sh", "-c", strings.Join(parts, "; ")}
+}
+
+// DiscTitleDir returns where PrepareDiscTitle puts a disc's title: a
+// .reel-disc-<name> directory in tempDir, removed once encoded.
+func DiscTitleDir(disc *discovery.Disc, tempDir string) string {
+	return filepath.Join(tempDir, ".reel-disc-"+disc.Name)
+}
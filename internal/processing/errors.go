@@ -0,0 +1,54 @@
+package processing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+// Failure causes for a file that produced no output, or an output that failed validation.
+var (
+	ErrValidationFailed   = errors.New("output validation failed")
+	ErrCancelled          = errors.New("encoding cancelled")
+	ErrOutputExists       = errors.New("output file already exists")
+	ErrNotAttempted       = errors.New("not attempted")
+	ErrVariableResolution = errors.New("resolution changes mid-stream")
+	ErrFinishedEarlier    = errors.New("finished in an earlier run of this batch")
+	ErrDeferred           = errors.New("deferred to the next run")
+)
+
+// FileError records why an input was not encoded.
+type FileError struct {
+	Input string // Input path
+	Err   error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Input, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// IsSkip reports whether a file error is a deliberate skip (existing output,
+// no video, projected output too large, or finished by an earlier run of the
+// batch) rather than a failure.
+func IsSkip(err error) bool {
+	var sizeErr *SizeAbortError
+	return errors.Is(err, ErrOutputExists) || errors.Is(err, ffprobe.ErrNoVideo) || errors.As(err, &sizeErr) ||
+		errors.Is(err, ErrFinishedEarlier)
+}
+
+// isFailure reports whether err means the input itself failed, rather than
+// being skipped, deferred, or left by a cancelled or stopped batch.
+func isFailure(err error) bool {
+	return !IsSkip(err) && !errors.Is(err, ErrCancelled) && !errors.Is(err, ErrDeferred) &&
+		!errors.Is(err, ErrNotAttempted)
+}
+
+// cancelled wraps a context error so it matches ErrCancelled.
+func cancelled(err error) error {
+	return fmt.Errorf("%w: %w", ErrCancelled, err)
+}
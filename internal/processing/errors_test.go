@@ -0,0 +1,85 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestFileErrorCauses(t *testing.T) {
+	chunkErr := &encode.ChunkEncodeError{Chunk: 7, Err: errors.New("encoder failed")}
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"cancelled", cancelled(context.Canceled), ErrCancelled},
+		{"context error kept", cancelled(context.Canceled), context.Canceled},
+		{"output exists", fmt.Errorf("%w: out.mkv", ErrOutputExists), ErrOutputExists},
+		{"chunk cause", fmt.Errorf("encode: %w", chunkErr), chunkErr.Err},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := error(&FileError{Input: "in.mkv", Err: tt.err})
+			if !errors.Is(err, tt.target) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.target)
+			}
+		})
+	}
+
+	var ce *encode.ChunkEncodeError
+	err := error(&FileError{Input: "in.mkv", Err: fmt.Errorf("encode: %w", chunkErr)})
+	if !errors.As(err, &ce) || ce.Chunk != 7 {
+		t.Errorf("errors.As(%v) chunk = %v, want 7", err, ce)
+	}
+}
+
+func TestFailedFiles(t *testing.T) {
+	failures := []*FileError{
+		{Input: "/rips/cover.mkv", Err: ffprobe.ErrNoVideo},
+		{Input: "/rips/a.mkv", Err: errors.New("encoder failed")},
+		{Input: "/rips/b.mkv", Err: fmt.Errorf("%w: batch stopped after a.mkv failed", ErrNotAttempted)},
+		{Input: "/rips/c.mkv", Err: fmt.Errorf("%w: not started before 07:00", ErrDeferred)},
+	}
+
+	got := failedFiles(failures)
+	if len(got) != 2 || got[0].Filename != "a.mkv" || got[1].Filename != "b.mkv" {
+		t.Fatalf("failedFiles() = %+v, want a.mkv and b.mkv", got)
+	}
+	if got[0].Reason != "encoder failed" {
+		t.Errorf("Reason = %q, want %q", got[0].Reason, "encoder failed")
+	}
+	if deferred := deferredFiles(failures); len(deferred) != 1 || deferred[0] != "c.mkv" {
+		t.Errorf("deferredFiles() = %v, want [c.mkv]", deferred)
+	}
+}
+
+func TestIsSkip(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"output exists", fmt.Errorf("%w: out.mkv", ErrOutputExists), true},
+		{"no video", ffprobe.ErrNoVideo, true},
+		{"size abort", &SizeAbortError{Projected: 2, Source: 1, Ratio: 0.9}, true},
+		{"finished earlier", fmt.Errorf("%w: encoded", ErrFinishedEarlier), true},
+		{"encode failure", errors.New("encoder failed"), false},
+		{"cancelled", cancelled(context.Canceled), false},
+		{"deferred", fmt.Errorf("%w: not started before 07:00", ErrDeferred), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSkip(&FileError{Input: "in.mkv", Err: tt.err}); got != tt.want {
+				t.Errorf("IsSkip() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
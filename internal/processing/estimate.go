@@ -0,0 +1,115 @@
+package processing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/ffmpeg"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// maxProbeChunks is the number of chunks encoded to estimate output size.
+const maxProbeChunks = 4
+
+// abortMinFraction is the share of frames that must be encoded before a live
+// projection is trusted enough to abort an encode.
+const abortMinFraction = 0.1
+
+// SizeAbortError reports an encode stopped because its projected output
+// exceeded the allowed fraction of the source size.
+type SizeAbortError struct {
+	Projected uint64
+	Source    uint64
+	Ratio     float64
+}
+
+func (e *SizeAbortError) Error() string {
+	return fmt.Sprintf("projected output %s exceeds %gx of source size %s",
+		util.FormatBytesReadable(e.Projected), e.Ratio, util.FormatBytesReadable(e.Source))
+}
+
+// exceedsSizeLimit reports whether a projection is over ratio times the source size.
+func exceedsSizeLimit(projected, sourceSize uint64, ratio float64) bool {
+	return ratio > 0 && sourceSize > 0 && float64(projected) > ratio*float64(sourceSize)
+}
+
+// selectProbeChunks picks up to n chunks spread evenly across the video,
+// taking the middle chunk of each equal section so probes avoid the
+// usually atypical opening and closing credits.
+func selectProbeChunks(chunks []chunk.Chunk, n int) []chunk.Chunk {
+	if n > len(chunks) {
+		n = len(chunks)
+	}
+	probes := make([]chunk.Chunk, 0, n)
+	for i := 0; i < n; i++ {
+		probes = append(probes, chunks[(2*i+1)*len(chunks)/(2*n)])
+	}
+	return probes
+}
+
+// audioBytesPerSecond returns the combined Opus output rate for all encoded
+// audio streams and the stereo downmix, if any. Copied streams aren't
+// counted, since their bitrate isn't known.
+func audioBytesPerSecond(streams []ffprobe.AudioStreamInfo, settings chunk.AudioSettings) float64 {
+	var kbps uint32
+	for _, s := range streams {
+		if !settings.Copies(s) {
+			_, channels, _ := chunk.OpusLayout(s)
+			kbps += ffmpeg.AudioBitrate(channels, settings.KbpsPerChannel)
+		}
+	}
+	if settings.Downmix != nil {
+		kbps += ffmpeg.AudioBitrate(2, settings.KbpsPerChannel)
+	}
+	return float64(kbps) * 1000 / 8
+}
+
+// extrapolateEstimate projects the final output from completed probe chunks.
+// Video size scales with frame count; audio is added at its fixed bitrate.
+// Elapsed is the wall time spent encoding the probes, or zero when they
+// were already done by an earlier run and no timing is available.
+func extrapolateEstimate(
+	probes []chunk.ChunkComp,
+	totalFrames int,
+	fps float64,
+	audioRate float64,
+	elapsed time.Duration,
+	workers int,
+) reporter.EncodeEstimate {
+	est := reporter.EncodeEstimate{ProbeChunks: len(probes)}
+
+	var probeSize uint64
+	for _, p := range probes {
+		est.ProbeFrames += p.Frames
+		probeSize += p.Size
+	}
+	if est.ProbeFrames == 0 || totalFrames == 0 || fps <= 0 {
+		return est
+	}
+
+	scale := float64(totalFrames) / float64(est.ProbeFrames)
+	est.EstimatedSize, est.BitrateKbps = projectOutput(probeSize, est.ProbeFrames, totalFrames, fps, audioRate)
+
+	// Probes ran one per worker, so with fewer probes than workers the full
+	// encode gets proportionally more parallelism than the probes did
+	if elapsed > 0 && workers > 0 {
+		parallel := min(len(probes), workers)
+		est.EstimatedTime = time.Duration(float64(elapsed) * scale * float64(parallel) / float64(workers))
+	}
+
+	return est
+}
+
+// projectOutput extrapolates the final output size and average video bitrate
+// from bytes written for a number of encoded frames.
+func projectOutput(videoBytes uint64, frames, totalFrames int, fps, audioRate float64) (uint64, float64) {
+	if frames <= 0 || totalFrames <= 0 || fps <= 0 {
+		return 0, 0
+	}
+	duration := float64(totalFrames) / fps
+	videoSize := float64(videoBytes) * float64(totalFrames) / float64(frames)
+	return uint64(videoSize + audioRate*duration), videoSize * 8 / duration / 1000
+}
@@ -0,0 +1,106 @@
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+)
+
+func TestSelectProbeChunks(t *testing.T) {
+	chunks := make([]chunk.Chunk, 20)
+	for i := range chunks {
+		chunks[i] = chunk.Chunk{Idx: i, Start: i * 100, End: (i + 1) * 100}
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		n     int
+		want  []int
+	}{
+		{"spread", 20, 4, []int{2, 7, 12, 17}},
+		{"single", 20, 1, []int{10}},
+		{"fewer chunks than probes", 3, 4, []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectProbeChunks(chunks[:tt.count], tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d probes, want %d", len(got), len(tt.want))
+			}
+			for i, c := range got {
+				if c.Idx != tt.want[i] {
+					t.Errorf("probe %d = chunk %d, want %d", i, c.Idx, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtrapolateEstimate(t *testing.T) {
+	probes := []chunk.ChunkComp{
+		{Idx: 2, Frames: 250, Size: 1_000_000},
+		{Idx: 7, Frames: 250, Size: 1_500_000},
+	}
+
+	// 500 probe frames of 10000 at 25fps: 400s of video, 50MB of video data
+	est := extrapolateEstimate(probes, 10000, 25, 16000, 20*time.Second, 4)
+
+	if est.ProbeChunks != 2 || est.ProbeFrames != 500 {
+		t.Errorf("probe counts = %d/%d, want 2/500", est.ProbeChunks, est.ProbeFrames)
+	}
+	if want := uint64(50_000_000 + 16000*400); est.EstimatedSize != want {
+		t.Errorf("EstimatedSize = %d, want %d", est.EstimatedSize, want)
+	}
+	if want := 1000.0; est.BitrateKbps != want {
+		t.Errorf("BitrateKbps = %v, want %v", est.BitrateKbps, want)
+	}
+	// 20s x 20 scale, but probes used 2 of 4 workers
+	if want := 200 * time.Second; est.EstimatedTime != want {
+		t.Errorf("EstimatedTime = %v, want %v", est.EstimatedTime, want)
+	}
+
+	if est := extrapolateEstimate(probes, 10000, 25, 0, 0, 4); est.EstimatedTime != 0 {
+		t.Errorf("EstimatedTime without timing = %v, want 0", est.EstimatedTime)
+	}
+}
+
+func TestProjectOutput(t *testing.T) {
+	// 2500 of 10000 frames at 25fps wrote 12.5MB: 50MB of video over 400s
+	size, kbps := projectOutput(12_500_000, 2500, 10000, 25, 16000)
+	if want := uint64(50_000_000 + 16000*400); size != want {
+		t.Errorf("size = %d, want %d", size, want)
+	}
+	if kbps != 1000 {
+		t.Errorf("kbps = %v, want 1000", kbps)
+	}
+
+	if size, kbps := projectOutput(0, 0, 10000, 25, 16000); size != 0 || kbps != 0 {
+		t.Errorf("no frames encoded = %d, %v; want 0, 0", size, kbps)
+	}
+}
+
+func TestExceedsSizeLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		projected uint64
+		source    uint64
+		ratio     float64
+		want      bool
+	}{
+		{"under", 800, 1000, 0.9, false},
+		{"over", 950, 1000, 0.9, true},
+		{"disabled", 5000, 1000, 0, false},
+		{"unknown source", 950, 0, 0.9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceedsSizeLimit(tt.projected, tt.source, tt.ratio); got != tt.want {
+				t.Errorf("exceedsSizeLimit(%d, %d, %g) = %v, want %v", tt.projected, tt.source, tt.ratio, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/keyframe"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+	"github.com/five82/reel/internal/worker"
+)
+
+// Growing inputs are still being ripped or downloaded. Their chunks are
+// encoded as the part of the file holding them is written, so the encode
+// finishes shortly after the rip does.
+const (
+	// growingPollInterval is how often a growing input is checked for more data
+	growingPollInterval = 5 * time.Second
+
+	// growingMarginSecs of video at the end of a growing input are left for
+	// later: the last packets may be partly written
+	growingMarginSecs = 30
+
+	// growingStartBytes of a growing input must exist before it is analyzed,
+	// so probing finds its streams
+	growingStartBytes = 64 << 20
+)
+
+// growingQuiet returns how long a growing input must go without growing to
+// count as finished.
+func growingQuiet(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.GrowingQuietSecs) * time.Second
+}
+
+// waitForGrowingInput waits until the input at path holds at least minBytes
+// or has stopped growing for quiet.
+func waitForGrowingInput(ctx context.Context, path string, minBytes uint64, quiet time.Duration) error {
+	for {
+		size, err := util.GetFileSize(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if size >= minBytes {
+			return nil
+		}
+		grew, err := util.WaitForGrowth(ctx, path, size, quiet, growingPollInterval)
+		if err != nil || !grew {
+			return err
+		}
+	}
+}
+
+// waitUntilFinished waits until the input at path has stopped growing.
+func waitUntilFinished(ctx context.Context, path string, quiet time.Duration) error {
+	return waitForGrowingInput(ctx, path, math.MaxUint64, quiet)
+}
+
+// readyChunks returns the fixed-length chunks of a growing input with frames
+// frames indexed so far that end at least growingMarginSecs before the last
+// one. Boundaries only depend on the frame rate and chunk length, so these
+// are also chunks of the finished file.
+func readyChunks(frames int, fpsNum, fpsDen uint32, chunkSecs float64) []chunk.Chunk {
+	if fpsDen == 0 {
+		return nil
+	}
+	limit := frames - int(float64(fpsNum)/float64(fpsDen)*growingMarginSecs)
+	starts := keyframe.GenerateFixedChunks(frames, fpsNum, fpsDen, chunkSecs)
+	chunks := chunk.Chunkify(chunk.ScenesFromStarts(starts, frames))
+	n := 0
+	for n < len(chunks) && chunks[n].End <= limit {
+		n++
+	}
+	return chunks[:n]
+}
+
+// growingEncode is what encoding the ready chunks of a growing input needs.
+type growingEncode struct {
+	cfg          *config.Config
+	inputPath    string
+	streamIndex  int
+	workDir      string
+	encCfg       *encode.EncodeConfig
+	cropH, cropV uint32
+	chunkSecs    float64
+	bitDepth     uint8
+	outFPSNum    uint32 // Output frame rate, which PAL slowdown changes
+	outFPSDen    uint32
+	rep          reporter.Reporter
+}
+
+// encodeGrowing encodes the ready chunks of a growing input in passes,
+// indexing it again after each, until it hasn't grown for the quiet period.
+// Encoded chunks are recorded for resume like any others, so the rest of the
+// pipeline only encodes what remains. It returns an index and video info of
+// the finished file, closing idx once it is replaced.
+func encodeGrowing(ctx context.Context, g growingEncode, idx *ffms.VidIdx, vidInf *ffms.VidInf) (*ffms.VidIdx, *ffms.VidInf, error) {
+	quiet := growingQuiet(g.cfg)
+	var encoded int // Chunks encoded by earlier passes
+	for {
+		size, err := util.GetFileSize(g.inputPath)
+		if err != nil {
+			return idx, vidInf, fmt.Errorf("failed to stat %s: %w", g.inputPath, err)
+		}
+
+		if ready := readyChunks(vidInf.Frames, vidInf.FPSNum, vidInf.FPSDen, g.chunkSecs); len(ready) > encoded {
+			outInf := *vidInf
+			outInf.FPSNum, outInf.FPSDen = g.outFPSNum, g.outFPSDen
+			encoding := newTaskProgress(g.rep, "Encoding while ripping", "frames")
+			if _, err := encode.EncodeAll(ctx, ready, &outInf, g.encCfg, idx, g.workDir, g.cropH, g.cropV, func(p worker.Progress) {
+				encoding.update(uint64(p.FramesEncoded()), uint64(p.FramesTotal))
+			}); err != nil {
+				return idx, vidInf, fmt.Errorf("chunked encoding failed: %w", err)
+			}
+			encoding.finish()
+			encoded = len(ready)
+			g.rep.StageProgress(reporter.StageProgress{Stage: "Encoding", Message: fmt.Sprintf(
+				"Encoded the first %d chunks; waiting for %s to grow", len(ready), util.GetFilename(g.inputPath))})
+		}
+
+		grew, err := util.WaitForGrowth(ctx, g.inputPath, size, quiet, growingPollInterval)
+		if err != nil {
+			return idx, vidInf, err
+		}
+		if !grew {
+			g.rep.StageProgress(reporter.StageProgress{Stage: "Encoding", Message: "Input stopped growing; encoding the rest"})
+		}
+
+		var next *ffms.VidIdx
+		if grew {
+			next, err = ffms.NewGrowingVidIdx(ctx, g.inputPath)
+		} else {
+			next, err = ffms.NewVidIdx(ctx, g.inputPath, nil)
+		}
+		if err != nil {
+			if !grew || ctx.Err() != nil {
+				return idx, vidInf, fmt.Errorf("failed to create video index: %w", err)
+			}
+			g.rep.Verbose(fmt.Sprintf("Could not index the growing input yet: %v", err))
+			continue
+		}
+		next.SelectTrack(g.streamIndex)
+		nextInf, err := ffms.GetVidInf(next)
+		if err != nil {
+			next.Close()
+			return idx, vidInf, fmt.Errorf("failed to get video info: %w", err)
+		}
+		nextInf.Output8Bit = g.bitDepth == 8
+		idx.Close()
+		idx, vidInf = next, nextInf
+		if !grew {
+			return idx, vidInf, nil
+		}
+		g.rep.Verbose(fmt.Sprintf("Input grew to %d frames", vidInf.Frames))
+	}
+}
@@ -0,0 +1,43 @@
+package processing
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/keyframe"
+)
+
+func TestReadyChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames int
+		want   int
+	}{
+		{"nothing past the margin", 24 * 20, 0},
+		{"chunks ending before the margin", 24 * 100, 7},
+		{"chunk ending exactly at the margin", 24 * 110, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readyChunks(tt.frames, 24, 1, 10)
+			if len(got) != tt.want {
+				t.Fatalf("readyChunks(%d) returned %d chunks, want %d", tt.frames, len(got), tt.want)
+			}
+
+			// Ready chunks are the first chunks of the finished file
+			finished := 24 * 3600
+			all := chunk.Chunkify(chunk.ScenesFromStarts(keyframe.GenerateFixedChunks(finished, 24, 1, 10), finished))
+			if !slices.Equal(got, all[:len(got)]) {
+				t.Errorf("readyChunks() = %v, want a prefix of the finished file's chunks", got)
+			}
+		})
+	}
+}
+
+func TestReadyChunksNoFrameRate(t *testing.T) {
+	if got := readyChunks(1000, 24, 0, 10); got != nil {
+		t.Errorf("readyChunks() = %v, want nil without a frame rate", got)
+	}
+}
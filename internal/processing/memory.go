@@ -0,0 +1,100 @@
+package processing
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+// memorySampleInterval is how often memory is measured during an encode.
+const memorySampleInterval = 2 * time.Second
+
+// ReportMemory is the peak resident memory measured while the chunks
+// encoded, for comparing with the memory per worker that worker capping
+// assumes.
+type ReportMemory struct {
+	PeakTotal    uint64 `json:"peak_total_bytes"`     // reel and its child processes together
+	PeakReel     uint64 `json:"peak_reel_bytes"`      // reel itself: decoding, frame buffers and any in-process encoders
+	PeakEncoder  uint64 `json:"peak_encoder_bytes"`   // Largest single encoder process; 0 with in-process encoders
+	PeakEncoders int    `json:"peak_encoders"`        // Most encoder processes running at once
+	Estimate     uint64 `json:"mem_per_worker_bytes"` // Memory per worker that capping assumed; 0 when not capping
+}
+
+// add folds a measurement of reel and its child processes into the peaks.
+// Encoder processes are told apart by name.
+func (m *ReportMemory) add(self util.ProcMemory, children []util.ProcMemory, encoderName string) {
+	total, encoders := self.RSS, 0
+	for _, c := range children {
+		total += c.RSS
+		if c.Name == encoderName {
+			encoders++
+			m.PeakEncoder = max(m.PeakEncoder, c.RSS)
+		}
+	}
+	m.PeakTotal = max(m.PeakTotal, total)
+	m.PeakReel = max(m.PeakReel, self.RSS)
+	m.PeakEncoders = max(m.PeakEncoders, encoders)
+}
+
+// memorySampler measures reel's memory and its children's in the
+// background until stopped.
+type memorySampler struct {
+	stop chan struct{}
+	done chan struct{}
+
+	mu   sync.Mutex
+	peak ReportMemory
+	ok   bool // Any measurement taken
+}
+
+// startMemorySampler starts measuring memory every memorySampleInterval.
+func startMemorySampler(paths toolpath.Paths) *memorySampler {
+	s := &memorySampler{stop: make(chan struct{}), done: make(chan struct{})}
+	// The kernel keeps the first 15 bytes of a command name
+	name := filepath.Base(paths.Path(toolpath.SvtAv1EncApp))
+	name = name[:min(len(name), 15)]
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			s.sample(name)
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func (s *memorySampler) sample(encoderName string) {
+	self, ok := util.ProcessMemory(os.Getpid())
+	if !ok {
+		return
+	}
+	children := util.ChildMemory(self.Pid)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peak.add(self, children, encoderName)
+	s.ok = true
+}
+
+// finish stops sampling and returns the peaks, or nil if memory couldn't
+// be measured on this system.
+func (s *memorySampler) finish() *ReportMemory {
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ok {
+		return nil
+	}
+	peak := s.peak
+	return &peak
+}
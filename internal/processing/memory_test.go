@@ -0,0 +1,30 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/util"
+)
+
+func TestReportMemoryAdd(t *testing.T) {
+	const gib = 1 << 30
+	var m ReportMemory
+	m.add(util.ProcMemory{RSS: 1 * gib}, []util.ProcMemory{
+		{Name: "SvtAv1EncApp", RSS: 2 * gib},
+		{Name: "SvtAv1EncApp", RSS: 3 * gib},
+		{Name: "ffmpeg", RSS: gib / 2},
+	}, "SvtAv1EncApp")
+	m.add(util.ProcMemory{RSS: 2 * gib}, []util.ProcMemory{
+		{Name: "SvtAv1EncApp", RSS: 1 * gib},
+	}, "SvtAv1EncApp")
+
+	want := ReportMemory{
+		PeakTotal:    6*gib + gib/2, // The first sample, everything together
+		PeakReel:     2 * gib,       // The second sample
+		PeakEncoder:  3 * gib,
+		PeakEncoders: 2,
+	}
+	if m != want {
+		t.Errorf("add() peaks = %+v, want %+v", m, want)
+	}
+}
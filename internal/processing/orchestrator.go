@@ -2,15 +2,24 @@ package processing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/five82/reel/internal/chunk"
 	"github.com/five82/reel/internal/config"
 	"github.com/five82/reel/internal/encoder"
 	"github.com/five82/reel/internal/ffmpeg"
 	"github.com/five82/reel/internal/ffprobe"
 	"github.com/five82/reel/internal/mediainfo"
 	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/tools"
 	"github.com/five82/reel/internal/util"
 	"github.com/five82/reel/internal/validation"
 )
@@ -18,6 +27,7 @@ import (
 // EncodeResult contains the result of a single file encode.
 type EncodeResult struct {
 	Filename          string
+	OutputPath        string
 	Duration          time.Duration
 	InputSize         uint64
 	OutputSize        uint64
@@ -25,27 +35,61 @@ type EncodeResult struct {
 	EncodingSpeed     float32
 	ValidationPassed  bool
 	ValidationSteps   []validation.ValidationStep
+	Copies            []CopyResult
+	Checksum          string // Hex digest of the output with cfg.Checksum, if set
 }
 
 // ProcessVideos orchestrates encoding for a list of video files.
+// outputOverrides maps an input path to its output path, absolute or relative
+// to cfg.OutputDir; other inputs are written as <name>.mkv in cfg.OutputDir.
+// Inputs that produced no output are returned as failures with the cause.
 func ProcessVideos(
 	ctx context.Context,
 	cfg *config.Config,
 	filesToProcess []string,
-	targetFilenameOverride string,
+	outputOverrides map[string]string,
 	rep reporter.Reporter,
-) ([]EncodeResult, error) {
+) ([]EncodeResult, []*FileError, error) {
 	if rep == nil {
 		rep = reporter.NullReporter{}
 	}
 
-	var results []EncodeResult
+	if err := validateDestinations(cfg.CopyDestinations); err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := applyEncoderCapabilities(withTools(cfg), rep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Encode inputs that are the same file or have identical content once
+	var duplicates map[string][]string
+	if cfg.DuplicatePolicy != config.DuplicatesEncode && len(filesToProcess) > 1 {
+		unique, dups, err := dedupeInputs(filesToProcess)
+		if err != nil {
+			rep.Warning(fmt.Sprintf("Skipping duplicate detection: %v", err))
+		} else if len(unique) < len(filesToProcess) {
+			rep.Warning(fmt.Sprintf("%d duplicate inputs will be encoded once (duplicates: %s)",
+				len(filesToProcess)-len(unique), cfg.DuplicatePolicy))
+			for _, original := range unique {
+				for _, dup := range dups[original] {
+					rep.Verbose(fmt.Sprintf("%s is a duplicate of %s", dup, original))
+				}
+			}
+			filesToProcess, duplicates = unique, dups
+		}
+	}
+
+	// Inputs that share a name in different directories would be skipped
+	// for each other's output
+	namer := newOutputNamer(cfg, outputOverrides)
+	if err := checkOutputCollisions(filesToProcess, namer); err != nil {
+		return nil, nil, err
+	}
 
 	// Emit hardware information
-	sysInfo := util.GetSystemInfo()
-	rep.Hardware(reporter.HardwareSummary{
-		Hostname: sysInfo.Hostname,
-	})
+	rep.Hardware(hardwareSummary(cfg))
 
 	// Show batch initialization for multiple files
 	if len(filesToProcess) > 1 {
@@ -60,217 +104,76 @@ func ProcessVideos(
 		})
 	}
 
-	for fileIdx, inputPath := range filesToProcess {
-		// Check for cancellation before starting each file
-		if ctx.Err() != nil {
-			rep.Warning(fmt.Sprintf("Encoding cancelled: %v", ctx.Err()))
-			break
-		}
-
-		fileStartTime := time.Now()
-
-		// Show file progress for multiple files
-		if len(filesToProcess) > 1 {
-			rep.FileProgress(reporter.FileProgressContext{
-				CurrentFile: fileIdx + 1,
-				TotalFiles:  len(filesToProcess),
-			})
-		}
-
-		inputFilename := util.GetFilename(inputPath)
-
-		// Determine output path
-		override := ""
-		if len(filesToProcess) == 1 && targetFilenameOverride != "" {
-			override = targetFilenameOverride
-		}
-		outputPath := util.ResolveOutputPath(inputPath, cfg.OutputDir, override)
-
-		// Skip if output exists
-		if util.FileExists(outputPath) {
-			rep.Warning(fmt.Sprintf("Output file already exists: %s. Skipping encode.", outputPath))
-			continue
-		}
-
-		// Analyze video properties
-		videoProps, err := ffprobe.GetVideoProperties(inputPath)
-		if err != nil {
-			rep.Error(reporter.ReporterError{
-				Title:      "Analysis Error",
-				Message:    fmt.Sprintf("Could not analyze %s: %v", inputFilename, err),
-				Context:    fmt.Sprintf("File: %s", inputPath),
-				Suggestion: "Check if the file is a valid video format",
-			})
-			continue
-		}
-
-		// Use mediainfo for HDR detection
-		mediaInfoData, err := mediainfo.GetMediaInfo(inputPath)
-		if err != nil {
-			rep.Error(reporter.ReporterError{
-				Title:      "Analysis Error",
-				Message:    fmt.Sprintf("Could not get mediainfo for %s: %v", inputFilename, err),
-				Context:    fmt.Sprintf("File: %s", inputPath),
-				Suggestion: "Check if mediainfo is installed",
-			})
-			continue
-		}
-		hdrInfo := mediainfo.DetectHDR(mediaInfoData)
-
-		// Determine quality settings
-		quality, _ := determineQualitySettings(videoProps, cfg)
-		isHDR := hdrInfo.IsHDR
-
-		// Get audio info
-		audioChannels := GetAudioChannels(inputPath)
-		audioStreams := GetAudioStreamInfo(inputPath)
-		audioDescription := FormatAudioDescription(audioChannels)
-
-		// Emit initialization event
-		rep.Initialization(reporter.InitializationSummary{
-			InputFile:        inputFilename,
-			OutputFile:       util.GetFilename(outputPath),
-			Duration:         util.FormatDuration(videoProps.DurationSecs),
-			Resolution:       fmt.Sprintf("%dx%d", videoProps.Width, videoProps.Height),
-			DynamicRange:     formatDynamicRange(isHDR),
-			AudioDescription: audioDescription,
-		})
-
-		// Verbose video analysis details
-		rep.Verbose(fmt.Sprintf("Video duration: %.2f seconds", videoProps.DurationSecs))
-		if isHDR {
-			rep.Verbose(fmt.Sprintf("Color primaries: %s, transfer: %s", hdrInfo.ColourPrimaries, hdrInfo.TransferCharacteristics))
-		}
-
-		// Setup encode parameters (for display only)
-		encodeParams := setupEncodeParams(cfg, quality, hdrInfo)
-
-		// Format audio description for config display
-		audioDescConfig := FormatAudioDescriptionConfig(audioChannels, audioStreams)
-
-		// Emit encoding config
-		rep.EncodingConfig(reporter.EncodingConfigSummary{
-			Encoder:            "SVT-AV1",
-			Preset:             fmt.Sprintf("%d", encodeParams.Preset),
-			Tune:               fmt.Sprintf("%d", encodeParams.Tune),
-			Quality:            formatQualityDescription(videoProps.Width, encodeParams.Quality),
-			PixelFormat:        encodeParams.PixelFormat,
-			MatrixCoefficients: encodeParams.MatrixCoefficients,
-			AudioCodec:         "Opus",
-			AudioDescription:   audioDescConfig,
-			SVTAV1Params:       encoder.SvtParamsDisplay(cfg.SVTAV1ACBias, cfg.SVTAV1EnableVarianceBoost, cfg.SVTAV1Tune),
-		})
-
-		// Run chunked encoding with FFMS2 + SvtAv1EncApp
-		cropResult, encodeError := ProcessChunked(ctx, cfg, inputPath, outputPath, videoProps, audioStreams, quality, rep)
-		encodeSuccess := encodeError == nil
-
-		if !encodeSuccess {
-			rep.Error(reporter.ReporterError{
-				Title:      "Encoding Error",
-				Message:    fmt.Sprintf("Failed to encode %s: %v", inputFilename, encodeError),
-				Context:    fmt.Sprintf("File: %s", inputPath),
-				Suggestion: "Check logs for more details",
-			})
-			continue
-		}
-
-		fileElapsedTime := time.Since(fileStartTime)
-
-		inputSize, _ := util.GetFileSize(inputPath)
-		outputSize, _ := util.GetFileSize(outputPath)
-		encodingSpeed := float32(videoProps.DurationSecs) / float32(fileElapsedTime.Seconds())
-
-		// Calculate expected dimensions after crop
-		expectedWidth, expectedHeight := GetOutputDimensions(videoProps.Width, videoProps.Height, cropResult.CropFilter)
-
-		// Validate output
-		expectedDims := &[2]uint32{expectedWidth, expectedHeight}
-		expectedDuration := videoProps.DurationSecs
-		expectedAudioTracks := len(audioChannels)
-
-		validationResult, err := validation.ValidateOutputVideo(inputPath, outputPath, validation.Options{
-			ExpectedDimensions:  expectedDims,
-			ExpectedDuration:    &expectedDuration,
-			ExpectedHDR:         &isHDR,
-			ExpectedAudioTracks: &expectedAudioTracks,
-		})
-
-		var validationPassed bool
-		var validationSteps []validation.ValidationStep
-		if err != nil {
-			validationPassed = false
-			validationSteps = []validation.ValidationStep{
-				{Name: "Validation", Passed: false, Details: err.Error()},
-			}
+	// Continue a batch interrupted by an earlier run of the same command
+	var state *batchState
+	if cfg.BatchStateDir != "" && len(filesToProcess) > 1 {
+		var path string
+		settings := batchSettingsKey(cfg)
+		if cfg.BatchDir != "" {
+			path = batchDirStatePath(cfg.BatchStateDir, cfg.BatchDir, cfg.OutputDir, cfg.OutputTemplate, settings)
 		} else {
-			validationPassed = validationResult.IsValid()
-			for _, step := range validationResult.GetValidationSteps() {
-				validationSteps = append(validationSteps, validation.ValidationStep{
-					Name:    step.Name,
-					Passed:  step.Passed,
-					Details: step.Details,
-				})
+			outputs := make([]string, len(filesToProcess))
+			for i, f := range filesToProcess {
+				outputs[i] = namer.path(f)
 			}
+			path = batchStatePath(cfg.BatchStateDir, filesToProcess, outputs, settings)
 		}
-
-		results = append(results, EncodeResult{
-			Filename:          inputFilename,
-			Duration:          fileElapsedTime,
-			InputSize:         inputSize,
-			OutputSize:        outputSize,
-			VideoDurationSecs: videoProps.DurationSecs,
-			EncodingSpeed:     encodingSpeed,
-			ValidationPassed:  validationPassed,
-			ValidationSteps:   validationSteps,
-		})
-
-		// Emit validation complete
-		var repSteps []reporter.ValidationStep
-		for _, s := range validationSteps {
-			repSteps = append(repSteps, reporter.ValidationStep{
-				Name:    s.Name,
-				Passed:  s.Passed,
-				Details: s.Details,
-			})
+		state, err = loadBatchState(path, filesToProcess, cfg.ResumeBatch)
+		if err != nil {
+			rep.Warning(fmt.Sprintf("Starting the batch over: %v", err))
+		} else if n, failed := len(state.Done), len(state.Failed); cfg.ResumeBatch && failed > 0 {
+			rep.Warning(fmt.Sprintf("Resuming batch: %d of %d files finished and %d failed in an earlier run; skipping them",
+				n, len(filesToProcess), failed))
+		} else if n > 0 {
+			rep.Warning(fmt.Sprintf("Resuming batch: %d of %d files finished in an earlier run", n, len(filesToProcess)))
 		}
-		rep.ValidationComplete(reporter.ValidationSummary{
-			Passed: validationPassed,
-			Steps:  repSteps,
-		})
+	}
 
-		// Emit encoding complete
-		rep.EncodingComplete(reporter.EncodingOutcome{
-			InputFile:    inputFilename,
-			OutputFile:   util.GetFilename(outputPath),
-			OriginalSize: inputSize,
-			EncodedSize:  outputSize,
-			VideoStream:  fmt.Sprintf("AV1 (libsvtav1), %dx%d", expectedWidth, expectedHeight),
-			AudioStream:  GenerateAudioResultsDescription(audioChannels, audioStreams),
-			TotalTime:    fileElapsedTime,
-			AverageSpeed: encodingSpeed,
-			OutputPath:   outputPath,
-		})
+	// Files encoding at once share the workers; with one file there is
+	// nothing to share
+	cfg.ParallelFiles = max(min(cfg.ParallelFiles, len(filesToProcess)), 1)
 
-		// Cooldown between encodes
-		if len(filesToProcess) > 1 && fileIdx < len(filesToProcess)-1 && cfg.EncodeCooldownSecs > 0 {
-			time.Sleep(time.Duration(cfg.EncodeCooldownSecs) * time.Second)
+	b := &batch{
+		cfg:        cfg,
+		files:      filesToProcess,
+		outputs:    namer,
+		duplicates: duplicates,
+		state:      state,
+	}
+	if cfg.ParallelFiles > 1 {
+		rep.Verbose(fmt.Sprintf("Encoding up to %d files at once", cfg.ParallelFiles))
+		b.runParallel(ctx, rep)
+	} else {
+		for fileIdx, inputPath := range filesToProcess {
+			b.encodeFile(ctx, fileIdx, inputPath, rep)
+		}
+		b.next.stop()
+	}
+	results, failures, skipped := b.results, b.failures, b.skipped
+	if state != nil && state.complete() {
+		if err := state.remove(); err != nil {
+			rep.Warning(err.Error())
 		}
 	}
 
-	// Generate summary
-	switch len(results) {
-	case 0:
-		rep.Warning("No files were successfully encoded")
-	case 1:
+	// Generate summary; a batch summary accounts for every input
+	switch {
+	case len(filesToProcess) == 1 && len(results) == 1:
 		rep.OperationComplete(fmt.Sprintf("Successfully encoded %s", results[0].Filename))
+	case len(filesToProcess) == 1:
+		rep.Warning("No files were successfully encoded")
 	default:
+		if len(results) == 0 {
+			rep.Warning("No files were successfully encoded")
+		}
+
 		// Calculate totals
 		var totalDuration time.Duration
 		var totalOriginalSize, totalEncodedSize uint64
 		var totalVideoDuration float64
 		var fileResults []reporter.FileResult
 		validationPassedCount := 0
+		copySucceeded, copyFailed := 0, 0
 
 		for _, r := range results {
 			totalDuration += r.Duration
@@ -285,6 +188,13 @@ func ProcessVideos(
 			if r.ValidationPassed {
 				validationPassedCount++
 			}
+			for _, c := range r.Copies {
+				if c.Err != nil {
+					copyFailed++
+				} else {
+					copySucceeded++
+				}
+			}
 		}
 
 		avgSpeed := float32(0)
@@ -302,10 +212,875 @@ func ProcessVideos(
 			FileResults:           fileResults,
 			ValidationPassedCount: validationPassedCount,
 			ValidationFailedCount: len(results) - validationPassedCount,
+			CopySucceededCount:    copySucceeded,
+			CopyFailedCount:       copyFailed,
+			Skipped:               skipped,
+			Failed:                failedFiles(failures),
+			Deferred:              deferredFiles(failures),
+		})
+	}
+
+	return results, failures, nil
+}
+
+// batch holds the state of a ProcessVideos run shared by its files.
+type batch struct {
+	cfg        *config.Config
+	files      []string
+	outputs    *outputNamer
+	duplicates map[string][]string
+
+	// Analysis of the next file, started while the current one encodes
+	next *prefetch
+
+	// Inputs finished by earlier runs of the batch; nil when not kept
+	state *batchState
+
+	// Guards the fields below when files encode in parallel
+	mu       sync.Mutex
+	results  []EncodeResult
+	failures []*FileError
+	skipped  []reporter.SkippedFile
+	started  int   // Files started, for cfg.MaxFilesPerRun
+	stopErr  error // Once set, the remaining inputs are recorded as failures with this cause
+}
+
+// fail records that inputPath produced no output, stopping the batch if
+// fail-fast is set.
+func (b *batch) fail(rep reporter.Reporter, inputPath string, err error) {
+	b.mu.Lock()
+	b.failures = append(b.failures, &FileError{Input: inputPath, Err: err})
+	// A size abort is retried like a failure; the next run may encode smaller
+	var sizeErr *SizeAbortError
+	if errors.As(err, &sizeErr) || isFailure(err) {
+		b.markFailed(rep, inputPath, err.Error())
+	} else if IsSkip(err) && !errors.Is(err, ErrFinishedEarlier) {
+		b.markDone(rep, inputPath, err.Error())
+	}
+	b.mu.Unlock()
+	if b.cfg.FailFast && !IsSkip(err) && !errors.Is(err, ErrCancelled) && !errors.Is(err, ErrDeferred) {
+		b.stopAfter(rep, util.GetFilename(inputPath))
+	}
+}
+
+// stopAfter stops the batch after filename failed (fail-fast).
+func (b *batch) stopAfter(rep reporter.Reporter, filename string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopErr == nil && len(b.files) > 1 {
+		rep.Warning(fmt.Sprintf("Stopping batch after %s failed (fail-fast)", filename))
+		b.stopErr = fmt.Errorf("%w: batch stopped after %s failed", ErrNotAttempted, filename)
+	}
+}
+
+// stop stops the batch with err unless it was already stopped, and returns
+// the cause the remaining inputs are recorded with.
+func (b *batch) stop(err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopErr == nil {
+		b.stopErr = err
+	}
+	return b.stopErr
+}
+
+// startFile counts a file as started, unless the run has already started
+// cfg.MaxFilesPerRun files.
+func (b *batch) startFile() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cfg.MaxFilesPerRun > 0 && b.started >= b.cfg.MaxFilesPerRun {
+		return false
+	}
+	b.started++
+	return true
+}
+
+// atFileLimit reports whether the run has started cfg.MaxFilesPerRun files.
+func (b *batch) atFileLimit() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cfg.MaxFilesPerRun > 0 && b.started >= b.cfg.MaxFilesPerRun
+}
+
+// stopped returns why the batch stopped, or nil.
+func (b *batch) stopped() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stopErr
+}
+
+// checkStop stops the batch if ctx was cancelled and returns why it stopped.
+func (b *batch) checkStop(ctx context.Context, rep reporter.Reporter) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopErr == nil && ctx.Err() != nil {
+		rep.Warning(fmt.Sprintf("Encoding cancelled: %v", ctx.Err()))
+		b.stopErr = cancelled(ctx.Err())
+	}
+	return b.stopErr
+}
+
+func (b *batch) skip(file reporter.SkippedFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.skipped = append(b.skipped, file)
+}
+
+func (b *batch) addResult(rep reporter.Reporter, inputPath string, r EncodeResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.results = append(b.results, r)
+	if r.ValidationPassed {
+		b.markDone(rep, inputPath, "encoded")
+	} else {
+		b.markFailed(rep, inputPath, ErrValidationFailed.Error())
+	}
+}
+
+// markDone records in the batch state that inputPath needs no further runs.
+// The caller holds b.mu.
+func (b *batch) markDone(rep reporter.Reporter, inputPath, outcome string) {
+	if b.state == nil {
+		return
+	}
+	if err := b.state.markDone(inputPath, outcome); err != nil {
+		rep.Warning(err.Error())
+	}
+}
+
+// markFailed records in the batch state that inputPath failed, for
+// --resume-batch to skip. The caller holds b.mu.
+func (b *batch) markFailed(rep reporter.Reporter, inputPath, reason string) {
+	if b.state == nil {
+		return
+	}
+	if err := b.state.markFailed(inputPath, reason); err != nil {
+		rep.Warning(err.Error())
+	}
+}
+
+// finishedEarlier returns how inputPath finished in an earlier run of the
+// batch, counting failures with --resume-batch. A finished input whose
+// output has since gone is not counted, so it is encoded again.
+func (b *batch) finishedEarlier(inputPath string) (string, bool) {
+	if b.state == nil {
+		return "", false
+	}
+	b.mu.Lock()
+	outcome, done := b.state.Done[inputPath]
+	reason, failed := b.state.Failed[inputPath]
+	b.mu.Unlock()
+	switch {
+	case done && util.FileExists(b.outputs.path(inputPath)):
+		return outcome, true
+	case failed && b.cfg.ResumeBatch:
+		return "failed: " + reason, true
+	}
+	return "", false
+}
+
+// encodeFile analyzes, encodes and validates one input of the batch,
+// recording the result or failure.
+func (b *batch) encodeFile(ctx context.Context, fileIdx int, inputPath string, rep reporter.Reporter) {
+	cfg := b.cfg
+	pre := b.next
+	b.next = nil
+	defer pre.stop()
+
+	fail := func(err error) {
+		b.fail(rep, inputPath, err)
+	}
+
+	// Skip inputs an earlier run of the batch finished
+	if outcome, ok := b.finishedEarlier(inputPath); ok {
+		fail(fmt.Errorf("%w: %s", ErrFinishedEarlier, outcome))
+		return
+	}
+
+	// Check for cancellation before starting each file
+	if err := b.checkStop(ctx, rep); err != nil {
+		fail(err)
+		return
+	}
+
+	// Don't start a new file outside the schedule window
+	if cfg.Schedule != nil {
+		err := cfg.Schedule.Wait(ctx, func(resume time.Time) {
+			rep.StageProgress(reporter.StageProgress{
+				Stage:   "Paused",
+				Message: fmt.Sprintf("Outside schedule %s; resuming at %s", cfg.Schedule, resume.Format("15:04")),
+			})
+		})
+		if err != nil {
+			rep.Warning(fmt.Sprintf("Encoding cancelled: %v", err))
+			fail(b.stop(cancelled(err)))
+			return
+		}
+	}
+
+	// Leave files for a later run once the deadline has passed
+	if !cfg.Deadline.IsZero() && !time.Now().Before(cfg.Deadline) {
+		fail(fmt.Errorf("%w: not started before %s", ErrDeferred, cfg.Deadline.Format("15:04")))
+		return
+	}
+
+	fileStartTime := time.Now()
+
+	// Show file progress for multiple files
+	if len(b.files) > 1 {
+		rep.FileProgress(reporter.FileProgressContext{
+			CurrentFile: fileIdx + 1,
+			TotalFiles:  len(b.files),
+		})
+	}
+
+	inputFilename := util.GetFilename(inputPath)
+
+	// Determine output path
+	outputPath := b.outputs.path(inputPath)
+	if err := util.EnsureOutputDirectory(filepath.Dir(outputPath), cfg.OutputPerms); err != nil {
+		rep.Error(reporter.ReporterError{
+			Title:      "Output Error",
+			Message:    fmt.Sprintf("Cannot create output directory for %s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("Output: %s", outputPath),
+			Suggestion: "Check permissions on the output path",
+		})
+		fail(err)
+		return
+	}
+
+	// Skip if output exists
+	if util.FileExists(outputPath) {
+		rep.Warning(fmt.Sprintf("Output file already exists: %s. Skipping encode.", outputPath))
+		fail(fmt.Errorf("%w: %s", ErrOutputExists, outputPath))
+		return
+	}
+
+	// Leave files for a later run once this one has started its share;
+	// outputs already in place above don't count
+	if !b.startFile() {
+		fail(fmt.Errorf("%w: %d files already started this run", ErrDeferred, cfg.MaxFilesPerRun))
+		return
+	}
+
+	// A source that is still being ripped or downloaded is analyzed once
+	// enough of it is there to find its streams
+	if cfg.GrowingQuietSecs > 0 {
+		rep.StageProgress(reporter.StageProgress{
+			Stage:   "Waiting",
+			Message: fmt.Sprintf("Waiting for %s to grow to %s", inputFilename, util.FormatBytes(growingStartBytes)),
+		})
+		if err := waitForGrowingInput(ctx, inputPath, growingStartBytes, growingQuiet(cfg)); err != nil {
+			rep.Warning(fmt.Sprintf("Stopped waiting for %s: %v", inputFilename, err))
+			if ctx.Err() != nil {
+				err = cancelled(err)
+			}
+			fail(err)
+			return
+		}
+	}
+
+	// Apply per-title overrides from a sidecar next to the source
+	fileCfg := cfg
+	sidecarPath := config.SidecarPath(inputPath)
+	sidecar, err := config.LoadSidecar(sidecarPath)
+	if err == nil && sidecar != nil {
+		adjusted := *cfg
+		err = adjusted.ApplySidecar(sidecar)
+		fileCfg = &adjusted
+	}
+	if err != nil {
+		rep.Error(reporter.ReporterError{
+			Title:      "Sidecar Error",
+			Message:    fmt.Sprintf("Invalid per-title settings for %s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("File: %s", sidecarPath),
+			Suggestion: "Fix or remove the sidecar file",
+		})
+		fail(err)
+		return
+	}
+	if sidecar != nil {
+		rep.Verbose(fmt.Sprintf("Using per-title settings from %s", sidecarPath))
+	}
+
+	// Analyze video properties, unless that was done during the last encode
+	if pre = pre.wait(ctx); pre != nil {
+		rep.Verbose("Using analysis prefetched during the previous encode")
+	}
+	videoProps, err := pre.videoProperties(inputPath, fileCfg)
+	if errors.Is(err, ffprobe.ErrNoVideo) {
+		rep.Warning(fmt.Sprintf("Skipping %s: %v", inputFilename, err))
+		b.skip(reporter.SkippedFile{Filename: inputFilename, Reason: err.Error()})
+		fail(err)
+		return
+	}
+	if err != nil {
+		rep.Error(reporter.ReporterError{
+			Title:      "Analysis Error",
+			Message:    fmt.Sprintf("Could not analyze %s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("File: %s", inputPath),
+			Suggestion: "Check if the file is a valid video format",
+		})
+		fail(err)
+		return
+	}
+
+	// The pipeline decodes every frame at the stream's size
+	if err := pre.checkResolution(cfg.Tools, inputPath, videoProps); errors.Is(err, ErrVariableResolution) {
+		rep.Error(reporter.ReporterError{
+			Title:      "Unsupported Source",
+			Message:    fmt.Sprintf("%s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("File: %s", inputPath),
+			Suggestion: fmt.Sprintf("Scale the source to one resolution first, e.g. ffmpeg -i input -vf scale=%d:%d -c:a copy output.mkv", videoProps.Width, videoProps.Height),
+		})
+		fail(err)
+		return
+	} else if err != nil {
+		rep.Warning(fmt.Sprintf("Could not check %s for resolution changes: %v", inputFilename, err))
+	}
+
+	// Use mediainfo for HDR detection
+	mediaInfoData, err := pre.mediaInfoData(cfg.Tools, inputPath)
+	if err != nil {
+		rep.Error(reporter.ReporterError{
+			Title:      "Analysis Error",
+			Message:    fmt.Sprintf("Could not get mediainfo for %s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("File: %s", inputPath),
+			Suggestion: "Check if mediainfo is installed",
+		})
+		if errors.Is(err, exec.ErrNotFound) {
+			err = fmt.Errorf("%w: %w", tools.ErrDependencyMissing, err)
+		}
+		fail(err)
+		return
+	}
+	hdrInfo := mediainfo.DetectHDR(mediaInfoData)
+
+	// Determine quality settings
+	quality, _ := determineQualitySettings(videoProps, fileCfg)
+	isHDR := hdrInfo.IsHDR
+	bitDepth, depthWarning := outputBitDepth(fileCfg.BitDepth, hdrInfo)
+	if depthWarning != "" {
+		rep.Warning(depthWarning)
+	}
+
+	// Get audio info
+	audioChannels, audioStreams := pre.audio(cfg.Tools, inputPath)
+	if len(fileCfg.AudioTracks) > 0 || len(fileCfg.AudioLanguages) > 0 {
+		audioStreams, err = SelectAudioStreams(audioStreams, fileCfg.AudioTracks, fileCfg.AudioLanguages)
+		if err != nil {
+			rep.Error(reporter.ReporterError{
+				Title:      "Audio Selection Error",
+				Message:    fmt.Sprintf("Cannot select audio for %s: %v", inputFilename, err),
+				Context:    fmt.Sprintf("File: %s", inputPath),
+				Suggestion: "Check the audio tracks and languages in the sidecar file",
+			})
+			fail(err)
+			return
+		}
+		audioChannels = audioChannels[:0]
+		for _, s := range audioStreams {
+			audioChannels = append(audioChannels, s.Channels)
+		}
+	}
+	audioDescription := FormatAudioDescription(audioChannels)
+	objectAudio := SelectObjectAudio(mediainfo.DetectObjectAudio(mediaInfoData), audioStreams)
+	audioSettings := chunk.AudioSettings{
+		KbpsPerChannel: fileCfg.AudioKbpsPerChannel,
+		Passthrough:    fileCfg.AudioPassthrough,
+		Downmix:        StereoDownmix(fileCfg, audioStreams),
+	}
+	if fileCfg.PreserveAtmos {
+		for _, o := range objectAudio {
+			audioSettings.Preserve = append(audioSettings.Preserve, o.Index)
+		}
+	}
+
+	// Emit initialization event
+	rep.Initialization(reporter.InitializationSummary{
+		InputFile:        inputFilename,
+		OutputFile:       util.GetFilename(outputPath),
+		Duration:         util.FormatDuration(videoProps.DurationSecs),
+		Resolution:       fmt.Sprintf("%dx%d", videoProps.Width, videoProps.Height),
+		DynamicRange:     formatDynamicRange(isHDR),
+		AudioDescription: audioDescription,
+		ObjectAudio:      FormatObjectAudio(objectAudio),
+	})
+	for _, o := range objectAudio {
+		if i := slices.IndexFunc(audioStreams, func(s ffprobe.AudioStreamInfo) bool { return s.Index == o.Index }); !audioSettings.Copies(audioStreams[i]) {
+			rep.Warning(fmt.Sprintf("Audio stream %d is %s; encoding it to Opus discards its object metadata (use --preserve-atmos to copy it)", o.Index, o.Format))
+		}
+	}
+
+	// Verbose video analysis details
+	rep.Verbose(fmt.Sprintf("Video duration: %.2f seconds", videoProps.DurationSecs))
+	if isHDR {
+		rep.Verbose(fmt.Sprintf("Color primaries: %s, transfer: %s", hdrInfo.ColourPrimaries, hdrInfo.TransferCharacteristics))
+	}
+
+	// Tune for the kind of content, classifying the source unless it was given
+	content := fileCfg.ContentType
+	if content == config.ContentAuto {
+		if content = pre.detectedContent(); content == "" {
+			rep.Verbose("Classifying content from sampled frames")
+			content = DetectContent(cfg.Tools, inputPath, videoProps)
+		}
+	}
+	if content != config.ContentFilm {
+		adjusted := *fileCfg
+		if err := adjusted.ApplyContent(content); err != nil {
+			rep.Warning(fmt.Sprintf("Ignoring content type: %v", err))
+		} else {
+			fileCfg = &adjusted
+		}
+	}
+
+	// Deinterlacing writes a lossless intermediate, so it is only done for
+	// sources idet finds interlaced
+	if fileCfg.Deinterlace {
+		rep.Verbose("Checking the source for interlacing")
+		if interlaced, err := DetectInterlaced(ctx, cfg.Tools, inputPath, videoProps); err != nil {
+			rep.Verbose(fmt.Sprintf("%v; deinterlacing anyway", err))
+		} else if !interlaced {
+			rep.Verbose("Source is progressive; skipping deinterlacing")
+			adjusted := *fileCfg
+			adjusted.Deinterlace = false
+			fileCfg = &adjusted
+		}
+	}
+
+	// Setup encode parameters (for display only)
+	encodeParams := setupEncodeParams(fileCfg, videoProps.Width, quality, bitDepth, hdrInfo)
+
+	// Format audio description for config display
+	audioDescConfig := FormatAudioDescriptionConfig(audioChannels, audioStreams, audioSettings)
+	if downmix := audioSettings.Downmix; downmix != nil {
+		audioDescConfig += ", " + FormatDownmixDescription(downmix, fileCfg.AudioKbpsPerChannel)
+	} else if fileCfg.StereoDownmix && len(audioStreams) > 0 {
+		rep.Verbose("No surround audio to downmix; skipping the stereo downmix")
+	}
+
+	// Emit encoding config
+	rep.EncodingConfig(reporter.EncodingConfigSummary{
+		Encoder:            "SVT-AV1",
+		Preset:             fmt.Sprintf("%d", encodeParams.Preset),
+		Tune:               fmt.Sprintf("%d", encodeParams.Tune),
+		Quality:            formatQualityDescription(videoProps.Width, encodeParams.Quality),
+		PixelFormat:        encodeParams.PixelFormat,
+		MatrixCoefficients: encodeParams.MatrixCoefficients,
+		AudioCodec:         "Opus",
+		AudioDescription:   audioDescConfig,
+		Content:            formatContent(content, fileCfg.ContentType == config.ContentAuto),
+		SVTAV1Params: encoder.SvtParamsDisplay(&encoder.EncConfig{
+			Tune:                fileCfg.SVTAV1Tune,
+			ACBias:              fileCfg.SVTAV1ACBias,
+			EnableVarianceBoost: fileCfg.SVTAV1EnableVarianceBoost,
+			KeyintSecs:          fileCfg.KeyintSecs,
+			SCM:                 fileCfg.SVTAV1SCM,
+			DisableSCD:          !fileCfg.SVTAV1SceneDetection,
+			TileRows:            fileCfg.SVTAV1TileRows,
+			TileColumns:         fileCfg.SVTAV1TileColumns,
+		}),
+	})
+
+	// A growing source's final size is unknown, so its work files can't be
+	// sized for memory
+	if fileCfg.GrowingQuietSecs > 0 && fileCfg.ScratchLocation != config.ScratchDisk {
+		rep.Verbose("Keeping work files on disk for an input that is still growing")
+		adjusted := *fileCfg
+		adjusted.ScratchLocation = config.ScratchDisk
+		fileCfg = &adjusted
+	}
+
+	// Make sure the work directory has room for chunks and the merged video
+	inputSize, _ := util.GetFileSize(inputPath)
+	scratch := EstimateScratchSpace(inputSize, videoProps.Width)
+	if fileCfg.Deinterlace {
+		scratch += EstimateDeinterlaceSpace(videoProps)
+	}
+	tempDir, err := selectTempDir(fileCfg, inputPath, scratch)
+	if err != nil {
+		rep.Error(reporter.ReporterError{
+			Title:      "Disk Space Error",
+			Message:    fmt.Sprintf("Cannot encode %s: %v", inputFilename, err),
+			Context:    fmt.Sprintf("File: %s", inputPath),
+			Suggestion: "Free up space or use --temp-dir to point at a larger volume",
 		})
+		fail(err)
+		return
+	}
+	auto := fileCfg.ScratchLocation == config.ScratchAuto
+	switch primary := scratchPrimary(fileCfg); {
+	case chunk.WorkDirExists(chunk.GetWorkDirPath(inputPath, tempDir)):
+		rep.Verbose(fmt.Sprintf("Reusing work directory in %s", tempDir))
+	case auto && tempDir == config.MemoryScratchDir:
+		rep.Verbose(fmt.Sprintf("Work files fit in memory (need about %s); using %s",
+			util.FormatBytes(scratch), tempDir))
+	case auto && tempDir == primary:
+		rep.Verbose(fmt.Sprintf("Work files too large for memory (need about %s, %s usable in %s); using %s",
+			util.FormatBytes(scratch), util.FormatBytes(memoryScratchAvailable()), config.MemoryScratchDir, tempDir))
+	case tempDir != primary:
+		rep.Warning(fmt.Sprintf("Not enough space in %s (need about %s); using %s for work files",
+			primary, util.FormatBytes(scratch), tempDir))
+	}
+	if tempDir != fileCfg.GetTempDir() {
+		adjusted := *fileCfg
+		adjusted.TempDir = tempDir
+		fileCfg = &adjusted
 	}
 
-	return results, nil
+	// Analyze the next file while this one encodes
+	if cfg.Prefetch && cfg.ParallelFiles <= 1 && cfg.GrowingQuietSecs == 0 && fileIdx+1 < len(b.files) && !b.atFileLimit() {
+		nextInput := b.files[fileIdx+1]
+		if !util.FileExists(b.outputs.path(nextInput)) {
+			b.next = startPrefetch(ctx, cfg, nextInput)
+		}
+	}
+
+	// Encode from a local copy of a source on network storage, so chunk
+	// decoding doesn't seek over the network
+	encodeInput, encodeOutput := inputPath, outputPath
+	var staged *stagedFile
+	if cfg.StageLocal {
+		if staged, err = stageInput(ctx, cfg, inputPath, outputPath, rep); err != nil {
+			if ctx.Err() != nil {
+				err = cancelled(err)
+			}
+			rep.Error(reporter.ReporterError{
+				Title:      "Staging Error",
+				Message:    fmt.Sprintf("Cannot stage %s: %v", inputFilename, err),
+				Context:    fmt.Sprintf("File: %s", inputPath),
+				Suggestion: "Free up space or use --temp-dir to point at a larger local volume",
+			})
+			fail(err)
+			return
+		}
+		encodeInput, encodeOutput = staged.input, staged.output
+	}
+
+	// Run chunked encoding with FFMS2 + SvtAv1EncApp
+	chunked, encodeError := ProcessChunked(ctx, fileCfg, encodeInput, encodeOutput, videoProps, audioStreams, audioSettings, pre.detectedCrop(fileCfg), quality, bitDepth, rep)
+	encodeSuccess := encodeError == nil
+
+	var sizeErr *SizeAbortError
+	if errors.As(encodeError, &sizeErr) {
+		rep.Warning(fmt.Sprintf("Stopped encoding %s: %v. Keeping the source as is.", inputFilename, sizeErr))
+		if staged != nil {
+			_ = staged.cleanup()
+		}
+		fail(encodeError)
+		return
+	}
+	if errors.Is(encodeError, ErrDeferred) {
+		rep.Warning(fmt.Sprintf("Deadline reached; %s deferred to the next run", inputFilename))
+		fail(encodeError)
+		return
+	}
+
+	if !encodeSuccess {
+		if ctx.Err() != nil {
+			encodeError = cancelled(encodeError)
+		}
+		rep.Error(reporter.ReporterError{
+			Title:      "Encoding Error",
+			Message:    fmt.Sprintf("Failed to encode %s: %v", inputFilename, encodeError),
+			Context:    fmt.Sprintf("File: %s", inputPath),
+			Suggestion: "Check logs for more details",
+		})
+		fail(encodeError)
+		return
+	}
+
+	if staged != nil {
+		if err := staged.moveOutput(ctx, outputPath, rep); err != nil {
+			if ctx.Err() != nil {
+				err = cancelled(err)
+			}
+			rep.Error(reporter.ReporterError{
+				Title:      "Output Error",
+				Message:    fmt.Sprintf("Failed to move %s into place: %v", util.GetFilename(outputPath), err),
+				Context:    fmt.Sprintf("Encoded file: %s", staged.output),
+				Suggestion: "Check that the output directory is writable and has enough space",
+			})
+			fail(err)
+			return
+		}
+		if err := staged.cleanup(); err != nil {
+			rep.Warning(fmt.Sprintf("Failed to remove staged copy %s: %v", staged.dir, err))
+		}
+	}
+
+	if err := cfg.OutputPerms.ApplyFile(outputPath); err != nil {
+		rep.Warning(fmt.Sprintf("Failed to set permissions on %s: %v", util.GetFilename(outputPath), err))
+	}
+	if cfg.PreserveTimes {
+		if err := util.CopyTimes(inputPath, outputPath, cfg.PreserveAccessTime); err != nil {
+			rep.Warning(fmt.Sprintf("Failed to copy timestamps to %s: %v", util.GetFilename(outputPath), err))
+		}
+	}
+
+	// Analysis saw a source that was still growing; validate against the
+	// finished one
+	if fileCfg.GrowingQuietSecs > 0 {
+		if props, err := ffprobe.GetVideoStreamProperties(cfg.Tools, inputPath, videoStream(fileCfg)); err == nil {
+			videoProps = props
+		} else {
+			rep.Warning(fmt.Sprintf("Could not analyze the finished %s: %v", inputFilename, err))
+		}
+	}
+
+	fileElapsedTime := time.Since(fileStartTime)
+
+	outputSize, _ := util.GetFileSize(outputPath)
+	encodingSpeed := float32(videoProps.DurationSecs) / float32(fileElapsedTime.Seconds())
+
+	// Calculate expected dimensions after crop
+	expectedWidth, expectedHeight := GetOutputDimensions(videoProps.Width, videoProps.Height, chunked.Crop.CropFilter)
+
+	// Validate output; a slowed-down encode runs longer than the source
+	expectedDims := &[2]uint32{expectedWidth, expectedHeight}
+	expectedDuration := videoProps.DurationSecs * chunked.TimeScale
+	// Nothing was copied if the audio had to be time-stretched
+	audioSettings.Tempo = 1 / chunked.TimeScale
+	expectedAudioTracks := len(audioChannels)
+	if audioSettings.Downmix != nil {
+		expectedAudioTracks++
+	}
+	expectedAttachments := expectedAttachmentCount(inputPath, outputPath, fileCfg)
+
+	validationResult, err := validation.ValidateOutputVideo(inputPath, outputPath, validation.Options{
+		ExpectedDimensions:  expectedDims,
+		ExpectedDuration:    &expectedDuration,
+		ExpectedHDR:         &isHDR,
+		SourceHDR:           &hdrInfo,
+		ExpectedBitDepth:    bitDepth,
+		ExpectedAudioTracks: &expectedAudioTracks,
+		AudioPassthrough:    fileCfg.AudioPassthrough,
+		PreservedCodecs:     PreservedCodecs(audioStreams, audioSettings),
+		SourceAudio:         chunk.MuxedAudioStreams(outputPath, audioStreams),
+		ExpectedAttachments: expectedAttachments,
+	})
+
+	var validationPassed bool
+	var validationSteps []validation.ValidationStep
+	if err != nil {
+		validationPassed = false
+		validationSteps = []validation.ValidationStep{validation.ProbeFailureStep(err)}
+	} else {
+		validationPassed = validationResult.IsValid()
+		validationSteps = validationResult.GetValidationSteps()
+	}
+
+	// Checksum the output; the sidecar is written before copies are made so
+	// --also-copy-to picks it up
+	var checksum string
+	if cfg.Checksum != config.ChecksumNone {
+		checksum, err = writeChecksum(ctx, outputPath, cfg, rep)
+		if err != nil {
+			rep.Warning(fmt.Sprintf("Failed to checksum %s: %v", util.GetFilename(outputPath), err))
+		}
+	}
+
+	// Write the results report first so --also-copy-to picks it up as a sidecar
+	if fileCfg.WriteReport {
+		report := Report{
+			Input:        inputPath,
+			Output:       outputPath,
+			InputSize:    inputSize,
+			OutputSize:   outputSize,
+			DurationSecs: videoProps.DurationSecs,
+			EncodeSecs:   fileElapsedTime.Seconds(),
+			CRF:          encodeParams.Quality,
+			Preset:       encodeParams.Preset,
+			Content:      content,
+			Crop:         newReportCrop(chunked.Crop),
+			Chunks:       chunked.Chunks,
+			Memory:       chunked.Memory,
+			Validation:   ReportValidation{Passed: validationPassed, Steps: validationSteps},
+			Determinism:  chunked.Determinism,
+		}
+		if checksum != "" {
+			report.Checksum = &ReportChecksum{Algorithm: cfg.Checksum, Digest: checksum}
+		}
+		if err := WriteReport(outputPath, report); err != nil {
+			rep.Warning(fmt.Sprintf("Failed to write report: %v", err))
+		} else if err := cfg.OutputPerms.ApplyFile(ReportPath(outputPath)); err != nil {
+			rep.Warning(fmt.Sprintf("Failed to set permissions on report: %v", err))
+		}
+	}
+
+	// Copy validated output to any additional destinations
+	var copies []CopyResult
+	if len(cfg.CopyDestinations) > 0 {
+		if validationPassed {
+			copies = CopyToDestinations(outputPath, cfg.CopyDestinations)
+			for _, c := range copies {
+				if c.Err != nil {
+					rep.Warning(fmt.Sprintf("Failed to copy %s to %s: %v", util.GetFilename(outputPath), c.Destination, c.Err))
+				} else {
+					rep.Verbose(fmt.Sprintf("Copied %s to %s", util.GetFilename(outputPath), c.Destination))
+				}
+			}
+		} else {
+			rep.Warning("Validation failed; not copying output to additional destinations")
+		}
+	}
+
+	// Give duplicate inputs the same output, once it has passed validation
+	if dups := len(b.duplicates[inputPath]); dups > 0 && cfg.DuplicatePolicy != config.DuplicatesSkip {
+		if validationPassed {
+			b.placeDuplicates(rep, inputPath, outputPath, checksum)
+		} else {
+			rep.Warning(fmt.Sprintf("Validation failed; not placing output for %d duplicates of %s", dups, inputFilename))
+		}
+	}
+
+	b.addResult(rep, inputPath, EncodeResult{
+		Filename:          inputFilename,
+		OutputPath:        outputPath,
+		Duration:          fileElapsedTime,
+		InputSize:         inputSize,
+		OutputSize:        outputSize,
+		VideoDurationSecs: videoProps.DurationSecs,
+		EncodingSpeed:     encodingSpeed,
+		ValidationPassed:  validationPassed,
+		ValidationSteps:   validationSteps,
+		Copies:            copies,
+		Checksum:          checksum,
+	})
+
+	// Emit validation complete
+	var repSteps []reporter.ValidationStep
+	for _, s := range validationSteps {
+		repSteps = append(repSteps, reporter.ValidationStep{
+			Name:    s.Name,
+			Check:   s.Check,
+			Code:    s.Code,
+			Passed:  s.Passed,
+			Details: s.Details,
+			Params:  s.Params,
+		})
+	}
+	rep.ValidationComplete(reporter.ValidationSummary{
+		Passed: validationPassed,
+		Steps:  repSteps,
+	})
+	if cfg.FailFast && !validationPassed {
+		b.stopAfter(rep, inputFilename)
+	}
+
+	// Emit encoding complete
+	audioResult := GenerateAudioResultsDescription(audioChannels, audioStreams, audioSettings)
+	if audioSettings.Downmix != nil {
+		audioResult += " + " + strings.ToLower(audioSettings.Downmix.Title())
+	}
+	rep.EncodingComplete(reporter.EncodingOutcome{
+		InputFile:    inputFilename,
+		OutputFile:   util.GetFilename(outputPath),
+		OriginalSize: inputSize,
+		EncodedSize:  outputSize,
+		VideoStream:  fmt.Sprintf("AV1 (libsvtav1), %dx%d", expectedWidth, expectedHeight),
+		AudioStream:  audioResult,
+		TotalTime:    fileElapsedTime,
+		AverageSpeed: encodingSpeed,
+		OutputPath:   outputPath,
+		Copies:       copyOutcomes(copies),
+	})
+
+	// Cooldown between encodes
+	if b.stopped() == nil && !b.atFileLimit() && len(b.files) > 1 && fileIdx < len(b.files)-1 && cfg.EncodeCooldownSecs > 0 {
+		time.Sleep(time.Duration(cfg.EncodeCooldownSecs) * time.Second)
+	}
+}
+
+// placeDuplicates gives the duplicates of inputPath its output, leaving
+// outputs that are already in place.
+func (b *batch) placeDuplicates(rep reporter.Reporter, inputPath, outputPath, checksum string) {
+	cfg := b.cfg
+	for _, dup := range b.duplicates[inputPath] {
+		dupOutput := b.outputs.path(dup)
+		if dupOutput == outputPath || util.FileExists(dupOutput) {
+			continue
+		}
+		err := placeDuplicateOutput(outputPath, dupOutput, cfg.DuplicatePolicy)
+		if err == nil {
+			err = cfg.OutputPerms.ApplyFile(dupOutput)
+		}
+		if err == nil && cfg.PreserveTimes {
+			err = util.CopyTimes(dup, dupOutput, cfg.PreserveAccessTime)
+		}
+		if err == nil && checksum != "" {
+			err = util.WriteChecksumFile(dupOutput, cfg.Checksum, checksum)
+		}
+		if err != nil {
+			rep.Warning(fmt.Sprintf("Failed to place output for duplicate %s: %v", util.GetFilename(dup), err))
+		} else {
+			rep.Verbose(fmt.Sprintf("Placed %s for duplicate %s", dupOutput, util.GetFilename(dup)))
+		}
+	}
+}
+
+// failedFiles lists the failures that aren't skipped for lack of video or
+// deferred to the next run, which the summary lists separately.
+func failedFiles(failures []*FileError) []reporter.FailedFile {
+	var failed []reporter.FailedFile
+	for _, f := range failures {
+		if errors.Is(f, ffprobe.ErrNoVideo) || errors.Is(f, ErrDeferred) {
+			continue
+		}
+		failed = append(failed, reporter.FailedFile{Filename: util.GetFilename(f.Input), Reason: f.Err.Error()})
+	}
+	return failed
+}
+
+// deferredFiles lists the inputs the deadline or file limit left for a
+// later run.
+func deferredFiles(failures []*FileError) []string {
+	var deferred []string
+	for _, f := range failures {
+		if errors.Is(f, ErrDeferred) {
+			deferred = append(deferred, util.GetFilename(f.Input))
+		}
+	}
+	return deferred
+}
+
+// hardwareSummary describes the host and encoder tools, including NUMA
+// pinning on machines with more than one node.
+func hardwareSummary(cfg *config.Config) reporter.HardwareSummary {
+	sysInfo := util.GetSystemInfo()
+	summary := reporter.HardwareSummary{
+		Hostname:        sysInfo.Hostname,
+		CPUModel:        sysInfo.CPUModel,
+		PhysicalCores:   util.PhysicalCores(),
+		LogicalCores:    sysInfo.NumCPU,
+		TotalMemory:     util.TotalMemoryBytes(),
+		AvailableMemory: util.AvailableMemoryBytes(),
+		OS:              fmt.Sprintf("%s (%s)", sysInfo.OSName, sysInfo.Arch),
+		SvtVersion:      toolVersion(cfg.Tools, tools.SvtVersion),
+		FFmpegVersion:   toolVersion(cfg.Tools, tools.FFmpegVersion),
+		NUMANodes:       len(util.NUMANodes()),
+	}
+	switch {
+	case summary.NUMANodes < 2:
+	case cfg.NUMA == config.NUMAOff:
+		summary.NUMA = fmt.Sprintf("%d nodes, pinning off", summary.NUMANodes)
+	default:
+		if p := numaPinning(cfg); p != nil {
+			summary.NUMA = p.String()
+		}
+	}
+	return summary
+}
+
+// toolVersion returns the version reported by detect, or "not found".
+func toolVersion(paths toolpath.Paths, detect func(toolpath.Paths) (tools.Version, error)) string {
+	v, err := detect(paths)
+	if err != nil {
+		return "not found"
+	}
+	return v.String()
 }
 
 // determineQualitySettings returns the CRF quality setting based on video resolution.
@@ -322,27 +1097,31 @@ func formatDynamicRange(isHDR bool) string {
 }
 
 func formatQualityDescription(width uint32, crf uint32) string {
-	var tier string
+	return fmt.Sprintf("CRF %d (%s)", crf, qualityTier(width))
+}
+
+// qualityTier returns the resolution tier ("SD", "HD" or "UHD") that selects the CRF.
+func qualityTier(width uint32) string {
 	if width >= config.UHDWidthThreshold {
-		tier = "UHD"
+		return "UHD"
 	} else if width >= config.HDWidthThreshold {
-		tier = "HD"
-	} else {
-		tier = "SD"
+		return "HD"
 	}
-	return fmt.Sprintf("CRF %d (%s)", crf, tier)
+	return "SD"
 }
 
 func setupEncodeParams(
 	cfg *config.Config,
+	width uint32,
 	quality uint32,
+	bitDepth uint8,
 	hdrInfo mediainfo.HDRInfo,
 ) *ffmpeg.EncodeParams {
 	params := &ffmpeg.EncodeParams{
 		Quality:     quality,
-		Preset:      cfg.SVTAV1Preset,
+		Preset:      cfg.PresetForWidth(width),
 		Tune:        cfg.SVTAV1Tune,
-		PixelFormat: "yuv420p10le",
+		PixelFormat: pixelFormat(bitDepth),
 	}
 
 	// Set matrix coefficients based on HDR
@@ -357,3 +1136,73 @@ func setupEncodeParams(
 
 	return params
 }
+
+// applyEncoderCapabilities verifies encoder tool versions and returns a copy of
+// cfg with any SVT-AV1 parameters the installed build does not support disabled.
+func applyEncoderCapabilities(cfg *config.Config, rep reporter.Reporter) (*config.Config, error) {
+	check, err := tools.CheckEncoders(cfg.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	rep.Verbose(fmt.Sprintf("SvtAv1EncApp %s, ffmpeg %s", check.Svt, check.FFmpeg))
+
+	adjusted := *cfg
+	if !check.Features.ACBias && adjusted.SVTAV1ACBias != 0 {
+		rep.Warning(fmt.Sprintf("SvtAv1EncApp %s does not support --ac-bias; ignoring it", check.Svt))
+		adjusted.SVTAV1ACBias = 0
+	}
+	if !check.Features.VarianceBoost && adjusted.SVTAV1EnableVarianceBoost {
+		rep.Warning(fmt.Sprintf("SvtAv1EncApp %s does not support variance boost; disabling it", check.Svt))
+		adjusted.SVTAV1EnableVarianceBoost = false
+	}
+	return &adjusted, nil
+}
+
+// writeChecksum computes the digest of outputPath and writes it to the
+// output's checksum sidecar.
+func writeChecksum(ctx context.Context, outputPath string, cfg *config.Config, rep reporter.Reporter) (string, error) {
+	progress := newTaskProgress(rep, "Checksum", "bytes")
+	digest, err := util.FileChecksum(ctx, outputPath, cfg.Checksum, progress.update)
+	if err != nil {
+		return "", err
+	}
+	progress.finish()
+	if err := util.WriteChecksumFile(outputPath, cfg.Checksum, digest); err != nil {
+		return "", err
+	}
+	if err := cfg.OutputPerms.ApplyFile(util.ChecksumPath(outputPath, cfg.Checksum)); err != nil {
+		rep.Warning(fmt.Sprintf("Failed to set permissions on checksum file: %v", err))
+	}
+	rep.Verbose(fmt.Sprintf("%s %s", cfg.Checksum, digest))
+	return digest, nil
+}
+
+// formatContent describes the content type and whether it was detected.
+func formatContent(content string, detected bool) string {
+	if detected {
+		return content + " (detected)"
+	}
+	return content
+}
+
+// expectedAttachmentCount returns how many attachments the output should
+// have: none when they are dropped or its container can't hold them,
+// otherwise as many as the source, plus the attached encode settings.
+// Returns nil when the source can't be probed.
+func expectedAttachmentCount(inputPath, outputPath string, cfg *config.Config) *int {
+	count := 0
+	if !chunk.HoldsAttachments(outputPath) {
+		return &count
+	}
+	if !cfg.DropAttachments {
+		var err error
+		if count, err = ffprobe.CountAttachments(cfg.Tools, inputPath); err != nil {
+			return nil
+		}
+	}
+	if cfg.AttachSettings {
+		count++
+	}
+	return &count
+}
@@ -0,0 +1,92 @@
+package processing
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/validation"
+)
+
+// ffmpegOrSkip runs ffmpeg with args, skipping the test when ffmpeg and
+// ffprobe aren't installed or the build lacks what args need.
+func ffmpegOrSkip(t *testing.T, args ...string) {
+	t.Helper()
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skip(tool + " not installed")
+		}
+	}
+	args = append([]string{"-hide_banner", "-loglevel", "error", "-y"}, args...)
+	if output, err := exec.Command("ffmpeg", args...).CombinedOutput(); err != nil {
+		t.Skipf("ffmpeg %v: %v\n%s", args, err, output)
+	}
+}
+
+func TestMP4OutputPassesValidation(t *testing.T) {
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	if err := os.Mkdir(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	font := filepath.Join(dir, "font.ttf")
+	if err := os.WriteFile(font, []byte("not really a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A source with a font attachment and no default audio track
+	source := filepath.Join(dir, "source.mkv")
+	ffmpegOrSkip(t,
+		"-f", "lavfi", "-i", "testsrc=duration=1:size=64x64:rate=10",
+		"-f", "lavfi", "-i", "sine=duration=1",
+		"-attach", font, "-metadata:s:t", "mimetype=application/x-truetype-font",
+		"-c:v", "ffv1", "-c:a", "aac", "-disposition:a:0", "0", source)
+	ffmpegOrSkip(t, "-f", "lavfi", "-i", "testsrc=duration=1:size=64x64:rate=10",
+		"-c:v", "libaom-av1", "-cpu-used", "8", chunk.GetVideoPath(workDir))
+	ffmpegOrSkip(t, "-i", source, "-map", "0:a", "-c", "copy", chunk.GetAudioStreamPath(workDir, 0))
+
+	audioStreams, err := ffprobe.GetAudioStreamInfo(nil, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "movie.mp4")
+	if err := chunk.MuxFinal(nil, source, workDir, output, audioStreams, nil, nil, true, nil, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewConfig(dir, dir, dir)
+	result, err := validation.ValidateOutputVideo(source, output, validation.Options{
+		SourceAudio:         chunk.MuxedAudioStreams(output, audioStreams),
+		ExpectedAttachments: expectedAttachmentCount(source, output, cfg),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsAudioMetadataPreserved {
+		t.Errorf("audio metadata mismatches: %v", result.AudioMetadataMismatches)
+	}
+	if !result.IsAttachmentCountCorrect {
+		t.Errorf("attachments = %v, expected %v", *result.AttachmentCount, *result.ExpectedAttachments)
+	}
+}
+
+func TestExpectedAttachmentCountWithoutAttachments(t *testing.T) {
+	cfg := config.NewConfig("/input", "/output", "/log")
+	cfg.AttachSettings = true
+
+	// MP4 and MOV can't hold attachments, not even the encode settings
+	for _, output := range []string{"/output/movie.mp4", "/output/movie.mov"} {
+		if got := expectedAttachmentCount("/input/movie.mkv", output, cfg); got == nil || *got != 0 {
+			t.Errorf("expectedAttachmentCount(%s) = %v, want 0", output, got)
+		}
+	}
+
+	cfg.DropAttachments = true
+	if got := expectedAttachmentCount("/input/movie.mkv", "/output/movie.mkv", cfg); got == nil || *got != 1 {
+		t.Errorf("expectedAttachmentCount(mkv) = %v, want 1 for the settings", got)
+	}
+}
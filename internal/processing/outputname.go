@@ -0,0 +1,82 @@
+package processing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/util"
+)
+
+// outputNamer resolves the output path of each input of a batch: its
+// override, the output template, or the source name with .mkv. A path is
+// resolved once, so probing for template values and the {date} token can't
+// make it change partway through the batch.
+type outputNamer struct {
+	cfg       *config.Config
+	overrides map[string]string
+	date      time.Time
+
+	mu    sync.Mutex
+	paths map[string]string
+}
+
+func newOutputNamer(cfg *config.Config, overrides map[string]string) *outputNamer {
+	return &outputNamer{cfg: cfg, overrides: overrides, date: time.Now(), paths: make(map[string]string)}
+}
+
+// path returns the output path for inputPath.
+func (n *outputNamer) path(inputPath string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if p, ok := n.paths[inputPath]; ok {
+		return p
+	}
+
+	override, template := n.overrides[inputPath], n.cfg.OutputTemplate
+	fields := util.OutputFields{Date: n.date}
+	if override == "" && util.OutputTemplateNeedsVideo(template) {
+		fields.Resolution, fields.CRF = n.videoFields(inputPath)
+	}
+	p := util.ResolveOutputPath(inputPath, n.cfg.OutputDir, override, template, fields)
+	n.paths[inputPath] = p
+	return p
+}
+
+// videoFields probes inputPath for the {resolution} and {crf} template
+// values, applying its sidecar as the encode will. A source that can't be
+// probed gets an empty resolution; its encode reports the problem.
+func (n *outputNamer) videoFields(inputPath string) (string, uint8) {
+	cfg := n.cfg
+	if sidecar, err := config.LoadSidecar(config.SidecarPath(inputPath)); err == nil && sidecar != nil {
+		adjusted := *cfg
+		if adjusted.ApplySidecar(sidecar) == nil {
+			cfg = &adjusted
+		}
+	}
+	props, err := ffprobe.GetVideoStreamProperties(cfg.Tools, inputPath, videoStream(cfg))
+	if err != nil {
+		return "", cfg.CRFSD
+	}
+	return util.ResolutionLabel(props.Width, props.Height), cfg.CRFForWidth(props.Width)
+}
+
+// checkOutputCollisions returns an error naming the first two inputs that
+// resolve to the same output path, as inputs listed from different
+// directories with the same name do.
+func checkOutputCollisions(inputs []string, n *outputNamer) error {
+	if len(inputs) < 2 {
+		return nil
+	}
+	seen := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		output := n.path(input)
+		if other, ok := seen[output]; ok {
+			return fmt.Errorf("%s and %s would both be written to %s", other, input, output)
+		}
+		seen[output] = input
+	}
+	return nil
+}
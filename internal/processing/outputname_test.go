@@ -0,0 +1,37 @@
+package processing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+)
+
+func TestCheckOutputCollisions(t *testing.T) {
+	cfg := config.NewConfig("/in", "/out", "/log")
+
+	tests := []struct {
+		name      string
+		inputs    []string
+		overrides map[string]string
+		wantErr   bool
+	}{
+		{"distinct names", []string{"/a/one.mkv", "/b/two.mkv"}, nil, false},
+		{"same name in two directories", []string{"/a/movie.mkv", "/b/movie.mkv"}, nil, true},
+		{"same stem, different extension", []string{"/a/movie.mkv", "/a/movie.mp4"}, nil, true},
+		{"override separates them", []string{"/a/movie.mkv", "/b/movie.mkv"}, map[string]string{"/b/movie.mkv": "movie (b).mkv"}, false},
+		{"single input", []string{"/a/movie.mkv"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOutputCollisions(tt.inputs, newOutputNamer(cfg, tt.overrides))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkOutputCollisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (!strings.Contains(err.Error(), tt.inputs[0]) || !strings.Contains(err.Error(), tt.inputs[1])) {
+				t.Errorf("error %q doesn't name both inputs", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package processing
+
+import "math"
+
+// Film rate that PAL speedup correction restores 25fps sources to.
+const (
+	filmFPSNum = 24000
+	filmFPSDen = 1001
+)
+
+// palTimeScale is how much longer a 25fps source runs at the film rate (about 4.3%).
+const palTimeScale = 25.0 * filmFPSDen / filmFPSNum
+
+// isPALRate reports whether a frame rate is 25fps.
+func isPALRate(num, den uint32) bool {
+	if den == 0 {
+		return false
+	}
+	return math.Abs(float64(num)/float64(den)-25) < 0.01
+}
@@ -0,0 +1,34 @@
+package processing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsPALRate(t *testing.T) {
+	tests := []struct {
+		num, den uint32
+		want     bool
+	}{
+		{25, 1, true},
+		{25000, 1000, true},
+		{24000, 1001, false},
+		{30000, 1001, false},
+		{50, 1, false},
+		{25, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := isPALRate(tt.num, tt.den); got != tt.want {
+			t.Errorf("isPALRate(%d, %d) = %v, want %v", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestPALTimeScale(t *testing.T) {
+	// An hour at 25fps is 90000 frames, which run 62.5625 minutes at 23.976fps
+	got := 3600 * palTimeScale
+	if want := 90000.0 * filmFPSDen / filmFPSNum; math.Abs(got-want) > 1e-9 {
+		t.Errorf("slowed duration = %v, want %v", got, want)
+	}
+}
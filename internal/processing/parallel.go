@@ -0,0 +1,261 @@
+package processing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// runParallel encodes the batch's files cfg.ParallelFiles at a time, in input
+// order. Each file's reports are serialized and their encoding progress is
+// merged into one.
+func (b *batch) runParallel(ctx context.Context, rep reporter.Reporter) {
+	shared := newParallelReporter(rep)
+	files := make(chan int)
+	var wg sync.WaitGroup
+	for range b.cfg.ParallelFiles {
+		wg.Go(func() {
+			for fileIdx := range files {
+				inputPath := b.files[fileIdx]
+				fileRep := shared.file(util.GetFilename(inputPath))
+				b.encodeFile(ctx, fileIdx, inputPath, fileRep)
+				fileRep.done()
+			}
+		})
+	}
+	for fileIdx := range b.files {
+		files <- fileIdx
+	}
+	close(files)
+	wg.Wait()
+}
+
+// parallelReporter forwards reports from files encoding at the same time to
+// one reporter. Calls are serialized, messages are prefixed with the file
+// name, and encoding progress covers all files that are encoding.
+type parallelReporter struct {
+	mu       sync.Mutex
+	rep      reporter.Reporter
+	progress map[*fileReporter]reporter.ProgressSnapshot
+	stale    bool // The set of encoding files changed since the last start
+}
+
+func newParallelReporter(rep reporter.Reporter) *parallelReporter {
+	return &parallelReporter{rep: rep, progress: make(map[*fileReporter]reporter.ProgressSnapshot)}
+}
+
+// file returns the reporter for one file of the batch.
+func (p *parallelReporter) file(name string) *fileReporter {
+	return &fileReporter{p: p, name: name}
+}
+
+// merged combines the progress of the encoding files. A single file's
+// progress is passed through as is.
+func (p *parallelReporter) merged() reporter.ProgressSnapshot {
+	if len(p.progress) == 1 {
+		for _, s := range p.progress {
+			return s
+		}
+	}
+	var m reporter.ProgressSnapshot
+	for _, s := range p.progress {
+		m.CurrentFrame += s.CurrentFrame
+		m.TotalFrames += s.TotalFrames
+		m.ChunksComplete += s.ChunksComplete
+		m.ChunksTotal += s.ChunksTotal
+		m.Speed += s.Speed
+		m.FPS += s.FPS
+		m.ETA = max(m.ETA, s.ETA) // The file finishing last
+	}
+	if m.TotalFrames > 0 {
+		m.Percent = float32(float64(m.CurrentFrame) / float64(m.TotalFrames) * 100)
+	}
+	return m
+}
+
+// restart reports a new encode covering the files that are encoding, so
+// progress displays start over with the combined totals.
+func (p *parallelReporter) restart() {
+	m := p.merged()
+	reporter.ReportEncodingStarted(p.rep, reporter.EncodingStart{
+		TotalFrames:   m.TotalFrames,
+		TotalChunks:   m.ChunksTotal,
+		ResumedFrames: m.CurrentFrame,
+	})
+	p.stale = false
+}
+
+// remove drops f from the merged progress.
+func (p *parallelReporter) remove(f *fileReporter) {
+	if _, ok := p.progress[f]; ok {
+		delete(p.progress, f)
+		p.stale = len(p.progress) > 0
+	}
+}
+
+// fileReporter is one file's view of a parallelReporter.
+type fileReporter struct {
+	p    *parallelReporter
+	name string
+}
+
+// done removes the file from the merged progress once it has finished or failed.
+func (f *fileReporter) done() {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.remove(f)
+}
+
+func (f *fileReporter) prefix(message string) string {
+	return f.name + ": " + message
+}
+
+func (f *fileReporter) Hardware(summary reporter.HardwareSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.Hardware(summary)
+}
+
+func (f *fileReporter) Initialization(summary reporter.InitializationSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.Initialization(summary)
+}
+
+func (f *fileReporter) StageProgress(update reporter.StageProgress) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	update.Message = f.prefix(update.Message)
+	f.p.rep.StageProgress(update)
+}
+
+func (f *fileReporter) TaskProgress(progress reporter.TaskProgress) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	progress.Task = f.prefix(progress.Task)
+	reporter.ReportTaskProgress(f.p.rep, progress)
+}
+
+func (f *fileReporter) CropResult(summary reporter.CropSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.CropResult(summary)
+}
+
+func (f *fileReporter) EncodingConfig(summary reporter.EncodingConfigSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.EncodingConfig(summary)
+}
+
+func (f *fileReporter) EncodeEstimate(estimate reporter.EncodeEstimate) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	reporter.ReportEncodeEstimate(f.p.rep, estimate)
+}
+
+func (f *fileReporter) EncodingStarted(totalFrames uint64) {
+	f.EncodingStartedWith(reporter.EncodingStart{TotalFrames: totalFrames})
+}
+
+func (f *fileReporter) EncodingStartedWith(start reporter.EncodingStart) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.progress[f] = reporter.ProgressSnapshot{
+		CurrentFrame:   start.ResumedFrames,
+		TotalFrames:    start.TotalFrames,
+		Percent:        start.ResumedPercent(),
+		ChunksComplete: start.ResumedChunks,
+		ChunksTotal:    start.TotalChunks,
+	}
+	if len(f.p.progress) == 1 {
+		reporter.ReportEncodingStarted(f.p.rep, start)
+		f.p.stale = false
+		return
+	}
+	f.p.restart()
+}
+
+func (f *fileReporter) EncodingProgress(progress reporter.ProgressSnapshot) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	if _, ok := f.p.progress[f]; !ok {
+		return
+	}
+	f.p.progress[f] = progress
+	if f.p.stale {
+		f.p.restart()
+	}
+	f.p.rep.EncodingProgress(f.p.merged())
+}
+
+func (f *fileReporter) ChunkRetry(retry reporter.ChunkRetry) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	retry.Reason = f.prefix(retry.Reason)
+	reporter.ReportChunkRetry(f.p.rep, retry)
+}
+
+func (f *fileReporter) WorkerCap(workers reporter.WorkerCap) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	reporter.ReportWorkerCap(f.p.rep, workers)
+}
+
+func (f *fileReporter) ValidationComplete(summary reporter.ValidationSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	// Validation ends this file's progress display
+	f.p.remove(f)
+	f.p.rep.ValidationComplete(summary)
+}
+
+func (f *fileReporter) EncodingComplete(summary reporter.EncodingOutcome) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.EncodingComplete(summary)
+}
+
+func (f *fileReporter) Warning(message string) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.Warning(f.prefix(message))
+}
+
+func (f *fileReporter) Error(err reporter.ReporterError) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.Error(err)
+}
+
+func (f *fileReporter) OperationComplete(message string) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.OperationComplete(message)
+}
+
+func (f *fileReporter) BatchStarted(info reporter.BatchStartInfo) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.BatchStarted(info)
+}
+
+func (f *fileReporter) FileProgress(context reporter.FileProgressContext) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.FileProgress(context)
+}
+
+func (f *fileReporter) BatchComplete(summary reporter.BatchSummary) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.BatchComplete(summary)
+}
+
+func (f *fileReporter) Verbose(message string) {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+	f.p.rep.Verbose(f.prefix(message))
+}
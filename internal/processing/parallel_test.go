@@ -0,0 +1,71 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/reporter"
+)
+
+// progressRecorder records encoding starts and progress.
+type progressRecorder struct {
+	reporter.NullReporter
+	starts   []reporter.EncodingStart
+	progress []reporter.ProgressSnapshot
+	warnings []string
+}
+
+func (r *progressRecorder) EncodingStartedWith(start reporter.EncodingStart) {
+	r.starts = append(r.starts, start)
+}
+
+func (r *progressRecorder) EncodingProgress(progress reporter.ProgressSnapshot) {
+	r.progress = append(r.progress, progress)
+}
+
+func (r *progressRecorder) Warning(message string) {
+	r.warnings = append(r.warnings, message)
+}
+
+func TestParallelReporterMergesProgress(t *testing.T) {
+	rec := &progressRecorder{}
+	shared := newParallelReporter(rec)
+	a, b := shared.file("a.mkv"), shared.file("b.mkv")
+
+	a.EncodingStartedWith(reporter.EncodingStart{TotalFrames: 100, TotalChunks: 4})
+	a.EncodingProgress(reporter.ProgressSnapshot{CurrentFrame: 50, TotalFrames: 100, Percent: 50, Speed: 1, ChunksTotal: 4})
+	if got := rec.progress[0]; got.Percent != 50 {
+		t.Errorf("single file progress = %.0f%%, want it passed through as 50%%", got.Percent)
+	}
+
+	b.EncodingStartedWith(reporter.EncodingStart{TotalFrames: 300, TotalChunks: 6})
+	if got := rec.starts[1]; got.TotalFrames != 400 || got.ResumedFrames != 50 || got.TotalChunks != 10 {
+		t.Errorf("merged start = %+v, want 400 frames, 50 done, 10 chunks", got)
+	}
+	b.EncodingProgress(reporter.ProgressSnapshot{CurrentFrame: 150, TotalFrames: 300, Speed: 2})
+	got := rec.progress[len(rec.progress)-1]
+	if got.CurrentFrame != 200 || got.Percent != 50 || got.Speed != 3 {
+		t.Errorf("merged progress = %+v, want 200/400 frames (50%%) at 3x", got)
+	}
+
+	// Once a finishes, the display restarts with b's totals
+	a.ValidationComplete(reporter.ValidationSummary{Passed: true})
+	b.EncodingProgress(reporter.ProgressSnapshot{CurrentFrame: 200, TotalFrames: 300, Percent: 66})
+	if len(rec.starts) != 3 || rec.starts[2].TotalFrames != 300 {
+		t.Errorf("starts after a finished = %+v, want a restart with 300 frames", rec.starts)
+	}
+
+	// Progress from a file that has finished is dropped
+	n := len(rec.progress)
+	a.EncodingProgress(reporter.ProgressSnapshot{CurrentFrame: 100, TotalFrames: 100})
+	if len(rec.progress) != n {
+		t.Error("progress from a finished file was reported")
+	}
+}
+
+func TestParallelReporterPrefixesMessages(t *testing.T) {
+	rec := &progressRecorder{}
+	newParallelReporter(rec).file("a.mkv").Warning("low space")
+	if len(rec.warnings) != 1 || rec.warnings[0] != "a.mkv: low space" {
+		t.Errorf("warnings = %q, want the file name prefixed", rec.warnings)
+	}
+}
@@ -0,0 +1,97 @@
+package processing
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// rclonePrefix marks a destination that is handed to rclone instead of
+// being treated as a local directory.
+const rclonePrefix = "rclone:"
+
+// CopyResult contains the outcome of copying an output to one destination.
+type CopyResult struct {
+	Destination string
+	Err         error
+}
+
+// CopyToDestinations copies the output file and its sidecar files to each
+// destination. Local destinations are directories (created if missing);
+// destinations prefixed with "rclone:" are passed to `rclone copy`, e.g.
+// "rclone:nas:backup/movies". Every destination is attempted even if an
+// earlier one fails.
+func CopyToDestinations(outputPath string, destinations []string) []CopyResult {
+	files := append([]string{outputPath}, util.SidecarFiles(outputPath)...)
+
+	results := make([]CopyResult, 0, len(destinations))
+	for _, dest := range destinations {
+		var err error
+		if remote, ok := strings.CutPrefix(dest, rclonePrefix); ok {
+			err = copyWithRclone(files, remote)
+		} else {
+			err = copyToDirectory(files, dest)
+		}
+		results = append(results, CopyResult{Destination: dest, Err: err})
+	}
+	return results
+}
+
+// copyToDirectory copies files into a local (or mounted network) directory.
+func copyToDirectory(files []string, dir string) error {
+	if err := util.EnsureDirectory(dir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, f := range files {
+		if err := util.CopyFile(f, filepath.Join(dir, filepath.Base(f))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyWithRclone uploads files to an rclone remote path.
+func copyWithRclone(files []string, remote string) error {
+	if _, err := exec.LookPath("rclone"); err != nil {
+		return fmt.Errorf("rclone not found in PATH")
+	}
+	for _, f := range files {
+		output, err := cmdlog.CombinedOutput(exec.Command("rclone", "copy", f, remote))
+		if err != nil {
+			return fmt.Errorf("rclone copy failed: %w\nOutput: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// copyOutcomes converts copy results for the reporter.
+func copyOutcomes(results []CopyResult) []reporter.CopyOutcome {
+	var outcomes []reporter.CopyOutcome
+	for _, r := range results {
+		outcome := reporter.CopyOutcome{Destination: r.Destination, Success: r.Err == nil}
+		if r.Err != nil {
+			outcome.Error = r.Err.Error()
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// validateDestinations checks that local destinations are not files.
+func validateDestinations(destinations []string) error {
+	for _, dest := range destinations {
+		if strings.HasPrefix(dest, rclonePrefix) {
+			continue
+		}
+		if info, err := os.Stat(dest); err == nil && !info.IsDir() {
+			return fmt.Errorf("copy destination is not a directory: %s", dest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package processing
+
+import (
+	"context"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/indexcache"
+	"github.com/five82/reel/internal/mediainfo"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+// prefetch is the analysis of the next file in a batch, run while the
+// current file encodes so the next encode starts without waiting for it.
+// Its methods fall back to probing directly on a nil prefetch.
+type prefetch struct {
+	done     chan struct{}
+	cancel   context.CancelFunc
+	complete bool // Probes finished; false if cancelled partway
+
+	props         *ffprobe.VideoProperties
+	propsErr      error
+	resolutionErr error
+	mediaInfo     *mediainfo.Response
+	mediaInfoErr  error
+	audioChannels []uint32
+	audioStreams  []ffprobe.AudioStreamInfo
+
+	// Set only when the file's settings call for detection
+	content      string
+	crop         *CropResult
+	cropSettings string
+}
+
+// startPrefetch analyzes inputPath in the background. The FFMS2 index goes
+// to the index cache, where the encode will find it. Call stop once the
+// prefetch is no longer needed.
+func startPrefetch(ctx context.Context, cfg *config.Config, inputPath string) *prefetch {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &prefetch{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(p.done)
+		p.run(ctx, cfg, inputPath)
+	}()
+	return p
+}
+
+func (p *prefetch) run(ctx context.Context, cfg *config.Config, inputPath string) {
+	// Sidecar errors are reported when the file is reached
+	fileCfg, err := withSidecar(cfg, inputPath)
+	if err != nil {
+		fileCfg = nil
+	}
+
+	probeCfg := fileCfg
+	if probeCfg == nil {
+		probeCfg = cfg
+	}
+	p.props, p.propsErr = ffprobe.GetVideoStreamProperties(cfg.Tools, inputPath, videoStream(probeCfg))
+	if p.propsErr == nil {
+		p.resolutionErr = checkResolution(cfg.Tools, inputPath, p.props)
+		p.mediaInfo, p.mediaInfoErr = mediainfo.GetMediaInfo(cfg.Tools, inputPath)
+		p.audioChannels = GetAudioChannels(cfg.Tools, inputPath)
+		p.audioStreams = GetAudioStreamInfo(cfg.Tools, inputPath)
+	}
+	p.complete = ctx.Err() == nil
+	if !p.complete || p.propsErr != nil || fileCfg == nil {
+		return
+	}
+
+	if fileCfg.ContentType == config.ContentAuto {
+		p.content = DetectContent(cfg.Tools, inputPath, p.props)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if fileCfg.CropFilter == "" && fileCfg.CropMode != "none" {
+		crop := DetectCrop(ctx, cfg.Tools, inputPath, p.props, false, fileCfg.CropConfidence, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		p.crop, p.cropSettings = &crop, cropSettings(fileCfg)
+	}
+	if ctx.Err() != nil || fileCfg.IndexCacheDir == "" || fileCfg.Deinterlace {
+		return
+	}
+
+	path, err := indexcache.Path(fileCfg.IndexCacheDir, inputPath)
+	if err != nil || util.FileExists(path) {
+		return
+	}
+	if idx, err := ffms.NewVidIdx(ctx, inputPath, nil); err == nil {
+		_ = writeIndex(idx, path, true, fileCfg.IndexCacheMaxMB)
+		idx.Close()
+	}
+}
+
+// stop cancels the prefetch and waits for it to return. It does nothing on
+// a nil prefetch, and is cheap once the prefetch has finished.
+func (p *prefetch) stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// wait blocks until the prefetch finishes. It returns nil if there is none,
+// it was cancelled partway, or ctx is cancelled first.
+func (p *prefetch) wait(ctx context.Context) *prefetch {
+	if p == nil {
+		return nil
+	}
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return nil
+	}
+	if !p.complete {
+		return nil
+	}
+	return p
+}
+
+func (p *prefetch) videoProperties(inputPath string, cfg *config.Config) (*ffprobe.VideoProperties, error) {
+	if p == nil {
+		return ffprobe.GetVideoStreamProperties(cfg.Tools, inputPath, videoStream(cfg))
+	}
+	return p.props, p.propsErr
+}
+
+func (p *prefetch) checkResolution(paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties) error {
+	if p == nil {
+		return checkResolution(paths, inputPath, props)
+	}
+	return p.resolutionErr
+}
+
+func (p *prefetch) mediaInfoData(paths toolpath.Paths, inputPath string) (*mediainfo.Response, error) {
+	if p == nil {
+		return mediainfo.GetMediaInfo(paths, inputPath)
+	}
+	return p.mediaInfo, p.mediaInfoErr
+}
+
+func (p *prefetch) audio(paths toolpath.Paths, inputPath string) ([]uint32, []ffprobe.AudioStreamInfo) {
+	if p == nil {
+		return GetAudioChannels(paths, inputPath), GetAudioStreamInfo(paths, inputPath)
+	}
+	return p.audioChannels, p.audioStreams
+}
+
+// videoStream returns the video stream cfg selects for ffprobe, -1 for the
+// primary one.
+func videoStream(cfg *config.Config) int {
+	if cfg.VideoStream == nil {
+		return -1
+	}
+	return *cfg.VideoStream
+}
+
+// detectedContent returns the prefetched content classification, or "".
+func (p *prefetch) detectedContent() string {
+	if p == nil {
+		return ""
+	}
+	return p.content
+}
+
+// detectedCrop returns the prefetched crop if it was detected with the same
+// settings as cfg, or nil.
+func (p *prefetch) detectedCrop(cfg *config.Config) *CropResult {
+	if p == nil || p.crop == nil || p.cropSettings != cropSettings(cfg) {
+		return nil
+	}
+	return p.crop
+}
+
+// withTools returns a copy of cfg with its tool paths resolved.
+func withTools(cfg *config.Config) *config.Config {
+	adjusted := *cfg
+	adjusted.Tools = toolpath.Resolve(cfg.ToolPaths)
+	return &adjusted
+}
+
+// withSidecar returns cfg with the per-title settings next to inputPath applied.
+func withSidecar(cfg *config.Config, inputPath string) (*config.Config, error) {
+	sidecar, err := config.LoadSidecar(config.SidecarPath(inputPath))
+	if err != nil || sidecar == nil {
+		return cfg, err
+	}
+	adjusted := *cfg
+	if err := adjusted.ApplySidecar(sidecar); err != nil {
+		return nil, err
+	}
+	return &adjusted, nil
+}
@@ -0,0 +1,63 @@
+package processing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+)
+
+func TestPrefetchDetectedCrop(t *testing.T) {
+	cfg := config.NewConfig("/input", "/output", "/log")
+	crop := &CropResult{CropFilter: "crop=1920:800:0:140", Required: true}
+	p := &prefetch{crop: crop, cropSettings: cropSettings(cfg)}
+
+	if got := p.detectedCrop(cfg); got != crop {
+		t.Errorf("detectedCrop() = %v, want the prefetched crop", got)
+	}
+	changed := *cfg
+	changed.CropConfidence = 0.6
+	if got := p.detectedCrop(&changed); got != nil {
+		t.Errorf("detectedCrop() with other crop settings = %v, want nil", got)
+	}
+	if got := (*prefetch)(nil).detectedCrop(cfg); got != nil {
+		t.Errorf("detectedCrop() on nil prefetch = %v, want nil", got)
+	}
+}
+
+func TestPrefetchWait(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	ctx := context.Background()
+
+	if got := (&prefetch{done: done, complete: true}).wait(ctx); got == nil {
+		t.Error("wait() on a finished prefetch = nil")
+	}
+	if got := (&prefetch{done: done}).wait(ctx); got != nil {
+		t.Error("wait() on a prefetch cancelled partway should be nil")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := (&prefetch{done: make(chan struct{}), complete: true}).wait(cancelled); got != nil {
+		t.Error("wait() with a cancelled context should be nil")
+	}
+}
+
+func TestPrefetchStop(t *testing.T) {
+	// stop cancels the prefetch's context and returns once it has finished
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &prefetch{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		<-ctx.Done()
+		close(p.done)
+	}()
+	p.stop()
+	select {
+	case <-p.done:
+	default:
+		t.Error("stop() returned before the prefetch finished")
+	}
+
+	(*prefetch)(nil).stop()
+}
@@ -0,0 +1,44 @@
+package processing
+
+import "time"
+
+// fpsWindow is how far back the live frame rate looks. Chunks finish in
+// bursts, so a shorter window would swing with every completion.
+const fpsWindow = time.Minute
+
+// rateSample is the frames complete at a point in time.
+type rateSample struct {
+	at     time.Time
+	frames int
+}
+
+// fpsMeter computes a live frame rate from chunk progress, over roughly
+// the last fpsWindow.
+type fpsMeter struct {
+	samples []rateSample
+}
+
+// newFPSMeter returns a meter for an encode that started at start with
+// frames already complete.
+func newFPSMeter(start time.Time, frames int) *fpsMeter {
+	return &fpsMeter{samples: []rateSample{{start, frames}}}
+}
+
+// add records frames complete at t and returns the frames per second since
+// the last sample at least fpsWindow old, or since the start if none is.
+func (m *fpsMeter) add(t time.Time, frames int) float64 {
+	m.samples = append(m.samples, rateSample{t, frames})
+	cut := t.Add(-fpsWindow)
+	i := 0
+	for i+1 < len(m.samples) && !m.samples[i+1].at.After(cut) {
+		i++
+	}
+	m.samples = m.samples[i:]
+
+	first := m.samples[0]
+	secs := t.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	return float64(frames-first.frames) / secs
+}
@@ -0,0 +1,31 @@
+package processing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFPSMeter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(secs int) time.Time { return start.Add(time.Duration(secs) * time.Second) }
+
+	m := newFPSMeter(start, 1000) // Resumed with 1000 frames done
+	tests := []struct {
+		secs   int
+		frames int
+		want   float64
+	}{
+		{0, 1000, 0},    // No time elapsed
+		{10, 1300, 30},  // Since the start
+		{50, 2200, 24},  // Still within a window of the start
+		{100, 2650, 15}, // From the 10s sample, the last a window old
+		{170, 3000, 5},  // From the 100s sample
+	}
+	for _, tt := range tests {
+		got := m.add(at(tt.secs), tt.frames)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("add(%ds, %d) = %.2f, want %v", tt.secs, tt.frames, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package processing
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/five82/reel/internal/encode"
+	"github.com/five82/reel/internal/validation"
+)
+
+// reportVersion is bumped when fields are renamed or removed.
+const reportVersion = 1
+
+// Report is the machine-readable result written next to an output as
+// <output>.reel.json.
+type Report struct {
+	Version      int              `json:"version"`
+	Input        string           `json:"input"`
+	Output       string           `json:"output"`
+	InputSize    uint64           `json:"input_size"`
+	OutputSize   uint64           `json:"output_size"`
+	DurationSecs float64          `json:"duration_secs"`
+	EncodeSecs   float64          `json:"encode_secs"`
+	CRF          uint32           `json:"crf"`
+	Preset       uint8            `json:"preset"`
+	Content      string           `json:"content"`
+	Crop         ReportCrop       `json:"crop"`
+	Chunks       ReportChunks     `json:"chunks"`
+	Memory       *ReportMemory    `json:"memory,omitempty"` // Unset where memory can't be measured
+	Validation   ReportValidation `json:"validation"`
+	Checksum     *ReportChecksum  `json:"checksum,omitempty"` // Set with --checksum
+
+	// Determinism is set with --verify-determinism
+	Determinism *DeterminismCheck `json:"determinism,omitempty"`
+}
+
+// ReportChecksum is the digest of the output.
+type ReportChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Digest    string `json:"digest"` // Lowercase hex
+}
+
+// ReportCrop holds the crop used and the sample distribution behind it.
+type ReportCrop struct {
+	Filter     string     `json:"filter,omitempty"` // Empty when not cropped
+	Confidence float64    `json:"confidence"`       // Share of detected samples agreeing on the most common crop
+	Samples    int        `json:"samples"`          // Positions sampled; 0 when detection didn't run
+	Votes      []CropVote `json:"votes,omitempty"`
+}
+
+// newReportCrop converts a crop result for the report.
+func newReportCrop(r CropResult) ReportCrop {
+	return ReportCrop{
+		Filter:     r.CropFilter,
+		Confidence: r.Confidence,
+		Samples:    r.Samples,
+		Votes:      r.Votes,
+	}
+}
+
+// slowChunkCount is how many of the slowest chunks are reported.
+const slowChunkCount = 5
+
+// ReportChunks summarizes per-chunk encode speed. Chunks reused from an
+// interrupted run have no timing and are not counted.
+type ReportChunks struct {
+	Encoded   int           `json:"encoded"` // Chunks encoded by this run
+	MeanFPS   float64       `json:"mean_fps"`
+	MedianFPS float64       `json:"median_fps"`
+	Slowest   []ReportChunk `json:"slowest,omitempty"` // Lowest fps first
+
+	// Shares of the chunks' encode time spent decoding frames and blocked
+	// writing them to the encoders. Writing overlaps decoding, so they can
+	// add up to more than the whole.
+	DecodeShare float64 `json:"decode_share"`
+	PipeShare   float64 `json:"pipe_share"`
+
+	// Shares of the chunks' encode time the decoder spent waiting on the
+	// encoder with its decode-ahead full, and the encoder waiting on the
+	// decoder with nothing decoded ahead.
+	DecodeStallShare float64 `json:"decode_stall_share"`
+	EncodeStallShare float64 `json:"encode_stall_share"`
+}
+
+// ReportChunk is the encode time of one chunk.
+type ReportChunk struct {
+	Chunk      int     `json:"chunk"`
+	StartFrame int     `json:"start_frame"`
+	EndFrame   int     `json:"end_frame"` // Exclusive
+	StartSecs  float64 `json:"start_secs"`
+	EndSecs    float64 `json:"end_secs"`
+	EncodeSecs float64 `json:"encode_secs"`
+	FPS        float64 `json:"fps"`
+}
+
+// newReportChunks summarizes chunk timings; frameRate converts frame numbers
+// to source timestamps.
+func newReportChunks(timings []encode.ChunkTiming, frameRate float64) ReportChunks {
+	if len(timings) == 0 {
+		return ReportChunks{}
+	}
+	sorted := slices.Clone(timings)
+	slices.SortFunc(sorted, func(a, b encode.ChunkTiming) int {
+		return cmp.Compare(a.FPS(), b.FPS())
+	})
+
+	var frames int
+	var elapsed, decode, pipe, decodeStall, encodeStall time.Duration
+	for _, t := range sorted {
+		frames += t.Chunk.Frames()
+		elapsed += t.Elapsed
+		decode += t.Decode
+		pipe += t.Pipe
+		decodeStall += t.DecodeStall
+		encodeStall += t.EncodeStall
+	}
+	r := ReportChunks{Encoded: len(sorted)}
+	if elapsed > 0 {
+		r.MeanFPS = float64(frames) / elapsed.Seconds()
+		r.DecodeShare = decode.Seconds() / elapsed.Seconds()
+		r.PipeShare = pipe.Seconds() / elapsed.Seconds()
+		r.DecodeStallShare = decodeStall.Seconds() / elapsed.Seconds()
+		r.EncodeStallShare = encodeStall.Seconds() / elapsed.Seconds()
+	}
+	mid := len(sorted) / 2
+	r.MedianFPS = sorted[mid].FPS()
+	if len(sorted)%2 == 0 {
+		r.MedianFPS = (sorted[mid-1].FPS() + sorted[mid].FPS()) / 2
+	}
+
+	for _, t := range sorted[:min(slowChunkCount, len(sorted))] {
+		c := ReportChunk{
+			Chunk:      t.Chunk.Idx,
+			StartFrame: t.Chunk.Start,
+			EndFrame:   t.Chunk.End,
+			EncodeSecs: t.Elapsed.Seconds(),
+			FPS:        t.FPS(),
+		}
+		if frameRate > 0 {
+			c.StartSecs = float64(t.Chunk.Start) / frameRate
+			c.EndSecs = float64(t.Chunk.End) / frameRate
+		}
+		r.Slowest = append(r.Slowest, c)
+	}
+	return r
+}
+
+// ReportValidation holds the validation outcome with structured step codes.
+type ReportValidation struct {
+	Passed bool                        `json:"passed"`
+	Steps  []validation.ValidationStep `json:"steps"`
+}
+
+// ReportPath returns the report path for an output: movie.mkv -> movie.mkv.reel.json.
+func ReportPath(outputPath string) string {
+	return outputPath + ".reel.json"
+}
+
+// WriteReport writes r to the output's report path.
+func WriteReport(outputPath string, r Report) error {
+	r.Version = reportVersion
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(ReportPath(outputPath), append(data, '\n'), 0o666); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package processing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/encode"
+)
+
+func TestNewReportChunks(t *testing.T) {
+	timing := func(idx, start, end int, secs float64) encode.ChunkTiming {
+		return encode.ChunkTiming{
+			Chunk:   chunk.Chunk{Idx: idx, Start: start, End: end},
+			Elapsed: time.Duration(secs * float64(time.Second)),
+			Decode:  time.Duration(secs * float64(time.Second) / 4),
+			Pipe:    time.Duration(secs * float64(time.Second) / 2),
+
+			DecodeStall: time.Duration(secs * float64(time.Second) / 5),
+			EncodeStall: time.Duration(secs * float64(time.Second) / 10),
+		}
+	}
+	timings := []encode.ChunkTiming{
+		timing(0, 0, 240, 10),    // 24 fps
+		timing(1, 240, 480, 40),  // 6 fps
+		timing(2, 480, 720, 20),  // 12 fps
+		timing(3, 720, 960, 5),   // 48 fps
+		timing(4, 960, 1200, 30), // 8 fps
+		timing(5, 1200, 1440, 8), // 30 fps
+	}
+
+	got := newReportChunks(timings, 24)
+	if got.Encoded != 6 {
+		t.Errorf("Encoded = %d, want 6", got.Encoded)
+	}
+	if got.MedianFPS != 18 {
+		t.Errorf("MedianFPS = %.2f, want 18", got.MedianFPS)
+	}
+	if want := 1440.0 / 113; got.MeanFPS != want {
+		t.Errorf("MeanFPS = %.2f, want %.2f", got.MeanFPS, want)
+	}
+	if got.DecodeShare != 0.25 || got.PipeShare != 0.5 {
+		t.Errorf("DecodeShare, PipeShare = %.2f, %.2f; want 0.25, 0.50", got.DecodeShare, got.PipeShare)
+	}
+	if math.Abs(got.DecodeStallShare-0.2) > 1e-9 || math.Abs(got.EncodeStallShare-0.1) > 1e-9 {
+		t.Errorf("DecodeStallShare, EncodeStallShare = %.2f, %.2f; want 0.20, 0.10", got.DecodeStallShare, got.EncodeStallShare)
+	}
+	if len(got.Slowest) != slowChunkCount {
+		t.Fatalf("len(Slowest) = %d, want %d", len(got.Slowest), slowChunkCount)
+	}
+	slowest := got.Slowest[0]
+	if slowest.Chunk != 1 || slowest.FPS != 6 || slowest.StartSecs != 10 || slowest.EndSecs != 20 {
+		t.Errorf("Slowest[0] = %+v, want chunk 1 at 6 fps covering 10-20s", slowest)
+	}
+	if got.Slowest[1].Chunk != 4 {
+		t.Errorf("Slowest[1].Chunk = %d, want 4", got.Slowest[1].Chunk)
+	}
+}
+
+func TestNewReportChunksEmpty(t *testing.T) {
+	if got := newReportChunks(nil, 24); got.Encoded != 0 || got.Slowest != nil {
+		t.Errorf("newReportChunks(nil) = %+v, want zero value", got)
+	}
+}
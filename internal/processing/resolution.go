@@ -0,0 +1,39 @@
+package processing
+
+import (
+	"fmt"
+
+	"github.com/five82/reel/internal/ffprobe"
+	"github.com/five82/reel/internal/toolpath"
+	"github.com/five82/reel/internal/util"
+)
+
+// resolutionSamples is how many positions are checked for a resolution change.
+const resolutionSamples = 24
+
+// checkResolution samples frames across the video and returns an error
+// wrapping ErrVariableResolution if any differ in size from the stream. The
+// pipeline decodes every frame at one size, so such sources can't be encoded.
+func checkResolution(paths toolpath.Paths, inputPath string, props *ffprobe.VideoProperties) error {
+	times := make([]float64, resolutionSamples)
+	for i := range times {
+		times[i] = props.DurationSecs * float64(i) / resolutionSamples
+	}
+	sizes, err := ffprobe.SampleFrameSizes(paths, inputPath, props.VideoIndex, times)
+	if err != nil {
+		return fmt.Errorf("failed to sample frame sizes: %w", err)
+	}
+	return resolutionChange(props.Width, props.Height, sizes)
+}
+
+// resolutionChange returns an error for the first sampled frame whose size
+// differs from width x height.
+func resolutionChange(width, height uint32, sizes []ffprobe.FrameSize) error {
+	for _, s := range sizes {
+		if s.Width != width || s.Height != height {
+			return fmt.Errorf("%w: %dx%d at %s, but the video starts at %dx%d",
+				ErrVariableResolution, s.Width, s.Height, util.FormatDuration(s.Time), width, height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package processing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestResolutionChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   []ffprobe.FrameSize
+		wantErr bool
+	}{
+		{"no samples", nil, false},
+		{"constant", []ffprobe.FrameSize{{Time: 0, Width: 1920, Height: 1080}, {Time: 600, Width: 1920, Height: 1080}}, false},
+		{"segment at another size", []ffprobe.FrameSize{{Time: 0, Width: 1920, Height: 1080}, {Time: 600, Width: 1280, Height: 720}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolutionChange(1920, 1080, tt.sizes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolutionChange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrVariableResolution) {
+				t.Errorf("error %v doesn't wrap ErrVariableResolution", err)
+			}
+		})
+	}
+}
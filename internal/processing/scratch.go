@@ -0,0 +1,191 @@
+package processing
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/util"
+)
+
+// Expected encoded size as a fraction of the source, by resolution tier.
+// Deliberately pessimistic so already-compressed sources still fit.
+const (
+	scratchRatioSD  = 0.75
+	scratchRatioHD  = 0.5
+	scratchRatioUHD = 0.4
+)
+
+// EstimateScratchSpace estimates the work directory space needed to encode a
+// source of the given size and width. The work directory holds every encoded
+// chunk plus the merged video until the final mux, so the expected output is
+// counted twice, with 10% headroom for audio and the index.
+func EstimateScratchSpace(sourceSize uint64, width uint32) uint64 {
+	ratio := scratchRatioSD
+	if width >= config.UHDWidthThreshold {
+		ratio = scratchRatioUHD
+	} else if width >= config.HDWidthThreshold {
+		ratio = scratchRatioHD
+	}
+	return uint64(float64(sourceSize) * ratio * 2 * 1.1)
+}
+
+// Share of available memory work files may take on a tmpfs, leaving the rest
+// for decoding and encoding. The auto location only moves work files to
+// memory when they fit comfortably.
+const (
+	memoryScratchShare = 0.5
+	autoScratchShare   = 0.25
+)
+
+// scratchLocation picks local directories for work directories and checks the
+// space they can use. Encoders and ffmpeg write work files by path, so only
+// local (or mounted) directories are supported, not object storage.
+type scratchLocation interface {
+	// candidates returns directories to try, most preferred first.
+	candidates(cfg *config.Config) []string
+	// available returns the bytes work files can use in dir, or 0 if unknown.
+	available(dir string) uint64
+}
+
+// diskScratch keeps work files in the configured temp dir, falling back to
+// the output directory and the system temp dir.
+type diskScratch struct{}
+
+func (diskScratch) candidates(cfg *config.Config) []string {
+	primary := cfg.GetTempDir()
+	dirs := []string{primary}
+	for _, dir := range []string{cfg.OutputDir, os.TempDir()} {
+		if dir != primary {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (diskScratch) available(dir string) uint64 {
+	return util.GetAvailableSpace(dir)
+}
+
+// memoryScratch keeps work files on a tmpfs, whose pages come out of RAM, so
+// space is limited by free memory as well as by the tmpfs size. A tmpfs whose
+// free space is unknown is not used.
+type memoryScratch struct {
+	dir             string // Overrides the configured temp dir
+	share           float64
+	availableMemory func() uint64
+}
+
+func (m memoryScratch) candidates(cfg *config.Config) []string {
+	switch {
+	case m.dir != "":
+		return []string{m.dir}
+	case cfg.TempDir != "":
+		return []string{cfg.TempDir}
+	}
+	return []string{config.MemoryScratchDir}
+}
+
+func (m memoryScratch) available(dir string) uint64 {
+	fsFree := util.GetAvailableSpace(dir)
+	if fsFree == 0 {
+		return 0
+	}
+	memFree := uint64(float64(m.availableMemory()) * m.share)
+	if memFree == 0 {
+		return fsFree
+	}
+	return min(fsFree, memFree)
+}
+
+// newScratchLocation returns the location for a config.ScratchLocations value.
+func newScratchLocation(name string) scratchLocation {
+	switch name {
+	case config.ScratchMemory:
+		return memoryScratch{share: memoryScratchShare, availableMemory: util.AvailableMemoryBytes}
+	case config.ScratchAuto:
+		// The temp dir is the disk location to fall back to.
+		return memoryScratch{dir: config.MemoryScratchDir, share: autoScratchShare, availableMemory: util.AvailableMemoryBytes}
+	}
+	return diskScratch{}
+}
+
+// scratchPrimary returns the directory work files are expected in; selecting
+// another one is worth a warning. For the auto location that is the disk
+// location, since moving to memory is only an optimization.
+func scratchPrimary(cfg *config.Config) string {
+	if cfg.ScratchLocation == config.ScratchAuto {
+		return cfg.GetTempDir()
+	}
+	return newScratchLocation(cfg.ScratchLocation).candidates(cfg)[0]
+}
+
+// scratchCandidate is a directory and the location that measures it.
+type scratchCandidate struct {
+	dir string
+	loc scratchLocation
+}
+
+// scratchCandidates lists the configured location's directories followed by
+// the disk location's, without duplicates.
+func scratchCandidates(cfg *config.Config) []scratchCandidate {
+	locations := []scratchLocation{newScratchLocation(cfg.ScratchLocation)}
+	if _, ok := locations[0].(diskScratch); !ok {
+		locations = append(locations, diskScratch{})
+	}
+	var candidates []scratchCandidate
+	seen := make(map[string]bool)
+	for _, l := range locations {
+		for _, dir := range l.candidates(cfg) {
+			if !seen[dir] {
+				seen[dir] = true
+				candidates = append(candidates, scratchCandidate{dir, l})
+			}
+		}
+	}
+	return candidates
+}
+
+// selectTempDir returns a temp directory with at least required bytes free.
+// The configured location's directories are tried first, then the disk
+// location's. An existing work directory (an interrupted encode that can
+// resume) always keeps its location.
+func selectTempDir(cfg *config.Config, inputPath string, required uint64) (string, error) {
+	candidates := scratchCandidates(cfg)
+	for _, c := range candidates {
+		if chunk.WorkDirExists(chunk.GetWorkDirPath(inputPath, c.dir)) {
+			return c.dir, nil
+		}
+	}
+
+	primary := scratchPrimary(cfg)
+	var primaryAvailable uint64
+	for _, c := range candidates {
+		available := c.loc.available(c.dir)
+		if c.dir == primary {
+			primaryAvailable = available
+		}
+		if available == 0 {
+			if _, disk := c.loc.(diskScratch); disk && c.dir == primary {
+				return c.dir, nil // Cannot determine, assume OK
+			}
+			continue
+		}
+		if available >= required {
+			if c.dir != primary && util.EnsureDirectoryWritable(c.dir) != nil {
+				continue
+			}
+			return c.dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("insufficient space for work directory: need about %s, %s available in %s",
+		util.FormatBytes(required), util.FormatBytes(primaryAvailable), primary)
+}
+
+// memoryScratchAvailable returns the bytes the auto location would allow on
+// its tmpfs, for logging why it chose disk.
+func memoryScratchAvailable() uint64 {
+	return newScratchLocation(config.ScratchAuto).available(config.MemoryScratchDir)
+}
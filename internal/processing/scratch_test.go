@@ -0,0 +1,99 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/util"
+)
+
+func TestEstimateScratchSpace(t *testing.T) {
+	tests := []struct {
+		name  string
+		width uint32
+		want  uint64
+	}{
+		{"SD", 720, 1650},
+		{"HD", 1920, 1100},
+		{"UHD", 3840, 880},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateScratchSpace(1000, tt.width); got != tt.want {
+				t.Errorf("EstimateScratchSpace(1000, %d) = %d, want %d", tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScratchCandidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		scratch string
+		tempDir string
+		want    string
+	}{
+		{"disk defaults to output dir", config.ScratchDisk, "", "/out"},
+		{"disk uses temp dir", config.ScratchDisk, "/scratch", "/scratch"},
+		{"memory defaults to /dev/shm", config.ScratchMemory, "", config.MemoryScratchDir},
+		{"memory uses temp dir", config.ScratchMemory, "/mnt/ram", "/mnt/ram"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig("/in", "/out", "/log")
+			cfg.TempDir = tt.tempDir
+			cfg.ScratchLocation = tt.scratch
+			if got := scratchPrimary(cfg); got != tt.want {
+				t.Errorf("scratchPrimary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScratchAvailable(t *testing.T) {
+	dir := t.TempDir()
+	fsFree := util.GetAvailableSpace(dir)
+	if fsFree == 0 {
+		t.Skip("free space unknown")
+	}
+
+	small := memoryScratch{share: 0.5, availableMemory: func() uint64 { return 1000 }}
+	if got := small.available(dir); got != 500 {
+		t.Errorf("available() with 1000 bytes of memory = %d, want 500", got)
+	}
+	unknown := memoryScratch{share: 0.5, availableMemory: func() uint64 { return 0 }}
+	if got := unknown.available(dir); got != fsFree {
+		t.Errorf("available() with unknown memory = %d, want %d", got, fsFree)
+	}
+}
+
+func TestScratchPrimaryAuto(t *testing.T) {
+	cfg := config.NewConfig("/in", "/out", "/log")
+	cfg.ScratchLocation = config.ScratchAuto
+	if got := scratchPrimary(cfg); got != "/out" {
+		t.Errorf("scratchPrimary() = %q, want the disk location /out", got)
+	}
+	candidates := scratchCandidates(cfg)
+	if candidates[0].dir != config.MemoryScratchDir || candidates[1].dir != "/out" {
+		t.Errorf("scratchCandidates() starts %q, %q; want %q, /out", candidates[0].dir, candidates[1].dir, config.MemoryScratchDir)
+	}
+}
+
+func TestSelectTempDirAutoFallsBackToDisk(t *testing.T) {
+	if util.GetAvailableSpace(config.MemoryScratchDir) == 0 {
+		t.Skip(config.MemoryScratchDir + " not available")
+	}
+	cfg := config.NewConfig("/in", t.TempDir(), "/log")
+	cfg.ScratchLocation = config.ScratchAuto
+
+	required := memoryScratchAvailable() + 1
+	dir, err := selectTempDir(cfg, "/in/movie.mkv", required)
+	if util.GetAvailableSpace(cfg.OutputDir) < required {
+		return // Neither fits; nothing to check
+	}
+	if err != nil || dir != cfg.OutputDir {
+		t.Errorf("selectTempDir() = %q, %v; want %q", dir, err, cfg.OutputDir)
+	}
+}
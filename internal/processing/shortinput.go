@@ -0,0 +1,31 @@
+package processing
+
+import "github.com/five82/reel/internal/encode"
+
+// minShortChunkSecs is the shortest chunk a short input is cut into so that
+// every worker gets one. Each chunk starts with a keyframe, so shorter
+// chunks cost compression.
+const minShortChunkSecs = 5.0
+
+// shortInputChunkSecs returns the chunk duration for an input of
+// durationSecs encoded by workers: chunkSecs, unless that would leave
+// workers without a chunk, in which case chunks are shortened until each
+// worker has one, down to minShortChunkSecs.
+func shortInputChunkSecs(durationSecs, chunkSecs float64, workers int) float64 {
+	if workers <= 1 || durationSecs >= chunkSecs*float64(workers) {
+		return chunkSecs
+	}
+	return min(chunkSecs, max(durationSecs/float64(workers), minShortChunkSecs))
+}
+
+// workersForChunks returns the workers to request, shared among files
+// encoding at once, when only chunks chunks are left to encode: no more
+// than one per chunk for each file, as a worker without a chunk only holds
+// threads the others could use.
+func workersForChunks(workers, chunks, files int) int {
+	files = max(files, 1)
+	if chunks <= 0 || encode.ShareWorkers(workers, files) <= chunks {
+		return workers
+	}
+	return chunks * files
+}
@@ -0,0 +1,48 @@
+package processing
+
+import "testing"
+
+func TestShortInputChunkSecs(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  float64
+		chunkSecs float64
+		workers   int
+		want      float64
+	}{
+		{"long input", 3600, 10, 24, 10},
+		{"exactly enough", 240, 10, 24, 10},
+		{"3-minute clip", 180, 10, 24, 7.5},
+		{"shortened to the minimum", 60, 10, 24, minShortChunkSecs},
+		{"configured below the minimum", 30, 2, 24, 2},
+		{"single worker", 30, 10, 1, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shortInputChunkSecs(tt.duration, tt.chunkSecs, tt.workers); got != tt.want {
+				t.Errorf("shortInputChunkSecs(%g, %g, %d) = %g, want %g", tt.duration, tt.chunkSecs, tt.workers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkersForChunks(t *testing.T) {
+	tests := []struct {
+		name                   string
+		workers, chunks, files int
+		want                   int
+	}{
+		{"more chunks than workers", 24, 100, 1, 24},
+		{"fewer chunks", 24, 6, 1, 6},
+		{"shared between files", 24, 6, 2, 12},
+		{"share already fits", 24, 12, 2, 24},
+		{"nothing left", 24, 0, 1, 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workersForChunks(tt.workers, tt.chunks, tt.files); got != tt.want {
+				t.Errorf("workersForChunks(%d, %d, %d) = %d, want %d", tt.workers, tt.chunks, tt.files, got, tt.want)
+			}
+		})
+	}
+}
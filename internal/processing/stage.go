@@ -0,0 +1,90 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/config"
+	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/util"
+)
+
+// stagedFile is a source copied to local storage for --stage-local, and the
+// local path its output is written to before being moved into place.
+type stagedFile struct {
+	dir    string
+	input  string
+	output string
+}
+
+// stageDir is where sources are staged: the temp dir if one is set, else
+// the system temp dir, as the default temp dir is the output directory,
+// which may be on the same network storage.
+func stageDir(cfg *config.Config) string {
+	if cfg.TempDir != "" {
+		return cfg.TempDir
+	}
+	return os.TempDir()
+}
+
+// stageInput copies inputPath to local storage. A copy left by an earlier
+// run is reused if it still matches the source's size and modification
+// time, which the copy is given so a resumed encode's checkpoint matches.
+func stageInput(ctx context.Context, cfg *config.Config, inputPath, outputPath string, rep reporter.Reporter) (*stagedFile, error) {
+	base := stageDir(cfg)
+	dir := filepath.Join(base, chunk.WorkDirName(inputPath)+"-staged")
+	s := &stagedFile{
+		dir:    dir,
+		input:  filepath.Join(dir, "input", filepath.Base(inputPath)),
+		output: filepath.Join(dir, "output", filepath.Base(outputPath)),
+	}
+
+	src, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	if staged, err := os.Stat(s.input); err == nil && staged.Size() == src.Size() && staged.ModTime().Equal(src.ModTime()) {
+		rep.Verbose(fmt.Sprintf("Reusing staged copy in %s", dir))
+		return s, nil
+	}
+
+	// Room for the copy and an output no larger than the source
+	need := 2 * uint64(src.Size())
+	if available := util.GetAvailableSpace(base); available > 0 && available < need {
+		return nil, fmt.Errorf("not enough space in %s to stage the source (need about %s, %s available)",
+			base, util.FormatBytes(need), util.FormatBytes(available))
+	}
+	for _, d := range []string{filepath.Dir(s.input), filepath.Dir(s.output)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create staging directory: %w", err)
+		}
+	}
+
+	progress := newTaskProgress(rep, "Staging source", "bytes")
+	if err := util.CopyFileProgress(ctx, inputPath, s.input, progress.update); err != nil {
+		return nil, fmt.Errorf("failed to stage source: %w", err)
+	}
+	progress.finish()
+	if err := util.CopyTimes(inputPath, s.input, false); err != nil {
+		return nil, fmt.Errorf("failed to stage source: %w", err)
+	}
+	return s, nil
+}
+
+// moveOutput moves the locally written output to outputPath.
+func (s *stagedFile) moveOutput(ctx context.Context, outputPath string, rep reporter.Reporter) error {
+	progress := newTaskProgress(rep, "Copying output", "bytes")
+	if err := util.MoveFile(ctx, s.output, outputPath, progress.update); err != nil {
+		return fmt.Errorf("failed to move output into place: %w", err)
+	}
+	progress.finish()
+	return nil
+}
+
+// cleanup removes the staged copy and anything left of the local output.
+func (s *stagedFile) cleanup() error {
+	return os.RemoveAll(s.dir)
+}
@@ -0,0 +1,48 @@
+package processing
+
+import (
+	"sync"
+
+	"github.com/five82/reel/internal/reporter"
+)
+
+// taskProgress reports an analysis task's progress, passing on only updates
+// that move it by at least a whole percent.
+type taskProgress struct {
+	rep  reporter.Reporter
+	task string
+	unit string
+
+	mu          sync.Mutex
+	lastPercent int
+	total       uint64
+	done        bool
+}
+
+func newTaskProgress(rep reporter.Reporter, task, unit string) *taskProgress {
+	return &taskProgress{rep: rep, task: task, unit: unit, lastPercent: -1}
+}
+
+// update reports done out of total units.
+func (t *taskProgress) update(done, total uint64) {
+	progress := reporter.TaskProgress{Task: t.task, Done: min(done, total), Total: total, Unit: t.unit}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	percent := int(progress.Percent())
+	if t.done || percent <= t.lastPercent {
+		return
+	}
+	t.lastPercent, t.total, t.done = percent, total, progress.Complete()
+	reporter.ReportTaskProgress(t.rep, progress)
+}
+
+// finish reports the task complete, for tasks whose last update can fall
+// short of the total.
+func (t *taskProgress) finish() {
+	t.mu.Lock()
+	total, pending := t.total, !t.done && t.lastPercent >= 0
+	t.mu.Unlock()
+	if pending {
+		t.update(total, total)
+	}
+}
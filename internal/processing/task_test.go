@@ -0,0 +1,49 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/reporter"
+)
+
+// taskRecorder records task progress.
+type taskRecorder struct {
+	reporter.NullReporter
+	tasks []reporter.TaskProgress
+}
+
+func (r *taskRecorder) TaskProgress(progress reporter.TaskProgress) {
+	r.tasks = append(r.tasks, progress)
+}
+
+func TestTaskProgressThrottles(t *testing.T) {
+	rec := &taskRecorder{}
+	task := newTaskProgress(rec, "Indexing", "bytes")
+	for done := uint64(0); done <= 1000; done++ {
+		task.update(done, 1000)
+	}
+	task.update(1000, 1000)
+
+	if len(rec.tasks) != 101 {
+		t.Fatalf("got %d updates, want one per percent (101)", len(rec.tasks))
+	}
+	if last := rec.tasks[len(rec.tasks)-1]; !last.Complete() {
+		t.Errorf("last update %d/%d, want complete", last.Done, last.Total)
+	}
+}
+
+func TestTaskProgressFinish(t *testing.T) {
+	rec := &taskRecorder{}
+	task := newTaskProgress(rec, "Audio extraction", "seconds")
+	task.finish()
+	if len(rec.tasks) != 0 {
+		t.Fatalf("finish before any update reported %d updates, want none", len(rec.tasks))
+	}
+
+	task.update(58, 60)
+	task.finish()
+	task.finish()
+	if len(rec.tasks) != 2 || !rec.tasks[1].Complete() {
+		t.Errorf("updates = %+v, want the partial update then one completion", rec.tasks)
+	}
+}
@@ -28,6 +28,12 @@ func (c *CompositeReporter) StageProgress(update StageProgress) {
 	}
 }
 
+func (c *CompositeReporter) TaskProgress(progress TaskProgress) {
+	for _, r := range c.reporters {
+		ReportTaskProgress(r, progress)
+	}
+}
+
 func (c *CompositeReporter) CropResult(summary CropSummary) {
 	for _, r := range c.reporters {
 		r.CropResult(summary)
@@ -40,12 +46,36 @@ func (c *CompositeReporter) EncodingConfig(summary EncodingConfigSummary) {
 	}
 }
 
+func (c *CompositeReporter) EncodeEstimate(estimate EncodeEstimate) {
+	for _, r := range c.reporters {
+		ReportEncodeEstimate(r, estimate)
+	}
+}
+
+func (c *CompositeReporter) ChunkRetry(retry ChunkRetry) {
+	for _, r := range c.reporters {
+		ReportChunkRetry(r, retry)
+	}
+}
+
+func (c *CompositeReporter) WorkerCap(workers WorkerCap) {
+	for _, r := range c.reporters {
+		ReportWorkerCap(r, workers)
+	}
+}
+
 func (c *CompositeReporter) EncodingStarted(totalFrames uint64) {
 	for _, r := range c.reporters {
 		r.EncodingStarted(totalFrames)
 	}
 }
 
+func (c *CompositeReporter) EncodingStartedWith(start EncodingStart) {
+	for _, r := range c.reporters {
+		ReportEncodingStarted(r, start)
+	}
+}
+
 func (c *CompositeReporter) EncodingProgress(progress ProgressSnapshot) {
 	for _, r := range c.reporters {
 		r.EncodingProgress(progress)
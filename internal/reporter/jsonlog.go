@@ -0,0 +1,305 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// JSONTimeFormat is the timestamp format of JSON log records.
+const JSONTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// JSONRecord is one line of a JSON log: what happened, at what level, with
+// its values under Fields. Event names match the library's event types.
+type JSONRecord struct {
+	Time    string         `json:"time"`
+	Level   string         `json:"level"` // debug, info, warn or error
+	Event   string         `json:"event"`
+	Message string         `json:"message,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// JSONLogReporter writes encoding events to a log as JSON lines, for log
+// shippers that index fields rather than parse text.
+type JSONLogReporter struct {
+	mu                 sync.Mutex
+	enc                *json.Encoder
+	lastProgressBucket int // Track progress in 5% buckets
+}
+
+// NewJSONLogReporter creates a reporter that writes JSON lines to w.
+func NewJSONLogReporter(w io.Writer) *JSONLogReporter {
+	return &JSONLogReporter{
+		enc:                json.NewEncoder(w),
+		lastProgressBucket: -1,
+	}
+}
+
+func (r *JSONLogReporter) record(level, event, message string, fields map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(JSONRecord{
+		Time:    time.Now().Format(JSONTimeFormat),
+		Level:   level,
+		Event:   event,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+func (r *JSONLogReporter) Hardware(summary HardwareSummary) {
+	r.record("info", "hardware", "", map[string]any{
+		"hostname":               summary.Hostname,
+		"cpu_model":              summary.CPUModel,
+		"physical_cores":         summary.PhysicalCores,
+		"logical_cores":          summary.LogicalCores,
+		"total_memory_bytes":     summary.TotalMemory,
+		"available_memory_bytes": summary.AvailableMemory,
+		"os":                     summary.OS,
+		"svt_version":            summary.SvtVersion,
+		"ffmpeg_version":         summary.FFmpegVersion,
+		"numa_nodes":             summary.NUMANodes,
+		"numa":                   summary.NUMA,
+	})
+}
+
+func (r *JSONLogReporter) Initialization(summary InitializationSummary) {
+	r.record("info", "initialization", "", map[string]any{
+		"input_file":        summary.InputFile,
+		"output_file":       summary.OutputFile,
+		"duration":          summary.Duration,
+		"resolution":        summary.Resolution,
+		"dynamic_range":     summary.DynamicRange,
+		"audio_description": summary.AudioDescription,
+		"object_audio":      summary.ObjectAudio,
+	})
+}
+
+func (r *JSONLogReporter) StageProgress(update StageProgress) {
+	r.record("info", "stage_progress", update.Message, map[string]any{
+		"stage":   update.Stage,
+		"percent": update.Percent,
+	})
+}
+
+func (r *JSONLogReporter) TaskProgress(progress TaskProgress) {
+	if !taskStep(progress) {
+		return
+	}
+	r.record("info", "task_progress", "", map[string]any{
+		"task":    progress.Task,
+		"done":    progress.Done,
+		"total":   progress.Total,
+		"unit":    progress.Unit,
+		"percent": progress.Percent(),
+	})
+}
+
+func (r *JSONLogReporter) CropResult(summary CropSummary) {
+	r.record("info", "crop_result", summary.Message, map[string]any{
+		"crop":     summary.Crop,
+		"required": summary.Required,
+		"disabled": summary.Disabled,
+	})
+}
+
+func (r *JSONLogReporter) EncodingConfig(summary EncodingConfigSummary) {
+	r.record("info", "encoding_config", "", map[string]any{
+		"encoder":             summary.Encoder,
+		"preset":              summary.Preset,
+		"tune":                summary.Tune,
+		"quality":             summary.Quality,
+		"content":             summary.Content,
+		"pixel_format":        summary.PixelFormat,
+		"matrix_coefficients": summary.MatrixCoefficients,
+		"audio_codec":         summary.AudioCodec,
+		"audio_description":   summary.AudioDescription,
+		"svt_params":          summary.SVTAV1Params,
+	})
+}
+
+func (r *JSONLogReporter) EncodeEstimate(estimate EncodeEstimate) {
+	r.record("info", "encode_estimate", "", map[string]any{
+		"probe_chunks":      estimate.ProbeChunks,
+		"probe_frames":      estimate.ProbeFrames,
+		"original_size":     estimate.OriginalSize,
+		"estimated_size":    estimate.EstimatedSize,
+		"bitrate_kbps":      estimate.BitrateKbps,
+		"estimated_seconds": int64(estimate.EstimatedTime.Seconds()),
+	})
+}
+
+func (r *JSONLogReporter) EncodingStarted(totalFrames uint64) {
+	r.EncodingStartedWith(EncodingStart{TotalFrames: totalFrames})
+}
+
+func (r *JSONLogReporter) EncodingStartedWith(start EncodingStart) {
+	r.mu.Lock()
+	r.lastProgressBucket = -1
+	r.mu.Unlock()
+	r.record("info", "encoding_started", "", map[string]any{
+		"total_frames":    start.TotalFrames,
+		"total_chunks":    start.TotalChunks,
+		"resumed_frames":  start.ResumedFrames,
+		"resumed_chunks":  start.ResumedChunks,
+		"resumed_percent": start.ResumedPercent(),
+	})
+}
+
+func (r *JSONLogReporter) EncodingProgress(progress ProgressSnapshot) {
+	// Log progress at 5% intervals
+	bucket := int(progress.Percent / 5)
+	r.mu.Lock()
+	if bucket <= r.lastProgressBucket || bucket > 20 {
+		r.mu.Unlock()
+		return
+	}
+	r.lastProgressBucket = bucket
+	r.mu.Unlock()
+	r.record("info", "encoding_progress", "", map[string]any{
+		"percent":         progress.Percent,
+		"speed":           progress.Speed,
+		"fps":             progress.FPS,
+		"eta_seconds":     int64(progress.ETA.Seconds()),
+		"chunks_complete": progress.ChunksComplete,
+		"chunks_total":    progress.ChunksTotal,
+		"bitrate_kbps":    progress.BitrateKbps,
+		"projected_size":  progress.ProjectedSize,
+	})
+}
+
+func (r *JSONLogReporter) ChunkRetry(retry ChunkRetry) {
+	r.record("warn", "chunk_retry", retry.Reason, map[string]any{
+		"chunk":         retry.Chunk,
+		"attempt":       retry.Attempt,
+		"retries":       retry.Retries,
+		"threads":       retry.Threads,
+		"delay_seconds": retry.Delay.Seconds(),
+	})
+}
+
+func (r *JSONLogReporter) WorkerCap(workers WorkerCap) {
+	r.record("info", "worker_cap", "", map[string]any{
+		"requested":              workers.Requested,
+		"granted":                workers.Granted,
+		"mem_per_worker_bytes":   workers.MemPerWorker,
+		"available_memory_bytes": workers.Available,
+		"raised":                 workers.Raised,
+		"lowered":                workers.Lowered,
+	})
+}
+
+func (r *JSONLogReporter) ValidationComplete(summary ValidationSummary) {
+	level := "info"
+	if !summary.Passed {
+		level = "warn"
+	}
+	steps := make([]map[string]any, 0, len(summary.Steps))
+	for _, step := range summary.Steps {
+		steps = append(steps, map[string]any{
+			"name":    step.Name,
+			"check":   step.Check,
+			"code":    step.Code,
+			"passed":  step.Passed,
+			"details": step.Details,
+			"params":  step.Params,
+		})
+	}
+	r.record(level, "validation_complete", "", map[string]any{
+		"passed": summary.Passed,
+		"steps":  steps,
+	})
+}
+
+func (r *JSONLogReporter) EncodingComplete(summary EncodingOutcome) {
+	copies := make([]map[string]any, 0, len(summary.Copies))
+	for _, c := range summary.Copies {
+		copies = append(copies, map[string]any{
+			"destination": c.Destination,
+			"success":     c.Success,
+			"error":       c.Error,
+		})
+	}
+	r.record("info", "encoding_complete", "", map[string]any{
+		"input_file":    summary.InputFile,
+		"output_file":   summary.OutputFile,
+		"output_path":   summary.OutputPath,
+		"original_size": summary.OriginalSize,
+		"encoded_size":  summary.EncodedSize,
+		"video_stream":  summary.VideoStream,
+		"audio_stream":  summary.AudioStream,
+		"total_seconds": int64(summary.TotalTime.Seconds()),
+		"average_speed": summary.AverageSpeed,
+		"copies":        copies,
+	})
+}
+
+func (r *JSONLogReporter) Warning(message string) {
+	r.record("warn", "warning", message, nil)
+}
+
+func (r *JSONLogReporter) Error(err ReporterError) {
+	r.record("error", "error", err.Message, map[string]any{
+		"title":      err.Title,
+		"context":    err.Context,
+		"suggestion": err.Suggestion,
+	})
+}
+
+func (r *JSONLogReporter) OperationComplete(message string) {
+	r.record("info", "operation_complete", message, nil)
+}
+
+func (r *JSONLogReporter) BatchStarted(info BatchStartInfo) {
+	r.record("info", "batch_started", "", map[string]any{
+		"total_files": info.TotalFiles,
+		"file_list":   info.FileList,
+		"output_dir":  info.OutputDir,
+	})
+}
+
+func (r *JSONLogReporter) FileProgress(context FileProgressContext) {
+	r.record("info", "file_progress", "", map[string]any{
+		"current_file": context.CurrentFile,
+		"total_files":  context.TotalFiles,
+	})
+}
+
+func (r *JSONLogReporter) BatchComplete(summary BatchSummary) {
+	files := make([]map[string]any, 0, len(summary.FileResults))
+	for _, result := range summary.FileResults {
+		files = append(files, map[string]any{
+			"filename":  result.Filename,
+			"reduction": result.Reduction,
+		})
+	}
+	skipped := make([]map[string]any, 0, len(summary.Skipped))
+	for _, s := range summary.Skipped {
+		skipped = append(skipped, map[string]any{"filename": s.Filename, "reason": s.Reason})
+	}
+	failed := make([]map[string]any, 0, len(summary.Failed))
+	for _, f := range summary.Failed {
+		failed = append(failed, map[string]any{"filename": f.Filename, "reason": f.Reason})
+	}
+	r.record("info", "batch_complete", "", map[string]any{
+		"successful_count":        summary.SuccessfulCount,
+		"total_files":             summary.TotalFiles,
+		"total_original_size":     summary.TotalOriginalSize,
+		"total_encoded_size":      summary.TotalEncodedSize,
+		"total_seconds":           int64(summary.TotalDuration.Seconds()),
+		"average_speed":           summary.AverageSpeed,
+		"validation_passed_count": summary.ValidationPassedCount,
+		"validation_failed_count": summary.ValidationFailedCount,
+		"copy_succeeded_count":    summary.CopySucceededCount,
+		"copy_failed_count":       summary.CopyFailedCount,
+		"files":                   files,
+		"skipped":                 skipped,
+		"failed":                  failed,
+		"deferred":                summary.Deferred,
+	})
+}
+
+func (r *JSONLogReporter) Verbose(message string) {
+	r.record("debug", "verbose", message, nil)
+}
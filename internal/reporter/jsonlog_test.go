@@ -0,0 +1,133 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// decodeJSONLines decodes each line of a JSON log, failing on lines that
+// aren't JSON objects or have keys outside the record's.
+func decodeJSONLines(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	keys := map[string]bool{"time": true, "level": true, "event": true, "message": true, "fields": true}
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not a JSON object: %v", scanner.Text(), err)
+		}
+		for key := range record {
+			if !keys[key] {
+				t.Errorf("line %q has unexpected key %q", scanner.Text(), key)
+			}
+		}
+		stamp, _ := record["time"].(string)
+		if _, err := time.Parse(JSONTimeFormat, stamp); err != nil {
+			t.Errorf("line %q has time %q not in JSONTimeFormat: %v", scanner.Text(), stamp, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestJSONLogReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONLogReporter(&buf)
+
+	r.Warning("Audio stream 1 has no language")
+	r.Error(ReporterError{Title: "Disk Space Error", Message: "Cannot encode movie.mkv", Suggestion: "Free up space"})
+	r.ChunkRetry(ChunkRetry{Chunk: 7, Attempt: 1, Retries: 2, Threads: 4, Delay: 5 * time.Second, Reason: "killed by signal"})
+	r.Verbose("Classifying content from sampled frames")
+	r.OperationComplete("Encoding complete")
+
+	tests := []struct {
+		level   string
+		event   string
+		message string
+		fields  map[string]any // Checked fields; nil when the record has none
+	}{
+		{"warn", "warning", "Audio stream 1 has no language", nil},
+		{"error", "error", "Cannot encode movie.mkv", map[string]any{"title": "Disk Space Error", "context": "", "suggestion": "Free up space"}},
+		{"warn", "chunk_retry", "killed by signal", map[string]any{"chunk": 7.0, "attempt": 1.0, "retries": 2.0, "threads": 4.0, "delay_seconds": 5.0}},
+		{"debug", "verbose", "Classifying content from sampled frames", nil},
+		{"info", "operation_complete", "Encoding complete", nil},
+	}
+
+	records := decodeJSONLines(t, buf.Bytes())
+	if len(records) != len(tests) {
+		t.Fatalf("got %d records, want %d:\n%s", len(records), len(tests), buf.String())
+	}
+	for i, tt := range tests {
+		record := records[i]
+		if record["level"] != tt.level || record["event"] != tt.event || record["message"] != tt.message {
+			t.Errorf("record %d = %v, want level %q, event %q, message %q", i, record, tt.level, tt.event, tt.message)
+		}
+		fields, hasFields := record["fields"].(map[string]any)
+		if tt.fields == nil {
+			if _, ok := record["fields"]; ok {
+				t.Errorf("record %d has fields %v, want none", i, record["fields"])
+			}
+			continue
+		}
+		if !hasFields {
+			t.Errorf("record %d has no fields object", i)
+			continue
+		}
+		for key, want := range tt.fields {
+			if fields[key] != want {
+				t.Errorf("record %d field %q = %v, want %v", i, key, fields[key], want)
+			}
+		}
+	}
+}
+
+func TestJSONLogReporterOmitsEmptyMessage(t *testing.T) {
+	var buf bytes.Buffer
+	NewJSONLogReporter(&buf).FileProgress(FileProgressContext{CurrentFile: 2, TotalFiles: 5})
+
+	records := decodeJSONLines(t, buf.Bytes())
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if _, ok := records[0]["message"]; ok {
+		t.Errorf("record has message %v, want it omitted", records[0]["message"])
+	}
+	fields, _ := records[0]["fields"].(map[string]any)
+	if records[0]["event"] != "file_progress" || fields["current_file"] != 2.0 || fields["total_files"] != 5.0 {
+		t.Errorf("record = %v", records[0])
+	}
+}
+
+func TestJSONLogReporterProgressBuckets(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONLogReporter(&buf)
+
+	r.EncodingStartedWith(EncodingStart{TotalFrames: 1000, TotalChunks: 10})
+	for _, percent := range []float32{0, 1, 4.9, 5, 7, 12, 10, 100} {
+		r.EncodingProgress(ProgressSnapshot{Percent: percent})
+	}
+
+	var percents []float64
+	for _, record := range decodeJSONLines(t, buf.Bytes()) {
+		if record["event"] != "encoding_progress" {
+			continue
+		}
+		fields, _ := record["fields"].(map[string]any)
+		percents = append(percents, fields["percent"].(float64))
+	}
+	// One record per 5% step, never going back
+	want := []float64{0, 5, 12, 100}
+	if len(percents) != len(want) {
+		t.Fatalf("progress records at %v, want %v", percents, want)
+	}
+	for i := range want {
+		if percents[i] != want[i] {
+			t.Errorf("progress records at %v, want %v", percents, want)
+			break
+		}
+	}
+}
@@ -36,6 +36,14 @@ func (r *LogReporter) log(level, format string, args ...any) {
 func (r *LogReporter) Hardware(summary HardwareSummary) {
 	r.log("INFO", "=== HARDWARE ===")
 	r.log("INFO", "Hostname: %s", summary.Hostname)
+	r.log("INFO", "CPU: %s", formatCPU(summary))
+	r.log("INFO", "Memory: %s", formatMemory(summary))
+	r.log("INFO", "OS: %s", summary.OS)
+	r.log("INFO", "SvtAv1EncApp: %s", summary.SvtVersion)
+	r.log("INFO", "FFmpeg: %s", summary.FFmpegVersion)
+	if summary.NUMA != "" {
+		r.log("INFO", "NUMA: %s", summary.NUMA)
+	}
 }
 
 func (r *LogReporter) Initialization(summary InitializationSummary) {
@@ -46,12 +54,21 @@ func (r *LogReporter) Initialization(summary InitializationSummary) {
 	r.log("INFO", "Resolution: %s", summary.Resolution)
 	r.log("INFO", "Dynamic range: %s", summary.DynamicRange)
 	r.log("INFO", "Audio: %s", summary.AudioDescription)
+	if summary.ObjectAudio != "" {
+		r.log("INFO", "Object audio: %s", summary.ObjectAudio)
+	}
 }
 
 func (r *LogReporter) StageProgress(update StageProgress) {
 	r.log("INFO", "[%s] %s", strings.ToUpper(update.Stage), update.Message)
 }
 
+func (r *LogReporter) TaskProgress(progress TaskProgress) {
+	if taskStep(progress) {
+		r.log("INFO", "%s", formatTask(progress))
+	}
+}
+
 func (r *LogReporter) CropResult(summary CropSummary) {
 	if summary.Disabled {
 		r.log("INFO", "Crop detection: disabled")
@@ -68,6 +85,9 @@ func (r *LogReporter) EncodingConfig(summary EncodingConfigSummary) {
 	r.log("INFO", "Preset: %s", summary.Preset)
 	r.log("INFO", "Tune: %s", summary.Tune)
 	r.log("INFO", "Quality: %s", summary.Quality)
+	if summary.Content != "" {
+		r.log("INFO", "Content: %s", summary.Content)
+	}
 	r.log("INFO", "Pixel format: %s", summary.PixelFormat)
 	r.log("INFO", "Matrix: %s", summary.MatrixCoefficients)
 	r.log("INFO", "Audio codec: %s", summary.AudioCodec)
@@ -78,11 +98,28 @@ func (r *LogReporter) EncodingConfig(summary EncodingConfigSummary) {
 	}
 }
 
+func (r *LogReporter) EncodeEstimate(estimate EncodeEstimate) {
+	r.log("INFO", "Estimate from %d probe chunks (%d frames): size %s, video bitrate %.0f kbps",
+		estimate.ProbeChunks, estimate.ProbeFrames,
+		util.FormatBytesReadable(estimate.EstimatedSize), estimate.BitrateKbps)
+	if estimate.EstimatedTime > 0 {
+		r.log("INFO", "Estimated encode time: %s", util.FormatDurationFromSecs(int64(estimate.EstimatedTime.Seconds())))
+	}
+}
+
 func (r *LogReporter) EncodingStarted(totalFrames uint64) {
+	r.EncodingStartedWith(EncodingStart{TotalFrames: totalFrames})
+}
+
+func (r *LogReporter) EncodingStartedWith(start EncodingStart) {
 	r.mu.Lock()
 	r.lastProgressBucket = -1
 	r.mu.Unlock()
-	r.log("INFO", "=== ENCODING STARTED === (total frames: %d)", totalFrames)
+	r.log("INFO", "=== ENCODING STARTED === (total frames: %d)", start.TotalFrames)
+	if start.ResumedChunks > 0 {
+		r.log("INFO", "Resuming from %.1f%%: %d/%d chunks (%d frames) already encoded",
+			start.ResumedPercent(), start.ResumedChunks, start.TotalChunks, start.ResumedFrames)
+	}
 }
 
 func (r *LogReporter) EncodingProgress(progress ProgressSnapshot) {
@@ -95,6 +132,10 @@ func (r *LogReporter) EncodingProgress(progress ProgressSnapshot) {
 		r.log("INFO", "Progress: %.0f%% (speed %.1fx, fps %.1f, eta %s)",
 			progress.Percent, progress.Speed, progress.FPS,
 			util.FormatDurationFromSecs(int64(progress.ETA.Seconds())))
+		if progress.ProjectedSize > 0 {
+			r.log("INFO", "Average video bitrate %.0f kbps, projected size %s",
+				progress.BitrateKbps, util.FormatBytesReadable(progress.ProjectedSize))
+		}
 	} else {
 		r.mu.Unlock()
 	}
@@ -132,6 +173,38 @@ func (r *LogReporter) EncodingComplete(summary EncodingOutcome) {
 		util.FormatDurationFromSecs(int64(summary.TotalTime.Seconds())),
 		summary.AverageSpeed)
 	r.log("INFO", "Saved to: %s", summary.OutputPath)
+
+	for _, c := range summary.Copies {
+		if c.Success {
+			r.log("INFO", "Copied to: %s", c.Destination)
+		} else {
+			r.log("WARN", "Copy to %s failed: %s", c.Destination, c.Error)
+		}
+	}
+}
+
+func (r *LogReporter) ChunkRetry(retry ChunkRetry) {
+	r.log("WARN", "Chunk %d failed (%s); retry %d/%d in %s with %d threads",
+		retry.Chunk, retry.Reason, retry.Attempt, retry.Retries, retry.Delay, retry.Threads)
+}
+
+func (r *LogReporter) WorkerCap(workers WorkerCap) {
+	if workers.Raised {
+		r.log("INFO", "Memory has room (%s available, %s per worker); encoding with %d/%d workers",
+			util.FormatBytes(workers.Available), util.FormatBytes(workers.MemPerWorker), workers.Granted, workers.Requested)
+		return
+	}
+	if workers.Lowered {
+		r.log("WARN", "Memory running short (%s available, %s per worker); encoding with %d/%d workers",
+			util.FormatBytes(workers.Available), util.FormatBytes(workers.MemPerWorker), workers.Granted, workers.Requested)
+		return
+	}
+	if workers.Available == 0 {
+		r.log("INFO", "Memory cap: available memory unknown; using %d workers", workers.Granted)
+		return
+	}
+	r.log("INFO", "Memory cap: %d/%d workers at %s each, %s available",
+		workers.Granted, workers.Requested, util.FormatBytes(workers.MemPerWorker), util.FormatBytes(workers.Available))
 }
 
 func (r *LogReporter) Warning(message string) {
@@ -177,10 +250,32 @@ func (r *LogReporter) BatchComplete(summary BatchSummary) {
 	r.log("INFO", "Time: %s (avg speed %.1fx)",
 		util.FormatDurationFromSecs(int64(summary.TotalDuration.Seconds())),
 		summary.AverageSpeed)
+	if summary.CopySucceededCount+summary.CopyFailedCount > 0 {
+		r.log("INFO", "Copies: %d succeeded, %d failed", summary.CopySucceededCount, summary.CopyFailedCount)
+	}
+
+	if len(summary.Skipped) > 0 {
+		r.log("INFO", "Skipped: %d (no encodable video)", len(summary.Skipped))
+	}
+	if len(summary.Failed) > 0 {
+		r.log("INFO", "Failed: %d", len(summary.Failed))
+	}
+	if len(summary.Deferred) > 0 {
+		r.log("INFO", "Deferred: %d (deadline reached; rerun to continue)", len(summary.Deferred))
+	}
 
 	for _, result := range summary.FileResults {
 		r.log("INFO", "  - %s (%.1f%% reduction)", result.Filename, result.Reduction)
 	}
+	for _, s := range summary.Skipped {
+		r.log("INFO", "  - %s (skipped: %s)", s.Filename, s.Reason)
+	}
+	for _, f := range summary.Failed {
+		r.log("INFO", "  - %s (failed: %s)", f.Filename, f.Reason)
+	}
+	for _, name := range summary.Deferred {
+		r.log("INFO", "  - %s (deferred)", name)
+	}
 }
 
 func (r *LogReporter) Verbose(message string) {
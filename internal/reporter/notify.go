@@ -0,0 +1,93 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/util"
+)
+
+// notifyTimeout bounds each notify-send call, which can hang without a
+// notification daemon.
+const notifyTimeout = 5 * time.Second
+
+// DesktopNotifyReporter posts a desktop notification via notify-send when a
+// file finishes, fails or fails validation, and when a batch completes.
+// Other events are ignored; compose it with the terminal and log reporters.
+type DesktopNotifyReporter struct {
+	NullReporter
+	path string
+	wg   sync.WaitGroup
+}
+
+// NewDesktopNotifyReporter returns a reporter that runs notify-send, or an
+// error if notify-send is not installed.
+func NewDesktopNotifyReporter() (*DesktopNotifyReporter, error) {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return nil, fmt.Errorf("notify-send not found (install libnotify): %w", err)
+	}
+	return &DesktopNotifyReporter{path: path}, nil
+}
+
+// notify posts a notification without holding up the encode.
+func (r *DesktopNotifyReporter) notify(urgency, title, body string) {
+	r.wg.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		_ = cmdlog.Run(exec.CommandContext(ctx, r.path, "--app-name=reel", "--urgency="+urgency, title, body))
+	})
+}
+
+// Wait blocks until notifications already posted have been handed to
+// notify-send, so the last one isn't lost when the program exits.
+func (r *DesktopNotifyReporter) Wait() {
+	r.wg.Wait()
+}
+
+func (r *DesktopNotifyReporter) EncodingComplete(summary EncodingOutcome) {
+	reduction := util.CalculateSizeReduction(summary.OriginalSize, summary.EncodedSize)
+	r.notify("normal", "Encoded "+summary.InputFile, fmt.Sprintf("%s -> %s (%.1f%% smaller) in %s",
+		util.FormatBytesReadable(summary.OriginalSize),
+		util.FormatBytesReadable(summary.EncodedSize),
+		reduction,
+		util.FormatDurationFromSecs(int64(summary.TotalTime.Seconds()))))
+}
+
+func (r *DesktopNotifyReporter) ValidationComplete(summary ValidationSummary) {
+	if summary.Passed {
+		return
+	}
+	var failed []string
+	for _, step := range summary.Steps {
+		if !step.Passed {
+			failed = append(failed, step.Name)
+		}
+	}
+	r.notify("critical", "Validation failed", "Failed checks: "+strings.Join(failed, ", "))
+}
+
+func (r *DesktopNotifyReporter) Error(err ReporterError) {
+	body := err.Message
+	if err.Context != "" {
+		body += "\n" + err.Context
+	}
+	r.notify("critical", err.Title, body)
+}
+
+func (r *DesktopNotifyReporter) BatchComplete(summary BatchSummary) {
+	if summary.TotalFiles < 2 {
+		return
+	}
+	urgency := "normal"
+	if len(summary.Failed) > 0 || summary.ValidationFailedCount > 0 {
+		urgency = "critical"
+	}
+	r.notify(urgency, "Batch complete", fmt.Sprintf("%d of %d encoded, %d failed, %d skipped",
+		summary.SuccessfulCount, summary.TotalFiles, len(summary.Failed), len(summary.Skipped)))
+}
@@ -20,6 +20,75 @@ type Reporter interface {
 	Verbose(message string)
 }
 
+// EncodingStartReporter is implemented by reporters that want an encode's
+// chunk count and resume state as it starts. They get EncodingStartedWith in
+// place of EncodingStarted.
+type EncodingStartReporter interface {
+	EncodingStartedWith(start EncodingStart)
+}
+
+// ReportEncodingStarted sends start to r as EncodingStartedWith when r
+// implements EncodingStartReporter, and as EncodingStarted otherwise.
+func ReportEncodingStarted(r Reporter, start EncodingStart) {
+	if sr, ok := r.(EncodingStartReporter); ok {
+		sr.EncodingStartedWith(start)
+		return
+	}
+	r.EncodingStarted(start.TotalFrames)
+}
+
+// EstimateReporter is implemented by reporters that want the projected
+// size and time of an encode run with --estimate.
+type EstimateReporter interface {
+	EncodeEstimate(estimate EncodeEstimate)
+}
+
+// ReportEncodeEstimate sends estimate to r if it implements EstimateReporter.
+func ReportEncodeEstimate(r Reporter, estimate EncodeEstimate) {
+	if o, ok := r.(EstimateReporter); ok {
+		o.EncodeEstimate(estimate)
+	}
+}
+
+// ChunkRetryReporter is implemented by reporters that want to know when a
+// chunk is retried after its encoder was killed.
+type ChunkRetryReporter interface {
+	ChunkRetry(retry ChunkRetry)
+}
+
+// ReportChunkRetry sends retry to r if it implements ChunkRetryReporter.
+func ReportChunkRetry(r Reporter, retry ChunkRetry) {
+	if o, ok := r.(ChunkRetryReporter); ok {
+		o.ChunkRetry(retry)
+	}
+}
+
+// WorkerCapReporter is implemented by reporters that want to know when
+// the worker count is capped or changed to fit available memory.
+type WorkerCapReporter interface {
+	WorkerCap(workers WorkerCap)
+}
+
+// ReportWorkerCap sends workers to r if it implements WorkerCapReporter.
+func ReportWorkerCap(r Reporter, workers WorkerCap) {
+	if o, ok := r.(WorkerCapReporter); ok {
+		o.WorkerCap(workers)
+	}
+}
+
+// TaskReporter is implemented by reporters that want progress within
+// analysis tasks such as indexing, crop detection and audio extraction.
+type TaskReporter interface {
+	TaskProgress(progress TaskProgress)
+}
+
+// ReportTaskProgress sends progress to r if it implements TaskReporter.
+func ReportTaskProgress(r Reporter, progress TaskProgress) {
+	if o, ok := r.(TaskReporter); ok {
+		o.TaskProgress(progress)
+	}
+}
+
 // NullReporter is a no-op reporter that discards all updates.
 type NullReporter struct{}
 
@@ -0,0 +1,39 @@
+package reporter
+
+import "testing"
+
+// startRecorder records EncodingStarted calls.
+type startRecorder struct {
+	NullReporter
+	totalFrames []uint64
+}
+
+func (r *startRecorder) EncodingStarted(totalFrames uint64) {
+	r.totalFrames = append(r.totalFrames, totalFrames)
+}
+
+// detailedStartRecorder also implements EncodingStartReporter.
+type detailedStartRecorder struct {
+	startRecorder
+	starts []EncodingStart
+}
+
+func (r *detailedStartRecorder) EncodingStartedWith(start EncodingStart) {
+	r.starts = append(r.starts, start)
+}
+
+func TestReportEncodingStarted(t *testing.T) {
+	start := EncodingStart{TotalFrames: 1000, TotalChunks: 10, ResumedFrames: 200, ResumedChunks: 2}
+
+	plain := &startRecorder{}
+	ReportEncodingStarted(plain, start)
+	if len(plain.totalFrames) != 1 || plain.totalFrames[0] != 1000 {
+		t.Errorf("plain reporter got EncodingStarted(%v), want 1000", plain.totalFrames)
+	}
+
+	detailed := &detailedStartRecorder{}
+	ReportEncodingStarted(NewCompositeReporter(detailed), start)
+	if len(detailed.starts) != 1 || detailed.starts[0] != start || len(detailed.totalFrames) != 0 {
+		t.Errorf("detailed reporter got %v and EncodingStarted(%v), want only %v", detailed.starts, detailed.totalFrames, start)
+	}
+}
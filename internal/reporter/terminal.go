@@ -3,8 +3,10 @@ package reporter
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/fatih/color"
 	"github.com/five82/reel/internal/util"
@@ -16,8 +18,12 @@ type TerminalReporter struct {
 	mu         sync.Mutex
 	progress   *progressbar.ProgressBar
 	maxPercent float32
+	progDesc   string
 	lastStage  string
-	verbose    bool
+	tasks      []TaskProgress // Unfinished analysis tasks, in start order
+	taskLine   bool           // A task line is on screen
+	tty        bool
+	verbose    atomic.Bool
 	cyan       *color.Color
 	green      *color.Color
 	yellow     *color.Color
@@ -34,8 +40,7 @@ func NewTerminalReporter() *TerminalReporter {
 
 // NewTerminalReporterVerbose creates a new terminal reporter with configurable verbose mode.
 func NewTerminalReporterVerbose(verbose bool) *TerminalReporter {
-	return &TerminalReporter{
-		verbose: verbose,
+	r := &TerminalReporter{
 		cyan:    color.New(color.FgCyan, color.Bold),
 		green:   color.New(color.FgGreen),
 		yellow:  color.New(color.FgYellow, color.Bold),
@@ -44,6 +49,21 @@ func NewTerminalReporterVerbose(verbose bool) *TerminalReporter {
 		bold:    color.New(color.Bold),
 		dim:     color.New(color.Faint),
 	}
+	if fi, err := os.Stderr.Stat(); err == nil {
+		r.tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	r.verbose.Store(verbose)
+	return r
+}
+
+// SetVerbose turns verbose output on or off. Safe to call during an encode.
+func (r *TerminalReporter) SetVerbose(verbose bool) {
+	r.verbose.Store(verbose)
+}
+
+// IsVerbose reports whether verbose output is on.
+func (r *TerminalReporter) IsVerbose() bool {
+	return r.verbose.Load()
 }
 
 func (r *TerminalReporter) finishProgress() {
@@ -54,12 +74,91 @@ func (r *TerminalReporter) finishProgress() {
 		r.progress = nil
 	}
 	r.maxPercent = 0
+	r.progDesc = ""
+}
+
+// taskBarWidth is the width of each analysis task's bar.
+const taskBarWidth = 10
+
+// TaskProgress draws unfinished analysis tasks as bars on one line, or adds
+// them to the encoding bar while it is shown. The line is only drawn when
+// stderr is a terminal.
+func (r *TerminalReporter) TaskProgress(progress TaskProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.tasks, func(t TaskProgress) bool { return t.Task == progress.Task })
+	switch {
+	case progress.Complete() && i >= 0:
+		r.tasks = slices.Delete(r.tasks, i, i+1)
+	case progress.Complete():
+	case i >= 0:
+		r.tasks[i] = progress
+	default:
+		r.tasks = append(r.tasks, progress)
+	}
+
+	if r.progress != nil {
+		r.progress.Describe(r.describe())
+		return
+	}
+	if !r.tty {
+		return
+	}
+	if len(r.tasks) == 0 {
+		r.clearTaskLineLocked()
+		return
+	}
+	parts := make([]string, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		filled := int(t.Percent()) * taskBarWidth / 100
+		parts = append(parts, fmt.Sprintf("%s [%s%s] %3.0f%%", t.Task,
+			strings.Repeat("=", filled), strings.Repeat(" ", taskBarWidth-filled), t.Percent()))
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\r\033[K  %s", strings.Join(parts, "   "))
+	r.taskLine = true
+}
+
+// clearTaskLine erases the task line so other output starts on a clean
+// line; the next task update redraws it.
+func (r *TerminalReporter) clearTaskLine() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clearTaskLineLocked()
+}
+
+func (r *TerminalReporter) clearTaskLineLocked() {
+	if r.taskLine {
+		_, _ = fmt.Fprint(os.Stderr, "\r\033[K")
+		r.taskLine = false
+	}
+}
+
+// describe returns the encoding bar's description followed by any
+// unfinished tasks, such as audio extraction running alongside the encode.
+func (r *TerminalReporter) describe() string {
+	desc := r.progDesc
+	for _, t := range r.tasks {
+		if desc != "" {
+			desc += ", "
+		}
+		desc += fmt.Sprintf("%s %.0f%%", strings.ToLower(t.Task), t.Percent())
+	}
+	return desc
 }
 
 func (r *TerminalReporter) Hardware(summary HardwareSummary) {
 	fmt.Println()
 	_, _ = r.cyan.Println("HARDWARE")
 	r.printLabel("Hostname:", summary.Hostname)
+	r.printLabel("CPU:", formatCPU(summary))
+	r.printLabel("Memory:", formatMemory(summary))
+	r.printLabel("OS:", summary.OS)
+	r.printLabel("SvtAv1EncApp:", summary.SvtVersion)
+	r.printLabel("FFmpeg:", summary.FFmpegVersion)
+	if summary.NUMA != "" {
+		r.printLabel("NUMA:", summary.NUMA)
+	}
 }
 
 // labelWidth is the global width for all labels to ensure consistent alignment.
@@ -81,9 +180,13 @@ func (r *TerminalReporter) Initialization(summary InitializationSummary) {
 	r.printLabel("Resolution:", summary.Resolution)
 	r.printLabel("Dynamic:", summary.DynamicRange)
 	r.printLabel("Audio:", summary.AudioDescription)
+	if summary.ObjectAudio != "" {
+		r.printLabel("Object audio:", summary.ObjectAudio)
+	}
 }
 
 func (r *TerminalReporter) StageProgress(update StageProgress) {
+	r.clearTaskLine()
 	r.mu.Lock()
 	if r.lastStage != update.Stage {
 		r.mu.Unlock()
@@ -97,6 +200,7 @@ func (r *TerminalReporter) StageProgress(update StageProgress) {
 }
 
 func (r *TerminalReporter) CropResult(summary CropSummary) {
+	r.clearTaskLine()
 	var status string
 	if summary.Disabled {
 		status = color.New(color.Faint).Sprint("auto-crop disabled")
@@ -115,6 +219,9 @@ func (r *TerminalReporter) EncodingConfig(summary EncodingConfigSummary) {
 	r.printLabel("Preset:", summary.Preset)
 	r.printLabel("Tune:", summary.Tune)
 	r.printLabel("Quality:", summary.Quality)
+	if summary.Content != "" {
+		r.printLabel("Content:", summary.Content)
+	}
 	r.printLabel("Pixel format:", summary.PixelFormat)
 	r.printLabel("Matrix:", summary.MatrixCoefficients)
 	r.printLabel("Audio codec:", summary.AudioCodec)
@@ -125,12 +232,36 @@ func (r *TerminalReporter) EncodingConfig(summary EncodingConfigSummary) {
 	}
 }
 
+func (r *TerminalReporter) EncodeEstimate(estimate EncodeEstimate) {
+	size := util.FormatBytesReadable(estimate.EstimatedSize)
+	if estimate.OriginalSize > 0 {
+		size = fmt.Sprintf("%s (%.1f%% reduction)", size,
+			util.CalculateSizeReduction(estimate.OriginalSize, estimate.EstimatedSize))
+	}
+	r.printLabel("Est. size:", r.bold.Sprint(size))
+	r.printLabel("Est. bitrate:", fmt.Sprintf("%.0f kbps video", estimate.BitrateKbps))
+	if estimate.EstimatedTime > 0 {
+		r.printLabel("Est. time:", util.FormatDurationFromSecs(int64(estimate.EstimatedTime.Seconds())))
+	}
+	r.printLabel("Based on:", r.dim.Sprintf("%d probe chunks (%d frames)", estimate.ProbeChunks, estimate.ProbeFrames))
+}
+
 func (r *TerminalReporter) EncodingStarted(totalFrames uint64) {
+	r.EncodingStartedWith(EncodingStart{TotalFrames: totalFrames})
+}
+
+func (r *TerminalReporter) EncodingStartedWith(start EncodingStart) {
 	r.finishProgress()
 
+	if start.ResumedChunks > 0 {
+		r.printLabel("Resuming:", fmt.Sprintf("%s (%d/%d chunks already encoded)",
+			r.bold.Sprintf("%.1f%%", start.ResumedPercent()), start.ResumedChunks, start.TotalChunks))
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.clearTaskLineLocked()
 	r.progress = progressbar.NewOptions64(
 		100,
 		progressbar.OptionSetDescription(""),
@@ -149,6 +280,10 @@ func (r *TerminalReporter) EncodingStarted(totalFrames uint64) {
 			BarEnd:        "]",
 		}),
 	)
+
+	// Start from the resumed position so the bar reflects the whole encode
+	r.maxPercent = min(start.ResumedPercent(), 100)
+	_ = r.progress.Set64(int64(r.maxPercent))
 }
 
 func (r *TerminalReporter) EncodingProgress(progress ProgressSnapshot) {
@@ -175,15 +310,20 @@ func (r *TerminalReporter) EncodingProgress(progress ProgressSnapshot) {
 	var desc string
 	if progress.ChunksTotal > 0 {
 		// Chunked encoding: show chunk progress
-		desc = fmt.Sprintf("chunks %d/%d, speed %.1fx, eta %s",
-			progress.ChunksComplete, progress.ChunksTotal,
+		desc = fmt.Sprintf("chunks %d/%d, %.1f fps, speed %.1fx, eta %s",
+			progress.ChunksComplete, progress.ChunksTotal, progress.FPS,
 			progress.Speed, util.FormatDurationFromSecs(int64(progress.ETA.Seconds())))
+		if progress.ProjectedSize > 0 {
+			desc += fmt.Sprintf(", ~%s at %.0f kbps",
+				util.FormatBytesReadable(progress.ProjectedSize), progress.BitrateKbps)
+		}
 	} else {
 		// Traditional encoding: show fps
 		desc = fmt.Sprintf("speed %.1fx, fps %.1f, eta %s",
 			progress.Speed, progress.FPS, util.FormatDurationFromSecs(int64(progress.ETA.Seconds())))
 	}
-	r.progress.Describe(desc)
+	r.progDesc = desc
+	r.progress.Describe(r.describe())
 }
 
 func (r *TerminalReporter) ValidationComplete(summary ValidationSummary) {
@@ -225,14 +365,48 @@ func (r *TerminalReporter) EncodingComplete(summary EncodingOutcome) {
 		util.FormatDurationFromSecs(int64(summary.TotalTime.Seconds())),
 		summary.AverageSpeed))
 	r.printLabel("Saved to:", r.green.Sprint(summary.OutputPath))
+
+	for _, c := range summary.Copies {
+		if c.Success {
+			r.printLabel("Copied to:", fmt.Sprintf("%s %s", r.green.Sprint("✓"), c.Destination))
+		} else {
+			r.printLabel("Copied to:", fmt.Sprintf("%s %s (%s)", r.red.Sprint("✗"), c.Destination, c.Error))
+		}
+	}
+}
+
+func (r *TerminalReporter) ChunkRetry(retry ChunkRetry) {
+	r.Warning(fmt.Sprintf("Chunk %d failed (%s); retrying (%d/%d) in %s",
+		retry.Chunk, retry.Reason, retry.Attempt, retry.Retries, retry.Delay))
+}
+
+func (r *TerminalReporter) WorkerCap(workers WorkerCap) {
+	if workers.Raised {
+		fmt.Println()
+		fmt.Printf("  %s Memory has room; encoding with %s workers\n",
+			r.magenta.Sprint("›"), r.bold.Sprintf("%d/%d", workers.Granted, workers.Requested))
+		return
+	}
+	if workers.Lowered {
+		r.Warning(fmt.Sprintf("Memory running short; encoding with %d/%d workers", workers.Granted, workers.Requested))
+		return
+	}
+	if workers.Available == 0 {
+		r.Verbose(fmt.Sprintf("Memory cap: available memory unknown; using %d workers", workers.Granted))
+		return
+	}
+	r.Verbose(fmt.Sprintf("Memory cap: %d/%d workers at %s each, %s available",
+		workers.Granted, workers.Requested, util.FormatBytes(workers.MemPerWorker), util.FormatBytes(workers.Available)))
 }
 
 func (r *TerminalReporter) Warning(message string) {
+	r.clearTaskLine()
 	fmt.Println()
 	_, _ = r.yellow.Printf("WARN: %s\n", message)
 }
 
 func (r *TerminalReporter) Error(err ReporterError) {
+	r.clearTaskLine()
 	_, _ = fmt.Fprintln(os.Stderr)
 	_, _ = r.red.Fprintf(os.Stderr, "ERROR %s\n", err.Title)
 	_, _ = fmt.Fprintf(os.Stderr, "  %s\n", err.Message)
@@ -278,15 +452,40 @@ func (r *TerminalReporter) BatchComplete(summary BatchSummary) {
 	fmt.Printf("  Time: %s (avg speed %.1fx)\n",
 		util.FormatDurationFromSecs(int64(summary.TotalDuration.Seconds())),
 		summary.AverageSpeed)
+	if summary.CopySucceededCount+summary.CopyFailedCount > 0 {
+		fmt.Printf("  Copies: %s succeeded, %s failed\n",
+			r.green.Sprint(summary.CopySucceededCount),
+			r.red.Sprint(summary.CopyFailedCount))
+	}
+
+	if len(summary.Skipped) > 0 {
+		fmt.Printf("  Skipped: %s (no encodable video)\n", r.yellow.Sprint(len(summary.Skipped)))
+	}
+	if len(summary.Failed) > 0 {
+		fmt.Printf("  Failed: %s\n", r.red.Sprint(len(summary.Failed)))
+	}
+	if len(summary.Deferred) > 0 {
+		fmt.Printf("  Deferred: %s (deadline reached; rerun to continue)\n", r.yellow.Sprint(len(summary.Deferred)))
+	}
 
 	for _, result := range summary.FileResults {
 		fmt.Printf("  - %s (%.1f%% reduction)\n", result.Filename, result.Reduction)
 	}
+	for _, s := range summary.Skipped {
+		fmt.Printf("  - %s %s\n", s.Filename, r.dim.Sprintf("(skipped: %s)", s.Reason))
+	}
+	for _, f := range summary.Failed {
+		fmt.Printf("  - %s %s\n", f.Filename, r.red.Sprintf("(failed: %s)", f.Reason))
+	}
+	for _, name := range summary.Deferred {
+		fmt.Printf("  - %s %s\n", name, r.yellow.Sprint("(deferred)"))
+	}
 }
 
 func (r *TerminalReporter) Verbose(message string) {
-	if !r.verbose {
+	if !r.verbose.Load() {
 		return
 	}
+	r.clearTaskLine()
 	fmt.Printf("  %s %s\n", r.dim.Sprint("›"), r.dim.Sprint(message))
 }
@@ -1,11 +1,26 @@
 // Package reporter provides progress reporting interfaces and implementations.
 package reporter
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/five82/reel/internal/util"
+)
 
 // HardwareSummary contains hardware information.
 type HardwareSummary struct {
-	Hostname string
+	Hostname        string
+	CPUModel        string // Empty if unknown
+	PhysicalCores   int
+	LogicalCores    int
+	TotalMemory     uint64 // Bytes, 0 if unknown
+	AvailableMemory uint64 // Bytes, 0 if unknown
+	OS              string // e.g. "Ubuntu 24.04.1 LTS (amd64)"
+	SvtVersion      string // SvtAv1EncApp version, or why it couldn't be run
+	FFmpegVersion   string
+	NUMANodes       int    // NUMA nodes with CPUs, 0 if unknown
+	NUMA            string // How workers are pinned to nodes, e.g. "2 nodes, pinning workers with numactl"
 }
 
 // InitializationSummary describes the current file before encoding.
@@ -16,6 +31,7 @@ type InitializationSummary struct {
 	Resolution       string
 	DynamicRange     string
 	AudioDescription string
+	ObjectAudio      string // Dolby Atmos and DTS:X streams, e.g. "Dolby Atmos (stream 0)"
 }
 
 // CropSummary contains crop detection results.
@@ -32,6 +48,7 @@ type EncodingConfigSummary struct {
 	Preset             string
 	Tune               string
 	Quality            string
+	Content            string
 	PixelFormat        string
 	MatrixCoefficients string
 	AudioCodec         string
@@ -39,17 +56,36 @@ type EncodingConfigSummary struct {
 	SVTAV1Params       string
 }
 
+// EncodingStart describes an encode that is about to begin. Resumed counts
+// are non-zero when chunks completed by an interrupted run are reused.
+type EncodingStart struct {
+	TotalFrames   uint64
+	TotalChunks   int
+	ResumedFrames uint64
+	ResumedChunks int
+}
+
+// ResumedPercent returns the share of the encode already done before this run.
+func (s EncodingStart) ResumedPercent() float32 {
+	if s.TotalFrames == 0 {
+		return 0
+	}
+	return float32(float64(s.ResumedFrames) / float64(s.TotalFrames) * 100)
+}
+
 // ProgressSnapshot contains encoding progress information.
 type ProgressSnapshot struct {
 	CurrentFrame   uint64
 	TotalFrames    uint64
 	Percent        float32
 	Speed          float32
-	FPS            float32
+	FPS            float32 // Frames per second; over the last minute or so when chunked
 	ETA            time.Duration
 	Bitrate        string
 	ChunksComplete int
 	ChunksTotal    int
+	BitrateKbps    float64 // Average video bitrate of completed chunks
+	ProjectedSize  uint64  // Final output size extrapolated from completed chunks
 }
 
 // ValidationSummary contains validation results.
@@ -61,8 +97,11 @@ type ValidationSummary struct {
 // ValidationStep represents a single validation check.
 type ValidationStep struct {
 	Name    string
+	Check   string // Stable check identifier, e.g. "duration"
+	Code    string // Stable result code, e.g. "ok" or "duration_mismatch"
 	Passed  bool
 	Details string
+	Params  map[string]any // Values behind the result (expected/actual, drift)
 }
 
 // EncodingOutcome contains final encoding results.
@@ -76,6 +115,14 @@ type EncodingOutcome struct {
 	TotalTime    time.Duration
 	AverageSpeed float32
 	OutputPath   string
+	Copies       []CopyOutcome
+}
+
+// CopyOutcome describes copying an output to an additional destination.
+type CopyOutcome struct {
+	Destination string
+	Success     bool
+	Error       string
 }
 
 // ReporterError contains error information.
@@ -110,6 +157,11 @@ type BatchSummary struct {
 	FileResults           []FileResult
 	ValidationPassedCount int
 	ValidationFailedCount int
+	CopySucceededCount    int
+	CopyFailedCount       int
+	Skipped               []SkippedFile // Inputs skipped because they aren't encodable video
+	Failed                []FailedFile  // Other inputs that produced no output
+	Deferred              []string      // Inputs left for a later run by the deadline
 }
 
 // FileResult contains per-file encoding result.
@@ -118,6 +170,52 @@ type FileResult struct {
 	Reduction float64
 }
 
+// SkippedFile is an input that was skipped without being encoded.
+type SkippedFile struct {
+	Filename string
+	Reason   string
+}
+
+// FailedFile is an input that produced no output, with the cause.
+type FailedFile struct {
+	Filename string
+	Reason   string
+}
+
+// EncodeEstimate contains the projected result of an encode, extrapolated
+// from probe chunks encoded before the main encode.
+type EncodeEstimate struct {
+	ProbeChunks   int
+	ProbeFrames   int
+	OriginalSize  uint64
+	EstimatedSize uint64        // Projected output size including audio
+	BitrateKbps   float64       // Projected video bitrate
+	EstimatedTime time.Duration // Projected video encode time (0 if unknown)
+}
+
+// ChunkRetry describes a chunk being retried after its encoder was killed.
+type ChunkRetry struct {
+	Chunk   int           // Chunk index
+	Attempt int           // Retry number, starting at 1
+	Retries int           // Configured retry limit
+	Threads int           // Threads per worker for the retry
+	Delay   time.Duration // Wait before the retry
+	Reason  string        // Why the previous attempt failed
+}
+
+// WorkerCap describes how many workers fit in available memory. It is
+// reported when an encode starts and again whenever the encode scales its
+// workers: up while memory has room (Raised), down under memory pressure
+// (Lowered).
+type WorkerCap struct {
+	Requested    int    // Workers asked for
+	Granted      int    // Workers allowed to encode at once
+	MemPerWorker uint64 // Estimated memory per worker, measured once scaling
+	Available    uint64 // Measured available memory (0 if unknown)
+	Raised       bool   // More workers started mid-encode
+	Lowered      bool   // Workers retired mid-encode
+}
+
 // StageProgress represents a generic stage update.
 type StageProgress struct {
 	Stage   string
@@ -125,3 +223,61 @@ type StageProgress struct {
 	Message string
 	ETA     *time.Duration
 }
+
+// TaskProgress reports how far an analysis task (indexing, crop detection,
+// audio extraction) or a file transfer has got, in the task's own units.
+type TaskProgress struct {
+	Task  string // "Indexing", "Crop detection", "Audio extraction", "Staging source", "Copying output" or "Checksum"
+	Done  uint64
+	Total uint64
+	Unit  string // "bytes", "samples" or "seconds"
+}
+
+// Percent returns how much of the task is done, from 0 to 100.
+func (p TaskProgress) Percent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return min(100, float64(p.Done)/float64(p.Total)*100)
+}
+
+// Complete reports whether the task has finished.
+func (p TaskProgress) Complete() bool {
+	return p.Total > 0 && p.Done >= p.Total
+}
+
+// formatTask describes a task's progress, with counts unless they are bytes.
+func formatTask(p TaskProgress) string {
+	s := fmt.Sprintf("%s %.0f%%", p.Task, p.Percent())
+	if p.Unit != "bytes" {
+		s += fmt.Sprintf(" (%d/%d %s)", p.Done, p.Total, p.Unit)
+	}
+	return s
+}
+
+// taskStep reports whether a task update is worth a log line: each quarter
+// of the way through.
+func taskStep(p TaskProgress) bool {
+	return p.Complete() || (p.Done > 0 && int(p.Percent())%25 == 0)
+}
+
+// formatCPU describes the CPU model and core counts of a HardwareSummary.
+func formatCPU(s HardwareSummary) string {
+	model := s.CPUModel
+	if model == "" {
+		model = "Unknown CPU"
+	}
+	return fmt.Sprintf("%s (%d cores, %d threads)", model, s.PhysicalCores, s.LogicalCores)
+}
+
+// formatMemory describes the total and available memory of a HardwareSummary.
+func formatMemory(s HardwareSummary) string {
+	if s.TotalMemory == 0 {
+		return "unknown"
+	}
+	if s.AvailableMemory == 0 {
+		return util.FormatBytesReadable(s.TotalMemory) + " total"
+	}
+	return fmt.Sprintf("%s total, %s available",
+		util.FormatBytesReadable(s.TotalMemory), util.FormatBytesReadable(s.AvailableMemory))
+}
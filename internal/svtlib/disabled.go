@@ -0,0 +1,52 @@
+//go:build !svtlib
+
+// Package svtlib links libSvtAv1Enc to encode chunks in-process, instead of
+// running an SvtAv1EncApp process per chunk and piping frames through its
+// stdin. It is only linked when reel is built with the svtlib tag.
+package svtlib
+
+import (
+	"errors"
+
+	"github.com/five82/reel/internal/encoder"
+)
+
+// Linked reports whether libSvtAv1Enc is linked into this build.
+const Linked = false
+
+// errNotLinked is returned by Open in builds without the svtlib tag.
+var errNotLinked = errors.New("libSvtAv1Enc is not linked; build with -tags svtlib")
+
+// Version returns the linked libSvtAv1Enc version; empty as none is linked.
+func Version() string {
+	return ""
+}
+
+// Config describes the stream an Encoder encodes.
+type Config struct {
+	Width, Height  uint32
+	FPSNum, FPSDen uint32
+	BitDepth       int                // 8 or 10; 10-bit samples take two bytes
+	Params         []encoder.LibParam // From encoder.LibParams
+}
+
+// Encoder is an in-process libSvtAv1Enc encoder for one chunk.
+type Encoder struct{}
+
+// Open fails, as libSvtAv1Enc is not linked.
+func Open(Config) (*Encoder, error) {
+	return nil, errNotLinked
+}
+
+// Send fails, as libSvtAv1Enc is not linked.
+func (e *Encoder) Send([]byte, func([]byte, int64) error) error {
+	return errNotLinked
+}
+
+// Finish fails, as libSvtAv1Enc is not linked.
+func (e *Encoder) Finish(func([]byte, int64) error) error {
+	return errNotLinked
+}
+
+// Close does nothing.
+func (e *Encoder) Close() {}
@@ -0,0 +1,188 @@
+//go:build svtlib
+
+// Package svtlib links libSvtAv1Enc to encode chunks in-process, instead of
+// running an SvtAv1EncApp process per chunk and piping frames through its
+// stdin. It is only linked when reel is built with the svtlib tag.
+package svtlib
+
+/*
+#cgo pkg-config: SvtAv1Enc
+#include <stdlib.h>
+#include <string.h>
+#include <svt-av1/EbSvtAv1Enc.h>
+
+// Helper for the init_handle signature, which lost its app data argument in 3.0
+static EbErrorType reel_svt_init_handle(EbComponentType **handle, EbSvtAv1EncConfiguration *cfg) {
+#if SVT_AV1_CHECK_VERSION(3, 0, 0)
+	return svt_av1_enc_init_handle(handle, cfg);
+#else
+	return svt_av1_enc_init_handle(handle, NULL, cfg);
+#endif
+}
+
+// Helper to send one planar YUV 4:2:0 frame of bytes_per_sample samples,
+// or end of stream if frame is NULL. The library copies the frame.
+static EbErrorType reel_svt_send(EbComponentType *handle, uint8_t *frame, uint32_t width, uint32_t height,
+		int bytes_per_sample, int64_t pts) {
+	EbBufferHeaderType hdr;
+	memset(&hdr, 0, sizeof(hdr));
+	hdr.size = sizeof(hdr);
+	if (frame == NULL) {
+		hdr.flags = EB_BUFFERFLAG_EOS;
+		return svt_av1_enc_send_picture(handle, &hdr);
+	}
+
+	size_t luma = (size_t)width * height * bytes_per_sample;
+	EbSvtIOFormat io;
+	memset(&io, 0, sizeof(io));
+	io.luma = frame;
+	io.cb = frame + luma;
+	io.cr = frame + luma + luma / 4;
+	io.y_stride = width;
+	io.cb_stride = width / 2;
+	io.cr_stride = width / 2;
+
+	hdr.p_buffer = (uint8_t *)&io;
+	hdr.n_filled_len = luma * 3 / 2;
+	hdr.n_alloc_len = hdr.n_filled_len;
+	hdr.pts = pts;
+	hdr.pic_type = EB_AV1_INVALID_PICTURE;
+	return svt_av1_enc_send_picture(handle, &hdr);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/five82/reel/internal/encoder"
+)
+
+// Linked reports whether libSvtAv1Enc is linked into this build.
+const Linked = true
+
+// Version returns the linked libSvtAv1Enc version, e.g. "v2.3.0".
+func Version() string {
+	return C.GoString(C.svt_av1_get_version())
+}
+
+// Config describes the stream an Encoder encodes.
+type Config struct {
+	Width, Height  uint32
+	FPSNum, FPSDen uint32
+	BitDepth       int                // 8 or 10; 10-bit samples take two bytes
+	Params         []encoder.LibParam // From encoder.LibParams
+}
+
+// Encoder is an in-process libSvtAv1Enc encoder for one chunk.
+type Encoder struct {
+	handle         *C.EbComponentType
+	width, height  uint32
+	bytesPerSample int
+	pts            int64
+}
+
+// Open creates and initializes an encoder for cfg.
+func Open(cfg Config) (*Encoder, error) {
+	var handle *C.EbComponentType
+	var svtCfg C.EbSvtAv1EncConfiguration
+	if err := C.reel_svt_init_handle(&handle, &svtCfg); err != C.EB_ErrorNone {
+		return nil, fmt.Errorf("failed to create encoder: error 0x%x", uint32(err))
+	}
+	e := &Encoder{handle: handle, width: cfg.Width, height: cfg.Height, bytesPerSample: 1}
+
+	svtCfg.source_width = C.uint32_t(cfg.Width)
+	svtCfg.source_height = C.uint32_t(cfg.Height)
+	svtCfg.frame_rate_numerator = C.uint32_t(cfg.FPSNum)
+	svtCfg.frame_rate_denominator = C.uint32_t(cfg.FPSDen)
+	svtCfg.encoder_bit_depth = C.uint32_t(cfg.BitDepth)
+	svtCfg.encoder_color_format = C.EB_YUV420
+	if cfg.BitDepth > 8 {
+		e.bytesPerSample = 2
+	}
+	for _, p := range cfg.Params {
+		name, value := C.CString(p.Name), C.CString(p.Value)
+		err := C.svt_av1_enc_parse_parameter(&svtCfg, name, value)
+		C.free(unsafe.Pointer(name))
+		C.free(unsafe.Pointer(value))
+		if err != C.EB_ErrorNone {
+			_ = C.svt_av1_enc_deinit_handle(handle)
+			return nil, fmt.Errorf("invalid encoder parameter --%s %s", p.Name, p.Value)
+		}
+	}
+
+	if err := C.svt_av1_enc_set_parameter(handle, &svtCfg); err != C.EB_ErrorNone {
+		_ = C.svt_av1_enc_deinit_handle(handle)
+		return nil, fmt.Errorf("encoder rejected its configuration: error 0x%x", uint32(err))
+	}
+	if err := C.svt_av1_enc_init(handle); err != C.EB_ErrorNone {
+		_ = C.svt_av1_enc_deinit_handle(handle)
+		return nil, fmt.Errorf("failed to initialize encoder: error 0x%x", uint32(err))
+	}
+	return e, nil
+}
+
+// Send encodes frame, a planar YUV 4:2:0 picture, and passes the packets
+// that are ready to write.
+func (e *Encoder) Send(frame []byte, write func(packet []byte, pts int64) error) error {
+	if err := C.reel_svt_send(e.handle, (*C.uint8_t)(unsafe.Pointer(&frame[0])), C.uint32_t(e.width),
+		C.uint32_t(e.height), C.int(e.bytesPerSample), C.int64_t(e.pts)); err != C.EB_ErrorNone {
+		return fmt.Errorf("failed to send frame %d: error 0x%x", e.pts, uint32(err))
+	}
+	e.pts++
+	_, err := e.drain(false, write)
+	return err
+}
+
+// Finish signals the end of the stream and passes the remaining packets to
+// write.
+func (e *Encoder) Finish(write func(packet []byte, pts int64) error) error {
+	if err := C.reel_svt_send(e.handle, nil, 0, 0, 0, 0); err != C.EB_ErrorNone {
+		return fmt.Errorf("failed to end stream: error 0x%x", uint32(err))
+	}
+	for {
+		done, err := e.drain(true, write)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// drain passes encoded packets to write until none is ready, or, once all
+// frames are sent, until the end of the stream, which it reports.
+func (e *Encoder) drain(sendDone bool, write func(packet []byte, pts int64) error) (bool, error) {
+	var done C.uint8_t
+	if sendDone {
+		done = 1
+	}
+	for {
+		var pkt *C.EbBufferHeaderType
+		err := C.svt_av1_enc_get_packet(e.handle, &pkt, done)
+		if err == C.EB_NoErrorEmptyQueue {
+			return false, nil
+		}
+		if err != C.EB_ErrorNone {
+			return false, fmt.Errorf("encoding failed: error 0x%x", uint32(err))
+		}
+		eos := pkt.flags&C.EB_BUFFERFLAG_EOS != 0
+		var writeErr error
+		if pkt.n_filled_len > 0 {
+			data := unsafe.Slice((*byte)(unsafe.Pointer(pkt.p_buffer)), int(pkt.n_filled_len))
+			writeErr = write(data, int64(pkt.pts))
+		}
+		C.svt_av1_enc_release_out_buffer(&pkt)
+		if writeErr != nil {
+			return false, writeErr
+		}
+		if eos {
+			return true, nil
+		}
+	}
+}
+
+// Close releases the encoder.
+func (e *Encoder) Close() {
+	_ = C.svt_av1_enc_deinit(e.handle)
+	_ = C.svt_av1_enc_deinit_handle(e.handle)
+}
@@ -0,0 +1,67 @@
+// Package toolpath resolves the binaries of the external tools reel runs,
+// which can be pinned to explicit paths instead of being looked up in PATH,
+// for example to choose between svt-av1-psy and mainline SVT-AV1 builds.
+package toolpath
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// External tools whose binary can be pinned.
+const (
+	FFmpeg       = "ffmpeg"
+	FFprobe      = "ffprobe"
+	MediaInfo    = "mediainfo"
+	SvtAv1EncApp = "SvtAv1EncApp"
+)
+
+// Tools lists the tools whose binary can be pinned.
+var Tools = []string{FFmpeg, FFprobe, MediaInfo, SvtAv1EncApp}
+
+// Paths are the binaries to run for each tool, keyed by lowercase tool
+// name. They are resolved once with Resolve and passed to the code that
+// runs the tools, so encodes with different pins can run side by side. A
+// nil Paths runs each tool from PATH.
+type Paths map[string]string
+
+// Known reports whether tool names a tool in Tools, in any case.
+func Known(tool string) bool {
+	return slices.ContainsFunc(Tools, func(t string) bool { return strings.EqualFold(t, tool) })
+}
+
+// EnvVar returns the environment variable that pins tool, e.g. REEL_FFMPEG.
+func EnvVar(tool string) string {
+	return "REEL_" + strings.ToUpper(tool)
+}
+
+// Resolve returns the binary to run for each tool in Tools:
+// $REEL_<TOOL> if set, else the pin in pins (keyed by tool name in any
+// case), else the tool's name for a PATH lookup.
+func Resolve(pins map[string]string) Paths {
+	pinned := make(map[string]string, len(pins))
+	for tool, path := range pins {
+		pinned[strings.ToLower(tool)] = path
+	}
+	paths := make(Paths, len(Tools))
+	for _, tool := range Tools {
+		path := os.Getenv(EnvVar(tool))
+		if path == "" {
+			path = pinned[strings.ToLower(tool)]
+		}
+		if path != "" {
+			paths[strings.ToLower(tool)] = path
+		}
+	}
+	return paths
+}
+
+// Path returns the binary to run for tool, or the tool's name for a PATH
+// lookup when p doesn't pin it.
+func (p Paths) Path(tool string) string {
+	if path := p[strings.ToLower(tool)]; path != "" {
+		return path
+	}
+	return tool
+}
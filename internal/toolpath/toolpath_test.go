@@ -0,0 +1,47 @@
+package toolpath
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	t.Setenv("REEL_FFPROBE", "/env/ffprobe")
+	paths := Resolve(map[string]string{"svtav1encapp": "/opt/psy/SvtAv1EncApp", "ffprobe": "/opt/ff/ffprobe"})
+
+	tests := []struct {
+		tool string
+		want string
+	}{
+		{SvtAv1EncApp, "/opt/psy/SvtAv1EncApp"}, // pinned, matched in any case
+		{FFprobe, "/env/ffprobe"},               // environment wins
+		{FFmpeg, "ffmpeg"},                      // PATH lookup
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			if got := paths.Path(tt.tool); got != tt.want {
+				t.Errorf("Path(%q) = %q, want %q", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIsIndependent(t *testing.T) {
+	mainline := Resolve(map[string]string{SvtAv1EncApp: "/usr/bin/SvtAv1EncApp"})
+	psy := Resolve(map[string]string{SvtAv1EncApp: "/opt/psy/SvtAv1EncApp"})
+	if got := mainline.Path(SvtAv1EncApp); got != "/usr/bin/SvtAv1EncApp" {
+		t.Errorf("Path() = %q after resolving other pins, want the first encode's binary", got)
+	}
+	if got := psy.Path(SvtAv1EncApp); got != "/opt/psy/SvtAv1EncApp" {
+		t.Errorf("Path() = %q, want the second encode's binary", got)
+	}
+	if got := Paths(nil).Path(FFmpeg); got != FFmpeg {
+		t.Errorf("nil Paths Path() = %q, want %q", got, FFmpeg)
+	}
+}
+
+func TestKnown(t *testing.T) {
+	if !Known("svtav1encapp") || !Known("ffmpeg") {
+		t.Error("Known() = false for a known tool")
+	}
+	if Known("x264") {
+		t.Error("Known(x264) = true")
+	}
+}
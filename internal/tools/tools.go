@@ -0,0 +1,268 @@
+// Package tools detects external tool versions and the features they support.
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
+	"github.com/five82/reel/internal/ffms"
+	"github.com/five82/reel/internal/opuslib"
+	"github.com/five82/reel/internal/svtlib"
+	"github.com/five82/reel/internal/toolpath"
+)
+
+// ErrDependencyMissing is returned when a required external tool is missing,
+// fails to run, or is older than the supported minimum.
+var ErrDependencyMissing = errors.New("required dependency missing")
+
+// Minimum versions reel is tested against. Older builds are rejected.
+var (
+	MinSvtVersion    = Version{Major: 2, Minor: 0, Patch: 0, Known: true}
+	MinFFmpegVersion = Version{Major: 5, Minor: 0, Patch: 0, Known: true}
+)
+
+// Mainline SVT-AV1 versions that introduced optional parameters.
+// The svt-av1-psy fork has carried these since before they were upstreamed.
+var (
+	svtACBiasVersion        = Version{Major: 3, Minor: 0, Patch: 0, Known: true}
+	svtVarianceBoostVersion = Version{Major: 2, Minor: 0, Patch: 0, Known: true}
+)
+
+var versionRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Version is a parsed tool version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	Known bool   // False when the version could not be parsed (e.g. git builds)
+	Fork  string // Fork name when detected, e.g. "PSY" for svt-av1-psy
+	Raw   string // First line of the tool's version output
+}
+
+// String returns the version as major.minor.patch, or the raw string if unknown.
+func (v Version) String() string {
+	if !v.Known {
+		if v.Raw != "" {
+			return v.Raw
+		}
+		return "unknown"
+	}
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Fork != "" {
+		s += " (" + v.Fork + ")"
+	}
+	return s
+}
+
+// AtLeast reports whether v is greater than or equal to min.
+// Unknown versions are assumed to be recent enough.
+func (v Version) AtLeast(min Version) bool {
+	if !v.Known {
+		return true
+	}
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+// parseVersion extracts the first dotted version number from s.
+func parseVersion(s string) Version {
+	v := Version{Raw: s}
+	m := versionRegex.FindStringSubmatch(s)
+	if m == nil {
+		return v
+	}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	v.Known = true
+	return v
+}
+
+// firstLine returns the first non-empty line of output.
+func firstLine(output string) string {
+	for line := range strings.SplitSeq(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// ParseSvtVersion parses `SvtAv1EncApp --version` output,
+// e.g. "SVT-AV1 v2.3.0 (release)" or "SVT-AV1-PSY v2.2.1-A (release)".
+func ParseSvtVersion(output string) Version {
+	line := firstLine(output)
+	v := parseVersion(line)
+	if strings.Contains(strings.ToUpper(line), "PSY") {
+		v.Fork = "PSY"
+	}
+	return v
+}
+
+// ParseFFmpegVersion parses `ffmpeg -version` output, e.g. "ffmpeg version 7.1 ...".
+// Git snapshot builds ("ffmpeg version N-113456-g...") have no release number
+// and are reported as unknown.
+func ParseFFmpegVersion(output string) Version {
+	line := firstLine(output)
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[1] != "version" {
+		return Version{Raw: line}
+	}
+	if strings.HasPrefix(fields[2], "N-") {
+		return Version{Raw: line}
+	}
+	v := parseVersion(strings.TrimPrefix(fields[2], "n"))
+	v.Raw = line
+	return v
+}
+
+// SvtVersion runs SvtAv1EncApp and returns its version, or returns the
+// version of the linked libSvtAv1Enc in builds that encode in-process.
+func SvtVersion(paths toolpath.Paths) (Version, error) {
+	if svtlib.Linked {
+		return ParseSvtVersion("SVT-AV1 " + svtlib.Version()), nil
+	}
+	out, err := cmdlog.CombinedOutput(exec.Command(paths.Path(toolpath.SvtAv1EncApp), "--version"))
+	if err != nil {
+		return Version{}, fmt.Errorf("%w: SvtAv1EncApp not found or failed to run: %w", ErrDependencyMissing, err)
+	}
+	return ParseSvtVersion(string(out)), nil
+}
+
+// FFmpegVersion runs ffmpeg and returns its version.
+func FFmpegVersion(paths toolpath.Paths) (Version, error) {
+	out, err := cmdlog.CombinedOutput(exec.Command(paths.Path(toolpath.FFmpeg), "-hide_banner", "-version"))
+	if err != nil {
+		return Version{}, fmt.Errorf("%w: ffmpeg not found or failed to run: %w", ErrDependencyMissing, err)
+	}
+	return ParseFFmpegVersion(string(out)), nil
+}
+
+// SvtFeatures lists optional SvtAv1EncApp parameters supported by a build.
+type SvtFeatures struct {
+	ACBias        bool // --ac-bias
+	VarianceBoost bool // --enable-variance-boost, --variance-boost-strength, --variance-octile
+}
+
+// Features returns the optional parameters supported by an SvtAv1EncApp version.
+func (v Version) Features() SvtFeatures {
+	if v.Fork == "PSY" {
+		return SvtFeatures{ACBias: true, VarianceBoost: true}
+	}
+	return SvtFeatures{
+		ACBias:        v.AtLeast(svtACBiasVersion),
+		VarianceBoost: v.AtLeast(svtVarianceBoostVersion),
+	}
+}
+
+// EncoderCheck contains the detected encoder tool versions.
+type EncoderCheck struct {
+	Svt      Version
+	FFmpeg   Version
+	Features SvtFeatures
+}
+
+// CheckEncoders detects SvtAv1EncApp and ffmpeg and verifies minimum versions.
+func CheckEncoders(paths toolpath.Paths) (*EncoderCheck, error) {
+	svt, err := SvtVersion(paths)
+	if err != nil {
+		return nil, err
+	}
+	if !svt.AtLeast(MinSvtVersion) {
+		return nil, fmt.Errorf("%w: SvtAv1EncApp %s is too old (minimum %s)", ErrDependencyMissing, svt, MinSvtVersion)
+	}
+
+	ff, err := FFmpegVersion(paths)
+	if err != nil {
+		return nil, err
+	}
+	if !ff.AtLeast(MinFFmpegVersion) {
+		return nil, fmt.Errorf("%w: ffmpeg %s is too old (minimum %s)", ErrDependencyMissing, ff, MinFFmpegVersion)
+	}
+
+	return &EncoderCheck{Svt: svt, FFmpeg: ff, Features: svt.Features()}, nil
+}
+
+// ToolInfo describes an installed external tool for logging.
+type ToolInfo struct {
+	Name      string
+	Version   string // Version line, or the error if the tool could not be run
+	Build     string // Build configuration, when the tool reports one
+	Available bool   // False when the tool could not be run
+}
+
+// Inventory returns version and build details for every external tool reel uses.
+// Missing tools are included with the error in place of a version.
+func Inventory(paths toolpath.Paths) []ToolInfo {
+	tools := []ToolInfo{
+		svtInfo(paths),
+		describe(paths, toolpath.FFmpeg, []string{"-hide_banner", "-version"}, ffmpegBuild),
+		describe(paths, toolpath.FFprobe, []string{"-hide_banner", "-version"}, ffmpegBuild),
+		describe(paths, toolpath.MediaInfo, []string{"--Version"}, nil),
+		{Name: "FFMS2", Version: ffms.Version(), Available: true},
+	}
+	if opuslib.Linked {
+		tools = append(tools, ToolInfo{Name: "libopus", Version: opuslib.Version() + " (linked)", Available: true})
+	}
+	return tools
+}
+
+// svtInfo describes SvtAv1EncApp, or the linked libSvtAv1Enc in builds that
+// encode in-process.
+func svtInfo(paths toolpath.Paths) ToolInfo {
+	if svtlib.Linked {
+		return ToolInfo{Name: "libSvtAv1Enc", Version: svtlib.Version() + " (linked)", Available: true}
+	}
+	return describe(paths, toolpath.SvtAv1EncApp, []string{"--version"}, nil)
+}
+
+// describe runs a tool's version command and extracts its version line and,
+// if build is non-nil, its build configuration.
+func describe(paths toolpath.Paths, name string, args []string, build func(string) string) ToolInfo {
+	info := ToolInfo{Name: name}
+	out, err := cmdlog.CombinedOutput(exec.Command(paths.Path(name), args...))
+	if err != nil {
+		info.Version = fmt.Sprintf("unavailable (%v)", err)
+		return info
+	}
+	info.Version = versionLine(string(out))
+	info.Available = true
+	if build != nil {
+		info.Build = build(string(out))
+	}
+	return info
+}
+
+// versionLine returns the first line of tool output containing a version number,
+// falling back to the first line. mediainfo prints a banner before its version.
+func versionLine(output string) string {
+	for line := range strings.SplitSeq(output, "\n") {
+		if line = strings.TrimSpace(line); versionRegex.MatchString(line) {
+			return line
+		}
+	}
+	return firstLine(output)
+}
+
+// ffmpegBuild extracts the configure flags from ffmpeg/ffprobe -version output.
+func ffmpegBuild(output string) string {
+	for line := range strings.SplitSeq(output, "\n") {
+		if cfg, ok := strings.CutPrefix(strings.TrimSpace(line), "configuration:"); ok {
+			return strings.TrimSpace(cfg)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package tools
+
+import "testing"
+
+func TestParseSvtVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+		fork   string
+	}{
+		{"SVT-AV1 v2.3.0 (release)\n", "2.3.0", ""},
+		{"SVT-AV1-PSY v2.2.1-A (release)", "2.2.1 (PSY)", "PSY"},
+		{"SVT-AV1 v3.0.0-12-gabcdef (release)", "3.0.0", ""},
+		{"\nSVT-AV1 v1.8 (release)", "1.8.0", ""},
+	}
+
+	for _, tt := range tests {
+		v := ParseSvtVersion(tt.output)
+		if !v.Known {
+			t.Errorf("ParseSvtVersion(%q) not parsed", tt.output)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("ParseSvtVersion(%q) = %s, want %s", tt.output, v, tt.want)
+		}
+		if v.Fork != tt.fork {
+			t.Errorf("ParseSvtVersion(%q) fork = %q, want %q", tt.output, v.Fork, tt.fork)
+		}
+	}
+}
+
+func TestParseFFmpegVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		known  bool
+		want   string
+	}{
+		{"ffmpeg version 7.1 Copyright (c) 2000-2024 the FFmpeg developers", true, "7.1.0"},
+		{"ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023", true, "6.1.1"},
+		{"ffmpeg version n6.0 Copyright", true, "6.0.0"},
+		{"ffmpeg version N-113456-g1234abcd Copyright", false, ""},
+		{"garbage", false, ""},
+	}
+
+	for _, tt := range tests {
+		v := ParseFFmpegVersion(tt.output)
+		if v.Known != tt.known {
+			t.Errorf("ParseFFmpegVersion(%q).Known = %v, want %v", tt.output, v.Known, tt.known)
+			continue
+		}
+		if tt.known && v.String() != tt.want {
+			t.Errorf("ParseFFmpegVersion(%q) = %s, want %s", tt.output, v, tt.want)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	min := Version{Major: 2, Minor: 1, Patch: 0, Known: true}
+
+	tests := []struct {
+		v    Version
+		want bool
+	}{
+		{Version{Major: 2, Minor: 1, Patch: 0, Known: true}, true},
+		{Version{Major: 2, Minor: 0, Patch: 9, Known: true}, false},
+		{Version{Major: 3, Minor: 0, Patch: 0, Known: true}, true},
+		{Version{Major: 1, Minor: 9, Patch: 9, Known: true}, false},
+		{Version{}, true}, // Unknown versions are assumed recent
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.AtLeast(min); got != tt.want {
+			t.Errorf("%s.AtLeast(%s) = %v, want %v", tt.v, min, got, tt.want)
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	mainline2 := ParseSvtVersion("SVT-AV1 v2.3.0 (release)").Features()
+	if mainline2.ACBias {
+		t.Error("mainline 2.3.0 should not support --ac-bias")
+	}
+	if !mainline2.VarianceBoost {
+		t.Error("mainline 2.3.0 should support variance boost")
+	}
+
+	mainline3 := ParseSvtVersion("SVT-AV1 v3.0.0 (release)").Features()
+	if !mainline3.ACBias || !mainline3.VarianceBoost {
+		t.Errorf("mainline 3.0.0 features = %+v, want all supported", mainline3)
+	}
+
+	psy := ParseSvtVersion("SVT-AV1-PSY v2.2.1-A (release)").Features()
+	if !psy.ACBias || !psy.VarianceBoost {
+		t.Errorf("psy features = %+v, want all supported", psy)
+	}
+}
+
+func TestVersionLine(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"SVT-AV1 v2.3.0 (release)\n", "SVT-AV1 v2.3.0 (release)"},
+		{"MediaInfo Command line, \nMediaInfoLib - v24.01\n", "MediaInfoLib - v24.01"},
+		{"ffmpeg version 7.1 Copyright\nbuilt with gcc 14\n", "ffmpeg version 7.1 Copyright"},
+	}
+
+	for _, tt := range tests {
+		if got := versionLine(tt.output); got != tt.want {
+			t.Errorf("versionLine(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestFFmpegBuild(t *testing.T) {
+	output := "ffmpeg version 7.1\nbuilt with gcc 14\nconfiguration: --enable-gpl --enable-libopus\nlibavutil 59.39.100\n"
+	if got := ffmpegBuild(output); got != "--enable-gpl --enable-libopus" {
+		t.Errorf("ffmpegBuild() = %q", got)
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"math/bits"
+
+	"golang.org/x/sys/unix"
+)
+
+// PinThread restricts the calling OS thread to cpus. Callers lock the
+// goroutine to its thread first; processes it starts inherit the affinity.
+func PinThread(cpus []int) error {
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(0, &set)
+}
+
+// AllowedCPUs returns the CPUs the process may run on, as limited by
+// taskset or a cpuset, or nil when the mask can't be read.
+func AllowedCPUs() []int {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &set); err != nil {
+		return nil
+	}
+	var cpus []int
+	for cpu := range len(set) * bits.UintSize {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
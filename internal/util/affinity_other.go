@@ -0,0 +1,17 @@
+//go:build !linux
+
+package util
+
+import "errors"
+
+// PinThread restricts the calling OS thread to cpus. CPU affinity is only
+// supported on Linux.
+func PinThread(cpus []int) error {
+	return errors.New("CPU affinity is not supported on this platform")
+}
+
+// AllowedCPUs returns the CPUs the process may run on. CPU affinity is only
+// supported on Linux, so it always returns nil.
+func AllowedCPUs() []int {
+	return nil
+}
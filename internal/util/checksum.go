@@ -0,0 +1,60 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// newHash returns the hash for a checksum algorithm.
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unknown checksum algorithm %q", algorithm)
+}
+
+// FileChecksum returns the hex digest of the file at path, reporting the
+// bytes read so far, if progress is non-nil, and stopping when ctx is
+// cancelled.
+func FileChecksum(ctx context.Context, path, algorithm string, progress func(done, total uint64)) (string, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	r := &progressReader{ctx: ctx, r: f, total: uint64(info.Size()), progress: progress}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ChecksumPath returns the sidecar path holding the digest of path, e.g.
+// movie.mkv.sha256.
+func ChecksumPath(path, algorithm string) string {
+	return path + "." + algorithm
+}
+
+// WriteChecksumFile writes the digest of path to its sidecar in the format
+// sha256sum -c reads, naming the file relative to the sidecar so the pair
+// can be moved together.
+func WriteChecksumFile(path, algorithm, digest string) error {
+	line := fmt.Sprintf("%s  %s\n", digest, filepath.Base(path))
+	return os.WriteFile(ChecksumPath(path, algorithm), []byte(line), 0o666)
+}
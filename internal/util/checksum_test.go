@@ -0,0 +1,40 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "movie.mkv")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	got, err := FileChecksum(context.Background(), path, "sha256", nil)
+	if err != nil {
+		t.Fatalf("FileChecksum failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("FileChecksum() = %s, want %s", got, want)
+	}
+
+	if _, err := FileChecksum(context.Background(), path, "md4", nil); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+
+	if err := WriteChecksumFile(path, "sha256", got); err != nil {
+		t.Fatalf("WriteChecksumFile failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "movie.mkv.sha256"))
+	if err != nil {
+		t.Fatalf("checksum file not written: %v", err)
+	}
+	if line := want + "  movie.mkv\n"; string(data) != line {
+		t.Errorf("checksum file = %q, want %q", data, line)
+	}
+}
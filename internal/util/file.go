@@ -1,38 +1,45 @@
 package util
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
-// VideoExtensions is the list of supported video file extensions.
-var VideoExtensions = map[string]bool{
-	".mkv":  true,
-	".wmv":  true,
-	".ts":   true,
-	".avi":  true,
-	".mp4":  true,
-	".m4v":  true,
-	".mpg":  true,
-	".mpeg": true,
-	".mov":  true,
-	".webm": true,
-	".flv":  true,
-	".m2ts": true,
-	".ogv":  true,
-	".vob":  true,
-}
-
-// IsVideoFile checks if the given path is a valid video file.
-func IsVideoFile(path string) bool {
+// DefaultVideoExtensions are the file extensions recognized as video unless
+// the config file sets its own list.
+var DefaultVideoExtensions = []string{
+	".mkv", ".wmv", ".ts", ".avi", ".mp4", ".m4v", ".mpg",
+	".mpeg", ".mov", ".webm", ".flv", ".m2ts", ".ogv", ".vob",
+}
+
+// NormalizeExtension lowercases ext and adds a leading dot if missing.
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// HasVideoExtension reports whether path has one of the given extensions,
+// ignoring case.
+func HasVideoExtension(path string, extensions []string) bool {
+	return slices.Contains(extensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// IsVideoFile checks if the given path is a file with one of the given extensions.
+func IsVideoFile(path string, extensions []string) bool {
 	info, err := os.Stat(path)
 	if err != nil || info.IsDir() {
 		return false
 	}
-
-	ext := strings.ToLower(filepath.Ext(path))
-	return VideoExtensions[ext]
+	return HasVideoExtension(path, extensions)
 }
 
 // GetFilename returns the filename from a path.
@@ -73,13 +80,158 @@ func FileExists(path string) bool {
 	return err == nil && !info.IsDir()
 }
 
-// ResolveOutputPath determines the output path for an encoded file.
-func ResolveOutputPath(inputPath, outputDir string, targetOverride string) string {
+// CopyFile copies src to dst, writing to a temporary file first so a partial
+// copy never appears under the final name.
+func CopyFile(src, dst string) error {
+	return CopyFileProgress(context.Background(), src, dst, nil)
+}
+
+// CopyFileProgress is CopyFile reporting the bytes copied so far, if
+// progress is non-nil, and stopping when ctx is cancelled.
+func CopyFileProgress(ctx context.Context, src, dst string, progress func(done, total uint64)) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := dst + ".partial"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	r := &progressReader{ctx: ctx, r: in, total: uint64(info.Size()), progress: progress}
+	if _, err = io.Copy(out, r); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, dst)
+}
+
+// progressReader reports the bytes read through it and fails once its
+// context is cancelled.
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	done     uint64
+	total    uint64
+	progress func(done, total uint64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.done += uint64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
+
+// MoveFile moves src to dst: a rename on the same filesystem, otherwise a
+// copy with progress, as for CopyFileProgress, and removal of src.
+func MoveFile(ctx context.Context, src, dst string, progress func(done, total uint64)) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := CopyFileProgress(ctx, src, dst, progress); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// SidecarFiles returns files next to path whose names start with the full
+// filename of path followed by a dot (e.g. movie.mkv.sha256).
+func SidecarFiles(path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	prefix := filepath.Base(path) + "."
+	var sidecars []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		sidecars = append(sidecars, filepath.Join(filepath.Dir(path), entry.Name()))
+	}
+	return sidecars
+}
+
+// WaitForGrowth blocks until the file at path is no longer size bytes,
+// returning true, or has not been modified for at least quiet, returning
+// false. The modification time is checked against both the file's timestamp
+// and the time the change was seen, so a skewed network clock can't stall
+// the wait. It checks every poll interval.
+func WaitForGrowth(ctx context.Context, path string, size uint64, quiet, poll time.Duration) (bool, error) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var lastMod time.Time
+	changedAt := time.Now()
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if uint64(info.Size()) != size {
+			return true, nil
+		}
+		if !info.ModTime().Equal(lastMod) {
+			if !lastMod.IsZero() {
+				changedAt = time.Now()
+			}
+			lastMod = info.ModTime()
+		}
+		if time.Since(lastMod) >= quiet || time.Since(changedAt) >= quiet {
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ResolveOutputPath determines the output path for an encoded file. An
+// absolute targetOverride is used as-is; a relative one is placed in outputDir.
+// Otherwise the name is template expanded with fields, or the source name
+// with .mkv when there is no template. fields.Name defaults to the source
+// name.
+func ResolveOutputPath(inputPath, outputDir, targetOverride, template string, fields OutputFields) string {
+	if filepath.IsAbs(targetOverride) {
+		return targetOverride
+	}
 	if targetOverride != "" {
 		return filepath.Join(outputDir, targetOverride)
 	}
 	stem := GetFileStem(inputPath)
-	return filepath.Join(outputDir, stem+".mkv")
+	if template == "" {
+		return filepath.Join(outputDir, stem+".mkv")
+	}
+	if fields.Name == "" {
+		fields.Name = stem
+	}
+	return filepath.Join(outputDir, ExpandOutputTemplate(template, fields))
 }
 
 // OutputPathInfo contains resolved output path information.
@@ -125,3 +277,17 @@ func ResolveOutputArg(inputPath, outputPath string) (OutputPathInfo, error) {
 		FilenameOverride: "",
 	}, nil
 }
+
+// CopyTimes gives dst the modification time of src, and its access time
+// too when withAccess is set; otherwise dst's access time becomes now.
+func CopyTimes(src, dst string, withAccess bool) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	atime := time.Now()
+	if withAccess {
+		atime = accessTime(info)
+	}
+	return os.Chtimes(dst, atime, info.ModTime())
+}
@@ -0,0 +1,195 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mkv")
+	if err := os.WriteFile(src, []byte("video data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "dst.mkv")
+	if err := CopyFile(src, dst); err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("destination not created: %v", err)
+	}
+	if string(data) != "video data" {
+		t.Errorf("destination content = %q, want %q", data, "video data")
+	}
+	if FileExists(dst + ".partial") {
+		t.Error("partial file should not remain after copy")
+	}
+
+	if err := CopyFile(filepath.Join(dir, "missing.mkv"), dst); err == nil {
+		t.Error("expected error for missing source")
+	}
+}
+
+func TestCopyFileProgress(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mkv")
+	if err := os.WriteFile(src, []byte("video data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var done, total uint64
+	err := CopyFileProgress(context.Background(), src, filepath.Join(dir, "dst.mkv"), func(d, t uint64) {
+		done, total = d, t
+	})
+	if err != nil {
+		t.Fatalf("CopyFileProgress failed: %v", err)
+	}
+	if done != 10 || total != 10 {
+		t.Errorf("last progress = %d/%d, want 10/10", done, total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dst := filepath.Join(dir, "cancelled.mkv")
+	if err := CopyFileProgress(ctx, src, dst, nil); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+	if FileExists(dst) || FileExists(dst+".partial") {
+		t.Error("cancelled copy should leave no file behind")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mkv")
+	if err := os.WriteFile(src, []byte("video data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "out", "dst.mkv")
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(context.Background(), src, dst, nil); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if FileExists(src) {
+		t.Error("source should be gone after move")
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "video data" {
+		t.Errorf("destination = %q, %v; want %q", data, err, "video data")
+	}
+}
+
+func TestSidecarFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"movie.mkv", "movie.mkv.sha256", "movie.mkv.reel.json", "movie.nfo", "other.mkv.sha256"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := SidecarFiles(filepath.Join(dir, "movie.mkv"))
+	if len(got) != 2 {
+		t.Fatalf("SidecarFiles() returned %d files, want 2: %v", len(got), got)
+	}
+	for _, p := range got {
+		base := filepath.Base(p)
+		if base != "movie.mkv.sha256" && base != "movie.mkv.reel.json" {
+			t.Errorf("unexpected sidecar %s", base)
+		}
+	}
+}
+
+func TestWaitForGrowth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growing.mkv")
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return
+		}
+		_, _ = f.WriteString("more")
+		_ = f.Close()
+	}()
+
+	grew, err := WaitForGrowth(context.Background(), path, 1, time.Hour, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForGrowth() error = %v", err)
+	}
+	if !grew {
+		t.Error("WaitForGrowth() = false, want true for a growing file")
+	}
+}
+
+func TestWaitForGrowthQuiet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripped.mkv")
+	if err := os.WriteFile(path, []byte("done"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	grew, err := WaitForGrowth(context.Background(), path, 4, 50*time.Millisecond, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForGrowth() error = %v", err)
+	}
+	if grew {
+		t.Error("WaitForGrowth() = true, want false for a file that stopped growing")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want about the quiet period", elapsed)
+	}
+}
+
+func TestWaitForGrowthCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.mkv")
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WaitForGrowth(ctx, path, 1, time.Hour, time.Millisecond); err == nil {
+		t.Error("expected error from cancelled context")
+	}
+}
+
+func TestCopyTimes(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src.mkv"), filepath.Join(dir, "dst.mkv")
+	for _, p := range []string{src, dst} {
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	atime := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	mtime := time.Date(2018, 3, 14, 20, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(src, atime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyTimes(src, dst, true); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+	if got := accessTime(info); runtime.GOOS == "linux" && !got.Equal(atime) {
+		t.Errorf("atime = %v, want %v", got, atime)
+	}
+}
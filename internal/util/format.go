@@ -28,6 +28,30 @@ func FormatBytes(bytes uint64) string {
 	}
 }
 
+// ParseBytes parses a size such as "3G", "512M", "1.5GiB" or "4096" (bytes).
+// Units are binary: K, M and G mean KiB, MiB and GiB.
+func ParseBytes(s string) (uint64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	num = strings.TrimSuffix(strings.TrimSuffix(num, "B"), "I")
+	unit := uint64(1)
+	switch {
+	case strings.HasSuffix(num, "K"):
+		unit = KiB
+	case strings.HasSuffix(num, "M"):
+		unit = MiB
+	case strings.HasSuffix(num, "G"):
+		unit = GiB
+	}
+	if unit > 1 {
+		num = num[:len(num)-1]
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || val <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive size like 3G or 512M", s)
+	}
+	return uint64(val * float64(unit)), nil
+}
+
 // FormatBytesReadable formats bytes showing both MB and GB values.
 func FormatBytesReadable(bytes uint64) string {
 	bf := float64(bytes)
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NUMANode is a NUMA node and the logical CPUs on it.
+type NUMANode struct {
+	ID      int
+	CPUs    []int
+	CPUList string // As in sysfs, e.g. "0-15,32-47"
+}
+
+// NUMANodes returns the NUMA nodes that have CPUs the process may run on,
+// read from sysfs and limited to its affinity mask. Returns nil when the
+// topology can't be read (including off Linux).
+func NUMANodes() []NUMANode {
+	return numaNodesFrom("/sys/devices/system/node", AllowedCPUs())
+}
+
+// numaNodesFrom reads the nodes under nodeDir, keeping only the CPUs in
+// allowed unless it is nil.
+func numaNodesFrom(nodeDir string, allowed []int) []NUMANode {
+	entries, err := os.ReadDir(nodeDir)
+	if err != nil {
+		return nil
+	}
+
+	var nodes []NUMANode
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), "node")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(nodeDir, entry.Name(), "cpulist"))
+		if err != nil {
+			continue
+		}
+		list := strings.TrimSpace(string(data))
+		cpus, err := ParseCPUList(list)
+		if err != nil {
+			continue
+		}
+		if allowed != nil {
+			cpus = slices.DeleteFunc(cpus, func(cpu int) bool { return !slices.Contains(allowed, cpu) })
+			list = FormatCPUList(cpus)
+		}
+		if len(cpus) == 0 {
+			continue // Memory-only node, or none of its CPUs are allowed
+		}
+		nodes = append(nodes, NUMANode{ID: id, CPUs: cpus, CPUList: list})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// ParseCPUList parses a Linux CPU list such as "0-3,8,10-11".
+func ParseCPUList(list string) ([]int, error) {
+	var cpus []int
+	if list == "" {
+		return cpus, nil
+	}
+	for part := range strings.SplitSeq(list, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(hi)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid CPU list %q", list)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// FormatCPUList formats ascending cpus as a Linux CPU list, the inverse of
+// ParseCPUList.
+func FormatCPUList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
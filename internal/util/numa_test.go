@@ -0,0 +1,90 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"0", []int{0}, false},
+		{"0-3", []int{0, 1, 2, 3}, false},
+		{"0-1,8,10-11", []int{0, 1, 8, 10, 11}, false},
+		{"3-1", nil, true},
+		{"a", nil, true},
+		{"0,", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCPUList(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCPUList(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseCPUList(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestNUMANodesFrom(t *testing.T) {
+	dir := t.TempDir()
+	for name, cpulist := range map[string]string{
+		"node1": "4-7\n",
+		"node0": "0-3\n",
+		"node2": "\n", // Memory only
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "cpulist"), []byte(cpulist), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "possible"), []byte("0-2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := numaNodesFrom(dir, nil)
+	if len(nodes) != 2 {
+		t.Fatalf("numaNodesFrom() returned %d nodes, want 2", len(nodes))
+	}
+	if nodes[0].ID != 0 || nodes[0].CPUList != "0-3" || nodes[1].ID != 1 || !slices.Equal(nodes[1].CPUs, []int{4, 5, 6, 7}) {
+		t.Errorf("numaNodesFrom() = %+v", nodes)
+	}
+
+	if nodes := numaNodesFrom(filepath.Join(dir, "missing"), nil); nodes != nil {
+		t.Errorf("numaNodesFrom(missing) = %+v, want nil", nodes)
+	}
+
+	// Under taskset 1-2,8 node 0 keeps two CPUs and node 1 none
+	nodes = numaNodesFrom(dir, []int{1, 2, 8})
+	if len(nodes) != 1 || nodes[0].ID != 0 || nodes[0].CPUList != "1-2" || !slices.Equal(nodes[0].CPUs, []int{1, 2}) {
+		t.Errorf("numaNodesFrom(allowed) = %+v", nodes)
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	tests := []struct {
+		cpus []int
+		want string
+	}{
+		{nil, ""},
+		{[]int{0}, "0"},
+		{[]int{0, 1, 2, 3}, "0-3"},
+		{[]int{0, 1, 8, 10, 11}, "0-1,8,10-11"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatCPUList(tt.cpus); got != tt.want {
+			t.Errorf("FormatCPUList(%v) = %q, want %q", tt.cpus, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputFields are the values an output filename template can use.
+type OutputFields struct {
+	Name       string    // Source filename without extension
+	Resolution string    // Resolution tier, e.g. "1080p" (see ResolutionLabel)
+	CRF        uint8     // CRF the file is encoded at
+	Date       time.Time // Encode date
+}
+
+// OutputTokens lists the tokens an output filename template can use.
+var OutputTokens = []string{"name", "resolution", "crf", "codec", "date", "ext"}
+
+// outputTokenPattern matches "{token}" and "{token:options}".
+var outputTokenPattern = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// ValidateOutputTemplate checks that an output filename template such as
+// "{name}.av1.{ext}" uses only known tokens, names each file after its
+// source with {name}, and stays inside the output directory. {name} takes
+// replacements as "{name:x264=AV1,h264=AV1}".
+func ValidateOutputTemplate(template string) error {
+	if !strings.Contains(template, "{name") {
+		return fmt.Errorf("output template %q must contain {name}", template)
+	}
+	for _, m := range outputTokenPattern.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(OutputTokens, m[1]) {
+			return fmt.Errorf("unknown output template token {%s} (available: %s)", m[1], strings.Join(OutputTokens, ", "))
+		}
+		if m[2] != "" && m[1] != "name" {
+			return fmt.Errorf("output template token {%s} takes no options", m[1])
+		}
+		if m[1] == "name" && m[2] != "" {
+			for r := range strings.SplitSeq(m[2], ",") {
+				if old, _, ok := strings.Cut(r, "="); !ok || old == "" {
+					return fmt.Errorf("invalid replacement %q in {name}: want OLD=NEW", r)
+				}
+			}
+		}
+	}
+	if filepath.IsAbs(template) || slices.Contains(strings.Split(filepath.ToSlash(template), "/"), "..") {
+		return fmt.Errorf("output template %q must stay inside the output directory", template)
+	}
+	return nil
+}
+
+// OutputTemplateNeedsVideo reports whether template uses values that take
+// probing the source: its resolution or the CRF chosen for it.
+func OutputTemplateNeedsVideo(template string) bool {
+	return strings.Contains(template, "{resolution}") || strings.Contains(template, "{crf}")
+}
+
+// ExpandOutputTemplate substitutes fields into a template checked by
+// ValidateOutputTemplate. Tokens are replaced in the template only, so a
+// source name containing braces is kept as it is.
+func ExpandOutputTemplate(template string, f OutputFields) string {
+	return outputTokenPattern.ReplaceAllStringFunc(template, func(token string) string {
+		m := outputTokenPattern.FindStringSubmatch(token)
+		switch m[1] {
+		case "name":
+			return replaceInName(f.Name, m[2])
+		case "resolution":
+			return f.Resolution
+		case "crf":
+			return strconv.Itoa(int(f.CRF))
+		case "codec":
+			return "av1"
+		case "date":
+			return f.Date.Format(time.DateOnly)
+		case "ext":
+			return "mkv"
+		}
+		return token
+	})
+}
+
+// replaceInName applies "OLD=NEW,OLD=NEW" replacements to name, matching
+// OLD without regard to case.
+func replaceInName(name, replacements string) string {
+	if replacements == "" {
+		return name
+	}
+	for r := range strings.SplitSeq(replacements, ",") {
+		old, repl, _ := strings.Cut(r, "=")
+		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(old))
+		name = re.ReplaceAllLiteralString(name, repl)
+	}
+	return name
+}
+
+// ResolutionLabel names the resolution tier of a video: 2160p, 1080p or
+// 720p by width, so a cropped picture keeps its tier, otherwise its height.
+func ResolutionLabel(width, height uint32) string {
+	switch {
+	case width >= 3840:
+		return "2160p"
+	case width >= 1920:
+		return "1080p"
+	case width >= 1280:
+		return "720p"
+	default:
+		return fmt.Sprintf("%dp", height)
+	}
+}
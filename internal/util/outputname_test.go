@@ -0,0 +1,81 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateOutputTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{"{name}.av1.{ext}", false},
+		{"{resolution}/{name} [CRF {crf}].{ext}", false},
+		{"{name:x264=AV1,h264=AV1}.{ext}", false},
+		{"{codec}.{ext}", true},
+		{"{name}.{title}.{ext}", true},
+		{"{name}.{crf:x=y}.{ext}", true},
+		{"{name:x264}.{ext}", true},
+		{"../{name}.{ext}", true},
+		{"/tmp/{name}.{ext}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			err := ValidateOutputTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOutputTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveOutputPath(t *testing.T) {
+	fields := OutputFields{
+		Resolution: "1080p",
+		CRF:        27,
+		Date:       time.Date(2026, 3, 14, 22, 0, 0, 0, time.Local),
+	}
+
+	tests := []struct {
+		name     string
+		override string
+		template string
+		want     string
+	}{
+		{"source name", "", "", "/out/Movie.2020.x264.mkv"},
+		{"absolute override", "/elsewhere/film.mkv", "{name}.av1.{ext}", "/elsewhere/film.mkv"},
+		{"relative override", "film.mp4", "{name}.av1.{ext}", "/out/film.mp4"},
+		{"suffix", "", "{name}.av1.{ext}", "/out/Movie.2020.x264.av1.mkv"},
+		{"replacement ignores case", "", "{name:X264=AV1}.{ext}", "/out/Movie.2020.AV1.mkv"},
+		{"all tokens", "", "{resolution}/{name}.{codec}.crf{crf}.{date}.{ext}", "/out/1080p/Movie.2020.x264.av1.crf27.2026-03-14.mkv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveOutputPath("/in/Movie.2020.x264.mkv", "/out", tt.override, tt.template, fields)
+			if got != tt.want {
+				t.Errorf("ResolveOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolutionLabel(t *testing.T) {
+	tests := []struct {
+		width, height uint32
+		want          string
+	}{
+		{3840, 1600, "2160p"},
+		{1920, 800, "1080p"},
+		{1280, 720, "720p"},
+		{720, 576, "576p"},
+	}
+
+	for _, tt := range tests {
+		if got := ResolutionLabel(tt.width, tt.height); got != tt.want {
+			t.Errorf("ResolutionLabel(%d, %d) = %q, want %q", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
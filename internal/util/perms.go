@@ -0,0 +1,142 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OutputPerms are the permissions and ownership given to output files and
+// the directories created for them. The zero value leaves them as created,
+// with the mode the process umask allows.
+type OutputPerms struct {
+	Mode  os.FileMode // Permission bits for files; 0 leaves them as created
+	Chown bool        // Set the owner to UID and GID
+	UID   int         // -1 keeps the owner
+	GID   int         // -1 keeps the group
+}
+
+// ParseFileMode parses an octal permission mode such as "640" or "0664".
+func ParseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode == 0 || mode > 0o777 {
+		return 0, fmt.Errorf("invalid mode %q: want octal permissions such as 644", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// ParseOwner parses "user", "user:group" or ":group", by name or numeric
+// ID, into a UID and GID, with -1 for the part not given.
+func ParseOwner(s string) (uid, gid int, err error) {
+	name, group, _ := strings.Cut(s, ":")
+	if name == "" && group == "" {
+		return 0, 0, fmt.Errorf("invalid owner %q: want user, user:group or :group", s)
+	}
+	uid, gid = -1, -1
+	if name != "" {
+		if uid, err = lookupID(name, func(n string) (string, error) {
+			u, err := user.Lookup(n)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		}); err != nil {
+			return 0, 0, fmt.Errorf("unknown user %q: %w", name, err)
+		}
+	}
+	if group != "" {
+		if gid, err = lookupID(group, func(n string) (string, error) {
+			g, err := user.LookupGroup(n)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		}); err != nil {
+			return 0, 0, fmt.Errorf("unknown group %q: %w", group, err)
+		}
+	}
+	return uid, gid, nil
+}
+
+// lookupID returns a numeric ID as is, or looks a name up.
+func lookupID(s string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil && id >= 0 {
+		return id, nil
+	}
+	id, err := lookup(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// IsSet reports whether p changes anything.
+func (p OutputPerms) IsSet() bool {
+	return p.Mode != 0 || p.Chown
+}
+
+// ApplyFile gives the file at path the mode and owner.
+func (p OutputPerms) ApplyFile(path string) error {
+	return p.apply(path, p.Mode)
+}
+
+// apply sets mode, unless it's 0, and the owner of path.
+func (p OutputPerms) apply(path string, mode os.FileMode) error {
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	}
+	if p.Chown {
+		if err := os.Chown(path, p.UID, p.GID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirMode is the directory mode matching the file mode: each class that
+// can read files can also list the directory, and the owner can always
+// write to it.
+func (p OutputPerms) dirMode() os.FileMode {
+	mode := p.Mode | 0o700
+	for _, read := range []os.FileMode{0o400, 0o040, 0o004} {
+		if mode&read != 0 {
+			mode |= read >> 2
+		}
+	}
+	return mode
+}
+
+// EnsureOutputDirectory creates an output directory and its missing
+// parents like mkdir -p, subject to the umask, and gives the directories
+// it created the perms' owner and a mode matching their file mode.
+// Existing directories are left alone.
+func EnsureOutputDirectory(path string, perms OutputPerms) error {
+	var created []string
+	for dir := filepath.Clean(path); !DirectoryExists(dir); dir = filepath.Dir(dir) {
+		created = append(created, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	if err := os.MkdirAll(path, 0o777); err != nil {
+		return err
+	}
+	if !perms.IsSet() {
+		return nil
+	}
+	mode := os.FileMode(0)
+	if perms.Mode != 0 {
+		mode = perms.dirMode()
+	}
+	for _, dir := range created {
+		if err := perms.apply(dir, mode); err != nil {
+			return fmt.Errorf("failed to set permissions on %s: %w", dir, err)
+		}
+	}
+	return nil
+}
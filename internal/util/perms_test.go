@@ -0,0 +1,93 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{"644", 0o644, false},
+		{"0664", 0o664, false},
+		{"640", 0o640, false},
+		{"0", 0, true},
+		{"888", 0, true},
+		{"1777", 0, true},
+		{"rw-r--r--", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFileMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFileMode(%q) = %o, %v; want %o, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseOwnerNumeric(t *testing.T) {
+	tests := []struct {
+		in       string
+		uid, gid int
+	}{
+		{"1000:1001", 1000, 1001},
+		{"1000", 1000, -1},
+		{":1001", -1, 1001},
+	}
+
+	for _, tt := range tests {
+		uid, gid, err := ParseOwner(tt.in)
+		if err != nil || uid != tt.uid || gid != tt.gid {
+			t.Errorf("ParseOwner(%q) = %d, %d, %v; want %d, %d", tt.in, uid, gid, err, tt.uid, tt.gid)
+		}
+	}
+	if _, _, err := ParseOwner(":"); err == nil {
+		t.Error("ParseOwner(\":\") succeeded")
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	tests := []struct {
+		file, dir os.FileMode
+	}{
+		{0o640, 0o750},
+		{0o664, 0o775},
+		{0o600, 0o700},
+		{0o444, 0o755},
+	}
+
+	for _, tt := range tests {
+		if got := (OutputPerms{Mode: tt.file}).dirMode(); got != tt.dir {
+			t.Errorf("dirMode(%o) = %o, want %o", tt.file, got, tt.dir)
+		}
+	}
+}
+
+func TestEnsureOutputDirectory(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Chmod(root, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "a", "b")
+
+	if err := EnsureOutputDirectory(path, OutputPerms{Mode: 0o640}); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{filepath.Join(root, "a"), path} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != 0o750 {
+			t.Errorf("%s mode = %o, want 750", dir, got)
+		}
+	}
+	// Existing directories keep their mode
+	if info, _ := os.Stat(root); info.Mode().Perm() != 0o700 {
+		t.Errorf("existing directory mode changed to %o", info.Mode().Perm())
+	}
+}
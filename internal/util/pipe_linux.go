@@ -0,0 +1,33 @@
+package util
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// GrowPipe raises the capacity of pipe f towards size, capped at the
+// system's pipe-max-size, and returns the capacity it ends up with. A pipe
+// that holds whole frames takes each in one write instead of sixteen 64 KiB
+// ones, with a context switch to the reader between each.
+func GrowPipe(f *os.File, size int) int {
+	if data, err := os.ReadFile("/proc/sys/fs/pipe-max-size"); err == nil {
+		if limit, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			size = min(size, limit)
+		}
+	}
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return 0
+	}
+	var capacity int
+	_ = conn.Control(func(fd uintptr) {
+		capacity, err = unix.FcntlInt(fd, unix.F_SETPIPE_SZ, size)
+		if err != nil {
+			capacity, _ = unix.FcntlInt(fd, unix.F_GETPIPE_SZ, 0)
+		}
+	})
+	return capacity
+}
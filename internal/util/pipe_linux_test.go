@@ -0,0 +1,20 @@
+package util
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGrowPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	defer func() { _ = w.Close() }()
+
+	// 256 KiB is within the default pipe-max-size of 1 MiB
+	if got := GrowPipe(w, 256<<10); got < 256<<10 {
+		t.Errorf("GrowPipe() = %d, want at least %d", got, 256<<10)
+	}
+}
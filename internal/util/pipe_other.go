@@ -0,0 +1,11 @@
+//go:build !linux
+
+package util
+
+import "os"
+
+// GrowPipe leaves the pipe as it is; pipe capacity can only be set on
+// Linux. It returns 0 as the capacity isn't known.
+func GrowPipe(f *os.File, size int) int {
+	return 0
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package util
+
+import "os/exec"
+
+// KillGroupOnCancel leaves cmd killed on its own when its context is
+// cancelled; process groups are a Unix feature.
+func KillGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package util
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// KillGroupOnCancel starts cmd, made with exec.CommandContext, in a process
+// group of its own, and makes cancelling its context kill the whole group
+// rather than only the process started. A terminal's Ctrl+C then no longer
+// reaches the command directly, leaving the caller to decide when it stops.
+func KillGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ProcMemory is the resident memory of a process.
+type ProcMemory struct {
+	Pid  int
+	Name string // Command name, as in /proc/<pid>/comm (at most 15 bytes)
+	RSS  uint64 // Resident set size in bytes
+}
+
+// ProcessMemory returns the resident memory of process pid, or false if it
+// has exited.
+func ProcessMemory(pid int) (ProcMemory, bool) {
+	dir := "/proc/" + strconv.Itoa(pid)
+	statm, err := os.ReadFile(dir + "/statm")
+	if err != nil {
+		return ProcMemory{}, false
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return ProcMemory{}, false
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return ProcMemory{}, false
+	}
+	comm, _ := os.ReadFile(dir + "/comm")
+	return ProcMemory{Pid: pid, Name: strings.TrimSpace(string(comm)), RSS: pages * uint64(os.Getpagesize())}, true
+}
+
+// ChildMemory returns the resident memory of every running descendant of
+// process pid.
+func ChildMemory(pid int) []ProcMemory {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	children := make(map[int][]int)
+	for _, e := range entries {
+		child, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if parent, ok := parentPid(child); ok {
+			children[parent] = append(children[parent], child)
+		}
+	}
+
+	var mems []ProcMemory
+	pending := children[pid]
+	for len(pending) > 0 {
+		p := pending[len(pending)-1]
+		pending = append(pending[:len(pending)-1], children[p]...)
+		if m, ok := ProcessMemory(p); ok {
+			mems = append(mems, m)
+		}
+	}
+	return mems
+}
+
+// parentPid returns the parent of process pid from /proc/<pid>/stat.
+func parentPid(pid int) (int, bool) {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, false
+	}
+	// The command name is in parentheses and may hold spaces or
+	// parentheses itself; the state and parent pid follow the last one
+	s := string(stat)
+	end := strings.LastIndexByte(s, ')')
+	if end < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 2 {
+		return 0, false
+	}
+	parent, err := strconv.Atoi(fields[1])
+	return parent, err == nil
+}
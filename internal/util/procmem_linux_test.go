@@ -0,0 +1,40 @@
+package util
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProcessMemory(t *testing.T) {
+	m, ok := ProcessMemory(os.Getpid())
+	if !ok || m.RSS == 0 || m.Name == "" {
+		t.Errorf("ProcessMemory(self) = %+v, %v, want a named process with resident memory", m, ok)
+	}
+	if _, ok := ProcessMemory(-1); ok {
+		t.Error("ProcessMemory(-1) found a process")
+	}
+}
+
+func TestChildMemory(t *testing.T) {
+	// A grandchild, to check descendants are found beyond direct children
+	cmd := exec.Command("sh", "-c", "sleep 10 & wait")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	hasSleep := func(m ProcMemory) bool { return m.Name == "sleep" }
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.ContainsFunc(ChildMemory(os.Getpid()), hasSleep) {
+		if time.Now().After(deadline) {
+			t.Fatalf("ChildMemory() = %+v, want the sleep grandchild", ChildMemory(os.Getpid()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
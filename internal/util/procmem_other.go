@@ -0,0 +1,21 @@
+//go:build !linux
+
+package util
+
+// ProcMemory is the resident memory of a process.
+type ProcMemory struct {
+	Pid  int
+	Name string
+	RSS  uint64
+}
+
+// ProcessMemory reports nothing; resident memory is read from /proc, which
+// only Linux has.
+func ProcessMemory(pid int) (ProcMemory, bool) {
+	return ProcMemory{}, false
+}
+
+// ChildMemory reports no children outside Linux.
+func ChildMemory(pid int) []ProcMemory {
+	return nil
+}
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily time window such as 22:00-07:00. Windows whose end is
+// earlier than their start span midnight.
+type Window struct {
+	Start time.Duration // Offset from midnight
+	End   time.Duration // Offset from midnight
+}
+
+// ParseWindow parses a window in "HH:MM-HH:MM" form.
+func ParseWindow(s string) (Window, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid schedule %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(strings.TrimSpace(startStr))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid schedule %q: %w", s, err)
+	}
+	end, err := parseClock(strings.TrimSpace(endStr))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid schedule %q: %w", s, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid schedule %q: start and end are the same", s)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// parseClock parses HH:MM as an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the window in HH:MM-HH:MM form.
+func (w Window) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// Contains reports whether t falls inside the window.
+func (w Window) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// NextStart returns the next time at or after t when the window opens.
+func (w Window) NextStart(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := midnight.Add(w.Start)
+	if start.Before(t) {
+		start = midnight.AddDate(0, 0, 1).Add(w.Start)
+	}
+	return start
+}
+
+// Wait blocks until the window is open. onPause is called with the resume
+// time if waiting is needed.
+func (w Window) Wait(ctx context.Context, onPause func(resume time.Time)) error {
+	now := time.Now()
+	if w.Contains(now) {
+		return nil
+	}
+	resume := w.NextStart(now)
+	if onPause != nil {
+		onPause(resume)
+	}
+
+	timer := time.NewTimer(time.Until(resume))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sinceMidnight returns the wall-clock offset of t from its local midnight.
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"22:00-07:00", "22:00-07:00", false},
+		{"09:30-17:00", "09:30-17:00", false},
+		{" 1:05 - 2:00 ", "01:05-02:00", false},
+		{"22:00", "", true},
+		{"25:00-07:00", "", true},
+		{"08:00-08:00", "", true},
+	}
+
+	for _, tt := range tests {
+		w, err := ParseWindow(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWindow(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && w.String() != tt.want {
+			t.Errorf("ParseWindow(%q) = %s, want %s", tt.input, w, tt.want)
+		}
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	overnight, _ := ParseWindow("22:00-07:00")
+	daytime, _ := ParseWindow("09:00-17:00")
+	at := func(h, m int) time.Time { return time.Date(2024, 5, 1, h, m, 0, 0, time.Local) }
+
+	tests := []struct {
+		w    Window
+		t    time.Time
+		want bool
+	}{
+		{overnight, at(23, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 0), false},
+		{overnight, at(12, 0), false},
+		{overnight, at(22, 0), true},
+		{daytime, at(9, 0), true},
+		{daytime, at(16, 59), true},
+		{daytime, at(17, 0), false},
+		{daytime, at(2, 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.w.Contains(tt.t); got != tt.want {
+			t.Errorf("%s.Contains(%s) = %v, want %v", tt.w, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestWindowNextStart(t *testing.T) {
+	w, _ := ParseWindow("22:00-07:00")
+
+	morning := time.Date(2024, 5, 1, 8, 0, 0, 0, time.Local)
+	if got, want := w.NextStart(morning), time.Date(2024, 5, 1, 22, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("NextStart(08:00) = %v, want %v", got, want)
+	}
+
+	late := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	if got, want := w.NextStart(late), time.Date(2024, 5, 2, 22, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("NextStart(23:00) = %v, want %v", got, want)
+	}
+}
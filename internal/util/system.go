@@ -2,12 +2,15 @@ package util
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/five82/reel/internal/cmdlog"
 )
 
 // SystemInfo contains information about the host system.
@@ -16,6 +19,8 @@ type SystemInfo struct {
 	NumCPU   int
 	OS       string
 	Arch     string
+	OSName   string // Distribution or product name and version, e.g. "Ubuntu 24.04.1 LTS"
+	CPUModel string // Empty if unknown
 }
 
 // GetSystemInfo collects system information.
@@ -26,23 +31,103 @@ func GetSystemInfo() SystemInfo {
 		NumCPU:   runtime.NumCPU(),
 		OS:       runtime.GOOS,
 		Arch:     runtime.GOARCH,
+		OSName:   osName(),
+		CPUModel: cpuModel(),
+	}
+}
+
+// osName returns the OS distribution name and version, falling back to
+// runtime.GOOS.
+func osName() string {
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/etc/os-release"); err == nil {
+			if name := parseOSRelease(string(data)); name != "" {
+				return name
+			}
+		}
+	case "darwin":
+		if out, err := cmdlog.Output(exec.Command("sw_vers", "-productVersion")); err == nil {
+			return "macOS " + strings.TrimSpace(string(out))
+		}
+	}
+	return runtime.GOOS
+}
+
+// parseOSRelease returns PRETTY_NAME from os-release content.
+func parseOSRelease(content string) string {
+	for line := range strings.SplitSeq(content, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+// cpuModel returns the CPU model name, or "" if it cannot be determined.
+func cpuModel() string {
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+			return parseCPUModel(string(data))
+		}
+	case "darwin":
+		if out, err := cmdlog.Output(exec.Command("sysctl", "-n", "machdep.cpu.brand_string")); err == nil {
+			return strings.TrimSpace(string(out))
+		}
+	}
+	return ""
+}
+
+// parseCPUModel returns the first "model name" from /proc/cpuinfo content.
+func parseCPUModel(cpuinfo string) string {
+	for line := range strings.SplitSeq(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
 	}
+	return ""
 }
 
 // AvailableMemoryBytes returns the available memory in bytes.
 // On Linux, this reads MemAvailable from /proc/meminfo.
 // Returns 0 if memory cannot be determined.
 func AvailableMemoryBytes() uint64 {
+	return meminfoBytes("MemAvailable")
+}
+
+// TotalMemoryBytes returns the installed memory in bytes, from MemTotal in
+// /proc/meminfo on Linux and hw.memsize on macOS. Returns 0 if memory cannot
+// be determined.
+func TotalMemoryBytes() uint64 {
+	if runtime.GOOS == "darwin" {
+		out, err := cmdlog.Output(exec.Command("sysctl", "-n", "hw.memsize"))
+		if err != nil {
+			return 0
+		}
+		bytes, _ := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+		return bytes
+	}
+	return meminfoBytes("MemTotal")
+}
+
+// meminfoBytes returns a /proc/meminfo field in bytes, or 0 if it can't be read.
+func meminfoBytes(key string) uint64 {
 	f, err := os.Open("/proc/meminfo")
 	if err != nil {
 		return 0
 	}
 	defer func() { _ = f.Close() }()
+	return parseMeminfo(f, key)
+}
 
-	scanner := bufio.NewScanner(f)
+// parseMeminfo returns the value of key from /proc/meminfo content in bytes.
+func parseMeminfo(r io.Reader, key string) uint64 {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "MemAvailable:") {
+		if strings.HasPrefix(line, key+":") {
 			fields := strings.Fields(line)
 			if len(fields) >= 2 {
 				kb, err := strconv.ParseUint(fields[1], 10, 64)
@@ -158,7 +243,7 @@ func physicalCoresLinux() int {
 // physicalCoresDarwin uses sysctl to get physical core count on macOS.
 // Returns 0 if detection fails.
 func physicalCoresDarwin() int {
-	out, err := exec.Command("sysctl", "-n", "hw.physicalcpu").Output()
+	out, err := cmdlog.Output(exec.Command("sysctl", "-n", "hw.physicalcpu"))
 	if err != nil {
 		return 0
 	}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -86,3 +87,50 @@ func TestSMTDetection(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMeminfo(t *testing.T) {
+	meminfo := "MemTotal:       65536000 kB\nMemFree:         1024 kB\nMemAvailable:   32768000 kB\n"
+	tests := []struct {
+		key  string
+		want uint64
+	}{
+		{"MemTotal", 65536000 * 1024},
+		{"MemAvailable", 32768000 * 1024},
+		{"MemFre", 0},
+		{"SwapTotal", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseMeminfo(strings.NewReader(meminfo), tt.key); got != tt.want {
+			t.Errorf("parseMeminfo(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParseCPUModel(t *testing.T) {
+	cpuinfo := "processor\t: 0\nvendor_id\t: AuthenticAMD\nmodel name\t: AMD Ryzen 9 7950X 16-Core Processor\n\nprocessor\t: 1\nmodel name\t: AMD Ryzen 9 7950X 16-Core Processor\n"
+	if got, want := parseCPUModel(cpuinfo), "AMD Ryzen 9 7950X 16-Core Processor"; got != want {
+		t.Errorf("parseCPUModel() = %q, want %q", got, want)
+	}
+	if got := parseCPUModel("processor\t: 0\nBogoMIPS\t: 50.00\n"); got != "" {
+		t.Errorf("parseCPUModel(no model name) = %q, want empty", got)
+	}
+}
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"NAME=\"Ubuntu\"\nPRETTY_NAME=\"Ubuntu 24.04.1 LTS\"\nID=ubuntu\n", "Ubuntu 24.04.1 LTS"},
+		{"PRETTY_NAME='Arch Linux'\n", "Arch Linux"},
+		{"PRETTY_NAME=Debian\n", "Debian"},
+		{"NAME=Fedora\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseOSRelease(tt.content); got != tt.want {
+			t.Errorf("parseOSRelease(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package util
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime returns the last access time recorded for a file. Only Linux
+// exposes it here; elsewhere the modification time stands in.
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
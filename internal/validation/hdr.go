@@ -2,7 +2,12 @@
 package validation
 
 import (
+	"math"
+	"regexp"
+	"strconv"
+
 	"github.com/five82/reel/internal/mediainfo"
+	"github.com/five82/reel/internal/toolpath"
 )
 
 // HDRValidationResult contains the result of HDR validation.
@@ -11,17 +16,18 @@ type HDRValidationResult struct {
 	ActualHDR     *bool
 	Message       string
 	MediaInfoUsed bool
+	Output        *mediainfo.HDRInfo // Output metadata, when MediaInfo could read it
 }
 
 // ValidateHDRStatusWithPath validates HDR status using MediaInfo.
 // This provides comprehensive HDR detection by checking MediaInfo availability first.
-func ValidateHDRStatusWithPath(outputPath string, expectedHDR *bool) HDRValidationResult {
-	return validateHDRStatusWithAvailabilityCheck(outputPath, expectedHDR, mediainfo.IsAvailable())
+func ValidateHDRStatusWithPath(paths toolpath.Paths, outputPath string, expectedHDR *bool) HDRValidationResult {
+	return validateHDRStatusWithAvailabilityCheck(paths, outputPath, expectedHDR, mediainfo.IsAvailable(paths))
 }
 
 // validateHDRStatusWithAvailabilityCheck is the internal validation function.
 // This allows for easier testing without depending on actual system MediaInfo installation.
-func validateHDRStatusWithAvailabilityCheck(outputPath string, expectedHDR *bool, mediainfoAvailable bool) HDRValidationResult {
+func validateHDRStatusWithAvailabilityCheck(paths toolpath.Paths, outputPath string, expectedHDR *bool, mediainfoAvailable bool) HDRValidationResult {
 	// Check if MediaInfo is available first
 	if !mediainfoAvailable {
 		return HDRValidationResult{
@@ -34,13 +40,17 @@ func validateHDRStatusWithAvailabilityCheck(outputPath string, expectedHDR *bool
 
 	// Use MediaInfo for HDR detection
 	var actualHDR *bool
-	info, err := mediainfo.GetMediaInfo(outputPath)
+	var output *mediainfo.HDRInfo
+	info, err := mediainfo.GetMediaInfo(paths, outputPath)
 	if err == nil {
 		hdrInfo := mediainfo.DetectHDR(info)
 		actualHDR = &hdrInfo.IsHDR
+		output = &hdrInfo
 	}
 
-	return validateHDRResult(expectedHDR, actualHDR)
+	result := validateHDRResult(expectedHDR, actualHDR)
+	result.Output = output
+	return result
 }
 
 // validateHDRResult performs the common HDR validation logic.
@@ -104,12 +114,12 @@ func validateHDRResult(expectedHDR, actualHDR *bool) HDRValidationResult {
 
 // GetDetailedHDRInfo returns detailed HDR metadata from MediaInfo.
 // This is useful for debugging and detailed reporting.
-func GetDetailedHDRInfo(path string) (*mediainfo.HDRInfo, error) {
-	if !mediainfo.IsAvailable() {
+func GetDetailedHDRInfo(paths toolpath.Paths, path string) (*mediainfo.HDRInfo, error) {
+	if !mediainfo.IsAvailable(paths) {
 		return nil, nil
 	}
 
-	info, err := mediainfo.GetMediaInfo(path)
+	info, err := mediainfo.GetMediaInfo(paths, path)
 	if err != nil {
 		return nil, err
 	}
@@ -117,3 +127,65 @@ func GetDetailedHDRInfo(path string) (*mediainfo.HDRInfo, error) {
 	hdrInfo := mediainfo.DetectHDR(info)
 	return &hdrInfo, nil
 }
+
+// hdrMetadataTolerance is the relative difference allowed between source and
+// output HDR metadata values. Each container and codec stores them in its
+// own fixed-point units, so values rarely survive to the last digit.
+const hdrMetadataTolerance = 0.01
+
+// compareHDRMetadata compares the static HDR metadata of the source and the
+// output: mastering display primaries and luminance, MaxCLL and MaxFALL.
+// Returns the fields the source had but the output lost, and descriptions
+// of the fields whose values changed.
+func compareHDRMetadata(source, output mediainfo.HDRInfo) (dropped, mismatches []string) {
+	fields := []struct {
+		name           string
+		source, output string
+	}{
+		{"mastering display primaries", source.MasteringDisplayPrimaries, output.MasteringDisplayPrimaries},
+		{"mastering display luminance", source.MasteringDisplayLuminance, output.MasteringDisplayLuminance},
+		{"MaxCLL", source.MaxCLL, output.MaxCLL},
+		{"MaxFALL", source.MaxFALL, output.MaxFALL},
+	}
+	for _, f := range fields {
+		switch {
+		case f.source == "":
+		case f.output == "":
+			dropped = append(dropped, f.name)
+		case !sameHDRValue(f.source, f.output):
+			mismatches = append(mismatches, f.name+": "+f.output+", source "+f.source)
+		}
+	}
+	return dropped, mismatches
+}
+
+// hasHDRMetadata reports whether info carries any static HDR metadata.
+func hasHDRMetadata(info mediainfo.HDRInfo) bool {
+	return info.MasteringDisplayPrimaries != "" || info.MasteringDisplayLuminance != "" ||
+		info.MaxCLL != "" || info.MaxFALL != ""
+}
+
+var numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// sameHDRValue compares two MediaInfo metadata strings. Named values such
+// as "Display P3" must match exactly; numeric ones match when every number
+// is within hdrMetadataTolerance.
+func sameHDRValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	na, nb := numberPattern.FindAllString(a, -1), numberPattern.FindAllString(b, -1)
+	if len(na) == 0 || len(na) != len(nb) {
+		return false
+	}
+	for i := range na {
+		x, _ := strconv.ParseFloat(na[i], 64)
+		y, _ := strconv.ParseFloat(nb[i], 64)
+		// An absolute floor keeps tiny values such as a 0.0001 cd/m2
+		// minimum luminance from failing on rounding
+		if math.Abs(x-y) > max(hdrMetadataTolerance*math.Max(x, y), 0.001) {
+			return false
+		}
+	}
+	return true
+}
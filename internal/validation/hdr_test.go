@@ -1,7 +1,10 @@
 package validation
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/five82/reel/internal/mediainfo"
 )
 
 func TestValidateHDRResult(t *testing.T) {
@@ -109,7 +112,7 @@ func TestValidateHDRResult(t *testing.T) {
 
 func TestValidateHDRStatusWithAvailabilityCheck_MediaInfoNotAvailable(t *testing.T) {
 	// Test when MediaInfo is not available
-	result := validateHDRStatusWithAvailabilityCheck("/nonexistent/file.mkv", nil, false)
+	result := validateHDRStatusWithAvailabilityCheck(nil, "/nonexistent/file.mkv", nil, false)
 
 	if !result.IsValid {
 		t.Error("Should pass validation when MediaInfo is not available")
@@ -128,3 +131,61 @@ func TestValidateHDRStatusWithAvailabilityCheck_MediaInfoNotAvailable(t *testing
 		t.Error("MediaInfoUsed should be false when MediaInfo is not available")
 	}
 }
+
+func TestCompareHDRMetadata(t *testing.T) {
+	source := mediainfo.HDRInfo{
+		MasteringDisplayPrimaries: "Display P3",
+		MasteringDisplayLuminance: "min: 0.0050 cd/m2, max: 1000 cd/m2",
+		MaxCLL:                    "1000 cd/m2",
+		MaxFALL:                   "400 cd/m2",
+	}
+
+	tests := []struct {
+		name           string
+		source, output mediainfo.HDRInfo
+		wantDropped    []string
+		wantMismatches int
+	}{
+		{"identical", source, source, nil, 0},
+		{
+			name:   "fixed-point rounding",
+			source: source,
+			output: mediainfo.HDRInfo{
+				MasteringDisplayPrimaries: "Display P3",
+				MasteringDisplayLuminance: "min: 0.0050 cd/m2, max: 999.9961 cd/m2",
+				MaxCLL:                    "1000 cd/m2",
+				MaxFALL:                   "400 cd/m2",
+			},
+		},
+		{
+			name:        "light levels dropped",
+			source:      source,
+			output:      mediainfo.HDRInfo{MasteringDisplayPrimaries: "Display P3", MasteringDisplayLuminance: source.MasteringDisplayLuminance},
+			wantDropped: []string{"MaxCLL", "MaxFALL"},
+		},
+		{
+			name:   "values changed",
+			source: source,
+			output: mediainfo.HDRInfo{
+				MasteringDisplayPrimaries: "BT.2020",
+				MasteringDisplayLuminance: "min: 0.0001 cd/m2, max: 4000 cd/m2",
+				MaxCLL:                    "1000 cd/m2",
+				MaxFALL:                   "400 cd/m2",
+			},
+			wantMismatches: 2,
+		},
+		{"source without metadata", mediainfo.HDRInfo{}, mediainfo.HDRInfo{MaxCLL: "1000 cd/m2"}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dropped, mismatches := compareHDRMetadata(tt.source, tt.output)
+			if !slices.Equal(dropped, tt.wantDropped) {
+				t.Errorf("dropped = %q, want %q", dropped, tt.wantDropped)
+			}
+			if len(mismatches) != tt.wantMismatches {
+				t.Errorf("mismatches = %q, want %d", mismatches, tt.wantMismatches)
+			}
+		})
+	}
+}
@@ -1,21 +1,30 @@
 // Package validation provides post-encode validation checks.
 package validation
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Result contains the overall validation result.
 type Result struct {
 	IsAV1                    bool
-	Is10Bit                  bool
+	IsBitDepthCorrect        bool // 10-bit, or 8-bit when that was requested
 	IsCropCorrect            bool
 	IsDurationCorrect        bool
 	IsHDRCorrect             bool
-	IsAudioOpus              bool
+	IsHDRMetadataPreserved   bool // Mastering display and light levels survive the encode
+	IsAudioOpus              bool // Every track is Opus or a requested passthrough format
 	IsAudioTrackCountCorrect bool
+	IsAudioMetadataPreserved bool // Tracks keep the source languages and dispositions
+	IsAttachmentCountCorrect bool
 	IsSyncPreserved          bool
 
 	// Details
 	CodecName          string
 	PixelFormat        string
 	BitDepth           *uint8
+	ExpectedBitDepth   uint8
 	ActualDimensions   *[2]uint32
 	ExpectedDimensions *[2]uint32
 	CropMessage        string
@@ -29,24 +38,94 @@ type Result struct {
 	AudioMessage       string
 	SyncDriftMs        *float64
 	SyncMessage        string
+
+	AudioTrackCount     int
+	ExpectedAudioTracks *int
+	AudioProbeFailed    bool
+
+	AudioMetadataChecked    bool
+	AudioMetadataMismatches []string
+
+	HDRMetadataChecked    bool
+	HDRMetadataDropped    []string
+	HDRMetadataMismatches []string
+
+	AttachmentCount     *int
+	ExpectedAttachments *int
 }
 
-// ValidationStep represents a single validation check.
+// Check identifiers, stable across releases for programmatic use.
+const (
+	CheckVideoCodec = "video_codec"
+	CheckBitDepth   = "bit_depth"
+	CheckDimensions = "dimensions"
+	CheckDuration   = "duration"
+	CheckHDR        = "hdr"
+	CheckHDRMeta    = "hdr_metadata"
+	CheckAudio      = "audio"
+	CheckAudioMeta  = "audio_metadata"
+	CheckAttachment = "attachments"
+	CheckSync       = "av_sync"
+	CheckProbe      = "probe"
+)
+
+// Result codes. CodeOK and CodeSkipped mean the check passed; the others
+// name the reason it failed.
+const (
+	CodeOK                = "ok"
+	CodeSkipped           = "skipped"
+	CodeCodecMismatch     = "codec_mismatch"
+	CodeBitDepthTooLow    = "bit_depth_too_low"
+	CodeBitDepthMismatch  = "bit_depth_mismatch"
+	CodeDimensionMismatch = "dimension_mismatch"
+	CodeDurationMismatch  = "duration_mismatch"
+	CodeHDRMismatch       = "hdr_mismatch"
+	CodeHDRMetaDropped    = "hdr_metadata_dropped"
+	CodeHDRMetaMismatch   = "hdr_metadata_mismatch"
+	CodeAudioNotOpus      = "audio_not_opus"
+	CodeAudioTrackCount   = "audio_track_count_mismatch"
+	CodeAudioMetadata     = "audio_metadata_mismatch"
+	CodeAttachmentCount   = "attachment_count_mismatch"
+	CodeSyncDrift         = "sync_drift"
+	CodeProbeFailed       = "probe_failed"
+)
+
+// ValidationStep represents a single validation check. Check and Code are
+// stable identifiers and Params holds the values behind the result, so
+// callers don't have to parse the human-readable Details.
 type ValidationStep struct {
-	Name    string
-	Passed  bool
-	Details string
+	Name    string         `json:"name"`
+	Check   string         `json:"check"`
+	Code    string         `json:"code"`
+	Passed  bool           `json:"passed"`
+	Details string         `json:"details"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// ProbeFailureStep describes validation that couldn't run because the output
+// couldn't be analyzed.
+func ProbeFailureStep(err error) ValidationStep {
+	return ValidationStep{
+		Name:    "Validation",
+		Check:   CheckProbe,
+		Code:    CodeProbeFailed,
+		Passed:  false,
+		Details: err.Error(),
+	}
 }
 
 // IsValid returns true if all validation checks passed.
 func (r *Result) IsValid() bool {
 	return r.IsAV1 &&
-		r.Is10Bit &&
+		r.IsBitDepthCorrect &&
 		r.IsCropCorrect &&
 		r.IsDurationCorrect &&
 		r.IsHDRCorrect &&
+		r.IsHDRMetadataPreserved &&
 		r.IsAudioOpus &&
 		r.IsAudioTrackCountCorrect &&
+		r.IsAudioMetadataPreserved &&
+		r.IsAttachmentCountCorrect &&
 		r.IsSyncPreserved
 }
 
@@ -55,43 +134,228 @@ func (r *Result) GetValidationSteps() []ValidationStep {
 	steps := []ValidationStep{
 		{
 			Name:    "Video codec",
+			Check:   CheckVideoCodec,
+			Code:    code(r.IsAV1, CodeCodecMismatch),
 			Passed:  r.IsAV1,
 			Details: formatCodecDetails(r.CodecName, r.IsAV1),
+			Params:  map[string]any{"codec": r.CodecName},
 		},
-		{
-			Name:    "Bit depth",
-			Passed:  r.Is10Bit,
-			Details: formatBitDepthDetails(r.BitDepth, r.PixelFormat),
-		},
-		{
-			Name:    "Crop detection",
-			Passed:  r.IsCropCorrect,
-			Details: r.CropMessage,
-		},
+		r.bitDepthStep(),
+		r.dimensionsStep(),
 		{
 			Name:    "Video duration",
+			Check:   CheckDuration,
+			Code:    codeUnlessSkipped(r.IsDurationCorrect, r.ExpectedDuration == nil, CodeDurationMismatch),
 			Passed:  r.IsDurationCorrect,
 			Details: r.DurationMessage,
+			Params:  params("actual_secs", r.ActualDuration, "expected_secs", r.ExpectedDuration),
 		},
 		{
 			Name:    "HDR/SDR status",
+			Check:   CheckHDR,
+			Code:    codeUnlessSkipped(r.IsHDRCorrect, r.ExpectedHDR == nil, CodeHDRMismatch),
 			Passed:  r.IsHDRCorrect,
 			Details: r.HDRMessage,
+			Params:  params("actual_hdr", r.ActualHDR, "expected_hdr", r.ExpectedHDR),
 		},
-		{
-			Name:    "Audio tracks",
-			Passed:  r.IsAudioOpus && r.IsAudioTrackCountCorrect,
-			Details: r.AudioMessage,
-		},
+		r.hdrMetadataStep(),
+		r.audioStep(),
+		r.audioMetadataStep(),
+		r.attachmentStep(),
 		{
 			Name:    "Audio/video sync",
+			Check:   CheckSync,
+			Code:    codeUnlessSkipped(r.IsSyncPreserved, r.SyncDriftMs == nil, CodeSyncDrift),
 			Passed:  r.IsSyncPreserved,
 			Details: r.SyncMessage,
+			Params:  params("drift_ms", r.SyncDriftMs, "max_drift_ms", maxSyncDriftMs),
 		},
 	}
 	return steps
 }
 
+func (r *Result) bitDepthStep() ValidationStep {
+	expected := r.ExpectedBitDepth
+	if expected == 0 {
+		expected = 10
+	}
+	step := ValidationStep{
+		Name:    "Bit depth",
+		Check:   CheckBitDepth,
+		Code:    code(r.IsBitDepthCorrect, CodeBitDepthTooLow),
+		Passed:  r.IsBitDepthCorrect,
+		Details: formatBitDepthDetails(r.BitDepth, r.PixelFormat),
+		Params:  params("bit_depth", r.BitDepth, "pixel_format", r.PixelFormat, "expected_bit_depth", &expected),
+	}
+	if !r.IsBitDepthCorrect {
+		if r.BitDepth != nil && *r.BitDepth > expected {
+			step.Code = CodeBitDepthMismatch
+		}
+		step.Details += ", expected " + formatDepth(expected)
+	}
+	return step
+}
+
+func (r *Result) dimensionsStep() ValidationStep {
+	step := ValidationStep{
+		Name:    "Crop detection",
+		Check:   CheckDimensions,
+		Code:    codeUnlessSkipped(r.IsCropCorrect, r.ExpectedDimensions == nil, CodeDimensionMismatch),
+		Passed:  r.IsCropCorrect,
+		Details: r.CropMessage,
+	}
+	if r.ActualDimensions != nil && r.ExpectedDimensions != nil {
+		step.Params = map[string]any{
+			"actual_width":    r.ActualDimensions[0],
+			"actual_height":   r.ActualDimensions[1],
+			"expected_width":  r.ExpectedDimensions[0],
+			"expected_height": r.ExpectedDimensions[1],
+		}
+	}
+	return step
+}
+
+func (r *Result) hdrMetadataStep() ValidationStep {
+	step := ValidationStep{
+		Name:   "HDR metadata",
+		Check:  CheckHDRMeta,
+		Code:   codeUnlessSkipped(r.IsHDRMetadataPreserved, !r.HDRMetadataChecked, CodeHDRMetaMismatch),
+		Passed: r.IsHDRMetadataPreserved,
+		Params: params("dropped", r.HDRMetadataDropped, "mismatches", r.HDRMetadataMismatches),
+	}
+	switch {
+	case !r.HDRMetadataChecked:
+		step.Details = "HDR metadata validation skipped"
+	case len(r.HDRMetadataDropped) > 0:
+		step.Code = CodeHDRMetaDropped
+		step.Details = "Dropped " + strings.Join(r.HDRMetadataDropped, ", ")
+		if len(r.HDRMetadataMismatches) > 0 {
+			step.Details += "; " + strings.Join(r.HDRMetadataMismatches, "; ")
+		}
+	case r.IsHDRMetadataPreserved:
+		step.Details = "Mastering display and light levels match the source"
+	default:
+		step.Details = strings.Join(r.HDRMetadataMismatches, "; ")
+	}
+	return step
+}
+
+func (r *Result) audioStep() ValidationStep {
+	step := ValidationStep{
+		Name:    "Audio tracks",
+		Check:   CheckAudio,
+		Passed:  r.IsAudioOpus && r.IsAudioTrackCountCorrect,
+		Details: r.AudioMessage,
+		Params:  params("codecs", r.AudioCodecs, "track_count", r.AudioTrackCount, "expected_tracks", r.ExpectedAudioTracks),
+	}
+	switch {
+	case r.AudioProbeFailed:
+		step.Code = CodeSkipped
+	case !r.IsAudioOpus:
+		step.Code = CodeAudioNotOpus
+	case !r.IsAudioTrackCountCorrect:
+		step.Code = CodeAudioTrackCount
+	default:
+		step.Code = CodeOK
+	}
+	return step
+}
+
+func (r *Result) audioMetadataStep() ValidationStep {
+	step := ValidationStep{
+		Name:   "Audio metadata",
+		Check:  CheckAudioMeta,
+		Code:   codeUnlessSkipped(r.IsAudioMetadataPreserved, !r.AudioMetadataChecked, CodeAudioMetadata),
+		Passed: r.IsAudioMetadataPreserved,
+		Params: params("mismatches", r.AudioMetadataMismatches),
+	}
+	switch {
+	case !r.AudioMetadataChecked:
+		step.Details = "Audio metadata validation skipped"
+	case r.IsAudioMetadataPreserved:
+		step.Details = "Languages and dispositions match the source"
+	default:
+		step.Details = strings.Join(r.AudioMetadataMismatches, "; ")
+	}
+	return step
+}
+
+func (r *Result) attachmentStep() ValidationStep {
+	step := ValidationStep{
+		Name:   "Attachments",
+		Check:  CheckAttachment,
+		Code:   codeUnlessSkipped(r.IsAttachmentCountCorrect, r.AttachmentCount == nil, CodeAttachmentCount),
+		Passed: r.IsAttachmentCountCorrect,
+		Params: params("count", r.AttachmentCount, "expected", r.ExpectedAttachments),
+	}
+	switch {
+	case r.AttachmentCount == nil:
+		step.Details = "Attachment validation skipped"
+	case r.IsAttachmentCountCorrect:
+		step.Details = fmt.Sprintf("Attachment count matches source (%d)", *r.AttachmentCount)
+	default:
+		step.Details = fmt.Sprintf("Attachment count mismatch: got %d, expected %d", *r.AttachmentCount, *r.ExpectedAttachments)
+	}
+	return step
+}
+
+// code returns CodeOK for a passed check, otherwise the failure code.
+func code(passed bool, failure string) string {
+	if passed {
+		return CodeOK
+	}
+	return failure
+}
+
+// codeUnlessSkipped is code for checks that are skipped without an expectation.
+func codeUnlessSkipped(passed, skipped bool, failure string) string {
+	if skipped {
+		return CodeSkipped
+	}
+	return code(passed, failure)
+}
+
+// params builds a parameter map from key/value pairs, dereferencing pointers
+// and dropping nil or empty values.
+func params(kv ...any) map[string]any {
+	m := make(map[string]any, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		key := kv[i].(string)
+		switch v := kv[i+1].(type) {
+		case *float64:
+			if v != nil {
+				m[key] = *v
+			}
+		case *uint8:
+			if v != nil {
+				m[key] = *v
+			}
+		case *bool:
+			if v != nil {
+				m[key] = *v
+			}
+		case *int:
+			if v != nil {
+				m[key] = *v
+			}
+		case string:
+			if v != "" {
+				m[key] = v
+			}
+		case []string:
+			if len(v) > 0 {
+				m[key] = v
+			}
+		default:
+			m[key] = v
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
 // GetFailures returns descriptions of failed validation checks.
 func (r *Result) GetFailures() []string {
 	var failures []string
@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetValidationStepsCodes(t *testing.T) {
+	expected, actual := 120.0, 118.2
+	drift := 1800.0
+	expectedTracks := 2
+	hdr := true
+
+	r := &Result{
+		IsAV1:                    true,
+		CodecName:                "av1",
+		IsBitDepthCorrect:        true,
+		BitDepth:                 ptrUint8(10),
+		IsCropCorrect:            true,
+		CropMessage:              "No crop validation required",
+		IsDurationCorrect:        false,
+		ExpectedDuration:         &expected,
+		ActualDuration:           &actual,
+		IsHDRCorrect:             true,
+		ExpectedHDR:              &hdr,
+		ActualHDR:                &hdr,
+		IsHDRMetadataPreserved:   false,
+		HDRMetadataChecked:       true,
+		HDRMetadataDropped:       []string{"MaxCLL", "MaxFALL"},
+		IsAudioOpus:              false,
+		IsAudioTrackCountCorrect: true,
+		AudioCodecs:              []string{"aac", "opus"},
+		AudioTrackCount:          2,
+		ExpectedAudioTracks:      &expectedTracks,
+		IsAudioMetadataPreserved: true,
+		IsAttachmentCountCorrect: false,
+		AttachmentCount:          ptrInt(0),
+		ExpectedAttachments:      ptrInt(3),
+		IsSyncPreserved:          false,
+		SyncDriftMs:              &drift,
+	}
+
+	want := map[string]string{
+		CheckVideoCodec: CodeOK,
+		CheckBitDepth:   CodeOK,
+		CheckDimensions: CodeSkipped,
+		CheckDuration:   CodeDurationMismatch,
+		CheckHDR:        CodeOK,
+		CheckHDRMeta:    CodeHDRMetaDropped,
+		CheckAudio:      CodeAudioNotOpus,
+		CheckAudioMeta:  CodeSkipped,
+		CheckAttachment: CodeAttachmentCount,
+		CheckSync:       CodeSyncDrift,
+	}
+
+	steps := r.GetValidationSteps()
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(want))
+	}
+	for _, step := range steps {
+		if step.Code != want[step.Check] {
+			t.Errorf("%s: code = %q, want %q", step.Check, step.Code, want[step.Check])
+		}
+		if passed := step.Code == CodeOK || step.Code == CodeSkipped; passed != step.Passed {
+			t.Errorf("%s: code %q disagrees with passed = %v", step.Check, step.Code, step.Passed)
+		}
+		if step.Check == CheckDuration {
+			if step.Params["expected_secs"] != expected || step.Params["actual_secs"] != actual {
+				t.Errorf("duration params = %v", step.Params)
+			}
+		}
+	}
+}
+
+func TestBitDepthStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    *uint8
+		expected uint8
+		correct  bool
+		want     string
+	}{
+		{"10-bit", ptrUint8(10), 10, true, CodeOK},
+		{"8-bit when 10 expected", ptrUint8(8), 10, false, CodeBitDepthTooLow},
+		{"8-bit requested", ptrUint8(8), 8, true, CodeOK},
+		{"10-bit when 8 requested", ptrUint8(10), 8, false, CodeBitDepthMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bitDepthMatches(tt.depth, tt.expected); got != tt.correct {
+				t.Fatalf("bitDepthMatches() = %v, want %v", got, tt.correct)
+			}
+			r := &Result{IsBitDepthCorrect: tt.correct, BitDepth: tt.depth, ExpectedBitDepth: tt.expected}
+			step := r.bitDepthStep()
+			if step.Code != tt.want || step.Params["expected_bit_depth"] != tt.expected {
+				t.Errorf("bitDepthStep() = %+v, want code %q", step, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeFailureStep(t *testing.T) {
+	step := ProbeFailureStep(errors.New("no such file"))
+	if step.Passed || step.Code != CodeProbeFailed || step.Check != CheckProbe {
+		t.Errorf("ProbeFailureStep() = %+v", step)
+	}
+}
+
+func ptrUint8(v uint8) *uint8 { return &v }
+
+func ptrInt(v int) *int { return &v }
@@ -3,10 +3,12 @@ package validation
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 
 	"github.com/five82/reel/internal/ffprobe"
 	"github.com/five82/reel/internal/mediainfo"
+	"github.com/five82/reel/internal/toolpath"
 )
 
 const (
@@ -21,35 +23,51 @@ type Options struct {
 	ExpectedDimensions    *[2]uint32
 	ExpectedDuration      *float64
 	ExpectedHDR           *bool
+	SourceHDR             *mediainfo.HDRInfo // Mastering display and light levels to preserve
+	ExpectedBitDepth      uint8              // 8 or 10 (0 = 10)
 	ExpectedAudioTracks   *int
 	ExpectedAudioChannels []uint32
+	AudioPassthrough      []string                  // Lossless formats accepted alongside Opus
+	PreservedCodecs       []string                  // Codecs of object audio streams copied as is
+	SourceAudio           []ffprobe.AudioStreamInfo // Source streams whose metadata the leading tracks keep
+	ExpectedAttachments   *int
+	Tools                 toolpath.Paths // Binaries of ffprobe and mediainfo
 }
 
 // ValidateOutputVideo performs comprehensive validation of an encoded video.
 func ValidateOutputVideo(inputPath, outputPath string, opts Options) (*Result, error) {
+	paths := opts.Tools
 	result := &Result{
 		IsCropCorrect:            true,
 		IsDurationCorrect:        true,
 		IsHDRCorrect:             true,
+		IsHDRMetadataPreserved:   true,
 		IsAudioOpus:              true,
 		IsAudioTrackCountCorrect: true,
+		IsAudioMetadataPreserved: true,
+		IsAttachmentCountCorrect: true,
 		IsSyncPreserved:          true,
 	}
 
 	// Get output video properties
-	outputProps, err := ffprobe.GetVideoProperties(outputPath)
+	outputProps, err := ffprobe.GetVideoProperties(paths, outputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get output video properties: %w", err)
 	}
 
 	// Validate video codec (should be AV1)
-	mediaInfo, err := ffprobe.GetMediaInfo(outputPath)
+	mediaInfo, err := ffprobe.GetMediaInfo(paths, outputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get media info: %w", err)
 	}
 
-	result.IsAV1, result.CodecName = validateVideoCodec(outputPath)
-	result.Is10Bit, result.BitDepth, result.PixelFormat = validateBitDepth(outputPath)
+	result.IsAV1, result.CodecName = validateVideoCodec(paths, outputPath)
+	result.ExpectedBitDepth = opts.ExpectedBitDepth
+	if result.ExpectedBitDepth == 0 {
+		result.ExpectedBitDepth = 10
+	}
+	result.BitDepth, result.PixelFormat = probeBitDepth(paths, outputPath, result.ExpectedBitDepth)
+	result.IsBitDepthCorrect = bitDepthMatches(result.BitDepth, result.ExpectedBitDepth)
 
 	// Validate dimensions if expected
 	if opts.ExpectedDimensions != nil {
@@ -74,30 +92,51 @@ func ValidateOutputVideo(inputPath, outputPath string, opts Options) (*Result, e
 	}
 
 	// Validate HDR status if expected - use comprehensive MediaInfo-based validation
+	hdrResult := ValidateHDRStatusWithPath(paths, outputPath, opts.ExpectedHDR)
+	result.ActualHDR = hdrResult.ActualHDR
+	result.HDRMessage = hdrResult.Message
 	if opts.ExpectedHDR != nil {
-		hdrResult := ValidateHDRStatusWithPath(outputPath, opts.ExpectedHDR)
 		result.IsHDRCorrect = hdrResult.IsValid
-		result.ActualHDR = hdrResult.ActualHDR
 		result.ExpectedHDR = opts.ExpectedHDR
-		result.HDRMessage = hdrResult.Message
-	} else {
-		// No expected HDR, but still detect actual status for reporting
-		hdrResult := ValidateHDRStatusWithPath(outputPath, nil)
-		result.IsHDRCorrect = true // No expectation means always valid
-		result.ActualHDR = hdrResult.ActualHDR
-		result.HDRMessage = hdrResult.Message
+	}
+	// No expected HDR means always valid; the status is still detected for reporting
+
+	// Validate mastering display and light levels against the source
+	if opts.SourceHDR != nil && hdrResult.Output != nil && hasHDRMetadata(*opts.SourceHDR) {
+		result.HDRMetadataChecked = true
+		result.HDRMetadataDropped, result.HDRMetadataMismatches = compareHDRMetadata(*opts.SourceHDR, *hdrResult.Output)
+		result.IsHDRMetadataPreserved = len(result.HDRMetadataDropped) == 0 && len(result.HDRMetadataMismatches) == 0
 	}
 
 	// Validate audio
-	audioStreams, err := ffprobe.GetAudioStreamInfo(outputPath)
+	audioStreams, err := ffprobe.GetAudioStreamInfo(paths, outputPath)
+	result.ExpectedAudioTracks = opts.ExpectedAudioTracks
 	if err != nil {
+		result.AudioProbeFailed = true
 		result.AudioMessage = "Failed to get audio info"
 	} else {
+		result.AudioTrackCount = len(audioStreams)
 		result.IsAudioOpus, result.IsAudioTrackCountCorrect, result.AudioCodecs, result.AudioMessage = validateAudio(
-			audioStreams, opts.ExpectedAudioTracks,
+			audioStreams, opts.ExpectedAudioTracks, opts.AudioPassthrough, opts.PreservedCodecs,
 		)
 	}
 
+	// Validate audio languages and dispositions against the source
+	if err == nil && opts.SourceAudio != nil {
+		result.AudioMetadataChecked = true
+		result.AudioMetadataMismatches = validateAudioMetadata(opts.SourceAudio, audioStreams)
+		result.IsAudioMetadataPreserved = len(result.AudioMetadataMismatches) == 0
+	}
+
+	// Validate attachment count if expected
+	result.ExpectedAttachments = opts.ExpectedAttachments
+	if opts.ExpectedAttachments != nil {
+		if count, err := ffprobe.CountAttachments(paths, outputPath); err == nil {
+			result.AttachmentCount = &count
+			result.IsAttachmentCountCorrect = count == *opts.ExpectedAttachments
+		}
+	}
+
 	// Validate A/V sync
 	if opts.ExpectedDuration != nil && mediaInfo != nil {
 		result.IsSyncPreserved, result.SyncDriftMs, result.SyncMessage = validateSync(
@@ -111,8 +150,8 @@ func ValidateOutputVideo(inputPath, outputPath string, opts Options) (*Result, e
 }
 
 // validateVideoCodec checks that the output is AV1.
-func validateVideoCodec(outputPath string) (bool, string) {
-	probe, err := ffprobe.GetMediaInfo(outputPath)
+func validateVideoCodec(paths toolpath.Paths, outputPath string) (bool, string) {
+	probe, err := ffprobe.GetMediaInfo(paths, outputPath)
 	if err != nil {
 		return false, ""
 	}
@@ -121,7 +160,7 @@ func validateVideoCodec(outputPath string) (bool, string) {
 	codecName := ""
 	if probe.Width > 0 {
 		// Use ffprobe with show_streams to get codec
-		streams, err := getVideoCodec(outputPath)
+		streams, err := getVideoCodec(paths, outputPath)
 		if err == nil {
 			codecName = streams
 		}
@@ -134,36 +173,50 @@ func validateVideoCodec(outputPath string) (bool, string) {
 }
 
 // getVideoCodec gets the video codec name using ffprobe.
-func getVideoCodec(outputPath string) (string, error) {
-	return ffprobe.GetVideoCodecName(outputPath)
+func getVideoCodec(paths toolpath.Paths, outputPath string) (string, error) {
+	return ffprobe.GetVideoCodecName(paths, outputPath)
 }
 
-// validateBitDepth checks that the output is 10-bit.
-func validateBitDepth(outputPath string) (bool, *uint8, string) {
+// probeBitDepth returns the bit depth of the output video, and its pixel
+// format when the depth had to be assumed. Returns a nil depth when the
+// output can't be probed.
+func probeBitDepth(paths toolpath.Paths, outputPath string, expected uint8) (*uint8, string) {
 	// Try to get bit depth from MediaInfo first
-	info, err := mediainfo.GetMediaInfo(outputPath)
+	info, err := mediainfo.GetMediaInfo(paths, outputPath)
 	if err == nil {
 		hdr := mediainfo.DetectHDR(info)
 		if hdr.BitDepth != nil {
-			is10Bit := *hdr.BitDepth >= 10
-			return is10Bit, hdr.BitDepth, ""
+			return hdr.BitDepth, ""
 		}
 	}
 
 	// Fallback to ffprobe
-	props, err := ffprobe.GetVideoProperties(outputPath)
+	props, err := ffprobe.GetVideoProperties(paths, outputPath)
 	if err != nil {
-		return false, nil, ""
+		return nil, ""
 	}
 
 	if props.HDRInfo.BitDepth != nil {
-		is10Bit := *props.HDRInfo.BitDepth >= 10
-		return is10Bit, props.HDRInfo.BitDepth, ""
+		return props.HDRInfo.BitDepth, ""
 	}
 
-	// Default to true for AV1 (typically 10-bit)
-	defaultDepth := uint8(10)
-	return true, &defaultDepth, "yuv420p10le"
+	// AV1 doesn't always report a depth; assume the one encoded at
+	if expected == 8 {
+		return &expected, "yuv420p"
+	}
+	return &expected, "yuv420p10le"
+}
+
+// bitDepthMatches reports whether the output depth is the expected one: at
+// least 10 bits for 10-bit output, exactly 8 for 8-bit output.
+func bitDepthMatches(depth *uint8, expected uint8) bool {
+	if depth == nil {
+		return false
+	}
+	if expected == 8 {
+		return *depth == 8
+	}
+	return *depth >= expected
 }
 
 // validateDimensions checks that dimensions match expected values.
@@ -186,16 +239,20 @@ func validateDuration(actual, expected float64) (bool, string) {
 		actual, expected, diff)
 }
 
-// validateAudio checks audio codec and track count.
-func validateAudio(streams []ffprobe.AudioStreamInfo, expectedTracks *int) (bool, bool, []string, string) {
-	isOpus := true
+// validateAudio checks audio codec and track count. Tracks must be Opus, in
+// one of the passthrough formats, or in one of the preserved codecs.
+func validateAudio(streams []ffprobe.AudioStreamInfo, expectedTracks *int, passthrough, preserved []string) (bool, bool, []string, string) {
+	codecsOK, allOpus := true, true
 	var codecs []string
 
 	for _, stream := range streams {
 		codec := strings.ToLower(stream.CodecName)
 		codecs = append(codecs, codec)
 		if codec != "opus" {
-			isOpus = false
+			allOpus = false
+			if !slices.Contains(passthrough, stream.LosslessFormat()) && !slices.Contains(preserved, codec) {
+				codecsOK = false
+			}
 		}
 	}
 
@@ -204,24 +261,65 @@ func validateAudio(streams []ffprobe.AudioStreamInfo, expectedTracks *int) (bool
 		trackCountCorrect = len(streams) == *expectedTracks
 	}
 
+	expected := "Opus"
+	if len(passthrough) > 0 {
+		expected = "Opus or " + strings.Join(passthrough, ", ")
+	}
+
 	var message string
 	if len(streams) == 0 {
 		message = "No audio tracks"
 	} else if len(streams) == 1 {
-		if isOpus {
+		if allOpus {
 			message = "Audio track is Opus"
+		} else if codecsOK {
+			message = fmt.Sprintf("Audio track is %s (passthrough)", codecs[0])
 		} else {
-			message = fmt.Sprintf("Audio track is %s (expected Opus)", codecs[0])
+			message = fmt.Sprintf("Audio track is %s (expected %s)", codecs[0], expected)
 		}
 	} else {
-		if isOpus {
+		if allOpus {
 			message = fmt.Sprintf("%d audio tracks, all Opus", len(streams))
-		} else {
+		} else if codecsOK {
 			message = fmt.Sprintf("%d audio tracks: %s", len(streams), strings.Join(codecs, ", "))
+		} else {
+			message = fmt.Sprintf("%d audio tracks: %s (expected %s)", len(streams), strings.Join(codecs, ", "), expected)
+		}
+	}
+
+	return codecsOK, trackCountCorrect, codecs, message
+}
+
+// validateAudioMetadata compares the language and default and forced
+// dispositions of each source stream with the output track in its place,
+// returning a description of each difference. A missing language matches
+// "und".
+func validateAudioMetadata(source, output []ffprobe.AudioStreamInfo) []string {
+	var mismatches []string
+	for i, src := range source {
+		if i >= len(output) {
+			break // Reported by the track count check
+		}
+		out := output[i]
+		if lang, want := languageOrUnd(out.Language), languageOrUnd(src.Language); lang != want {
+			mismatches = append(mismatches, fmt.Sprintf("track %d language %s, expected %s", i, lang, want))
+		}
+		if out.Disposition.Default != src.Disposition.Default {
+			mismatches = append(mismatches, fmt.Sprintf("track %d default flag %d, expected %d", i, out.Disposition.Default, src.Disposition.Default))
+		}
+		if out.Disposition.Forced != src.Disposition.Forced {
+			mismatches = append(mismatches, fmt.Sprintf("track %d forced flag %d, expected %d", i, out.Disposition.Forced, src.Disposition.Forced))
 		}
 	}
+	return mismatches
+}
 
-	return isOpus, trackCountCorrect, codecs, message
+// languageOrUnd returns language, or "und" when it is empty.
+func languageOrUnd(language string) string {
+	if language == "" {
+		return "und"
+	}
+	return strings.ToLower(language)
 }
 
 // validateSync checks audio/video sync drift.
@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/five82/reel/internal/ffprobe"
+)
+
+func TestValidateAudioPassthrough(t *testing.T) {
+	opus := ffprobe.AudioStreamInfo{CodecName: "opus"}
+	truehd := ffprobe.AudioStreamInfo{CodecName: "truehd"}
+	dtsHRA := ffprobe.AudioStreamInfo{CodecName: "dts", Profile: "DTS-HD HRA"}
+	eac3 := ffprobe.AudioStreamInfo{CodecName: "eac3"}
+
+	tests := []struct {
+		name        string
+		streams     []ffprobe.AudioStreamInfo
+		passthrough []string
+		preserved   []string
+		want        bool
+	}{
+		{"all opus", []ffprobe.AudioStreamInfo{opus, opus}, nil, nil, true},
+		{"truehd without passthrough", []ffprobe.AudioStreamInfo{truehd, opus}, nil, nil, false},
+		{"truehd passed through", []ffprobe.AudioStreamInfo{truehd, opus}, []string{"truehd"}, nil, true},
+		{"lossy dts not accepted as dts-hd", []ffprobe.AudioStreamInfo{dtsHRA}, []string{"dts-hd"}, nil, false},
+		{"preserved atmos eac3", []ffprobe.AudioStreamInfo{eac3, opus}, nil, []string{"eac3"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, _, msg := validateAudio(tt.streams, nil, tt.passthrough, tt.preserved)
+			if got != tt.want {
+				t.Errorf("codecs ok = %v, want %v (%s)", got, tt.want, msg)
+			}
+		})
+	}
+}
+
+func TestValidateAudioMetadata(t *testing.T) {
+	main := ffprobe.AudioStreamInfo{Language: "eng", Disposition: ffprobe.StreamDisposition{Default: 1}}
+	commentary := ffprobe.AudioStreamInfo{Language: "eng"}
+	untagged := ffprobe.AudioStreamInfo{}
+
+	tests := []struct {
+		name   string
+		source []ffprobe.AudioStreamInfo
+		output []ffprobe.AudioStreamInfo
+		want   int
+	}{
+		{"preserved", []ffprobe.AudioStreamInfo{main, commentary}, []ffprobe.AudioStreamInfo{main, commentary, untagged}, 0},
+		{"untagged matches und", []ffprobe.AudioStreamInfo{untagged}, []ffprobe.AudioStreamInfo{{Language: "und"}}, 0},
+		{"default flag lost", []ffprobe.AudioStreamInfo{main}, []ffprobe.AudioStreamInfo{commentary}, 1},
+		{"language and forced changed", []ffprobe.AudioStreamInfo{commentary}, []ffprobe.AudioStreamInfo{
+			{Language: "fre", Disposition: ffprobe.StreamDisposition{Forced: 1}},
+		}, 2},
+		{"missing track left to count check", []ffprobe.AudioStreamInfo{main, commentary}, []ffprobe.AudioStreamInfo{main}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateAudioMetadata(tt.source, tt.output); len(got) != tt.want {
+				t.Errorf("got %d mismatches %q, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,18 @@
 // Package worker provides types and utilities for parallel chunk encoding.
 package worker
 
+import (
+	"sync"
+	"time"
+)
+
 // Semaphore provides a counting semaphore for controlling concurrency.
 // It is used to limit the number of chunks in flight to prevent memory exhaustion.
 type Semaphore struct {
 	permits chan struct{}
+
+	mu   sync.Mutex
+	debt int // Permits to drop as they are released, after Shrink
 }
 
 // NewSemaphore creates a new semaphore with the given number of permits.
@@ -22,8 +30,59 @@ func NewSemaphore(count int) *Semaphore {
 	return s
 }
 
-// Release returns a permit to the semaphore.
+// NewGrowableSemaphore creates a semaphore with count permits that Grow can
+// raise to at most limit.
+func NewGrowableSemaphore(count, limit int) *Semaphore {
+	count = max(count, 1)
+	s := &Semaphore{
+		permits: make(chan struct{}, max(limit, count)),
+	}
+	for range count {
+		s.permits <- struct{}{}
+	}
+	return s
+}
+
+// Grow adds n permits, up to the semaphore's limit. Permits still owed
+// from Shrink are forgiven first.
+func (s *Semaphore) Grow(n int) {
+	s.mu.Lock()
+	forgiven := min(n, s.debt)
+	s.debt -= forgiven
+	s.mu.Unlock()
+	for range n - forgiven {
+		s.release()
+	}
+}
+
+// Shrink removes n permits: free ones at once, and the rest as they are
+// released.
+func (s *Semaphore) Shrink(n int) {
+	for ; n > 0; n-- {
+		select {
+		case <-s.permits:
+		default:
+			s.mu.Lock()
+			s.debt += n
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Release returns a permit to the semaphore, unless Shrink is owed one.
 func (s *Semaphore) Release() {
+	s.mu.Lock()
+	if s.debt > 0 {
+		s.debt--
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	s.release()
+}
+
+func (s *Semaphore) release() {
 	select {
 	case s.permits <- struct{}{}:
 	default:
@@ -42,22 +101,36 @@ type EncodeResult struct {
 	ChunkIdx int
 	Frames   int
 	Size     uint64
-	Error    error
+	Elapsed  time.Duration // Wall time of the successful attempt
+	Decode   time.Duration // Of which spent decoding frames
+	Pipe     time.Duration // Spent blocked writing frames to the encoder, alongside decoding
+
+	DecodeStall time.Duration // Decoder waiting on the encoder, with its decode-ahead full
+	EncodeStall time.Duration // Encoder waiting on the decoder, with no frame decoded ahead
+
+	Error error
 }
 
 // Progress represents encoding progress information.
 type Progress struct {
 	ChunksComplete int
 	ChunksTotal    int
-	FramesComplete int
+	FramesComplete int // Frames in finished chunks
+	FramesEncoding int // Frames encoded so far in running chunks
 	FramesTotal    int
 	BytesComplete  uint64
 }
 
-// Percent returns the completion percentage.
+// FramesEncoded returns the frames encoded, finished chunks or not.
+func (p Progress) FramesEncoded() int {
+	return p.FramesComplete + p.FramesEncoding
+}
+
+// Percent returns the completion percentage, counting frames of running
+// chunks.
 func (p Progress) Percent() float64 {
 	if p.FramesTotal == 0 {
 		return 0
 	}
-	return float64(p.FramesComplete) / float64(p.FramesTotal) * 100
+	return float64(p.FramesEncoded()) / float64(p.FramesTotal) * 100
 }
@@ -0,0 +1,26 @@
+// Package reel provides a Go library for AV1 video encoding with SVT-AV1.
+//
+// This file re-exports the chunk planning types so callers can place chunk
+// boundaries themselves.
+
+package reel
+
+import (
+	"github.com/five82/reel/internal/chunk"
+	"github.com/five82/reel/internal/ffms"
+)
+
+// ChunkPlanner places chunks in place of the chunk strategy. PlanChunks
+// receives the video and the scenes the chunk strategy chose, and returns
+// chunks covering every frame from 0 to VidInf.Frames in order.
+type ChunkPlanner = chunk.Planner
+
+// VidInf describes the video being chunked: frame size, rate and count.
+type VidInf = ffms.VidInf
+
+// Scene is a span of frames, from StartFrame up to but excluding EndFrame.
+type Scene = chunk.Scene
+
+// Chunk is a span of frames encoded as one unit, from Start up to but
+// excluding End. Idx is ignored in planned chunks.
+type Chunk = chunk.Chunk
@@ -24,13 +24,22 @@ package reel
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/five82/reel/internal/config"
 	"github.com/five82/reel/internal/discovery"
 	"github.com/five82/reel/internal/processing"
 	"github.com/five82/reel/internal/reporter"
+	"github.com/five82/reel/internal/toolpath"
 	"github.com/five82/reel/internal/util"
+	"github.com/five82/reel/internal/validation"
 )
 
 // Encoder is the main entry point for video encoding.
@@ -46,6 +55,7 @@ type Result struct {
 	SizeReductionPercent float64
 	ValidationPassed     bool
 	EncodingSpeed        float32
+	Checksum             string // Hex digest of the output, with WithChecksum
 }
 
 // BatchResult contains the result of a batch encode.
@@ -55,6 +65,7 @@ type BatchResult struct {
 	TotalFiles            int
 	TotalSizeReduction    float64
 	ValidationPassedCount int
+	Failures              []*FileError // Inputs that produced no output, with the cause
 }
 
 // Option configures the encoder.
@@ -63,6 +74,7 @@ type Option func(*config.Config)
 // New creates a new Encoder with the given options.
 func New(opts ...Option) (*Encoder, error) {
 	cfg := config.NewConfig(".", ".", ".")
+	cfg.Version = Version
 
 	for _, opt := range opts {
 		opt(cfg)
@@ -94,6 +106,145 @@ func WithCRFByResolution(sd, hd, uhd uint8) Option {
 	}
 }
 
+// WithPreset sets the SVT-AV1 preset for all resolutions (0-13, lower is slower and higher quality).
+func WithPreset(preset uint8) Option {
+	return func(c *config.Config) {
+		c.PresetSD = preset
+		c.PresetHD = preset
+		c.PresetUHD = preset
+	}
+}
+
+// WithPresetByResolution sets resolution-specific SVT-AV1 presets (0-13), using
+// the same resolution tiers as WithCRFByResolution. A faster UHD preset keeps
+// 4K encodes tractable.
+func WithPresetByResolution(sd, hd, uhd uint8) Option {
+	return func(c *config.Config) {
+		c.PresetSD = sd
+		c.PresetHD = hd
+		c.PresetUHD = uhd
+	}
+}
+
+// WithTune sets the SVT-AV1 tune (0 = visual quality, 1 = PSNR, 2 = SSIM).
+// Content tuning leaves an explicit tune unchanged.
+func WithTune(tune uint8) Option {
+	return func(c *config.Config) {
+		c.SVTAV1Tune = tune
+		c.TuneExplicit = true
+	}
+}
+
+// WithSvtParams passes additional SvtAv1EncApp parameters as
+// "key=value:key=value", e.g. "enable-qm=1:qm-min=8". Parameters reel sets
+// itself (dimensions, frame rate, CRF, preset, keyint, tune, film grain,
+// variance boost, ac-bias, color and HDR metadata, and similar) are rejected
+// by New; use the matching option instead.
+func WithSvtParams(params string) Option {
+	return func(c *config.Config) {
+		c.SVTAV1ExtraParams = params
+	}
+}
+
+// WithKeyint sets the maximum keyframe interval in seconds (1-30, default
+// 10). Streaming setups usually want 2-4 seconds so players can seek and
+// switch quickly.
+func WithKeyint(secs float64) Option {
+	return func(c *config.Config) {
+		c.KeyintSecs = secs
+	}
+}
+
+// WithTiles splits frames into rows x columns tiles, each a power of two
+// (up to 64 rows and 16 columns), for decoders that decode tiles in
+// parallel. Invalid layouts are rejected by New.
+func WithTiles(rows, columns uint8) Option {
+	return func(c *config.Config) {
+		c.SVTAV1TileRows = rows
+		c.SVTAV1TileColumns = columns
+	}
+}
+
+// WithSceneDetection sets whether SVT-AV1 places keyframes at scene changes
+// within a chunk (on by default). Without it keyframes fall only at chunk
+// starts and every keyint.
+func WithSceneDetection(enabled bool) Option {
+	return func(c *config.Config) {
+		c.SVTAV1SceneDetection = enabled
+	}
+}
+
+// WithBitDepth sets the output bit depth: "10" (the default), "8", or
+// "auto" to encode 8-bit SDR sources at 8-bit. HDR sources are always
+// encoded at 10-bit.
+func WithBitDepth(mode string) Option {
+	return func(c *config.Config) {
+		c.BitDepth = mode
+	}
+}
+
+// WithPALSlowdown slows 25fps PAL sources back to the 23.976fps film rate,
+// time-stretching audio to keep its pitch and retiming subtitles. Other
+// sources are encoded unchanged.
+func WithPALSlowdown() Option {
+	return func(c *config.Config) {
+		c.PALSlowdown = true
+	}
+}
+
+// WithVideoStream encodes the n-th video stream, counted from 0, instead of
+// the primary one: the first flagged default, passing over cover art.
+func WithVideoStream(n int) Option {
+	return func(c *config.Config) {
+		c.VideoStream = &n
+	}
+}
+
+// WithAudioPassthrough copies audio streams in the given lossless formats
+// ("truehd", "dts-hd", "flac", "alac", "pcm") instead of encoding them to
+// Opus. Validation accepts them alongside Opus.
+func WithAudioPassthrough(formats ...string) Option {
+	return func(c *config.Config) {
+		c.AudioPassthrough = formats
+	}
+}
+
+// WithPreserveAtmos copies Dolby Atmos and DTS:X audio streams instead of
+// encoding them to Opus, which would discard their object metadata.
+func WithPreserveAtmos() Option {
+	return func(c *config.Config) {
+		c.PreserveAtmos = true
+	}
+}
+
+// WithDropAttachments leaves attachments such as embedded subtitle fonts out
+// of the output. By default they are kept so ASS subtitles render as
+// intended.
+func WithDropAttachments() Option {
+	return func(c *config.Config) {
+		c.DropAttachments = true
+	}
+}
+
+// WithStereoDownmix adds a stereo downmix of the first surround audio
+// stream as an extra track after the others. dialogueBoostDB (0-12) raises
+// the center channel to make dialogue clearer; 0 is a standard downmix.
+func WithStereoDownmix(dialogueBoostDB float64) Option {
+	return func(c *config.Config) {
+		c.StereoDownmix = true
+		c.DialogueBoostDB = dialogueBoostDB
+	}
+}
+
+// WithFailFast stops a batch at the first file that fails to encode or
+// fails validation. The inputs left are recorded in BatchResult.Failures
+// with ErrNotAttempted. By default the batch continues past failures.
+func WithFailFast() Option {
+	return func(c *config.Config) {
+		c.FailFast = true
+	}
+}
+
 // WithDisableAutocrop disables automatic black bar detection.
 func WithDisableAutocrop() Option {
 	return func(c *config.Config) {
@@ -101,6 +252,22 @@ func WithDisableAutocrop() Option {
 	}
 }
 
+// WithCropConfidence sets the share of crop detection samples (0-1, default
+// 0.8) that must agree on one crop before it is used.
+func WithCropConfidence(confidence float64) Option {
+	return func(c *config.Config) {
+		c.CropConfidence = confidence
+	}
+}
+
+// WithCropFilter crops every input to "W:H:X:Y" instead of detecting black
+// bars. The crop must be centered in the source; inputs it doesn't fit fail.
+func WithCropFilter(crop string) Option {
+	return func(c *config.Config) {
+		c.CropFilter = crop
+	}
+}
+
 // WithWorkers sets the number of parallel encoder workers.
 // Default is 1. Higher values enable parallel chunk encoding.
 func WithWorkers(workers int) Option {
@@ -117,6 +284,367 @@ func WithChunkBuffer(buffer int) Option {
 	}
 }
 
+// WithDecodeAhead sets how many frames each worker decodes ahead of its
+// encoder. Default is 2; each frame held costs a decoded frame of memory.
+func WithDecodeAhead(frames int) Option {
+	return func(c *config.Config) {
+		c.DecodeAhead = frames
+	}
+}
+
+// WithThreadsPerWorker sets the threads per encoder worker (SVT-AV1 --lp flag).
+// Default is calculated from the core count and resolution.
+func WithThreadsPerWorker(threads int) Option {
+	return func(c *config.Config) {
+		c.ThreadsPerWorker = threads
+	}
+}
+
+// WithDisableMemoryCap uses the worker count as given instead of capping it
+// by available memory, for machines where MemAvailable understates what can
+// be used (large swap or zram).
+func WithDisableMemoryCap() Option {
+	return func(c *config.Config) {
+		c.DisableMemoryCap = true
+	}
+}
+
+// WithMemPerWorker sets the memory each worker is assumed to need when
+// capping workers by available memory (0 = estimate by resolution).
+func WithMemPerWorker(bytes uint64) Option {
+	return func(c *config.Config) {
+		c.MemPerWorker = bytes
+	}
+}
+
+// WithNUMA sets whether encoder workers are pinned to NUMA nodes: "auto"
+// (default) spreads them round robin across nodes on multi-socket machines,
+// pinning each worker's decoding and its SvtAv1EncApp processes; "off" leaves
+// scheduling to the kernel.
+func WithNUMA(mode string) Option {
+	return func(c *config.Config) {
+		c.NUMA = mode
+	}
+}
+
+// WithAsyncEvents delivers events to the EventHandler from a separate
+// goroutine with room for buffer events, so a slow handler doesn't hold up
+// encoding. Progress events are dropped while the buffer is full; other
+// events wait for room. Encode returns once every queued event is delivered.
+func WithAsyncEvents(buffer int) Option {
+	return func(c *config.Config) {
+		c.EventBuffer = buffer
+	}
+}
+
+// WithChunkRetries sets how many times a chunk whose encoder was killed by a
+// signal, such as by the OOM killer, is retried before the encode fails.
+// Each retry waits longer; fewerThreads halves the threads on each retry.
+// Default is 2 retries at the same thread count.
+func WithChunkRetries(retries int, fewerThreads bool) Option {
+	return func(c *config.Config) {
+		c.ChunkRetries = retries
+		c.RetryFewerThreads = fewerThreads
+	}
+}
+
+// WithChunkTimeout sets the seconds allowed per 1080p frame of a chunk,
+// scaled by the frame size and at least 5 minutes a chunk, before its
+// encoder is killed as hung and the chunk retried as WithChunkRetries
+// allows. 0 disables the timeout. Default is 30.
+func WithChunkTimeout(secsPerFrame float64) Option {
+	return func(c *config.Config) {
+		c.ChunkTimeoutSecs = secsPerFrame
+	}
+}
+
+// WithPrefetch analyzes the next file in a batch (probing, crop detection and
+// indexing) while the current one encodes, so it starts without waiting.
+func WithPrefetch(enabled bool) Option {
+	return func(c *config.Config) {
+		c.Prefetch = enabled
+	}
+}
+
+// WithParallelFiles encodes up to n files of a batch at once, each with a 1/n
+// share of the workers. Reports from the files are serialized, and progress
+// events cover all files that are encoding. Default is 1.
+func WithParallelFiles(n int) Option {
+	return func(c *config.Config) {
+		c.ParallelFiles = n
+	}
+}
+
+// WithIndexCache sets the directory FFMS2 indexes are cached in between
+// encodes, so a source is only indexed once. An empty dir disables the cache.
+// Default is $XDG_CACHE_HOME/reel/index.
+func WithIndexCache(dir string) Option {
+	return func(c *config.Config) {
+		c.IndexCacheDir = dir
+	}
+}
+
+// WithBatchResume keeps batch progress in dir, so re-running an interrupted
+// EncodeBatch with the same inputs and outputs skips the files it finished
+// (recorded as ErrFinishedEarlier). Default is off; the CLI uses
+// $XDG_STATE_HOME/reel/batches.
+func WithBatchResume(dir string) Option {
+	return func(c *config.Config) {
+		c.BatchStateDir = dir
+	}
+}
+
+// WithChunkDuration sets the chunk length in seconds (1-120) for all resolutions.
+func WithChunkDuration(secs float64) Option {
+	return func(c *config.Config) {
+		c.ChunkDurationSD = secs
+		c.ChunkDurationHD = secs
+		c.ChunkDurationUHD = secs
+	}
+}
+
+// WithChunkDurationByResolution sets resolution-specific chunk lengths in
+// seconds, using the same tiers as WithCRFByResolution.
+func WithChunkDurationByResolution(sd, hd, uhd float64) Option {
+	return func(c *config.Config) {
+		c.ChunkDurationSD = sd
+		c.ChunkDurationHD = hd
+		c.ChunkDurationUHD = uhd
+	}
+}
+
+// WithChunkStrategy sets how chunk boundaries are placed: "fixed" (default)
+// makes every chunk the chunk duration; "balanced" sizes chunks by estimated
+// complexity so each takes about as long to encode.
+func WithChunkStrategy(strategy string) Option {
+	return func(c *config.Config) {
+		c.ChunkStrategy = strategy
+	}
+}
+
+// WithChunkPlanner places chunks with planner instead of the chunk strategy,
+// for custom scene detection or per-episode boundaries. The planner runs once
+// per file; a resumed encode keeps the chunks planned when it started. An
+// error from the planner, or chunks that don't cover the video, fail the file.
+func WithChunkPlanner(planner ChunkPlanner) Option {
+	return func(c *config.Config) {
+		c.ChunkPlanner = planner
+	}
+}
+
+// WithCooldown sets the pause between files in a batch, in seconds. Default is 3.
+func WithCooldown(secs uint64) Option {
+	return func(c *config.Config) {
+		c.EncodeCooldownSecs = secs
+	}
+}
+
+// WithTempDir sets the directory for work files (chunks, merged video).
+// Default is the output directory.
+func WithTempDir(dir string) Option {
+	return func(c *config.Config) {
+		c.TempDir = dir
+	}
+}
+
+// WithScratch sets where work files are kept: "disk" (default); "memory", a
+// RAM-backed tmpfs (the temp dir, or /dev/shm); or "auto", which uses
+// /dev/shm only for work files that fit comfortably in memory. Both memory
+// locations fall back to disk when the estimated work files don't fit.
+func WithScratch(location string) Option {
+	return func(c *config.Config) {
+		c.ScratchLocation = location
+	}
+}
+
+// WithStageLocal copies each source to local storage (the temp dir, or the
+// system temp dir) before encoding it and moves the output into place
+// afterwards, so sources on network storage are read once in sequence
+// rather than seeked through by every chunk.
+func WithStageLocal() Option {
+	return func(c *config.Config) {
+		c.StageLocal = true
+	}
+}
+
+// WithMaxFilesPerRun starts at most n files of a batch, returning the rest
+// as failures wrapping ErrDeferred for a later run. Inputs whose output
+// already exists don't count. 0 means no limit.
+func WithMaxFilesPerRun(n int) Option {
+	return func(c *config.Config) {
+		c.MaxFilesPerRun = n
+	}
+}
+
+// WithGrowingInput encodes inputs that are still being ripped or downloaded.
+// Chunks are encoded as the part of the file holding them is written, and
+// the rest once the file hasn't grown for quietSecs seconds. Needs the fixed
+// chunk strategy.
+func WithGrowingInput(quietSecs uint64) Option {
+	return func(c *config.Config) {
+		c.GrowingQuietSecs = quietSecs
+	}
+}
+
+// WithEstimate encodes a few probe chunks before each encode and reports the
+// projected output size and encode time via EncodeEstimateEvent.
+func WithEstimate(enabled bool) Option {
+	return func(c *config.Config) {
+		c.EstimateSize = enabled
+	}
+}
+
+// WithVerifyDeterminism encodes one random chunk of each file twice before
+// the encode and warns if the two outputs differ; the report records the
+// result.
+func WithVerifyDeterminism() Option {
+	return func(c *config.Config) {
+		c.VerifyDeterminism = true
+	}
+}
+
+// WithAbortIfLargerThan stops a file's encode once its projected output exceeds
+// ratio times the source size. The file is skipped with a warning.
+func WithAbortIfLargerThan(ratio float64) Option {
+	return func(c *config.Config) {
+		c.AbortSizeRatio = ratio
+	}
+}
+
+// WithToolPath runs tool ("ffmpeg", "ffprobe", "mediainfo" or
+// "SvtAv1EncApp") from path instead of looking it up in PATH. A REEL_<TOOL>
+// environment variable, e.g. REEL_SVTAV1ENCAPP, still takes precedence.
+func WithToolPath(tool, path string) Option {
+	return func(c *config.Config) {
+		c.ToolPaths = maps.Clone(c.ToolPaths)
+		if c.ToolPaths == nil {
+			c.ToolPaths = make(map[string]string)
+		}
+		c.ToolPaths[strings.ToLower(tool)] = path
+	}
+}
+
+// WithDuplicatePolicy sets how batch inputs that are the same file or have
+// identical content are handled: "link" (default) or "copy" encode once and
+// give the duplicates the same output, "skip" encodes once without outputs
+// for duplicates, and "encode" disables deduplication.
+func WithDuplicatePolicy(policy string) Option {
+	return func(c *config.Config) {
+		c.DuplicatePolicy = policy
+	}
+}
+
+// WithVideoExtensions sets the extensions recognized when a batch input is a
+// directory, e.g. ".mkv", ".m2ts". Case and the leading dot don't matter.
+func WithVideoExtensions(extensions ...string) Option {
+	return func(c *config.Config) {
+		c.VideoExtensions = nil
+		for _, ext := range extensions {
+			if ext = util.NormalizeExtension(ext); ext != "" {
+				c.VideoExtensions = append(c.VideoExtensions, ext)
+			}
+		}
+	}
+}
+
+// WithInclude keeps only the files in directory inputs whose name matches
+// one of the globs, e.g. "*.mkv". Matching ignores case.
+func WithInclude(globs ...string) Option {
+	return func(c *config.Config) {
+		c.IncludeGlobs = globs
+	}
+}
+
+// WithExclude skips the files in directory inputs whose name matches one of
+// the globs, e.g. "*sample*", "*trailer*". Matching ignores case.
+func WithExclude(globs ...string) Option {
+	return func(c *config.Config) {
+		c.ExcludeGlobs = globs
+	}
+}
+
+// WithMinSize skips files in directory inputs smaller than bytes.
+func WithMinSize(bytes uint64) Option {
+	return func(c *config.Config) {
+		c.MinFileSize = bytes
+	}
+}
+
+// WithMinDuration skips files in directory inputs shorter than d. Each file
+// passing the other filters is probed for its duration.
+func WithMinDuration(d time.Duration) Option {
+	return func(c *config.Config) {
+		c.MinDuration = d
+	}
+}
+
+// WithOutputTemplate names outputs from a template instead of the source
+// name, e.g. "{name}.av1.{ext}". Tokens are {name}, {resolution}, {crf},
+// {codec}, {date} and {ext}; {name:x264=AV1} replaces text in the name.
+// An output path given for an input takes precedence.
+func WithOutputTemplate(template string) Option {
+	return func(c *config.Config) {
+		c.OutputTemplate = template
+	}
+}
+
+// WithOutputMode sets outputs and their reports to mode, e.g. 0o664, and
+// the directories created for them to the matching mode with search bits.
+func WithOutputMode(mode os.FileMode) Option {
+	return func(c *config.Config) {
+		c.OutputPerms.Mode = mode
+	}
+}
+
+// WithOutputOwner sets the owner and group of outputs and the directories
+// created for them; -1 keeps either one.
+func WithOutputOwner(uid, gid int) Option {
+	return func(c *config.Config) {
+		c.OutputPerms.Chown, c.OutputPerms.UID, c.OutputPerms.GID = true, uid, gid
+	}
+}
+
+// WithPreserveTimes gives each output the modification time of its
+// source, and its access time too when withAccess is set.
+func WithPreserveTimes(withAccess bool) Option {
+	return func(c *config.Config) {
+		c.PreserveTimes, c.PreserveAccessTime = true, withAccess
+	}
+}
+
+// WithChecksum computes a digest of each output with algorithm ("sha256"),
+// writes it to <output>.<algorithm> in the format sha256sum -c reads, and
+// includes it in the result and the report.
+func WithChecksum(algorithm string) Option {
+	return func(c *config.Config) {
+		c.Checksum = algorithm
+	}
+}
+
+// WithReport writes <output>.reel.json next to each output with the encode
+// results and structured validation codes.
+func WithReport(enabled bool) Option {
+	return func(c *config.Config) {
+		c.WriteReport = enabled
+	}
+}
+
+// WithAttachSettings attaches the encode settings to each output as a JSON
+// file, in addition to the container tags always written.
+func WithAttachSettings() Option {
+	return func(c *config.Config) {
+		c.AttachSettings = true
+	}
+}
+
+// WithContent sets the content type to tune for: "film", "anime", "screen",
+// or "auto" (the default) to classify each source.
+func WithContent(content string) Option {
+	return func(c *config.Config) {
+		c.ContentType = content
+	}
+}
+
 // EncodeWithReporter encodes a single video file using a custom Reporter.
 // This provides direct access to all encoding events, unlike Encode which
 // uses the EventHandler abstraction.
@@ -126,7 +654,7 @@ func (e *Encoder) EncodeWithReporter(ctx context.Context, input, outputDir strin
 	cfg.OutputDir = outputDir
 
 	// Ensure output directory exists
-	if err := util.EnsureDirectory(outputDir); err != nil {
+	if err := util.EnsureOutputDirectory(outputDir, cfg.OutputPerms); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -136,100 +664,157 @@ func (e *Encoder) EncodeWithReporter(ctx context.Context, input, outputDir strin
 	}
 
 	// Process single file
-	results, err := processing.ProcessVideos(ctx, &cfg, []string{input}, "", rep)
+	results, failures, err := processing.ProcessVideos(ctx, &cfg, []string{input}, nil, rep)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(results) == 0 {
-		return nil, fmt.Errorf("no files were encoded")
-	}
-
-	r := results[0]
-	return &Result{
-		OutputFile:           util.ResolveOutputPath(input, outputDir, ""),
-		OriginalSize:         r.InputSize,
-		EncodedSize:          r.OutputSize,
-		SizeReductionPercent: util.CalculateSizeReduction(r.InputSize, r.OutputSize),
-		ValidationPassed:     r.ValidationPassed,
-		EncodingSpeed:        r.EncodingSpeed,
-	}, nil
+	return singleResult(input, results, failures)
 }
 
 // Encode encodes a single video file.
+//
+// Errors can be tested with errors.Is against ErrDependencyMissing,
+// ErrCancelled, ErrOutputExists and ErrNoVideo, and with errors.As for
+// *ChunkEncodeError and *SizeAbortError. When the output fails validation,
+// the Result is returned along with an error wrapping ErrValidationFailed.
 func (e *Encoder) Encode(ctx context.Context, input, outputDir string, handler EventHandler) (*Result, error) {
 	// Update config paths
 	cfg := *e.config
 	cfg.OutputDir = outputDir
 
 	// Ensure output directory exists
-	if err := util.EnsureDirectory(outputDir); err != nil {
+	if err := util.EnsureOutputDirectory(outputDir, cfg.OutputPerms); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create reporter
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 	var rep reporter.Reporter = reporter.NullReporter{}
 	if handler != nil {
-		rep = newEventReporter(handler)
+		events := newEventReporter(handler, cancel, cfg.EventBuffer)
+		defer events.close()
+		rep = events
 	}
 
 	// Process single file
-	results, err := processing.ProcessVideos(ctx, &cfg, []string{input}, "", rep)
+	results, failures, err := processing.ProcessVideos(ctx, &cfg, []string{input}, nil, rep)
 	if err != nil {
 		return nil, err
 	}
+	stoppedByHandler(ctx, failures)
+	return singleResult(input, results, failures)
+}
 
+// singleResult converts the outcome of a single-file ProcessVideos call.
+func singleResult(input string, results []processing.EncodeResult, failures []*processing.FileError) (*Result, error) {
 	if len(results) == 0 {
+		if len(failures) > 0 {
+			return nil, failures[0]
+		}
 		return nil, fmt.Errorf("no files were encoded")
 	}
 
 	r := results[0]
-	return &Result{
-		OutputFile:           util.ResolveOutputPath(input, outputDir, ""),
+	result := &Result{
+		OutputFile:           r.OutputPath,
 		OriginalSize:         r.InputSize,
 		EncodedSize:          r.OutputSize,
 		SizeReductionPercent: util.CalculateSizeReduction(r.InputSize, r.OutputSize),
 		ValidationPassed:     r.ValidationPassed,
 		EncodingSpeed:        r.EncodingSpeed,
-	}, nil
+		Checksum:             r.Checksum,
+	}
+	if !r.ValidationPassed {
+		return result, validationError(input, r.ValidationSteps)
+	}
+	return result, nil
+}
+
+// validationError lists the codes of the failed validation checks.
+func validationError(input string, steps []validation.ValidationStep) error {
+	var codes []string
+	for _, s := range steps {
+		if !s.Passed {
+			codes = append(codes, s.Code)
+		}
+	}
+	return &FileError{Input: input, Err: fmt.Errorf("%w: %s", ErrValidationFailed, strings.Join(codes, ", "))}
 }
 
-// EncodeBatch encodes multiple video files.
+// BatchOptions configures EncodeBatchWithOptions.
+type BatchOptions struct {
+	// Outputs maps an input path to its output path. Relative outputs are
+	// placed in the output directory. Inputs without an entry are written as
+	// <name>.mkv in the output directory.
+	Outputs map[string]string
+}
+
+// EncodeBatch encodes multiple video files. Directory inputs are expanded to
+// the video files they contain.
 func (e *Encoder) EncodeBatch(ctx context.Context, inputs []string, outputDir string, handler EventHandler) (*BatchResult, error) {
+	return e.EncodeBatchWithOptions(ctx, inputs, outputDir, BatchOptions{}, handler)
+}
+
+// EncodeBatchWithOptions encodes multiple video files with per-input output
+// paths. Directory inputs are expanded to the video files they contain, in
+// the same order as the CLI; their files can be given outputs in opts.Outputs.
+func (e *Encoder) EncodeBatchWithOptions(ctx context.Context, inputs []string, outputDir string, opts BatchOptions, handler EventHandler) (*BatchResult, error) {
 	// Update config paths
 	cfg := *e.config
 	cfg.OutputDir = outputDir
 
+	files, err := expandInputs(toolpath.Resolve(cfg.ToolPaths), inputs, cfg.VideoExtensions, discovery.Filter{
+		Include:     cfg.IncludeGlobs,
+		Exclude:     cfg.ExcludeGlobs,
+		MinSize:     cfg.MinFileSize,
+		MinDuration: cfg.MinDuration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := absKeys(opts.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure output directory exists
-	if err := util.EnsureDirectory(outputDir); err != nil {
+	if err := util.EnsureOutputDirectory(outputDir, cfg.OutputPerms); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create reporter
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 	var rep reporter.Reporter = reporter.NullReporter{}
 	if handler != nil {
-		rep = newEventReporter(handler)
+		events := newEventReporter(handler, cancel, cfg.EventBuffer)
+		defer events.close()
+		rep = events
 	}
 
 	// Process files
-	results, err := processing.ProcessVideos(ctx, &cfg, inputs, "", rep)
+	results, failures, err := processing.ProcessVideos(ctx, &cfg, files, outputs, rep)
 	if err != nil {
 		return nil, err
 	}
+	stoppedByHandler(ctx, failures)
 
 	batch := &BatchResult{
-		TotalFiles: len(inputs),
+		TotalFiles: len(files),
+		Failures:   failures,
 	}
 
 	var totalInputSize, totalOutputSize uint64
 	for _, r := range results {
 		batch.Results = append(batch.Results, Result{
-			OutputFile:           util.ResolveOutputPath(r.Filename, outputDir, ""),
+			OutputFile:           r.OutputPath,
 			OriginalSize:         r.InputSize,
 			EncodedSize:          r.OutputSize,
 			SizeReductionPercent: util.CalculateSizeReduction(r.InputSize, r.OutputSize),
 			ValidationPassed:     r.ValidationPassed,
 			EncodingSpeed:        r.EncodingSpeed,
+			Checksum:             r.Checksum,
 		})
 		batch.SuccessfulCount++
 		totalInputSize += r.InputSize
@@ -244,34 +829,296 @@ func (e *Encoder) EncodeBatch(ctx context.Context, inputs []string, outputDir st
 	return batch, nil
 }
 
+// expandInputs resolves inputs to absolute paths, replacing directories with
+// the video files they contain that pass the discovery filter.
+func expandInputs(paths toolpath.Paths, inputs []string, extensions []string, filter discovery.Filter) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		path, err := filepath.Abs(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input path %s: %w", input, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("input path does not exist: %s", input)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		found, err := discovery.FindVideoFiles(path, extensions)
+		if err != nil {
+			return nil, err
+		}
+		found, _ = filter.Apply(paths, found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// absKeys returns outputs keyed by absolute input path, to match expandInputs.
+func absKeys(outputs map[string]string) (map[string]string, error) {
+	abs := make(map[string]string, len(outputs))
+	for input, output := range outputs {
+		path, err := filepath.Abs(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input path %s: %w", input, err)
+		}
+		abs[path] = output
+	}
+	return abs, nil
+}
+
 // FindVideos finds video files in a directory.
 func FindVideos(dir string) ([]string, error) {
-	return discovery.FindVideoFiles(dir)
+	return discovery.FindVideoFiles(dir, util.DefaultVideoExtensions)
 }
 
-// eventReporter adapts EventHandler to the Reporter interface.
+// stoppedByHandler adds the handler's error to failures cancelled because an
+// EventHandler returned ErrStopEncoding.
+func stoppedByHandler(ctx context.Context, failures []*FileError) {
+	cause := context.Cause(ctx)
+	if !errors.Is(cause, ErrStopEncoding) {
+		return
+	}
+	for _, f := range failures {
+		if errors.Is(f.Err, ErrCancelled) {
+			f.Err = fmt.Errorf("%w: %w", cause, f.Err)
+		}
+	}
+}
+
+// eventReporter adapts EventHandler to the Reporter interface. A handler
+// returning ErrStopEncoding cancels the encode; other handler errors are
+// reported once per event type as a WarningEvent. With a buffer, events are
+// delivered from a separate goroutine so a slow handler doesn't hold up
+// encoding; progress events are dropped while the buffer is full.
 type eventReporter struct {
 	handler EventHandler
+	cancel  context.CancelCauseFunc
+
+	mu     sync.Mutex
+	failed map[string]bool // Event types whose handler error was reported
+
+	queue chan Event // nil = deliver synchronously
+	done  chan struct{}
 }
 
-func newEventReporter(handler EventHandler) *eventReporter {
-	return &eventReporter{handler: handler}
+func newEventReporter(handler EventHandler, cancel context.CancelCauseFunc, buffer int) *eventReporter {
+	r := &eventReporter{handler: handler, cancel: cancel, failed: make(map[string]bool)}
+	if buffer > 0 {
+		r.queue = make(chan Event, buffer)
+		r.done = make(chan struct{})
+		go func() {
+			defer close(r.done)
+			for e := range r.queue {
+				r.deliver(e)
+			}
+		}()
+	}
+	return r
 }
 
-func (r *eventReporter) Hardware(reporter.HardwareSummary)             {}
-func (r *eventReporter) Initialization(reporter.InitializationSummary) {}
-func (r *eventReporter) StageProgress(reporter.StageProgress)          {}
-func (r *eventReporter) CropResult(reporter.CropSummary)               {}
-func (r *eventReporter) EncodingConfig(reporter.EncodingConfigSummary) {}
-func (r *eventReporter) EncodingStarted(uint64)                        {}
+// emit delivers e, or queues it when events are delivered asynchronously.
+func (r *eventReporter) emit(e Event) {
+	if r.queue == nil {
+		r.deliver(e)
+		return
+	}
+	if droppable(e) {
+		select {
+		case r.queue <- e:
+		default: // The next progress event supersedes it
+		}
+		return
+	}
+	r.queue <- e
+}
+
+// droppable reports whether e may be dropped when the queue is full because
+// a later event supersedes it.
+func droppable(e Event) bool {
+	switch e := e.(type) {
+	case EncodingProgressEvent:
+		return true
+	case TaskProgressEvent:
+		return e.Done < e.Total
+	}
+	return false
+}
+
+func (r *eventReporter) deliver(e Event) {
+	err := r.handler(e)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrStopEncoding) {
+		r.cancel(err)
+		return
+	}
+
+	r.mu.Lock()
+	reported := r.failed[e.Type()]
+	r.failed[e.Type()] = true
+	r.mu.Unlock()
+	if !reported && e.Type() != EventTypeWarning {
+		_ = r.handler(WarningEvent{
+			BaseEvent: BaseEvent{EventType: EventTypeWarning, Time: NewTimestamp()},
+			Message:   fmt.Sprintf("Event handler failed on %s: %v", e.Type(), err),
+		})
+	}
+}
+
+// close waits for queued events to be delivered.
+func (r *eventReporter) close() {
+	if r.queue != nil {
+		close(r.queue)
+		<-r.done
+	}
+}
+
+func (r *eventReporter) Hardware(s reporter.HardwareSummary) {
+	r.emit(HardwareEvent{
+		BaseEvent:            BaseEvent{EventType: EventTypeHardware, Time: NewTimestamp()},
+		Hostname:             s.Hostname,
+		CPUModel:             s.CPUModel,
+		PhysicalCores:        s.PhysicalCores,
+		LogicalCores:         s.LogicalCores,
+		TotalMemoryBytes:     s.TotalMemory,
+		AvailableMemoryBytes: s.AvailableMemory,
+		OS:                   s.OS,
+		SvtVersion:           s.SvtVersion,
+		FFmpegVersion:        s.FFmpegVersion,
+		NUMANodes:            s.NUMANodes,
+		NUMA:                 s.NUMA,
+	})
+}
+
+func (r *eventReporter) Initialization(s reporter.InitializationSummary) {
+	r.emit(InitializationEvent{
+		BaseEvent:        BaseEvent{EventType: EventTypeInitialization, Time: NewTimestamp()},
+		InputFile:        s.InputFile,
+		OutputFile:       s.OutputFile,
+		Duration:         s.Duration,
+		Resolution:       s.Resolution,
+		DynamicRange:     s.DynamicRange,
+		AudioDescription: s.AudioDescription,
+		ObjectAudio:      s.ObjectAudio,
+	})
+}
+
+func (r *eventReporter) StageProgress(s reporter.StageProgress) {
+	var eta int64
+	if s.ETA != nil {
+		eta = int64(s.ETA.Seconds())
+	}
+	r.emit(StageProgressEvent{
+		BaseEvent:  BaseEvent{EventType: EventTypeStageProgress, Time: NewTimestamp()},
+		Stage:      s.Stage,
+		Percent:    s.Percent,
+		Message:    s.Message,
+		ETASeconds: eta,
+	})
+}
+
+func (r *eventReporter) TaskProgress(p reporter.TaskProgress) {
+	r.emit(TaskProgressEvent{
+		BaseEvent: BaseEvent{EventType: EventTypeTaskProgress, Time: NewTimestamp()},
+		Task:      p.Task,
+		Done:      p.Done,
+		Total:     p.Total,
+		Unit:      p.Unit,
+		Percent:   p.Percent(),
+	})
+}
+
+func (r *eventReporter) CropResult(s reporter.CropSummary) {
+	r.emit(CropResultEvent{
+		BaseEvent: BaseEvent{EventType: EventTypeCropResult, Time: NewTimestamp()},
+		Message:   s.Message,
+		Crop:      s.Crop,
+		Required:  s.Required,
+		Disabled:  s.Disabled,
+	})
+}
+
+func (r *eventReporter) EncodingConfig(s reporter.EncodingConfigSummary) {
+	r.emit(EncodingConfigEvent{
+		BaseEvent:          BaseEvent{EventType: EventTypeEncodingConfig, Time: NewTimestamp()},
+		Encoder:            s.Encoder,
+		Preset:             s.Preset,
+		Tune:               s.Tune,
+		Quality:            s.Quality,
+		Content:            s.Content,
+		PixelFormat:        s.PixelFormat,
+		MatrixCoefficients: s.MatrixCoefficients,
+		AudioCodec:         s.AudioCodec,
+		AudioDescription:   s.AudioDescription,
+		SVTAV1Params:       s.SVTAV1Params,
+	})
+}
+
+func (r *eventReporter) EncodingStarted(totalFrames uint64) {
+	r.EncodingStartedWith(reporter.EncodingStart{TotalFrames: totalFrames})
+}
+
+func (r *eventReporter) EncodingStartedWith(s reporter.EncodingStart) {
+	r.emit(EncodingStartedEvent{
+		BaseEvent:      BaseEvent{EventType: EventTypeEncodingStarted, Time: NewTimestamp()},
+		TotalFrames:    s.TotalFrames,
+		TotalChunks:    s.TotalChunks,
+		ResumedFrames:  s.ResumedFrames,
+		ResumedChunks:  s.ResumedChunks,
+		ResumedPercent: s.ResumedPercent(),
+	})
+}
+
+func (r *eventReporter) EncodeEstimate(e reporter.EncodeEstimate) {
+	r.emit(EncodeEstimateEvent{
+		BaseEvent:        BaseEvent{EventType: EventTypeEncodeEstimate, Time: NewTimestamp()},
+		OriginalSize:     e.OriginalSize,
+		EstimatedSize:    e.EstimatedSize,
+		BitrateKbps:      e.BitrateKbps,
+		EstimatedSeconds: int64(e.EstimatedTime.Seconds()),
+		ProbeChunks:      e.ProbeChunks,
+	})
+}
 
 func (r *eventReporter) EncodingProgress(p reporter.ProgressSnapshot) {
-	_ = r.handler(EncodingProgressEvent{
+	r.emit(EncodingProgressEvent{
 		BaseEvent:  BaseEvent{EventType: EventTypeEncodingProgress, Time: NewTimestamp()},
 		Percent:    p.Percent,
 		Speed:      p.Speed,
 		FPS:        p.FPS,
 		ETASeconds: int64(p.ETA.Seconds()),
+
+		BitrateKbps:   p.BitrateKbps,
+		ProjectedSize: p.ProjectedSize,
+	})
+}
+
+func (r *eventReporter) ChunkRetry(c reporter.ChunkRetry) {
+	r.emit(ChunkRetryEvent{
+		BaseEvent:    BaseEvent{EventType: EventTypeChunkRetry, Time: NewTimestamp()},
+		Chunk:        c.Chunk,
+		Attempt:      c.Attempt,
+		Retries:      c.Retries,
+		Threads:      c.Threads,
+		DelaySeconds: int64(c.Delay.Seconds()),
+		Reason:       c.Reason,
+	})
+}
+
+func (r *eventReporter) WorkerCap(c reporter.WorkerCap) {
+	r.emit(WorkerCapEvent{
+		BaseEvent:            BaseEvent{EventType: EventTypeWorkerCap, Time: NewTimestamp()},
+		Requested:            c.Requested,
+		Granted:              c.Granted,
+		MemPerWorkerBytes:    c.MemPerWorker,
+		AvailableMemoryBytes: c.Available,
+		Capped:               c.Granted < c.Requested,
+		Raised:               c.Raised,
+		Lowered:              c.Lowered,
 	})
 }
 
@@ -280,11 +1127,14 @@ func (r *eventReporter) ValidationComplete(s reporter.ValidationSummary) {
 	for i, step := range s.Steps {
 		steps[i] = ValidationStep{
 			Step:    step.Name,
+			Check:   step.Check,
+			Code:    step.Code,
 			Passed:  step.Passed,
 			Details: step.Details,
+			Params:  step.Params,
 		}
 	}
-	_ = r.handler(ValidationCompleteEvent{
+	r.emit(ValidationCompleteEvent{
 		BaseEvent:        BaseEvent{EventType: EventTypeValidationComplete, Time: NewTimestamp()},
 		ValidationPassed: s.Passed,
 		ValidationSteps:  steps,
@@ -292,7 +1142,7 @@ func (r *eventReporter) ValidationComplete(s reporter.ValidationSummary) {
 }
 
 func (r *eventReporter) EncodingComplete(s reporter.EncodingOutcome) {
-	_ = r.handler(EncodingCompleteEvent{
+	r.emit(EncodingCompleteEvent{
 		BaseEvent:            BaseEvent{EventType: EventTypeEncodingComplete, Time: NewTimestamp()},
 		OutputFile:           s.OutputFile,
 		OriginalSize:         s.OriginalSize,
@@ -302,14 +1152,14 @@ func (r *eventReporter) EncodingComplete(s reporter.EncodingOutcome) {
 }
 
 func (r *eventReporter) Warning(message string) {
-	_ = r.handler(WarningEvent{
+	r.emit(WarningEvent{
 		BaseEvent: BaseEvent{EventType: EventTypeWarning, Time: NewTimestamp()},
 		Message:   message,
 	})
 }
 
 func (r *eventReporter) Error(e reporter.ReporterError) {
-	_ = r.handler(ErrorEvent{
+	r.emit(ErrorEvent{
 		BaseEvent:  BaseEvent{EventType: EventTypeError, Time: NewTimestamp()},
 		Title:      e.Title,
 		Message:    e.Message,
@@ -318,16 +1168,45 @@ func (r *eventReporter) Error(e reporter.ReporterError) {
 	})
 }
 
-func (r *eventReporter) OperationComplete(string)                  {}
-func (r *eventReporter) BatchStarted(reporter.BatchStartInfo)      {}
-func (r *eventReporter) FileProgress(reporter.FileProgressContext) {}
+func (r *eventReporter) OperationComplete(string) {}
+
+func (r *eventReporter) BatchStarted(info reporter.BatchStartInfo) {
+	r.emit(BatchStartedEvent{
+		BaseEvent:  BaseEvent{EventType: EventTypeBatchStarted, Time: NewTimestamp()},
+		TotalFiles: info.TotalFiles,
+		FileList:   info.FileList,
+		OutputDir:  info.OutputDir,
+	})
+}
+
+func (r *eventReporter) FileProgress(c reporter.FileProgressContext) {
+	r.emit(FileProgressEvent{
+		BaseEvent:   BaseEvent{EventType: EventTypeFileProgress, Time: NewTimestamp()},
+		CurrentFile: c.CurrentFile,
+		TotalFiles:  c.TotalFiles,
+	})
+}
 
 func (r *eventReporter) BatchComplete(s reporter.BatchSummary) {
-	_ = r.handler(BatchCompleteEvent{
+	var skipped []SkippedFile
+	for _, f := range s.Skipped {
+		skipped = append(skipped, SkippedFile{Filename: f.Filename, Reason: f.Reason})
+	}
+	var failed []FailedFile
+	for _, f := range s.Failed {
+		failed = append(failed, FailedFile{Filename: f.Filename, Reason: f.Reason})
+	}
+	r.emit(BatchCompleteEvent{
 		BaseEvent:                 BaseEvent{EventType: EventTypeBatchComplete, Time: NewTimestamp()},
 		SuccessfulCount:           s.SuccessfulCount,
 		TotalFiles:                s.TotalFiles,
 		TotalSizeReductionPercent: util.CalculateSizeReduction(s.TotalOriginalSize, s.TotalEncodedSize),
+		SkippedCount:              len(s.Skipped),
+		Skipped:                   skipped,
+		FailedCount:               len(s.Failed),
+		Failed:                    failed,
+		DeferredCount:             len(s.Deferred),
+		Deferred:                  s.Deferred,
 	})
 }
 
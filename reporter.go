@@ -14,6 +14,28 @@ type Reporter = reporter.Reporter
 // NullReporter is a no-op reporter that discards all updates.
 type NullReporter = reporter.NullReporter
 
+// EncodingStartReporter is an optional Reporter extension: a reporter that
+// implements it gets EncodingStartedWith, with chunk counts and resume
+// state, in place of EncodingStarted.
+type EncodingStartReporter = reporter.EncodingStartReporter
+
+// TaskReporter is an optional Reporter extension: a reporter that
+// implements it gets progress within indexing, crop detection and audio
+// extraction.
+type TaskReporter = reporter.TaskReporter
+
+// WorkerCapReporter is an optional Reporter extension: a reporter that
+// implements it is told when the worker count is changed to fit memory.
+type WorkerCapReporter = reporter.WorkerCapReporter
+
+// ChunkRetryReporter is an optional Reporter extension: a reporter that
+// implements it is told when a chunk is retried after its encoder was killed.
+type ChunkRetryReporter = reporter.ChunkRetryReporter
+
+// EstimateReporter is an optional Reporter extension: a reporter that
+// implements it gets the projection of an encode run with WithEstimate.
+type EstimateReporter = reporter.EstimateReporter
+
 // HardwareSummary contains hardware information.
 type HardwareSummary = reporter.HardwareSummary
 
@@ -26,6 +48,18 @@ type CropSummary = reporter.CropSummary
 // EncodingConfigSummary contains encoding configuration.
 type EncodingConfigSummary = reporter.EncodingConfigSummary
 
+// EncodingStart describes an encode that is about to begin, including resume state.
+type EncodingStart = reporter.EncodingStart
+
+// EncodeEstimate contains the projected result of an encode.
+type EncodeEstimate = reporter.EncodeEstimate
+
+// ChunkRetry describes a chunk being retried after its encoder was killed.
+type ChunkRetry = reporter.ChunkRetry
+
+// WorkerCap describes how many workers fit in available memory.
+type WorkerCap = reporter.WorkerCap
+
 // ProgressSnapshot contains encoding progress information.
 type ProgressSnapshot = reporter.ProgressSnapshot
 
@@ -57,3 +91,6 @@ type FileResult = reporter.FileResult
 
 // StageProgress represents a generic stage update.
 type StageProgress = reporter.StageProgress
+
+// TaskProgress reports how far an analysis task has got.
+type TaskProgress = reporter.TaskProgress
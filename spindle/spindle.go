@@ -0,0 +1,213 @@
+// Package spindle adapts reel's library events and errors to the job updates
+// Spindle records for an item in its ENCODING stage, so Spindle doesn't need
+// to know which reel events arrive in which order.
+//
+// Compatibility: Update, Phase and Outcome, including their JSON field names
+// and string values, are versioned by SchemaVersion. Within a schema version
+// fields and values are only added, never renamed, removed or given a new
+// meaning. A change that would break a consumer bumps SchemaVersion.
+package spindle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/five82/reel"
+)
+
+// SchemaVersion is the version of the Update and Outcome payloads.
+const SchemaVersion = 1
+
+// Phase is where an encode job is.
+type Phase string
+
+const (
+	PhaseAnalyzing  Phase = "analyzing"  // Probing, crop detection and estimates
+	PhaseEncoding   Phase = "encoding"   // Chunks are being encoded
+	PhaseValidating Phase = "validating" // Output written, being checked
+	PhaseComplete   Phase = "complete"   // Output validated and in place
+)
+
+// Level is the severity of an Update's message.
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Update is the state of an encode job after a reel event.
+type Update struct {
+	SchemaVersion int    `json:"schema_version"`
+	Event         string `json:"event"` // reel event type that produced the update
+	Timestamp     int64  `json:"timestamp"`
+	Phase         Phase  `json:"phase"`
+	Stage         string `json:"stage,omitempty"` // reel's finer-grained stage, e.g. "Chunking" or "Muxing"
+
+	Percent       float32 `json:"percent"` // 0-100 of the encode, including resumed chunks
+	ETASeconds    int64   `json:"eta_seconds"`
+	FPS           float32 `json:"fps"`
+	Speed         float32 `json:"speed"`
+	ProjectedSize uint64  `json:"projected_size"` // Estimated, then extrapolated output size
+
+	Crop        string `json:"crop,omitempty"` // Chosen ffmpeg crop filter, empty when not cropping
+	OutputFile  string `json:"output_file,omitempty"`
+	EncodedSize uint64 `json:"encoded_size,omitempty"`
+
+	Level   Level  `json:"level,omitempty"`
+	Message string `json:"message,omitempty"` // Warnings, errors and validation failures
+}
+
+// Adapter turns reel events into Updates. Progress fields carry over from
+// one update to the next, so every Update describes the whole job.
+type Adapter struct {
+	mu   sync.Mutex
+	send func(Update)
+	last Update
+}
+
+// NewAdapter returns an adapter that calls send with each Update. send is
+// called on the encoding goroutine and should not block for long.
+func NewAdapter(send func(Update)) *Adapter {
+	return &Adapter{
+		send: send,
+		last: Update{SchemaVersion: SchemaVersion, Phase: PhaseAnalyzing},
+	}
+}
+
+// Handler returns the reel.EventHandler to pass to Encode.
+func (a *Adapter) Handler() reel.EventHandler {
+	return func(event reel.Event) error {
+		a.handle(event)
+		return nil
+	}
+}
+
+// Last returns the most recent Update.
+func (a *Adapter) Last() Update {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+func (a *Adapter) handle(event reel.Event) {
+	a.mu.Lock()
+	u := a.last
+	u.Event = event.Type()
+	u.Timestamp = event.Timestamp()
+	u.Level, u.Message = "", ""
+
+	switch e := event.(type) {
+	case reel.StageProgressEvent:
+		u.Stage = e.Stage
+		u.Level = LevelInfo
+		u.Message = e.Message
+	case reel.CropResultEvent:
+		u.Crop = e.Crop
+		u.Level = LevelInfo
+		u.Message = e.Message
+	case reel.EncodeEstimateEvent:
+		u.ProjectedSize = e.EstimatedSize
+		u.ETASeconds = e.EstimatedSeconds
+	case reel.EncodingStartedEvent:
+		u.Phase = PhaseEncoding
+		u.Percent = e.ResumedPercent
+	case reel.EncodingProgressEvent:
+		u.Phase = PhaseEncoding
+		u.Percent = e.Percent
+		u.ETASeconds = e.ETASeconds
+		u.FPS = e.FPS
+		u.Speed = e.Speed
+		u.ProjectedSize = e.ProjectedSize
+	case reel.ChunkRetryEvent:
+		u.Level = LevelWarning
+		u.Message = fmt.Sprintf("Retrying chunk %d (attempt %d of %d): %s", e.Chunk, e.Attempt, e.Retries, e.Reason)
+	case reel.ValidationCompleteEvent:
+		u.Phase = PhaseValidating
+		u.Percent = 100
+		u.ETASeconds = 0
+		if !e.ValidationPassed {
+			u.Level = LevelError
+			u.Message = "Validation failed: " + strings.Join(failedCodes(e.ValidationSteps), ", ")
+		}
+	case reel.EncodingCompleteEvent:
+		u.Phase = PhaseComplete
+		u.Percent = 100
+		u.ETASeconds = 0
+		u.OutputFile = e.OutputFile
+		u.EncodedSize = e.EncodedSize
+	case reel.WarningEvent:
+		u.Level = LevelWarning
+		u.Message = e.Message
+	case reel.ErrorEvent:
+		u.Level = LevelError
+		u.Message = e.Title + ": " + e.Message
+	default:
+		// Batch and other events don't change a single job's state
+		a.mu.Unlock()
+		return
+	}
+
+	a.last = u
+	a.mu.Unlock()
+	a.send(u)
+}
+
+// failedCodes returns the result codes of the failed validation steps.
+func failedCodes(steps []reel.ValidationStep) []string {
+	var codes []string
+	for _, s := range steps {
+		if !s.Passed {
+			codes = append(codes, s.Code)
+		}
+	}
+	return codes
+}
+
+// Outcome is how an encode job ended, for deciding the item's next status.
+type Outcome string
+
+const (
+	OutcomeEncoded           Outcome = "encoded"            // Output written and validated
+	OutcomeValidationFailed  Outcome = "validation_failed"  // Output written but failed validation
+	OutcomeSkipped           Outcome = "skipped"            // Nothing to do: no video, output exists, too large, or done earlier
+	OutcomeDeferred          Outcome = "deferred"           // Stopped by a deadline; rerun to continue
+	OutcomeCancelled         Outcome = "cancelled"          // The context was cancelled; rerun to continue
+	OutcomeDependencyMissing Outcome = "dependency_missing" // Encoder tools missing or too old; retrying won't help
+	OutcomeFailed            Outcome = "failed"             // Any other failure
+)
+
+// Classify returns the Outcome of an Encode call from its error.
+func Classify(err error) Outcome {
+	var sizeErr *reel.SizeAbortError
+	switch {
+	case err == nil:
+		return OutcomeEncoded
+	case errors.Is(err, reel.ErrValidationFailed):
+		return OutcomeValidationFailed
+	case errors.Is(err, reel.ErrNoVideo), errors.Is(err, reel.ErrOutputExists),
+		errors.Is(err, reel.ErrFinishedEarlier), errors.As(err, &sizeErr):
+		return OutcomeSkipped
+	case errors.Is(err, reel.ErrDeferred):
+		return OutcomeDeferred
+	case errors.Is(err, reel.ErrCancelled), errors.Is(err, context.Canceled):
+		return OutcomeCancelled
+	case errors.Is(err, reel.ErrDependencyMissing):
+		return OutcomeDependencyMissing
+	default:
+		return OutcomeFailed
+	}
+}
+
+// Retryable reports whether rerunning the same encode may succeed.
+func (o Outcome) Retryable() bool {
+	switch o {
+	case OutcomeDeferred, OutcomeCancelled, OutcomeFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package spindle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/five82/reel"
+)
+
+func TestAdapterPhases(t *testing.T) {
+	var updates []Update
+	a := NewAdapter(func(u Update) { updates = append(updates, u) })
+	handler := a.Handler()
+
+	events := []reel.Event{
+		reel.CropResultEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeCropResult}, Crop: "crop=1920:800:0:140", Required: true},
+		reel.StageProgressEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeStageProgress}, Stage: "Chunking"},
+		reel.EncodeEstimateEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeEncodeEstimate}, EstimatedSize: 900},
+		reel.EncodingStartedEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeEncodingStarted}, ResumedPercent: 20},
+		reel.EncodingProgressEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeEncodingProgress}, Percent: 50, ETASeconds: 60, ProjectedSize: 1000},
+		reel.WarningEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeWarning}, Message: "slow"},
+		reel.BatchCompleteEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeBatchComplete}},
+		reel.ValidationCompleteEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeValidationComplete}, ValidationPassed: true},
+		reel.EncodingCompleteEvent{BaseEvent: reel.BaseEvent{EventType: reel.EventTypeEncodingComplete}, OutputFile: "out.mkv", EncodedSize: 800},
+	}
+	for _, e := range events {
+		if err := handler(e); err != nil {
+			t.Fatalf("handler(%s) = %v", e.Type(), err)
+		}
+	}
+
+	wantPhases := []Phase{PhaseAnalyzing, PhaseAnalyzing, PhaseAnalyzing, PhaseEncoding, PhaseEncoding, PhaseEncoding, PhaseValidating, PhaseComplete}
+	if len(updates) != len(wantPhases) {
+		t.Fatalf("got %d updates, want %d", len(updates), len(wantPhases))
+	}
+	for i, want := range wantPhases {
+		if updates[i].Phase != want {
+			t.Errorf("update %d (%s) phase = %s, want %s", i, updates[i].Event, updates[i].Phase, want)
+		}
+		if updates[i].SchemaVersion != SchemaVersion {
+			t.Errorf("update %d schema version = %d, want %d", i, updates[i].SchemaVersion, SchemaVersion)
+		}
+	}
+
+	// Progress carries over to the warning; the message doesn't outlive it
+	if w := updates[5]; w.Percent != 50 || w.ProjectedSize != 1000 || w.Level != LevelWarning || w.Message != "slow" {
+		t.Errorf("warning update = %+v", w)
+	}
+	if v := updates[6]; v.Message != "" || v.Percent != 100 {
+		t.Errorf("validation update = %+v", v)
+	}
+	if last := a.Last(); last.OutputFile != "out.mkv" || last.EncodedSize != 800 || last.Crop != "crop=1920:800:0:140" || last.Stage != "Chunking" {
+		t.Errorf("Last() = %+v", last)
+	}
+}
+
+func TestAdapterValidationFailure(t *testing.T) {
+	var got Update
+	a := NewAdapter(func(u Update) { got = u })
+	_ = a.Handler()(reel.ValidationCompleteEvent{
+		BaseEvent:        reel.BaseEvent{EventType: reel.EventTypeValidationComplete},
+		ValidationPassed: false,
+		ValidationSteps: []reel.ValidationStep{
+			{Code: "ok", Passed: true},
+			{Code: "duration_mismatch"},
+		},
+	})
+	if got.Level != LevelError || got.Message != "Validation failed: duration_mismatch" {
+		t.Errorf("update = %+v", got)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      Outcome
+		retryable bool
+	}{
+		{"success", nil, OutcomeEncoded, false},
+		{"validation", fmt.Errorf("out.mkv: %w", reel.ErrValidationFailed), OutcomeValidationFailed, false},
+		{"no video", &reel.FileError{Input: "in.mkv", Err: reel.ErrNoVideo}, OutcomeSkipped, false},
+		{"size abort", &reel.SizeAbortError{Projected: 2, Source: 1, Ratio: 0.9}, OutcomeSkipped, false},
+		{"deferred", fmt.Errorf("%w: not started", reel.ErrDeferred), OutcomeDeferred, true},
+		{"cancelled", context.Canceled, OutcomeCancelled, true},
+		{"dependency", fmt.Errorf("ffmpeg: %w", reel.ErrDependencyMissing), OutcomeDependencyMissing, false},
+		{"other", errors.New("encoder failed"), OutcomeFailed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if got != tt.want {
+				t.Errorf("Classify() = %s, want %s", got, tt.want)
+			}
+			if got.Retryable() != tt.retryable {
+				t.Errorf("%s.Retryable() = %v, want %v", got, got.Retryable(), tt.retryable)
+			}
+		})
+	}
+}